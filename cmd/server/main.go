@@ -26,6 +26,9 @@ package main
 // @tag.name todos
 // @tag.description Todo CRUD operations (requires authentication)
 
+// @tag.name admin
+// @tag.description Administrative endpoints (requires an administrator account)
+
 // @tag.name health
 // @tag.description Health check and readiness endpoints
 
@@ -38,6 +41,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -46,12 +50,17 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	_ "todo-api-backend/docs" // Import generated docs
+	"todo-api-backend/internal/audit"
 	"todo-api-backend/internal/config"
 	"todo-api-backend/internal/database"
 	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/jobs"
 	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/outbox"
+	"todo-api-backend/internal/realtime"
 	"todo-api-backend/internal/repository"
 	"todo-api-backend/internal/service"
+	"todo-api-backend/internal/storage"
 	"todo-api-backend/pkg/jwt"
 )
 
@@ -86,11 +95,133 @@ func main() {
 	// Initialize repositories
 	repos := repository.NewRepositories(db)
 
+	// Initialize the optional security audit file sink
+	var serviceOpts []service.ServicesOption
+	if cfg.AuditLogFile != "" {
+		auditFile, err := audit.NewFileSink(cfg.AuditLogFile)
+		if err != nil {
+			log.Fatalf("Failed to open audit log file: %v", err)
+		}
+		defer auditFile.Close()
+		serviceOpts = append(serviceOpts, service.WithAuditSinks(auditFile))
+	}
+
+	if cfg.IdempotencyTTL > 0 {
+		serviceOpts = append(serviceOpts, service.WithIdempotencyTTL(time.Duration(cfg.IdempotencyTTL)*time.Hour))
+	}
+
+	if cfg.TrashRetentionDays > 0 {
+		serviceOpts = append(serviceOpts, service.WithTrashRetention(time.Duration(cfg.TrashRetentionDays)*24*time.Hour))
+	}
+
+	serviceOpts = append(serviceOpts, service.WithEventReplayBuffer(cfg.EventReplayBuffer))
+
+	// Share live messages between instances through Postgres LISTEN/NOTIFY
+	var pgBroker *realtime.PostgresBroker
+	if cfg.UsesPostgresBroker() {
+		pgBroker = realtime.NewPostgresBroker(db, cfg.DatabaseURL)
+		serviceOpts = append(serviceOpts, service.WithBroker(pgBroker))
+	}
+
+	// Write domain events to the transactional outbox and publish them once committed
+	var outboxPublisher outbox.EventPublisher
+	if cfg.OutboxRelayInterval > 0 {
+		outboxPublisher, err = newOutboxPublisher(cfg)
+		if err != nil {
+			log.Fatalf("Failed to create outbox publisher: %v", err)
+		}
+		serviceOpts = append(serviceOpts, service.WithDomainEvents())
+	}
+
+	// Keep the contents of attachments in the configured blob store
+	var blobStore storage.BlobStore
+	if cfg.AttachmentStorage != "" {
+		blobStore, err = newBlobStore(cfg)
+		if err != nil {
+			log.Fatalf("Failed to create attachment storage: %v", err)
+		}
+		urlSecret := cfg.AttachmentURLSecret
+		if urlSecret == "" {
+			urlSecret = cfg.JWTSecret
+		}
+		serviceOpts = append(serviceOpts, service.WithAttachments(blobStore, service.AttachmentLimits{
+			MaxSize:      int64(cfg.AttachmentMaxSizeMB) << 20,
+			Quota:        int64(cfg.AttachmentQuotaMB) << 20,
+			AllowedTypes: cfg.AttachmentAllowedTypes,
+			URLTTL:       time.Duration(cfg.AttachmentURLTTL) * time.Minute,
+			URLSecret:    []byte(urlSecret),
+		}))
+	}
+
 	// Initialize services
-	services := service.NewServices(repos, tokenManager)
+	services := service.NewServices(repos, tokenManager, serviceOpts...)
 
 	// Initialize handlers
-	h := handler.NewHandler(services)
+	h := handler.NewHandler(services, handler.WithHeartbeat(time.Duration(cfg.EventHeartbeat)*time.Second))
+
+	// Start background jobs (stopped on shutdown)
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if pgBroker != nil {
+		go pgBroker.Start(jobsCtx)
+		go realtime.ForwardEvents(jobsCtx, pgBroker, services.Events)
+	}
+
+	if cfg.TrashRetentionDays > 0 {
+		trashPurger := jobs.NewTrashPurger(
+			repos.Todo,
+			time.Duration(cfg.TrashRetentionDays)*24*time.Hour,
+			time.Duration(cfg.TrashPurgeInterval)*time.Minute,
+		)
+		go trashPurger.Start(jobsCtx)
+	}
+
+	if cfg.AutoArchiveInterval > 0 {
+		autoArchiver := jobs.NewAutoArchiver(
+			repos.User,
+			repos.Todo,
+			time.Duration(cfg.AutoArchiveInterval)*time.Minute,
+		)
+		go autoArchiver.Start(jobsCtx)
+	}
+
+	if cfg.IdempotencyTTL > 0 {
+		idempotencyPurger := jobs.NewIdempotencyKeyPurger(
+			repos.Idempotency,
+			time.Duration(cfg.IdempotencyPurgeInterval)*time.Minute,
+		)
+		go idempotencyPurger.Start(jobsCtx)
+	}
+
+	if cfg.WebhookDispatchInterval > 0 {
+		webhookDispatcher := jobs.NewWebhookDispatcher(
+			repos.Webhook,
+			repos.Delivery,
+			time.Duration(cfg.WebhookDispatchInterval)*time.Second,
+			cfg.WebhookMaxAttempts,
+			cfg.WebhookDisableAfter,
+		)
+		go webhookDispatcher.Start(jobsCtx)
+	}
+
+	if outboxPublisher != nil {
+		outboxRelay := jobs.NewOutboxRelay(
+			repos.Outbox,
+			outboxPublisher,
+			time.Duration(cfg.OutboxRelayInterval)*time.Second,
+		)
+		go outboxRelay.Start(jobsCtx)
+	}
+
+	if blobStore != nil && cfg.AttachmentSweepInterval > 0 {
+		attachmentSweeper := jobs.NewAttachmentSweeper(
+			repos.Attachment,
+			blobStore,
+			time.Duration(cfg.AttachmentSweepInterval)*time.Minute,
+		)
+		go attachmentSweeper.Start(jobsCtx)
+	}
 
 	// Create Gin router
 	router := gin.New()
@@ -98,19 +229,20 @@ func main() {
 	// Add recovery middleware
 	router.Use(gin.Recovery())
 
-	// Add logging middleware
+	// Add logging middleware, keeping the WebSocket access token out of the log
 	if cfg.IsDevelopment() {
-		router.Use(gin.Logger())
+		router.Use(middleware.LoggerMiddleware(gin.DefaultWriter, "access_token"))
 	}
 
 	// Add CORS middleware
 	corsConfig := &middleware.CORSConfig{
 		AllowOrigins:     cfg.AllowedOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With", "If-Match", "If-None-Match", "Idempotency-Key", "Last-Event-ID"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Type", "ETag", "Idempotent-Replayed"},
 		AllowCredentials: false,
 		MaxAge:           12 * 3600, // 12 hours
+		ExcludePaths:     []string{"/caldav", "/.well-known/caldav"},
 	}
 	router.Use(middleware.CORSMiddleware(corsConfig))
 
@@ -118,7 +250,14 @@ func main() {
 	registerPublicRoutes(router, h)
 
 	// Register protected routes with JWT middleware
-	registerProtectedRoutes(router, h, tokenManager)
+	var idempotency middleware.IdempotencyStore
+	if cfg.IdempotencyTTL > 0 {
+		idempotency = services.Idempotency
+	}
+	registerProtectedRoutes(router, h, tokenManager, services.Audit, idempotency, cfg.AdminUserIDs)
+
+	// Register the CalDAV server, authenticated with app passwords
+	h.RegisterCalDAVRoutes(router, h.CalDAVAuth(middleware.WithSecurityAuditor(services.Audit)))
 
 	// Create HTTP server
 	server := &http.Server{
@@ -144,6 +283,9 @@ func main() {
 
 	log.Println("Shutting down server...")
 
+	// Stop background jobs
+	stopJobs()
+
 	// Create a context with timeout for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -161,6 +303,34 @@ func main() {
 	log.Println("Server exited")
 }
 
+// newOutboxPublisher creates the publisher the outbox relay hands domain events to
+func newOutboxPublisher(cfg *config.Config) (outbox.EventPublisher, error) {
+	switch strings.ToLower(cfg.OutboxPublisher) {
+	case "http":
+		return outbox.NewHTTPPublisher(cfg.OutboxPublisherURL), nil
+	case "nats":
+		return outbox.NewNATSPublisher(cfg.OutboxPublisherURL, cfg.OutboxSubjectPrefix)
+	default:
+		return outbox.NewLogPublisher(nil), nil
+	}
+}
+
+// newBlobStore creates the blob store that holds the contents of attachments
+func newBlobStore(cfg *config.Config) (storage.BlobStore, error) {
+	switch strings.ToLower(cfg.AttachmentStorage) {
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        cfg.AttachmentS3Endpoint,
+			Region:          cfg.AttachmentS3Region,
+			Bucket:          cfg.AttachmentS3Bucket,
+			AccessKeyID:     cfg.AttachmentS3AccessKey,
+			SecretAccessKey: cfg.AttachmentS3SecretKey,
+		})
+	default:
+		return storage.NewLocalStore(cfg.AttachmentDir)
+	}
+}
+
 // registerPublicRoutes registers routes that don't require authentication
 func registerPublicRoutes(router *gin.Engine, h *handler.Handler) {
 	// Health check endpoint
@@ -179,24 +349,143 @@ func registerPublicRoutes(router *gin.Engine, h *handler.Handler) {
 		auth.POST("/register", h.Register)
 		auth.POST("/login", h.Login)
 	}
+
+	// Calendar feed subscriptions (public - the secret token in the URL authenticates calendar apps)
+	v1.GET("/calendar/:token", h.ServeCalendarFeed)
+
+	// Attachment downloads (public - the signature in the URL authenticates the download until it expires)
+	v1.GET("/attachments/:id/download", h.DownloadAttachment)
 }
 
 // registerProtectedRoutes registers routes that require JWT authentication
-func registerProtectedRoutes(router *gin.Engine, h *handler.Handler, tokenManager *jwt.TokenManager) {
+func registerProtectedRoutes(router *gin.Engine, h *handler.Handler, tokenManager *jwt.TokenManager, auditor middleware.SecurityAuditor, idempotency middleware.IdempotencyStore, adminUserIDs []uint) {
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 
 	// Apply JWT middleware to protected routes
 	protected := v1.Group("")
-	protected.Use(middleware.AuthMiddleware(tokenManager))
+	protected.Use(middleware.AuthMiddleware(tokenManager, middleware.WithSecurityAuditor(auditor)))
+
+	// Make mutating requests with an Idempotency-Key safe to retry
+	if idempotency != nil {
+		protected.Use(middleware.IdempotencyMiddleware(idempotency, h.MaxRequestBodySize()))
+	}
+
+	// Account routes (protected)
+	protected.PUT("/auth/password", h.ChangePassword)
 
 	// Todo routes (protected)
 	todos := protected.Group("/todos")
 	{
 		todos.POST("", h.CreateTodo)
 		todos.GET("", h.GetTodos)
+		todos.GET("/search", h.SearchTodos)
+		todos.POST("/bulk", h.BulkTodos)
+		todos.POST("/quick", h.QuickAddTodo)
+		todos.GET("/export", h.ExportTodos)
+		todos.POST("/import", h.ImportTodos)
+		todos.GET("/trash", h.GetTrash)
+		todos.DELETE("/trash", h.EmptyTrash)
+		todos.DELETE("/trash/:id", h.PurgeTodo)
+		todos.POST("/archive", h.ArchiveCompletedTodos)
+		todos.GET("/dependencies", h.GetDependencyGraph)
+		todos.GET("/archive/policy", h.GetArchivePolicy)
+		todos.PUT("/archive/policy", h.UpdateArchivePolicy)
 		todos.GET("/:id", h.GetTodo)
 		todos.PUT("/:id", h.UpdateTodo)
+		todos.PATCH("/:id", h.PatchTodo)
 		todos.DELETE("/:id", h.DeleteTodo)
+		todos.POST("/:id/restore", h.RestoreTodo)
+		todos.POST("/:id/archive", h.ArchiveTodo)
+		todos.POST("/:id/unarchive", h.UnarchiveTodo)
+		todos.GET("/:id/history", h.GetTodoHistory)
+		todos.GET("/:id/dependencies", h.GetTodoDependencies)
+		todos.POST("/:id/dependencies", h.AddTodoDependency)
+		todos.DELETE("/:id/dependencies/:other_id", h.RemoveTodoDependency)
+		todos.POST("/:id/move", h.MoveTodo)
+		todos.GET("/:id/comments", h.GetTodoComments)
+		todos.POST("/:id/comments", h.AddTodoComment)
+		todos.PUT("/:id/comments/:comment_id", h.UpdateTodoComment)
+		todos.DELETE("/:id/comments/:comment_id", h.DeleteTodoComment)
+		todos.POST("/:id/attachments", h.UploadTodoAttachment)
+		todos.GET("/:id/attachments", h.GetTodoAttachments)
+		todos.GET("/:id/attachments/:attachment_id", h.GetTodoAttachment)
+		todos.DELETE("/:id/attachments/:attachment_id", h.DeleteTodoAttachment)
+	}
+
+	// Real-time event stream (protected)
+	protected.GET("/events", h.StreamEvents)
+
+	// Live collaboration WebSocket (protected; browsers pass the token in the query string)
+	v1.GET("/ws", middleware.AuthMiddleware(tokenManager, middleware.WithSecurityAuditor(auditor), middleware.WithQueryToken("access_token")), h.Live)
+
+	// Webhook routes (protected)
+	webhooks := protected.Group("/webhooks")
+	{
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("", h.GetWebhooks)
+		webhooks.GET("/:id", h.GetWebhook)
+		webhooks.PUT("/:id", h.UpdateWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.GetWebhookDeliveries)
+	}
+
+	// Template routes (protected)
+	templates := protected.Group("/templates")
+	{
+		templates.POST("", h.CreateTemplate)
+		templates.GET("", h.GetTemplates)
+		templates.GET("/:id", h.GetTemplate)
+		templates.PUT("/:id", h.UpdateTemplate)
+		templates.DELETE("/:id", h.DeleteTemplate)
+		templates.POST("/:id/instantiate", h.InstantiateTemplate)
+	}
+
+	// List routes (protected)
+	lists := protected.Group("/lists")
+	{
+		lists.POST("", h.CreateList)
+		lists.GET("", h.GetLists)
+		lists.GET("/:id", h.GetList)
+		lists.PUT("/:id", h.UpdateList)
+		lists.DELETE("/:id", h.DeleteList)
+		lists.GET("/:id/board", h.GetListBoard)
+	}
+
+	// Custom field routes (protected)
+	customFields := protected.Group("/custom-fields")
+	{
+		customFields.POST("", h.CreateCustomField)
+		customFields.GET("", h.GetCustomFields)
+		customFields.GET("/:id", h.GetCustomField)
+		customFields.PUT("/:id", h.UpdateCustomField)
+		customFields.DELETE("/:id", h.DeleteCustomField)
+	}
+
+	// Calendar feed routes (protected)
+	calendar := protected.Group("/calendar")
+	{
+		calendar.GET("/feed", h.GetCalendarFeed)
+		calendar.POST("/feed", h.RegenerateCalendarToken)
+		calendar.DELETE("/feed", h.DisableCalendarFeed)
 	}
-}
\ No newline at end of file
+
+	// CalDAV app password routes (protected)
+	caldav := protected.Group("/caldav")
+	{
+		caldav.GET("/passwords", h.GetCalDAVPasswords)
+		caldav.POST("/passwords", h.CreateCalDAVPassword)
+		caldav.DELETE("/passwords/:id", h.DeleteCalDAVPassword)
+	}
+
+	// Offline sync routes (protected)
+	protected.GET("/sync", h.PullChanges)
+	protected.POST("/sync", h.PushChanges)
+
+	// Admin routes (protected, restricted to ADMIN_USER_IDS)
+	admin := protected.Group("/admin")
+	admin.Use(middleware.AdminMiddleware(adminUserIDs))
+	{
+		admin.GET("/audit-log", h.GetSecurityEvents)
+	}
+}
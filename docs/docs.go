@@ -284,7 +284,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a specific todo by ID, ensuring user ownership",
+                "description": "Replace the editable fields of a specific todo by ID, ensuring user ownership. Omitted title, description, completed and due_at are reset to their defaults, while an omitted state or custom_fields keeps the current value; use PATCH for partial updates. For a todo in a list, a new state must be allowed by the workflow and decides whether the todo is completed; without a state, changing completed moves the todo to the first allowed state that matches",
                 "consumes": [
                     "application/json"
                 ],
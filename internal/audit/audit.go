@@ -0,0 +1,35 @@
+// Package audit provides the building blocks of the security audit log:
+// sinks that events are written to and the client information carried
+// in the request context.
+package audit
+
+import (
+	"context"
+
+	"todo-api-backend/internal/model"
+)
+
+// Sink receives every recorded security event in addition to the database
+type Sink interface {
+	// Write writes a security event to the sink
+	Write(ctx context.Context, event *model.SecurityEvent) error
+}
+
+// Client identifies the client a request originated from
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithClient returns a copy of ctx carrying the client information
+func WithClient(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, clientKey{}, Client{IP: ip, UserAgent: userAgent})
+}
+
+// ClientFromContext returns the client information carried by ctx, if any
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientKey{}).(Client)
+	return client, ok
+}
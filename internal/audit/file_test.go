@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/model"
+)
+
+func TestFileSink_WritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	userID := uint(1)
+	require.NoError(t, sink.Write(context.Background(), &model.SecurityEvent{
+		EventType: model.SecurityEventLogin,
+		Outcome:   model.SecurityOutcomeSuccess,
+		UserID:    &userID,
+	}))
+	require.NoError(t, sink.Write(context.Background(), &model.SecurityEvent{
+		EventType: model.SecurityEventTokenValidation,
+		Outcome:   model.SecurityOutcomeFailure,
+		Reason:    "expired_token",
+	}))
+	require.NoError(t, sink.Close())
+
+	// Reopening appends rather than truncates
+	sink, err = NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), &model.SecurityEvent{EventType: model.SecurityEventRegister}))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []model.SecurityEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event model.SecurityEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, events, 3)
+	assert.Equal(t, model.SecurityEventLogin, events[0].EventType)
+	assert.Equal(t, uint(1), *events[0].UserID)
+	assert.Equal(t, "expired_token", events[1].Reason)
+	assert.Equal(t, model.SecurityEventRegister, events[2].EventType)
+}
+
+func TestClientFromContext(t *testing.T) {
+	_, ok := ClientFromContext(context.Background())
+	assert.False(t, ok)
+
+	client, ok := ClientFromContext(WithClient(context.Background(), "203.0.113.7", "test-agent"))
+	assert.True(t, ok)
+	assert.Equal(t, Client{IP: "203.0.113.7", UserAgent: "test-agent"}, client)
+}
@@ -25,6 +25,52 @@ type Config struct {
 
 	// CORS configuration
 	AllowedOrigins []string `env:"ALLOWED_ORIGINS"`
+
+	// Trash configuration
+	TrashRetentionDays int `env:"TRASH_RETENTION_DAYS"`
+	TrashPurgeInterval int `env:"TRASH_PURGE_INTERVAL_MINUTES"`
+
+	// Archive configuration
+	AutoArchiveInterval int `env:"AUTO_ARCHIVE_INTERVAL_MINUTES"`
+
+	// Idempotency configuration
+	IdempotencyTTL           int `env:"IDEMPOTENCY_TTL_HOURS"`
+	IdempotencyPurgeInterval int `env:"IDEMPOTENCY_PURGE_INTERVAL_MINUTES"`
+
+	// Real-time event configuration
+	EventReplayBuffer int    `env:"EVENT_REPLAY_BUFFER"`
+	EventHeartbeat    int    `env:"EVENT_HEARTBEAT_SECONDS"`
+	RealtimeBroker    string `env:"REALTIME_BROKER"`
+
+	// Webhook configuration
+	WebhookDispatchInterval int `env:"WEBHOOK_DISPATCH_INTERVAL_SECONDS"`
+	WebhookMaxAttempts      int `env:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookDisableAfter     int `env:"WEBHOOK_DISABLE_AFTER_FAILURES"`
+
+	// Domain event outbox configuration
+	OutboxRelayInterval int    `env:"OUTBOX_RELAY_INTERVAL_SECONDS"`
+	OutboxPublisher     string `env:"OUTBOX_PUBLISHER"`
+	OutboxPublisherURL  string `env:"OUTBOX_PUBLISHER_URL"`
+	OutboxSubjectPrefix string `env:"OUTBOX_SUBJECT_PREFIX"`
+
+	// Security audit configuration
+	AuditLogFile string `env:"AUDIT_LOG_FILE"`
+	AdminUserIDs []uint `env:"ADMIN_USER_IDS"`
+
+	// Attachment configuration
+	AttachmentStorage       string   `env:"ATTACHMENT_STORAGE"`
+	AttachmentDir           string   `env:"ATTACHMENT_DIR"`
+	AttachmentS3Endpoint    string   `env:"ATTACHMENT_S3_ENDPOINT"`
+	AttachmentS3Region      string   `env:"ATTACHMENT_S3_REGION"`
+	AttachmentS3Bucket      string   `env:"ATTACHMENT_S3_BUCKET"`
+	AttachmentS3AccessKey   string   `env:"ATTACHMENT_S3_ACCESS_KEY_ID"`
+	AttachmentS3SecretKey   string   `env:"ATTACHMENT_S3_SECRET_ACCESS_KEY"`
+	AttachmentMaxSizeMB     int      `env:"ATTACHMENT_MAX_SIZE_MB"`
+	AttachmentQuotaMB       int      `env:"ATTACHMENT_QUOTA_MB"`
+	AttachmentAllowedTypes  []string `env:"ATTACHMENT_ALLOWED_TYPES"`
+	AttachmentURLTTL        int      `env:"ATTACHMENT_URL_TTL_MINUTES"`
+	AttachmentURLSecret     string   `env:"ATTACHMENT_URL_SECRET"`
+	AttachmentSweepInterval int      `env:"ATTACHMENT_SWEEP_INTERVAL_MINUTES"`
 }
 
 // Load loads configuration from environment variables with defaults
@@ -41,6 +87,44 @@ func Load() (*Config, error) {
 		JWTSecret:      os.Getenv("JWT_SECRET"), // No default for JWT_SECRET - must be explicitly set
 		JWTExpiration:  getEnvIntWithDefault("JWT_EXPIRATION", 24),
 		AllowedOrigins: getEnvSliceWithDefault("ALLOWED_ORIGINS", []string{"*"}),
+
+		TrashRetentionDays: getEnvIntWithDefault("TRASH_RETENTION_DAYS", 30),
+		TrashPurgeInterval: getEnvIntWithDefault("TRASH_PURGE_INTERVAL_MINUTES", 60),
+
+		AutoArchiveInterval: getEnvIntWithDefault("AUTO_ARCHIVE_INTERVAL_MINUTES", 60),
+
+		IdempotencyTTL:           getEnvIntWithDefault("IDEMPOTENCY_TTL_HOURS", 24),
+		IdempotencyPurgeInterval: getEnvIntWithDefault("IDEMPOTENCY_PURGE_INTERVAL_MINUTES", 60),
+
+		EventReplayBuffer: getEnvIntWithDefault("EVENT_REPLAY_BUFFER", 1000),
+		EventHeartbeat:    getEnvIntWithDefault("EVENT_HEARTBEAT_SECONDS", 15),
+		RealtimeBroker:    getEnvWithDefault("REALTIME_BROKER", "memory"),
+
+		WebhookDispatchInterval: getEnvIntWithDefault("WEBHOOK_DISPATCH_INTERVAL_SECONDS", 10),
+		WebhookMaxAttempts:      getEnvIntWithDefault("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookDisableAfter:     getEnvIntWithDefault("WEBHOOK_DISABLE_AFTER_FAILURES", 20),
+
+		OutboxRelayInterval: getEnvIntWithDefault("OUTBOX_RELAY_INTERVAL_SECONDS", 5),
+		OutboxPublisher:     getEnvWithDefault("OUTBOX_PUBLISHER", "log"),
+		OutboxPublisherURL:  os.Getenv("OUTBOX_PUBLISHER_URL"),
+		OutboxSubjectPrefix: getEnvWithDefault("OUTBOX_SUBJECT_PREFIX", "todoapp"),
+
+		AuditLogFile: os.Getenv("AUDIT_LOG_FILE"), // Empty disables the file sink
+		AdminUserIDs: getEnvUintSliceWithDefault("ADMIN_USER_IDS", nil),
+
+		AttachmentStorage:       getEnvWithDefault("ATTACHMENT_STORAGE", "local"),
+		AttachmentDir:           getEnvWithDefault("ATTACHMENT_DIR", "data/attachments"),
+		AttachmentS3Endpoint:    os.Getenv("ATTACHMENT_S3_ENDPOINT"),
+		AttachmentS3Region:      getEnvWithDefault("ATTACHMENT_S3_REGION", "us-east-1"),
+		AttachmentS3Bucket:      os.Getenv("ATTACHMENT_S3_BUCKET"),
+		AttachmentS3AccessKey:   os.Getenv("ATTACHMENT_S3_ACCESS_KEY_ID"),
+		AttachmentS3SecretKey:   os.Getenv("ATTACHMENT_S3_SECRET_ACCESS_KEY"),
+		AttachmentMaxSizeMB:     getEnvIntWithDefault("ATTACHMENT_MAX_SIZE_MB", 10),
+		AttachmentQuotaMB:       getEnvIntWithDefault("ATTACHMENT_QUOTA_MB", 100),
+		AttachmentAllowedTypes:  getEnvSliceWithDefault("ATTACHMENT_ALLOWED_TYPES", nil), // Empty allows the default types
+		AttachmentURLTTL:        getEnvIntWithDefault("ATTACHMENT_URL_TTL_MINUTES", 15),
+		AttachmentURLSecret:     os.Getenv("ATTACHMENT_URL_SECRET"), // Empty falls back to JWT_SECRET
+		AttachmentSweepInterval: getEnvIntWithDefault("ATTACHMENT_SWEEP_INTERVAL_MINUTES", 60),
 	}
 
 	// Validate required configuration
@@ -74,6 +158,89 @@ func (c *Config) Validate() error {
 		errors = append(errors, "JWT_EXPIRATION must be greater than 0")
 	}
 
+	// Validate trash retention (0 disables automatic purging)
+	if c.TrashRetentionDays < 0 {
+		errors = append(errors, "TRASH_RETENTION_DAYS must not be negative")
+	}
+	if c.TrashRetentionDays > 0 && c.TrashPurgeInterval <= 0 {
+		errors = append(errors, "TRASH_PURGE_INTERVAL_MINUTES must be greater than 0")
+	}
+
+	// Validate auto-archive interval (0 disables the auto-archive job)
+	if c.AutoArchiveInterval < 0 {
+		errors = append(errors, "AUTO_ARCHIVE_INTERVAL_MINUTES must not be negative")
+	}
+
+	// Validate idempotency key retention (0 disables idempotency keys)
+	if c.IdempotencyTTL < 0 {
+		errors = append(errors, "IDEMPOTENCY_TTL_HOURS must not be negative")
+	}
+	if c.IdempotencyTTL > 0 && c.IdempotencyPurgeInterval <= 0 {
+		errors = append(errors, "IDEMPOTENCY_PURGE_INTERVAL_MINUTES must be greater than 0")
+	}
+
+	// Validate real-time events (0 disables stream resumption and heartbeats respectively)
+	if c.EventReplayBuffer < 0 {
+		errors = append(errors, "EVENT_REPLAY_BUFFER must not be negative")
+	}
+	if c.EventHeartbeat < 0 {
+		errors = append(errors, "EVENT_HEARTBEAT_SECONDS must not be negative")
+	}
+	validBrokers := []string{"memory", "postgres"}
+	if c.RealtimeBroker != "" && !contains(validBrokers, strings.ToLower(c.RealtimeBroker)) {
+		errors = append(errors, "REALTIME_BROKER must be one of: memory, postgres")
+	}
+
+	// Validate webhooks (0 disables delivery and automatic disabling respectively)
+	if c.WebhookDispatchInterval < 0 {
+		errors = append(errors, "WEBHOOK_DISPATCH_INTERVAL_SECONDS must not be negative")
+	}
+	if c.WebhookDispatchInterval > 0 && c.WebhookMaxAttempts <= 0 {
+		errors = append(errors, "WEBHOOK_MAX_ATTEMPTS must be greater than 0")
+	}
+	if c.WebhookDisableAfter < 0 {
+		errors = append(errors, "WEBHOOK_DISABLE_AFTER_FAILURES must not be negative")
+	}
+
+	// Validate the domain event outbox (0 disables domain events)
+	if c.OutboxRelayInterval < 0 {
+		errors = append(errors, "OUTBOX_RELAY_INTERVAL_SECONDS must not be negative")
+	}
+	validPublishers := []string{"log", "http", "nats"}
+	if c.OutboxPublisher != "" && !contains(validPublishers, strings.ToLower(c.OutboxPublisher)) {
+		errors = append(errors, "OUTBOX_PUBLISHER must be one of: log, http, nats")
+	}
+	if c.OutboxRelayInterval > 0 && c.OutboxPublisherURL == "" && c.OutboxPublisher != "" && strings.ToLower(c.OutboxPublisher) != "log" {
+		errors = append(errors, "OUTBOX_PUBLISHER_URL is required for the http and nats publishers")
+	}
+
+	// Validate attachments (an empty storage disables attachments, a sweep interval of 0 disables the sweeper)
+	validStorages := []string{"local", "s3"}
+	storage := strings.ToLower(c.AttachmentStorage)
+	if storage != "" && !contains(validStorages, storage) {
+		errors = append(errors, "ATTACHMENT_STORAGE must be one of: local, s3")
+	}
+	if storage == "local" && c.AttachmentDir == "" {
+		errors = append(errors, "ATTACHMENT_DIR is required for local attachment storage")
+	}
+	if storage == "s3" && (c.AttachmentS3Endpoint == "" || c.AttachmentS3Bucket == "") {
+		errors = append(errors, "ATTACHMENT_S3_ENDPOINT and ATTACHMENT_S3_BUCKET are required for s3 attachment storage")
+	}
+	if storage != "" {
+		if c.AttachmentMaxSizeMB <= 0 {
+			errors = append(errors, "ATTACHMENT_MAX_SIZE_MB must be greater than 0")
+		}
+		if c.AttachmentQuotaMB < c.AttachmentMaxSizeMB {
+			errors = append(errors, "ATTACHMENT_QUOTA_MB must not be less than ATTACHMENT_MAX_SIZE_MB")
+		}
+		if c.AttachmentURLTTL <= 0 {
+			errors = append(errors, "ATTACHMENT_URL_TTL_MINUTES must be greater than 0")
+		}
+	}
+	if c.AttachmentSweepInterval < 0 {
+		errors = append(errors, "ATTACHMENT_SWEEP_INTERVAL_MINUTES must not be negative")
+	}
+
 	// Validate port
 	if c.Port == "" {
 		errors = append(errors, "PORT is required")
@@ -103,6 +270,11 @@ func (c *Config) IsDevelopment() bool {
 	return strings.ToLower(c.Environment) == "development"
 }
 
+// UsesPostgresBroker returns true if live messages are shared between instances through Postgres
+func (c *Config) UsesPostgresBroker() bool {
+	return strings.ToLower(c.RealtimeBroker) == "postgres"
+}
+
 // IsProduction returns true if the environment is production
 func (c *Config) IsProduction() bool {
 	return strings.ToLower(c.Environment) == "production"
@@ -134,6 +306,23 @@ func getEnvSliceWithDefault(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvUintSliceWithDefault gets an environment variable as a slice of
+// unsigned integers, skipping invalid entries, with a default value
+func getEnvUintSliceWithDefault(key string, defaultValue []uint) []uint {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var values []uint
+	for _, item := range strings.Split(value, ",") {
+		if n, err := strconv.ParseUint(strings.TrimSpace(item), 10, 0); err == nil && n > 0 {
+			values = append(values, uint(n))
+		}
+	}
+	return values
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -142,4 +331,4 @@ func contains(slice []string, item string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
@@ -29,6 +29,34 @@ func TestLoad(t *testing.T) {
 				JWTSecret:      "test-secret-key-that-is-long-enough",
 				JWTExpiration:  24,
 				AllowedOrigins: []string{"*"},
+
+				TrashRetentionDays: 30,
+				TrashPurgeInterval: 60,
+
+				AutoArchiveInterval: 60,
+
+				IdempotencyTTL:           24,
+				IdempotencyPurgeInterval: 60,
+
+				EventReplayBuffer: 1000,
+				EventHeartbeat:    15,
+				RealtimeBroker:    "memory",
+
+				WebhookDispatchInterval: 10,
+				WebhookMaxAttempts:      8,
+				WebhookDisableAfter:     20,
+
+				OutboxRelayInterval: 5,
+				OutboxPublisher:     "log",
+				OutboxSubjectPrefix: "todoapp",
+
+				AttachmentStorage:       "local",
+				AttachmentDir:           "data/attachments",
+				AttachmentS3Region:      "us-east-1",
+				AttachmentMaxSizeMB:     10,
+				AttachmentQuotaMB:       100,
+				AttachmentURLTTL:        15,
+				AttachmentSweepInterval: 60,
 			},
 		},
 		{
@@ -41,6 +69,35 @@ func TestLoad(t *testing.T) {
 				"JWT_SECRET":      "super-secret-production-key-that-is-very-long",
 				"JWT_EXPIRATION":  "48",
 				"ALLOWED_ORIGINS": "https://example.com,https://app.example.com",
+				"AUDIT_LOG_FILE":  "/var/log/todoapi/audit.log",
+				"ADMIN_USER_IDS":  "1, 42,not-an-id",
+
+				"IDEMPOTENCY_TTL_HOURS": "72",
+
+				"EVENT_REPLAY_BUFFER":     "0",
+				"EVENT_HEARTBEAT_SECONDS": "30",
+				"REALTIME_BROKER":         "postgres",
+
+				"WEBHOOK_DISPATCH_INTERVAL_SECONDS": "0",
+				"WEBHOOK_MAX_ATTEMPTS":              "5",
+				"WEBHOOK_DISABLE_AFTER_FAILURES":    "0",
+
+				"OUTBOX_RELAY_INTERVAL_SECONDS": "1",
+				"OUTBOX_PUBLISHER":              "nats",
+				"OUTBOX_PUBLISHER_URL":          "nats://events:4222",
+				"OUTBOX_SUBJECT_PREFIX":         "prod.todos",
+
+				"ATTACHMENT_STORAGE":                "s3",
+				"ATTACHMENT_S3_ENDPOINT":            "https://s3.eu-central-1.amazonaws.com",
+				"ATTACHMENT_S3_REGION":              "eu-central-1",
+				"ATTACHMENT_S3_BUCKET":              "todo-attachments",
+				"ATTACHMENT_S3_ACCESS_KEY_ID":       "AKIAEXAMPLE",
+				"ATTACHMENT_S3_SECRET_ACCESS_KEY":   "secret",
+				"ATTACHMENT_MAX_SIZE_MB":            "25",
+				"ATTACHMENT_QUOTA_MB":               "1024",
+				"ATTACHMENT_ALLOWED_TYPES":          "image/png,image/jpeg",
+				"ATTACHMENT_URL_TTL_MINUTES":        "5",
+				"ATTACHMENT_SWEEP_INTERVAL_MINUTES": "0",
 			},
 			expectError: false,
 			expected: &Config{
@@ -51,6 +108,43 @@ func TestLoad(t *testing.T) {
 				JWTSecret:      "super-secret-production-key-that-is-very-long",
 				JWTExpiration:  48,
 				AllowedOrigins: []string{"https://example.com", "https://app.example.com"},
+
+				TrashRetentionDays: 30,
+				TrashPurgeInterval: 60,
+
+				AutoArchiveInterval: 60,
+
+				IdempotencyTTL:           72,
+				IdempotencyPurgeInterval: 60,
+
+				EventReplayBuffer: 0,
+				EventHeartbeat:    30,
+				RealtimeBroker:    "postgres",
+
+				WebhookDispatchInterval: 0,
+				WebhookMaxAttempts:      5,
+				WebhookDisableAfter:     0,
+
+				OutboxRelayInterval: 1,
+				OutboxPublisher:     "nats",
+				OutboxPublisherURL:  "nats://events:4222",
+				OutboxSubjectPrefix: "prod.todos",
+
+				AuditLogFile: "/var/log/todoapi/audit.log",
+				AdminUserIDs: []uint{1, 42},
+
+				AttachmentStorage:       "s3",
+				AttachmentDir:           "data/attachments",
+				AttachmentS3Endpoint:    "https://s3.eu-central-1.amazonaws.com",
+				AttachmentS3Region:      "eu-central-1",
+				AttachmentS3Bucket:      "todo-attachments",
+				AttachmentS3AccessKey:   "AKIAEXAMPLE",
+				AttachmentS3SecretKey:   "secret",
+				AttachmentMaxSizeMB:     25,
+				AttachmentQuotaMB:       1024,
+				AttachmentAllowedTypes:  []string{"image/png", "image/jpeg"},
+				AttachmentURLTTL:        5,
+				AttachmentSweepInterval: 0,
 			},
 		},
 		{
@@ -96,6 +190,35 @@ func TestLoad(t *testing.T) {
 			assert.Equal(t, tt.expected.JWTSecret, config.JWTSecret)
 			assert.Equal(t, tt.expected.JWTExpiration, config.JWTExpiration)
 			assert.Equal(t, tt.expected.AllowedOrigins, config.AllowedOrigins)
+			assert.Equal(t, tt.expected.TrashRetentionDays, config.TrashRetentionDays)
+			assert.Equal(t, tt.expected.TrashPurgeInterval, config.TrashPurgeInterval)
+			assert.Equal(t, tt.expected.AutoArchiveInterval, config.AutoArchiveInterval)
+			assert.Equal(t, tt.expected.IdempotencyTTL, config.IdempotencyTTL)
+			assert.Equal(t, tt.expected.IdempotencyPurgeInterval, config.IdempotencyPurgeInterval)
+			assert.Equal(t, tt.expected.EventReplayBuffer, config.EventReplayBuffer)
+			assert.Equal(t, tt.expected.EventHeartbeat, config.EventHeartbeat)
+			assert.Equal(t, tt.expected.RealtimeBroker, config.RealtimeBroker)
+			assert.Equal(t, tt.expected.WebhookDispatchInterval, config.WebhookDispatchInterval)
+			assert.Equal(t, tt.expected.WebhookMaxAttempts, config.WebhookMaxAttempts)
+			assert.Equal(t, tt.expected.WebhookDisableAfter, config.WebhookDisableAfter)
+			assert.Equal(t, tt.expected.OutboxRelayInterval, config.OutboxRelayInterval)
+			assert.Equal(t, tt.expected.OutboxPublisher, config.OutboxPublisher)
+			assert.Equal(t, tt.expected.OutboxPublisherURL, config.OutboxPublisherURL)
+			assert.Equal(t, tt.expected.OutboxSubjectPrefix, config.OutboxSubjectPrefix)
+			assert.Equal(t, tt.expected.AuditLogFile, config.AuditLogFile)
+			assert.Equal(t, tt.expected.AdminUserIDs, config.AdminUserIDs)
+			assert.Equal(t, tt.expected.AttachmentStorage, config.AttachmentStorage)
+			assert.Equal(t, tt.expected.AttachmentDir, config.AttachmentDir)
+			assert.Equal(t, tt.expected.AttachmentS3Endpoint, config.AttachmentS3Endpoint)
+			assert.Equal(t, tt.expected.AttachmentS3Region, config.AttachmentS3Region)
+			assert.Equal(t, tt.expected.AttachmentS3Bucket, config.AttachmentS3Bucket)
+			assert.Equal(t, tt.expected.AttachmentS3AccessKey, config.AttachmentS3AccessKey)
+			assert.Equal(t, tt.expected.AttachmentS3SecretKey, config.AttachmentS3SecretKey)
+			assert.Equal(t, tt.expected.AttachmentMaxSizeMB, config.AttachmentMaxSizeMB)
+			assert.Equal(t, tt.expected.AttachmentQuotaMB, config.AttachmentQuotaMB)
+			assert.Equal(t, tt.expected.AttachmentAllowedTypes, config.AttachmentAllowedTypes)
+			assert.Equal(t, tt.expected.AttachmentURLTTL, config.AttachmentURLTTL)
+			assert.Equal(t, tt.expected.AttachmentSweepInterval, config.AttachmentSweepInterval)
 
 			// Clean up
 			clearEnv()
@@ -212,6 +335,242 @@ func TestConfig_Validate(t *testing.T) {
 			expectError: true,
 			errorMsg:    "ENVIRONMENT must be one of: development, staging, production",
 		},
+		{
+			name: "negative trash retention",
+			config: &Config{
+				Port:               "8080",
+				Environment:        "development",
+				LogLevel:           "info",
+				DatabaseURL:        "postgres://localhost/test",
+				JWTSecret:          "test-secret",
+				JWTExpiration:      24,
+				TrashRetentionDays: -1,
+			},
+			expectError: true,
+			errorMsg:    "TRASH_RETENTION_DAYS must not be negative",
+		},
+		{
+			name: "trash retention without purge interval",
+			config: &Config{
+				Port:               "8080",
+				Environment:        "development",
+				LogLevel:           "info",
+				DatabaseURL:        "postgres://localhost/test",
+				JWTSecret:          "test-secret",
+				JWTExpiration:      24,
+				TrashRetentionDays: 30,
+				TrashPurgeInterval: 0,
+			},
+			expectError: true,
+			errorMsg:    "TRASH_PURGE_INTERVAL_MINUTES must be greater than 0",
+		},
+		{
+			name: "negative idempotency TTL",
+			config: &Config{
+				Port:           "8080",
+				Environment:    "development",
+				LogLevel:       "info",
+				DatabaseURL:    "postgres://localhost/test",
+				JWTSecret:      "test-secret",
+				JWTExpiration:  24,
+				IdempotencyTTL: -1,
+			},
+			expectError: true,
+			errorMsg:    "IDEMPOTENCY_TTL_HOURS must not be negative",
+		},
+		{
+			name: "idempotency TTL without purge interval",
+			config: &Config{
+				Port:                     "8080",
+				Environment:              "development",
+				LogLevel:                 "info",
+				DatabaseURL:              "postgres://localhost/test",
+				JWTSecret:                "test-secret",
+				JWTExpiration:            24,
+				IdempotencyTTL:           24,
+				IdempotencyPurgeInterval: 0,
+			},
+			expectError: true,
+			errorMsg:    "IDEMPOTENCY_PURGE_INTERVAL_MINUTES must be greater than 0",
+		},
+		{
+			name: "negative event replay buffer",
+			config: &Config{
+				Port:              "8080",
+				Environment:       "development",
+				LogLevel:          "info",
+				DatabaseURL:       "postgres://localhost/test",
+				JWTSecret:         "test-secret",
+				JWTExpiration:     24,
+				EventReplayBuffer: -1,
+			},
+			expectError: true,
+			errorMsg:    "EVENT_REPLAY_BUFFER must not be negative",
+		},
+		{
+			name: "negative event heartbeat",
+			config: &Config{
+				Port:           "8080",
+				Environment:    "development",
+				LogLevel:       "info",
+				DatabaseURL:    "postgres://localhost/test",
+				JWTSecret:      "test-secret",
+				JWTExpiration:  24,
+				EventHeartbeat: -1,
+			},
+			expectError: true,
+			errorMsg:    "EVENT_HEARTBEAT_SECONDS must not be negative",
+		},
+		{
+			name: "invalid realtime broker",
+			config: &Config{
+				Port:           "8080",
+				Environment:    "development",
+				LogLevel:       "info",
+				DatabaseURL:    "postgres://localhost/test",
+				JWTSecret:      "test-secret",
+				JWTExpiration:  24,
+				RealtimeBroker: "redis",
+			},
+			expectError: true,
+			errorMsg:    "REALTIME_BROKER must be one of: memory, postgres",
+		},
+		{
+			name: "negative webhook dispatch interval",
+			config: &Config{
+				Port:                    "8080",
+				Environment:             "development",
+				LogLevel:                "info",
+				DatabaseURL:             "postgres://localhost/test",
+				JWTSecret:               "test-secret",
+				JWTExpiration:           24,
+				WebhookDispatchInterval: -1,
+			},
+			expectError: true,
+			errorMsg:    "WEBHOOK_DISPATCH_INTERVAL_SECONDS must not be negative",
+		},
+		{
+			name: "webhook dispatch without attempts",
+			config: &Config{
+				Port:                    "8080",
+				Environment:             "development",
+				LogLevel:                "info",
+				DatabaseURL:             "postgres://localhost/test",
+				JWTSecret:               "test-secret",
+				JWTExpiration:           24,
+				WebhookDispatchInterval: 10,
+				WebhookMaxAttempts:      0,
+			},
+			expectError: true,
+			errorMsg:    "WEBHOOK_MAX_ATTEMPTS must be greater than 0",
+		},
+		{
+			name: "negative webhook disable threshold",
+			config: &Config{
+				Port:                "8080",
+				Environment:         "development",
+				LogLevel:            "info",
+				DatabaseURL:         "postgres://localhost/test",
+				JWTSecret:           "test-secret",
+				JWTExpiration:       24,
+				WebhookDisableAfter: -1,
+			},
+			expectError: true,
+			errorMsg:    "WEBHOOK_DISABLE_AFTER_FAILURES must not be negative",
+		},
+		{
+			name: "negative outbox relay interval",
+			config: &Config{
+				Port:                "8080",
+				Environment:         "development",
+				LogLevel:            "info",
+				DatabaseURL:         "postgres://localhost/test",
+				JWTSecret:           "test-secret",
+				JWTExpiration:       24,
+				OutboxRelayInterval: -1,
+			},
+			expectError: true,
+			errorMsg:    "OUTBOX_RELAY_INTERVAL_SECONDS must not be negative",
+		},
+		{
+			name: "invalid outbox publisher",
+			config: &Config{
+				Port:            "8080",
+				Environment:     "development",
+				LogLevel:        "info",
+				DatabaseURL:     "postgres://localhost/test",
+				JWTSecret:       "test-secret",
+				JWTExpiration:   24,
+				OutboxPublisher: "kafka",
+			},
+			expectError: true,
+			errorMsg:    "OUTBOX_PUBLISHER must be one of: log, http, nats",
+		},
+		{
+			name: "outbox publisher without url",
+			config: &Config{
+				Port:                "8080",
+				Environment:         "development",
+				LogLevel:            "info",
+				DatabaseURL:         "postgres://localhost/test",
+				JWTSecret:           "test-secret",
+				JWTExpiration:       24,
+				OutboxRelayInterval: 5,
+				OutboxPublisher:     "http",
+			},
+			expectError: true,
+			errorMsg:    "OUTBOX_PUBLISHER_URL is required for the http and nats publishers",
+		},
+		{
+			name: "invalid attachment storage",
+			config: &Config{
+				Port:              "8080",
+				Environment:       "development",
+				LogLevel:          "info",
+				DatabaseURL:       "postgres://localhost/test",
+				JWTSecret:         "test-secret",
+				JWTExpiration:     24,
+				AttachmentStorage: "gcs",
+			},
+			expectError: true,
+			errorMsg:    "ATTACHMENT_STORAGE must be one of: local, s3",
+		},
+		{
+			name: "s3 attachment storage without bucket",
+			config: &Config{
+				Port:                 "8080",
+				Environment:          "development",
+				LogLevel:             "info",
+				DatabaseURL:          "postgres://localhost/test",
+				JWTSecret:            "test-secret",
+				JWTExpiration:        24,
+				AttachmentStorage:    "s3",
+				AttachmentS3Endpoint: "http://localhost:9000",
+				AttachmentMaxSizeMB:  10,
+				AttachmentQuotaMB:    100,
+				AttachmentURLTTL:     15,
+			},
+			expectError: true,
+			errorMsg:    "ATTACHMENT_S3_ENDPOINT and ATTACHMENT_S3_BUCKET are required for s3 attachment storage",
+		},
+		{
+			name: "attachment quota below size limit",
+			config: &Config{
+				Port:                "8080",
+				Environment:         "development",
+				LogLevel:            "info",
+				DatabaseURL:         "postgres://localhost/test",
+				JWTSecret:           "test-secret",
+				JWTExpiration:       24,
+				AttachmentStorage:   "local",
+				AttachmentDir:       "data/attachments",
+				AttachmentMaxSizeMB: 10,
+				AttachmentQuotaMB:   5,
+				AttachmentURLTTL:    15,
+			},
+			expectError: true,
+			errorMsg:    "ATTACHMENT_QUOTA_MB must not be less than ATTACHMENT_MAX_SIZE_MB",
+		},
 	}
 
 	for _, tt := range tests {
@@ -277,8 +636,18 @@ func clearEnv() {
 	envVars := []string{
 		"PORT", "ENVIRONMENT", "LOG_LEVEL", "DATABASE_URL",
 		"JWT_SECRET", "JWT_EXPIRATION", "ALLOWED_ORIGINS",
+		"TRASH_RETENTION_DAYS", "TRASH_PURGE_INTERVAL_MINUTES",
+		"AUTO_ARCHIVE_INTERVAL_MINUTES",
+		"IDEMPOTENCY_TTL_HOURS", "IDEMPOTENCY_PURGE_INTERVAL_MINUTES",
+		"EVENT_REPLAY_BUFFER", "EVENT_HEARTBEAT_SECONDS", "REALTIME_BROKER",
+		"WEBHOOK_DISPATCH_INTERVAL_SECONDS", "WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_DISABLE_AFTER_FAILURES",
+		"OUTBOX_RELAY_INTERVAL_SECONDS", "OUTBOX_PUBLISHER", "OUTBOX_PUBLISHER_URL", "OUTBOX_SUBJECT_PREFIX",
+		"AUDIT_LOG_FILE", "ADMIN_USER_IDS",
+		"ATTACHMENT_STORAGE", "ATTACHMENT_DIR", "ATTACHMENT_S3_ENDPOINT", "ATTACHMENT_S3_REGION", "ATTACHMENT_S3_BUCKET",
+		"ATTACHMENT_S3_ACCESS_KEY_ID", "ATTACHMENT_S3_SECRET_ACCESS_KEY", "ATTACHMENT_MAX_SIZE_MB", "ATTACHMENT_QUOTA_MB",
+		"ATTACHMENT_ALLOWED_TYPES", "ATTACHMENT_URL_TTL_MINUTES", "ATTACHMENT_URL_SECRET", "ATTACHMENT_SWEEP_INTERVAL_MINUTES",
 	}
 	for _, env := range envVars {
 		os.Unsetenv(env)
 	}
-}
\ No newline at end of file
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	_ "embed"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -18,15 +19,43 @@ type Migration struct {
 	SQL     string
 }
 
+// todoSearchMigration adds the generated tsvector column and GIN index used by
+// full-text search. GORM cannot express either, so AutoMigrate applies it directly.
+//
+//go:embed migrations/006_todo_search.sql
+var todoSearchMigration string
+
 // AutoMigrate runs GORM auto-migration for all models
 func AutoMigrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&model.User{},
 		&model.Todo{},
+		&model.TodoHistory{},
+		&model.SecurityEvent{},
+		&model.IdempotencyRecord{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.OutboxMessage{},
+		&model.CalendarFeed{},
+		&model.CalDAVPassword{},
+		&model.CalDAVResource{},
+		&model.TodoTemplate{},
+		&model.TodoDependency{},
+		&model.TodoList{},
+		&model.CustomField{},
+		&model.TodoComment{},
+		&model.Attachment{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate database: %w", err)
 	}
+
+	// Full-text search is PostgreSQL-only; other databases use the LIKE fallback
+	if db.Dialector.Name() == "postgres" {
+		if err := db.Exec(todoSearchMigration).Error; err != nil {
+			return fmt.Errorf("failed to migrate todo search: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -146,4 +175,4 @@ func executeMigration(db *gorm.DB, migration Migration) error {
 
 	fmt.Printf("Applied migration: %s_%s\n", migration.Version, migration.Name)
 	return nil
-}
\ No newline at end of file
+}
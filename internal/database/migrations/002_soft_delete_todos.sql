@@ -0,0 +1,6 @@
+-- Soft deletion for todos
+-- Deleted todos are kept in the trash until restored or purged
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos(deleted_at);
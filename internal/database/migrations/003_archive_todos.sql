@@ -0,0 +1,11 @@
+-- Archiving of completed todos
+-- Archived todos are hidden from the default listing but kept separate from completion
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_archived_at ON todos(archived_at);
+CREATE INDEX IF NOT EXISTS idx_todos_user_id_archived_at ON todos(user_id, archived_at);
+
+-- Per-user auto-archive policy (0 disables it)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auto_archive_days INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,30 @@
+-- Append-only change history for todos
+-- Entries are kept after the todo is purged, so there is no foreign key to todos
+
+CREATE TABLE IF NOT EXISTS todo_history (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    actor_id INTEGER NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_history_todo_id ON todo_history(todo_id);
+CREATE INDEX IF NOT EXISTS idx_todo_history_user_id ON todo_history(user_id);
+CREATE INDEX IF NOT EXISTS idx_todo_history_created_at ON todo_history(created_at);
+
+-- Reject updates and deletes so the history stays append-only
+CREATE OR REPLACE FUNCTION prevent_todo_history_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'todo_history is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS todo_history_append_only ON todo_history;
+CREATE TRIGGER todo_history_append_only
+    BEFORE UPDATE OR DELETE ON todo_history
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_todo_history_modification();
@@ -0,0 +1,32 @@
+-- Append-only security audit log for authentication events
+-- Events are kept after the user is deleted, so there is no foreign key to users
+
+CREATE TABLE IF NOT EXISTS security_audit_log (
+    id SERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    user_id INTEGER,
+    email VARCHAR(255),
+    ip VARCHAR(64),
+    user_agent VARCHAR(512),
+    reason VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_audit_log_event_type ON security_audit_log(event_type);
+CREATE INDEX IF NOT EXISTS idx_security_audit_log_user_id ON security_audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_security_audit_log_created_at ON security_audit_log(created_at);
+
+-- Reject updates and deletes so the audit log stays append-only
+CREATE OR REPLACE FUNCTION prevent_security_audit_log_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'security_audit_log is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS security_audit_log_append_only ON security_audit_log;
+CREATE TRIGGER security_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON security_audit_log
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_security_audit_log_modification();
@@ -0,0 +1,4 @@
+-- Optimistic concurrency control for todos
+-- Every write increments the version, which is exposed to clients as the ETag
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
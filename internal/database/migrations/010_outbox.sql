@@ -0,0 +1,22 @@
+-- Transactional outbox
+-- Domain events are written in the transaction of the todo or user change
+-- that caused them and published by the relay worker once committed
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id INTEGER NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(512),
+    next_attempt_at TIMESTAMP NOT NULL,
+    published_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_aggregate ON outbox_messages(aggregate_type, aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published_at ON outbox_messages(published_at);
+
+-- The relay only publishes the oldest unpublished message of every aggregate
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_unpublished ON outbox_messages(aggregate_type, aggregate_id, id) WHERE published_at IS NULL;
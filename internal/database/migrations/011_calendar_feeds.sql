@@ -0,0 +1,15 @@
+-- Due dates and calendar feeds
+-- Todos with a due date are published to calendar apps through a per-user
+-- feed URL; only a hash of the secret token in the URL is stored
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS due_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_due_at ON todos(due_at);
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feeds_token_hash ON calendar_feeds(token_hash);
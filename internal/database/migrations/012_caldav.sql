@@ -0,0 +1,26 @@
+-- CalDAV access
+-- Task apps sign in with app passwords, of which only a hash is stored.
+-- Todos created through CalDAV keep the resource name and UID chosen by the
+-- client, so that the app finds them again at the same URL.
+
+CREATE TABLE IF NOT EXISTS caldav_passwords (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(64) NOT NULL,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_caldav_passwords_user_id ON caldav_passwords(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_caldav_passwords_password_hash ON caldav_passwords(password_hash);
+
+CREATE TABLE IF NOT EXISTS caldav_resources (
+    todo_id INTEGER PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    uid VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_caldav_resources_user_name ON caldav_resources(user_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_caldav_resources_user_uid ON caldav_resources(user_id, uid);
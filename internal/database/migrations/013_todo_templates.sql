@@ -0,0 +1,16 @@
+-- Todo templates
+-- A template stores a tree of todos as JSON; instantiating it creates a todo
+-- for every item with its placeholders filled in and due dates offset from
+-- a start date
+
+CREATE TABLE IF NOT EXISTS todo_templates (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description VARCHAR(1000),
+    items JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_templates_user_id ON todo_templates(user_id);
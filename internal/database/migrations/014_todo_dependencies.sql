@@ -0,0 +1,16 @@
+-- Todo dependencies
+-- A dependency records that a todo cannot start until another todo of the
+-- same user is completed; cycles are rejected when a dependency is added
+
+CREATE TABLE IF NOT EXISTS todo_dependencies (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    blocked_by_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (todo_id <> blocked_by_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_dependencies_user_id ON todo_dependencies(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_dependencies_pair ON todo_dependencies(todo_id, blocked_by_id);
+CREATE INDEX IF NOT EXISTS idx_todo_dependencies_blocked_by_id ON todo_dependencies(blocked_by_id);
@@ -0,0 +1,20 @@
+-- Todo lists with workflows
+-- A list stores the ordered workflow states of its todos and the transitions
+-- allowed between them as JSON; a todo in a list is completed while it is in a
+-- terminal state
+
+CREATE TABLE IF NOT EXISTS todo_lists (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    workflow JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_lists_user_id ON todo_lists(user_id);
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS list_id INTEGER REFERENCES todo_lists(id) ON DELETE SET NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS state VARCHAR(32) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_todos_list_id ON todos(list_id);
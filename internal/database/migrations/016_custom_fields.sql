@@ -0,0 +1,23 @@
+-- Custom fields on todos
+-- A custom field is defined by a user for all of their todos or for the todos
+-- of one list; the values of a todo are stored as a JSON object by field key
+
+CREATE TABLE IF NOT EXISTS custom_fields (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    list_id INTEGER REFERENCES todo_lists(id) ON DELETE CASCADE,
+    key VARCHAR(32) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    rules JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_fields_user_key ON custom_fields(user_id, key);
+CREATE INDEX IF NOT EXISTS idx_custom_fields_list_id ON custom_fields(list_id);
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS custom_fields JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_todos_custom_fields ON todos USING GIN (custom_fields);
@@ -0,0 +1,20 @@
+-- Todo comments
+-- Comments are threaded one level deep: a reply references the top-level
+-- comment it belongs to and is removed together with it. Mentions hold the
+-- users mentioned with @email who had access to the todo as JSON.
+
+CREATE TABLE IF NOT EXISTS todo_comments (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES todo_comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    mentions JSONB NOT NULL DEFAULT '[]',
+    edited BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_comments_todo_id ON todo_comments(todo_id);
+CREATE INDEX IF NOT EXISTS idx_todo_comments_user_id ON todo_comments(user_id);
+CREATE INDEX IF NOT EXISTS idx_todo_comments_parent_id ON todo_comments(parent_id);
@@ -0,0 +1,20 @@
+-- Todo attachments
+-- The contents are kept in a blob store under storage_key. The todo is not a
+-- foreign key: when a todo is purged its attachments are left behind so that
+-- the attachment sweeper can delete their blobs before removing the rows.
+
+CREATE TABLE IF NOT EXISTS todo_attachments (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    todo_id INTEGER NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_attachments_user_id ON todo_attachments(user_id);
+CREATE INDEX IF NOT EXISTS idx_todo_attachments_todo_id ON todo_attachments(todo_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_attachments_storage_key ON todo_attachments(storage_key);
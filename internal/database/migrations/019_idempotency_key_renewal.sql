@@ -0,0 +1,5 @@
+-- Renewal time of idempotency keys
+-- Requests that are still running renew their key, so that a retry is not
+-- mistaken for the retry of an abandoned request
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS renewed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
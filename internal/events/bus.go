@@ -0,0 +1,171 @@
+// Package events distributes todo change events to subscribers within the process.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"todo-api-backend/internal/model"
+)
+
+// Todo change event types
+const (
+	TypeTodoCreated = "todo.created"
+	TypeTodoUpdated = "todo.updated"
+	TypeTodoDeleted = "todo.deleted"
+)
+
+const (
+	// DefaultReplaySize is the number of recent events kept for resuming subscribers
+	DefaultReplaySize = 1000
+
+	// subscriberBuffer is the number of events a subscriber may fall behind
+	// before it is disconnected
+	subscriberBuffer = 64
+)
+
+// Event describes a change to a todo
+type Event struct {
+	ID         uint64      `json:"id" example:"1700000000000001"`
+	Type       string      `json:"type" example:"todo.updated"`
+	UserID     uint        `json:"-"`
+	TodoID     uint        `json:"todo_id" example:"1"`
+	Todo       *model.Todo `json:"todo,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// Publisher accepts events for delivery
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Bus fans events out to subscribers and keeps the most recent ones in a
+// bounded buffer so that subscribers can resume after a disconnect. Event IDs
+// start from the time the bus was created, so IDs handed out by an earlier
+// process are recognised as too old to resume from.
+type Bus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	floor       uint64
+	replay      []Event
+	start       int
+	count       int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus creates a bus that keeps the last replaySize events for replay
+func NewBus(replaySize int) *Bus {
+	if replaySize < 0 {
+		replaySize = 0
+	}
+
+	seed := uint64(time.Now().UnixMicro())
+	return &Bus{
+		lastID:      seed,
+		floor:       seed,
+		replay:      make([]Event, replaySize),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and delivers it to the subscribers of its
+// user. Subscribers that cannot keep up are disconnected rather than allowed
+// to block the publisher; they can resume from the replay buffer.
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	b.remember(event)
+
+	for sub := range b.subscribers {
+		if sub.userID != event.UserID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the events of a user. When resume is
+// set, the buffered events published after lastEventID are returned for
+// replay; ok is false if those events are no longer, or were never, in the
+// buffer, in which case the subscriber must reload its state.
+func (b *Bus) Subscribe(userID uint, lastEventID uint64, resume bool) (sub *Subscription, replay []Event, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{
+		bus:    b,
+		userID: userID,
+		events: make(chan Event, subscriberBuffer),
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if !resume {
+		return sub, nil, true
+	}
+	if lastEventID < b.floor || lastEventID > b.lastID {
+		return sub, nil, false
+	}
+
+	for i := 0; i < b.count; i++ {
+		event := b.replay[(b.start+i)%len(b.replay)]
+		if event.ID > lastEventID && event.UserID == userID {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, true
+}
+
+// remember stores an event in the replay buffer, evicting the oldest one when full
+func (b *Bus) remember(event Event) {
+	if len(b.replay) == 0 {
+		b.floor = event.ID
+		return
+	}
+
+	if b.count == len(b.replay) {
+		b.floor = b.replay[b.start].ID
+		b.start = (b.start + 1) % len(b.replay)
+		b.count--
+	}
+	b.replay[(b.start+b.count)%len(b.replay)] = event
+	b.count++
+}
+
+// remove unregisters a subscriber and closes its channel; b.mu must be held
+func (b *Bus) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// Subscription receives the events of a single user
+type Subscription struct {
+	bus    *Bus
+	userID uint
+	events chan Event
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscription is closed or the subscriber fell too far behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unregisters the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
@@ -0,0 +1,96 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_DeliversOnlyTheUsersEvents(t *testing.T) {
+	bus := NewBus(10)
+	sub, _, ok := bus.Subscribe(1, 0, false)
+	require.True(t, ok)
+	defer sub.Close()
+
+	bus.Publish(Event{Type: TypeTodoCreated, UserID: 2, TodoID: 1})
+	bus.Publish(Event{Type: TypeTodoUpdated, UserID: 1, TodoID: 2})
+
+	event := <-sub.Events()
+	assert.Equal(t, TypeTodoUpdated, event.Type)
+	assert.Equal(t, uint(2), event.TodoID)
+	assert.NotZero(t, event.ID)
+	assert.False(t, event.OccurredAt.IsZero())
+	assert.Empty(t, sub.Events())
+}
+
+func TestBus_ReplaysMissedEvents(t *testing.T) {
+	bus := NewBus(10)
+	first, _, _ := bus.Subscribe(1, 0, false)
+	bus.Publish(Event{Type: TypeTodoCreated, UserID: 1, TodoID: 1})
+	seen := <-first.Events()
+	first.Close()
+
+	bus.Publish(Event{Type: TypeTodoUpdated, UserID: 1, TodoID: 1})
+	bus.Publish(Event{Type: TypeTodoCreated, UserID: 2, TodoID: 2})
+	bus.Publish(Event{Type: TypeTodoDeleted, UserID: 1, TodoID: 1})
+
+	sub, replay, ok := bus.Subscribe(1, seen.ID, true)
+	defer sub.Close()
+
+	require.True(t, ok)
+	require.Len(t, replay, 2)
+	assert.Equal(t, TypeTodoUpdated, replay[0].Type)
+	assert.Equal(t, TypeTodoDeleted, replay[1].Type)
+	assert.Greater(t, replay[1].ID, replay[0].ID)
+}
+
+func TestBus_ReplayUnavailable(t *testing.T) {
+	bus := NewBus(2)
+	bus.Publish(Event{Type: TypeTodoCreated, UserID: 1, TodoID: 1})
+	first := bus.lastID
+	bus.Publish(Event{Type: TypeTodoCreated, UserID: 1, TodoID: 2})
+	bus.Publish(Event{Type: TypeTodoCreated, UserID: 1, TodoID: 3})
+	bus.Publish(Event{Type: TypeTodoCreated, UserID: 1, TodoID: 4})
+
+	tests := []struct {
+		name        string
+		lastEventID uint64
+		ok          bool
+		replayed    int
+	}{
+		{"evicted from the buffer", first, false, 0},
+		{"oldest still replayable", first + 1, true, 2},
+		{"issued by an earlier process", 42, false, 0},
+		{"never issued", bus.lastID + 1, false, 0},
+		{"up to date", bus.lastID, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay, ok := bus.Subscribe(1, tt.lastEventID, true)
+			defer sub.Close()
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Len(t, replay, tt.replayed)
+		})
+	}
+}
+
+func TestBus_DisconnectsSlowSubscribers(t *testing.T) {
+	bus := NewBus(0)
+	sub, _, _ := bus.Subscribe(1, 0, false)
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		bus.Publish(Event{Type: TypeTodoUpdated, UserID: 1, TodoID: 1})
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+
+	// Closing an already dropped subscription is harmless
+	sub.Close()
+}
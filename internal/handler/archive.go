@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+// ArchiveTodo handles archiving a specific todo
+// @Summary Archive todo
+// @Description Archive a specific todo by ID, hiding it from the default listing
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag the todo must still have"
+// @Success 200 {object} model.Todo "Todo archived successfully"
+// @Header 200 {string} ETag "Version of the todo"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 409 {object} model.ErrorResponse "Todo was modified concurrently"
+// @Failure 412 {object} model.ErrorResponse "If-Match ETag does not match the current todo"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/archive [post]
+func (h *Handler) ArchiveTodo(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+// UnarchiveTodo handles moving an archived todo back to the active list
+// @Summary Unarchive todo
+// @Description Move an archived todo back to the default listing
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag the todo must still have"
+// @Success 200 {object} model.Todo "Todo unarchived successfully"
+// @Header 200 {string} ETag "Version of the todo"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 409 {object} model.ErrorResponse "Todo was modified concurrently"
+// @Failure 412 {object} model.ErrorResponse "If-Match ETag does not match the current todo"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/unarchive [post]
+func (h *Handler) UnarchiveTodo(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+// setArchived archives or unarchives the todo identified by the URL parameter
+func (h *Handler) setArchived(c *gin.Context, archived bool) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Parse todo ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid todo ID format",
+		})
+		return
+	}
+
+	// Call service to change archive state
+	var todo *model.Todo
+	if archived {
+		todo, err = h.services.Todo.Archive(conditionalContext(c), uint(id), userID)
+	} else {
+		todo, err = h.services.Todo.Unarchive(conditionalContext(c), uint(id), userID)
+	}
+	if err != nil {
+		switch err.Error() {
+		case "todo not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Todo not found",
+			})
+		case "todo version does not match":
+			c.JSON(http.StatusPreconditionFailed, model.ErrorResponse{
+				Error:   "precondition_failed",
+				Message: "Todo has been modified since it was retrieved",
+			})
+		case "todo was modified concurrently":
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Error:   "conflict",
+				Message: "Todo was modified concurrently, please retry",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "archive_failed",
+				Message: "Failed to update archive state",
+			})
+		}
+		return
+	}
+
+	c.Header("ETag", todoETag(todo))
+	c.JSON(http.StatusOK, todo)
+}
+
+// ArchiveCompletedTodos handles archiving all completed todos older than N days
+// @Summary Archive completed todos
+// @Description Archive all completed todos of the authenticated user that were completed more than N days ago
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ArchiveCompletedRequest true "Bulk archive request"
+// @Success 200 {object} model.ArchiveResponse "Completed todos archived successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/archive [post]
+func (h *Handler) ArchiveCompletedTodos(c *gin.Context) {
+	var req model.ArchiveCompletedRequest
+
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{
+				"OlderThanDays": "Must be between 0 and 3650 days",
+			},
+		})
+		return
+	}
+
+	// Call service to archive completed todos
+	archived, err := h.services.Todo.ArchiveCompleted(c.Request.Context(), userID, req.OlderThanDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "archive_failed",
+			Message: "Failed to archive completed todos",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ArchiveResponse{Archived: archived})
+}
+
+// GetArchivePolicy handles retrieving the auto-archive policy
+// @Summary Get auto-archive policy
+// @Description Retrieve the auto-archive policy of the authenticated user
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.ArchivePolicyResponse "Auto-archive policy retrieved successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/archive/policy [get]
+func (h *Handler) GetArchivePolicy(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	policy, err := h.services.Todo.GetArchivePolicy(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve archive policy",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateArchivePolicy handles updating the auto-archive policy
+// @Summary Update auto-archive policy
+// @Description Configure how many days after completion todos are archived automatically (0 disables)
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ArchivePolicyRequest true "Auto-archive policy"
+// @Success 200 {object} model.ArchivePolicyResponse "Auto-archive policy updated successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/archive/policy [put]
+func (h *Handler) UpdateArchivePolicy(c *gin.Context) {
+	var req model.ArchivePolicyRequest
+
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{
+				"AutoArchiveDays": "Must be between 0 and 3650 days",
+			},
+		})
+		return
+	}
+
+	policy, err := h.services.Todo.UpdateArchivePolicy(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "update_failed",
+			Message: "Failed to update archive policy",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
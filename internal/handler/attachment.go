@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// multipartOverhead is how many bytes an upload may exceed the maximum
+// attachment size by, for the boundaries and headers of the multipart form
+const multipartOverhead = 64 << 10
+
+// UploadTodoAttachment handles attaching a file to a todo
+// @Summary Upload a todo attachment
+// @Description Attach a file, such as a screenshot, to a todo with a multipart form whose file field holds the file. The type is detected from the contents rather than taken from the client and must be one of the allowed types (by default PNG, JPEG, GIF and WebP images, PDF and plain text). Files larger than the maximum size (10 MB by default) are rejected, as are uploads that would take the user over their storage quota (100 MB by default). The response holds a signed download URL.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} model.Attachment "Attachment successfully uploaded"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format or missing or empty file"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 409 {object} model.ErrorResponse "Attachment quota exceeded"
+// @Failure 413 {object} model.ErrorResponse "Attachment too large"
+// @Failure 415 {object} model.ErrorResponse "Attachment type not allowed"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Attachments not available"
+// @Router /api/v1/todos/{id}/attachments [post]
+func (h *Handler) UploadTodoAttachment(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	// Cut off uploads that cannot fit before they are spooled to disk
+	maxSize := h.services.Attachment.MaxSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			attachmentTooLarge(c, maxSize)
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "A multipart file field named file is required",
+		})
+		return
+	}
+	defer file.Close()
+
+	// Call service to store the attachment
+	attachment, err := h.services.Attachment.Upload(c.Request.Context(), id, userID, header.Filename, file, header.Size)
+	if err != nil {
+		if errors.Is(err, service.ErrAttachmentTooLarge) {
+			attachmentTooLarge(c, maxSize)
+			return
+		}
+		attachmentError(c, err, "upload_failed", "Failed to upload attachment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetTodoAttachments handles listing the attachments of a todo
+// @Summary Get todo attachments
+// @Description Retrieve the attachments of a todo, oldest first, each with a signed download URL, together with the storage used by the user and their quota
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {object} model.AttachmentListResponse "Attachments retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Attachments not available"
+// @Router /api/v1/todos/{id}/attachments [get]
+func (h *Handler) GetTodoAttachments(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the attachments
+	attachments, err := h.services.Attachment.List(c.Request.Context(), id, userID)
+	if err != nil {
+		attachmentError(c, err, "retrieval_failed", "Failed to retrieve attachments")
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// GetTodoAttachment handles retrieving an attachment of a todo
+// @Summary Get a todo attachment
+// @Description Retrieve the metadata of an attachment with a fresh signed download URL
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 200 {object} model.Attachment "Attachment retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo or attachment ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo or attachment not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Attachments not available"
+// @Router /api/v1/todos/{id}/attachments/{attachment_id} [get]
+func (h *Handler) GetTodoAttachment(c *gin.Context) {
+	userID, id, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the attachment
+	attachment, err := h.services.Attachment.Get(c.Request.Context(), id, attachmentID, userID)
+	if err != nil {
+		attachmentError(c, err, "retrieval_failed", "Failed to retrieve attachment")
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// DeleteTodoAttachment handles deleting an attachment of a todo
+// @Summary Delete a todo attachment
+// @Description Delete an attachment and its contents, which frees its size from the quota of the user
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 204 "Attachment successfully deleted"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo or attachment ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo or attachment not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Attachments not available"
+// @Router /api/v1/todos/{id}/attachments/{attachment_id} [delete]
+func (h *Handler) DeleteTodoAttachment(c *gin.Context) {
+	userID, id, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to delete the attachment
+	if err := h.services.Attachment.Delete(c.Request.Context(), id, attachmentID, userID); err != nil {
+		attachmentError(c, err, "deletion_failed", "Failed to delete attachment")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DownloadAttachment handles downloading the contents of an attachment
+// @Summary Download an attachment
+// @Description Download the contents of an attachment through a signed URL returned with its metadata. The signature authenticates the request, so the URL can be opened without a JWT until it expires. Contents are always served as a download and never rendered inline.
+// @Tags attachments
+// @Produce application/octet-stream
+// @Param id path int true "Attachment ID"
+// @Param expires query int true "Expiry time of the URL as a Unix timestamp"
+// @Param signature query string true "Signature of the URL"
+// @Success 200 {file} file "Attachment contents"
+// @Failure 400 {object} model.ErrorResponse "Invalid attachment ID format or expiry time"
+// @Failure 403 {object} model.ErrorResponse "Invalid signature or expired URL"
+// @Failure 404 {object} model.ErrorResponse "Attachment not found or its todo is in the trash"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Attachments not available"
+// @Router /api/v1/attachments/{id}/download [get]
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid attachment ID format",
+		})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "expires must be a Unix timestamp",
+		})
+		return
+	}
+
+	attachment, blob, err := h.services.Attachment.Open(c.Request.Context(), uint(id), expires, c.Query("signature"))
+	if err != nil {
+		attachmentError(c, err, "download_failed", "Failed to download attachment")
+		return
+	}
+	defer blob.Close()
+
+	// Large downloads over slow links outlive the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Failed to clear write deadline for attachment download: %v", err)
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, blob, map[string]string{
+		"Content-Disposition":     disposition,
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'; sandbox",
+		"Cache-Control":           "private, no-cache",
+	})
+}
+
+// attachmentParams extracts the authenticated user, the todo ID and the
+// attachment ID, writing the error response and reporting false if any is
+// missing or invalid
+func attachmentParams(c *gin.Context) (uint, uint, uint, bool) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid attachment ID format",
+		})
+		return 0, 0, 0, false
+	}
+
+	return userID, id, uint(attachmentID), true
+}
+
+// attachmentTooLarge writes the response for an attachment larger than maxSize bytes
+func attachmentTooLarge(c *gin.Context, maxSize int64) {
+	c.JSON(http.StatusRequestEntityTooLarge, model.ErrorResponse{
+		Error:   "attachment_too_large",
+		Message: fmt.Sprintf("Attachments may be at most %s", formatSize(maxSize)),
+	})
+}
+
+// formatSize formats a size in bytes with the largest whole unit
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", size>>10)
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
+
+// attachmentError writes the response for an attachment service error
+func attachmentError(c *gin.Context, err error, code, message string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound), errors.Is(err, service.ErrUnauthorizedAccess):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Todo not found",
+		})
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Attachment not found",
+		})
+	case errors.Is(err, service.ErrEmptyAttachment):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{"file": "Must not be empty"},
+		})
+	case errors.Is(err, service.ErrUnsupportedAttachmentType):
+		c.JSON(http.StatusUnsupportedMediaType, model.ErrorResponse{
+			Error:   "unsupported_media_type",
+			Message: "The attachment type is not allowed",
+		})
+	case errors.Is(err, service.ErrAttachmentQuotaExceeded):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "quota_exceeded",
+			Message: "The attachment would exceed your storage quota",
+		})
+	case errors.Is(err, service.ErrInvalidDownloadSignature):
+		c.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error:   "invalid_signature",
+			Message: "The download link is invalid",
+		})
+	case errors.Is(err, service.ErrDownloadExpired):
+		c.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error:   "link_expired",
+			Message: "The download link has expired",
+		})
+	case errors.Is(err, service.ErrAttachmentsUnavailable):
+		c.JSON(http.StatusServiceUnavailable, model.ErrorResponse{
+			Error:   "attachments_unavailable",
+			Message: "Attachments are not enabled on this server",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
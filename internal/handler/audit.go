@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/model"
+)
+
+// GetSecurityEvents handles querying the security audit log
+// @Summary Query security audit log
+// @Description Retrieve authentication events, newest first. Restricted to administrators.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Filter by user ID"
+// @Param event query string false "Filter by event type" Enums(register, login, token_validation, password_change, caldav_login)
+// @Param outcome query string false "Filter by outcome" Enums(success, failure)
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events before this RFC 3339 time"
+// @Param limit query int false "Maximum number of events (default 100, max 1000)"
+// @Success 200 {object} model.SecurityEventsResponse "Security events retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid filter"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 403 {object} model.ErrorResponse "Administrator access required"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/audit-log [get]
+func (h *Handler) GetSecurityEvents(c *gin.Context) {
+	filter, message := parseSecurityEventFilter(c)
+	if message != "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_filter",
+			Message: message,
+		})
+		return
+	}
+
+	// Call service to query the audit log
+	events, err := h.services.Audit.Query(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve security events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SecurityEventsResponse{
+		Events: events,
+		Count:  len(events),
+	})
+}
+
+// parseSecurityEventFilter builds the audit log filter from query parameters,
+// returning a message describing the first invalid parameter
+func parseSecurityEventFilter(c *gin.Context) (*model.SecurityEventFilter, string) {
+	filter := &model.SecurityEventFilter{
+		EventType: c.Query("event"),
+		Outcome:   c.Query("outcome"),
+	}
+
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, "user_id must be a positive integer"
+		}
+		userID := uint(id)
+		filter.UserID = &userID
+	}
+
+	switch filter.EventType {
+	case "", model.SecurityEventRegister, model.SecurityEventLogin,
+		model.SecurityEventTokenValidation, model.SecurityEventPasswordChange, model.SecurityEventCalDAVLogin:
+	default:
+		return nil, "event must be one of: register, login, token_validation, password_change, caldav_login"
+	}
+
+	switch filter.Outcome {
+	case "", model.SecurityOutcomeSuccess, model.SecurityOutcomeFailure:
+	default:
+		return nil, "outcome must be one of: success, failure"
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, "from must be an RFC 3339 timestamp"
+		}
+		filter.From = &from
+	}
+
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, "to must be an RFC 3339 timestamp"
+		}
+		filter.To = &to
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, "from must be before to"
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, "limit must be a positive integer"
+		}
+		filter.Limit = limit
+	}
+
+	return filter, ""
+}
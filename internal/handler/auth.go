@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	
+	"todo-api-backend/internal/audit"
+	"todo-api-backend/internal/middleware"
 	"todo-api-backend/internal/model"
 )
 
@@ -58,7 +62,7 @@ func (h *Handler) Register(c *gin.Context) {
 	}
 	
 	// Call service to register user
-	response, err := h.services.Auth.Register(c.Request.Context(), &req)
+	response, err := h.services.Auth.Register(auditContext(c), &req)
 	if err != nil {
 		// Handle different types of errors
 		switch err.Error() {
@@ -126,7 +130,7 @@ func (h *Handler) Login(c *gin.Context) {
 	}
 	
 	// Call service to authenticate user
-	response, err := h.services.Auth.Login(c.Request.Context(), &req)
+	response, err := h.services.Auth.Login(auditContext(c), &req)
 	if err != nil {
 		// Handle different types of errors
 		switch err.Error() {
@@ -145,4 +149,100 @@ func (h *Handler) Login(c *gin.Context) {
 	}
 	
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+// ChangePassword handles changing the password of the authenticated user
+// @Summary Change password
+// @Description Change the password of the authenticated user after verifying the current password
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ChangePasswordRequest true "Password change request"
+// @Success 200 {object} model.SuccessResponse "Password successfully changed"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data, incorrect current password or weak new password"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "User not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/auth/password [put]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+	
+	var req model.ChangePasswordRequest
+	
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+	
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		details := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			switch err.Tag() {
+			case "required":
+				details[err.Field()] = "This field is required"
+			case "min":
+				details[err.Field()] = "Password must be at least 8 characters long"
+			default:
+				details[err.Field()] = "Invalid value"
+			}
+		}
+		
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: details,
+		})
+		return
+	}
+	
+	// Call service to change the password
+	if err := h.services.Auth.ChangePassword(auditContext(c), userID, &req); err != nil {
+		switch {
+		case err.Error() == "current password is incorrect":
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "incorrect_password",
+				Message: "Current password is incorrect",
+			})
+		case strings.HasPrefix(err.Error(), "password validation failed"):
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "weak_password",
+				Message: "New password does not meet the strength requirements",
+			})
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "User not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "password_change_failed",
+				Message: "Failed to change password",
+			})
+		}
+		return
+	}
+	
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Message: "Password changed successfully",
+	})
+}
+
+// auditContext returns the request context annotated with the client
+// information recorded in the security audit log
+func auditContext(c *gin.Context) context.Context {
+	return audit.WithClient(c.Request.Context(), c.ClientIP(), c.Request.UserAgent())
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+// BulkTodos handles running many todo operations in one request
+// @Summary Bulk todo operations
+// @Description Run create, update, delete and complete operations over many todos in a single transaction. With atomic set, any failure rolls back every operation; otherwise each operation succeeds or fails on its own. Returns 200 when every operation succeeded, 207 when some failed and 422 when an atomic request was rolled back.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.BulkRequest true "Bulk request"
+// @Success 200 {object} model.BulkResponse "All operations succeeded"
+// @Success 207 {object} model.BulkResponse "Some operations failed"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 422 {object} model.BulkResponse "Atomic request rolled back"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/bulk [post]
+func (h *Handler) BulkTodos(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req model.BulkRequest
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		details := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			switch err.Tag() {
+			case "required":
+				details[err.Field()] = "This field is required"
+			case "min":
+				details[err.Field()] = "At least one operation is required"
+			case "max":
+				details[err.Field()] = "At most 100 operations and 100 ids per operation are allowed"
+			case "oneof":
+				details[err.Field()] = "Must be one of: create, update, delete, complete"
+			default:
+				details[err.Field()] = "Invalid value"
+			}
+		}
+
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: details,
+		})
+		return
+	}
+
+	// Call service to run the operations
+	response, err := h.services.Todo.Bulk(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "too many bulk items":
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "too_many_items",
+				Message: "A bulk request may touch at most 500 todos",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "bulk_failed",
+				Message: "Failed to run bulk operations",
+			})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case !response.Committed:
+		status = http.StatusUnprocessableEntity
+	case response.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, response)
+}
@@ -0,0 +1,707 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/ical"
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+const (
+	// calDAVRoot is the principal of the authenticated user and the home of its calendars
+	calDAVRoot = "/caldav/"
+
+	// calDAVCollection is the calendar collection holding the todos of the authenticated user
+	calDAVCollection = "/caldav/todos/"
+
+	// calDAVRealm is the realm of the Basic auth challenge
+	calDAVRealm = "Todo CalDAV"
+
+	// calDAVObjectContentType is the media type of the calendar object resources
+	calDAVObjectContentType = "text/calendar; charset=utf-8; component=VTODO"
+
+	// maxDAVRequestSize limits the XML bodies of PROPFIND and REPORT requests
+	maxDAVRequestSize = 1 << 20
+
+	// maxCalDAVObjectSize limits the calendar objects clients PUT
+	maxCalDAVObjectSize = 1 << 20
+)
+
+// CalDAV precondition and report names
+var (
+	calValidCalendarData          = xml.Name{Space: calDAVNamespace, Local: "valid-calendar-data"}
+	calSupportedCalendarComponent = xml.Name{Space: calDAVNamespace, Local: "supported-calendar-component"}
+	calNoUIDConflict              = xml.Name{Space: calDAVNamespace, Local: "no-uid-conflict"}
+	calMaxResourceSize            = xml.Name{Space: calDAVNamespace, Local: "max-resource-size"}
+	davSupportedReport            = xml.Name{Space: davNamespace, Local: "supported-report"}
+	calCalendarQuery              = xml.Name{Space: calDAVNamespace, Local: "calendar-query"}
+	calCalendarMultiget           = xml.Name{Space: calDAVNamespace, Local: "calendar-multiget"}
+)
+
+// CalDAVAuth returns the middleware authenticating CalDAV requests with an
+// email address and app password
+func (h *Handler) CalDAVAuth(opts ...middleware.AuthOption) gin.HandlerFunc {
+	rejected := func(err error) bool {
+		return errors.Is(err, service.ErrInvalidCredentials)
+	}
+	return middleware.BasicAuthMiddleware(h.services.CalDAV, calDAVRealm, rejected, opts...)
+}
+
+// CreateCalDAVPassword handles generating an app password for CalDAV clients
+// @Summary Create CalDAV app password
+// @Description Generate an app password with which task apps sign in to the CalDAV server, using the email address of the account as user name. The password is only shown once.
+// @Tags caldav
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateCalDAVPasswordRequest true "App password name"
+// @Success 201 {object} model.CalDAVPasswordResponse "App password created"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/caldav/passwords [post]
+func (h *Handler) CreateCalDAVPassword(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req model.CreateCalDAVPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{
+				"Name": "Name is required and must be at most 100 characters long",
+			},
+		})
+		return
+	}
+
+	password, err := h.services.CalDAV.CreatePassword(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "creation_failed",
+			Message: "Failed to create app password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, password)
+}
+
+// GetCalDAVPasswords handles listing the app passwords of the authenticated user
+// @Summary List CalDAV app passwords
+// @Description List the app passwords of the authenticated user with their last use. The passwords themselves are not returned.
+// @Tags caldav
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.CalDAVPasswordResponse "App passwords"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/caldav/passwords [get]
+func (h *Handler) GetCalDAVPasswords(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	passwords, err := h.services.CalDAV.ListPasswords(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve app passwords",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, passwords)
+}
+
+// DeleteCalDAVPassword handles revoking an app password
+// @Summary Revoke CalDAV app password
+// @Description Revoke an app password, so that task apps using it can no longer sign in
+// @Tags caldav
+// @Security BearerAuth
+// @Param id path int true "App password ID"
+// @Success 204 "App password revoked"
+// @Failure 400 {object} model.ErrorResponse "Invalid app password ID"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "App password not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/caldav/passwords/{id} [delete]
+func (h *Handler) DeleteCalDAVPassword(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "App password ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.services.CalDAV.DeletePassword(c.Request.Context(), uint(id), userID); err != nil {
+		switch err.Error() {
+		case "caldav password not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "App password not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "deletion_failed",
+				Message: "Failed to revoke app password",
+			})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CalDAVWellKnown redirects CalDAV service discovery (RFC 6764) to the principal
+func (h *Handler) CalDAVWellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, calDAVRoot)
+}
+
+// CalDAVOptions advertises the WebDAV and CalDAV capabilities of the server
+func (h *Handler) CalDAVOptions(c *gin.Context) {
+	c.Header("DAV", "1, calendar-access")
+	c.Header("Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	c.Status(http.StatusOK)
+}
+
+// PropfindCalDAVHome handles PROPFIND on the principal of the authenticated user,
+// which is also the home of its single calendar collection
+func (h *Handler) PropfindCalDAVHome(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	email, _ := middleware.GetUserEmail(c)
+
+	req, err := parsePropfind(c.Request.Body)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	responses := []*davResponse{newDAVResponse(calDAVRoot, req, calDAVHomeProps(email))}
+	if davDepth(c.GetHeader("Depth")) > 0 {
+		stamp, err := h.services.CalDAV.Stamp(c.Request.Context(), userID)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		responses = append(responses, newDAVResponse(calDAVCollection, req, calDAVCollectionProps(stamp)))
+	}
+
+	writeMultistatus(c, func(m *multistatusWriter) error {
+		for _, r := range responses {
+			if err := m.write(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PropfindCalDAVCollection handles PROPFIND on the calendar collection, listing
+// its calendar objects when Depth is 1
+func (h *Handler) PropfindCalDAVCollection(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+
+	req, err := parsePropfind(c.Request.Body)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	stamp, err := h.services.CalDAV.Stamp(ctx, userID)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	writeMultistatus(c, func(m *multistatusWriter) error {
+		if err := m.write(newDAVResponse(calDAVCollection, req, calDAVCollectionProps(stamp))); err != nil {
+			return err
+		}
+		if davDepth(c.GetHeader("Depth")) == 0 {
+			return nil
+		}
+		return h.services.CalDAV.List(ctx, userID, func(object *model.CalDAVObject) error {
+			return m.write(newDAVResponse(calDAVObjectHref(object.Name), req, calDAVObjectProps(object, req)))
+		})
+	})
+}
+
+// PropfindCalDAVObject handles PROPFIND on a calendar object
+func (h *Handler) PropfindCalDAVObject(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	req, err := parsePropfind(c.Request.Body)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	object, err := h.services.CalDAV.Get(c.Request.Context(), userID, c.Param("name"))
+	if err != nil {
+		c.Status(calDAVErrorStatus(err))
+		return
+	}
+
+	writeMultistatus(c, func(m *multistatusWriter) error {
+		return m.write(newDAVResponse(calDAVObjectHref(object.Name), req, calDAVObjectProps(object, req)))
+	})
+}
+
+// calendarReport is the body of a calendar-query or calendar-multiget REPORT
+type calendarReport struct {
+	XMLName  xml.Name
+	AllProp  *struct{}       `xml:"DAV: allprop"`
+	PropName *struct{}       `xml:"DAV: propname"`
+	Prop     *davPropNames   `xml:"DAV: prop"`
+	Hrefs    []string        `xml:"DAV: href"`
+	Filter   *calendarFilter `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// calendarFilter is the filter of a calendar-query
+type calendarFilter struct {
+	CompFilter calendarCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// calendarCompFilter selects calendar components by name and properties. Time
+// ranges are not evaluated, so queries with one return a superset of the
+// matching todos, which clients filter themselves.
+type calendarCompFilter struct {
+	Name         string               `xml:"name,attr"`
+	IsNotDefined *struct{}            `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	CompFilters  []calendarCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	PropFilters  []calendarPropFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// calendarPropFilter selects components by the presence or text of a property
+type calendarPropFilter struct {
+	Name         string             `xml:"name,attr"`
+	IsNotDefined *struct{}          `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *calendarTextMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// calendarTextMatch matches a substring of a property value, ignoring ASCII case
+type calendarTextMatch struct {
+	Text            string `xml:",chardata"`
+	NegateCondition string `xml:"negate-condition,attr"`
+}
+
+// ReportCalDAVCollection handles the calendar-query and calendar-multiget
+// REPORTs on the calendar collection
+func (h *Handler) ReportCalDAVCollection(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxDAVRequestSize))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	var report calendarReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	req := newPropRequest(report.AllProp != nil, report.PropName != nil, report.Prop)
+
+	switch report.XMLName {
+	case calCalendarQuery:
+		writeMultistatus(c, func(m *multistatusWriter) error {
+			return h.services.CalDAV.List(ctx, userID, func(object *model.CalDAVObject) error {
+				if report.Filter != nil && !report.Filter.CompFilter.matchesCalendar(object) {
+					return nil
+				}
+				return m.write(newDAVResponse(calDAVObjectHref(object.Name), req, calDAVObjectProps(object, req)))
+			})
+		})
+	case calCalendarMultiget:
+		writeMultistatus(c, func(m *multistatusWriter) error {
+			for _, href := range report.Hrefs {
+				response := &davResponse{href: href, status: http.StatusNotFound}
+				if name, ok := calDAVObjectName(href); ok {
+					object, err := h.services.CalDAV.Get(ctx, userID, name)
+					switch {
+					case err == nil:
+						response = newDAVResponse(href, req, calDAVObjectProps(object, req))
+					case !errors.Is(err, service.ErrCalDAVObjectNotFound):
+						return err
+					}
+				}
+				if err := m.write(response); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	default:
+		c.Data(http.StatusForbidden, davContentType, davError(davSupportedReport))
+	}
+}
+
+// GetCalDAVObject handles retrieving a calendar object
+// @Summary Get CalDAV calendar object
+// @Description Retrieve a todo as a calendar holding a single VTODO. Authenticated with HTTP Basic auth using the account email address and an app password.
+// @Tags caldav
+// @Produce text/calendar
+// @Security BasicAuth
+// @Param name path string true "Calendar object name"
+// @Param If-None-Match header string false "ETag of a previously fetched version"
+// @Success 200 {string} string "Calendar object"
+// @Success 304 "Calendar object not modified"
+// @Failure 401 "Invalid credentials"
+// @Failure 404 "Calendar object not found"
+// @Router /caldav/todos/{name} [get]
+func (h *Handler) GetCalDAVObject(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	object, err := h.services.CalDAV.Get(c.Request.Context(), userID, c.Param("name"))
+	if err != nil {
+		c.Status(calDAVErrorStatus(err))
+		return
+	}
+
+	etag := todoETag(object.Todo)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", object.Todo.UpdatedAt.UTC().Format(http.TimeFormat))
+	if noneMatch(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	data, err := ical.Marshal(object.Todo, object.UID, ical.Options{})
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(http.StatusOK, calDAVObjectContentType, data)
+}
+
+// PutCalDAVObject handles creating or replacing a calendar object
+// @Summary Put CalDAV calendar object
+// @Description Create a todo from a calendar holding a single VTODO, or replace the todo at an existing name. SUMMARY, DESCRIPTION and DUE map to the title, description and due date; STATUS:COMPLETED, or a COMPLETED date without a STATUS, marks the todo as completed. Other properties are not stored. If-Match makes the write conditional on the current version and If-None-Match: * restricts it to new names.
+// @Tags caldav
+// @Accept text/calendar
+// @Security BasicAuth
+// @Param name path string true "Calendar object name"
+// @Param If-Match header string false "ETag of the version being replaced"
+// @Param If-None-Match header string false "* to only create a new calendar object"
+// @Success 201 "Calendar object created"
+// @Success 204 "Calendar object replaced"
+// @Failure 401 "Invalid credentials"
+// @Failure 403 "Invalid calendar data or UID conflict"
+// @Failure 409 "Todo was modified concurrently"
+// @Failure 412 "Precondition failed"
+// @Failure 413 "Calendar object too large"
+// @Router /caldav/todos/{name} [put]
+func (h *Handler) PutCalDAVObject(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCalDAVObjectSize+1))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxCalDAVObjectSize {
+		c.Data(http.StatusRequestEntityTooLarge, davContentType, davError(calMaxResourceSize))
+		return
+	}
+
+	createOnly := strings.TrimSpace(c.GetHeader("If-None-Match")) == "*"
+	object, created, err := h.services.CalDAV.Put(conditionalContext(c), userID, c.Param("name"), data, createOnly)
+	if err != nil {
+		switch {
+		case errors.Is(err, ical.ErrNoTodo):
+			c.Data(http.StatusForbidden, davContentType, davError(calSupportedCalendarComponent))
+		case errors.Is(err, service.ErrInvalidCalendarData):
+			c.Data(http.StatusForbidden, davContentType, davError(calValidCalendarData))
+		case errors.Is(err, service.ErrCalDAVUIDConflict):
+			c.Data(http.StatusForbidden, davContentType, davError(calNoUIDConflict))
+		case errors.Is(err, service.ErrInvalidCalDAVName):
+			c.Status(http.StatusBadRequest)
+		default:
+			c.Status(calDAVErrorStatus(err))
+		}
+		return
+	}
+
+	c.Header("ETag", todoETag(object.Todo))
+	if created {
+		c.Header("Location", calDAVObjectHref(object.Name))
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteCalDAVObject handles deleting a calendar object
+// @Summary Delete CalDAV calendar object
+// @Description Move the todo of a calendar object to the trash
+// @Tags caldav
+// @Security BasicAuth
+// @Param name path string true "Calendar object name"
+// @Param If-Match header string false "ETag of the version being deleted"
+// @Success 204 "Calendar object deleted"
+// @Failure 401 "Invalid credentials"
+// @Failure 404 "Calendar object not found"
+// @Failure 412 "Precondition failed"
+// @Router /caldav/todos/{name} [delete]
+func (h *Handler) DeleteCalDAVObject(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	if err := h.services.CalDAV.Delete(conditionalContext(c), userID, c.Param("name")); err != nil {
+		c.Status(calDAVErrorStatus(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// calDAVErrorStatus maps the errors of calendar object operations to status codes
+func calDAVErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCalDAVObjectNotFound), errors.Is(err, service.ErrTodoNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrConcurrentUpdate):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeMultistatus streams a 207 Multi-Status response. Errors after the first
+// response element can only cut the response short.
+func writeMultistatus(c *gin.Context, fn func(m *multistatusWriter) error) {
+	c.Header("Content-Type", davContentType)
+	c.Status(http.StatusMultiStatus)
+
+	m := &multistatusWriter{w: c.Writer}
+	err := m.begin()
+	if err == nil {
+		err = fn(m)
+	}
+	if err == nil {
+		err = m.end()
+	}
+	if err != nil {
+		log.Printf("Failed to write CalDAV multistatus for %s: %v", c.Request.URL.Path, err)
+	}
+}
+
+// newDAVResponse returns the response element of a resource with the requested properties
+func newDAVResponse(href string, req *davPropRequest, available []davProperty) *davResponse {
+	props, missing := req.selectProps(available)
+	return &davResponse{href: href, props: props, missing: missing}
+}
+
+// calDAVHomeProps returns the properties of the principal of a user
+func calDAVHomeProps(email string) []davProperty {
+	return []davProperty{
+		{davResourceType, davElement(xml.Name{Space: davNamespace, Local: "collection"}, "") + davElement(xml.Name{Space: davNamespace, Local: "principal"}, "")},
+		{davDisplayName, escapeXML(email)},
+		{davCurrentUserPrincipal, davHref(calDAVRoot)},
+		{davPrincipalURL, davHref(calDAVRoot)},
+		{calHomeSet, davHref(calDAVRoot)},
+		{calUserAddressSet, davHref("mailto:" + email)},
+		{davCurrentUserPrivilegeSet, davPrivileges("read")},
+	}
+}
+
+// calDAVCollectionProps returns the properties of the calendar collection
+func calDAVCollectionProps(stamp *model.CalendarStamp) []davProperty {
+	reports := ""
+	for _, report := range []xml.Name{calCalendarQuery, calCalendarMultiget} {
+		reports += "<d:supported-report><d:report>" + davElement(report, "") + "</d:report></d:supported-report>"
+	}
+
+	return []davProperty{
+		{davResourceType, davElement(xml.Name{Space: davNamespace, Local: "collection"}, "") + davElement(xml.Name{Space: calDAVNamespace, Local: "calendar"}, "")},
+		{davDisplayName, escapeXML(calendarFeedName)},
+		{calSupportedComponentSet, `<cal:comp name="VTODO"/>`},
+		{csGetCTag, escapeXML(calendarETag(stamp, "caldav"))},
+		{davCurrentUserPrincipal, davHref(calDAVRoot)},
+		{davOwner, davHref(calDAVRoot)},
+		{davSupportedReportSet, reports},
+		{davCurrentUserPrivilegeSet, davPrivileges("read", "write", "write-content", "bind", "unbind")},
+	}
+}
+
+// calDAVObjectProps returns the properties of a calendar object. Its calendar
+// data is only rendered when the request asks for it.
+func calDAVObjectProps(object *model.CalDAVObject, req *davPropRequest) []davProperty {
+	props := []davProperty{
+		{davResourceType, ""},
+		{davGetETag, escapeXML(todoETag(object.Todo))},
+		{davGetContentType, calDAVObjectContentType},
+		{davGetLastModified, object.Todo.UpdatedAt.UTC().Format(http.TimeFormat)},
+	}
+
+	if req.wants(calCalendarData) {
+		data, err := ical.Marshal(object.Todo, object.UID, ical.Options{})
+		if err == nil {
+			props = append(props, davProperty{calCalendarData, escapeXML(string(data))})
+		}
+	}
+	return props
+}
+
+// davPrivileges returns the value of a current-user-privilege-set property
+func davPrivileges(privileges ...string) string {
+	var b strings.Builder
+	for _, privilege := range privileges {
+		b.WriteString("<d:privilege><d:" + privilege + "/></d:privilege>")
+	}
+	return b.String()
+}
+
+// calDAVObjectHref returns the URL path of a calendar object
+func calDAVObjectHref(name string) string {
+	return calDAVCollection + url.PathEscape(name)
+}
+
+// calDAVObjectName returns the name of the calendar object an href refers to
+func calDAVObjectName(href string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	name, ok := strings.CutPrefix(u.Path, calDAVCollection)
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// matchesCalendar evaluates the top-level comp-filter of a calendar-query, which names VCALENDAR
+func (f *calendarCompFilter) matchesCalendar(object *model.CalDAVObject) bool {
+	if !strings.EqualFold(f.Name, "VCALENDAR") {
+		return false
+	}
+	for i := range f.CompFilters {
+		if !f.CompFilters[i].matchesComponent(object) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesComponent evaluates a comp-filter against the VTODO of a calendar object
+func (f *calendarCompFilter) matchesComponent(object *model.CalDAVObject) bool {
+	defined := strings.EqualFold(f.Name, "VTODO")
+	if f.IsNotDefined != nil {
+		return !defined
+	}
+	if !defined {
+		return false
+	}
+
+	for i := range f.PropFilters {
+		if !f.PropFilters[i].matches(object) {
+			return false
+		}
+	}
+	// Todos have no alarms or other subcomponents
+	for i := range f.CompFilters {
+		if f.CompFilters[i].IsNotDefined == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// matches evaluates a prop-filter against the VTODO of a calendar object
+func (f *calendarPropFilter) matches(object *model.CalDAVObject) bool {
+	value, defined := vtodoProperty(object, f.Name)
+	if f.IsNotDefined != nil {
+		return !defined
+	}
+	if !defined {
+		return false
+	}
+	if f.TextMatch == nil {
+		return true
+	}
+
+	match := strings.Contains(strings.ToLower(value), strings.ToLower(f.TextMatch.Text))
+	if f.TextMatch.NegateCondition == "yes" {
+		return !match
+	}
+	return match
+}
+
+// vtodoProperty returns the value of a property of the VTODO of a calendar
+// object, and whether the VTODO has that property
+func vtodoProperty(object *model.CalDAVObject, name string) (string, bool) {
+	todo := object.Todo
+	switch strings.ToUpper(name) {
+	case "UID":
+		return object.UID, true
+	case "SUMMARY":
+		return todo.Title, true
+	case "DESCRIPTION":
+		return todo.Description, todo.Description != ""
+	case "DUE":
+		return "", todo.DueAt != nil
+	case "STATUS":
+		if todo.Completed {
+			return "COMPLETED", true
+		}
+		return "NEEDS-ACTION", true
+	case "COMPLETED":
+		return "", todo.Completed && todo.CompletedAt != nil
+	case "PERCENT-COMPLETE":
+		return "100", todo.Completed
+	case "DTSTAMP", "CREATED", "LAST-MODIFIED", "SEQUENCE":
+		return "", true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/ical"
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+const (
+	// calendarFeedName is the name calendar apps show for a subscribed feed
+	calendarFeedName = "Todos"
+
+	// calendarRefreshInterval suggests how often calendar apps poll a feed
+	calendarRefreshInterval = time.Hour
+)
+
+// GetCalendarFeed handles retrieving the state of the calendar feed of the authenticated user
+// @Summary Get calendar feed
+// @Description Report whether the authenticated user has a calendar feed. The secret feed URL is only returned when its token is generated.
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.CalendarFeedResponse "Calendar feed state"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/calendar/feed [get]
+func (h *Handler) GetCalendarFeed(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	feed, err := h.services.Calendar.GetFeed(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve calendar feed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// RegenerateCalendarToken handles creating the calendar feed of the authenticated user or replacing its token
+// @Summary Generate calendar feed URL
+// @Description Create the calendar feed of the authenticated user, or replace its secret token so that the previous feed URL stops working. The returned URL can be subscribed to by calendar apps without further authentication; it is only shown once.
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} model.CalendarFeedResponse "Feed token generated"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/calendar/feed [post]
+func (h *Handler) RegenerateCalendarToken(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	feed, err := h.services.Calendar.RegenerateToken(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "generation_failed",
+			Message: "Failed to generate calendar feed token",
+		})
+		return
+	}
+
+	feed.URL = calendarFeedURL(c, feed.Token)
+	c.JSON(http.StatusCreated, feed)
+}
+
+// DisableCalendarFeed handles removing the calendar feed of the authenticated user
+// @Summary Disable calendar feed
+// @Description Remove the calendar feed of the authenticated user, so that its URL stops working
+// @Tags calendar
+// @Security BearerAuth
+// @Success 204 "Calendar feed disabled"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Calendar feed not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/calendar/feed [delete]
+func (h *Handler) DisableCalendarFeed(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.services.Calendar.DisableFeed(c.Request.Context(), userID); err != nil {
+		switch err.Error() {
+		case "calendar feed not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Calendar feed not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "deletion_failed",
+				Message: "Failed to disable calendar feed",
+			})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ServeCalendarFeed handles serving a calendar feed to calendar apps
+// @Summary Calendar feed
+// @Description Serve the todos of a user as an iCalendar feed. The secret token in the URL authenticates the request, so calendar apps can poll it without a JWT. By default todos with a due date are served as events at their due date; component=vtodo serves every todo as a to-do instead. Archived and trashed todos are left out. Responses carry an ETag and Last-Modified, and conditional requests for an unchanged feed are answered with 304.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token followed by .ics"
+// @Param component query string false "Calendar component" Enums(vevent, vtodo) default(vevent)
+// @Param If-None-Match header string false "ETag of a previously fetched feed"
+// @Param If-Modified-Since header string false "Last-Modified of a previously fetched feed"
+// @Success 200 {string} string "iCalendar feed"
+// @Success 304 "Feed not modified"
+// @Failure 400 {object} model.ErrorResponse "Invalid component"
+// @Failure 404 {object} model.ErrorResponse "Calendar feed not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/calendar/{token}.ics [get]
+func (h *Handler) ServeCalendarFeed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok || token == "" {
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Calendar feed not found",
+		})
+		return
+	}
+
+	component := c.DefaultQuery("component", model.CalendarComponentEvent)
+	if component != model.CalendarComponentEvent && component != model.CalendarComponentTodo {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_component",
+			Message: "component must be one of: vevent, vtodo",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, err := h.services.Calendar.ResolveToken(ctx, token)
+	if err != nil {
+		switch err.Error() {
+		case "calendar feed not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Calendar feed not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "retrieval_failed",
+				Message: "Failed to retrieve calendar feed",
+			})
+		}
+		return
+	}
+
+	stamp, err := h.services.Calendar.Stamp(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve calendar feed",
+		})
+		return
+	}
+
+	// Polling clients revalidate every time, which is cheap while the feed is unchanged
+	etag := calendarETag(stamp, component)
+	lastModified := stamp.LastModified.UTC().Truncate(time.Second)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if notModified(c, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("Content-Type", ical.ContentType)
+	c.Status(http.StatusOK)
+
+	encoder := ical.NewEncoder(c.Writer, ical.Options{
+		Name:            calendarFeedName,
+		RefreshInterval: calendarRefreshInterval,
+	})
+	encode := encoder.EncodeEvent
+	if component == model.CalendarComponentTodo {
+		encode = encoder.EncodeTodo
+	}
+
+	err = encoder.Begin()
+	if err == nil {
+		err = h.services.Calendar.FeedTodos(ctx, userID, encode)
+	}
+	if err == nil {
+		err = encoder.End()
+	}
+	if err != nil {
+		// The status line has been sent, so the feed can only be cut short
+		log.Printf("Failed to serve calendar feed of user %d: %v", userID, err)
+	}
+}
+
+// calendarETag returns the entity tag of a calendar feed. It changes whenever
+// a todo of the user is created, changed or deleted.
+func calendarETag(stamp *model.CalendarStamp, component string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%s", stamp.Count, stamp.LastModified.UnixNano(), component)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// notModified reports whether a conditional GET may be answered with 304.
+// If-Modified-Since is ignored when If-None-Match is present, as RFC 9110 requires.
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if c.GetHeader("If-None-Match") != "" {
+		return noneMatch(c, etag)
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+// calendarFeedURL returns the absolute URL of the calendar feed with the given token
+func calendarFeedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/v1/calendar/%s.ics", scheme, c.Request.Host, token)
+}
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// GetTodoComments handles retrieving the comments of a todo
+// @Summary Get todo comments
+// @Description Retrieve a page of the top-level comments of a todo, oldest first, each with all of its replies, ensuring user access. Comment bodies are returned as the Markdown they were written in.
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param page query int false "Page number (default 1)"
+// @Param per_page query int false "Top-level comments per page (default 20, max 100)"
+// @Success 200 {object} model.CommentPage "Comments retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format or page"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/comments [get]
+func (h *Handler) GetTodoComments(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	page, perPage := 0, 0
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"page", &page}, {"per_page", &perPage}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_filter",
+				Message: param.name + " must be a positive integer",
+			})
+			return
+		}
+		*param.value = parsed
+	}
+
+	// Call service to get the comments
+	comments, err := h.services.Todo.ListComments(c.Request.Context(), id, userID, page, perPage)
+	if err != nil {
+		commentError(c, err, "retrieval_failed", "Failed to retrieve comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// AddTodoComment handles commenting on a todo
+// @Summary Add a todo comment
+// @Description Comment on a todo, or reply to one of its comments with parent_id; a reply to a reply joins the thread of its top-level comment. The body is Markdown. Users mentioned as @email who have access to the todo are listed in mentions and receive a comment.mentioned webhook event.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param request body model.CreateCommentRequest true "Comment"
+// @Success 201 {object} model.TodoComment "Comment successfully added"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 422 {object} model.ErrorResponse "Parent comment not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/comments [post]
+func (h *Handler) AddTodoComment(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to add the comment
+	comment, err := h.services.Todo.AddComment(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		commentError(c, err, "creation_failed", "Failed to add comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateTodoComment handles editing a comment
+// @Summary Edit a todo comment
+// @Description Replace the body of a comment, which only its author may do. Mentions are resolved again; only users who were not mentioned before receive a comment.mentioned webhook event.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param comment_id path int true "Comment ID"
+// @Param request body model.UpdateCommentRequest true "New comment body"
+// @Success 200 {object} model.TodoComment "Comment successfully updated"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 403 {object} model.ErrorResponse "Comment written by another user"
+// @Failure 404 {object} model.ErrorResponse "Todo or comment not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/comments/{comment_id} [put]
+func (h *Handler) UpdateTodoComment(c *gin.Context) {
+	userID, id, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to update the comment
+	comment, err := h.services.Todo.UpdateComment(c.Request.Context(), id, commentID, &req, userID)
+	if err != nil {
+		commentError(c, err, "update_failed", "Failed to update comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteTodoComment handles deleting a comment
+// @Summary Delete a todo comment
+// @Description Delete a comment together with its replies, which only its author may do
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param comment_id path int true "Comment ID"
+// @Success 204 "Comment successfully deleted"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo or comment ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 403 {object} model.ErrorResponse "Comment written by another user"
+// @Failure 404 {object} model.ErrorResponse "Todo or comment not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/comments/{comment_id} [delete]
+func (h *Handler) DeleteTodoComment(c *gin.Context) {
+	userID, id, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to delete the comment
+	if err := h.services.Todo.DeleteComment(c.Request.Context(), id, commentID, userID); err != nil {
+		commentError(c, err, "deletion_failed", "Failed to delete comment")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// commentParams extracts the authenticated user, the todo ID and the comment
+// ID, writing the error response and reporting false if any is missing or invalid
+func commentParams(c *gin.Context) (uint, uint, uint, bool) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid comment ID format",
+		})
+		return 0, 0, 0, false
+	}
+
+	return userID, id, uint(commentID), true
+}
+
+// commentError writes the response for a comment service error
+func commentError(c *gin.Context, err error, code, message string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound), errors.Is(err, service.ErrUnauthorizedAccess):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Todo not found",
+		})
+	case errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Comment not found",
+		})
+	case errors.Is(err, service.ErrCommentNotAuthor):
+		c.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Only the author can edit or delete a comment",
+		})
+	case errors.Is(err, service.ErrEmptyComment):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{"Body": "Must not be blank"},
+		})
+	case errors.Is(err, service.ErrParentCommentNotFound):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "parent_comment_not_found",
+			Message: "The parent comment does not exist on this todo",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
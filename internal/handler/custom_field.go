@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+	"todo-api-backend/pkg/validator"
+)
+
+// CreateCustomField handles defining a custom field of todos
+// @Summary Create a custom field
+// @Description Define a custom field for all todos of the authenticated user or, with a list_id, for the todos of one list. Fields are text, number, date (YYYY-MM-DD), select or checkbox. Number fields can have a min and max, text fields a min and max length and an email or url format, and select fields list their options. Todos set their values in custom_fields by field key; required fields must have a value whenever a todo is created or its values are replaced.
+// @Tags custom-fields
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateCustomFieldRequest true "Custom field creation request"
+// @Success 201 {object} model.CustomField "Custom field successfully created"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data, validation failed or rules that do not fit the type"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 409 {object} model.ErrorResponse "Key already in use or custom field limit reached"
+// @Failure 422 {object} model.ErrorResponse "List not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/custom-fields [post]
+func (h *Handler) CreateCustomField(c *gin.Context) {
+	var req model.CreateCustomFieldRequest
+
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to create the custom field
+	field, err := h.services.CustomField.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		customFieldError(c, err, "creation_failed", "Failed to create custom field")
+		return
+	}
+
+	c.JSON(http.StatusCreated, field)
+}
+
+// GetCustomFields handles listing the custom fields of the authenticated user
+// @Summary Get all custom fields
+// @Description Retrieve the custom fields of the authenticated user, including those of their lists
+// @Tags custom-fields
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.CustomFieldListResponse "Custom fields retrieved successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/custom-fields [get]
+func (h *Handler) GetCustomFields(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to list custom fields
+	fields, err := h.services.CustomField.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve custom fields",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.CustomFieldListResponse{
+		Fields: fields,
+		Count:  len(fields),
+	})
+}
+
+// GetCustomField handles retrieving a single custom field
+// @Summary Get a custom field
+// @Description Retrieve a custom field with its rules, ensuring user ownership
+// @Tags custom-fields
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Custom field ID"
+// @Success 200 {object} model.CustomField "Custom field retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid custom field ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Custom field not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/custom-fields/{id} [get]
+func (h *Handler) GetCustomField(c *gin.Context) {
+	userID, id, ok := customFieldParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the custom field
+	field, err := h.services.CustomField.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		customFieldError(c, err, "retrieval_failed", "Failed to retrieve custom field")
+		return
+	}
+
+	c.JSON(http.StatusOK, field)
+}
+
+// UpdateCustomField handles renaming a custom field or changing its rules
+// @Summary Update a custom field
+// @Description Rename a custom field, make it required or optional, or replace its rules, ensuring user ownership. The key, type and list of a field cannot change. Stored values are not checked again; the new rules apply the next time the values of a todo are set.
+// @Tags custom-fields
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Custom field ID"
+// @Param request body model.UpdateCustomFieldRequest true "Custom field update request"
+// @Success 200 {object} model.CustomField "Custom field successfully updated"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data, validation failed or rules that do not fit the type"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Custom field not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/custom-fields/{id} [put]
+func (h *Handler) UpdateCustomField(c *gin.Context) {
+	userID, id, ok := customFieldParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.UpdateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to update the custom field
+	field, err := h.services.CustomField.Update(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		customFieldError(c, err, "update_failed", "Failed to update custom field")
+		return
+	}
+
+	c.JSON(http.StatusOK, field)
+}
+
+// DeleteCustomField handles removing a custom field
+// @Summary Delete a custom field
+// @Description Remove a custom field and its values on every todo, ensuring user ownership
+// @Tags custom-fields
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Custom field ID"
+// @Success 204 "Custom field successfully deleted"
+// @Failure 400 {object} model.ErrorResponse "Invalid custom field ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Custom field not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/custom-fields/{id} [delete]
+func (h *Handler) DeleteCustomField(c *gin.Context) {
+	userID, id, ok := customFieldParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to delete the custom field
+	if err := h.services.CustomField.Delete(c.Request.Context(), id, userID); err != nil {
+		customFieldError(c, err, "deletion_failed", "Failed to delete custom field")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// customFieldParams extracts the authenticated user and the custom field ID,
+// writing the error response and reporting false if either is missing or invalid
+func customFieldParams(c *gin.Context) (uint, uint, bool) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, 0, false
+	}
+
+	// Parse custom field ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid custom field ID format",
+		})
+		return 0, 0, false
+	}
+
+	return userID, uint(id), true
+}
+
+// customFieldError writes the response for a custom field service error
+func customFieldError(c *gin.Context, err error, code, message string) {
+	switch {
+	case errors.Is(err, service.ErrCustomFieldNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Custom field not found",
+		})
+	case errors.Is(err, service.ErrListNotFound):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "list_not_found",
+			Message: "List not found",
+		})
+	case errors.Is(err, service.ErrCustomFieldExists):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "custom_field_exists",
+			Message: "A custom field with this key already exists",
+		})
+	case errors.Is(err, service.ErrCustomFieldLimit):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "custom_field_limit_reached",
+			Message: "A user can have at most 50 custom fields",
+		})
+	case errors.Is(err, service.ErrInvalidCustomField):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_custom_field",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
+
+// customFieldValueError writes the response for invalid custom field values
+// of a todo and reports whether err was one
+func customFieldValueError(c *gin.Context, err error) bool {
+	if !errors.Is(err, service.ErrInvalidCustomFieldValues) {
+		return false
+	}
+
+	response := model.ErrorResponse{
+		Error:   "invalid_custom_fields",
+		Message: "Custom field values are invalid",
+	}
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		response.Details = validator.FormatValidationErrors(validationErrors)
+	}
+	c.JSON(http.StatusUnprocessableEntity, response)
+	return true
+}
+
+// customFieldFilterError writes the response for an invalid custom field
+// condition or sorting of a todo listing and reports whether err was one
+func customFieldFilterError(c *gin.Context, err error) bool {
+	if !errors.Is(err, service.ErrInvalidCustomFieldFilter) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, model.ErrorResponse{
+		Error:   "invalid_filter",
+		Message: err.Error(),
+	})
+	return true
+}
+
+// customFieldFilter reads the custom field conditions and sorting of a todo
+// listing from the query: cf[key]=value matches a value, cf_gte[key] and
+// cf_lte[key] bound number and date values, and sort=cf.key or sort=-cf.key
+// orders by a value. It writes the error response and reports false if the
+// query is invalid.
+func customFieldFilter(c *gin.Context, filter *model.TodoFilter) bool {
+	for param, op := range map[string]string{
+		"cf":     model.CustomFieldEqual,
+		"cf_gte": model.CustomFieldAtLeast,
+		"cf_lte": model.CustomFieldAtMost,
+	} {
+		for key, value := range c.QueryMap(param) {
+			filter.CustomFields = append(filter.CustomFields, model.CustomFieldCondition{
+				Key:   key,
+				Op:    op,
+				Value: value,
+			})
+		}
+	}
+	// Query maps are unordered; keep the conditions in a stable order
+	sort.Slice(filter.CustomFields, func(i, j int) bool {
+		a, b := filter.CustomFields[i], filter.CustomFields[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Op < b.Op
+	})
+
+	if value := c.Query("sort"); value != "" {
+		desc := strings.HasPrefix(value, "-")
+		key, found := strings.CutPrefix(strings.TrimPrefix(value, "-"), "cf.")
+		if !found || key == "" {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_filter",
+				Message: "sort must name a custom field as cf.key or -cf.key",
+			})
+			return false
+		}
+		filter.SortField = &model.CustomFieldSort{Key: key, Desc: desc}
+	}
+	return true
+}
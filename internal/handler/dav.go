@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// XML namespaces of WebDAV, CalDAV and the CalendarServer extensions task apps rely on
+const (
+	davNamespace            = "DAV:"
+	calDAVNamespace         = "urn:ietf:params:xml:ns:caldav"
+	calendarServerNamespace = "http://calendarserver.org/ns/"
+
+	davContentType = "application/xml; charset=utf-8"
+)
+
+// davPrefixes maps the namespaces declared on every multistatus to their prefixes
+var davPrefixes = map[string]string{
+	davNamespace:            "d",
+	calDAVNamespace:         "cal",
+	calendarServerNamespace: "cs",
+}
+
+// Common property names
+var (
+	davResourceType            = xml.Name{Space: davNamespace, Local: "resourcetype"}
+	davDisplayName             = xml.Name{Space: davNamespace, Local: "displayname"}
+	davGetETag                 = xml.Name{Space: davNamespace, Local: "getetag"}
+	davGetContentType          = xml.Name{Space: davNamespace, Local: "getcontenttype"}
+	davGetLastModified         = xml.Name{Space: davNamespace, Local: "getlastmodified"}
+	davCurrentUserPrincipal    = xml.Name{Space: davNamespace, Local: "current-user-principal"}
+	davPrincipalURL            = xml.Name{Space: davNamespace, Local: "principal-URL"}
+	davOwner                   = xml.Name{Space: davNamespace, Local: "owner"}
+	davCurrentUserPrivilegeSet = xml.Name{Space: davNamespace, Local: "current-user-privilege-set"}
+	davSupportedReportSet      = xml.Name{Space: davNamespace, Local: "supported-report-set"}
+	calHomeSet                 = xml.Name{Space: calDAVNamespace, Local: "calendar-home-set"}
+	calUserAddressSet          = xml.Name{Space: calDAVNamespace, Local: "calendar-user-address-set"}
+	calSupportedComponentSet   = xml.Name{Space: calDAVNamespace, Local: "supported-calendar-component-set"}
+	calCalendarData            = xml.Name{Space: calDAVNamespace, Local: "calendar-data"}
+	csGetCTag                  = xml.Name{Space: calendarServerNamespace, Local: "getctag"}
+)
+
+// davProperty is a property of a resource with its value as XML
+type davProperty struct {
+	name  xml.Name
+	inner string
+}
+
+// davResponse is the response element of a multistatus for a single resource
+type davResponse struct {
+	href string
+	// status is set for resources that do not exist; found resources report per property
+	status int
+	props  []davProperty
+	// missing lists the requested properties the resource does not have
+	missing []xml.Name
+}
+
+// davPropfind is the body of a PROPFIND request. An empty body asks for all properties.
+type davPropfind struct {
+	XMLName  xml.Name      `xml:"DAV: propfind"`
+	AllProp  *struct{}     `xml:"DAV: allprop"`
+	PropName *struct{}     `xml:"DAV: propname"`
+	Prop     *davPropNames `xml:"DAV: prop"`
+}
+
+// davPropNames lists the properties a request asks for
+type davPropNames struct {
+	Names []davPropName `xml:",any"`
+}
+
+// davPropName is a single requested property
+type davPropName struct {
+	XMLName xml.Name
+}
+
+// davPropRequest describes which properties a PROPFIND or REPORT asks for
+type davPropRequest struct {
+	all   bool
+	names bool
+	props []xml.Name
+}
+
+// wants reports whether the request asks for a property by name. calendar-data
+// is expensive, so it is only returned when asked for explicitly.
+func (r *davPropRequest) wants(name xml.Name) bool {
+	for _, prop := range r.props {
+		if prop == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectProps returns the properties to report from those a resource has, and the
+// requested ones it lacks
+func (r *davPropRequest) selectProps(available []davProperty) ([]davProperty, []xml.Name) {
+	if r.all {
+		return available, nil
+	}
+	if r.names {
+		props := make([]davProperty, len(available))
+		for i, prop := range available {
+			props[i] = davProperty{name: prop.name}
+		}
+		return props, nil
+	}
+
+	var found []davProperty
+	var missing []xml.Name
+	for _, name := range r.props {
+		ok := false
+		for _, prop := range available {
+			if prop.name == name {
+				found = append(found, prop)
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+	return found, missing
+}
+
+// parsePropfind reads the properties a PROPFIND request asks for
+func parsePropfind(body io.Reader) (*davPropRequest, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxDAVRequestSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &davPropRequest{all: true}, nil
+	}
+
+	var propfind davPropfind
+	if err := xml.Unmarshal(data, &propfind); err != nil {
+		return nil, err
+	}
+	return newPropRequest(propfind.AllProp != nil, propfind.PropName != nil, propfind.Prop), nil
+}
+
+// newPropRequest builds a property request from the parsed elements of a request body
+func newPropRequest(all, names bool, prop *davPropNames) *davPropRequest {
+	switch {
+	case names:
+		return &davPropRequest{names: true}
+	case all || prop == nil:
+		return &davPropRequest{all: true}
+	}
+
+	req := &davPropRequest{}
+	for _, name := range prop.Names {
+		req.props = append(req.props, name.XMLName)
+	}
+	return req
+}
+
+// davDepth returns the Depth header of a request: 0 or 1. Infinity is served
+// as 1, which covers the whole hierarchy of this server.
+func davDepth(header string) int {
+	if strings.TrimSpace(header) == "0" {
+		return 0
+	}
+	return 1
+}
+
+// multistatusWriter streams a 207 Multi-Status response
+type multistatusWriter struct {
+	w       io.Writer
+	started bool
+}
+
+// begin writes the opening of the multistatus element
+func (m *multistatusWriter) begin() error {
+	m.started = true
+	_, err := io.WriteString(m.w, `<?xml version="1.0" encoding="utf-8"?>`+"\n"+
+		`<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`+"\n")
+	return err
+}
+
+// write writes the response element of a resource
+func (m *multistatusWriter) write(r *davResponse) error {
+	var b strings.Builder
+	b.WriteString("<d:response><d:href>")
+	b.WriteString(escapeXML(r.href))
+	b.WriteString("</d:href>")
+
+	if r.status != 0 {
+		b.WriteString("<d:status>" + davStatus(r.status) + "</d:status>")
+	} else {
+		if len(r.props) > 0 || len(r.missing) == 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, prop := range r.props {
+				writeDAVElement(&b, prop.name, prop.inner)
+			}
+			b.WriteString("</d:prop><d:status>" + davStatus(http.StatusOK) + "</d:status></d:propstat>")
+		}
+		if len(r.missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range r.missing {
+				writeDAVElement(&b, name, "")
+			}
+			b.WriteString("</d:prop><d:status>" + davStatus(http.StatusNotFound) + "</d:status></d:propstat>")
+		}
+	}
+
+	b.WriteString("</d:response>\n")
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+// end writes the closing of the multistatus element
+func (m *multistatusWriter) end() error {
+	_, err := io.WriteString(m.w, "</d:multistatus>\n")
+	return err
+}
+
+// davElement returns an element in one of the declared namespaces, for property values
+func davElement(name xml.Name, inner string) string {
+	var b strings.Builder
+	writeDAVElement(&b, name, inner)
+	return b.String()
+}
+
+// davHref returns an href element
+func davHref(href string) string {
+	return "<d:href>" + escapeXML(href) + "</d:href>"
+}
+
+// writeDAVElement writes an element, declaring its namespace unless it is one
+// of those declared on the multistatus
+func writeDAVElement(b *strings.Builder, name xml.Name, inner string) {
+	tag := name.Local
+	attrs := ""
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		attrs = ` xmlns:x="` + escapeXML(name.Space) + `"`
+	}
+
+	if inner == "" {
+		b.WriteString("<" + tag + attrs + "/>")
+		return
+	}
+	b.WriteString("<" + tag + attrs + ">" + inner + "</" + tag + ">")
+}
+
+// davError returns the body of an error response naming the failed precondition
+func davError(precondition xml.Name) []byte {
+	return []byte(`<?xml version="1.0" encoding="utf-8"?>` + "\n" +
+		`<d:error xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">` + davElement(precondition, "") + "</d:error>\n")
+}
+
+// davStatus returns the status line of a propstat or response element
+func davStatus(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// escapeXML escapes text for use in XML character data and attribute values
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
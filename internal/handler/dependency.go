@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// GetTodoDependencies handles retrieving the dependencies of a todo
+// @Summary Get todo dependencies
+// @Description Retrieve the todos that block a todo and the todos it blocks, ensuring user ownership. A todo is blocked while a todo blocking it is neither completed nor in the trash.
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {object} model.TodoDependenciesResponse "Dependencies retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/dependencies [get]
+func (h *Handler) GetTodoDependencies(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the dependencies
+	response, err := h.services.Todo.GetDependencies(c.Request.Context(), id, userID)
+	if err != nil {
+		dependencyError(c, err, "retrieval_failed", "Failed to retrieve dependencies")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddTodoDependency handles relating a todo to another todo
+// @Summary Add a todo dependency
+// @Description Make a todo blocked by another todo of the user with blocked_by, or make it block another todo with blocks. Dependencies that would form a cycle are rejected, and the error names the cycle.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param request body model.AddDependencyRequest true "Related todo"
+// @Success 201 {object} model.TodoDependency "Dependency successfully added"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 409 {object} model.ErrorResponse "Dependency exists or would form a cycle"
+// @Failure 422 {object} model.ErrorResponse "Related todo not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/dependencies [post]
+func (h *Handler) AddTodoDependency(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{"BlockedBy": "Exactly one of blocked_by and blocks is required"},
+		})
+		return
+	}
+
+	// Call service to add the dependency
+	dependency, err := h.services.Todo.AddDependency(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		dependencyError(c, err, "creation_failed", "Failed to add dependency")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dependency)
+}
+
+// RemoveTodoDependency handles removing the dependency between two todos
+// @Summary Remove a todo dependency
+// @Description Remove the dependency between a todo and another todo, whichever of them blocks the other, ensuring user ownership
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param other_id path int true "ID of the related todo"
+// @Success 204 "Dependency successfully removed"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo or dependency not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/dependencies/{other_id} [delete]
+func (h *Handler) RemoveTodoDependency(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	otherID, err := strconv.ParseUint(c.Param("other_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid todo ID format",
+		})
+		return
+	}
+
+	// Call service to remove the dependency
+	if err := h.services.Todo.RemoveDependency(c.Request.Context(), id, uint(otherID), userID); err != nil {
+		dependencyError(c, err, "deletion_failed", "Failed to remove dependency")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDependencyGraph handles retrieving the dependency graph of the authenticated user
+// @Summary Get the dependency graph
+// @Description Retrieve every todo outside the trash that blocks or is blocked by another todo, the dependencies between them as edges, and an execution order of their IDs in which every todo comes after the todos blocking it. Todos that can start at the same time are ordered by ID.
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.DependencyGraphResponse "Dependency graph retrieved successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/dependencies [get]
+func (h *Handler) GetDependencyGraph(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to get the graph
+	graph, err := h.services.Todo.GetDependencyGraph(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve dependency graph",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// dependencyParams extracts the authenticated user and the todo ID, writing
+// the error response and reporting false if either is missing or invalid
+func dependencyParams(c *gin.Context) (uint, uint, bool) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, 0, false
+	}
+
+	// Parse todo ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid todo ID format",
+		})
+		return 0, 0, false
+	}
+
+	return userID, uint(id), true
+}
+
+// dependencyError writes the response for a dependency service error
+func dependencyError(c *gin.Context, err error, code, message string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound), errors.Is(err, service.ErrUnauthorizedAccess):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Todo not found",
+		})
+	case errors.Is(err, service.ErrDependencyNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Dependency not found",
+		})
+	case errors.Is(err, service.ErrInvalidDependency), errors.Is(err, service.ErrSelfDependency):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrDependencyTodoNotFound):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "related_todo_not_found",
+			Message: "The related todo does not exist or is in the trash",
+		})
+	case errors.Is(err, service.ErrDependencyExists):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "dependency_exists",
+			Message: "The todos already have this dependency",
+		})
+	case errors.Is(err, service.ErrDependencyCycle):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "dependency_cycle",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// blockedETagSuffix marks the entity tag of a todo that is blocked by another
+// one. Blocked is computed rather than stored, so completing a blocker changes
+// the representation of a todo without changing its version.
+const blockedETagSuffix = "-blocked"
+
+// todoETag returns the strong entity tag of the current representation of a
+// todo: its version, and whether it is blocked
+func todoETag(todo *model.Todo) string {
+	tag := strconv.FormatUint(uint64(todo.Version), 10)
+	if todo.Blocked {
+		tag += blockedETagSuffix
+	}
+	return `"` + tag + `"`
+}
+
+// conditionalContext returns the request context, carrying the todo versions
+// accepted by the If-Match header when the request has one. Weak and
+// unrecognised tags never match, as If-Match requires strong comparison.
+// Writes only depend on the version, so a tag from before the todo became
+// blocked or unblocked still matches.
+func conditionalContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return ctx
+	}
+
+	versions := []uint{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") || len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.ParseUint(strings.TrimSuffix(tag[1:len(tag)-1], blockedETagSuffix), 10, 32); err == nil {
+			versions = append(versions, uint(version))
+		}
+	}
+	return service.WithIfMatch(ctx, versions...)
+}
+
+// noneMatch reports whether the If-None-Match header of the request matches
+// etag, using the weak comparison RFC 9110 requires for If-None-Match
+func noneMatch(c *gin.Context, etag string) bool {
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/events"
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+const (
+	// defaultHeartbeat is how often idle event streams send a keep-alive comment
+	defaultHeartbeat = 15 * time.Second
+
+	// eventRetryMillis tells EventSource clients how long to wait before reconnecting
+	eventRetryMillis = 3000
+
+	// resetEvent tells a client that missed events cannot be replayed
+	resetEvent = "reset"
+)
+
+// StreamEvents handles streaming the authenticated user's todo changes
+// @Summary Stream todo change events
+// @Description Open a Server-Sent Events stream of todo.created, todo.updated and todo.deleted events for the user's own todos. Each event carries its id; a reconnecting client sends the last id it received in the Last-Event-ID header to receive the events it missed. If those events are no longer available the stream starts with a reset event and the client should reload its todos. Idle streams receive a comment line at a regular interval to keep proxies from closing the connection.
+// @Tags events
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {object} events.Event "Stream of change events"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Router /api/v1/events [get]
+func (h *Handler) StreamEvents(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// An ID that does not parse was never issued and resumes nothing
+	lastEventHeader := c.GetHeader("Last-Event-ID")
+	resume := lastEventHeader != ""
+	lastEventID, _ := strconv.ParseUint(lastEventHeader, 10, 64)
+
+	sub, replay, ok := h.services.Events.Subscribe(userID, lastEventID, resume)
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Failed to clear write deadline for event stream: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", eventRetryMillis)
+	if !ok {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", resetEvent)
+	}
+	for _, event := range replay {
+		writeEvent(c.Writer, event)
+	}
+	c.Writer.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, open := <-sub.Events():
+			if !open {
+				// The client fell behind; it reconnects and resumes from the replay buffer
+				return
+			}
+			writeEvent(c.Writer, event)
+			c.Writer.Flush()
+		case <-heartbeat:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeEvent writes a change event in Server-Sent Events format
+func writeEvent(w io.Writer, event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode event %d: %v", event.ID, err)
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	
+
 	"todo-api-backend/internal/service"
 )
 
@@ -11,39 +13,200 @@ import (
 type Handler struct {
 	services  *service.Services
 	validator *validator.Validate
+	heartbeat time.Duration
+}
+
+// Option configures optional settings of the handlers
+type Option func(*Handler)
+
+// WithHeartbeat sets how often idle event streams and WebSockets send a keep-alive message; 0 disables heartbeats
+func WithHeartbeat(interval time.Duration) Option {
+	return func(h *Handler) {
+		h.heartbeat = interval
+	}
 }
 
 // NewHandler creates a new Handler instance with service dependencies
-func NewHandler(services *service.Services) *Handler {
-	return &Handler{
+func NewHandler(services *service.Services, opts ...Option) *Handler {
+	h := &Handler{
 		services:  services,
 		validator: validator.New(),
+		heartbeat: defaultHeartbeat,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// MaxRequestBodySize returns the largest request body any route accepts, so
+// middleware that buffers bodies can reject larger ones up front
+func (h *Handler) MaxRequestBodySize() int64 {
+	size := int64(maxImportSize)
+	if h.services.Attachment != nil {
+		size = max(size, h.services.Attachment.MaxSize()+multipartOverhead)
+	}
+	return size
 }
 
 // RegisterRoutes registers all HTTP routes with the Gin router
 func (h *Handler) RegisterRoutes(router *gin.Engine) {
 	// API v1 routes
 	v1 := router.Group("/api/v1")
-	
+
 	// Authentication routes (public)
 	auth := v1.Group("/auth")
 	{
 		auth.POST("/register", h.Register)
 		auth.POST("/login", h.Login)
 	}
-	
+
+	// Account routes (protected - JWT middleware is applied in the main server setup)
+	v1.PUT("/auth/password", h.ChangePassword)
+
 	// Todo routes (protected - will be implemented with JWT middleware)
 	todos := v1.Group("/todos")
 	// Note: JWT middleware will be applied to these routes in the main server setup
 	{
 		todos.POST("", h.CreateTodo)
 		todos.GET("", h.GetTodos)
+		todos.GET("/search", h.SearchTodos)
+		todos.POST("/bulk", h.BulkTodos)
+		todos.POST("/quick", h.QuickAddTodo)
+		todos.GET("/export", h.ExportTodos)
+		todos.POST("/import", h.ImportTodos)
+		todos.GET("/trash", h.GetTrash)
+		todos.DELETE("/trash", h.EmptyTrash)
+		todos.DELETE("/trash/:id", h.PurgeTodo)
+		todos.POST("/archive", h.ArchiveCompletedTodos)
+		todos.GET("/dependencies", h.GetDependencyGraph)
+		todos.GET("/archive/policy", h.GetArchivePolicy)
+		todos.PUT("/archive/policy", h.UpdateArchivePolicy)
 		todos.GET("/:id", h.GetTodo)
 		todos.PUT("/:id", h.UpdateTodo)
+		todos.PATCH("/:id", h.PatchTodo)
 		todos.DELETE("/:id", h.DeleteTodo)
+		todos.POST("/:id/restore", h.RestoreTodo)
+		todos.POST("/:id/archive", h.ArchiveTodo)
+		todos.POST("/:id/unarchive", h.UnarchiveTodo)
+		todos.GET("/:id/history", h.GetTodoHistory)
+		todos.GET("/:id/dependencies", h.GetTodoDependencies)
+		todos.POST("/:id/dependencies", h.AddTodoDependency)
+		todos.DELETE("/:id/dependencies/:other_id", h.RemoveTodoDependency)
+		todos.POST("/:id/move", h.MoveTodo)
+		todos.GET("/:id/comments", h.GetTodoComments)
+		todos.POST("/:id/comments", h.AddTodoComment)
+		todos.PUT("/:id/comments/:comment_id", h.UpdateTodoComment)
+		todos.DELETE("/:id/comments/:comment_id", h.DeleteTodoComment)
+		todos.POST("/:id/attachments", h.UploadTodoAttachment)
+		todos.GET("/:id/attachments", h.GetTodoAttachments)
+		todos.GET("/:id/attachments/:attachment_id", h.GetTodoAttachment)
+		todos.DELETE("/:id/attachments/:attachment_id", h.DeleteTodoAttachment)
+	}
+
+	// Event stream route (protected - JWT middleware is applied in the main server setup)
+	v1.GET("/events", h.StreamEvents)
+
+	// Live collaboration WebSocket (protected - JWT middleware accepting an access_token query parameter is applied in the main server setup)
+	v1.GET("/ws", h.Live)
+
+	// Webhook routes (protected - JWT middleware is applied in the main server setup)
+	webhooks := v1.Group("/webhooks")
+	{
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("", h.GetWebhooks)
+		webhooks.GET("/:id", h.GetWebhook)
+		webhooks.PUT("/:id", h.UpdateWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.GetWebhookDeliveries)
+	}
+
+	// Template routes (protected - JWT middleware is applied in the main server setup)
+	templates := v1.Group("/templates")
+	{
+		templates.POST("", h.CreateTemplate)
+		templates.GET("", h.GetTemplates)
+		templates.GET("/:id", h.GetTemplate)
+		templates.PUT("/:id", h.UpdateTemplate)
+		templates.DELETE("/:id", h.DeleteTemplate)
+		templates.POST("/:id/instantiate", h.InstantiateTemplate)
+	}
+
+	// List routes (protected - JWT middleware is applied in the main server setup)
+	lists := v1.Group("/lists")
+	{
+		lists.POST("", h.CreateList)
+		lists.GET("", h.GetLists)
+		lists.GET("/:id", h.GetList)
+		lists.PUT("/:id", h.UpdateList)
+		lists.DELETE("/:id", h.DeleteList)
+		lists.GET("/:id/board", h.GetListBoard)
 	}
-	
+
+	// Custom field routes (protected - JWT middleware is applied in the main server setup)
+	customFields := v1.Group("/custom-fields")
+	{
+		customFields.POST("", h.CreateCustomField)
+		customFields.GET("", h.GetCustomFields)
+		customFields.GET("/:id", h.GetCustomField)
+		customFields.PUT("/:id", h.UpdateCustomField)
+		customFields.DELETE("/:id", h.DeleteCustomField)
+	}
+
+	// Calendar feed routes (protected - JWT middleware is applied in the main server setup)
+	calendar := v1.Group("/calendar")
+	{
+		calendar.GET("/feed", h.GetCalendarFeed)
+		calendar.POST("/feed", h.RegenerateCalendarToken)
+		calendar.DELETE("/feed", h.DisableCalendarFeed)
+	}
+
+	// Calendar feed subscriptions (public - the secret token in the URL authenticates calendar apps)
+	calendar.GET("/:token", h.ServeCalendarFeed)
+
+	// Attachment downloads (public - the signature in the URL authenticates the download until it expires)
+	v1.GET("/attachments/:id/download", h.DownloadAttachment)
+
+	// CalDAV app password routes (protected - JWT middleware is applied in the main server setup)
+	caldav := v1.Group("/caldav")
+	{
+		caldav.GET("/passwords", h.GetCalDAVPasswords)
+		caldav.POST("/passwords", h.CreateCalDAVPassword)
+		caldav.DELETE("/passwords/:id", h.DeleteCalDAVPassword)
+	}
+
+	// Sync routes (protected - JWT middleware is applied in the main server setup)
+	v1.GET("/sync", h.PullChanges)
+	v1.POST("/sync", h.PushChanges)
+
+	// Admin routes (protected - JWT and admin middleware are applied in the main server setup)
+	admin := v1.Group("/admin")
+	{
+		admin.GET("/audit-log", h.GetSecurityEvents)
+	}
+
+	// CalDAV server (Basic auth with app passwords is applied in the main server setup)
+	h.RegisterCalDAVRoutes(router)
+
 	// Health check route
 	router.GET("/health", h.HealthCheck)
-}
\ No newline at end of file
+}
+
+// RegisterCalDAVRoutes registers the CalDAV server for task apps. Discovery and
+// OPTIONS are public; the middleware authenticates everything else.
+func (h *Handler) RegisterCalDAVRoutes(router gin.IRouter, middleware ...gin.HandlerFunc) {
+	router.GET("/.well-known/caldav", h.CalDAVWellKnown)
+	router.Handle("PROPFIND", "/.well-known/caldav", h.CalDAVWellKnown)
+	router.OPTIONS("/caldav/*path", h.CalDAVOptions)
+
+	caldav := router.Group("/caldav", middleware...)
+	{
+		caldav.Handle("PROPFIND", "/", h.PropfindCalDAVHome)
+		caldav.Handle("PROPFIND", "/todos/", h.PropfindCalDAVCollection)
+		caldav.Handle("REPORT", "/todos/", h.ReportCalDAVCollection)
+		caldav.Handle("PROPFIND", "/todos/:name", h.PropfindCalDAVObject)
+		caldav.GET("/todos/:name", h.GetCalDAVObject)
+		caldav.PUT("/todos/:name", h.PutCalDAVObject)
+		caldav.DELETE("/todos/:name", h.DeleteCalDAVObject)
+	}
+}
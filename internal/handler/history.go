@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+// GetTodoHistory handles retrieving the change history of a todo
+// @Summary Get todo history
+// @Description Retrieve the field-level change history of a todo in chronological order, ensuring user ownership
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {object} model.TodoHistoryResponse "Todo history retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/history [get]
+func (h *Handler) GetTodoHistory(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Parse todo ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid todo ID format",
+		})
+		return
+	}
+
+	// Call service to get history
+	history, err := h.services.Todo.GetHistory(c.Request.Context(), uint(id), userID)
+	if err != nil {
+		switch err.Error() {
+		case "todo not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Todo not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "retrieval_failed",
+				Message: "Failed to retrieve todo history",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TodoHistoryResponse{
+		TodoID:  uint(id),
+		History: history,
+		Count:   len(history),
+	})
+}
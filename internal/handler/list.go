@@ -0,0 +1,399 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// CreateList handles creating a todo list
+// @Summary Create a list
+// @Description Create a list of todos with a workflow: ordered states, such as Backlog, In Progress, Review and Done, and the transitions allowed between them. New todos start in the first state, which must not be terminal, and a todo is completed while it is in a terminal state. Lists without a workflow get a To Do and a Done state.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateListRequest true "List creation request"
+// @Success 201 {object} model.TodoList "List successfully created"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data, validation failed or invalid workflow"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 409 {object} model.ErrorResponse "List limit reached"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/lists [post]
+func (h *Handler) CreateList(c *gin.Context) {
+	var req model.CreateListRequest
+
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to create the list
+	list, err := h.services.List.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		listError(c, err, "creation_failed", "Failed to create list")
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+// GetLists handles listing the lists of the authenticated user
+// @Summary Get all lists
+// @Description Retrieve the lists of the authenticated user with their workflows
+// @Tags lists
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.ListListResponse "Lists retrieved successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/lists [get]
+func (h *Handler) GetLists(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to list lists
+	lists, err := h.services.List.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve lists",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ListListResponse{
+		Lists: lists,
+		Count: len(lists),
+	})
+}
+
+// GetList handles retrieving a single list
+// @Summary Get a list
+// @Description Retrieve a list with its workflow, ensuring user ownership
+// @Tags lists
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "List ID"
+// @Success 200 {object} model.TodoList "List retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid list ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "List not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/lists/{id} [get]
+func (h *Handler) GetList(c *gin.Context) {
+	userID, id, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the list
+	list, err := h.services.List.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		listError(c, err, "retrieval_failed", "Failed to retrieve list")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// UpdateList handles renaming a list or replacing its workflow
+// @Summary Update a list
+// @Description Rename a list or replace its workflow, ensuring user ownership. A new workflow must keep every state that todos of the list are in, with the same terminal flag.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "List ID"
+// @Param request body model.UpdateListRequest true "List update request"
+// @Success 200 {object} model.TodoList "List successfully updated"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data, validation failed or invalid workflow"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "List not found"
+// @Failure 409 {object} model.ErrorResponse "Workflow removes or changes states in use"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/lists/{id} [put]
+func (h *Handler) UpdateList(c *gin.Context) {
+	userID, id, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.UpdateListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to update the list
+	list, err := h.services.List.Update(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		listError(c, err, "update_failed", "Failed to update list")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// DeleteList handles removing a list
+// @Summary Delete a list
+// @Description Remove a list, ensuring user ownership. Its todos are kept outside any list and stay completed or not completed.
+// @Tags lists
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "List ID"
+// @Success 204 "List successfully deleted"
+// @Failure 400 {object} model.ErrorResponse "Invalid list ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "List not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/lists/{id} [delete]
+func (h *Handler) DeleteList(c *gin.Context) {
+	userID, id, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to delete the list
+	if err := h.services.List.Delete(c.Request.Context(), id, userID); err != nil {
+		listError(c, err, "deletion_failed", "Failed to delete list")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetListBoard handles retrieving the todos of a list grouped by state
+// @Summary Get the board of a list
+// @Description Retrieve the todos of a list that are neither archived nor trashed as a Kanban board: one column per workflow state in workflow order, each with its todos oldest first
+// @Tags lists
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "List ID"
+// @Success 200 {object} model.BoardResponse "Board retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid list ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "List not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/lists/{id}/board [get]
+func (h *Handler) GetListBoard(c *gin.Context) {
+	userID, id, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the board
+	board, err := h.services.Todo.GetBoard(c.Request.Context(), id, userID)
+	if err != nil {
+		listError(c, err, "retrieval_failed", "Failed to retrieve board")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// MoveTodo handles moving a todo between lists and workflow states
+// @Summary Move a todo
+// @Description Move a todo to another state of its list, following the transitions of the workflow, into another list or, with a null list_id, out of its list. A todo entering a list starts in the given state, or in the first state of the workflow (the first terminal state for completed todos). The todo is completed while it is in a terminal state.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag the todo must still have"
+// @Param request body model.MoveTodoRequest true "Target list and state"
+// @Success 200 {object} model.Todo "Todo successfully moved"
+// @Header 200 {string} ETag "Version of the moved todo"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 409 {object} model.ErrorResponse "Transition not allowed by the workflow"
+// @Failure 412 {object} model.ErrorResponse "If-Match ETag does not match the current todo"
+// @Failure 422 {object} model.ErrorResponse "List or state not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/move [post]
+func (h *Handler) MoveTodo(c *gin.Context) {
+	userID, id, ok := dependencyParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.MoveTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to move the todo
+	todo, err := h.services.Todo.MoveTodo(conditionalContext(c), id, &req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTodoNotFound), errors.Is(err, service.ErrUnauthorizedAccess):
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Todo not found",
+			})
+		case errors.Is(err, service.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, model.ErrorResponse{
+				Error:   "precondition_failed",
+				Message: "Todo has been modified since it was retrieved",
+			})
+		case errors.Is(err, service.ErrConcurrentUpdate):
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Error:   "conflict",
+				Message: "Todo was modified concurrently, please retry",
+			})
+		default:
+			if !workflowError(c, err) {
+				c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+					Error:   "update_failed",
+					Message: "Failed to move todo",
+				})
+			}
+		}
+		return
+	}
+
+	c.Header("ETag", todoETag(todo))
+	c.JSON(http.StatusOK, todo)
+}
+
+// listParams extracts the authenticated user and the list ID, writing the
+// error response and reporting false if either is missing or invalid
+func listParams(c *gin.Context) (uint, uint, bool) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, 0, false
+	}
+
+	// Parse list ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid list ID format",
+		})
+		return 0, 0, false
+	}
+
+	return userID, uint(id), true
+}
+
+// listError writes the response for a list service error
+func listError(c *gin.Context, err error, code, message string) {
+	switch {
+	case errors.Is(err, service.ErrListNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "List not found",
+		})
+	case errors.Is(err, service.ErrListLimit):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "list_limit_reached",
+			Message: "A user can have at most 100 lists",
+		})
+	case errors.Is(err, service.ErrInvalidWorkflow):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_workflow",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrWorkflowStateInUse):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "state_in_use",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
+
+// workflowError writes the response for an error of moving a todo between
+// lists or workflow states and reports whether err was one
+func workflowError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrListNotFound):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "list_not_found",
+			Message: "List not found",
+		})
+	case errors.Is(err, service.ErrUnknownState):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "unknown_state",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrStateWithoutList):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "not_in_list",
+			Message: "Only todos in a list have a workflow state",
+		})
+	case errors.Is(err, service.ErrTransitionNotAllowed):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "transition_not_allowed",
+			Message: err.Error(),
+		})
+	default:
+		return false
+	}
+	return true
+}
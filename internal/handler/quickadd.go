@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+	"todo-api-backend/pkg/quickadd"
+)
+
+// QuickAddTodo handles creating a todo from a single line of text
+// @Summary Quick-add a todo
+// @Description Create a todo from a line of text such as "Call Bob tomorrow 3pm !high #sales" and return it together with how the text was read. Relative dates (today, tomorrow, next week, in 2 days, friday) and absolute dates (2024-01-05, jan 5, 4/15) are resolved in the given time zone; dates without a time are all-day dates stored at midnight UTC. Priorities are written as !high, !medium, !low, !!!, !!, ! or p1 to p3, and #words are tags. Text in double quotes is kept as is. The locale, taken from the request, the Accept-Language header or English, selects the words and date order: en (US), en-GB, de and fr are supported. Todos have no priority or tag fields, so the title keeps them as a pri: tag and #tags.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Accept-Language header string false "Locale used when the request names none"
+// @Param request body model.QuickAddRequest true "Text of the todo"
+// @Success 201 {object} model.QuickAddResponse "Todo successfully created"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data, locale or time zone"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 422 {object} model.ErrorResponse "Text does not describe a valid todo"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/quick [post]
+func (h *Handler) QuickAddTodo(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req model.QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		details := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			switch err.Tag() {
+			case "required":
+				details[err.Field()] = "This field is required"
+			case "max":
+				details[err.Field()] = fmt.Sprintf("Must be at most %s characters long", err.Param())
+			default:
+				details[err.Field()] = "Invalid value"
+			}
+		}
+
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: details,
+		})
+		return
+	}
+
+	if req.Locale == "" {
+		if locale := quickadd.Negotiate(c.GetHeader("Accept-Language")); locale != nil {
+			req.Locale = locale.Tag
+		}
+	}
+
+	// Call service to parse the text and create the todo
+	response, err := h.services.Todo.QuickAdd(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedLocale):
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "unsupported_locale",
+				Message: "locale must be one of: en, en-US, en-GB, de, fr",
+			})
+		case errors.Is(err, service.ErrInvalidTimezone):
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_timezone",
+				Message: "timezone must be an IANA time zone such as Europe/Berlin",
+			})
+		case errors.Is(err, service.ErrInvalidQuickAdd):
+			c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+				Error:   "invalid_todo",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "creation_failed",
+				Message: "Failed to create todo",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
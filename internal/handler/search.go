@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+// SearchTodos handles full-text search over the authenticated user's todos
+// @Summary Search todos
+// @Description Full-text search over todo titles and descriptions, most relevant first. Quoted text matches a phrase and a trailing * matches word prefixes. Highlights are HTML-escaped with matches wrapped in <mark> tags.
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search query, e.g. \"quarterly report\" bob*"
+// @Param archived query string false "Archived filter: false (default), true or all" Enums(false, true, all)
+// @Param limit query int false "Maximum number of results (default 20, max 100)"
+// @Success 200 {object} model.TodoSearchResponse "Search results retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid search query"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/search [get]
+func (h *Handler) SearchTodos(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	req := model.SearchTodosRequest{
+		Query:    c.Query("q"),
+		Archived: c.Query("archived"),
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_query",
+				Message: "limit must be an integer",
+			})
+			return
+		}
+		req.Limit = limit
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_query",
+			Message: "q is required (max 256 characters), archived must be one of: false, true, all and limit must be between 1 and 100",
+		})
+		return
+	}
+
+	// Call service to search todos
+	results, err := h.services.Todo.Search(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "search query is empty":
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_query",
+				Message: "Search query must contain at least one word",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "search_failed",
+				Message: "Failed to search todos",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TodoSearchResponse{
+		Query:   req.Query,
+		Results: results,
+		Count:   len(results),
+	})
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+// PullChanges handles fetching the todo changes since a sync token
+// @Summary Pull todo changes
+// @Description Get the todos created or updated and the todos deleted since the sync token. Pass the returned token as since on the next pull. Without a token, or when the token is too old to be served, every todo is returned and reset is true, telling the client to replace its local copy. Changes close to the token may be returned twice; apply todos by version.
+// @Tags sync
+// @Produce json
+// @Security BearerAuth
+// @Param since query string false "Sync token returned by the previous pull"
+// @Success 200 {object} model.SyncResponse "Changes since the token"
+// @Failure 400 {object} model.ErrorResponse "Invalid sync token"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/sync [get]
+func (h *Handler) PullChanges(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to collect the changes
+	response, err := h.services.Todo.PullChanges(c.Request.Context(), userID, c.Query("since"))
+	if err != nil {
+		switch err.Error() {
+		case "invalid sync token":
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_sync_token",
+				Message: "The since parameter is not a valid sync token",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "sync_failed",
+				Message: "Failed to get changes",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PushChanges handles applying a batch of offline client mutations
+// @Summary Push client changes
+// @Description Apply create, update and delete mutations a client made while offline, in order. Updates and deletes carry the version the client last saw as base_version, or only client_updated_at when the version is unknown; mutations of todos that changed on the server since then are not applied but reported as conflicts with the server's copy of the todo. Returns 200 when every mutation was applied and 207 otherwise.
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SyncPushRequest true "Client mutations"
+// @Success 200 {object} model.SyncPushResponse "All mutations applied"
+// @Success 207 {object} model.SyncPushResponse "Some mutations conflicted or failed"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/sync [post]
+func (h *Handler) PushChanges(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req model.SyncPushRequest
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		details := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			switch err.Tag() {
+			case "required":
+				details[err.Field()] = "This field is required"
+			case "min":
+				details[err.Field()] = "At least one mutation is required"
+			case "max":
+				details[err.Field()] = "At most 100 mutations are allowed and client ids are limited to 255 characters"
+			case "oneof":
+				details[err.Field()] = "Must be one of: create, update, delete"
+			default:
+				details[err.Field()] = "Invalid value"
+			}
+		}
+
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: details,
+		})
+		return
+	}
+
+	// Call service to apply the mutations
+	response, err := h.services.Todo.PushChanges(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "sync_failed",
+			Message: "Failed to apply changes",
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if response.Conflicts > 0 || response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, response)
+}
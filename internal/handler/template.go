@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// CreateTemplate handles creating a todo template
+// @Summary Create a template
+// @Description Store a reusable tree of todos, such as an onboarding checklist. Titles and descriptions may contain placeholders such as {{name}} that are filled in when the template is instantiated, and due_offset_days sets the due date in days after the start date of an instantiation. A template has at most 200 items nested at most 5 levels deep.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateTemplateRequest true "Template creation request"
+// @Success 201 {object} model.TodoTemplate "Template successfully created"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 409 {object} model.ErrorResponse "Template limit reached"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/templates [post]
+func (h *Handler) CreateTemplate(c *gin.Context) {
+	var req model.CreateTemplateRequest
+
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to create the template
+	template, err := h.services.Template.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		templateError(c, err, "creation_failed", "Failed to create template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetTemplates handles listing the templates of the authenticated user
+// @Summary Get all templates
+// @Description Retrieve the templates of the authenticated user with their items and the placeholders they use
+// @Tags templates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.TemplateListResponse "List of templates retrieved successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/templates [get]
+func (h *Handler) GetTemplates(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to list templates
+	templates, err := h.services.Template.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve templates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TemplateListResponse{
+		Templates: templates,
+		Count:     len(templates),
+	})
+}
+
+// GetTemplate handles retrieving a single template
+// @Summary Get a template
+// @Description Retrieve a template with its items and the placeholders they use, ensuring user ownership
+// @Tags templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} model.TodoTemplate "Template retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid template ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Template not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/templates/{id} [get]
+func (h *Handler) GetTemplate(c *gin.Context) {
+	userID, id, ok := templateParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the template
+	template, err := h.services.Template.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		templateError(c, err, "retrieval_failed", "Failed to retrieve template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate handles replacing a template
+// @Summary Update a template
+// @Description Replace the name, description and items of a template, ensuring user ownership. Todos already created from the template are not changed.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param request body model.UpdateTemplateRequest true "Template update request"
+// @Success 200 {object} model.TodoTemplate "Template successfully updated"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Template not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/templates/{id} [put]
+func (h *Handler) UpdateTemplate(c *gin.Context) {
+	userID, id, ok := templateParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to update the template
+	template, err := h.services.Template.Update(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		templateError(c, err, "update_failed", "Failed to update template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate handles removing a template
+// @Summary Delete a template
+// @Description Remove a template, ensuring user ownership. Todos created from the template are kept.
+// @Tags templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 204 "Template successfully deleted"
+// @Failure 400 {object} model.ErrorResponse "Invalid template ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Template not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/templates/{id} [delete]
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	userID, id, ok := templateParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to delete the template
+	if err := h.services.Template.Delete(c.Request.Context(), id, userID); err != nil {
+		templateError(c, err, "deletion_failed", "Failed to delete template")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// InstantiateTemplate handles creating the todos of a template
+// @Summary Instantiate a template
+// @Description Create a todo for every item of a template in a single transaction: either all todos are created or none. Placeholders such as {{name}} are replaced by the given variables, and every placeholder the template uses must have a value. Items with due_offset_days get an all-day due date, stored at midnight UTC, that many days after start_date (default today). The todos are returned parents first, each with the todo created from its parent item.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param request body model.InstantiateTemplateRequest true "Placeholder values and start date"
+// @Success 201 {object} model.InstantiateTemplateResponse "Todos successfully created"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Template not found"
+// @Failure 422 {object} model.ErrorResponse "Missing variables or an item does not describe a valid todo"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/templates/{id}/instantiate [post]
+func (h *Handler) InstantiateTemplate(c *gin.Context) {
+	userID, id, ok := templateParams(c)
+	if !ok {
+		return
+	}
+
+	// An empty body instantiates a template without placeholders from today
+	var req model.InstantiateTemplateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid JSON format",
+			})
+			return
+		}
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, nestedValidationError(err))
+		return
+	}
+
+	// Call service to create the todos
+	response, err := h.services.Template.Instantiate(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		templateError(c, err, "instantiation_failed", "Failed to instantiate template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// templateParams extracts the authenticated user and the template ID, writing
+// the error response and reporting false if either is missing or invalid
+func templateParams(c *gin.Context) (uint, uint, bool) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, 0, false
+	}
+
+	// Parse template ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid template ID format",
+		})
+		return 0, 0, false
+	}
+
+	return userID, uint(id), true
+}
+
+// templateError writes the response for a template service error
+func templateError(c *gin.Context, err error, code, message string) {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Template not found",
+		})
+	case errors.Is(err, service.ErrTemplateLimit):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "template_limit_reached",
+			Message: "A user can store at most 100 templates",
+		})
+	case errors.Is(err, service.ErrTemplateTooLarge):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "template_too_large",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidStartDate):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{"StartDate": "Must be a date in YYYY-MM-DD format"},
+		})
+	case errors.Is(err, service.ErrMissingTemplateVariables):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "missing_variables",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidTemplateTodo):
+		c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "invalid_todo",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
+
+// nestedValidationError describes the validation errors of a request with nested
+// fields, which are named by their path, such as Items[0].Children[1].Title.
+func nestedValidationError(err error) model.ErrorResponse {
+	details := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		switch err.Tag() {
+		case "required":
+			details[field] = "This field is required"
+		case "min":
+			switch err.Kind() {
+			case reflect.Slice:
+				details[field] = "At least one item is required"
+			case reflect.String:
+				details[field] = fmt.Sprintf("Must be at least %s characters long", err.Param())
+			default:
+				details[field] = fmt.Sprintf("Must be at least %s", err.Param())
+			}
+		case "max":
+			switch err.Kind() {
+			case reflect.Slice, reflect.Map:
+				details[field] = fmt.Sprintf("Must have at most %s entries", err.Param())
+			case reflect.String:
+				details[field] = fmt.Sprintf("Must be at most %s characters long", err.Param())
+			default:
+				details[field] = fmt.Sprintf("Must be at most %s", err.Param())
+			}
+		case "datetime":
+			details[field] = "Must be a date in YYYY-MM-DD format"
+		default:
+			details[field] = "Invalid value"
+		}
+	}
+
+	return model.ErrorResponse{
+		Error:   "validation_failed",
+		Message: "Invalid input data",
+		Details: details,
+	}
+}
@@ -1,19 +1,28 @@
 package handler
 
 import (
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	
+
 	"todo-api-backend/internal/middleware"
 	"todo-api-backend/internal/model"
 )
 
+const (
+	// acceptPatch lists the media types accepted by PatchTodo
+	acceptPatch = model.PatchTypeMerge + ", " + model.PatchTypeJSON
+	// maxPatchSize limits the size of a patch document
+	maxPatchSize = 64 << 10
+)
+
 // CreateTodo handles todo creation
 // @Summary Create a new todo
-// @Description Create a new todo item for the authenticated user
+// @Description Create a new todo item for the authenticated user. With list_id the todo is added to a list, where it starts in state or the first state of the workflow
 // @Tags todos
 // @Accept json
 // @Produce json
@@ -22,11 +31,12 @@ import (
 // @Success 201 {object} model.Todo "Todo successfully created"
 // @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
 // @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 422 {object} model.ErrorResponse "List or state not found, or invalid custom field values"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /api/v1/todos [post]
 func (h *Handler) CreateTodo(c *gin.Context) {
 	var req model.CreateTodoRequest
-	
+
 	// Get user ID from context (set by JWT middleware)
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -36,7 +46,7 @@ func (h *Handler) CreateTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Bind JSON request body
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
@@ -45,7 +55,7 @@ func (h *Handler) CreateTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
 		details := make(map[string]string)
@@ -58,6 +68,8 @@ func (h *Handler) CreateTodo(c *gin.Context) {
 			case "max":
 				if err.Field() == "Title" {
 					details[err.Field()] = "Title must be at most 255 characters long"
+				} else if err.Field() == "State" {
+					details[err.Field()] = "State must be at most 32 characters long"
 				} else {
 					details[err.Field()] = "Description must be at most 1000 characters long"
 				}
@@ -65,7 +77,7 @@ func (h *Handler) CreateTodo(c *gin.Context) {
 				details[err.Field()] = "Invalid value"
 			}
 		}
-		
+
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error:   "validation_failed",
 			Message: "Invalid input data",
@@ -73,27 +85,36 @@ func (h *Handler) CreateTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Call service to create todo
 	todo, err := h.services.Todo.Create(c.Request.Context(), &req, userID)
 	if err != nil {
+		if workflowError(c, err) || customFieldValueError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error:   "creation_failed",
 			Message: "Failed to create todo",
 		})
 		return
 	}
-	
+
 	c.JSON(http.StatusCreated, todo)
 }
 
 // GetTodos handles retrieving all todos for the authenticated user
 // @Summary Get all todos
-// @Description Retrieve all todos belonging to the authenticated user
+// @Description Retrieve all todos belonging to the authenticated user. Archived todos are excluded unless requested.
 // @Tags todos
 // @Produce json
 // @Security BearerAuth
+// @Param archived query string false "Archived filter: false (default), true or all" Enums(false, true, all)
+// @Param cf[key] query string false "Only todos whose custom field key has this value"
+// @Param cf_gte[key] query string false "Only todos whose number or date custom field key is at least this value"
+// @Param cf_lte[key] query string false "Only todos whose number or date custom field key is at most this value"
+// @Param sort query string false "Order by a custom field value instead of newest first: cf.key ascending or -cf.key descending; todos without a value come last"
 // @Success 200 {object} model.TodoListResponse "List of todos retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid filter"
 // @Failure 401 {object} model.ErrorResponse "User not authenticated"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /api/v1/todos [get]
@@ -107,23 +128,43 @@ func (h *Handler) GetTodos(c *gin.Context) {
 		})
 		return
 	}
-	
+
+	// Parse listing filter from query parameters
+	filter := &model.TodoFilter{
+		Archived: c.DefaultQuery("archived", model.ArchivedExclude),
+	}
+	switch filter.Archived {
+	case model.ArchivedExclude, model.ArchivedOnly, model.ArchivedInclude:
+	default:
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_filter",
+			Message: "archived must be one of: false, true, all",
+		})
+		return
+	}
+	if !customFieldFilter(c, filter) {
+		return
+	}
+
 	// Call service to get todos
-	todos, err := h.services.Todo.GetByUserID(c.Request.Context(), userID)
+	todos, err := h.services.Todo.List(c.Request.Context(), userID, filter)
 	if err != nil {
+		if customFieldFilterError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error:   "retrieval_failed",
 			Message: "Failed to retrieve todos",
 		})
 		return
 	}
-	
+
 	// Return todos with count
 	response := model.TodoListResponse{
 		Todos: todos,
 		Count: len(todos),
 	}
-	
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -134,7 +175,10 @@ func (h *Handler) GetTodos(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Todo ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} model.Todo "Todo retrieved successfully"
+// @Header 200 {string} ETag "Version of the todo"
+// @Success 304 "Todo has not changed since the If-None-Match ETag"
 // @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
 // @Failure 401 {object} model.ErrorResponse "User not authenticated"
 // @Failure 404 {object} model.ErrorResponse "Todo not found"
@@ -150,7 +194,7 @@ func (h *Handler) GetTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Parse todo ID from URL parameter
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -161,7 +205,7 @@ func (h *Handler) GetTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Call service to get todo
 	todo, err := h.services.Todo.GetByID(c.Request.Context(), uint(id), userID)
 	if err != nil {
@@ -179,28 +223,41 @@ func (h *Handler) GetTodo(c *gin.Context) {
 		}
 		return
 	}
-	
+
+	// Let clients revalidate a cached copy
+	etag := todoETag(todo)
+	c.Header("ETag", etag)
+	if noneMatch(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, todo)
 }
 
-// UpdateTodo handles updating a specific todo
-// @Summary Update todo
-// @Description Update a specific todo by ID, ensuring user ownership
+// UpdateTodo handles replacing a specific todo
+// @Summary Replace todo
+// @Description Replace the editable fields of a specific todo by ID, ensuring user ownership. Omitted title, description, completed and due_at are reset to their defaults, while an omitted state or custom_fields keeps the current value; use PATCH for partial updates. For a todo in a list, a new state must be allowed by the workflow and decides whether the todo is completed; without a state, changing completed moves the todo to the first allowed state that matches
 // @Tags todos
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Todo ID"
-// @Param request body model.UpdateTodoRequest true "Todo update request"
+// @Param If-Match header string false "ETag the todo must still have"
+// @Param request body model.ReplaceTodoRequest true "Todo replacement request"
 // @Success 200 {object} model.Todo "Todo updated successfully"
+// @Header 200 {string} ETag "Version of the updated todo"
 // @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
 // @Failure 401 {object} model.ErrorResponse "User not authenticated"
 // @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 409 {object} model.ErrorResponse "Todo was modified concurrently or transition not allowed by the workflow"
+// @Failure 412 {object} model.ErrorResponse "If-Match ETag does not match the current todo"
+// @Failure 422 {object} model.ErrorResponse "Unknown state, todo not in a list or invalid custom field values"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /api/v1/todos/{id} [put]
 func (h *Handler) UpdateTodo(c *gin.Context) {
-	var req model.UpdateTodoRequest
-	
+	var req model.ReplaceTodoRequest
+
 	// Get user ID from context (set by JWT middleware)
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -210,7 +267,7 @@ func (h *Handler) UpdateTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Parse todo ID from URL parameter
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -221,7 +278,7 @@ func (h *Handler) UpdateTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Bind JSON request body
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
@@ -230,17 +287,21 @@ func (h *Handler) UpdateTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
 		details := make(map[string]string)
 		for _, err := range err.(validator.ValidationErrors) {
 			switch err.Tag() {
+			case "required":
+				details[err.Field()] = "Title is required"
 			case "min":
 				details[err.Field()] = "Title must be at least 1 character long"
 			case "max":
 				if err.Field() == "Title" {
 					details[err.Field()] = "Title must be at most 255 characters long"
+				} else if err.Field() == "State" {
+					details[err.Field()] = "State must be at most 32 characters long"
 				} else {
 					details[err.Field()] = "Description must be at most 1000 characters long"
 				}
@@ -248,7 +309,7 @@ func (h *Handler) UpdateTodo(c *gin.Context) {
 				details[err.Field()] = "Invalid value"
 			}
 		}
-		
+
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error:   "validation_failed",
 			Message: "Invalid input data",
@@ -256,9 +317,9 @@ func (h *Handler) UpdateTodo(c *gin.Context) {
 		})
 		return
 	}
-	
-	// Call service to update todo
-	todo, err := h.services.Todo.Update(c.Request.Context(), uint(id), &req, userID)
+
+	// Call service to replace todo
+	todo, err := h.services.Todo.Replace(conditionalContext(c), uint(id), &req, userID)
 	if err != nil {
 		switch err.Error() {
 		case "todo not found":
@@ -266,15 +327,144 @@ func (h *Handler) UpdateTodo(c *gin.Context) {
 				Error:   "not_found",
 				Message: "Todo not found",
 			})
+		case "todo version does not match":
+			c.JSON(http.StatusPreconditionFailed, model.ErrorResponse{
+				Error:   "precondition_failed",
+				Message: "Todo has been modified since it was retrieved",
+			})
+		case "todo was modified concurrently":
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Error:   "conflict",
+				Message: "Todo was modified concurrently, please retry",
+			})
 		default:
-			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-				Error:   "update_failed",
-				Message: "Failed to update todo",
+			if !workflowError(c, err) && !customFieldValueError(c, err) {
+				c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+					Error:   "update_failed",
+					Message: "Failed to update todo",
+				})
+			}
+		}
+		return
+	}
+
+	c.Header("ETag", todoETag(todo))
+	c.JSON(http.StatusOK, todo)
+}
+
+// PatchTodo handles partially updating a specific todo
+// @Summary Patch todo
+// @Description Apply a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document to a specific todo, ensuring user ownership. Setting description to null with a merge patch clears it
+// @Tags todos
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag the todo must still have"
+// @Param request body object true "Patch document"
+// @Success 200 {object} model.Todo "Todo updated successfully"
+// @Header 200 {string} ETag "Version of the updated todo"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID or malformed patch document"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 409 {object} model.ErrorResponse "Patch cannot be applied to the current todo or transition not allowed by the workflow"
+// @Failure 412 {object} model.ErrorResponse "If-Match ETag does not match the current todo"
+// @Failure 415 {object} model.ErrorResponse "Unsupported patch media type"
+// @Failure 422 {object} model.ErrorResponse "Patched todo is invalid, has an unknown state or invalid custom field values"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id} [patch]
+func (h *Handler) PatchTodo(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Parse todo ID from URL parameter
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid todo ID format",
+		})
+		return
+	}
+
+	// Only the two patch media types are accepted
+	patchType := c.ContentType()
+	if patchType != model.PatchTypeMerge && patchType != model.PatchTypeJSON {
+		c.Header("Accept-Patch", acceptPatch)
+		c.JSON(http.StatusUnsupportedMediaType, model.ErrorResponse{
+			Error:   "unsupported_media_type",
+			Message: "Content-Type must be " + model.PatchTypeMerge + " or " + model.PatchTypeJSON,
+		})
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to read patch document",
+		})
+		return
+	}
+
+	// Call service to patch todo
+	todo, err := h.services.Todo.Patch(conditionalContext(c), uint(id), patchType, patch, userID)
+	if err != nil {
+		switch {
+		case err.Error() == "todo not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Todo not found",
+			})
+		case err.Error() == "todo version does not match":
+			c.JSON(http.StatusPreconditionFailed, model.ErrorResponse{
+				Error:   "precondition_failed",
+				Message: "Todo has been modified since it was retrieved",
+			})
+		case err.Error() == "todo was modified concurrently":
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Error:   "conflict",
+				Message: "Todo was modified concurrently, please retry",
+			})
+		case strings.HasPrefix(err.Error(), "invalid patch document"):
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_patch",
+				Message: "Invalid patch document",
+				Details: map[string]string{"patch": patchErrorDetail(err)},
 			})
+		case strings.HasPrefix(err.Error(), "patch cannot be applied"):
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Error:   "patch_conflict",
+				Message: "Patch cannot be applied to the current todo",
+				Details: map[string]string{"patch": patchErrorDetail(err)},
+			})
+		case strings.HasPrefix(err.Error(), "patched todo is invalid"):
+			c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+				Error:   "validation_failed",
+				Message: "Patched todo is invalid",
+				Details: map[string]string{"patch": patchErrorDetail(err)},
+			})
+		default:
+			if !workflowError(c, err) && !customFieldValueError(c, err) {
+				c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+					Error:   "update_failed",
+					Message: "Failed to update todo",
+				})
+			}
 		}
 		return
 	}
-	
+
+	c.Header("ETag", todoETag(todo))
 	c.JSON(http.StatusOK, todo)
 }
 
@@ -284,10 +474,12 @@ func (h *Handler) UpdateTodo(c *gin.Context) {
 // @Tags todos
 // @Security BearerAuth
 // @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag the todo must still have"
 // @Success 204 "Todo deleted successfully"
 // @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
 // @Failure 401 {object} model.ErrorResponse "User not authenticated"
 // @Failure 404 {object} model.ErrorResponse "Todo not found"
+// @Failure 412 {object} model.ErrorResponse "If-Match ETag does not match the current todo"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /api/v1/todos/{id} [delete]
 func (h *Handler) DeleteTodo(c *gin.Context) {
@@ -300,7 +492,7 @@ func (h *Handler) DeleteTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Parse todo ID from URL parameter
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -311,9 +503,9 @@ func (h *Handler) DeleteTodo(c *gin.Context) {
 		})
 		return
 	}
-	
+
 	// Call service to delete todo
-	err = h.services.Todo.Delete(c.Request.Context(), uint(id), userID)
+	err = h.services.Todo.Delete(conditionalContext(c), uint(id), userID)
 	if err != nil {
 		switch err.Error() {
 		case "todo not found":
@@ -321,6 +513,11 @@ func (h *Handler) DeleteTodo(c *gin.Context) {
 				Error:   "not_found",
 				Message: "Todo not found",
 			})
+		case "todo version does not match":
+			c.JSON(http.StatusPreconditionFailed, model.ErrorResponse{
+				Error:   "precondition_failed",
+				Message: "Todo has been modified since it was retrieved",
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 				Error:   "deletion_failed",
@@ -329,6 +526,14 @@ func (h *Handler) DeleteTodo(c *gin.Context) {
 		}
 		return
 	}
-	
+
 	c.Status(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// patchErrorDetail returns the cause of a patch error without its sentinel prefix
+func patchErrorDetail(err error) string {
+	if _, detail, ok := strings.Cut(err.Error(), ": "); ok {
+		return detail
+	}
+	return err.Error()
+}
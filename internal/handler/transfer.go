@@ -0,0 +1,660 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/ical"
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/plaintext"
+)
+
+const (
+	// maxImportSize limits the size of an import upload
+	maxImportSize = 10 << 20
+
+	// maxImportLineSize limits the length of a single NDJSON line
+	maxImportLineSize = 1 << 20
+
+	// formulaPrefixes are the leading characters that make spreadsheet applications
+	// evaluate a CSV cell as a formula
+	formulaPrefixes = "=+-@\t\r"
+)
+
+// errInvalidImportFile wraps problems that make a whole import file unreadable
+var errInvalidImportFile = errors.New("invalid import file")
+
+// ExportTodos handles streaming all todos of the authenticated user as a file
+// @Summary Export todos
+// @Description Download the todos of the authenticated user as CSV, a JSON array, newline-delimited JSON, an iCalendar file of VTODO components, a todo.txt file or a GitHub-flavored Markdown task list. In todo.txt and Markdown the priority and description of a todo are kept in its pri: and note: tags, or below a Markdown item as indented lines, so they can be imported again without loss. Todos are streamed oldest first, so exports of any size use constant memory. Archived todos are included unless filtered out. CSV cells starting with =, +, -, @, tab or carriage return are prefixed with a single quote so spreadsheet applications do not run them as formulas; imports remove the quote again.
+// @Tags todos
+// @Produce text/csv
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce text/calendar
+// @Produce text/plain
+// @Produce text/markdown
+// @Security BearerAuth
+// @Param format query string false "File format" Enums(csv, json, ndjson, ics, txt, md) default(csv)
+// @Param archived query string false "Archived filter: false, true or all (default)" Enums(false, true, all)
+// @Success 200 {array} model.TodoExport "Exported todos"
+// @Failure 400 {object} model.ErrorResponse "Invalid format or filter"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/export [get]
+func (h *Handler) ExportTodos(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", model.TransferFormatCSV)
+	encoder := newTodoEncoder(format, c.Writer)
+	if encoder == nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "unsupported_format",
+			Message: "format must be one of: csv, json, ndjson, ics, txt, md",
+		})
+		return
+	}
+
+	filter := &model.TodoFilter{
+		Archived: c.DefaultQuery("archived", model.ArchivedInclude),
+	}
+	switch filter.Archived {
+	case model.ArchivedExclude, model.ArchivedOnly, model.ArchivedInclude:
+	default:
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_filter",
+			Message: "archived must be one of: false, true, all",
+		})
+		return
+	}
+
+	// Large exports outlive the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Failed to clear write deadline for export: %v", err)
+	}
+
+	// The response is committed with the first todo, so errors before it can
+	// still be reported while later ones can only cut the download short
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", encoder.contentType())
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"todos-%s.%s\"", time.Now().UTC().Format("20060102"), format))
+		c.Status(http.StatusOK)
+		return encoder.begin()
+	}
+
+	err := h.services.Todo.Export(c.Request.Context(), userID, filter, func(todo *model.Todo) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return encoder.encode(todo)
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = encoder.end()
+	}
+	if err != nil {
+		if !started {
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "export_failed",
+				Message: "Failed to export todos",
+			})
+			return
+		}
+		log.Printf("Failed to export todos of user %d: %v", userID, err)
+	}
+}
+
+// ImportTodos handles creating todos from an uploaded file
+// @Summary Import todos
+// @Description Create todos from a CSV, JSON array, newline-delimited JSON, todo.txt or GitHub-flavored Markdown file uploaded as the multipart field "file". The format is taken from the format parameter, the file extension or the part's content type. CSV files need a header row with a title column and may have description, completed, due_at, completed_at and created_at columns; other columns, such as those of an export, are ignored. Every todo.txt line and Markdown task list item is a todo; other Markdown content is ignored. Creation and completion dates of imported todos are kept. Each row is validated like a created todo. Invalid rows are reported and skipped unless atomic is set, in which case nothing is imported. With dry_run set, the file is validated and the report returned without creating any todos. Returns 200 when every row was valid, 207 when some rows were skipped and 422 when nothing was imported because of invalid rows.
+// @Tags todos
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "File to import"
+// @Param format query string false "File format; detected from the file when omitted" Enums(csv, json, ndjson, txt, md)
+// @Param dry_run query bool false "Validate without importing"
+// @Param atomic query bool false "Import nothing if any row is invalid"
+// @Success 200 {object} model.ImportResponse "Every row was valid"
+// @Success 207 {object} model.ImportResponse "Some rows were skipped"
+// @Failure 400 {object} model.ErrorResponse "Missing, unreadable or unsupported file"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 413 {object} model.ErrorResponse "File too large"
+// @Failure 422 {object} model.ImportResponse "Nothing imported because of invalid rows"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/import [post]
+func (h *Handler) ImportTodos(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	req := &model.ImportRequest{}
+	for name, value := range map[string]*bool{"dry_run": &req.DryRun, "atomic": &req.Atomic} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: name + " must be true or false",
+			})
+			return
+		}
+		*value = parsed
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, model.ErrorResponse{
+				Error:   "file_too_large",
+				Message: fmt.Sprintf("Import files may be at most %d MB", maxImportSize>>20),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "A multipart file field named file is required",
+		})
+		return
+	}
+	defer file.Close()
+
+	format := c.Query("format")
+	if format == "" {
+		format = detectImportFormat(header.Filename, header.Header.Get("Content-Type"))
+	}
+
+	rows, err := decodeImportRows(format, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidImportFile):
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_file",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "unsupported_format",
+				Message: "format must be one of: csv, json, ndjson, txt, md",
+			})
+		}
+		return
+	}
+	req.Rows = rows
+
+	// Call service to import the rows
+	response, err := h.services.Todo.Import(c.Request.Context(), userID, req)
+	if err != nil {
+		switch err.Error() {
+		case "too many import rows":
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "too_many_rows",
+				Message: "An import may contain at most 10000 todos",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "import_failed",
+				Message: "Failed to import todos",
+			})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case response.Failed == 0:
+	case !response.DryRun && !response.Committed:
+		status = http.StatusUnprocessableEntity
+	default:
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, response)
+}
+
+// todoEncoder writes todos to an export file one at a time
+type todoEncoder interface {
+	contentType() string
+	begin() error
+	encode(todo *model.Todo) error
+	end() error
+}
+
+// newTodoEncoder returns the encoder for an export format, or nil if the format is unknown
+func newTodoEncoder(format string, w io.Writer) todoEncoder {
+	switch format {
+	case model.TransferFormatCSV:
+		return &csvTodoEncoder{w: csv.NewWriter(w)}
+	case model.TransferFormatJSON:
+		return &jsonTodoEncoder{w: w}
+	case model.TransferFormatNDJSON:
+		return &ndjsonTodoEncoder{enc: json.NewEncoder(w)}
+	case model.TransferFormatICS:
+		return &icsTodoEncoder{enc: ical.NewEncoder(w, ical.Options{Name: calendarFeedName})}
+	case model.TransferFormatTodoTxt:
+		return &plaintextTodoEncoder{w: w, mediaType: "text/plain; charset=utf-8", write: plaintext.WriteTodoTxt}
+	case model.TransferFormatMarkdown:
+		return &plaintextTodoEncoder{w: w, mediaType: "text/markdown; charset=utf-8", write: plaintext.WriteMarkdown}
+	default:
+		return nil
+	}
+}
+
+// csvTodoEncoder writes todos as CSV rows below a header row
+type csvTodoEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvTodoEncoder) contentType() string { return "text/csv; charset=utf-8" }
+
+func (e *csvTodoEncoder) begin() error {
+	return e.w.Write(model.TransferCSVHeader)
+}
+
+func (e *csvTodoEncoder) encode(todo *model.Todo) error {
+	return e.w.Write([]string{
+		strconv.FormatUint(uint64(todo.ID), 10),
+		escapeFormula(todo.Title),
+		escapeFormula(todo.Description),
+		strconv.FormatBool(todo.Completed),
+		formatExportTime(todo.DueAt),
+		formatExportTime(todo.CompletedAt),
+		formatExportTime(todo.ArchivedAt),
+		formatExportTime(&todo.CreatedAt),
+		formatExportTime(&todo.UpdatedAt),
+	})
+}
+
+func (e *csvTodoEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonTodoEncoder writes todos as the elements of a JSON array
+type jsonTodoEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonTodoEncoder) contentType() string { return "application/json; charset=utf-8" }
+
+func (e *jsonTodoEncoder) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonTodoEncoder) encode(todo *model.Todo) error {
+	data, err := json.Marshal(model.NewTodoExport(todo))
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonTodoEncoder) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// ndjsonTodoEncoder writes one JSON object per line
+type ndjsonTodoEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonTodoEncoder) contentType() string { return "application/x-ndjson" }
+
+func (e *ndjsonTodoEncoder) begin() error { return nil }
+
+func (e *ndjsonTodoEncoder) encode(todo *model.Todo) error {
+	return e.enc.Encode(model.NewTodoExport(todo))
+}
+
+func (e *ndjsonTodoEncoder) end() error { return nil }
+
+// icsTodoEncoder writes todos as the VTODO components of a calendar
+type icsTodoEncoder struct {
+	enc *ical.Encoder
+}
+
+func (e *icsTodoEncoder) contentType() string { return ical.ContentType }
+
+func (e *icsTodoEncoder) begin() error { return e.enc.Begin() }
+
+func (e *icsTodoEncoder) encode(todo *model.Todo) error { return e.enc.EncodeTodo(todo) }
+
+func (e *icsTodoEncoder) end() error { return e.enc.End() }
+
+// plaintextTodoEncoder writes todos as the lines of a plain text task list
+type plaintextTodoEncoder struct {
+	w         io.Writer
+	mediaType string
+	write     func(w io.Writer, task *plaintext.Task) error
+}
+
+func (e *plaintextTodoEncoder) contentType() string { return e.mediaType }
+
+func (e *plaintextTodoEncoder) begin() error { return nil }
+
+func (e *plaintextTodoEncoder) encode(todo *model.Todo) error {
+	return e.write(e.w, newPlaintextTask(todo))
+}
+
+func (e *plaintextTodoEncoder) end() error { return nil }
+
+// newPlaintextTask converts a todo to a task list entry. A pri: tag in the title
+// becomes the priority of the task, and the description its note.
+func newPlaintextTask(todo *model.Todo) *plaintext.Task {
+	text, priority := plaintext.SplitPriority(todo.Title)
+	task := &plaintext.Task{
+		Text:        text,
+		Priority:    priority,
+		Completed:   todo.Completed,
+		CompletedAt: todo.CompletedAt,
+		DueAt:       todo.DueAt,
+		Note:        todo.Description,
+	}
+	if !todo.CreatedAt.IsZero() {
+		createdAt := todo.CreatedAt
+		task.CreatedAt = &createdAt
+	}
+	return task
+}
+
+// formatExportTime formats an optional timestamp for a CSV cell
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// escapeFormula prefixes a CSV cell that a spreadsheet would evaluate as a formula with a single quote
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeFormula removes the quote escapeFormula adds
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// detectImportFormat guesses the format of an uploaded file from its name and content type
+func detectImportFormat(filename, contentType string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return model.TransferFormatCSV
+	case ".json":
+		return model.TransferFormatJSON
+	case ".ndjson", ".jsonl":
+		return model.TransferFormatNDJSON
+	case ".txt":
+		return model.TransferFormatTodoTxt
+	case ".md", ".markdown":
+		return model.TransferFormatMarkdown
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return model.TransferFormatCSV
+	case "application/json":
+		return model.TransferFormatJSON
+	case "application/x-ndjson", "application/jsonl":
+		return model.TransferFormatNDJSON
+	case "text/plain":
+		return model.TransferFormatTodoTxt
+	case "text/markdown":
+		return model.TransferFormatMarkdown
+	}
+	return ""
+}
+
+// decodeImportRows reads every row of an import file. Rows that cannot be read are
+// returned with a parse error; errors wrapping errInvalidImportFile mean the file
+// as a whole is unreadable.
+func decodeImportRows(format string, r io.Reader) ([]model.ImportRow, error) {
+	switch format {
+	case model.TransferFormatCSV:
+		return decodeCSVRows(r)
+	case model.TransferFormatJSON:
+		return decodeJSONRows(r)
+	case model.TransferFormatNDJSON:
+		return decodeNDJSONRows(r)
+	case model.TransferFormatTodoTxt:
+		return decodePlaintextRows(r, plaintext.ReadTodoTxt)
+	case model.TransferFormatMarkdown:
+		return decodePlaintextRows(r, plaintext.ReadMarkdown)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// decodeCSVRows reads a CSV file with a header row. Rows are numbered like spreadsheet
+// rows, so the first todo is row 2.
+func decodeCSVRows(r io.Reader) ([]model.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: CSV file is empty", errInvalidImportFile)
+		}
+		return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet applications often start UTF-8 files with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("%w: CSV header must include a title column", errInvalidImportFile)
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var rows []model.ImportRow
+	for number := 2; ; number++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+		}
+
+		row := model.ImportRow{
+			Row:         number,
+			Title:       unescapeFormula(cell(record, "title")),
+			Description: unescapeFormula(cell(record, "description")),
+		}
+		completed, err := parseImportBool(cell(record, "completed"))
+		if err != nil {
+			row.ParseError = "completed must be true or false"
+		}
+		row.Completed = completed
+		dueAt, err := parseImportTime(cell(record, "due_at"))
+		if err != nil {
+			row.ParseError = "due_at must be an RFC 3339 timestamp or a YYYY-MM-DD date"
+		}
+		row.DueAt = dueAt
+		completedAt, err := parseImportTime(cell(record, "completed_at"))
+		if err != nil {
+			row.ParseError = "completed_at must be an RFC 3339 timestamp or a YYYY-MM-DD date"
+		}
+		row.CompletedAt = completedAt
+		createdAt, err := parseImportTime(cell(record, "created_at"))
+		if err != nil {
+			row.ParseError = "created_at must be an RFC 3339 timestamp or a YYYY-MM-DD date"
+		}
+		row.CreatedAt = createdAt
+		rows = append(rows, row)
+	}
+}
+
+// decodeJSONRows reads a JSON array of todo objects
+func decodeJSONRows(r io.Reader) ([]model.ImportRow, error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("%w: JSON file must contain an array of todos", errInvalidImportFile)
+	}
+
+	var rows []model.ImportRow
+	for number := 1; decoder.More(); number++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+		}
+		rows = append(rows, parseJSONRow(number, raw))
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+	}
+	return rows, nil
+}
+
+// decodeNDJSONRows reads one todo object per line, numbering rows by line and skipping blank lines
+func decodeNDJSONRows(r io.Reader) ([]model.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineSize)
+
+	var rows []model.ImportRow
+	for number := 1; scanner.Scan(); number++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		rows = append(rows, parseJSONRow(number, line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+	}
+	return rows, nil
+}
+
+// decodePlaintextRows reads the tasks of a plain text task list with read, numbering rows by line
+func decodePlaintextRows(r io.Reader, read func(io.Reader, func(int, *plaintext.Task, error) error) error) ([]model.ImportRow, error) {
+	var rows []model.ImportRow
+	err := read(r, func(line int, task *plaintext.Task, err error) error {
+		if err != nil {
+			rows = append(rows, model.ImportRow{Row: line, ParseError: err.Error()})
+			return nil
+		}
+		rows = append(rows, model.ImportRow{
+			Row:         line,
+			Title:       plaintext.JoinPriority(task.Text, task.Priority),
+			Description: task.Note,
+			Completed:   task.Completed,
+			DueAt:       task.DueAt,
+			CompletedAt: task.CompletedAt,
+			CreatedAt:   task.CreatedAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+	}
+	return rows, nil
+}
+
+// parseJSONRow reads a single todo object, recording why it could not be read
+func parseJSONRow(number int, data []byte) model.ImportRow {
+	var row model.ImportRow
+	if err := json.Unmarshal(data, &row); err != nil {
+		row = model.ImportRow{ParseError: "row must be a todo object with a string title and description, a boolean completed and an RFC 3339 due_at"}
+	}
+	row.Row = number
+	return row
+}
+
+// parseImportTime reads a time column of a CSV row, where an empty cell means no time
+func parseImportTime(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time %q", value)
+}
+
+// parseImportBool reads the completed column of a CSV row, where an empty cell means false
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "no", "n":
+		return false, nil
+	case "yes", "y", "x":
+		return true, nil
+	default:
+		return strconv.ParseBool(strings.TrimSpace(value))
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+// GetTrash handles retrieving all trashed todos for the authenticated user
+// @Summary Get trashed todos
+// @Description Retrieve all soft-deleted todos belonging to the authenticated user
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.TodoListResponse "List of trashed todos retrieved successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/trash [get]
+func (h *Handler) GetTrash(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to get trashed todos
+	todos, err := h.services.Todo.GetTrash(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve trash",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TodoListResponse{
+		Todos: todos,
+		Count: len(todos),
+	})
+}
+
+// RestoreTodo handles restoring a trashed todo
+// @Summary Restore todo
+// @Description Move a soft-deleted todo out of the trash, ensuring user ownership
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {object} model.Todo "Todo restored successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found in trash"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/{id}/restore [post]
+func (h *Handler) RestoreTodo(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Parse todo ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid todo ID format",
+		})
+		return
+	}
+
+	// Call service to restore todo
+	todo, err := h.services.Todo.Restore(c.Request.Context(), uint(id), userID)
+	if err != nil {
+		switch err.Error() {
+		case "todo not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Todo not found in trash",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "restore_failed",
+				Message: "Failed to restore todo",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// PurgeTodo handles permanently deleting a trashed todo
+// @Summary Purge todo
+// @Description Permanently delete a soft-deleted todo, ensuring user ownership
+// @Tags todos
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 204 "Todo purged successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid todo ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Todo not found in trash"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/trash/{id} [delete]
+func (h *Handler) PurgeTodo(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Parse todo ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid todo ID format",
+		})
+		return
+	}
+
+	// Call service to purge todo
+	if err := h.services.Todo.Purge(c.Request.Context(), uint(id), userID); err != nil {
+		switch err.Error() {
+		case "todo not found":
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Todo not found in trash",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "purge_failed",
+				Message: "Failed to purge todo",
+			})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EmptyTrash handles permanently deleting all trashed todos
+// @Summary Empty trash
+// @Description Permanently delete all soft-deleted todos belonging to the authenticated user
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse "Trash emptied successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/todos/trash [delete]
+func (h *Handler) EmptyTrash(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to empty trash
+	purged, err := h.services.Todo.EmptyTrash(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "purge_failed",
+			Message: "Failed to empty trash",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Message: "Trash emptied successfully",
+		Data:    gin.H{"purged": purged},
+	})
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+// CreateWebhook handles registering a webhook
+// @Summary Register a webhook
+// @Description Subscribe a URL to todo and comment mention events of the authenticated user. Every matching event is POSTed as JSON, signed with HMAC-SHA256 over "<timestamp>.<body>" using the secret returned here, which is not shown again. The signature is sent as "sha256=<hex>" in the X-Webhook-Signature header and the Unix timestamp in X-Webhook-Timestamp. Failed deliveries are retried with exponential backoff, and a webhook that keeps failing is disabled.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateWebhookRequest true "Webhook registration request"
+// @Success 201 {object} model.CreateWebhookResponse "Webhook successfully registered"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 409 {object} model.ErrorResponse "Webhook limit reached"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req model.CreateWebhookRequest
+
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, webhookValidationError(err))
+		return
+	}
+
+	// Call service to register the webhook
+	webhook, err := h.services.Webhook.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		webhookError(c, err, "creation_failed", "Failed to register webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.CreateWebhookResponse{
+		Webhook: webhook,
+		Secret:  webhook.Secret,
+	})
+}
+
+// GetWebhooks handles listing the webhooks of the authenticated user
+// @Summary Get all webhooks
+// @Description Retrieve the webhooks of the authenticated user, including disabled ones
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.WebhookListResponse "List of webhooks retrieved successfully"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks [get]
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// Call service to list webhooks
+	webhooks, err := h.services.Webhook.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "retrieval_failed",
+			Message: "Failed to retrieve webhooks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.WebhookListResponse{
+		Webhooks: webhooks,
+		Count:    len(webhooks),
+	})
+}
+
+// GetWebhook handles retrieving a single webhook
+// @Summary Get a webhook
+// @Description Retrieve a webhook, including whether it was disabled after repeated failures, ensuring user ownership
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} model.Webhook "Webhook retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid webhook ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Webhook not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{id} [get]
+func (h *Handler) GetWebhook(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to get the webhook
+	webhook, err := h.services.Webhook.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		webhookError(c, err, "retrieval_failed", "Failed to retrieve webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook handles replacing a webhook
+// @Summary Update a webhook
+// @Description Replace the URL, events and state of a webhook, ensuring user ownership. Setting active to true re-enables a disabled webhook and resets its failure count; the signing secret is kept.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param request body model.UpdateWebhookRequest true "Webhook update request"
+// @Success 200 {object} model.Webhook "Webhook successfully updated"
+// @Failure 400 {object} model.ErrorResponse "Invalid request data or validation failed"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Webhook not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{id} [put]
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	// Bind JSON request body
+	var req model.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, webhookValidationError(err))
+		return
+	}
+
+	// Call service to update the webhook
+	webhook, err := h.services.Webhook.Update(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		webhookError(c, err, "update_failed", "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles removing a webhook
+// @Summary Delete a webhook
+// @Description Remove a webhook together with its queued deliveries and delivery log, ensuring user ownership
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 204 "Webhook successfully deleted"
+// @Failure 400 {object} model.ErrorResponse "Invalid webhook ID format"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Webhook not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	// Call service to delete the webhook
+	if err := h.services.Webhook.Delete(c.Request.Context(), id, userID); err != nil {
+		webhookError(c, err, "deletion_failed", "Failed to delete webhook")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries handles retrieving the delivery log of a webhook
+// @Summary Get webhook deliveries
+// @Description Retrieve the most recent deliveries of a webhook, newest first, with their status, number of attempts, last response code and error, ensuring user ownership. Finished deliveries are kept for 30 days.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, max 200)"
+// @Success 200 {object} model.WebhookDeliveriesResponse "Delivery log retrieved successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid webhook ID format or limit"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Failure 404 {object} model.ErrorResponse "Webhook not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_filter",
+				Message: "limit must be a positive integer",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	// Call service to get the delivery log
+	deliveries, err := h.services.Webhook.ListDeliveries(c.Request.Context(), id, userID, limit)
+	if err != nil {
+		webhookError(c, err, "retrieval_failed", "Failed to retrieve webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.WebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Count:      len(deliveries),
+	})
+}
+
+// webhookParams extracts the authenticated user and the webhook ID, writing
+// the error response and reporting false if either is missing or invalid
+func webhookParams(c *gin.Context) (uint, uint, bool) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, 0, false
+	}
+
+	// Parse webhook ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid webhook ID format",
+		})
+		return 0, 0, false
+	}
+
+	return userID, uint(id), true
+}
+
+// webhookError writes the response for a webhook service error
+func webhookError(c *gin.Context, err error, code, message string) {
+	switch err.Error() {
+	case "webhook not found":
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Webhook not found",
+		})
+	case "invalid webhook url":
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Invalid input data",
+			Details: map[string]string{"URL": "URL must be an absolute http or https URL of a public host"},
+		})
+	case "webhook limit reached":
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "webhook_limit_reached",
+			Message: "A user can register at most 10 webhooks",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+	}
+}
+
+// webhookValidationError describes the validation errors of a webhook request
+func webhookValidationError(err error) model.ErrorResponse {
+	details := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Tag() {
+		case "required":
+			details[err.Field()] = "This field is required"
+		case "url":
+			details[err.Field()] = "URL must be an absolute http or https URL"
+		case "max":
+			if err.Field() == "URL" {
+				details[err.Field()] = "URL must be at most 2048 characters long"
+			} else {
+				details[err.Field()] = "At most 5 event types can be subscribed"
+			}
+		case "min":
+			details[err.Field()] = "At least one event type is required"
+		case "oneof":
+			details["Events"] = "Events must be one of: todo.created, todo.updated, todo.completed, todo.deleted, comment.mentioned"
+		default:
+			details[err.Field()] = "Invalid value"
+		}
+	}
+
+	return model.ErrorResponse{
+		Error:   "validation_failed",
+		Message: "Invalid input data",
+		Details: details,
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/realtime"
+)
+
+// Live handles WebSocket connections for live collaboration
+// @Summary Open a live collaboration WebSocket
+// @Description Upgrade to a WebSocket carrying JSON messages. Browsers that cannot set the Authorization header may pass the access token in the access_token query parameter. Send {"type":"subscribe","list":"user:<id>"} to join a list and receive its todo change events as {"type":"event"} messages, {"type":"presence","list":...,"status":"online|away"} and {"type":"typing","list":...,"todo_id":1,"typing":true} to share indicators with the other members of a list, and {"type":"ping"} to receive a pong. Members joining or leaving a list are announced as presence messages; idle connections receive heartbeat messages.
+// @Tags realtime
+// @Security BearerAuth
+// @Param access_token query string false "Access token, when the Authorization header cannot be set"
+// @Success 101 {object} realtime.ServerMessage "Switching protocols"
+// @Failure 400 {object} model.ErrorResponse "Not a WebSocket handshake"
+// @Failure 401 {object} model.ErrorResponse "User not authenticated"
+// @Router /api/v1/ws [get]
+func (h *Handler) Live(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	// The origin is not checked: the connection is authorized by a bearer
+	// token rather than cookies, so other sites cannot open it on a user's behalf
+	server := websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			// The connection outlives the server's read and write timeouts
+			ws.SetDeadline(time.Time{})
+
+			session := realtime.NewSession(userID, h.services.Broker, h.services.Events, h.heartbeat)
+			session.Run(c.Request.Context(), wsConn{ws})
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// wsConn adapts a WebSocket to realtime.Conn, sending messages as text frames
+type wsConn struct {
+	ws *websocket.Conn
+}
+
+func (c wsConn) Receive() ([]byte, error) {
+	var data []byte
+	err := websocket.Message.Receive(c.ws, &data)
+	return data, err
+}
+
+func (c wsConn) Send(data []byte) error {
+	return websocket.Message.Send(c.ws, string(data))
+}
+
+func (c wsConn) Close() error {
+	return c.ws.Close()
+}
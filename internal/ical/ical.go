@@ -0,0 +1,254 @@
+// Package ical serializes todos as RFC 5545 iCalendar data.
+//
+// A todo is written either as a VTODO, which task apps show in their to-do
+// lists, or as a VEVENT at its due date, which is what most calendar apps
+// display. Output is deterministic for a given set of todos, so identical
+// feeds render byte for byte identically.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"todo-api-backend/internal/model"
+)
+
+const (
+	// DefaultProdID identifies this application as the producer of the calendar
+	DefaultProdID = "-//todo-api-backend//Todo API//EN"
+
+	// DefaultDomain is the domain part of component UIDs
+	DefaultDomain = "todo-api-backend"
+
+	// ContentType is the media type of iCalendar data
+	ContentType = "text/calendar; charset=utf-8"
+
+	// maxLineOctets is the longest content line RFC 5545 allows before folding
+	maxLineOctets = 75
+
+	// dateTimeLayout formats UTC date-time values
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Options configures the calendar written by an Encoder
+type Options struct {
+	// ProdID identifies the producer; DefaultProdID when empty
+	ProdID string
+	// Domain makes component UIDs globally unique; DefaultDomain when empty
+	Domain string
+	// Name is shown by calendar apps as the name of a subscribed calendar
+	Name string
+	// RefreshInterval suggests how often subscribers poll the calendar; 0 omits the hint
+	RefreshInterval time.Duration
+}
+
+// Encoder writes todos as the components of a single VCALENDAR object
+type Encoder struct {
+	w    io.Writer
+	opts Options
+	buf  bytes.Buffer
+}
+
+// NewEncoder returns an encoder writing to w
+func NewEncoder(w io.Writer, opts Options) *Encoder {
+	if opts.ProdID == "" {
+		opts.ProdID = DefaultProdID
+	}
+	if opts.Domain == "" {
+		opts.Domain = DefaultDomain
+	}
+	return &Encoder{w: w, opts: opts}
+}
+
+// Begin writes the start of the calendar
+func (e *Encoder) Begin() error {
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", e.opts.ProdID)
+	e.line("CALSCALE", "GREGORIAN")
+	if e.opts.Name != "" {
+		e.line("X-WR-CALNAME", escapeText(e.opts.Name))
+	}
+	if e.opts.RefreshInterval > 0 {
+		interval := formatDuration(e.opts.RefreshInterval)
+		e.line("REFRESH-INTERVAL;VALUE=DURATION", interval)
+		e.line("X-PUBLISHED-TTL", interval)
+	}
+	return e.flush()
+}
+
+// EncodeTodo writes a todo as a VTODO component
+func (e *Encoder) EncodeTodo(todo *model.Todo) error {
+	return e.EncodeTodoUID(todo, TodoUID(todo.ID, e.opts.Domain))
+}
+
+// EncodeTodoUID writes a todo as a VTODO component with the given UID, for
+// todos whose UID was chosen by the client that created them
+func (e *Encoder) EncodeTodoUID(todo *model.Todo, uid string) error {
+	e.line("BEGIN", "VTODO")
+	e.line("UID", escapeText(uid))
+	e.common(todo)
+	if todo.DueAt != nil {
+		e.line("DUE", formatDateTime(*todo.DueAt))
+	}
+	if todo.Completed {
+		e.line("STATUS", "COMPLETED")
+		e.line("PERCENT-COMPLETE", "100")
+		if todo.CompletedAt != nil {
+			e.line("COMPLETED", formatDateTime(*todo.CompletedAt))
+		}
+	} else {
+		e.line("STATUS", "NEEDS-ACTION")
+	}
+	e.line("END", "VTODO")
+	return e.flush()
+}
+
+// EncodeEvent writes a todo as a VEVENT at its due date. Todos without a due
+// date have no place in a calendar and are skipped.
+func (e *Encoder) EncodeEvent(todo *model.Todo) error {
+	if todo.DueAt == nil {
+		return nil
+	}
+
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", EventUID(todo.ID, e.opts.Domain))
+	e.common(todo)
+	// Without DTEND the event takes no time, as befits a deadline
+	e.line("DTSTART", formatDateTime(*todo.DueAt))
+	// Deadlines should not block the owner's free/busy time
+	e.line("TRANSP", "TRANSPARENT")
+	e.line("END", "VEVENT")
+	return e.flush()
+}
+
+// End writes the end of the calendar
+func (e *Encoder) End() error {
+	e.line("END", "VCALENDAR")
+	return e.flush()
+}
+
+// Marshal returns a calendar holding a single todo as a VTODO with the given UID
+func Marshal(todo *model.Todo, uid string, opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, opts)
+	if err := e.Begin(); err != nil {
+		return nil, err
+	}
+	if err := e.EncodeTodoUID(todo, uid); err != nil {
+		return nil, err
+	}
+	if err := e.End(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// common writes the properties VTODO and VEVENT components share
+func (e *Encoder) common(todo *model.Todo) {
+	// Without a METHOD, DTSTAMP is the time the component was last revised
+	e.line("DTSTAMP", formatDateTime(todo.UpdatedAt))
+	e.line("CREATED", formatDateTime(todo.CreatedAt))
+	e.line("LAST-MODIFIED", formatDateTime(todo.UpdatedAt))
+	e.line("SEQUENCE", strconv.FormatUint(uint64(sequence(todo.Version)), 10))
+	e.line("SUMMARY", escapeText(todo.Title))
+	if todo.Description != "" {
+		e.line("DESCRIPTION", escapeText(todo.Description))
+	}
+}
+
+// line buffers a content line, folded to lines of at most 75 octets
+func (e *Encoder) line(name, value string) {
+	writeFolded(&e.buf, name+":"+value)
+}
+
+// flush writes the buffered content lines
+func (e *Encoder) flush() error {
+	_, err := e.w.Write(e.buf.Bytes())
+	e.buf.Reset()
+	return err
+}
+
+// TodoUID returns the UID of the VTODO of a todo
+func TodoUID(id uint, domain string) string {
+	return fmt.Sprintf("todo-%d@%s", id, domain)
+}
+
+// EventUID returns the UID of the VEVENT of a todo. It differs from the VTODO
+// UID so that clients merging both views do not treat them as one component.
+func EventUID(id uint, domain string) string {
+	return fmt.Sprintf("todo-%d-due@%s", id, domain)
+}
+
+// sequence returns the revision number of a todo, which starts at 0 in iCalendar
+func sequence(version uint) uint {
+	if version == 0 {
+		return 0
+	}
+	return version - 1
+}
+
+// formatDateTime formats a time as a UTC DATE-TIME value
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
+
+// formatDuration formats a positive duration as a DURATION value, rounded down to the second
+func formatDuration(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	if seconds <= 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := seconds / 3600; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+	}
+	if m := seconds % 3600 / 60; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+	}
+	if s := seconds % 60; s > 0 {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
+
+// textEscaper escapes the characters RFC 5545 reserves in TEXT values. Carriage
+// returns are dropped so that CRLF and LF line breaks both become \n.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", "",
+)
+
+// escapeText escapes a TEXT value
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeFolded writes a content line terminated by CRLF, folding it after at most
+// 75 octets without splitting multi-byte characters
+func writeFolded(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards their length
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
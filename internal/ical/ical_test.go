@@ -0,0 +1,157 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/model"
+)
+
+func testTodo() *model.Todo {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 1, 5, 17, 30, 0, 0, time.FixedZone("CET", 3600))
+	return &model.Todo{
+		ID:          7,
+		Title:       "Buy milk, eggs; bread",
+		Description: "Line one\nLine two",
+		DueAt:       &due,
+		Version:     3,
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(time.Hour),
+	}
+}
+
+func encode(t *testing.T, opts Options, fn func(*Encoder, *model.Todo) error, todos ...*model.Todo) string {
+	t.Helper()
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, opts)
+	require.NoError(t, e.Begin())
+	for _, todo := range todos {
+		require.NoError(t, fn(e, todo))
+	}
+	require.NoError(t, e.End())
+	return buf.String()
+}
+
+func TestEncoder_Calendar(t *testing.T) {
+	out := encode(t, Options{Name: "Todos", RefreshInterval: 90 * time.Minute}, (*Encoder).EncodeTodo)
+
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:"+DefaultProdID+"\r\n"+
+		"CALSCALE:GREGORIAN\r\n"+
+		"X-WR-CALNAME:Todos\r\n"+
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H30M\r\n"+
+		"X-PUBLISHED-TTL:PT1H30M\r\n"+
+		"END:VCALENDAR\r\n", out)
+}
+
+func TestEncoder_EncodeTodo(t *testing.T) {
+	todo := testTodo()
+
+	out := encode(t, Options{Domain: "example.com"}, (*Encoder).EncodeTodo, todo)
+
+	assert.Contains(t, out, "BEGIN:VTODO\r\nUID:todo-7@example.com\r\n")
+	assert.Contains(t, out, "DTSTAMP:20240101T130000Z\r\n")
+	assert.Contains(t, out, "CREATED:20240101T120000Z\r\n")
+	assert.Contains(t, out, "SEQUENCE:2\r\n")
+	assert.Contains(t, out, `SUMMARY:Buy milk\, eggs\; bread`+"\r\n")
+	assert.Contains(t, out, `DESCRIPTION:Line one\nLine two`+"\r\n")
+	assert.Contains(t, out, "DUE:20240105T163000Z\r\n")
+	assert.Contains(t, out, "STATUS:NEEDS-ACTION\r\nEND:VTODO\r\n")
+	assert.NotContains(t, out, "COMPLETED:")
+}
+
+func TestEncoder_EncodeTodo_Completed(t *testing.T) {
+	todo := testTodo()
+	completedAt := time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)
+	todo.Completed = true
+	todo.CompletedAt = &completedAt
+	todo.DueAt = nil
+
+	out := encode(t, Options{}, (*Encoder).EncodeTodo, todo)
+
+	assert.Contains(t, out, "STATUS:COMPLETED\r\nPERCENT-COMPLETE:100\r\nCOMPLETED:20240103T090000Z\r\n")
+	assert.NotContains(t, out, "DUE:")
+}
+
+func TestEncoder_EncodeEvent(t *testing.T) {
+	todo := testTodo()
+	undated := testTodo()
+	undated.ID = 8
+	undated.DueAt = nil
+
+	out := encode(t, Options{}, (*Encoder).EncodeEvent, todo, undated)
+
+	assert.Contains(t, out, "BEGIN:VEVENT\r\nUID:todo-7-due@"+DefaultDomain+"\r\n")
+	assert.Contains(t, out, "DTSTART:20240105T163000Z\r\nTRANSP:TRANSPARENT\r\nEND:VEVENT\r\n")
+	assert.Equal(t, 1, strings.Count(out, "BEGIN:VEVENT"), "todos without a due date are skipped")
+	assert.NotContains(t, out, "todo-8")
+}
+
+func TestEncoder_IsDeterministic(t *testing.T) {
+	todo := testTodo()
+
+	assert.Equal(t,
+		encode(t, Options{}, (*Encoder).EncodeTodo, todo),
+		encode(t, Options{}, (*Encoder).EncodeTodo, todo))
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"plain", "plain"},
+		{`back\slash`, `back\\slash`},
+		{"a;b,c", `a\;b\,c`},
+		{"crlf\r\nbreak", `crlf\nbreak`},
+		{"lone\rreturn", "lonereturn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.expected, escapeText(tt.in))
+		})
+	}
+}
+
+func TestWriteFolded(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:short"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"long ascii", "SUMMARY:" + strings.Repeat("a", 200)},
+		{"long multi-byte", "SUMMARY:" + strings.Repeat("é€", 60)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeFolded(&buf, tt.line)
+
+			out := buf.String()
+			require.True(t, strings.HasSuffix(out, "\r\n"))
+			for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+				assert.LessOrEqual(t, len(l), maxLineOctets)
+				assert.True(t, strings.ToValidUTF8(l, "?") == l, "folding must not split characters")
+			}
+			// Unfolding restores the original line
+			assert.Equal(t, tt.line, strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""))
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "PT1H", formatDuration(time.Hour))
+	assert.Equal(t, "PT1H5S", formatDuration(time.Hour+5*time.Second))
+	assert.Equal(t, "PT15M", formatDuration(15*time.Minute))
+	assert.Equal(t, "PT0S", formatDuration(0))
+}
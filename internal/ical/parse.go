@@ -0,0 +1,275 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxParseSize is the largest calendar Parse accepts
+const maxParseSize = 1 << 20
+
+var (
+	// ErrInvalidCalendar is returned for data that is not a well-formed iCalendar object
+	ErrInvalidCalendar = errors.New("invalid iCalendar data")
+
+	// ErrNoTodo is returned for calendars that do not hold exactly one to-do
+	ErrNoTodo = errors.New("calendar must contain exactly one VTODO")
+)
+
+// Todo holds the properties of a VTODO that map onto a todo
+type Todo struct {
+	UID         string
+	Summary     string
+	Description string
+	Due         *time.Time
+	// Completed is set by STATUS:COMPLETED, or by a COMPLETED date when STATUS is absent
+	Completed bool
+}
+
+// property is a content line split into its name, parameters and value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads a calendar holding a single to-do, as a CalDAV client stores it.
+// Recurrence overrides sharing the UID of the to-do are ignored, as are
+// properties and components todos have no field for.
+func Parse(data []byte) (*Todo, error) {
+	if len(data) > maxParseSize {
+		return nil, fmt.Errorf("%w: calendar is larger than %d bytes", ErrInvalidCalendar, maxParseSize)
+	}
+
+	lines, err := unfold(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("%w: missing BEGIN:VCALENDAR", ErrInvalidCalendar)
+	}
+
+	var (
+		todo  *Todo
+		props []property
+		stack []string
+		found int
+	)
+	for _, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(prop.value))
+			if len(stack) == 2 && stack[1] == "VTODO" {
+				props = props[:0]
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(prop.value) {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrInvalidCalendar, prop.value)
+			}
+			if len(stack) == 2 && stack[1] == "VTODO" {
+				parsed, err := newTodo(props)
+				if err != nil {
+					return nil, err
+				}
+				found++
+				if todo == nil {
+					todo = parsed
+				} else if parsed.UID != todo.UID {
+					return nil, fmt.Errorf("%w: found to-dos with different UIDs", ErrNoTodo)
+				}
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// Only the properties of the to-do itself matter, not those of its alarms
+		if len(stack) == 2 && stack[1] == "VTODO" {
+			props = append(props, prop)
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("%w: missing END:%s", ErrInvalidCalendar, stack[len(stack)-1])
+	}
+	if found == 0 {
+		return nil, ErrNoTodo
+	}
+	return todo, nil
+}
+
+// newTodo maps the properties of a VTODO onto a Todo
+func newTodo(props []property) (*Todo, error) {
+	todo := &Todo{}
+	status := ""
+	hasCompleted := false
+	for _, prop := range props {
+		switch prop.name {
+		case "UID":
+			todo.UID = unescapeText(prop.value)
+		case "SUMMARY":
+			todo.Summary = unescapeText(prop.value)
+		case "DESCRIPTION":
+			todo.Description = unescapeText(prop.value)
+		case "DUE":
+			due, err := parseDateTime(prop)
+			if err != nil {
+				return nil, err
+			}
+			todo.Due = &due
+		case "STATUS":
+			status = strings.ToUpper(prop.value)
+		case "COMPLETED":
+			hasCompleted = true
+		}
+	}
+	if todo.UID == "" {
+		return nil, fmt.Errorf("%w: VTODO has no UID", ErrInvalidCalendar)
+	}
+
+	todo.Completed = status == "COMPLETED" || (status == "" && hasCompleted)
+	return todo, nil
+}
+
+// unfold splits data into content lines, joining folded lines. Both CRLF and
+// bare LF line breaks are accepted, as many clients send either.
+func unfold(data []byte) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 4096), maxParseSize)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("%w: continuation line without content line", ErrInvalidCalendar)
+			}
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	return lines, nil
+}
+
+// parseLine splits a content line into its name, parameters and value.
+// Parameter values may be quoted to contain ':', ';' and ','.
+func parseLine(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return prop, fmt.Errorf("%w: malformed content line %q", ErrInvalidCalendar, line)
+	}
+	prop.name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("%w: malformed parameter in %s", ErrInvalidCalendar, prop.name)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		var end int
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				return prop, fmt.Errorf("%w: unterminated quote in %s", ErrInvalidCalendar, prop.name)
+			}
+			value = rest[1 : closing+1]
+			end = closing + 2
+		} else {
+			end = strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return prop, fmt.Errorf("%w: missing value of %s", ErrInvalidCalendar, prop.name)
+			}
+			value = rest[:end]
+		}
+		prop.params[name] = value
+
+		i += 1 + eq + 1 + end
+		if i >= len(line) {
+			return prop, fmt.Errorf("%w: missing value of %s", ErrInvalidCalendar, prop.name)
+		}
+	}
+	if line[i] != ':' {
+		return prop, fmt.Errorf("%w: malformed content line %q", ErrInvalidCalendar, line)
+	}
+
+	prop.value = line[i+1:]
+	return prop, nil
+}
+
+// parseDateTime parses a DATE or DATE-TIME value. Dates are taken as midnight
+// UTC; local times are interpreted in their TZID, or in UTC when it is absent
+// or unknown.
+func parseDateTime(prop property) (time.Time, error) {
+	value := prop.value
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: invalid date %q in %s", ErrInvalidCalendar, value, prop.name)
+		}
+		return t, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeLayout, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: invalid date-time %q in %s", ErrInvalidCalendar, value, prop.name)
+		}
+		return t, nil
+	}
+
+	loc := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date-time %q in %s", ErrInvalidCalendar, value, prop.name)
+	}
+	return t.UTC(), nil
+}
+
+// unescapeText reverses the escaping of a TEXT value
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			// \\, \; and \, stand for the character itself
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
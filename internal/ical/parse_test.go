@@ -0,0 +1,152 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func calendar(lines ...string) []byte {
+	return []byte(strings.Join(append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...), "END:VCALENDAR"), "\r\n") + "\r\n")
+}
+
+func TestParse(t *testing.T) {
+	todo, err := Parse(calendar(
+		"BEGIN:VTODO",
+		"UID:abc@phone",
+		"SUMMARY:Buy milk\\, eggs\\; bread",
+		"DESCRIPTION:Line one\\nLine ",
+		" two",
+		"DUE;TZID=Europe/Berlin:20240105T173000",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VTODO",
+	))
+
+	require.NoError(t, err)
+	assert.Equal(t, "abc@phone", todo.UID)
+	assert.Equal(t, "Buy milk, eggs; bread", todo.Summary)
+	assert.Equal(t, "Line one\nLine two", todo.Description)
+	require.NotNil(t, todo.Due)
+	assert.Equal(t, time.Date(2024, 1, 5, 16, 30, 0, 0, time.UTC), *todo.Due)
+	assert.False(t, todo.Completed)
+}
+
+func TestParse_AcceptsLF(t *testing.T) {
+	data := strings.ReplaceAll(string(calendar("BEGIN:VTODO", "UID:1", "SUMMARY:Plain", "END:VTODO")), "\r\n", "\n")
+
+	todo, err := Parse([]byte(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Plain", todo.Summary)
+}
+
+func TestParse_Completed(t *testing.T) {
+	tests := []struct {
+		name  string
+		props []string
+		want  bool
+	}{
+		{"needs action", []string{"STATUS:NEEDS-ACTION"}, false},
+		{"completed status", []string{"STATUS:COMPLETED"}, true},
+		{"completed date without status", []string{"COMPLETED:20240101T120000Z"}, true},
+		{"reopened with completed date", []string{"STATUS:IN-PROCESS", "COMPLETED:20240101T120000Z"}, false},
+		{"no status", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{"BEGIN:VTODO", "UID:1"}, tt.props...)
+			todo, err := Parse(calendar(append(lines, "END:VTODO")...))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, todo.Completed)
+		})
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	tests := []struct {
+		line string
+		want time.Time
+	}{
+		{"DUE:20240105T173000Z", time.Date(2024, 1, 5, 17, 30, 0, 0, time.UTC)},
+		{"DUE;VALUE=DATE:20240105", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"DUE:20240105", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"DUE:20240105T173000", time.Date(2024, 1, 5, 17, 30, 0, 0, time.UTC)},
+		{`DUE;TZID="America/New_York":20240105T173000`, time.Date(2024, 1, 5, 22, 30, 0, 0, time.UTC)},
+		{"DUE;TZID=Unknown/Zone:20240105T173000", time.Date(2024, 1, 5, 17, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			prop, err := parseLine(tt.line)
+			require.NoError(t, err)
+
+			got, err := parseDateTime(prop)
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestParseLine_QuotedParameters(t *testing.T) {
+	prop, err := parseLine(`ATTENDEE;CN="Doe; John";ROLE=REQ-PARTICIPANT:mailto:john@example.com`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ATTENDEE", prop.name)
+	assert.Equal(t, "Doe; John", prop.params["CN"])
+	assert.Equal(t, "REQ-PARTICIPANT", prop.params["ROLE"])
+	assert.Equal(t, "mailto:john@example.com", prop.value)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrInvalidCalendar},
+		{"not a calendar", []byte("hello\r\n"), ErrInvalidCalendar},
+		{"unterminated", []byte("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:1\r\nEND:VTODO\r\n"), ErrInvalidCalendar},
+		{"mismatched end", calendar("BEGIN:VTODO", "UID:1", "END:VEVENT"), ErrInvalidCalendar},
+		{"missing UID", calendar("BEGIN:VTODO", "SUMMARY:No UID", "END:VTODO"), ErrInvalidCalendar},
+		{"invalid due", calendar("BEGIN:VTODO", "UID:1", "DUE:tomorrow", "END:VTODO"), ErrInvalidCalendar},
+		{"event", calendar("BEGIN:VEVENT", "UID:1", "END:VEVENT"), ErrNoTodo},
+		{"two todos", calendar("BEGIN:VTODO", "UID:1", "END:VTODO", "BEGIN:VTODO", "UID:2", "END:VTODO"), ErrNoTodo},
+		{"too large", make([]byte, maxParseSize+1), ErrInvalidCalendar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestParse_RoundTripsMarshal(t *testing.T) {
+	todo := testTodo()
+	todo.Completed = true
+	completedAt := todo.UpdatedAt
+	todo.CompletedAt = &completedAt
+
+	data, err := Marshal(todo, "abc@phone", Options{})
+	require.NoError(t, err)
+
+	parsed, err := Parse(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "abc@phone", parsed.UID)
+	assert.Equal(t, todo.Title, parsed.Summary)
+	assert.Equal(t, todo.Description, parsed.Description)
+	assert.True(t, todo.DueAt.Equal(*parsed.Due))
+	assert.True(t, parsed.Completed)
+}
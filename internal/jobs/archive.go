@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-api-backend/internal/repository"
+)
+
+// AutoArchiver periodically archives completed todos according to each
+// user's auto-archive policy
+type AutoArchiver struct {
+	userRepo repository.UserRepository
+	todoRepo repository.TodoRepository
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewAutoArchiver creates a new auto-archiver
+func NewAutoArchiver(userRepo repository.UserRepository, todoRepo repository.TodoRepository, interval time.Duration) *AutoArchiver {
+	return &AutoArchiver{
+		userRepo: userRepo,
+		todoRepo: todoRepo,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the auto-archiver on every interval until the context is cancelled
+func (a *AutoArchiver) Start(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	log.Printf("Auto-archiver started (interval: %s)", a.interval)
+
+	for {
+		if _, err := a.RunOnce(ctx); err != nil {
+			log.Printf("Auto-archive failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Auto-archiver stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce applies every enabled auto-archive policy and returns the number of todos archived
+func (a *AutoArchiver) RunOnce(ctx context.Context) (int64, error) {
+	users, err := a.userRepo.GetWithAutoArchive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, user := range users {
+		now := a.now()
+		cutoff := now.AddDate(0, 0, -user.AutoArchiveDays)
+
+		archived, err := a.todoRepo.ArchiveCompletedBefore(ctx, user.ID, cutoff, now)
+		if err != nil {
+			// Keep going so one failing user does not block everyone else
+			log.Printf("Auto-archive failed for user %d: %v", user.ID, err)
+			continue
+		}
+		total += int64(len(archived))
+	}
+
+	if total > 0 {
+		log.Printf("Auto-archiver archived %d todo(s)", total)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/internal/storage"
+)
+
+// attachmentSweepBatchSize is how many orphaned attachments are removed per batch
+const attachmentSweepBatchSize = 100
+
+// AttachmentSweeper periodically deletes the blobs and metadata of
+// attachments whose todo has been purged
+type AttachmentSweeper struct {
+	repo     repository.AttachmentRepository
+	store    storage.BlobStore
+	interval time.Duration
+}
+
+// NewAttachmentSweeper creates a new attachment sweeper
+func NewAttachmentSweeper(repo repository.AttachmentRepository, store storage.BlobStore, interval time.Duration) *AttachmentSweeper {
+	return &AttachmentSweeper{
+		repo:     repo,
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start runs the sweeper on every interval until the context is cancelled
+func (s *AttachmentSweeper) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	log.Printf("Attachment sweeper started (interval: %s)", s.interval)
+
+	for {
+		// Run immediately on start, then on every tick
+		if _, err := s.RunOnce(ctx); err != nil {
+			log.Printf("Attachment sweep failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Attachment sweeper stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce removes orphaned attachments in batches and returns the number
+// removed. The blob of an attachment is deleted before its row, so a blob
+// that cannot be deleted keeps its row and is retried on the next run.
+func (s *AttachmentSweeper) RunOnce(ctx context.Context) (int64, error) {
+	var removed int64
+	for {
+		attachments, err := s.repo.ListOrphaned(ctx, attachmentSweepBatchSize)
+		if err != nil {
+			return removed, err
+		}
+
+		failed := false
+		for _, attachment := range attachments {
+			if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+				log.Printf("Attachment sweeper failed to delete blob of attachment %d: %v", attachment.ID, err)
+				failed = true
+				continue
+			}
+			if err := s.repo.Delete(ctx, attachment.ID, attachment.TodoID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		// A failed blob would be listed again, so leave it to the next run
+		if failed || len(attachments) < attachmentSweepBatchSize {
+			break
+		}
+	}
+
+	if removed > 0 {
+		log.Printf("Attachment sweeper removed %d orphaned attachment(s)", removed)
+	}
+	return removed, nil
+}
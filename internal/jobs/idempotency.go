@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-api-backend/internal/repository"
+)
+
+// IdempotencyKeyPurger periodically deletes idempotency keys whose TTL has passed
+type IdempotencyKeyPurger struct {
+	repo     repository.IdempotencyRepository
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewIdempotencyKeyPurger creates a new idempotency key purger
+func NewIdempotencyKeyPurger(repo repository.IdempotencyRepository, interval time.Duration) *IdempotencyKeyPurger {
+	return &IdempotencyKeyPurger{
+		repo:     repo,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the purger on every interval until the context is cancelled
+func (p *IdempotencyKeyPurger) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	log.Printf("Idempotency key purger started (interval: %s)", p.interval)
+
+	for {
+		if _, err := p.RunOnce(ctx); err != nil {
+			log.Printf("Idempotency key purge failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Idempotency key purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce deletes all expired idempotency keys and returns the number deleted
+func (p *IdempotencyKeyPurger) RunOnce(ctx context.Context) (int64, error) {
+	purged, err := p.repo.DeleteExpired(ctx, p.now())
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		log.Printf("Idempotency key purger removed %d expired key(s)", purged)
+	}
+
+	return purged, nil
+}
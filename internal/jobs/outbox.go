@@ -0,0 +1,148 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/outbox"
+	"todo-api-backend/internal/repository"
+)
+
+const (
+	// outboxPublishTimeout bounds publishing a single message
+	outboxPublishTimeout = 10 * time.Second
+
+	// outboxBatchSize is the number of messages claimed per round
+	outboxBatchSize = 100
+
+	// outboxBaseBackoff is the delay before the first retry; it doubles with every attempt
+	outboxBaseBackoff = time.Second
+
+	// outboxMaxBackoff caps the delay between retries
+	outboxMaxBackoff = 5 * time.Minute
+
+	// outboxRetention is how long published messages are kept
+	outboxRetention = 7 * 24 * time.Hour
+
+	// maxOutboxError limits the length of the error stored with a message
+	maxOutboxError = 512
+)
+
+// OutboxRelay periodically publishes the domain events written to the
+// transactional outbox. Messages are published at least once and in order
+// per aggregate: a message that cannot be published is retried with
+// exponential backoff, and later messages of its aggregate wait for it.
+type OutboxRelay struct {
+	repo      repository.OutboxRepository
+	publisher outbox.EventPublisher
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(repo repository.OutboxRepository, publisher outbox.EventPublisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start runs the relay on every interval until the context is cancelled
+func (r *OutboxRelay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	log.Printf("Outbox relay started (interval: %s)", r.interval)
+
+	for {
+		if _, err := r.RunOnce(ctx); err != nil {
+			log.Printf("Outbox relay failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce publishes every due message, removes old published messages and
+// returns the number of messages published
+func (r *OutboxRelay) RunOnce(ctx context.Context) (int, error) {
+	if _, err := r.repo.DeletePublishedBefore(ctx, r.now().Add(-outboxRetention)); err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+
+	published := 0
+	for ctx.Err() == nil {
+		// Every round claims the next message of each aggregate; publishing it
+		// makes the following message of the aggregate claimable in the next round
+		now := r.now()
+		lease := now.Add(outboxBatchSize*outboxPublishTimeout + time.Minute)
+		batch, err := r.repo.ClaimNext(ctx, now, lease, outboxBatchSize)
+		if err != nil {
+			return published, fmt.Errorf("failed to claim outbox messages: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, message := range batch {
+			ok, err := r.publish(ctx, message)
+			if err != nil {
+				return published, err
+			}
+			if ok {
+				published++
+			}
+		}
+	}
+
+	if published > 0 {
+		log.Printf("Outbox relay published %d event(s)", published)
+	}
+
+	return published, nil
+}
+
+// publish publishes a single message and records the outcome, reporting whether it was published
+func (r *OutboxRelay) publish(ctx context.Context, message *model.OutboxMessage) (bool, error) {
+	publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	publishErr := r.publisher.Publish(publishCtx, message)
+	cancel()
+
+	now := r.now()
+	if publishErr == nil {
+		if err := r.repo.MarkPublished(ctx, message.ID, now); err != nil {
+			return false, fmt.Errorf("failed to mark outbox message %d published: %w", message.ID, err)
+		}
+		return true, nil
+	}
+
+	message.Attempts++
+	message.LastError = truncate(publishErr.Error(), maxOutboxError)
+	message.NextAttemptAt = now.Add(outboxBackoff(message.Attempts))
+	if err := r.repo.MarkFailed(ctx, message); err != nil {
+		return false, fmt.Errorf("failed to record outbox message %d failure: %w", message.ID, err)
+	}
+
+	log.Printf("Failed to publish outbox message %d (%s %s/%d, attempt %d): %v",
+		message.ID, message.EventType, message.AggregateType, message.AggregateID, message.Attempts, publishErr)
+	return false, nil
+}
+
+// outboxBackoff returns the delay before the retry that follows the given attempt
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, outboxMaxBackoff)
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-api-backend/internal/repository"
+)
+
+// TrashPurger periodically and permanently deletes todos that have been
+// in the trash for longer than the configured retention period
+type TrashPurger struct {
+	todoRepo  repository.TodoRepository
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewTrashPurger creates a new trash purger
+func NewTrashPurger(todoRepo repository.TodoRepository, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		todoRepo:  todoRepo,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start runs the purger on every interval until the context is cancelled
+func (p *TrashPurger) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	log.Printf("Trash purger started (retention: %s, interval: %s)", p.retention, p.interval)
+
+	for {
+		// Run immediately on start, then on every tick
+		if _, err := p.RunOnce(ctx); err != nil {
+			log.Printf("Trash purge failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Trash purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges all todos deleted before the retention cutoff and returns the number purged
+func (p *TrashPurger) RunOnce(ctx context.Context) (int64, error) {
+	cutoff := p.now().Add(-p.retention)
+
+	purged, err := p.todoRepo.PurgeDeletedBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		log.Printf("Trash purger removed %d todo(s) deleted before %s", purged, cutoff.UTC().Format(time.RFC3339))
+	}
+
+	return purged, nil
+}
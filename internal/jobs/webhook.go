@@ -0,0 +1,270 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/pkg/webhook"
+)
+
+const (
+	// webhookTimeout bounds a single delivery attempt
+	webhookTimeout = 10 * time.Second
+
+	// webhookBatchSize is the number of deliveries claimed per run
+	webhookBatchSize = 20
+
+	// webhookBaseBackoff is the delay before the first retry; it doubles with every attempt
+	webhookBaseBackoff = 30 * time.Second
+
+	// webhookMaxBackoff caps the delay between retries
+	webhookMaxBackoff = time.Hour
+
+	// webhookLogRetention is how long finished deliveries are kept in the delivery log
+	webhookLogRetention = 30 * 24 * time.Hour
+
+	// maxWebhookError limits the length of the error stored with a delivery
+	maxWebhookError = 512
+)
+
+// WebhookDispatcher periodically delivers queued webhook events, retrying
+// failed deliveries with exponential backoff and disabling webhooks that
+// keep failing
+type WebhookDispatcher struct {
+	webhooks     repository.WebhookRepository
+	deliveries   repository.WebhookDeliveryRepository
+	client       *http.Client
+	interval     time.Duration
+	maxAttempts  int
+	disableAfter int
+	now          func() time.Time
+}
+
+// WebhookDispatcherOption configures optional behaviour of the webhook dispatcher
+type WebhookDispatcherOption func(*WebhookDispatcher)
+
+// WithWebhookTransport replaces the transport deliveries are sent with, which
+// by default refuses to connect to loopback, private and other internal
+// addresses
+func WithWebhookTransport(transport http.RoundTripper) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.client.Transport = transport
+	}
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher. A delivery is given
+// up after maxAttempts attempts and a webhook is disabled after disableAfter
+// consecutive failed attempts, or never if disableAfter is 0.
+func NewWebhookDispatcher(webhooks repository.WebhookRepository, deliveries repository.WebhookDeliveryRepository, interval time.Duration, maxAttempts, disableAfter int, opts ...WebhookDispatcherOption) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		client: &http.Client{
+			Timeout:   webhookTimeout,
+			Transport: newWebhookTransport(),
+			// A redirect is reported as a failure rather than followed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		interval:     interval,
+		maxAttempts:  maxAttempts,
+		disableAfter: disableAfter,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// newWebhookTransport returns a transport that checks every address it
+// connects to, so a webhook host that starts resolving to an internal address
+// after registration is refused. Proxies are not used, as they would connect
+// on the transport's behalf without the check.
+func newWebhookTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = webhook.DialContext(&net.Dialer{
+		Timeout:   webhookTimeout,
+		KeepAlive: 30 * time.Second,
+	})
+	return transport
+}
+
+// Start runs the dispatcher on every interval until the context is cancelled
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	log.Printf("Webhook dispatcher started (interval: %s, max attempts: %d)", d.interval, d.maxAttempts)
+
+	for {
+		if _, err := d.RunOnce(ctx); err != nil {
+			log.Printf("Webhook dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce attempts every due delivery, removes expired entries from the
+// delivery log and returns the number of deliveries attempted
+func (d *WebhookDispatcher) RunOnce(ctx context.Context) (int, error) {
+	if _, err := d.deliveries.DeleteFinishedBefore(ctx, d.now().Add(-webhookLogRetention)); err != nil {
+		return 0, fmt.Errorf("failed to prune webhook delivery log: %w", err)
+	}
+
+	attempted := 0
+	for {
+		// Claimed deliveries are skipped by other instances until they have all been attempted
+		now := d.now()
+		lease := now.Add(webhookBatchSize*webhookTimeout + time.Minute)
+		batch, err := d.deliveries.ClaimDue(ctx, now, lease, webhookBatchSize)
+		if err != nil {
+			return attempted, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+
+		for _, delivery := range batch {
+			if err := d.deliver(ctx, delivery); err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+
+		if len(batch) < webhookBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if attempted > 0 {
+		log.Printf("Webhook dispatcher made %d delivery attempt(s)", attempted)
+	}
+
+	return attempted, nil
+}
+
+// deliver attempts a single delivery and records its outcome
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *model.WebhookDelivery) error {
+	hook, err := d.webhooks.GetByIDForDelivery(ctx, delivery.WebhookID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get webhook %d: %w", delivery.WebhookID, err)
+	}
+
+	// Deliveries of a disabled webhook are given up rather than kept for reactivation
+	if hook == nil || !hook.Active {
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.LastError = "webhook disabled"
+		return d.update(ctx, delivery)
+	}
+
+	now := d.now()
+	code, postErr := d.post(ctx, hook, delivery, now)
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseCode = code
+
+	if postErr == nil {
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		if err := d.webhooks.RecordSuccess(ctx, hook.ID); err != nil {
+			return fmt.Errorf("failed to reset webhook %d failures: %w", hook.ID, err)
+		}
+		return d.update(ctx, delivery)
+	}
+
+	delivery.LastError = truncate(postErr.Error(), maxWebhookError)
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = model.WebhookDeliveryFailed
+	} else {
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+	}
+
+	reason := fmt.Sprintf("%d consecutive failed delivery attempts", d.disableAfter)
+	disabled, err := d.webhooks.RecordFailure(ctx, hook.ID, d.disableAfter, reason, now)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook %d failure: %w", hook.ID, err)
+	}
+	if disabled {
+		log.Printf("Webhook %d disabled after %d consecutive failed delivery attempts", hook.ID, d.disableAfter)
+	}
+
+	return d.update(ctx, delivery)
+}
+
+// post sends a delivery to its webhook and returns the response status code, if any
+func (d *WebhookDispatcher) post(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-api-webhooks/1.0")
+	req.Header.Set(webhook.EventHeader, delivery.EventType)
+	req.Header.Set(webhook.DeliveryHeader, delivery.EventID)
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a bounded part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// update stores the outcome of a delivery attempt
+func (d *WebhookDispatcher) update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	if err := d.deliveries.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery %d: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// webhookBackoff returns the delay before the retry that follows the given attempt
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short", "timeout", 10, "timeout"},
+		{"ascii", "connection refused", 10, "connection"},
+		{"rune boundary", "héllo", 3, "hé"},
+		{"inside a rune", "héllo", 2, "h"},
+		{"inside a wide rune", "a日本", 3, "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.n)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+}
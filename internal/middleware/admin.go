@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware restricts access to the users whose ID is in adminUserIDs.
+// Admin rights are keyed on user IDs rather than emails, so registering the
+// address of an admin who has not signed up yet does not grant them.
+// It must run after AuthMiddleware.
+func AdminMiddleware(adminUserIDs []uint) gin.HandlerFunc {
+	admins := make(map[uint]struct{}, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		if id != 0 {
+			admins[id] = struct{}{}
+		}
+	}
+
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		if _, isAdmin := admins[userID]; !isAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "Administrator access required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
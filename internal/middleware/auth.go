@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"todo-api-backend/internal/model"
 	"todo-api-backend/pkg/jwt"
 )
 
@@ -15,61 +17,104 @@ const (
 	UserEmailKey       = "user_email"
 )
 
+// SecurityAuditor records security events such as rejected tokens
+type SecurityAuditor interface {
+	Record(ctx context.Context, event *model.SecurityEvent)
+}
+
+// AuthOption configures optional behaviour of the authentication middleware
+type AuthOption func(*authConfig)
+
+// authConfig holds the optional settings of the authentication middleware
+type authConfig struct {
+	auditor    SecurityAuditor
+	queryToken string
+}
+
+// WithSecurityAuditor records token validation failures in the security audit log
+func WithSecurityAuditor(auditor SecurityAuditor) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.auditor = auditor
+	}
+}
+
+// WithQueryToken also accepts the token in the given query parameter when the
+// Authorization header is absent, for clients such as browser WebSockets that
+// cannot set headers
+func WithQueryToken(param string) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.queryToken = param
+	}
+}
+
 // AuthMiddleware creates a JWT authentication middleware
-func AuthMiddleware(tokenManager *jwt.TokenManager) gin.HandlerFunc {
+func AuthMiddleware(tokenManager *jwt.TokenManager, opts ...AuthOption) gin.HandlerFunc {
+	cfg := &authConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// reject aborts the request and records the failure if an auditor is configured
+	reject := func(c *gin.Context, reason, message string) {
+		if cfg.auditor != nil {
+			cfg.auditor.Record(c.Request.Context(), &model.SecurityEvent{
+				EventType: model.SecurityEventTokenValidation,
+				Outcome:   model.SecurityOutcomeFailure,
+				IP:        c.ClientIP(),
+				UserAgent: c.Request.UserAgent(),
+				Reason:    reason,
+			})
+		}
+
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": message,
+		})
+		c.Abort()
+	}
+
 	return func(c *gin.Context) {
 		// Extract token from Authorization header
 		authHeader := c.GetHeader(AuthorizationHeader)
+		if authHeader == "" && cfg.queryToken != "" {
+			if token := c.Query(cfg.queryToken); token != "" {
+				authHeader = BearerPrefix + token
+			}
+		}
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Authorization header is required",
-			})
-			c.Abort()
+			reject(c, "missing_header", "Authorization header is required")
 			return
 		}
 
 		// Check if the header starts with "Bearer "
 		if !strings.HasPrefix(authHeader, BearerPrefix) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Authorization header must start with 'Bearer '",
-			})
-			c.Abort()
+			reject(c, "invalid_scheme", "Authorization header must start with 'Bearer '")
 			return
 		}
 
 		// Extract the token part
 		tokenString := strings.TrimPrefix(authHeader, BearerPrefix)
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Token is required",
-			})
-			c.Abort()
+			reject(c, "missing_token", "Token is required")
 			return
 		}
 
 		// Validate the token
 		claims, err := tokenManager.ValidateToken(tokenString)
 		if err != nil {
-			var message string
+			var reason, message string
 			switch err {
 			case jwt.ErrExpiredToken:
-				message = "Token has expired"
+				reason, message = "expired_token", "Token has expired"
 			case jwt.ErrInvalidToken:
-				message = "Invalid token"
+				reason, message = "invalid_token", "Invalid token"
 			case jwt.ErrTokenClaims:
-				message = "Invalid token claims"
+				reason, message = "invalid_claims", "Invalid token claims"
 			default:
-				message = "Token validation failed"
+				reason, message = "validation_failed", "Token validation failed"
 			}
 
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": message,
-			})
-			c.Abort()
+			reject(c, reason, message)
 			return
 		}
 
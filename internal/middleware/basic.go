@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo-api-backend/internal/model"
+)
+
+// CredentialVerifier verifies the credentials of HTTP Basic auth
+type CredentialVerifier interface {
+	// Authenticate returns the user the credentials belong to, or an error if they are invalid
+	Authenticate(ctx context.Context, username, password string) (uint, error)
+}
+
+// CredentialErrorFunc reports whether an authentication error means that the
+// credentials were rejected, as opposed to the check itself failing
+type CredentialErrorFunc func(err error) bool
+
+// BasicAuthMiddleware creates an HTTP Basic authentication middleware for
+// clients such as CalDAV apps that cannot obtain a JWT. Requests without
+// credentials are challenged for them; rejected credentials are recorded in the
+// security audit log if an auditor is configured.
+func BasicAuthMiddleware(verifier CredentialVerifier, realm string, rejected CredentialErrorFunc, opts ...AuthOption) gin.HandlerFunc {
+	cfg := &authConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	challenge := `Basic realm="` + realm + `", charset="UTF-8"`
+
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		userID, err := verifier.Authenticate(c.Request.Context(), username, password)
+		if err != nil {
+			if !rejected(err) {
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			if cfg.auditor != nil {
+				cfg.auditor.Record(c.Request.Context(), &model.SecurityEvent{
+					EventType: model.SecurityEventCalDAVLogin,
+					Outcome:   model.SecurityOutcomeFailure,
+					Email:     username,
+					IP:        c.ClientIP(),
+					UserAgent: c.Request.UserAgent(),
+					Reason:    "invalid_credentials",
+				})
+			}
+
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		// Add user information to the context
+		c.Set(UserIDKey, userID)
+		c.Set(UserEmailKey, username)
+
+		c.Next()
+	}
+}
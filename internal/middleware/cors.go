@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +16,9 @@ type CORSConfig struct {
 	ExposeHeaders    []string
 	AllowCredentials bool
 	MaxAge           int
+	// ExcludePaths lists path prefixes left to their own handlers, such as
+	// WebDAV endpoints that answer OPTIONS themselves
+	ExcludePaths []string
 }
 
 // DefaultCORSConfig returns a default CORS configuration
@@ -37,10 +41,16 @@ func DefaultCORSConfig() *CORSConfig {
 			"Authorization",
 			"Accept",
 			"X-Requested-With",
+			"If-Match",
+			"If-None-Match",
+			"Idempotency-Key",
+			"Last-Event-ID",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			"ETag",
+			"Idempotent-Replayed",
 		},
 		AllowCredentials: false,
 		MaxAge:           12 * 3600, // 12 hours
@@ -54,6 +64,13 @@ func CORSMiddleware(config *CORSConfig) gin.HandlerFunc {
 	}
 
 	return func(c *gin.Context) {
+		for _, prefix := range config.ExcludePaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
 		origin := c.Request.Header.Get("Origin")
 		
 		// Set Access-Control-Allow-Origin
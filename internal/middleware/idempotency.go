@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"todo-api-backend/internal/model"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// idempotencyRenewInterval is how often the key of a running request is
+	// renewed, well within the minute after which the store treats a silent
+	// reservation as abandoned
+	idempotencyRenewInterval = 15 * time.Second
+)
+
+// replayedHeaders are the response headers stored with an idempotent response
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotencyStore reserves idempotency keys and stores the responses of
+// the requests that used them
+type IdempotencyStore interface {
+	Begin(ctx context.Context, userID uint, key string, fingerprint string) (*model.IdempotencyRecord, bool, error)
+	Renew(ctx context.Context, record *model.IdempotencyRecord) error
+	Complete(ctx context.Context, record *model.IdempotencyRecord) error
+	Abandon(ctx context.Context, record *model.IdempotencyRecord) error
+}
+
+// IdempotencyMiddleware makes mutating requests that carry an
+// Idempotency-Key header safe to retry. The first request with a key is
+// processed normally and its response stored per user; retries with the same
+// key and body receive the stored response, while reusing the key for a
+// different request is rejected with 422. The key is renewed while the
+// request runs, so slow requests such as imports are not run twice. Server errors are not stored, so
+// such requests can be retried with the same key. The body is buffered to
+// fingerprint the request, so bodies larger than maxBodySize are rejected
+// with 413. It must run after AuthMiddleware.
+func IdempotencyMiddleware(store IdempotencyStore, maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		userID, exists := GetUserID(c)
+		if !exists {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_idempotency_key",
+				Message: "Idempotency-Key must be at most 255 characters long",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, model.ErrorResponse{
+					Error:   "request_too_large",
+					Message: fmt.Sprintf("Request bodies may be at most %d bytes", maxBodySize),
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		fingerprint := requestFingerprint(c.Request, body)
+		record, found, err := store.Begin(ctx, userID, key, fingerprint)
+		if err != nil {
+			log.Printf("Idempotency key lookup failed: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "idempotency_failed",
+				Message: "Failed to process idempotency key",
+			})
+			return
+		}
+
+		if found {
+			replayIdempotentResponse(c, record, fingerprint)
+			return
+		}
+
+		// Process the request, capturing the response for later retries
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		stopRenewing := renewIdempotencyKey(ctx, store, record)
+		c.Next()
+		stopRenewing()
+
+		// Store the outcome even if the client went away in the meantime
+		ctx = context.WithoutCancel(ctx)
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Abandon(ctx, record); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+			return
+		}
+
+		record.StatusCode = status
+		record.Body = recorder.body.Bytes()
+		record.Headers = make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				record.Headers[name] = value
+			}
+		}
+		if err := store.Complete(ctx, record); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+	}
+}
+
+// renewIdempotencyKey keeps the reservation of record alive until the
+// returned function is called, even if the client goes away in the meantime
+func renewIdempotencyKey(ctx context.Context, store IdempotencyStore, record *model.IdempotencyRecord) func() {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(idempotencyRenewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := store.Renew(ctx, record); err != nil {
+					log.Printf("Failed to renew idempotency key: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// replayIdempotentResponse answers a retried request from its stored record
+func replayIdempotentResponse(c *gin.Context, record *model.IdempotencyRecord, fingerprint string) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "idempotency_key_reused",
+			Message: "Idempotency-Key was already used for a different request",
+		})
+	case !record.IsComplete():
+		c.AbortWithStatusJSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "idempotency_key_in_use",
+			Message: "A request with this Idempotency-Key is still being processed",
+		})
+	default:
+		for name, value := range record.Headers {
+			c.Header(name, value)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Status(record.StatusCode)
+		c.Writer.WriteHeaderNow()
+		if len(record.Body) > 0 {
+			c.Writer.Write(record.Body)
+		}
+		c.Abort()
+	}
+}
+
+// requestFingerprint identifies a request by its method, URL and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// isMutatingMethod reports whether requests with method change state
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder copies everything written to the response into a buffer
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedValue replaces the values of redacted query parameters in the log
+const redactedValue = "REDACTED"
+
+// LoggerMiddleware logs requests to out in gin's default format, with the
+// values of the given query parameters redacted so that credentials such as
+// the WebSocket access token do not end up in the log
+func LoggerMiddleware(out io.Writer, redactedParams ...string) gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Output: out,
+		Formatter: func(param gin.LogFormatterParams) string {
+			param.Path = redactQuery(param.Path, redactedParams)
+			return formatLogLine(param)
+		},
+	})
+}
+
+// redactQuery replaces the values of params in the query of path, keeping
+// the order of its parameters
+func redactQuery(path string, params []string) string {
+	base, query, found := strings.Cut(path, "?")
+	if !found || len(params) == 0 {
+		return path
+	}
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil {
+			key = name
+		}
+		for _, param := range params {
+			if key == param {
+				pairs[i] = param + "=" + redactedValue
+				break
+			}
+		}
+	}
+	return base + "?" + strings.Join(pairs, "&")
+}
+
+// formatLogLine mirrors gin's default log format
+func formatLogLine(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		param.Path,
+		param.ErrorMessage,
+	)
+}
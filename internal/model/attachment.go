@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Attachment represents a file attached to a todo, such as a screenshot. The
+// contents are kept in a blob store; the database holds the metadata.
+type Attachment struct {
+	ID     uint `json:"id" gorm:"primaryKey" example:"1"`
+	UserID uint `json:"user_id" gorm:"not null;index" example:"1"`
+	TodoID uint `json:"todo_id" gorm:"not null;index" example:"1"`
+	// Filename is the name of the uploaded file without its directory
+	Filename string `json:"filename" gorm:"not null;size:255" example:"login-error.png"`
+	// ContentType is the MIME type sniffed from the contents, not the one declared by the client
+	ContentType string `json:"content_type" gorm:"not null;size:100" example:"image/png"`
+	Size        int64  `json:"size" gorm:"not null" example:"48213"`
+	// Checksum is the hex-encoded SHA-256 of the contents
+	Checksum   string    `json:"checksum" gorm:"not null;size:64" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	StorageKey string    `json:"-" gorm:"not null;size:255;uniqueIndex"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	// DownloadURL is a signed link to the contents that works without a JWT until DownloadExpiresAt
+	DownloadURL       string     `json:"download_url,omitempty" gorm:"-" example:"/api/v1/attachments/1/download?expires=1704114000&signature=5d41402abc4b2a76b9719d911017c592"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty" gorm:"-" example:"2024-01-01T12:15:00Z"`
+}
+
+// TableName specifies the table name for the Attachment model
+func (Attachment) TableName() string {
+	return "todo_attachments"
+}
+
+// AttachmentListResponse represents the attachments of a todo together with
+// the storage used by the user
+type AttachmentListResponse struct {
+	Attachments []*Attachment `json:"attachments"`
+	Count       int           `json:"count" example:"2"`
+	// UsedBytes is the total size of all attachments of the user
+	UsedBytes int64 `json:"used_bytes" example:"1048576"`
+	// QuotaBytes is how many bytes of attachments the user may store
+	QuotaBytes int64 `json:"quota_bytes" example:"104857600"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+)
+
+// Security audit event types
+const (
+	SecurityEventRegister        = "register"
+	SecurityEventLogin           = "login"
+	SecurityEventTokenValidation = "token_validation"
+	SecurityEventPasswordChange  = "password_change"
+	SecurityEventCalDAVLogin     = "caldav_login"
+)
+
+// Security audit event outcomes
+const (
+	SecurityOutcomeSuccess = "success"
+	SecurityOutcomeFailure = "failure"
+)
+
+// SecurityEvent represents an append-only record of an authentication event
+type SecurityEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey" example:"1"`
+	EventType string    `json:"event_type" gorm:"not null;size:64;index" example:"login"`
+	Outcome   string    `json:"outcome" gorm:"not null;size:16" example:"failure"`
+	UserID    *uint     `json:"user_id,omitempty" gorm:"index" example:"1"`
+	Email     string    `json:"email,omitempty" gorm:"size:255" example:"user@example.com"`
+	IP        string    `json:"ip,omitempty" gorm:"size:64" example:"203.0.113.7"`
+	UserAgent string    `json:"user_agent,omitempty" gorm:"size:512" example:"Mozilla/5.0"`
+	Reason    string    `json:"reason,omitempty" gorm:"size:255" example:"invalid_password"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the SecurityEvent model
+func (SecurityEvent) TableName() string {
+	return "security_audit_log"
+}
+
+// SecurityEventFilter represents the criteria for querying the security audit log
+type SecurityEventFilter struct {
+	UserID    *uint
+	EventType string
+	Outcome   string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+}
+
+// SecurityEventsResponse represents the response for a security audit log query
+type SecurityEventsResponse struct {
+	Events []*SecurityEvent `json:"events"`
+	Count  int              `json:"count" example:"20"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// CalDAVPassword is an app password that lets a task app sign in to the CalDAV
+// server with HTTP Basic auth. Only a hash of the password is kept, so a lost
+// password can only be replaced, not recovered.
+type CalDAVPassword struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"not null;index"`
+	Name         string `gorm:"not null;size:100"`
+	PasswordHash string `gorm:"not null;size:64;uniqueIndex"`
+	LastUsedAt   *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the CalDAVPassword model
+func (CalDAVPassword) TableName() string {
+	return "caldav_passwords"
+}
+
+// CalDAVResource records the resource name and UID a CalDAV client chose for a
+// todo it created. Other todos are served under names derived from their ID.
+type CalDAVResource struct {
+	TodoID uint   `gorm:"primaryKey;autoIncrement:false"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_caldav_resources_user_name;uniqueIndex:idx_caldav_resources_user_uid"`
+	Name   string `gorm:"not null;size:255;uniqueIndex:idx_caldav_resources_user_name"`
+	UID    string `gorm:"not null;size:255;uniqueIndex:idx_caldav_resources_user_uid"`
+}
+
+// TableName specifies the table name for the CalDAVResource model
+func (CalDAVResource) TableName() string {
+	return "caldav_resources"
+}
+
+// CalDAVObject is a todo as a calendar object resource of the CalDAV collection
+type CalDAVObject struct {
+	// Name is the last segment of the resource URL
+	Name string
+	// UID is the UID of the VTODO
+	UID  string
+	Todo *Todo
+}
+
+// CreateCalDAVPasswordRequest represents the request payload for creating an app password
+type CreateCalDAVPasswordRequest struct {
+	Name string `json:"name" validate:"required,max=100" example:"iPhone Reminders"`
+}
+
+// CalDAVPasswordResponse represents an app password of the CalDAV server
+type CalDAVPasswordResponse struct {
+	ID   uint   `json:"id" example:"1"`
+	Name string `json:"name" example:"iPhone Reminders"`
+	// Password is only returned when the app password is created
+	Password   string     `json:"password,omitempty" example:"dav_Zk3q9Wm2xR7pL4tY8vB1nC6h"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2024-01-01T12:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Calendar feed components. Calendar apps show events; task apps show to-dos.
+const (
+	CalendarComponentEvent = "vevent"
+	CalendarComponentTodo  = "vtodo"
+)
+
+// CalendarFeed stores the secret token of a user's calendar feed. Only a hash of
+// the token is kept, so a lost feed URL can only be replaced, not recovered.
+type CalendarFeed struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	TokenHash string    `gorm:"not null;size:64;uniqueIndex"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the CalendarFeed model
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
+
+// CalendarFeedResponse represents the state of a user's calendar feed
+type CalendarFeedResponse struct {
+	Enabled   bool       `json:"enabled" example:"true"`
+	CreatedAt *time.Time `json:"created_at,omitempty" example:"2024-01-01T12:00:00Z"`
+	// Token and URL are only returned when a new token is generated
+	Token string `json:"token,omitempty" example:"cal_3q2-7wD0Y5m1Jz8kQm6s1Hc0bXyVnR4tLp9aEfUgWiK"`
+	URL   string `json:"url,omitempty" example:"https://todo.example.com/api/v1/calendar/cal_3q2-7wD0Y5m1Jz8kQm6s1Hc0bXyVnR4tLp9aEfUgWiK.ics"`
+}
+
+// CalendarStamp summarises the todos of a calendar feed, so that clients polling
+// an unchanged feed can be answered without rendering it
+type CalendarStamp struct {
+	// Count is the number of todos in the feed
+	Count int64
+	// LastModified is the latest change to any of the user's todos, including deletions
+	LastModified time.Time
+}
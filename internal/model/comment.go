@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// TodoComment represents a comment in the discussion of a todo. Comments are
+// threaded one level deep: a reply belongs to the top-level comment it answers.
+type TodoComment struct {
+	ID     uint `json:"id" gorm:"primaryKey" example:"1"`
+	TodoID uint `json:"todo_id" gorm:"not null;index:idx_todo_comments_todo_id" example:"1"`
+	UserID uint `json:"user_id" gorm:"not null;index" example:"1"`
+	// ParentID is the top-level comment a reply belongs to
+	ParentID *uint `json:"parent_id,omitempty" gorm:"index" example:"1"`
+	// Body is the comment text in Markdown, stored as written
+	Body string `json:"body" gorm:"type:text;not null" example:"Fixed in the **staging** build, @jane@example.com can you check?"`
+	// Mentions holds the users mentioned in the body who have access to the
+	// todo. Todos are not shared yet, so this can only be the owner.
+	Mentions  []CommentMention `json:"mentions" gorm:"serializer:json;type:jsonb"`
+	Edited    bool             `json:"edited" gorm:"not null;default:false" example:"false"`
+	CreatedAt time.Time        `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt time.Time        `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
+	// Replies holds the replies to a top-level comment, oldest first
+	Replies []*TodoComment `json:"replies,omitempty" gorm:"-"`
+
+	// Purging the todo removes its comments, and deleting a top-level
+	// comment removes its replies
+	Todo   *Todo        `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	Parent *TodoComment `json:"-" gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for the TodoComment model
+func (TodoComment) TableName() string {
+	return "todo_comments"
+}
+
+// CommentMention represents a user mentioned in a comment with @email
+type CommentMention struct {
+	UserID uint   `json:"user_id" example:"2"`
+	Email  string `json:"email" example:"jane@example.com"`
+}
+
+// CreateCommentRequest represents the request payload for commenting on a todo
+type CreateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000" example:"Fixed in the **staging** build, @jane@example.com can you check?"`
+	// ParentID is the comment to reply to; replies to a reply join the thread of its top-level comment
+	ParentID *uint `json:"parent_id,omitempty" example:"1"`
+}
+
+// UpdateCommentRequest represents the request payload for editing a comment
+type UpdateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000" example:"Fixed in the **staging** build."`
+}
+
+// CommentPage represents a page of the top-level comments of a todo, oldest
+// first, each with all of its replies
+type CommentPage struct {
+	Comments []*TodoComment `json:"comments"`
+	Page     int            `json:"page" example:"1"`
+	PerPage  int            `json:"per_page" example:"20"`
+	// Total is the number of top-level comments of the todo
+	Total int64 `json:"total" example:"42"`
+}
@@ -0,0 +1,103 @@
+package model
+
+import "time"
+
+// Custom field types
+const (
+	CustomFieldText     = "text"
+	CustomFieldNumber   = "number"
+	CustomFieldDate     = "date"
+	CustomFieldSelect   = "select"
+	CustomFieldCheckbox = "checkbox"
+)
+
+// CustomFieldDateLayout is the format of the values of date custom fields
+const CustomFieldDateLayout = "2006-01-02"
+
+// CustomField represents a user-defined field of todos, such as story points
+// or a customer name. A field without a list applies to every todo of the
+// user; a field of a list only applies to the todos in that list.
+type CustomField struct {
+	ID        uint             `json:"id" gorm:"primaryKey" example:"1"`
+	UserID    uint             `json:"user_id" gorm:"not null;uniqueIndex:idx_custom_fields_user_key" example:"1"`
+	ListID    *uint            `json:"list_id,omitempty" gorm:"index" example:"1"`
+	Key       string           `json:"key" gorm:"not null;size:32;uniqueIndex:idx_custom_fields_user_key" example:"story_points"`
+	Name      string           `json:"name" gorm:"not null;size:64" example:"Story points"`
+	Type      string           `json:"type" gorm:"not null;size:16" example:"number"`
+	Required  bool             `json:"required" gorm:"not null;default:false" example:"false"`
+	Rules     CustomFieldRules `json:"rules" gorm:"serializer:json;type:jsonb;not null"`
+	CreatedAt time.Time        `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt time.Time        `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the CustomField model
+func (CustomField) TableName() string {
+	return "custom_fields"
+}
+
+// CustomFieldRules represents the validation rules of a custom field
+type CustomFieldRules struct {
+	// Min and Max bound the values of number fields and the length of text fields
+	Min *float64 `json:"min,omitempty" example:"1"`
+	Max *float64 `json:"max,omitempty" example:"13"`
+	// Format restricts the values of text fields to email addresses or URLs
+	Format string `json:"format,omitempty" validate:"omitempty,oneof=email url" example:"url"`
+	// Options are the values a select field accepts
+	Options []string `json:"options,omitempty" validate:"max=50,dive,required,max=64" example:"low,medium,high"`
+}
+
+// CustomFieldValues holds the custom field values of a todo by field key.
+// Text, date and select values are strings, number values are numbers and
+// checkbox values are booleans.
+type CustomFieldValues map[string]interface{}
+
+// CreateCustomFieldRequest represents the request payload for creating a custom field
+type CreateCustomFieldRequest struct {
+	Key  string `json:"key" validate:"required,max=32" example:"story_points"`
+	Name string `json:"name" validate:"required,min=1,max=64" example:"Story points"`
+	Type string `json:"type" validate:"required,oneof=text number date select checkbox" example:"number"`
+	// ListID limits the field to the todos of a list
+	ListID   *uint            `json:"list_id,omitempty" example:"1"`
+	Required bool             `json:"required" example:"false"`
+	Rules    CustomFieldRules `json:"rules"`
+}
+
+// UpdateCustomFieldRequest represents the request payload for updating a custom
+// field; the key, type and list of a field cannot change
+type UpdateCustomFieldRequest struct {
+	Name     *string           `json:"name,omitempty" validate:"omitempty,min=1,max=64" example:"Story points"`
+	Required *bool             `json:"required,omitempty" example:"true"`
+	Rules    *CustomFieldRules `json:"rules,omitempty"`
+}
+
+// CustomFieldListResponse represents the response for listing custom fields
+type CustomFieldListResponse struct {
+	Fields []*CustomField `json:"fields"`
+	Count  int            `json:"count" example:"2"`
+}
+
+// Custom field filter operators
+const (
+	CustomFieldEqual   = "eq"
+	CustomFieldAtLeast = "gte"
+	CustomFieldAtMost  = "lte"
+)
+
+// CustomFieldCondition restricts listed todos to those with a matching custom field value
+type CustomFieldCondition struct {
+	Key string
+	// Op is one of eq, gte and lte
+	Op    string
+	Value string
+	// Type and Parsed are set from the field definition before the condition is applied
+	Type   string
+	Parsed interface{}
+}
+
+// CustomFieldSort orders listed todos by a custom field value; todos without a value come last
+type CustomFieldSort struct {
+	Key  string
+	Desc bool
+	// Type is set from the field definition before the todos are sorted
+	Type string
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// TodoDependency records that a todo cannot start until another todo of the same user is completed
+type TodoDependency struct {
+	ID     uint `json:"id" gorm:"primaryKey" example:"1"`
+	UserID uint `json:"-" gorm:"not null;index"`
+	// TodoID is the blocked todo
+	TodoID uint `json:"todo_id" gorm:"not null;uniqueIndex:idx_todo_dependencies_pair" example:"2"`
+	// BlockedByID is the todo that has to be completed first
+	BlockedByID uint      `json:"blocked_by_id" gorm:"not null;uniqueIndex:idx_todo_dependencies_pair;index" example:"1"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+
+	// Purging either todo removes the dependency
+	Todo      *Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	BlockedBy *Todo `json:"-" gorm:"foreignKey:BlockedByID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for the TodoDependency model
+func (TodoDependency) TableName() string {
+	return "todo_dependencies"
+}
+
+// AddDependencyRequest represents the request payload for relating a todo to another.
+// Exactly one of blocked_by and blocks is given.
+type AddDependencyRequest struct {
+	// BlockedBy is a todo that has to be completed before this one
+	BlockedBy uint `json:"blocked_by,omitempty" validate:"required_without=Blocks,excluded_with=Blocks" example:"1"`
+	// Blocks is a todo that cannot start until this one is completed
+	Blocks uint `json:"blocks,omitempty" validate:"required_without=BlockedBy,excluded_with=BlockedBy" example:"3"`
+}
+
+// TodoDependenciesResponse represents the direct dependencies of a todo
+type TodoDependenciesResponse struct {
+	TodoID uint `json:"todo_id" example:"2"`
+	// Blocked reports whether a todo in blocked_by is not completed yet
+	Blocked   bool    `json:"blocked" example:"true"`
+	BlockedBy []*Todo `json:"blocked_by"`
+	Blocks    []*Todo `json:"blocks"`
+}
+
+// DependencyEdge represents a dependency in the dependency graph
+type DependencyEdge struct {
+	TodoID      uint `json:"todo_id" example:"2"`
+	BlockedByID uint `json:"blocked_by_id" example:"1"`
+}
+
+// DependencyGraphResponse represents the dependencies between the todos of a user
+type DependencyGraphResponse struct {
+	// Todos holds every todo that blocks or is blocked by another todo
+	Todos []*Todo          `json:"todos"`
+	Edges []DependencyEdge `json:"edges"`
+	// Order lists the IDs of the todos so that every todo comes after the todos blocking it
+	Order []uint `json:"order" example:"1,2"`
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Todo history actions
+const (
+	HistoryActionCreated    = "created"
+	HistoryActionUpdated    = "updated"
+	HistoryActionDeleted    = "deleted"
+	HistoryActionRestored   = "restored"
+	HistoryActionPurged     = "purged"
+	HistoryActionArchived   = "archived"
+	HistoryActionUnarchived = "unarchived"
+)
+
+// TodoHistory represents an append-only record of a change made to a todo
+type TodoHistory struct {
+	ID        uint         `json:"id" gorm:"primaryKey" example:"1"`
+	TodoID    uint         `json:"todo_id" gorm:"not null;index" example:"1"`
+	UserID    uint         `json:"user_id" gorm:"not null;index" example:"1"`
+	ActorID   uint         `json:"actor_id" gorm:"not null" example:"1"`
+	Action    string       `json:"action" gorm:"not null;size:32" example:"updated"`
+	Changes   FieldChanges `json:"changes" gorm:"type:jsonb"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime;index" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the TodoHistory model
+func (TodoHistory) TableName() string {
+	return "todo_history"
+}
+
+// FieldChange represents the change of a single todo field
+type FieldChange struct {
+	Field string      `json:"field" example:"title"`
+	Old   interface{} `json:"old" swaggertype:"string" example:"Old title"`
+	New   interface{} `json:"new" swaggertype:"string" example:"New title"`
+}
+
+// FieldChanges is a list of field changes stored as JSON
+type FieldChanges []FieldChange
+
+// Value implements the driver.Valuer interface
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements the sql.Scanner interface
+func (c *FieldChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for FieldChanges")
+	}
+	return json.Unmarshal(data, c)
+}
+
+// TodoHistoryResponse represents the response for a todo's change history
+type TodoHistoryResponse struct {
+	TodoID  uint           `json:"todo_id" example:"1"`
+	History []*TodoHistory `json:"history"`
+	Count   int            `json:"count" example:"3"`
+}
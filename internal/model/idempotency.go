@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a request sent with an
+// Idempotency-Key header so that retries can be answered with the same response
+type IdempotencyRecord struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	UserID      uint              `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Key         string            `json:"key" gorm:"not null;size:255;uniqueIndex:idx_idempotency_keys_user_key"`
+	Fingerprint string            `json:"fingerprint" gorm:"not null;size:64"`
+	StatusCode  int               `json:"status_code" gorm:"not null;default:0"`
+	Headers     map[string]string `json:"headers" gorm:"serializer:json;type:text"`
+	Body        []byte            `json:"-"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	RenewedAt   time.Time         `json:"renewed_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	ExpiresAt   time.Time         `json:"expires_at" gorm:"not null;index"`
+}
+
+// TableName specifies the table name for the IdempotencyRecord model
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// IsComplete reports whether the response of the request has been stored.
+// A record without a response belongs to a request that is still in progress.
+func (r *IdempotencyRecord) IsComplete() bool {
+	return r.StatusCode != 0
+}
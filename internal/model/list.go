@@ -0,0 +1,125 @@
+package model
+
+import "time"
+
+// TodoList represents a list of todos, such as a team board, with the workflow its todos move through
+type TodoList struct {
+	ID        uint      `json:"id" gorm:"primaryKey" example:"1"`
+	UserID    uint      `json:"user_id" gorm:"not null;index" example:"1"`
+	Name      string    `json:"name" gorm:"not null;size:100" example:"Sprint board"`
+	Workflow  Workflow  `json:"workflow" gorm:"serializer:json;type:jsonb;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the TodoList model
+func (TodoList) TableName() string {
+	return "todo_lists"
+}
+
+// Workflow represents the ordered states of the todos of a list and the
+// transitions allowed between them. New todos start in the first state, and a
+// todo is completed while it is in a terminal state.
+type Workflow struct {
+	States      []WorkflowState      `json:"states" validate:"required,min=2,max=20,dive"`
+	Transitions []WorkflowTransition `json:"transitions" validate:"max=400,dive"`
+}
+
+// WorkflowState represents a state of a workflow, such as a column of a board
+type WorkflowState struct {
+	Key      string `json:"key" validate:"required,max=32" example:"in_progress"`
+	Name     string `json:"name" validate:"required,min=1,max=64" example:"In Progress"`
+	Terminal bool   `json:"terminal" example:"false"`
+}
+
+// WorkflowTransition represents a move of a todo from one state to another that the workflow allows
+type WorkflowTransition struct {
+	From string `json:"from" validate:"required" example:"in_progress"`
+	To   string `json:"to" validate:"required" example:"review"`
+}
+
+// DefaultWorkflow returns the workflow of lists created without one, which
+// mirrors the completed flag of todos outside lists
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		States: []WorkflowState{
+			{Key: "todo", Name: "To Do"},
+			{Key: "done", Name: "Done", Terminal: true},
+		},
+		Transitions: []WorkflowTransition{
+			{From: "todo", To: "done"},
+			{From: "done", To: "todo"},
+		},
+	}
+}
+
+// InitialState returns the state new todos of the workflow start in
+func (w *Workflow) InitialState() string {
+	if len(w.States) == 0 {
+		return ""
+	}
+	return w.States[0].Key
+}
+
+// State returns the state with the given key, or nil if the workflow has none
+func (w *Workflow) State(key string) *WorkflowState {
+	for i := range w.States {
+		if w.States[i].Key == key {
+			return &w.States[i]
+		}
+	}
+	return nil
+}
+
+// Allows reports whether the workflow allows moving a todo from one state to another
+func (w *Workflow) Allows(from, to string) bool {
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateListRequest represents the request payload for creating a list
+type CreateListRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100" example:"Sprint board"`
+	// Workflow defaults to a To Do and a Done state
+	Workflow *Workflow `json:"workflow,omitempty"`
+}
+
+// UpdateListRequest represents the request payload for updating a list
+type UpdateListRequest struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=1,max=100" example:"Sprint board"`
+	// Workflow replaces the workflow; states that todos of the list are in must be kept
+	Workflow *Workflow `json:"workflow,omitempty"`
+}
+
+// ListListResponse represents the response for listing lists
+type ListListResponse struct {
+	Lists []*TodoList `json:"lists"`
+	Count int         `json:"count" example:"1"`
+}
+
+// MoveTodoRequest represents the request payload for moving a todo into, within or out of a list
+type MoveTodoRequest struct {
+	// ListID is the list to move the todo to; null or 0 takes the todo out of its list
+	ListID *uint `json:"list_id" example:"1"`
+	// State is the state to move the todo to. It defaults to the first state of
+	// a new list, or its first terminal state for completed todos.
+	State string `json:"state,omitempty" validate:"max=32" example:"review"`
+}
+
+// BoardColumn represents the todos of a list in one workflow state
+type BoardColumn struct {
+	WorkflowState
+	Todos []*Todo `json:"todos"`
+	Count int     `json:"count" example:"3"`
+}
+
+// BoardResponse represents the todos of a list grouped by workflow state, in workflow order
+type BoardResponse struct {
+	List    *TodoList     `json:"list"`
+	Columns []BoardColumn `json:"columns"`
+	Count   int           `json:"count" example:"12"`
+}
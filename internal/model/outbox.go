@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox aggregate types
+const (
+	OutboxAggregateTodo = "todo"
+	OutboxAggregateUser = "user"
+)
+
+// Domain event types of the user aggregate. Todo events are named after the
+// history action that caused them, e.g. todo.created or todo.purged.
+const (
+	OutboxEventUserRegistered           = "user.registered"
+	OutboxEventUserPasswordChanged      = "user.password_changed"
+	OutboxEventUserArchivePolicyChanged = "user.archive_policy_changed"
+)
+
+// OutboxMessage represents a domain event written in the transaction of the
+// change that caused it and published by the outbox relay afterwards
+type OutboxMessage struct {
+	ID            uint            `json:"id" gorm:"primaryKey" example:"1"`
+	AggregateType string          `json:"aggregate_type" gorm:"not null;size:32;index:idx_outbox_messages_aggregate,priority:1" example:"todo"`
+	AggregateID   uint            `json:"aggregate_id" gorm:"not null;index:idx_outbox_messages_aggregate,priority:2" example:"1"`
+	EventType     string          `json:"event_type" gorm:"not null;size:64" example:"todo.created"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:text;not null" swaggertype:"object"`
+	Attempts      int             `json:"attempts" gorm:"not null;default:0" example:"0"`
+	LastError     string          `json:"last_error,omitempty" gorm:"size:512"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"not null" example:"2024-01-01T12:00:00Z"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty" gorm:"index" example:"2024-01-01T12:00:00Z"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the OutboxMessage model
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+// TodoEventPayload is the payload of a todo domain event. Todo is omitted
+// when the todo was purged or changed by a bulk operation.
+type TodoEventPayload struct {
+	TodoID  uint         `json:"todo_id" example:"1"`
+	UserID  uint         `json:"user_id" example:"1"`
+	ActorID uint         `json:"actor_id" example:"1"`
+	Todo    *Todo        `json:"todo,omitempty"`
+	Changes FieldChanges `json:"changes"`
+}
+
+// UserEventPayload is the payload of a user domain event
+type UserEventPayload struct {
+	UserID uint      `json:"user_id" example:"1"`
+	User   *UserInfo `json:"user"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// QuickAddRequest represents a todo written as a single line of text
+type QuickAddRequest struct {
+	Text string `json:"text" validate:"required,max=500" example:"Call Bob tomorrow 3pm !high #sales"`
+	// Locale selects the words and date conventions of the text, e.g. en-GB; it
+	// defaults to the Accept-Language header and then to English
+	Locale string `json:"locale,omitempty" validate:"max=35" example:"en-US"`
+	// Timezone is the IANA time zone of dates and times in the text; it defaults to UTC
+	Timezone string `json:"timezone,omitempty" validate:"max=64" example:"America/New_York"`
+}
+
+// QuickAddMatch represents a part of the text that was interpreted
+type QuickAddMatch struct {
+	// Kind is one of date, time, priority or tag
+	Kind string `json:"kind" example:"time"`
+	Text string `json:"text" example:"3pm"`
+}
+
+// QuickAddInterpretation represents how the text of a quick-add request was read
+type QuickAddInterpretation struct {
+	// Title is the text left after removing the dates, times, priority and tags
+	Title string     `json:"title" example:"Call Bob"`
+	DueAt *time.Time `json:"due_at,omitempty" example:"2024-01-05T15:00:00Z"`
+	// AllDay marks due dates without a time, which are stored at midnight UTC
+	AllDay bool `json:"all_day" example:"false"`
+	// Priority is A (high), B (medium) or C (low)
+	Priority string          `json:"priority,omitempty" example:"A"`
+	Tags     []string        `json:"tags" example:"sales"`
+	Matches  []QuickAddMatch `json:"matches"`
+	Locale   string          `json:"locale" example:"en"`
+	Timezone string          `json:"timezone" example:"UTC"`
+}
+
+// QuickAddResponse represents a todo created from a line of text together with its interpretation
+type QuickAddResponse struct {
+	Todo           *Todo                   `json:"todo"`
+	Interpretation *QuickAddInterpretation `json:"interpretation"`
+}
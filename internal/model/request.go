@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // RegisterRequest represents the request payload for user registration
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email" example:"user@example.com"`
@@ -14,8 +16,14 @@ type LoginRequest struct {
 
 // CreateTodoRequest represents the request payload for creating a todo
 type CreateTodoRequest struct {
-	Title       string `json:"title" validate:"required,min=1,max=255" example:"Complete project"`
-	Description string `json:"description" validate:"max=1000" example:"Finish the todo API backend project"`
+	Title       string     `json:"title" validate:"required,min=1,max=255" example:"Complete project"`
+	Description string     `json:"description" validate:"max=1000" example:"Finish the todo API backend project"`
+	DueAt       *time.Time `json:"due_at,omitempty" example:"2024-01-05T17:00:00Z"`
+	// ListID adds the todo to a list, where it starts in State or the first state of the workflow
+	ListID *uint  `json:"list_id,omitempty" example:"1"`
+	State  string `json:"state,omitempty" validate:"max=32" example:"backlog"`
+	// CustomFields sets the values of the custom fields of the todo by field key
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty" swaggertype:"object"`
 }
 
 // UpdateTodoRequest represents the request payload for updating a todo
@@ -23,4 +31,78 @@ type UpdateTodoRequest struct {
 	Title       *string `json:"title,omitempty" validate:"omitempty,min=1,max=255" example:"Updated task title"`
 	Description *string `json:"description,omitempty" validate:"omitempty,max=1000" example:"Updated description"`
 	Completed   *bool   `json:"completed,omitempty" example:"true"`
-}
\ No newline at end of file
+	// DueAt sets the due date; use PUT or PATCH to remove it
+	DueAt *time.Time `json:"due_at,omitempty" example:"2024-01-05T17:00:00Z"`
+}
+
+// ReplaceTodoRequest represents the full representation of a todo's editable
+// fields. PUT replaces a todo with it, resetting omitted fields except State
+// and CustomFields, and PATCH documents are applied to it.
+type ReplaceTodoRequest struct {
+	Title       string     `json:"title" validate:"required,min=1,max=255" example:"Updated task title"`
+	Description string     `json:"description" validate:"max=1000" example:"Updated description"`
+	Completed   bool       `json:"completed" example:"true"`
+	DueAt       *time.Time `json:"due_at" example:"2024-01-05T17:00:00Z"`
+	// State moves a todo in a list to another workflow state, which decides
+	// whether it is completed. Without a state change, changing completed moves
+	// the todo to the first state of the workflow that matches it.
+	State string `json:"state,omitempty" validate:"max=32" example:"review"`
+	// CustomFields replaces the custom field values of the todo; without it
+	// the values are kept
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty" swaggertype:"object"`
+}
+
+// Media types accepted by PATCH /todos/{id}
+const (
+	PatchTypeMerge = "application/merge-patch+json"
+	PatchTypeJSON  = "application/json-patch+json"
+)
+
+// ArchiveCompletedRequest represents the request payload for archiving completed todos in bulk
+type ArchiveCompletedRequest struct {
+	OlderThanDays int `json:"older_than_days" validate:"min=0,max=3650" example:"30"`
+}
+
+// ArchivePolicyRequest represents the request payload for updating the auto-archive policy
+type ArchivePolicyRequest struct {
+	AutoArchiveDays int `json:"auto_archive_days" validate:"min=0,max=3650" example:"14"`
+}
+
+// ChangePasswordRequest represents the request payload for changing the password of the authenticated user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"password123"`
+	NewPassword     string `json:"new_password" validate:"required,min=8" example:"newpassword456"`
+}
+
+// SearchTodosRequest represents the query parameters for searching todos
+type SearchTodosRequest struct {
+	Query    string `json:"q" validate:"required,max=256" example:"\"quarterly report\" bob*"`
+	Archived string `json:"archived" validate:"omitempty,oneof=false true all" example:"false"`
+	Limit    int    `json:"limit" validate:"min=0,max=100" example:"20"`
+}
+
+// Bulk operation types
+const (
+	BulkOpCreate   = "create"
+	BulkOpUpdate   = "update"
+	BulkOpDelete   = "delete"
+	BulkOpComplete = "complete"
+)
+
+// BulkOperation represents a single operation of a bulk request. Update, delete and
+// complete operations target the todo in id or every todo in ids.
+type BulkOperation struct {
+	Op          string  `json:"op" validate:"required,oneof=create update delete complete" example:"complete"`
+	ID          uint    `json:"id,omitempty" example:"1"`
+	IDs         []uint  `json:"ids,omitempty" validate:"max=100" example:"1,2,3"`
+	Title       *string `json:"title,omitempty" example:"Complete project"`
+	Description *string `json:"description,omitempty" example:"Finish the todo API backend project"`
+	Completed   *bool   `json:"completed,omitempty" example:"true"`
+}
+
+// BulkRequest represents the request payload for running many todo operations at once
+type BulkRequest struct {
+	// Atomic rolls back every operation if any of them fails
+	Atomic     bool            `json:"atomic" example:"false"`
+	Operations []BulkOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
@@ -30,4 +30,36 @@ type HealthResponse struct {
 	Status   string `json:"status" example:"ok"`
 	Database string `json:"database" example:"connected"`
 	Time     string `json:"time" example:"2024-01-01T12:00:00Z"`
-}
\ No newline at end of file
+}
+
+// ArchiveResponse represents the response for bulk archive operations
+type ArchiveResponse struct {
+	Archived int64 `json:"archived" example:"12"`
+}
+
+// ArchivePolicyResponse represents the auto-archive policy of a user
+type ArchivePolicyResponse struct {
+	AutoArchiveDays int  `json:"auto_archive_days" example:"14"`
+	Enabled         bool `json:"enabled" example:"true"`
+}
+
+// BulkItemResult represents the outcome of a single todo in a bulk request
+type BulkItemResult struct {
+	// Index is the position of the operation in the request
+	Index   int    `json:"index" example:"0"`
+	Op      string `json:"op" example:"complete"`
+	ID      uint   `json:"id,omitempty" example:"1"`
+	Status  int    `json:"status" example:"200"`
+	Error   string `json:"error,omitempty" example:"not_found"`
+	Message string `json:"message,omitempty" example:"Todo not found"`
+	Todo    *Todo  `json:"todo,omitempty"`
+}
+
+// BulkResponse represents the response for a bulk request
+type BulkResponse struct {
+	Atomic    bool              `json:"atomic" example:"false"`
+	Committed bool              `json:"committed" example:"true"`
+	Succeeded int               `json:"succeeded" example:"49"`
+	Failed    int               `json:"failed" example:"1"`
+	Results   []*BulkItemResult `json:"results"`
+}
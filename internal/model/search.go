@@ -0,0 +1,33 @@
+package model
+
+// SearchTerm is a single word or quoted phrase of a search query
+type SearchTerm struct {
+	// Words holds the lowercased words of the term; more than one word makes a phrase
+	Words []string
+	// Prefix makes the last word match any word starting with it
+	Prefix bool
+}
+
+// TodoSearchQuery represents a parsed full-text search over todos
+type TodoSearchQuery struct {
+	Terms    []SearchTerm
+	Archived string
+	Limit    int
+}
+
+// TodoSearchResult represents a todo matching a search together with its ranking
+type TodoSearchResult struct {
+	Todo *Todo `json:"todo"`
+	// Rank orders results by relevance; higher is more relevant
+	Rank float64 `json:"rank" example:"0.6079"`
+	// TitleHighlight and Snippet are HTML-escaped with matches wrapped in <mark> tags
+	TitleHighlight string `json:"title_highlight" example:"Call <mark>Bob</mark>"`
+	Snippet        string `json:"snippet" example:"Ask <mark>Bob</mark> about the quarterly report"`
+}
+
+// TodoSearchResponse represents the response for a todo search
+type TodoSearchResponse struct {
+	Query   string              `json:"query" example:"bob report*"`
+	Results []*TodoSearchResult `json:"results"`
+	Count   int                 `json:"count" example:"1"`
+}
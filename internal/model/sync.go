@@ -0,0 +1,87 @@
+package model
+
+import "time"
+
+// Sync mutation types
+const (
+	SyncOpCreate = "create"
+	SyncOpUpdate = "update"
+	SyncOpDelete = "delete"
+)
+
+// Sync mutation statuses
+const (
+	SyncStatusApplied  = "applied"
+	SyncStatusConflict = "conflict"
+	SyncStatusFailed   = "failed"
+)
+
+// Sync conflict reasons
+const (
+	// SyncConflictVersion means the todo changed on the server since base_version
+	SyncConflictVersion = "version_mismatch"
+	// SyncConflictServerNewer means the todo changed on the server after client_updated_at
+	SyncConflictServerNewer = "server_newer"
+	// SyncConflictDeleted means the todo no longer exists on the server
+	SyncConflictDeleted = "deleted"
+)
+
+// SyncTombstone identifies a todo that was deleted since the previous sync
+type SyncTombstone struct {
+	ID        uint      `json:"id" example:"1"`
+	DeletedAt time.Time `json:"deleted_at" example:"2024-01-02T12:00:00Z"`
+}
+
+// SyncResponse represents the changes to a user's todos since a sync token
+type SyncResponse struct {
+	// Token is passed as since on the next sync to receive only later changes
+	Token string `json:"token" example:"djE6MTcwNDExMDQwMDAwMDAwMDAwMA"`
+	// Reset tells the client to replace its local copy with todos instead of merging
+	Reset   bool             `json:"reset" example:"false"`
+	Todos   []*Todo          `json:"todos"`
+	Deleted []*SyncTombstone `json:"deleted"`
+}
+
+// SyncMutation represents a change a client made while offline. Updates and
+// deletes conflict if the todo changed on the server since base_version or,
+// when no base version is known, after client_updated_at.
+type SyncMutation struct {
+	Op string `json:"op" validate:"required,oneof=create update delete" example:"update"`
+	// ClientID is echoed back so clients can match created todos to local records
+	ClientID        string    `json:"client_id,omitempty" validate:"max=255" example:"local-42"`
+	ID              uint      `json:"id,omitempty" example:"1"`
+	BaseVersion     uint      `json:"base_version,omitempty" example:"3"`
+	ClientUpdatedAt time.Time `json:"client_updated_at" validate:"required" example:"2024-01-01T12:00:00Z"`
+	Title           *string   `json:"title,omitempty" example:"Complete project"`
+	Description     *string   `json:"description,omitempty" example:"Finish the todo API backend project"`
+	Completed       *bool     `json:"completed,omitempty" example:"true"`
+}
+
+// SyncPushRequest represents a batch of offline client mutations
+type SyncPushRequest struct {
+	Mutations []SyncMutation `json:"mutations" validate:"required,min=1,max=100,dive"`
+}
+
+// SyncMutationResult represents the outcome of a single client mutation
+type SyncMutationResult struct {
+	// Index is the position of the mutation in the request
+	Index    int    `json:"index" example:"0"`
+	Op       string `json:"op" example:"update"`
+	ClientID string `json:"client_id,omitempty" example:"local-42"`
+	ID       uint   `json:"id,omitempty" example:"1"`
+	Status   string `json:"status" example:"conflict"`
+	// Reason explains a conflict
+	Reason  string `json:"reason,omitempty" example:"version_mismatch"`
+	Error   string `json:"error,omitempty" example:"validation_failed"`
+	Message string `json:"message,omitempty" example:"Title must be at most 255 characters long"`
+	// Todo is the todo after an applied mutation, or the server's copy on conflict
+	Todo *Todo `json:"todo,omitempty"`
+}
+
+// SyncPushResponse represents the response for a batch of client mutations
+type SyncPushResponse struct {
+	Applied   int                   `json:"applied" example:"9"`
+	Conflicts int                   `json:"conflicts" example:"1"`
+	Failed    int                   `json:"failed" example:"0"`
+	Results   []*SyncMutationResult `json:"results"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// TodoTemplate represents a user's reusable tree of todos, such as an onboarding checklist
+type TodoTemplate struct {
+	ID          uint           `json:"id" gorm:"primaryKey" example:"1"`
+	UserID      uint           `json:"user_id" gorm:"not null;index" example:"1"`
+	Name        string         `json:"name" gorm:"not null;size:255" example:"Onboarding"`
+	Description string         `json:"description" gorm:"size:1000" example:"Checklist for every new hire"`
+	Items       []TemplateItem `json:"items" gorm:"serializer:json;type:jsonb;not null"`
+	// Variables lists the placeholders used by the items, e.g. name for {{name}}
+	Variables []string  `json:"variables" gorm:"-" example:"name"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the TodoTemplate model
+func (TodoTemplate) TableName() string {
+	return "todo_templates"
+}
+
+// TemplateItem represents a todo of a template together with its subtasks.
+// Titles and descriptions may contain placeholders such as {{name}}.
+type TemplateItem struct {
+	Title       string `json:"title" validate:"required,min=1,max=255" example:"Set up a laptop for {{name}}"`
+	Description string `json:"description,omitempty" validate:"max=1000" example:"Ask IT for a laptop with the standard image"`
+	// DueOffsetDays is the due date in days after the start date of an instantiation;
+	// items without it have no due date
+	DueOffsetDays *int           `json:"due_offset_days,omitempty" validate:"omitempty,min=-365,max=3650" example:"3"`
+	Children      []TemplateItem `json:"children,omitempty" validate:"max=100,dive"`
+}
+
+// CreateTemplateRequest represents the request payload for creating a template
+type CreateTemplateRequest struct {
+	Name        string         `json:"name" validate:"required,min=1,max=255" example:"Onboarding"`
+	Description string         `json:"description" validate:"max=1000" example:"Checklist for every new hire"`
+	Items       []TemplateItem `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+// UpdateTemplateRequest represents the request payload for replacing a template
+type UpdateTemplateRequest struct {
+	Name        string         `json:"name" validate:"required,min=1,max=255" example:"Onboarding"`
+	Description string         `json:"description" validate:"max=1000" example:"Checklist for every new hire"`
+	Items       []TemplateItem `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+// TemplateListResponse represents the response for listing templates
+type TemplateListResponse struct {
+	Templates []*TodoTemplate `json:"templates"`
+	Count     int             `json:"count" example:"1"`
+}
+
+// InstantiateTemplateRequest represents the request payload for creating the todos of a template
+type InstantiateTemplateRequest struct {
+	// Variables holds the values of the placeholders, e.g. {"name": "Alice"} for {{name}}
+	Variables map[string]string `json:"variables" validate:"max=50,dive,keys,min=1,max=64,endkeys,max=255"`
+	// StartDate is the date due offsets count from; it defaults to today (UTC)
+	StartDate string `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02" example:"2024-01-08"`
+}
+
+// InstantiatedTodo represents a todo created from a template item
+type InstantiatedTodo struct {
+	Todo *Todo `json:"todo"`
+	// ParentID is the todo created from the parent item, which is blocked by
+	// this todo; top-level items have none
+	ParentID *uint `json:"parent_id,omitempty" example:"1"`
+	Depth    int   `json:"depth" example:"0"`
+}
+
+// InstantiateTemplateResponse represents the todos created from a template, parents before their children
+type InstantiateTemplateResponse struct {
+	TemplateID uint                `json:"template_id" example:"1"`
+	Todos      []*InstantiatedTodo `json:"todos"`
+	Count      int                 `json:"count" example:"20"`
+}
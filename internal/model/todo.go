@@ -2,21 +2,59 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Todo represents a todo item in the system
 type Todo struct {
-	ID          uint      `json:"id" gorm:"primaryKey" example:"1"`
-	Title       string    `json:"title" gorm:"not null;size:255" example:"Complete project"`
-	Description string    `json:"description" gorm:"size:1000" example:"Finish the todo API backend project"`
-	Completed   bool      `json:"completed" gorm:"default:false" example:"false"`
-	UserID      uint      `json:"user_id" gorm:"not null;index" example:"1"`
-	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
-	User        User      `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	ID          uint       `json:"id" gorm:"primaryKey" example:"1"`
+	Title       string     `json:"title" gorm:"not null;size:255" example:"Complete project"`
+	Description string     `json:"description" gorm:"size:1000" example:"Finish the todo API backend project"`
+	Completed   bool       `json:"completed" gorm:"default:false" example:"false"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" example:"2024-01-01T15:00:00Z"`
+	DueAt       *time.Time `json:"due_at,omitempty" gorm:"index" example:"2024-01-05T17:00:00Z"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" gorm:"index" example:"2024-02-01T12:00:00Z"`
+	// ListID is the list of the todo; todos outside lists have no workflow state
+	ListID *uint `json:"list_id,omitempty" gorm:"index" example:"1"`
+	// State is the workflow state of a todo in a list; completed follows whether the state is terminal
+	State string `json:"state,omitempty" gorm:"size:32;not null;default:''" example:"in_progress"`
+	// CustomFields holds the values of the custom fields of the todo by field key
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty" gorm:"serializer:json;type:jsonb" swaggertype:"object"`
+	Version      uint              `json:"version" gorm:"not null;default:1" example:"1"`
+	// Blocked reports whether a todo this todo depends on is not completed yet
+	Blocked   bool           `json:"blocked" gorm:"-" example:"false"`
+	UserID    uint           `json:"user_id" gorm:"not null;index" example:"1"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string" example:"2024-01-02T12:00:00Z"`
+	User      User           `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
 
 // TableName specifies the table name for the Todo model
 func (Todo) TableName() string {
 	return "todos"
-}
\ No newline at end of file
+}
+
+// IsArchived reports whether the todo has been archived
+func (t *Todo) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+// Archive filter values for listing todos
+const (
+	ArchivedExclude = "false"
+	ArchivedOnly    = "true"
+	ArchivedInclude = "all"
+)
+
+// TodoFilter represents the options for listing todos
+type TodoFilter struct {
+	// Archived selects archived todos: "false" (default) excludes them,
+	// "true" returns only archived todos and "all" returns both
+	Archived string
+	// CustomFields restricts the todos to those matching every condition on their custom field values
+	CustomFields []CustomFieldCondition
+	// SortField orders the todos by a custom field value instead of newest first
+	SortField *CustomFieldSort
+}
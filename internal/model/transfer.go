@@ -0,0 +1,98 @@
+package model
+
+import "time"
+
+// Todo export and import file formats
+const (
+	TransferFormatCSV    = "csv"
+	TransferFormatJSON   = "json"
+	TransferFormatNDJSON = "ndjson"
+	// TransferFormatICS exports todos as iCalendar VTODO components; it cannot be imported
+	TransferFormatICS = "ics"
+	// TransferFormatTodoTxt is the todo.txt format, one todo per line
+	TransferFormatTodoTxt = "txt"
+	// TransferFormatMarkdown is a GitHub-flavored Markdown task list
+	TransferFormatMarkdown = "md"
+)
+
+// TransferCSVHeader lists the columns of a CSV export. Imports read the title,
+// description, completed, due_at, completed_at and created_at columns by name
+// and ignore the others.
+var TransferCSVHeader = []string{"id", "title", "description", "completed", "due_at", "completed_at", "archived_at", "created_at", "updated_at"}
+
+// TodoExport represents a single todo in an export file
+type TodoExport struct {
+	ID          uint       `json:"id" example:"1"`
+	Title       string     `json:"title" example:"Complete project"`
+	Description string     `json:"description" example:"Finish the todo API backend project"`
+	Completed   bool       `json:"completed" example:"false"`
+	DueAt       *time.Time `json:"due_at,omitempty" example:"2024-01-05T17:00:00Z"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" example:"2024-01-01T15:00:00Z"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" example:"2024-02-01T12:00:00Z"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// NewTodoExport converts a todo to its export representation
+func NewTodoExport(todo *Todo) *TodoExport {
+	return &TodoExport{
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		DueAt:       todo.DueAt,
+		CompletedAt: todo.CompletedAt,
+		ArchivedAt:  todo.ArchivedAt,
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	}
+}
+
+// ImportRow represents a single todo read from an import file
+type ImportRow struct {
+	// Row locates the todo in the file: its spreadsheet row in a CSV file, where the
+	// header is row 1, its 1-based index in a JSON array or its line number in
+	// other formats
+	Row         int        `json:"-"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	DueAt       *time.Time `json:"due_at"`
+	// CompletedAt and CreatedAt keep the dates of exported todos; a completed todo
+	// without a completion date is completed at the time of the import
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   *time.Time `json:"created_at"`
+	// ParseError describes why the row could not be read; such rows are reported as failed
+	ParseError string `json:"-"`
+}
+
+// ImportRequest represents a parsed import file and its options
+type ImportRequest struct {
+	Rows []ImportRow
+	// DryRun validates every row without creating any todos
+	DryRun bool
+	// Atomic imports nothing if any row is invalid
+	Atomic bool
+}
+
+// ImportRowError describes why a row of an import file was rejected
+type ImportRowError struct {
+	Row     int               `json:"row" example:"3"`
+	Error   string            `json:"error" example:"validation_failed"`
+	Message string            `json:"message" example:"Title is required"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// ImportResponse represents the outcome of an import
+type ImportResponse struct {
+	DryRun bool `json:"dry_run" example:"false"`
+	Atomic bool `json:"atomic" example:"false"`
+	// Committed is false when nothing was written, either because of a dry run or
+	// because an atomic import contained invalid rows
+	Committed bool `json:"committed" example:"true"`
+	Total     int  `json:"total" example:"3"`
+	// Imported is the number of todos created, or that would be created by a dry run
+	Imported int               `json:"imported" example:"2"`
+	Failed   int               `json:"failed" example:"1"`
+	Errors   []*ImportRowError `json:"errors"`
+}
@@ -6,12 +6,13 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey" example:"1"`
-	Email     string    `json:"email" gorm:"uniqueIndex;not null;size:255" example:"user@example.com"`
-	Password  string    `json:"-" gorm:"not null;size:255"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
-	Todos     []Todo    `json:"todos,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	ID              uint      `json:"id" gorm:"primaryKey" example:"1"`
+	Email           string    `json:"email" gorm:"uniqueIndex;not null;size:255" example:"user@example.com"`
+	Password        string    `json:"-" gorm:"not null;size:255"`
+	AutoArchiveDays int       `json:"-" gorm:"not null;default:0"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
+	Todos           []Todo    `json:"todos,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
 
 // TableName specifies the table name for the User model
@@ -35,4 +36,4 @@ func (u *User) ToUserInfo() *UserInfo {
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types. todo.completed is sent in addition to todo.updated
+// when a change marks a todo as completed; comment.mentioned is sent to the
+// webhooks of a user mentioned in a comment.
+const (
+	WebhookEventTodoCreated      = "todo.created"
+	WebhookEventTodoUpdated      = "todo.updated"
+	WebhookEventTodoCompleted    = "todo.completed"
+	WebhookEventTodoDeleted      = "todo.deleted"
+	WebhookEventCommentMentioned = "comment.mentioned"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook represents a user's subscription to todo events delivered by HTTP POST
+type Webhook struct {
+	ID     uint     `json:"id" gorm:"primaryKey" example:"1"`
+	UserID uint     `json:"user_id" gorm:"not null;index" example:"1"`
+	URL    string   `json:"url" gorm:"not null;size:2048" example:"https://bot.example.com/hooks/todos"`
+	Events []string `json:"events" gorm:"serializer:json;type:jsonb;not null" example:"todo.completed"`
+	// Secret signs the payloads; it is only returned when the webhook is created
+	Secret         string     `json:"-" gorm:"not null;size:64"`
+	Active         bool       `json:"active" gorm:"not null;default:true" example:"true"`
+	FailureCount   int        `json:"failure_count" gorm:"not null;default:0" example:"0"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty" example:"2024-01-01T12:00:00Z"`
+	DisabledReason string     `json:"disabled_reason,omitempty" gorm:"size:255" example:"20 consecutive failed delivery attempts"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// WebhookDelivery represents a queued webhook request and the outcome of its latest attempt
+type WebhookDelivery struct {
+	ID            uint            `json:"id" gorm:"primaryKey" example:"1"`
+	WebhookID     uint            `json:"webhook_id" gorm:"not null;index" example:"1"`
+	EventID       string          `json:"event_id" gorm:"not null;size:32" example:"5f0c6a1e9b2d4c7a8e3f1b0d2c4a6e8f"`
+	EventType     string          `json:"event_type" gorm:"not null;size:64" example:"todo.completed"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:text;not null" swaggertype:"object"`
+	Status        string          `json:"status" gorm:"not null;size:16;default:pending" example:"succeeded"`
+	Attempts      int             `json:"attempts" gorm:"not null;default:0" example:"1"`
+	ResponseCode  int             `json:"response_code,omitempty" example:"200"`
+	LastError     string          `json:"last_error,omitempty" gorm:"size:512" example:"unexpected status 503"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"not null;index" example:"2024-01-01T12:00:00Z"`
+	LastAttemptAt *time.Time      `json:"last_attempt_at,omitempty" example:"2024-01-01T12:00:00Z"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime;index" example:"2024-01-01T12:00:00Z"`
+}
+
+// TableName specifies the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook
+type WebhookPayload struct {
+	ID         string       `json:"id" example:"5f0c6a1e9b2d4c7a8e3f1b0d2c4a6e8f"`
+	Type       string       `json:"type" example:"todo.completed"`
+	TodoID     uint         `json:"todo_id" example:"1"`
+	Todo       *Todo        `json:"todo,omitempty"`
+	Comment    *TodoComment `json:"comment,omitempty"`
+	OccurredAt time.Time    `json:"occurred_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// CreateWebhookRequest represents the request payload for registering a webhook
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2048" example:"https://bot.example.com/hooks/todos"`
+	Events []string `json:"events" validate:"required,min=1,max=5,dive,oneof=todo.created todo.updated todo.completed todo.deleted comment.mentioned" example:"todo.completed"`
+}
+
+// UpdateWebhookRequest represents the request payload for replacing a webhook.
+// Activating a disabled webhook resets its failure count.
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2048" example:"https://bot.example.com/hooks/todos"`
+	Events []string `json:"events" validate:"required,min=1,max=5,dive,oneof=todo.created todo.updated todo.completed todo.deleted comment.mentioned" example:"todo.completed"`
+	Active bool     `json:"active" example:"true"`
+}
+
+// CreateWebhookResponse represents a newly registered webhook together with its signing secret
+type CreateWebhookResponse struct {
+	*Webhook
+	Secret string `json:"secret" example:"whsec_3b1f0c9e8d7a6b5c4d3e2f1a0b9c8d7e"`
+}
+
+// WebhookListResponse represents the response for listing webhooks
+type WebhookListResponse struct {
+	Webhooks []*Webhook `json:"webhooks"`
+	Count    int        `json:"count" example:"1"`
+}
+
+// WebhookDeliveriesResponse represents the delivery log of a webhook
+type WebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	Count      int                `json:"count" example:"20"`
+}
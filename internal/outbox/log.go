@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"todo-api-backend/internal/model"
+)
+
+// LogPublisher writes domain events to a logger as JSON, which is useful in
+// development and as a fallback when no message broker is available
+type LogPublisher struct {
+	logger *log.Logger
+}
+
+// NewLogPublisher creates a publisher writing to logger, or to the standard logger if nil
+func NewLogPublisher(logger *log.Logger) *LogPublisher {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogPublisher{logger: logger}
+}
+
+// Publish logs the message envelope
+func (p *LogPublisher) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	data, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	p.logger.Printf("Domain event: %s", data)
+	return nil
+}
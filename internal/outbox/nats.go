@@ -0,0 +1,206 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-api-backend/internal/model"
+)
+
+// natsTimeout bounds connecting to the server and every publish that has no earlier deadline
+const natsTimeout = 10 * time.Second
+
+// NATSPublisher publishes domain events to a NATS server using the core text
+// protocol, without depending on a client library. Every event is published
+// to "<prefix>.<event type>", e.g. "todoapp.todo.created", and is
+// acknowledged once the server answered the PING that follows it, which
+// NATS does only after processing the publish.
+type NATSPublisher struct {
+	address string
+	prefix  string
+	connect map[string]interface{}
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher creates a publisher for the server at rawURL, e.g.
+// nats://localhost:4222. Credentials in the URL are sent as user and
+// password, or as a token if only a user is given. The connection is
+// established on the first publish and re-established after errors.
+func NewNATSPublisher(rawURL, prefix string) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tcp" {
+		return nil, fmt.Errorf("unsupported NATS URL scheme %q", u.Scheme)
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	connect := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "todo-api-outbox",
+		"lang":     "go",
+		"version":  "1.0.0",
+	}
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			connect["user"] = u.User.Username()
+			connect["pass"] = password
+		} else {
+			connect["auth_token"] = u.User.Username()
+		}
+	}
+
+	return &NATSPublisher{
+		address: address,
+		prefix:  strings.TrimSuffix(prefix, "."),
+		connect: connect,
+	}, nil
+}
+
+// Subject returns the subject a message is published to
+func (p *NATSPublisher) Subject(message *model.OutboxMessage) string {
+	if p.prefix == "" {
+		return message.EventType
+	}
+	return p.prefix + "." + message.EventType
+}
+
+// Publish publishes the message envelope and waits for the server to process it
+func (p *NATSPublisher) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	body, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, p.Subject(message), body); err != nil {
+		// Start over with a new connection on the next publish
+		p.closeConn()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection to the server, if any
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closeConn()
+	return nil
+}
+
+// publish sends a PUB followed by a PING and waits for the PONG
+func (p *NATSPublisher) publish(ctx context.Context, subject string, body []byte) error {
+	if p.conn == nil {
+		if err := p.dial(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(natsTimeout)
+	}
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	frame := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body)
+	if _, err := p.conn.Write([]byte(frame)); err != nil {
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return p.awaitPong()
+}
+
+// dial connects to the server and performs the CONNECT handshake
+func (p *NATSPublisher) dial(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+
+	if err := conn.SetDeadline(time.Now().Add(natsTimeout)); err != nil {
+		return err
+	}
+
+	// The server greets every client with an INFO line
+	line, err := p.readLine()
+	if err != nil {
+		return fmt.Errorf("failed to read NATS server info: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected NATS greeting %q", line)
+	}
+
+	options, err := json.Marshal(p.connect)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", options); err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return p.awaitPong()
+}
+
+// awaitPong reads server messages until the PONG answering the last PING
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return fmt.Errorf("failed to read NATS response: %w", err)
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("NATS error: " + strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		}
+		// +OK and INFO updates need no answer
+	}
+}
+
+// readLine reads a single CRLF terminated protocol line
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// closeConn closes the current connection, if any
+func (p *NATSPublisher) closeConn() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+		p.reader = nil
+	}
+}
@@ -0,0 +1,44 @@
+// Package outbox provides the publishers that the outbox relay hands domain
+// events to once they have been committed together with the change that
+// caused them.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"todo-api-backend/internal/model"
+)
+
+// EventPublisher publishes domain events taken from the outbox. Delivery is
+// at least once: a message whose publication fails, or whose success could
+// not be recorded, is published again, so consumers should deduplicate by
+// the envelope ID.
+type EventPublisher interface {
+	// Publish publishes a single message, returning once it was accepted
+	Publish(ctx context.Context, message *model.OutboxMessage) error
+}
+
+// Envelope is the wire format of a published domain event. IDs increase
+// with every event, and the events of an aggregate are published in ID order.
+type Envelope struct {
+	ID            uint            `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope wraps an outbox message for publishing
+func NewEnvelope(message *model.OutboxMessage) Envelope {
+	return Envelope{
+		ID:            message.ID,
+		Type:          message.EventType,
+		AggregateType: message.AggregateType,
+		AggregateID:   message.AggregateID,
+		OccurredAt:    message.CreatedAt.UTC(),
+		Payload:       message.Payload,
+	}
+}
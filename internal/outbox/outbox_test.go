@@ -0,0 +1,240 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/model"
+)
+
+func testMessage() *model.OutboxMessage {
+	return &model.OutboxMessage{
+		ID:            42,
+		AggregateType: model.OutboxAggregateTodo,
+		AggregateID:   7,
+		EventType:     "todo.created",
+		Payload:       json.RawMessage(`{"todo_id":7}`),
+		CreatedAt:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNewEnvelope(t *testing.T) {
+	envelope := NewEnvelope(testMessage())
+
+	data, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 42,
+		"type": "todo.created",
+		"aggregate_type": "todo",
+		"aggregate_id": 7,
+		"occurred_at": "2024-01-01T12:00:00Z",
+		"payload": {"todo_id": 7}
+	}`, string(data))
+}
+
+func TestLogPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewLogPublisher(log.New(&buf, "", 0))
+
+	require.NoError(t, publisher.Publish(context.Background(), testMessage()))
+	assert.Contains(t, buf.String(), `"type":"todo.created"`)
+	assert.Contains(t, buf.String(), `"payload":{"todo_id":7}`)
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	var received Envelope
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "42", r.Header.Get(EventIDHeader))
+		assert.Equal(t, "todo.created", r.Header.Get(EventTypeHeader))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewHTTPPublisher(server.URL).Publish(context.Background(), testMessage()))
+	assert.Equal(t, uint(42), received.ID)
+	assert.Equal(t, uint(7), received.AggregateID)
+}
+
+func TestHTTPPublisher_RejectedEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewHTTPPublisher(server.URL).Publish(context.Background(), testMessage())
+	assert.EqualError(t, err, "unexpected status 503")
+}
+
+// fakeNATSServer is a minimal NATS server that records the CONNECT options
+// and published messages of its clients and answers PINGs
+type fakeNATSServer struct {
+	listener net.Listener
+	connects chan map[string]interface{}
+	messages chan [2]string
+	reject   atomic.Bool
+}
+
+func newFakeNATSServer(t *testing.T) *fakeNATSServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeNATSServer{
+		listener: listener,
+		connects: make(chan map[string]interface{}, 10),
+		messages: make(chan [2]string, 10),
+	}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeNATSServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *fakeNATSServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeNATSServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	io.WriteString(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			var options map[string]interface{}
+			json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &options)
+			s.connects <- options
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			if s.reject.Load() {
+				io.WriteString(conn, "-ERR 'Permissions Violation for Publish'\r\n")
+				return
+			}
+			s.messages <- [2]string{fields[1], string(payload[:size])}
+		case line == "PING":
+			io.WriteString(conn, "PONG\r\n")
+		}
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	server := newFakeNATSServer(t)
+
+	publisher, err := NewNATSPublisher(server.url(), "todoapp.")
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	ctx := context.Background()
+	require.NoError(t, publisher.Publish(ctx, testMessage()))
+	require.NoError(t, publisher.Publish(ctx, testMessage()))
+
+	// Both messages are published over a single connection
+	options := <-server.connects
+	assert.Equal(t, "todo-api-outbox", options["name"])
+	assert.Equal(t, false, options["verbose"])
+	assert.Len(t, server.connects, 0)
+
+	message := <-server.messages
+	assert.Equal(t, "todoapp.todo.created", message[0])
+	var envelope Envelope
+	require.NoError(t, json.Unmarshal([]byte(message[1]), &envelope))
+	assert.Equal(t, uint(42), envelope.ID)
+	assert.Len(t, server.messages, 1)
+}
+
+func TestNATSPublisher_Credentials(t *testing.T) {
+	server := newFakeNATSServer(t)
+	addr := strings.TrimPrefix(server.url(), "nats://")
+
+	publisher, err := NewNATSPublisher("nats://relay:secret@"+addr, "todoapp")
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), testMessage()))
+	options := <-server.connects
+	assert.Equal(t, "relay", options["user"])
+	assert.Equal(t, "secret", options["pass"])
+
+	publisher, err = NewNATSPublisher("nats://s3cr3t-token@"+addr, "todoapp")
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), testMessage()))
+	options = <-server.connects
+	assert.Equal(t, "s3cr3t-token", options["auth_token"])
+	assert.NotContains(t, options, "user")
+}
+
+func TestNATSPublisher_ServerError(t *testing.T) {
+	server := newFakeNATSServer(t)
+	server.reject.Store(true)
+
+	publisher, err := NewNATSPublisher(server.url(), "todoapp")
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), testMessage())
+	assert.EqualError(t, err, "NATS error: Permissions Violation for Publish")
+
+	// The next publish reconnects
+	server.reject.Store(false)
+	require.NoError(t, publisher.Publish(context.Background(), testMessage()))
+	assert.Len(t, server.connects, 2)
+}
+
+func TestNATSPublisher_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	publisher, err := NewNATSPublisher("nats://"+addr, "todoapp")
+	require.NoError(t, err)
+	assert.Error(t, publisher.Publish(context.Background(), testMessage()))
+}
+
+func TestNewNATSPublisher_InvalidURL(t *testing.T) {
+	for _, url := range []string{"", "localhost:4222", "http://localhost:4222"} {
+		_, err := NewNATSPublisher(url, "todoapp")
+		assert.Error(t, err, url)
+	}
+
+	publisher, err := NewNATSPublisher("nats://localhost", "")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:4222", publisher.address)
+	assert.Equal(t, "todo.created", publisher.Subject(testMessage()))
+}
@@ -0,0 +1,107 @@
+// Package realtime fans out live messages to WebSocket sessions, across server
+// instances when backed by a shared broker.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// brokerBuffer is the number of messages a subscription may fall behind before it is dropped
+const brokerBuffer = 256
+
+// Message is a live message distributed to every subscriber of its topic
+type Message struct {
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+	// Origin identifies the session that sent the message, so it can skip its own messages
+	Origin string          `json:"origin,omitempty"`
+	UserID uint            `json:"user_id,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// Broker distributes messages to the subscribers of a topic
+type Broker interface {
+	// Publish sends a message to the subscribers of its topic
+	Publish(ctx context.Context, msg Message) error
+
+	// Subscribe receives the messages of a topic until the subscription is closed
+	Subscribe(topic string) *Subscription
+}
+
+// Subscription receives the messages of a single topic
+type Subscription struct {
+	messages chan Message
+	close    func()
+}
+
+// Messages returns the channel messages are delivered on. It is closed when
+// the subscription is closed or fell too far behind.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	s.close()
+}
+
+// MemoryBroker delivers messages to subscribers within the process
+type MemoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+}
+
+// NewMemoryBroker creates a broker for a single server instance
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{topics: make(map[string]map[*Subscription]struct{})}
+}
+
+// Publish delivers a message to the subscribers of its topic. Subscribers
+// that cannot keep up are dropped rather than allowed to block the publisher.
+func (b *MemoryBroker) Publish(ctx context.Context, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.topics[msg.Topic] {
+		select {
+		case sub.messages <- msg:
+		default:
+			b.remove(msg.Topic, sub)
+		}
+	}
+	return nil
+}
+
+// Subscribe receives the messages of a topic
+func (b *MemoryBroker) Subscribe(topic string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{messages: make(chan Message, brokerBuffer)}
+	sub.close = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(topic, sub)
+	}
+
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[*Subscription]struct{})
+	}
+	b.topics[topic][sub] = struct{}{}
+	return sub
+}
+
+// remove unregisters a subscription and closes its channel; b.mu must be held
+func (b *MemoryBroker) remove(topic string, sub *Subscription) {
+	subs := b.topics[topic]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.topics, topic)
+	}
+	close(sub.messages)
+}
@@ -0,0 +1,105 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/events"
+)
+
+func TestMemoryBroker_DeliversToTopicSubscribers(t *testing.T) {
+	broker := NewMemoryBroker()
+	sub := broker.Subscribe("list:user:1")
+	other := broker.Subscribe("list:user:2")
+	defer sub.Close()
+	defer other.Close()
+
+	require.NoError(t, broker.Publish(context.Background(), Message{Topic: "list:user:1", Type: TypeTyping}))
+
+	msg := <-sub.Messages()
+	assert.Equal(t, TypeTyping, msg.Type)
+	assert.Empty(t, other.Messages())
+}
+
+func TestMemoryBroker_CloseEndsSubscription(t *testing.T) {
+	broker := NewMemoryBroker()
+	sub := broker.Subscribe("list:user:1")
+	sub.Close()
+	sub.Close()
+
+	_, open := <-sub.Messages()
+	assert.False(t, open)
+	require.NoError(t, broker.Publish(context.Background(), Message{Topic: "list:user:1"}))
+}
+
+func TestMemoryBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := NewMemoryBroker()
+	sub := broker.Subscribe("list:user:1")
+
+	for i := 0; i <= brokerBuffer; i++ {
+		require.NoError(t, broker.Publish(context.Background(), Message{Topic: "list:user:1"}))
+	}
+
+	received := 0
+	for range sub.Messages() {
+		received++
+	}
+	assert.Equal(t, brokerBuffer, received)
+	sub.Close()
+}
+
+func TestEventRelay_ForwardsEventsToBus(t *testing.T) {
+	broker := NewMemoryBroker()
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe(1, 0, false)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ForwardEvents(ctx, broker, bus)
+
+	// Wait for the forwarder to subscribe before relaying
+	relay := NewEventRelay(broker)
+	deadline := time.After(time.Second)
+	for {
+		relay.Publish(events.Event{Type: events.TypeTodoUpdated, UserID: 1, TodoID: 5})
+		select {
+		case event := <-sub.Events():
+			assert.Equal(t, events.TypeTodoUpdated, event.Type)
+			assert.Equal(t, uint(1), event.UserID)
+			assert.Equal(t, uint(5), event.TodoID)
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("event was not forwarded")
+		}
+	}
+}
+
+func TestEventRelay_PublishesOnEventsTopic(t *testing.T) {
+	broker := NewMemoryBroker()
+	sub := broker.Subscribe(EventsTopic)
+	defer sub.Close()
+
+	NewEventRelay(broker).Publish(events.Event{Type: events.TypeTodoCreated, UserID: 3, TodoID: 9})
+
+	msg := <-sub.Messages()
+	assert.Equal(t, events.TypeTodoCreated, msg.Type)
+	assert.Equal(t, uint(3), msg.UserID)
+
+	var event events.Event
+	require.NoError(t, json.Unmarshal(msg.Data, &event))
+	assert.Equal(t, uint(9), event.TodoID)
+}
+
+func TestCanAccessList(t *testing.T) {
+	assert.Equal(t, "user:1", UserList(1))
+	assert.True(t, CanAccessList(1, "user:1"))
+	assert.False(t, CanAccessList(1, "user:2"))
+	assert.False(t, CanAccessList(1, ""))
+}
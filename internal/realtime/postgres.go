@@ -0,0 +1,125 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// notifyChannel is the Postgres channel messages are exchanged on
+	notifyChannel = "todo_realtime"
+
+	// maxNotifyPayload is the largest payload Postgres accepts for NOTIFY
+	maxNotifyPayload = 7999
+
+	// maxListenBackoff caps the delay between attempts to re-establish LISTEN
+	maxListenBackoff = 30 * time.Second
+)
+
+var ErrMessageTooLarge = errors.New("message too large for the broker")
+
+// PostgresBroker distributes messages between server instances with
+// LISTEN/NOTIFY. Every instance, including the publishing one, receives
+// published messages through its listener and delivers them to its local
+// subscribers. Messages published while an instance is reconnecting its
+// listener are not delivered to that instance.
+type PostgresBroker struct {
+	db          *gorm.DB
+	databaseURL string
+	local       *MemoryBroker
+}
+
+// NewPostgresBroker creates a broker that publishes through db and listens on
+// a dedicated connection to databaseURL. Start must be called to receive messages.
+func NewPostgresBroker(db *gorm.DB, databaseURL string) *PostgresBroker {
+	return &PostgresBroker{
+		db:          db,
+		databaseURL: databaseURL,
+		local:       NewMemoryBroker(),
+	}
+}
+
+// Publish sends a message to every instance with NOTIFY
+func (b *PostgresBroker) Publish(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		return ErrMessageTooLarge
+	}
+
+	if err := b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", notifyChannel, string(payload)).Error; err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// Subscribe receives the messages of a topic published by any instance
+func (b *PostgresBroker) Subscribe(topic string) *Subscription {
+	return b.local.Subscribe(topic)
+}
+
+// Start listens for messages until ctx is done, reconnecting with
+// exponential backoff when the listening connection fails
+func (b *PostgresBroker) Start(ctx context.Context) {
+	log.Printf("Realtime broker listening on Postgres channel %q", notifyChannel)
+
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			log.Println("Realtime broker stopped")
+			return
+		}
+
+		// A listener that was up for a while starts over with a short delay
+		if time.Since(started) > maxListenBackoff {
+			backoff = time.Second
+		}
+
+		log.Printf("Realtime broker listener failed, retrying in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			log.Println("Realtime broker stopped")
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+// listen delivers notifications to local subscribers until the connection fails or ctx is done
+func (b *PostgresBroker) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.databaseURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var msg Message
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			log.Printf("Realtime broker received an invalid message: %v", err)
+			continue
+		}
+		b.local.Publish(ctx, msg)
+	}
+}
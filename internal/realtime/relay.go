@@ -0,0 +1,71 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"todo-api-backend/internal/events"
+)
+
+// EventsTopic is the broker topic todo change events are relayed on
+const EventsTopic = "todo-events"
+
+// EventRelay publishes todo change events through a broker so that the
+// event streams of every server instance receive them
+type EventRelay struct {
+	broker Broker
+}
+
+// NewEventRelay creates an events.Publisher that relays events through broker
+func NewEventRelay(broker Broker) *EventRelay {
+	return &EventRelay{broker: broker}
+}
+
+// Publish sends an event to every instance; failures are logged, never returned
+func (r *EventRelay) Publish(event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	msg := Message{Topic: EventsTopic, Type: event.Type, UserID: event.UserID, Data: data}
+	if err := r.broker.Publish(context.Background(), msg); err != nil {
+		log.Printf("Failed to relay %s event for todo %d: %v", event.Type, event.TodoID, err)
+	}
+}
+
+// ForwardEvents publishes the events relayed through broker on the local bus
+// until ctx is done
+func ForwardEvents(ctx context.Context, broker Broker, bus *events.Bus) {
+	for ctx.Err() == nil {
+		sub := broker.Subscribe(EventsTopic)
+		forward(ctx, sub, bus)
+		sub.Close()
+	}
+}
+
+// forward copies events from sub to bus until ctx is done or sub is dropped
+func forward(ctx context.Context, sub *Subscription, bus *events.Bus) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				log.Println("Event forwarder fell behind, resubscribing")
+				return
+			}
+
+			var event events.Event
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				log.Printf("Failed to decode relayed event: %v", err)
+				continue
+			}
+			// The user is not part of the event's JSON form
+			event.UserID = msg.UserID
+			bus.Publish(event)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"todo-api-backend/internal/events"
+)
+
+// Client message types
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypePresence    = "presence"
+	TypeTyping      = "typing"
+	TypePing        = "ping"
+)
+
+// Server message types; presence and typing messages are relayed as they are
+const (
+	TypeSubscribed   = "subscribed"
+	TypeUnsubscribed = "unsubscribed"
+	TypeEvent        = "event"
+	TypePong         = "pong"
+	TypeHeartbeat    = "heartbeat"
+	TypeError        = "error"
+)
+
+// Presence statuses
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+// sessionBuffer is the number of messages a session may fall behind before it is closed
+const sessionBuffer = 64
+
+// ClientMessage is a message sent by a WebSocket client
+type ClientMessage struct {
+	Type   string `json:"type" example:"subscribe"`
+	List   string `json:"list,omitempty" example:"user:1"`
+	Status string `json:"status,omitempty" example:"online"`
+	TodoID uint   `json:"todo_id,omitempty" example:"1"`
+	Typing bool   `json:"typing,omitempty" example:"true"`
+}
+
+// ServerMessage is a message sent to a WebSocket client
+type ServerMessage struct {
+	Type    string        `json:"type" example:"event"`
+	List    string        `json:"list,omitempty" example:"user:1"`
+	UserID  uint          `json:"user_id,omitempty" example:"1"`
+	Status  string        `json:"status,omitempty" example:"online"`
+	TodoID  uint          `json:"todo_id,omitempty" example:"1"`
+	Typing  *bool         `json:"typing,omitempty" example:"true"`
+	Event   *events.Event `json:"event,omitempty"`
+	Error   string        `json:"error,omitempty" example:"forbidden"`
+	Message string        `json:"message,omitempty" example:"You cannot access this list"`
+}
+
+// Conn is a message-oriented client connection such as a WebSocket
+type Conn interface {
+	Receive() ([]byte, error)
+	Send(data []byte) error
+	Close() error
+}
+
+// UserList returns the ID of the list holding a user's own todos
+func UserList(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// CanAccessList reports whether a user may join a list. Users can only
+// join the list of their own todos.
+func CanAccessList(userID uint, list string) bool {
+	return list == UserList(userID)
+}
+
+// listTopic returns the broker topic presence and typing messages of a list are sent on
+func listTopic(list string) string {
+	return "list:" + list
+}
+
+// Session serves a single client connection: it subscribes the client to
+// lists, delivers the todo change events and the presence and typing
+// indicators of those lists, and relays the client's own indicators to the
+// other members of the lists.
+type Session struct {
+	id        string
+	userID    uint
+	broker    Broker
+	bus       *events.Bus
+	heartbeat time.Duration
+
+	out    chan ServerMessage
+	cancel context.CancelFunc
+	lists  map[string]*listSubscription
+}
+
+// listSubscription holds the subscriptions of a session to a single list
+type listSubscription struct {
+	events  *events.Subscription
+	signals *Subscription
+	stop    chan struct{}
+}
+
+// NewSession creates a session for a user. Todo change events are read from
+// bus and indicators exchanged through broker; a heartbeat message is sent
+// at the given interval unless it is 0.
+func NewSession(userID uint, broker Broker, bus *events.Bus, heartbeat time.Duration) *Session {
+	return &Session{
+		id:        newSessionID(),
+		userID:    userID,
+		broker:    broker,
+		bus:       bus,
+		heartbeat: heartbeat,
+		out:       make(chan ServerMessage, sessionBuffer),
+		lists:     make(map[string]*listSubscription),
+	}
+}
+
+// Run serves conn until the client disconnects, the connection fails, the
+// client falls too far behind or ctx is done. It closes conn when it returns.
+func (s *Session) Run(ctx context.Context, conn Conn) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	defer s.cancel()
+	defer s.leaveAll(context.WithoutCancel(ctx))
+
+	go s.write(ctx, conn)
+
+	// Unblock the reader when the session ends for any other reason
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for {
+		data, err := conn.Receive()
+		if err != nil {
+			return
+		}
+
+		var msg ClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.sendError("", "invalid_message", "Messages must be JSON objects")
+			continue
+		}
+		s.handle(ctx, &msg)
+	}
+}
+
+// handle processes a single client message
+func (s *Session) handle(ctx context.Context, msg *ClientMessage) {
+	switch msg.Type {
+	case TypePing:
+		s.send(ServerMessage{Type: TypePong})
+
+	case TypeSubscribe:
+		if !CanAccessList(s.userID, msg.List) {
+			s.sendError(msg.List, "forbidden", "You cannot access this list")
+			return
+		}
+		if _, ok := s.lists[msg.List]; !ok {
+			s.join(ctx, msg.List)
+		}
+		s.send(ServerMessage{Type: TypeSubscribed, List: msg.List})
+
+	case TypeUnsubscribe:
+		if _, ok := s.lists[msg.List]; ok {
+			s.leave(ctx, msg.List)
+		}
+		s.send(ServerMessage{Type: TypeUnsubscribed, List: msg.List})
+
+	case TypePresence:
+		if msg.Status != PresenceOnline && msg.Status != PresenceAway {
+			s.sendError(msg.List, "invalid_message", "status must be one of: online, away")
+			return
+		}
+		s.signal(ctx, msg.List, ServerMessage{Type: TypePresence, Status: msg.Status})
+
+	case TypeTyping:
+		typing := msg.Typing
+		s.signal(ctx, msg.List, ServerMessage{Type: TypeTyping, TodoID: msg.TodoID, Typing: &typing})
+
+	default:
+		s.sendError(msg.List, "invalid_message", "type must be one of: subscribe, unsubscribe, presence, typing, ping")
+	}
+}
+
+// join subscribes the session to a list and announces it to the other members
+func (s *Session) join(ctx context.Context, list string) {
+	eventSub, _, _ := s.bus.Subscribe(s.userID, 0, false)
+	sub := &listSubscription{
+		events:  eventSub,
+		signals: s.broker.Subscribe(listTopic(list)),
+		stop:    make(chan struct{}),
+	}
+	s.lists[list] = sub
+
+	go s.pump(ctx, list, sub)
+	s.publish(ctx, list, ServerMessage{Type: TypePresence, Status: PresenceOnline})
+}
+
+// leave unsubscribes the session from a list and announces it to the other members
+func (s *Session) leave(ctx context.Context, list string) {
+	sub := s.lists[list]
+	delete(s.lists, list)
+
+	close(sub.stop)
+	sub.events.Close()
+	sub.signals.Close()
+	s.publish(ctx, list, ServerMessage{Type: TypePresence, Status: PresenceOffline})
+}
+
+// leaveAll unsubscribes the session from every list
+func (s *Session) leaveAll(ctx context.Context) {
+	for list := range s.lists {
+		s.leave(ctx, list)
+	}
+}
+
+// pump delivers the events and indicators of a list to the client until the
+// session leaves the list. A subscription that fell behind ends the session,
+// so the client reconnects and reloads.
+func (s *Session) pump(ctx context.Context, list string, sub *listSubscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.stop:
+			return
+
+		case event, ok := <-sub.events.Events():
+			if !ok {
+				s.close(sub)
+				return
+			}
+			s.send(ServerMessage{Type: TypeEvent, List: list, Event: &event})
+
+		case msg, ok := <-sub.signals.Messages():
+			if !ok {
+				s.close(sub)
+				return
+			}
+			if msg.Origin == s.id {
+				continue
+			}
+
+			var relayed ServerMessage
+			if err := json.Unmarshal(msg.Data, &relayed); err != nil {
+				log.Printf("Failed to decode %s message: %v", msg.Type, err)
+				continue
+			}
+			s.send(relayed)
+		}
+	}
+}
+
+// close ends the session unless sub was closed because the session left its list
+func (s *Session) close(sub *listSubscription) {
+	select {
+	case <-sub.stop:
+	default:
+		s.cancel()
+	}
+}
+
+// signal relays a presence or typing indicator to the other members of a list
+func (s *Session) signal(ctx context.Context, list string, msg ServerMessage) {
+	if _, ok := s.lists[list]; !ok {
+		s.sendError(list, "not_subscribed", "Subscribe to the list first")
+		return
+	}
+	if err := s.publish(ctx, list, msg); err != nil {
+		s.sendError(list, "broker_unavailable", "Failed to send the message")
+	}
+}
+
+// publish sends an indicator of this session's user to the members of a list
+func (s *Session) publish(ctx context.Context, list string, msg ServerMessage) error {
+	msg.List = list
+	msg.UserID = s.userID
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	err = s.broker.Publish(ctx, Message{
+		Topic:  listTopic(list),
+		Type:   msg.Type,
+		Origin: s.id,
+		UserID: s.userID,
+		Data:   data,
+	})
+	if err != nil {
+		log.Printf("Failed to publish %s message: %v", msg.Type, err)
+	}
+	return err
+}
+
+// send queues a message for the client, ending the session if the client fell too far behind
+func (s *Session) send(msg ServerMessage) {
+	select {
+	case s.out <- msg:
+	default:
+		s.cancel()
+	}
+}
+
+// sendError queues an error message for the client
+func (s *Session) sendError(list, code, message string) {
+	s.send(ServerMessage{Type: TypeError, List: list, Error: code, Message: message})
+}
+
+// write sends queued messages and heartbeats to the client until the session ends
+func (s *Session) write(ctx context.Context, conn Conn) {
+	defer s.cancel()
+
+	var heartbeat <-chan time.Time
+	if s.heartbeat > 0 {
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		var msg ServerMessage
+		select {
+		case <-ctx.Done():
+			return
+		case msg = <-s.out:
+		case <-heartbeat:
+			msg = ServerMessage{Type: TypeHeartbeat}
+		}
+
+		data, err := json.Marshal(msg)
+		if err != nil {
+			log.Printf("Failed to encode %s message: %v", msg.Type, err)
+			continue
+		}
+		if err := conn.Send(data); err != nil {
+			return
+		}
+	}
+}
+
+// newSessionID returns a random identifier for a session
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"todo-api-backend/internal/model"
+)
+
+// AttachmentRepository defines the interface for the metadata of todo attachments
+type AttachmentRepository interface {
+	// Lock serialises uploads of a user until the transaction in ctx ends, so
+	// that concurrent uploads cannot exceed the quota together
+	Lock(ctx context.Context, userID uint) error
+
+	// Create stores a new attachment
+	Create(ctx context.Context, attachment *model.Attachment) error
+
+	// GetByID retrieves an attachment
+	GetByID(ctx context.Context, id uint) (*model.Attachment, error)
+
+	// ListByTodoID retrieves the attachments of a todo, oldest first
+	ListByTodoID(ctx context.Context, todoID uint) ([]*model.Attachment, error)
+
+	// TotalSize returns the total size of all attachments of a user
+	TotalSize(ctx context.Context, userID uint) (int64, error)
+
+	// Delete removes an attachment of a todo
+	Delete(ctx context.Context, id uint, todoID uint) error
+
+	// ListOrphaned retrieves up to limit attachments whose todo has been purged
+	ListOrphaned(ctx context.Context, limit int) ([]*model.Attachment, error)
+}
+
+// attachmentRepository implements the AttachmentRepository interface
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository instance
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{
+		db: db,
+	}
+}
+
+// Lock locks the row of the user until the transaction in ctx ends
+func (r *attachmentRepository) Lock(ctx context.Context, userID uint) error {
+	var user model.User
+	return conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", userID).
+		Take(&user).Error
+}
+
+// Create stores a new attachment
+func (r *attachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	return conn(ctx, r.db).Create(attachment).Error
+}
+
+// GetByID retrieves an attachment
+func (r *attachmentRepository) GetByID(ctx context.Context, id uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := conn(ctx, r.db).Where("id = ?", id).First(&attachment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListByTodoID retrieves the attachments of a todo, oldest first
+func (r *attachmentRepository) ListByTodoID(ctx context.Context, todoID uint) ([]*model.Attachment, error) {
+	var attachments []*model.Attachment
+	err := conn(ctx, r.db).
+		Where("todo_id = ?", todoID).
+		Order("id ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// TotalSize returns the total size of all attachments of a user
+func (r *attachmentRepository) TotalSize(ctx context.Context, userID uint) (int64, error) {
+	var total int64
+	err := conn(ctx, r.db).Model(&model.Attachment{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// Delete removes an attachment, ensuring it belongs to the specified todo
+func (r *attachmentRepository) Delete(ctx context.Context, id uint, todoID uint) error {
+	result := conn(ctx, r.db).
+		Where("id = ? AND todo_id = ?", id, todoID).
+		Delete(&model.Attachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListOrphaned retrieves up to limit attachments whose todo no longer exists,
+// not even in the trash
+func (r *attachmentRepository) ListOrphaned(ctx context.Context, limit int) ([]*model.Attachment, error) {
+	var attachments []*model.Attachment
+	err := conn(ctx, r.db).
+		Where("NOT EXISTS (SELECT 1 FROM todos WHERE todos.id = todo_attachments.todo_id)").
+		Order("id ASC").
+		Limit(limit).
+		Find(&attachments).Error
+	return attachments, err
+}
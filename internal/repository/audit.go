@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"todo-api-backend/internal/model"
+)
+
+// securityEventRepository implements the SecurityEventRepository interface
+type securityEventRepository struct {
+	db *gorm.DB
+}
+
+// NewSecurityEventRepository creates a new security event repository instance
+func NewSecurityEventRepository(db *gorm.DB) SecurityEventRepository {
+	return &securityEventRepository{
+		db: db,
+	}
+}
+
+// Create appends a security event
+func (r *securityEventRepository) Create(ctx context.Context, event *model.SecurityEvent) error {
+	if err := conn(ctx, r.db).Create(event).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// List retrieves the security events matching the filter, newest first
+func (r *securityEventRepository) List(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	query := conn(ctx, r.db).Model(&model.SecurityEvent{})
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []*model.SecurityEvent
+	if err := query.Order("created_at DESC, id DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+)
+
+// CalDAVPasswordRepository defines the interface for CalDAV app password storage
+type CalDAVPasswordRepository interface {
+	// Create stores a new app password
+	Create(ctx context.Context, password *model.CalDAVPassword) error
+
+	// ListByUserID retrieves all app passwords of a user, oldest first
+	ListByUserID(ctx context.Context, userID uint) ([]*model.CalDAVPassword, error)
+
+	// GetByHash retrieves the app password with the given hash
+	GetByHash(ctx context.Context, passwordHash string) (*model.CalDAVPassword, error)
+
+	// Touch records that an app password was used at the given time
+	Touch(ctx context.Context, id uint, usedAt time.Time) error
+
+	// Delete removes an app password, ensuring it belongs to the specified user
+	Delete(ctx context.Context, id uint, userID uint) error
+}
+
+// CalDAVResourceRepository defines the interface for the resource names of todos created through CalDAV
+type CalDAVResourceRepository interface {
+	// Create stores the resource name and UID of a todo
+	Create(ctx context.Context, resource *model.CalDAVResource) error
+
+	// GetByName retrieves the resource of a user with the given name
+	GetByName(ctx context.Context, userID uint, name string) (*model.CalDAVResource, error)
+
+	// GetByTodoID retrieves the resource of a todo
+	GetByTodoID(ctx context.Context, todoID uint) (*model.CalDAVResource, error)
+
+	// ListByUserID retrieves all resources of a user
+	ListByUserID(ctx context.Context, userID uint) ([]*model.CalDAVResource, error)
+
+	// ExistsUID reports whether a user has a resource with the given UID
+	ExistsUID(ctx context.Context, userID uint, uid string) (bool, error)
+
+	// Release removes the resources of a user with the given name or UID whose
+	// todos are in the trash, so that the name and UID can be used again
+	Release(ctx context.Context, userID uint, name string, uid string) error
+}
+
+// calDAVPasswordRepository implements the CalDAVPasswordRepository interface
+type calDAVPasswordRepository struct {
+	db *gorm.DB
+}
+
+// NewCalDAVPasswordRepository creates a new CalDAV app password repository instance
+func NewCalDAVPasswordRepository(db *gorm.DB) CalDAVPasswordRepository {
+	return &calDAVPasswordRepository{
+		db: db,
+	}
+}
+
+// Create stores a new app password
+func (r *calDAVPasswordRepository) Create(ctx context.Context, password *model.CalDAVPassword) error {
+	return conn(ctx, r.db).Create(password).Error
+}
+
+// ListByUserID retrieves all app passwords of a user, oldest first
+func (r *calDAVPasswordRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.CalDAVPassword, error) {
+	var passwords []*model.CalDAVPassword
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id ASC").Find(&passwords).Error
+	return passwords, err
+}
+
+// GetByHash retrieves the app password with the given hash
+func (r *calDAVPasswordRepository) GetByHash(ctx context.Context, passwordHash string) (*model.CalDAVPassword, error) {
+	var password model.CalDAVPassword
+	if err := conn(ctx, r.db).Where("password_hash = ?", passwordHash).First(&password).Error; err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
+
+// Touch records that an app password was used at the given time
+func (r *calDAVPasswordRepository) Touch(ctx context.Context, id uint, usedAt time.Time) error {
+	return conn(ctx, r.db).Model(&model.CalDAVPassword{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+// Delete removes an app password, ensuring it belongs to the specified user
+func (r *calDAVPasswordRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&model.CalDAVPassword{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// calDAVResourceRepository implements the CalDAVResourceRepository interface
+type calDAVResourceRepository struct {
+	db *gorm.DB
+}
+
+// NewCalDAVResourceRepository creates a new CalDAV resource repository instance
+func NewCalDAVResourceRepository(db *gorm.DB) CalDAVResourceRepository {
+	return &calDAVResourceRepository{
+		db: db,
+	}
+}
+
+// Create stores the resource name and UID of a todo
+func (r *calDAVResourceRepository) Create(ctx context.Context, resource *model.CalDAVResource) error {
+	return conn(ctx, r.db).Create(resource).Error
+}
+
+// GetByName retrieves the resource of a user with the given name
+func (r *calDAVResourceRepository) GetByName(ctx context.Context, userID uint, name string) (*model.CalDAVResource, error) {
+	var resource model.CalDAVResource
+	if err := conn(ctx, r.db).Where("user_id = ? AND name = ?", userID, name).First(&resource).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// GetByTodoID retrieves the resource of a todo
+func (r *calDAVResourceRepository) GetByTodoID(ctx context.Context, todoID uint) (*model.CalDAVResource, error) {
+	var resource model.CalDAVResource
+	if err := conn(ctx, r.db).Where("todo_id = ?", todoID).First(&resource).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// ListByUserID retrieves all resources of a user
+func (r *calDAVResourceRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.CalDAVResource, error) {
+	var resources []*model.CalDAVResource
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Find(&resources).Error
+	return resources, err
+}
+
+// ExistsUID reports whether a user has a resource with the given UID
+func (r *calDAVResourceRepository) ExistsUID(ctx context.Context, userID uint, uid string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.CalDAVResource{}).Where("user_id = ? AND uid = ?", userID, uid).Count(&count).Error
+	return count > 0, err
+}
+
+// Release removes the resources of a user with the given name or UID whose
+// todos are in the trash, so that the name and UID can be used again
+func (r *calDAVResourceRepository) Release(ctx context.Context, userID uint, name string, uid string) error {
+	trashed := conn(ctx, r.db).Unscoped().Model(&model.Todo{}).Select("id").Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+	return conn(ctx, r.db).
+		Where("user_id = ? AND (name = ? OR uid = ?) AND todo_id IN (?)", userID, name, uid, trashed).
+		Delete(&model.CalDAVResource{}).Error
+}
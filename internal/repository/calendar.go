@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"todo-api-backend/internal/model"
+)
+
+// CalendarFeedRepository defines the interface for calendar feed tokens
+type CalendarFeedRepository interface {
+	// Save stores the feed of a user, replacing the token of an existing feed
+	Save(ctx context.Context, feed *model.CalendarFeed) error
+
+	// GetByUserID retrieves the feed of a user
+	GetByUserID(ctx context.Context, userID uint) (*model.CalendarFeed, error)
+
+	// GetByTokenHash retrieves the feed with the given token hash
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.CalendarFeed, error)
+
+	// Delete removes the feed of a user
+	Delete(ctx context.Context, userID uint) error
+
+	// Stamp summarises the todos in the feed of a user: active todos that are
+	// neither archived nor trashed
+	Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error)
+}
+
+// calendarFeedRepository implements the CalendarFeedRepository interface
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarFeedRepository creates a new calendar feed repository instance
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{
+		db: db,
+	}
+}
+
+// Save stores the feed of a user, replacing the token of an existing feed
+func (r *calendarFeedRepository) Save(ctx context.Context, feed *model.CalendarFeed) error {
+	return conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_at"}),
+		}).
+		Create(feed).Error
+}
+
+// GetByUserID retrieves the feed of a user
+func (r *calendarFeedRepository) GetByUserID(ctx context.Context, userID uint) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// GetByTokenHash retrieves the feed with the given token hash
+func (r *calendarFeedRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	if err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// Delete removes the feed of a user
+func (r *calendarFeedRepository) Delete(ctx context.Context, userID uint) error {
+	result := conn(ctx, r.db).Where("user_id = ?", userID).Delete(&model.CalendarFeed{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Stamp summarises the todos in the feed of a user. Trashed todos are included in
+// the last modification time, so that deleting a todo changes the stamp.
+func (r *calendarFeedRepository) Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error) {
+	var row struct {
+		Count        int64
+		LastModified *time.Time
+	}
+	err := conn(ctx, r.db).Unscoped().Model(&model.Todo{}).
+		Select("COALESCE(SUM(CASE WHEN deleted_at IS NULL AND archived_at IS NULL THEN 1 ELSE 0 END), 0) AS count, "+
+			"MAX(COALESCE(deleted_at, updated_at)) AS last_modified").
+		Where("user_id = ?", userID).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stamp := &model.CalendarStamp{Count: row.Count}
+	if row.LastModified != nil {
+		stamp.LastModified = *row.LastModified
+	}
+	return stamp, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+)
+
+// CommentRepository defines the interface for the comments of todos
+type CommentRepository interface {
+	// Create stores a new comment
+	Create(ctx context.Context, comment *model.TodoComment) error
+
+	// GetByID retrieves a comment of a todo
+	GetByID(ctx context.Context, id uint, todoID uint) (*model.TodoComment, error)
+
+	// ListThreads retrieves a page of the top-level comments of a todo, oldest first
+	ListThreads(ctx context.Context, todoID uint, offset, limit int) ([]*model.TodoComment, error)
+
+	// CountThreads returns the number of top-level comments of a todo
+	CountThreads(ctx context.Context, todoID uint) (int64, error)
+
+	// ListReplies retrieves the replies to the given top-level comments, oldest first
+	ListReplies(ctx context.Context, parentIDs []uint) ([]*model.TodoComment, error)
+
+	// Update stores the body and mentions of a comment and marks it as edited
+	Update(ctx context.Context, comment *model.TodoComment) error
+
+	// Delete removes a comment of a todo together with its replies
+	Delete(ctx context.Context, id uint, todoID uint) error
+}
+
+// commentRepository implements the CommentRepository interface
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository creates a new comment repository instance
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{
+		db: db,
+	}
+}
+
+// Create stores a new comment
+func (r *commentRepository) Create(ctx context.Context, comment *model.TodoComment) error {
+	return conn(ctx, r.db).Create(comment).Error
+}
+
+// GetByID retrieves a comment, ensuring it belongs to the specified todo
+func (r *commentRepository) GetByID(ctx context.Context, id uint, todoID uint) (*model.TodoComment, error) {
+	var comment model.TodoComment
+	err := conn(ctx, r.db).Where("id = ? AND todo_id = ?", id, todoID).First(&comment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListThreads retrieves a page of the top-level comments of a todo, oldest first
+func (r *commentRepository) ListThreads(ctx context.Context, todoID uint, offset, limit int) ([]*model.TodoComment, error) {
+	var comments []*model.TodoComment
+	err := conn(ctx, r.db).
+		Where("todo_id = ? AND parent_id IS NULL", todoID).
+		Order("id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&comments).Error
+	return comments, err
+}
+
+// CountThreads returns the number of top-level comments of a todo
+func (r *commentRepository) CountThreads(ctx context.Context, todoID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.TodoComment{}).
+		Where("todo_id = ? AND parent_id IS NULL", todoID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListReplies retrieves the replies to the given top-level comments, oldest first
+func (r *commentRepository) ListReplies(ctx context.Context, parentIDs []uint) ([]*model.TodoComment, error) {
+	var replies []*model.TodoComment
+	if len(parentIDs) == 0 {
+		return replies, nil
+	}
+	err := conn(ctx, r.db).
+		Where("parent_id IN ?", parentIDs).
+		Order("id ASC").
+		Find(&replies).Error
+	return replies, err
+}
+
+// Update stores the body and mentions of a comment and marks it as edited
+func (r *commentRepository) Update(ctx context.Context, comment *model.TodoComment) error {
+	comment.Edited = true
+	return conn(ctx, r.db).Model(comment).
+		Select("Body", "Mentions", "Edited").
+		Updates(comment).Error
+}
+
+// Delete removes a comment of a todo together with its replies
+func (r *commentRepository) Delete(ctx context.Context, id uint, todoID uint) error {
+	result := conn(ctx, r.db).
+		Where("todo_id = ? AND (id = ? OR parent_id = ?)", todoID, id, id).
+		Delete(&model.TodoComment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
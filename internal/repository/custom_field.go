@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"todo-api-backend/internal/model"
+)
+
+// CustomFieldRepository defines the interface for custom field definitions
+type CustomFieldRepository interface {
+	// Create stores a new custom field
+	Create(ctx context.Context, field *model.CustomField) error
+
+	// GetByID retrieves a custom field by ID, ensuring it belongs to the specified user
+	GetByID(ctx context.Context, id uint, userID uint) (*model.CustomField, error)
+
+	// ListByUserID retrieves all custom fields of a user, oldest first
+	ListByUserID(ctx context.Context, userID uint) ([]*model.CustomField, error)
+
+	// ListForTodo retrieves the custom fields that apply to a todo of a user in
+	// the given list, or outside any list if listID is nil, oldest first
+	ListForTodo(ctx context.Context, userID uint, listID *uint) ([]*model.CustomField, error)
+
+	// CountByUserID returns the number of custom fields of a user
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+
+	// ExistsKey reports whether the user has a custom field with the given key
+	ExistsKey(ctx context.Context, userID uint, key string) (bool, error)
+
+	// Update stores the name, required flag and rules of a custom field
+	Update(ctx context.Context, field *model.CustomField) error
+
+	// Delete removes a custom field, ensuring it belongs to the specified user
+	Delete(ctx context.Context, id uint, userID uint) error
+
+	// RemoveValues removes the values of a custom field key from every todo of a user, including trashed todos
+	RemoveValues(ctx context.Context, userID uint, key string) error
+}
+
+// customFieldRepository implements the CustomFieldRepository interface
+type customFieldRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomFieldRepository creates a new custom field repository instance
+func NewCustomFieldRepository(db *gorm.DB) CustomFieldRepository {
+	return &customFieldRepository{
+		db: db,
+	}
+}
+
+// Create stores a new custom field
+func (r *customFieldRepository) Create(ctx context.Context, field *model.CustomField) error {
+	return conn(ctx, r.db).Create(field).Error
+}
+
+// GetByID retrieves a custom field by ID, ensuring it belongs to the specified user
+func (r *customFieldRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.CustomField, error) {
+	var field model.CustomField
+	err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&field).Error
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// ListByUserID retrieves all custom fields of a user, oldest first
+func (r *customFieldRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.CustomField, error) {
+	var fields []*model.CustomField
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id ASC").Find(&fields).Error
+	return fields, err
+}
+
+// ListForTodo retrieves the custom fields of the user without a list and, if
+// listID is set, those of the list, oldest first
+func (r *customFieldRepository) ListForTodo(ctx context.Context, userID uint, listID *uint) ([]*model.CustomField, error) {
+	query := conn(ctx, r.db).Where("user_id = ?", userID)
+	if listID != nil {
+		query = query.Where("list_id IS NULL OR list_id = ?", *listID)
+	} else {
+		query = query.Where("list_id IS NULL")
+	}
+
+	var fields []*model.CustomField
+	err := query.Order("id ASC").Find(&fields).Error
+	return fields, err
+}
+
+// CountByUserID returns the number of custom fields of a user
+func (r *customFieldRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.CustomField{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ExistsKey reports whether the user has a custom field with the given key
+func (r *customFieldRepository) ExistsKey(ctx context.Context, userID uint, key string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.CustomField{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update stores the name, required flag and rules of a custom field
+func (r *customFieldRepository) Update(ctx context.Context, field *model.CustomField) error {
+	return conn(ctx, r.db).Model(field).
+		Select("Name", "Required", "Rules").
+		Updates(field).Error
+}
+
+// Delete removes a custom field, ensuring it belongs to the specified user
+func (r *customFieldRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&model.CustomField{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RemoveValues removes the values of a custom field key from every todo of a
+// user, bumping the version so that cached representations and sync clients
+// see the change
+func (r *customFieldRepository) RemoveValues(ctx context.Context, userID uint, key string) error {
+	return conn(ctx, r.db).Unscoped().Model(&model.Todo{}).
+		Where("user_id = ? AND custom_fields -> ?::text IS NOT NULL", userID, key).
+		Updates(map[string]interface{}{
+			"custom_fields": gorm.Expr("custom_fields - ?::text", key),
+			"version":       gorm.Expr("version + 1"),
+		}).Error
+}
+
+// customFieldOperators maps the custom field filter operators to SQL
+var customFieldOperators = map[string]string{
+	model.CustomFieldEqual:   "=",
+	model.CustomFieldAtLeast: ">=",
+	model.CustomFieldAtMost:  "<=",
+}
+
+// customFieldValue returns the SQL expression of the value of a custom field
+// of a todo, typed so that it compares by value; its key is the only parameter
+func customFieldValue(fieldType string) string {
+	switch fieldType {
+	case model.CustomFieldNumber:
+		return "(custom_fields ->> ?::text)::numeric"
+	case model.CustomFieldCheckbox:
+		return "(custom_fields ->> ?::text)::boolean"
+	default:
+		// Dates are stored as YYYY-MM-DD, which orders like the dates themselves
+		return "custom_fields ->> ?::text"
+	}
+}
+
+// applyCustomFieldFilter restricts a todo query to the todos matching every
+// condition on their custom field values
+func applyCustomFieldFilter(query *gorm.DB, conditions []model.CustomFieldCondition) *gorm.DB {
+	for _, condition := range conditions {
+		if condition.Op == model.CustomFieldEqual {
+			// Containment can use the GIN index on the values
+			document, err := json.Marshal(map[string]interface{}{condition.Key: condition.Parsed})
+			if err != nil {
+				query.AddError(err)
+				return query
+			}
+			query = query.Where("custom_fields @> ?::jsonb", string(document))
+			continue
+		}
+		sql := customFieldValue(condition.Type) + " " + customFieldOperators[condition.Op] + " ?"
+		query = query.Where(sql, condition.Key, condition.Parsed)
+	}
+	return query
+}
+
+// customFieldOrder orders todos by a custom field value, newest first among
+// equal values, with todos without a value last
+func customFieldOrder(sort *model.CustomFieldSort) clause.OrderBy {
+	direction := " ASC"
+	if sort.Desc {
+		direction = " DESC"
+	}
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:  customFieldValue(sort.Type) + direction + " NULLS LAST, created_at DESC",
+		Vars: []interface{}{sort.Key},
+	}}
+}
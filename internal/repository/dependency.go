@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"todo-api-backend/internal/model"
+)
+
+// DependencyRepository defines the interface for dependencies between todos
+type DependencyRepository interface {
+	// Lock serialises changes to the dependencies of a user until the
+	// transaction in ctx ends, so that concurrent changes cannot form a cycle
+	Lock(ctx context.Context, userID uint) error
+
+	// Create stores a new dependency
+	Create(ctx context.Context, dependency *model.TodoDependency) error
+
+	// Delete removes the dependency between two todos, whichever blocks the other
+	Delete(ctx context.Context, userID uint, todoID uint, otherID uint) error
+
+	// ListByUserID retrieves all dependencies of a user, including those of trashed todos
+	ListByUserID(ctx context.Context, userID uint) ([]*model.TodoDependency, error)
+
+	// ListLinkedTodos retrieves the todos of a user outside the trash that block
+	// or are blocked by another todo, oldest first
+	ListLinkedTodos(ctx context.Context, userID uint) ([]*model.Todo, error)
+
+	// ListBlockers retrieves the todos outside the trash that block a todo, oldest first
+	ListBlockers(ctx context.Context, userID uint, todoID uint) ([]*model.Todo, error)
+
+	// ListBlocked retrieves the todos outside the trash that a todo blocks, oldest first
+	ListBlocked(ctx context.Context, userID uint, todoID uint) ([]*model.Todo, error)
+
+	// BlockedTodoIDs returns which of the given todos are blocked by a todo
+	// that is neither completed nor trashed
+	BlockedTodoIDs(ctx context.Context, userID uint, todoIDs []uint) ([]uint, error)
+}
+
+// dependencyRepository implements the DependencyRepository interface
+type dependencyRepository struct {
+	db *gorm.DB
+}
+
+// NewDependencyRepository creates a new dependency repository instance
+func NewDependencyRepository(db *gorm.DB) DependencyRepository {
+	return &dependencyRepository{
+		db: db,
+	}
+}
+
+// Lock locks the row of the user until the transaction in ctx ends
+func (r *dependencyRepository) Lock(ctx context.Context, userID uint) error {
+	var user model.User
+	return conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", userID).
+		Take(&user).Error
+}
+
+// Create stores a new dependency
+func (r *dependencyRepository) Create(ctx context.Context, dependency *model.TodoDependency) error {
+	return conn(ctx, r.db).Create(dependency).Error
+}
+
+// Delete removes the dependency between two todos, whichever blocks the other
+func (r *dependencyRepository) Delete(ctx context.Context, userID uint, todoID uint, otherID uint) error {
+	result := conn(ctx, r.db).
+		Where("user_id = ? AND ((todo_id = ? AND blocked_by_id = ?) OR (todo_id = ? AND blocked_by_id = ?))", userID, todoID, otherID, otherID, todoID).
+		Delete(&model.TodoDependency{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListByUserID retrieves all dependencies of a user, including those of trashed todos
+func (r *dependencyRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.TodoDependency, error) {
+	var dependencies []*model.TodoDependency
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id ASC").Find(&dependencies).Error
+	return dependencies, err
+}
+
+// ListLinkedTodos retrieves the todos of a user outside the trash that take part in a dependency
+func (r *dependencyRepository) ListLinkedTodos(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Where("EXISTS (SELECT 1 FROM todo_dependencies d WHERE d.todo_id = todos.id OR d.blocked_by_id = todos.id)").
+		Order("id ASC").
+		Find(&todos).Error
+	return todos, err
+}
+
+// ListBlockers retrieves the todos outside the trash that block a todo
+func (r *dependencyRepository) ListBlockers(ctx context.Context, userID uint, todoID uint) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Where("id IN (SELECT blocked_by_id FROM todo_dependencies WHERE todo_id = ?)", todoID).
+		Order("id ASC").
+		Find(&todos).Error
+	return todos, err
+}
+
+// ListBlocked retrieves the todos outside the trash that a todo blocks
+func (r *dependencyRepository) ListBlocked(ctx context.Context, userID uint, todoID uint) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Where("id IN (SELECT todo_id FROM todo_dependencies WHERE blocked_by_id = ?)", todoID).
+		Order("id ASC").
+		Find(&todos).Error
+	return todos, err
+}
+
+// BlockedTodoIDs returns which of the given todos have an open blocker
+func (r *dependencyRepository) BlockedTodoIDs(ctx context.Context, userID uint, todoIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(todoIDs) == 0 {
+		return ids, nil
+	}
+	err := conn(ctx, r.db).Model(&model.TodoDependency{}).
+		Distinct("todo_dependencies.todo_id").
+		Joins("JOIN todos blocker ON blocker.id = todo_dependencies.blocked_by_id").
+		Where("todo_dependencies.user_id = ? AND todo_dependencies.todo_id IN ?", userID, todoIDs).
+		Where("blocker.completed = ? AND blocker.deleted_at IS NULL", false).
+		Pluck("todo_dependencies.todo_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"todo-api-backend/internal/model"
+)
+
+// todoHistoryRepository implements the TodoHistoryRepository interface
+type todoHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoHistoryRepository creates a new todo history repository instance
+func NewTodoHistoryRepository(db *gorm.DB) TodoHistoryRepository {
+	return &todoHistoryRepository{
+		db: db,
+	}
+}
+
+// Create appends a history entry
+func (r *todoHistoryRepository) Create(ctx context.Context, entry *model.TodoHistory) error {
+	if err := conn(ctx, r.db).Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByTodoID retrieves the history of a todo in chronological order, ensuring it belongs to the specified user
+func (r *todoHistoryRepository) GetByTodoID(ctx context.Context, todoID uint, userID uint) ([]*model.TodoHistory, error) {
+	var entries []*model.TodoHistory
+	err := conn(ctx, r.db).
+		Where("todo_id = ? AND user_id = ?", todoID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetByActionSince retrieves the history entries of a user with the given action recorded after since
+func (r *todoHistoryRepository) GetByActionSince(ctx context.Context, userID uint, action string, since time.Time) ([]*model.TodoHistory, error) {
+	var entries []*model.TodoHistory
+	err := conn(ctx, r.db).
+		Where("user_id = ? AND action = ? AND created_at > ?", userID, action, since).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"todo-api-backend/internal/model"
+)
+
+// IdempotencyRepository defines the interface for idempotency key storage
+type IdempotencyRepository interface {
+	// Create stores a new record. It reports false without an error when the
+	// user already has a record with the same key.
+	Create(ctx context.Context, record *model.IdempotencyRecord) (bool, error)
+
+	// GetByKey retrieves the record of a user's idempotency key
+	GetByKey(ctx context.Context, userID uint, key string) (*model.IdempotencyRecord, error)
+
+	// Update stores the response of a record
+	Update(ctx context.Context, record *model.IdempotencyRecord) error
+
+	// Renew records that the request of a reserved key is still running
+	Renew(ctx context.Context, id uint, now time.Time) error
+
+	// Delete removes a record so that its key can be used again
+	Delete(ctx context.Context, id uint) error
+
+	// DeleteExpired removes every record that expired before now and returns the number removed
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// idempotencyRepository implements the IdempotencyRepository interface
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository instance
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+// Create stores a new record unless the user already has one with the same key
+func (r *idempotencyRepository) Create(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	result := conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+			DoNothing: true,
+		}).
+		Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByKey retrieves the record of a user's idempotency key
+func (r *idempotencyRepository) GetByKey(ctx context.Context, userID uint, key string) (*model.IdempotencyRecord, error) {
+	var record model.IdempotencyRecord
+	err := conn(ctx, r.db).Where("user_id = ? AND key = ?", userID, key).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Update stores the response of a record
+func (r *idempotencyRepository) Update(ctx context.Context, record *model.IdempotencyRecord) error {
+	return conn(ctx, r.db).Model(record).
+		Select("StatusCode", "Headers", "Body").
+		Updates(record).Error
+}
+
+// Renew moves the renewal time of a record that has no response yet
+func (r *idempotencyRepository) Renew(ctx context.Context, id uint, now time.Time) error {
+	return conn(ctx, r.db).Model(&model.IdempotencyRecord{}).
+		Where("id = ? AND status_code = 0", id).
+		Update("renewed_at", now).Error
+}
+
+// Delete removes a record so that its key can be used again
+func (r *idempotencyRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&model.IdempotencyRecord{}, id).Error
+}
+
+// DeleteExpired removes every record that expired before now
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at < ?", now).Delete(&model.IdempotencyRecord{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -2,51 +2,153 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	"todo-api-backend/internal/model"
 	"gorm.io/gorm"
+	"todo-api-backend/internal/model"
 )
 
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	// Create creates a new user in the database
 	Create(ctx context.Context, user *model.User) error
-	
+
 	// GetByEmail retrieves a user by email address
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
-	
+
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id uint) (*model.User, error)
+
+	// Update updates an existing user
+	Update(ctx context.Context, user *model.User) error
+
+	// GetWithAutoArchive retrieves all users that have an auto-archive policy enabled
+	GetWithAutoArchive(ctx context.Context) ([]*model.User, error)
 }
 
 // TodoRepository defines the interface for todo data operations
 type TodoRepository interface {
 	// Create creates a new todo in the database
 	Create(ctx context.Context, todo *model.Todo) error
-	
+
 	// GetByID retrieves a todo by ID, ensuring it belongs to the specified user
 	GetByID(ctx context.Context, id uint, userID uint) (*model.Todo, error)
-	
+
 	// GetByUserID retrieves all todos belonging to a specific user
 	GetByUserID(ctx context.Context, userID uint) ([]*model.Todo, error)
-	
-	// Update updates an existing todo
+
+	// List retrieves the todos of a specific user matching the filter
+	List(ctx context.Context, userID uint, filter *model.TodoFilter) ([]*model.Todo, error)
+
+	// Update updates an existing todo and increments its version. It returns
+	// ErrVersionConflict if the todo was changed since it was loaded.
 	Update(ctx context.Context, todo *model.Todo) error
-	
-	// Delete deletes a todo by ID, ensuring it belongs to the specified user
+
+	// Delete soft-deletes a todo by ID, moving it to the user's trash
 	Delete(ctx context.Context, id uint, userID uint) error
+
+	// GetTrashedByID retrieves a soft-deleted todo by ID, ensuring it belongs to the specified user
+	GetTrashedByID(ctx context.Context, id uint, userID uint) (*model.Todo, error)
+
+	// GetTrashByUserID retrieves all soft-deleted todos belonging to a specific user
+	GetTrashByUserID(ctx context.Context, userID uint) ([]*model.Todo, error)
+
+	// Restore moves a soft-deleted todo out of the trash
+	Restore(ctx context.Context, id uint, userID uint) error
+
+	// Purge permanently deletes a soft-deleted todo
+	Purge(ctx context.Context, id uint, userID uint) error
+
+	// PurgeTrashByUserID permanently deletes all soft-deleted todos of a user
+	PurgeTrashByUserID(ctx context.Context, userID uint) (int64, error)
+
+	// PurgeDeletedBefore permanently deletes all todos soft-deleted before the cutoff
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+
+	// ArchiveCompletedBefore archives all completed todos of a user that were completed
+	// before the cutoff and returns the IDs of the archived todos
+	ArchiveCompletedBefore(ctx context.Context, userID uint, cutoff time.Time, archivedAt time.Time) ([]uint, error)
+
+	// ListChangedSince retrieves the todos of a user, including soft-deleted ones,
+	// that were updated or deleted after since
+	ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]*model.Todo, error)
+
+	// StreamByUserID calls fn for every todo of a user matching the filter, oldest
+	// first, loading them in batches so the full list is never held in memory
+	StreamByUserID(ctx context.Context, userID uint, filter *model.TodoFilter, fn func(*model.Todo) error) error
+}
+
+// TodoHistoryRepository defines the interface for the append-only todo history
+type TodoHistoryRepository interface {
+	// Create appends a history entry
+	Create(ctx context.Context, entry *model.TodoHistory) error
+
+	// GetByTodoID retrieves the history of a todo, ensuring it belongs to the specified user
+	GetByTodoID(ctx context.Context, todoID uint, userID uint) ([]*model.TodoHistory, error)
+
+	// GetByActionSince retrieves the history entries of a user with the given action recorded after since
+	GetByActionSince(ctx context.Context, userID uint, action string, since time.Time) ([]*model.TodoHistory, error)
+}
+
+// TodoSearcher defines the interface for full-text search over todos
+type TodoSearcher interface {
+	// Search retrieves the todos of a user matching the query, most relevant first
+	Search(ctx context.Context, userID uint, query *model.TodoSearchQuery) ([]*model.TodoSearchResult, error)
+}
+
+// SecurityEventRepository defines the interface for the append-only security audit log
+type SecurityEventRepository interface {
+	// Create appends a security event
+	Create(ctx context.Context, event *model.SecurityEvent) error
+
+	// List retrieves the security events matching the filter, newest first
+	List(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error)
 }
 
 // Repositories holds all repository interfaces for dependency injection
 type Repositories struct {
-	User UserRepository
-	Todo TodoRepository
+	User           UserRepository
+	Todo           TodoRepository
+	History        TodoHistoryRepository
+	Audit          SecurityEventRepository
+	Search         TodoSearcher
+	Idempotency    IdempotencyRepository
+	Webhook        WebhookRepository
+	Delivery       WebhookDeliveryRepository
+	Outbox         OutboxRepository
+	Calendar       CalendarFeedRepository
+	CalDAVPassword CalDAVPasswordRepository
+	CalDAVResource CalDAVResourceRepository
+	Template       TemplateRepository
+	Dependency     DependencyRepository
+	List           ListRepository
+	CustomField    CustomFieldRepository
+	Comment        CommentRepository
+	Attachment     AttachmentRepository
+	Tx             Transactor
 }
 
 // NewRepositories creates a new instance of Repositories with all implementations
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
-		User: NewUserRepository(db),
-		Todo: NewTodoRepository(db),
+		User:           NewUserRepository(db),
+		Todo:           NewTodoRepository(db),
+		History:        NewTodoHistoryRepository(db),
+		Audit:          NewSecurityEventRepository(db),
+		Search:         NewTodoSearcher(db),
+		Idempotency:    NewIdempotencyRepository(db),
+		Webhook:        NewWebhookRepository(db),
+		Delivery:       NewWebhookDeliveryRepository(db),
+		Outbox:         NewOutboxRepository(db),
+		Calendar:       NewCalendarFeedRepository(db),
+		CalDAVPassword: NewCalDAVPasswordRepository(db),
+		CalDAVResource: NewCalDAVResourceRepository(db),
+		Template:       NewTemplateRepository(db),
+		Dependency:     NewDependencyRepository(db),
+		List:           NewListRepository(db),
+		CustomField:    NewCustomFieldRepository(db),
+		Comment:        NewCommentRepository(db),
+		Attachment:     NewAttachmentRepository(db),
+		Tx:             NewTransactor(db),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+)
+
+// ListRepository defines the interface for todo lists and their workflows
+type ListRepository interface {
+	// Create stores a new list
+	Create(ctx context.Context, list *model.TodoList) error
+
+	// GetByID retrieves a list by ID, ensuring it belongs to the specified user
+	GetByID(ctx context.Context, id uint, userID uint) (*model.TodoList, error)
+
+	// ListByUserID retrieves all lists of a user, oldest first
+	ListByUserID(ctx context.Context, userID uint) ([]*model.TodoList, error)
+
+	// CountByUserID returns the number of lists of a user
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+
+	// Update stores the name and workflow of a list
+	Update(ctx context.Context, list *model.TodoList) error
+
+	// Delete removes a list and its custom fields, ensuring it belongs to the specified user
+	Delete(ctx context.Context, id uint, userID uint) error
+
+	// StatesInUse returns the states of the todos of a list, including trashed todos
+	StatesInUse(ctx context.Context, listID uint) ([]string, error)
+
+	// DetachTodos takes every todo of a list, including trashed todos, out of
+	// the list and removes their values of the custom fields of the list
+	DetachTodos(ctx context.Context, listID uint) error
+
+	// ListTodos retrieves the todos of a list that are neither archived nor trashed, oldest first
+	ListTodos(ctx context.Context, userID uint, listID uint) ([]*model.Todo, error)
+}
+
+// listRepository implements the ListRepository interface
+type listRepository struct {
+	db *gorm.DB
+}
+
+// NewListRepository creates a new list repository instance
+func NewListRepository(db *gorm.DB) ListRepository {
+	return &listRepository{
+		db: db,
+	}
+}
+
+// Create stores a new list
+func (r *listRepository) Create(ctx context.Context, list *model.TodoList) error {
+	return conn(ctx, r.db).Create(list).Error
+}
+
+// GetByID retrieves a list by ID, ensuring it belongs to the specified user
+func (r *listRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.TodoList, error) {
+	var list model.TodoList
+	err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListByUserID retrieves all lists of a user, oldest first
+func (r *listRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.TodoList, error) {
+	var lists []*model.TodoList
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id ASC").Find(&lists).Error
+	return lists, err
+}
+
+// CountByUserID returns the number of lists of a user
+func (r *listRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.TodoList{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Update stores the name and workflow of a list
+func (r *listRepository) Update(ctx context.Context, list *model.TodoList) error {
+	return conn(ctx, r.db).Model(list).
+		Select("Name", "Workflow").
+		Updates(list).Error
+}
+
+// Delete removes a list and its custom fields, ensuring it belongs to the specified user
+func (r *listRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	err := conn(ctx, r.db).Where("list_id = ? AND user_id = ?", id, userID).Delete(&model.CustomField{}).Error
+	if err != nil {
+		return err
+	}
+
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&model.TodoList{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// StatesInUse returns the states of the todos of a list, including trashed todos
+func (r *listRepository) StatesInUse(ctx context.Context, listID uint) ([]string, error) {
+	var states []string
+	err := conn(ctx, r.db).Unscoped().Model(&model.Todo{}).
+		Where("list_id = ?", listID).
+		Distinct("state").
+		Pluck("state", &states).Error
+	return states, err
+}
+
+// DetachTodos takes every todo of a list out of the list, dropping the values
+// of the custom fields of the list, and bumps the version so that cached
+// representations and sync clients see the change
+func (r *listRepository) DetachTodos(ctx context.Context, listID uint) error {
+	return conn(ctx, r.db).Unscoped().Model(&model.Todo{}).
+		Where("list_id = ?", listID).
+		Updates(map[string]interface{}{
+			"list_id":       nil,
+			"state":         "",
+			"custom_fields": gorm.Expr("custom_fields - ARRAY(SELECT key FROM custom_fields WHERE list_id = ?)::text[]", listID),
+			"version":       gorm.Expr("version + 1"),
+		}).Error
+}
+
+// ListTodos retrieves the todos of a list that are neither archived nor trashed, oldest first
+func (r *listRepository) ListTodos(ctx context.Context, userID uint, listID uint) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := conn(ctx, r.db).
+		Where("user_id = ? AND list_id = ? AND archived_at IS NULL", userID, listID).
+		Order("created_at ASC, id ASC").
+		Find(&todos).Error
+	return todos, err
+}
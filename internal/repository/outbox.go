@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+)
+
+// OutboxRepository defines the interface for the transactional outbox of domain events
+type OutboxRepository interface {
+	// Append writes a message to the outbox. Called with the context of a
+	// transaction, the message is only stored if the transaction commits.
+	Append(ctx context.Context, message *model.OutboxMessage) error
+
+	// ClaimNext reserves up to limit messages that are due at now and are the
+	// oldest unpublished message of their aggregate, moving their next attempt
+	// to leaseUntil so that other relays skip them. Because a message is only
+	// claimed once every earlier message of its aggregate has been published,
+	// messages are published in order per aggregate. Messages are returned
+	// oldest first.
+	ClaimNext(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.OutboxMessage, error)
+
+	// MarkPublished records that a message was published
+	MarkPublished(ctx context.Context, id uint, publishedAt time.Time) error
+
+	// MarkFailed stores the attempts, error and next attempt of a message that could not be published
+	MarkFailed(ctx context.Context, message *model.OutboxMessage) error
+
+	// DeletePublishedBefore removes messages published before the cutoff
+	DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// outboxRepository implements the OutboxRepository interface
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository instance
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+// Append writes a message to the outbox
+func (r *outboxRepository) Append(ctx context.Context, message *model.OutboxMessage) error {
+	return conn(ctx, r.db).Create(message).Error
+}
+
+// ClaimNext reserves the due head message of up to limit aggregates until leaseUntil
+func (r *outboxRepository) ClaimNext(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.OutboxMessage, error) {
+	var messages []*model.OutboxMessage
+	err := conn(ctx, r.db).Raw(`
+		UPDATE outbox_messages SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT m.id FROM outbox_messages m
+			WHERE m.published_at IS NULL AND m.next_attempt_at <= ?
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_messages p
+				WHERE p.aggregate_type = m.aggregate_type AND p.aggregate_id = m.aggregate_id
+				AND p.published_at IS NULL AND p.id < m.id
+			)
+			ORDER BY m.id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, now, limit,
+	).Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not preserve the order of the subquery
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// MarkPublished records that a message was published
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uint, publishedAt time.Time) error {
+	return conn(ctx, r.db).Model(&model.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"published_at": publishedAt,
+			"last_error":   "",
+		}).Error
+}
+
+// MarkFailed stores the attempts, error and next attempt of a message that could not be published
+func (r *outboxRepository) MarkFailed(ctx context.Context, message *model.OutboxMessage) error {
+	return conn(ctx, r.db).Model(message).
+		Select("Attempts", "LastError", "NextAttemptAt").
+		Updates(message).Error
+}
+
+// DeletePublishedBefore removes messages published before the cutoff
+func (r *outboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("published_at IS NOT NULL AND published_at < ?", cutoff).
+		Delete(&model.OutboxMessage{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"todo-api-backend/internal/model"
+)
+
+// Markers wrapped around matches by ts_headline; they are replaced with
+// <mark> tags after the headline has been HTML-escaped
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+const (
+	// titleHeadlineOptions returns the whole title with all matches highlighted
+	titleHeadlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=TRUE`
+	// snippetHeadlineOptions returns up to two fragments of the description around the matches
+	snippetHeadlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+
+	// snippetMaxRunes and snippetLeadRunes size the snippets built by the LIKE fallback
+	snippetMaxRunes  = 200
+	snippetLeadRunes = 60
+)
+
+// searchHit is a ranked search match before its todo is loaded
+type searchHit struct {
+	ID             uint
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// NewTodoSearcher creates the todo searcher best suited to the database:
+// PostgreSQL full-text search when available, LIKE matching otherwise
+func NewTodoSearcher(db *gorm.DB) TodoSearcher {
+	if db != nil && db.Dialector != nil && db.Dialector.Name() == "postgres" {
+		return &postgresTodoSearcher{db: db}
+	}
+	return &likeTodoSearcher{db: db}
+}
+
+// postgresTodoSearcher implements TodoSearcher using the todos.search_vector tsvector column
+type postgresTodoSearcher struct {
+	db *gorm.DB
+}
+
+// Search ranks the user's todos against the query and highlights the matches
+func (r *postgresTodoSearcher) Search(ctx context.Context, userID uint, query *model.TodoSearchQuery) ([]*model.TodoSearchResult, error) {
+	tsquery := buildTSQuery(query.Terms)
+	if tsquery == "" {
+		return []*model.TodoSearchResult{}, nil
+	}
+
+	// Rank and limit first so headlines are only generated for the returned rows
+	ranked := conn(ctx, r.db).
+		Table("todos, to_tsquery('english', ?) AS q(query)", tsquery).
+		Select("todos.id, todos.title, todos.description, todos.updated_at, q.query, ts_rank(todos.search_vector, q.query) AS rank").
+		Where("todos.user_id = ? AND todos.deleted_at IS NULL AND todos.search_vector @@ q.query", userID)
+	ranked = applyArchivedFilter(ranked, query.Archived).
+		Order("rank DESC, todos.updated_at DESC, todos.id DESC").
+		Limit(query.Limit)
+
+	var hits []searchHit
+	err := conn(ctx, r.db).
+		Table("(?) AS ranked", ranked).
+		Select(
+			"ranked.id, ranked.rank, "+
+				"ts_headline('english', ranked.title, ranked.query, ?) AS title_highlight, "+
+				"ts_headline('english', COALESCE(ranked.description, ''), ranked.query, ?) AS snippet",
+			titleHeadlineOptions, snippetHeadlineOptions,
+		).
+		Order("ranked.rank DESC, ranked.updated_at DESC, ranked.id DESC").
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hits {
+		hits[i].TitleHighlight = renderHeadline(hits[i].TitleHighlight)
+		hits[i].Snippet = renderHeadline(hits[i].Snippet)
+	}
+
+	return loadSearchResults(ctx, r.db, hits, nil)
+}
+
+// likeTodoSearcher implements TodoSearcher with case-insensitive LIKE matching
+// for databases without full-text search
+type likeTodoSearcher struct {
+	db *gorm.DB
+}
+
+// Search matches every term against the title or description of the user's todos
+func (r *likeTodoSearcher) Search(ctx context.Context, userID uint, query *model.TodoSearchQuery) ([]*model.TodoSearchResult, error) {
+	var conditions, rankParts []string
+	var conditionArgs, rankArgs []interface{}
+	for _, term := range query.Terms {
+		pattern := likePattern(term)
+		if pattern == "" {
+			continue
+		}
+		conditions = append(conditions, "(LOWER(title) LIKE ? OR LOWER(COALESCE(description, '')) LIKE ?)")
+		conditionArgs = append(conditionArgs, pattern, pattern)
+		// Title matches weigh more than description matches, as in the tsvector weights
+		rankParts = append(rankParts, "(CASE WHEN LOWER(title) LIKE ? THEN 1.0 ELSE 0 END + CASE WHEN LOWER(COALESCE(description, '')) LIKE ? THEN 0.4 ELSE 0 END)")
+		rankArgs = append(rankArgs, pattern, pattern)
+	}
+	if len(conditions) == 0 {
+		return []*model.TodoSearchResult{}, nil
+	}
+
+	db := conn(ctx, r.db).
+		Table("todos").
+		Select("id, "+strings.Join(rankParts, " + ")+" AS rank", rankArgs...).
+		Where("user_id = ? AND deleted_at IS NULL", userID).
+		Where(strings.Join(conditions, " AND "), conditionArgs...)
+	db = applyArchivedFilter(db, query.Archived)
+
+	var hits []searchHit
+	if err := db.Order("rank DESC, updated_at DESC, id DESC").Limit(query.Limit).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+
+	return loadSearchResults(ctx, r.db, hits, searchPattern(query.Terms))
+}
+
+// loadSearchResults loads the todos of the hits and returns them in hit order.
+// When highlight is set the highlights are computed from the loaded todos.
+func loadSearchResults(ctx context.Context, db *gorm.DB, hits []searchHit, highlight *regexp.Regexp) ([]*model.TodoSearchResult, error) {
+	results := make([]*model.TodoSearchResult, 0, len(hits))
+	if len(hits) == 0 {
+		return results, nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	var todos []*model.Todo
+	if err := conn(ctx, db).Where("id IN ?", ids).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Todo, len(todos))
+	for _, todo := range todos {
+		byID[todo.ID] = todo
+	}
+
+	for _, hit := range hits {
+		todo, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		result := &model.TodoSearchResult{
+			Todo:           todo,
+			Rank:           hit.Rank,
+			TitleHighlight: hit.TitleHighlight,
+			Snippet:        hit.Snippet,
+		}
+		if highlight != nil {
+			result.TitleHighlight = highlightHTML(todo.Title, highlight, 0)
+			result.Snippet = highlightHTML(todo.Description, highlight, snippetMaxRunes)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// searchWords returns the words of a term reduced to letters and digits, which
+// keeps them free of tsquery operators and LIKE wildcards
+func searchWords(term model.SearchTerm) []string {
+	var words []string
+	for _, word := range term.Words {
+		words = append(words, strings.FieldsFunc(strings.ToLower(word), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return words
+}
+
+// buildTSQuery converts search terms to a to_tsquery expression: phrases use the
+// followed-by operator, prefix terms use :* and all terms must match
+func buildTSQuery(terms []model.SearchTerm) string {
+	var parts []string
+	for _, term := range terms {
+		words := searchWords(term)
+		if len(words) == 0 {
+			continue
+		}
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+		part := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// likePattern converts a search term to a lowercase LIKE pattern
+func likePattern(term model.SearchTerm) string {
+	words := searchWords(term)
+	if len(words) == 0 {
+		return ""
+	}
+	return "%" + strings.Join(words, " ") + "%"
+}
+
+// searchPattern builds a case-insensitive expression matching any of the terms
+func searchPattern(terms []model.SearchTerm) *regexp.Regexp {
+	var alternatives []string
+	for _, term := range terms {
+		words := searchWords(term)
+		if len(words) == 0 {
+			continue
+		}
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		alternative := strings.Join(words, `\s+`)
+		if term.Prefix {
+			alternative += `[\p{L}\p{N}]*`
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+}
+
+// highlightHTML HTML-escapes text and wraps the matches of pattern in <mark> tags.
+// When maxRunes is positive and the text is longer, only a window around the first
+// match is returned.
+func highlightHTML(text string, pattern *regexp.Regexp, maxRunes int) string {
+	matches := pattern.FindAllStringIndex(text, -1)
+
+	start, end := 0, len(text)
+	if maxRunes > 0 && utf8.RuneCountInString(text) > maxRunes {
+		anchor := 0
+		if len(matches) > 0 {
+			anchor = matches[0][0]
+		}
+		start = moveRunes(text, anchor, -snippetLeadRunes)
+		end = moveRunes(text, start, maxRunes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	pos := start
+	for _, match := range matches {
+		from, to := match[0], match[1]
+		if to <= pos || from >= end {
+			continue
+		}
+		from = max(from, pos)
+		to = min(to, end)
+		b.WriteString(html.EscapeString(text[pos:from]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[from:to]))
+		b.WriteString("</mark>")
+		pos = to
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
+
+// moveRunes returns the byte offset n runes away from offset, clamped to the text
+func moveRunes(text string, offset, n int) int {
+	for ; n < 0 && offset > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:offset])
+		offset -= size
+	}
+	for ; n > 0 && offset < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// renderHeadline HTML-escapes a ts_headline result and turns its markers into <mark> tags
+func renderHeadline(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-api-backend/internal/model"
+)
+
+// TestNewTodoSearcher_Fallback verifies that databases without full-text search use LIKE matching
+func TestNewTodoSearcher_Fallback(t *testing.T) {
+	assert.IsType(t, &likeTodoSearcher{}, NewTodoSearcher(nil))
+}
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		terms    []model.SearchTerm
+		expected string
+	}{
+		{"single word", []model.SearchTerm{{Words: []string{"report"}}}, "report"},
+		{"all terms must match", []model.SearchTerm{{Words: []string{"call"}}, {Words: []string{"bob"}}}, "call & bob"},
+		{"phrase", []model.SearchTerm{{Words: []string{"quarterly", "report"}}}, "(quarterly <-> report)"},
+		{"prefix", []model.SearchTerm{{Words: []string{"rep"}, Prefix: true}}, "rep:*"},
+		{"phrase with prefix", []model.SearchTerm{{Words: []string{"quarterly", "rep"}, Prefix: true}}, "(quarterly <-> rep:*)"},
+		{"operators are stripped", []model.SearchTerm{{Words: []string{"b&o!b"}}, {Words: []string{"|:*"}}}, "(b <-> o <-> b)"},
+		{"no terms", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, buildTSQuery(tt.terms))
+		})
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	assert.Equal(t, "%quarterly report%", likePattern(model.SearchTerm{Words: []string{"Quarterly", "report"}}))
+	assert.Equal(t, "%rep%", likePattern(model.SearchTerm{Words: []string{"rep"}, Prefix: true}))
+	assert.Equal(t, "%100 off%", likePattern(model.SearchTerm{Words: []string{"100%", "_off"}}))
+	assert.Equal(t, "", likePattern(model.SearchTerm{Words: []string{"%_"}}))
+}
+
+func TestHighlightHTML(t *testing.T) {
+	pattern := searchPattern([]model.SearchTerm{
+		{Words: []string{"quarterly", "report"}},
+		{Words: []string{"bo"}, Prefix: true},
+	})
+
+	assert.Equal(t,
+		"Send the <mark>Quarterly  report</mark> to <mark>Bob</mark> &lt;asap&gt;",
+		highlightHTML("Send the Quarterly  report to Bob <asap>", pattern, 0))
+	assert.Equal(t, "No match &amp; nothing", highlightHTML("No match & nothing", pattern, 0))
+}
+
+func TestHighlightHTML_Snippet(t *testing.T) {
+	pattern := searchPattern([]model.SearchTerm{{Words: []string{"needle"}}})
+	padding := "lorem ipsum dolor sit amet "
+
+	text := ""
+	for i := 0; i < 10; i++ {
+		text += padding
+	}
+	text += "needle "
+	for i := 0; i < 10; i++ {
+		text += padding
+	}
+
+	snippet := highlightHTML(text, pattern, 100)
+
+	assert.Contains(t, snippet, "<mark>needle</mark>")
+	assert.Regexp(t, `^… `, snippet)
+	assert.Regexp(t, ` …$`, snippet)
+}
+
+func TestRenderHeadline(t *testing.T) {
+	headline := "Buy <b>" + highlightStart + "milk" + highlightStop + "</b> & eggs"
+
+	assert.Equal(t, "Buy &lt;b&gt;<mark>milk</mark>&lt;/b&gt; &amp; eggs", renderHeadline(headline))
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+)
+
+// TemplateRepository defines the interface for todo template storage
+type TemplateRepository interface {
+	// Create stores a new template
+	Create(ctx context.Context, template *model.TodoTemplate) error
+
+	// GetByID retrieves a template by ID, ensuring it belongs to the specified user
+	GetByID(ctx context.Context, id uint, userID uint) (*model.TodoTemplate, error)
+
+	// ListByUserID retrieves all templates of a user, oldest first
+	ListByUserID(ctx context.Context, userID uint) ([]*model.TodoTemplate, error)
+
+	// CountByUserID returns the number of templates of a user
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+
+	// Update stores the name, description and items of a template
+	Update(ctx context.Context, template *model.TodoTemplate) error
+
+	// Delete removes a template, ensuring it belongs to the specified user
+	Delete(ctx context.Context, id uint, userID uint) error
+}
+
+// templateRepository implements the TemplateRepository interface
+type templateRepository struct {
+	db *gorm.DB
+}
+
+// NewTemplateRepository creates a new template repository instance
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &templateRepository{
+		db: db,
+	}
+}
+
+// Create stores a new template
+func (r *templateRepository) Create(ctx context.Context, template *model.TodoTemplate) error {
+	return conn(ctx, r.db).Create(template).Error
+}
+
+// GetByID retrieves a template by ID, ensuring it belongs to the specified user
+func (r *templateRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.TodoTemplate, error) {
+	var template model.TodoTemplate
+	err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListByUserID retrieves all templates of a user, oldest first
+func (r *templateRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.TodoTemplate, error) {
+	var templates []*model.TodoTemplate
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id ASC").Find(&templates).Error
+	return templates, err
+}
+
+// CountByUserID returns the number of templates of a user
+func (r *templateRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.TodoTemplate{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Update stores the name, description and items of a template
+func (r *templateRepository) Update(ctx context.Context, template *model.TodoTemplate) error {
+	return conn(ctx, r.db).Model(template).
+		Select("Name", "Description", "Items").
+		Updates(template).Error
+}
+
+// Delete removes a template, ensuring it belongs to the specified user
+func (r *templateRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&model.TodoTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -3,11 +3,16 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
-	"todo-api-backend/internal/model"
 	"gorm.io/gorm"
+	"todo-api-backend/internal/model"
 )
 
+// ErrVersionConflict is returned by Update when the stored todo is no longer
+// at the version the caller loaded
+var ErrVersionConflict = errors.New("todo version conflict")
+
 // todoRepository implements the TodoRepository interface
 type todoRepository struct {
 	db *gorm.DB
@@ -22,7 +27,10 @@ func NewTodoRepository(db *gorm.DB) TodoRepository {
 
 // Create creates a new todo in the database
 func (r *todoRepository) Create(ctx context.Context, todo *model.Todo) error {
-	if err := r.db.WithContext(ctx).Create(todo).Error; err != nil {
+	if todo.Version == 0 {
+		todo.Version = 1
+	}
+	if err := conn(ctx, r.db).Create(todo).Error; err != nil {
 		return err
 	}
 	return nil
@@ -31,7 +39,7 @@ func (r *todoRepository) Create(ctx context.Context, todo *model.Todo) error {
 // GetByID retrieves a todo by ID, ensuring it belongs to the specified user
 func (r *todoRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
 	var todo model.Todo
-	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&todo).Error
+	err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&todo).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
@@ -44,25 +52,100 @@ func (r *todoRepository) GetByID(ctx context.Context, id uint, userID uint) (*mo
 // GetByUserID retrieves all todos belonging to a specific user
 func (r *todoRepository) GetByUserID(ctx context.Context, userID uint) ([]*model.Todo, error) {
 	var todos []*model.Todo
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&todos).Error
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC").Find(&todos).Error
 	if err != nil {
 		return nil, err
 	}
 	return todos, nil
 }
 
+// List retrieves the todos of a specific user matching the filter
+func (r *todoRepository) List(ctx context.Context, userID uint, filter *model.TodoFilter) ([]*model.Todo, error) {
+	query := conn(ctx, r.db).Where("user_id = ?", userID)
+
+	archived := ""
+	if filter != nil {
+		archived = filter.Archived
+		query = applyCustomFieldFilter(query, filter.CustomFields)
+	}
+	query = applyArchivedFilter(query, archived)
+
+	if filter != nil && filter.SortField != nil {
+		query = query.Order(customFieldOrder(filter.SortField))
+	} else {
+		query = query.Order("created_at DESC")
+	}
+
+	var todos []*model.Todo
+	if err := query.Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
 // Update updates an existing todo
 func (r *todoRepository) Update(ctx context.Context, todo *model.Todo) error {
-	err := r.db.WithContext(ctx).Save(todo).Error
-	if err != nil {
-		return err
+	// Only write over the version that was loaded, bumping it on success
+	version := todo.Version
+	todo.Version = version + 1
+
+	result := conn(ctx, r.db).Model(todo).
+		Where("version = ?", version).
+		Select("*").Omit("ID", "UserID", "CreatedAt", "User").
+		Updates(todo)
+	if result.Error != nil {
+		todo.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		todo.Version = version
+		return ErrVersionConflict
 	}
 	return nil
 }
 
-// Delete deletes a todo by ID, ensuring it belongs to the specified user
+// Delete soft-deletes a todo by ID, ensuring it belongs to the specified user
 func (r *todoRepository) Delete(ctx context.Context, id uint, userID uint) error {
-	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Todo{})
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Todo{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetTrashedByID retrieves a soft-deleted todo by ID, ensuring it belongs to the specified user
+func (r *todoRepository) GetTrashedByID(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	var todo model.Todo
+	err := conn(ctx, r.db).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&todo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// GetTrashByUserID retrieves all soft-deleted todos belonging to a specific user
+func (r *todoRepository) GetTrashByUserID(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := conn(ctx, r.db).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// Restore moves a soft-deleted todo out of the trash, ensuring it belongs to the specified user
+func (r *todoRepository) Restore(ctx context.Context, id uint, userID uint) error {
+	result := conn(ctx, r.db).Unscoped().Model(&model.Todo{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -70,4 +153,127 @@ func (r *todoRepository) Delete(ctx context.Context, id uint, userID uint) error
 		return gorm.ErrRecordNotFound
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Purge permanently deletes a soft-deleted todo, ensuring it belongs to the specified user
+func (r *todoRepository) Purge(ctx context.Context, id uint, userID uint) error {
+	result := conn(ctx, r.db).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		Delete(&model.Todo{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeTrashByUserID permanently deletes all soft-deleted todos belonging to a specific user
+func (r *todoRepository) PurgeTrashByUserID(ctx context.Context, userID uint) (int64, error) {
+	result := conn(ctx, r.db).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Delete(&model.Todo{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeDeletedBefore permanently deletes all todos that were soft-deleted before the cutoff
+func (r *todoRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Delete(&model.Todo{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ListChangedSince retrieves the todos of a user, including soft-deleted ones, that were
+// updated or deleted after since, oldest change first
+func (r *todoRepository) ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := conn(ctx, r.db).Unscoped().
+		Where("user_id = ? AND (updated_at > ? OR deleted_at > ?)", userID, since, since).
+		Order("COALESCE(deleted_at, updated_at) ASC, id ASC").
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// ArchiveCompletedBefore archives all completed todos of a user that were completed before the cutoff
+// and returns the IDs of the archived todos. Todos completed before completion times were tracked
+// fall back to their last update time.
+func (r *todoRepository) ArchiveCompletedBefore(ctx context.Context, userID uint, cutoff time.Time, archivedAt time.Time) ([]uint, error) {
+	var ids []uint
+	err := conn(ctx, r.db).Model(&model.Todo{}).
+		Where("user_id = ? AND completed = ? AND archived_at IS NULL", userID, true).
+		Where("COALESCE(completed_at, updated_at) < ?", cutoff).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	err = conn(ctx, r.db).Model(&model.Todo{}).
+		Where("id IN ? AND archived_at IS NULL", ids).
+		Updates(map[string]interface{}{"archived_at": archivedAt, "version": gorm.Expr("version + 1")}).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// streamBatchSize is the number of todos StreamByUserID loads per query
+const streamBatchSize = 500
+
+// StreamByUserID calls fn for every todo of a user matching the filter, oldest first.
+// Todos are loaded in batches using keyset pagination on the ID, so no connection is
+// held open while fn runs and memory use does not grow with the number of todos.
+func (r *todoRepository) StreamByUserID(ctx context.Context, userID uint, filter *model.TodoFilter, fn func(*model.Todo) error) error {
+	archived := ""
+	if filter != nil {
+		archived = filter.Archived
+	}
+
+	var lastID uint
+	for {
+		query := conn(ctx, r.db).Where("user_id = ? AND id > ?", userID, lastID)
+		query = applyArchivedFilter(query, archived)
+
+		var todos []*model.Todo
+		if err := query.Order("id ASC").Limit(streamBatchSize).Find(&todos).Error; err != nil {
+			return err
+		}
+
+		for _, todo := range todos {
+			if err := fn(todo); err != nil {
+				return err
+			}
+			lastID = todo.ID
+		}
+
+		if len(todos) < streamBatchSize {
+			return nil
+		}
+	}
+}
+
+// applyArchivedFilter restricts a todo query according to the archived filter value
+func applyArchivedFilter(query *gorm.DB, archived string) *gorm.DB {
+	switch archived {
+	case model.ArchivedOnly:
+		return query.Where("archived_at IS NOT NULL")
+	case model.ArchivedInclude:
+		// No archive restriction
+		return query
+	default:
+		return query.Where("archived_at IS NULL")
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
+// Transactor runs functions inside a database transaction
+type Transactor interface {
+	// WithinTransaction runs fn in a transaction. Repository calls made with the
+	// context passed to fn take part in the transaction; it is committed when fn
+	// returns nil and rolled back otherwise. Nested calls run in a savepoint of the
+	// outer transaction, so a failing nested call only rolls back its own changes.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// gormTransactor implements the Transactor interface using GORM transactions
+type gormTransactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new transactor instance
+func NewTransactor(db *gorm.DB) Transactor {
+	return &gormTransactor{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn inside a database transaction
+func (t *gormTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Nest inside the transaction that is already in progress using a savepoint
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.Transaction(func(nested *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, nested))
+		})
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction bound to the context, falling back to db
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
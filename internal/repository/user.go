@@ -22,7 +22,7 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 
 // Create creates a new user in the database
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
-	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+	if err := conn(ctx, r.db).Create(user).Error; err != nil {
 		return err
 	}
 	return nil
@@ -31,7 +31,7 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 // GetByEmail retrieves a user by email address
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
@@ -44,7 +44,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).First(&user, id).Error
+	err := conn(ctx, r.db).First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
@@ -52,4 +52,22 @@ func (r *userRepository) GetByID(ctx context.Context, id uint) (*model.User, err
 		return nil, err
 	}
 	return &user, nil
-}
\ No newline at end of file
+}
+
+// Update updates an existing user
+func (r *userRepository) Update(ctx context.Context, user *model.User) error {
+	if err := conn(ctx, r.db).Save(user).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetWithAutoArchive retrieves all users that have an auto-archive policy enabled
+func (r *userRepository) GetWithAutoArchive(ctx context.Context) ([]*model.User, error) {
+	var users []*model.User
+	err := conn(ctx, r.db).Where("auto_archive_days > 0").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+)
+
+// WebhookRepository defines the interface for webhook subscription storage
+type WebhookRepository interface {
+	// Create stores a new webhook
+	Create(ctx context.Context, webhook *model.Webhook) error
+
+	// GetByID retrieves a webhook by ID, ensuring it belongs to the specified user
+	GetByID(ctx context.Context, id uint, userID uint) (*model.Webhook, error)
+
+	// GetByIDForDelivery retrieves a webhook by ID regardless of its owner
+	GetByIDForDelivery(ctx context.Context, id uint) (*model.Webhook, error)
+
+	// ListByUserID retrieves all webhooks of a user, oldest first
+	ListByUserID(ctx context.Context, userID uint) ([]*model.Webhook, error)
+
+	// CountByUserID returns the number of webhooks of a user
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+
+	// ListSubscribed retrieves the active webhooks of a user subscribed to an event type
+	ListSubscribed(ctx context.Context, userID uint, eventType string) ([]*model.Webhook, error)
+
+	// Update stores the URL, events, state and failure count of a webhook
+	Update(ctx context.Context, webhook *model.Webhook) error
+
+	// Delete removes a webhook and its delivery log
+	Delete(ctx context.Context, id uint, userID uint) error
+
+	// RecordSuccess resets the consecutive failure count of a webhook
+	RecordSuccess(ctx context.Context, id uint) error
+
+	// RecordFailure increments the consecutive failure count of a webhook and
+	// disables it once the count reaches threshold. It reports whether this
+	// failure disabled the webhook.
+	RecordFailure(ctx context.Context, id uint, threshold int, reason string, now time.Time) (bool, error)
+}
+
+// WebhookDeliveryRepository defines the interface for the webhook delivery queue and log
+type WebhookDeliveryRepository interface {
+	// Create queues a delivery
+	Create(ctx context.Context, delivery *model.WebhookDelivery) error
+
+	// ClaimDue reserves up to limit pending deliveries that are due at now by
+	// moving their next attempt to leaseUntil, so that other dispatchers skip
+	// them while they are attempted. Deliveries are returned oldest first.
+	ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.WebhookDelivery, error)
+
+	// Update stores the outcome of a delivery attempt
+	Update(ctx context.Context, delivery *model.WebhookDelivery) error
+
+	// ListByWebhookID retrieves the most recent deliveries of a webhook, newest first
+	ListByWebhookID(ctx context.Context, webhookID uint, limit int) ([]*model.WebhookDelivery, error)
+
+	// DeleteFinishedBefore removes succeeded and failed deliveries created before the cutoff
+	DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// webhookRepository implements the WebhookRepository interface
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository instance
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// Create stores a new webhook
+func (r *webhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	return conn(ctx, r.db).Create(webhook).Error
+}
+
+// GetByID retrieves a webhook by ID, ensuring it belongs to the specified user
+func (r *webhookRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.Webhook, error) {
+	var webhook model.Webhook
+	err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&webhook).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// GetByIDForDelivery retrieves a webhook by ID regardless of its owner
+func (r *webhookRepository) GetByIDForDelivery(ctx context.Context, id uint) (*model.Webhook, error) {
+	var webhook model.Webhook
+	err := conn(ctx, r.db).First(&webhook, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListByUserID retrieves all webhooks of a user, oldest first
+func (r *webhookRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// CountByUserID returns the number of webhooks of a user
+func (r *webhookRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Webhook{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ListSubscribed retrieves the active webhooks of a user subscribed to an event type
+func (r *webhookRepository) ListSubscribed(ctx context.Context, userID uint, eventType string) ([]*model.Webhook, error) {
+	events, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	var webhooks []*model.Webhook
+	err = conn(ctx, r.db).
+		Where("user_id = ? AND active = ? AND events @> ?::jsonb", userID, true, string(events)).
+		Order("id ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// Update stores the URL, events, state and failure count of a webhook
+func (r *webhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	return conn(ctx, r.db).Model(webhook).
+		Select("URL", "Events", "Active", "FailureCount", "DisabledAt", "DisabledReason").
+		Updates(webhook).Error
+}
+
+// Delete removes a webhook and its delivery log
+func (r *webhookRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Webhook{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RecordSuccess resets the consecutive failure count of a webhook
+func (r *webhookRepository) RecordSuccess(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Model(&model.Webhook{}).
+		Where("id = ? AND failure_count <> 0", id).
+		Update("failure_count", 0).Error
+}
+
+// RecordFailure increments the consecutive failure count of a webhook and disables it at threshold
+func (r *webhookRepository) RecordFailure(ctx context.Context, id uint, threshold int, reason string, now time.Time) (bool, error) {
+	err := conn(ctx, r.db).Model(&model.Webhook{}).
+		Where("id = ?", id).
+		Update("failure_count", gorm.Expr("failure_count + 1")).Error
+	if err != nil || threshold <= 0 {
+		return false, err
+	}
+
+	result := conn(ctx, r.db).Model(&model.Webhook{}).
+		Where("id = ? AND active = ? AND failure_count >= ?", id, true, threshold).
+		Updates(map[string]interface{}{
+			"active":          false,
+			"disabled_at":     now,
+			"disabled_reason": reason,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// webhookDeliveryRepository implements the WebhookDeliveryRepository interface
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository instance
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		db: db,
+	}
+}
+
+// Create queues a delivery
+func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return conn(ctx, r.db).Create(delivery).Error
+}
+
+// ClaimDue reserves up to limit due deliveries until leaseUntil
+func (r *webhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := conn(ctx, r.db).Raw(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, model.WebhookDeliveryPending, now, limit,
+	).Scan(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not preserve the order of the subquery
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	return deliveries, nil
+}
+
+// Update stores the outcome of a delivery attempt
+func (r *webhookDeliveryRepository) Update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return conn(ctx, r.db).Model(delivery).
+		Select("Status", "Attempts", "ResponseCode", "LastError", "NextAttemptAt", "LastAttemptAt").
+		Updates(delivery).Error
+}
+
+// ListByWebhookID retrieves the most recent deliveries of a webhook, newest first
+func (r *webhookDeliveryRepository) ListByWebhookID(ctx context.Context, webhookID uint, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := conn(ctx, r.db).
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// DeleteFinishedBefore removes succeeded and failed deliveries created before the cutoff
+func (r *webhookDeliveryRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("status <> ? AND created_at < ?", model.WebhookDeliveryPending, cutoff).
+		Delete(&model.WebhookDelivery{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,410 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/internal/storage"
+)
+
+const (
+	// Limits applied when AttachmentLimits leaves them unset
+	defaultAttachmentMaxSize = 10 << 20
+	defaultAttachmentQuota   = 100 << 20
+	defaultAttachmentURLTTL  = 15 * time.Minute
+
+	// sniffLength is how many leading bytes are inspected to detect the MIME type
+	sniffLength = 512
+
+	// maxFilenameLength is the longest filename kept, in bytes
+	maxFilenameLength = 255
+)
+
+// DefaultAttachmentTypes are the MIME types attachments may have unless
+// AttachmentLimits lists others
+var DefaultAttachmentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"}
+
+var (
+	ErrAttachmentsUnavailable    = errors.New("attachments are not available")
+	ErrAttachmentNotFound        = errors.New("attachment not found")
+	ErrAttachmentTooLarge        = errors.New("attachment is too large")
+	ErrEmptyAttachment           = errors.New("attachment is empty")
+	ErrUnsupportedAttachmentType = errors.New("attachment type is not allowed")
+	ErrAttachmentQuotaExceeded   = errors.New("attachment quota exceeded")
+	ErrInvalidDownloadSignature  = errors.New("invalid download signature")
+	ErrDownloadExpired           = errors.New("download link has expired")
+)
+
+// AttachmentLimits configures the checks applied to uploaded attachments and
+// the signed URLs their contents are downloaded from
+type AttachmentLimits struct {
+	// MaxSize is the largest attachment accepted, in bytes
+	MaxSize int64
+	// Quota is the total size of the attachments a user may store, in bytes
+	Quota int64
+	// AllowedTypes are the MIME types attachments may have, as detected from their contents
+	AllowedTypes []string
+	// URLTTL is how long a download URL stays valid
+	URLTTL time.Duration
+	// URLSecret signs download URLs; a random secret is used if empty, which
+	// invalidates URLs on restart and between server instances
+	URLSecret []byte
+}
+
+// attachmentService implements the AttachmentService interface
+type attachmentService struct {
+	repo     repository.AttachmentRepository
+	todoRepo repository.TodoRepository
+	tx       repository.Transactor
+	store    storage.BlobStore
+	limits   AttachmentLimits
+	now      func() time.Time
+}
+
+// NewAttachmentService creates a new attachment service that keeps the
+// contents of attachments in store. Without a store every call fails with
+// ErrAttachmentsUnavailable.
+func NewAttachmentService(repo repository.AttachmentRepository, todoRepo repository.TodoRepository, tx repository.Transactor, store storage.BlobStore, limits AttachmentLimits) AttachmentService {
+	if limits.MaxSize <= 0 {
+		limits.MaxSize = defaultAttachmentMaxSize
+	}
+	if limits.Quota <= 0 {
+		limits.Quota = defaultAttachmentQuota
+	}
+	if len(limits.AllowedTypes) == 0 {
+		limits.AllowedTypes = DefaultAttachmentTypes
+	}
+	if limits.URLTTL == 0 {
+		limits.URLTTL = defaultAttachmentURLTTL
+	}
+	if len(limits.URLSecret) == 0 {
+		limits.URLSecret = make([]byte, 32)
+		if _, err := rand.Read(limits.URLSecret); err != nil {
+			panic(fmt.Sprintf("failed to generate attachment URL secret: %v", err))
+		}
+	}
+
+	return &attachmentService{
+		repo:     repo,
+		todoRepo: todoRepo,
+		tx:       tx,
+		store:    store,
+		limits:   limits,
+		now:      time.Now,
+	}
+}
+
+// MaxSize returns the size of the largest attachment accepted, in bytes
+func (s *attachmentService) MaxSize() int64 {
+	return s.limits.MaxSize
+}
+
+// Upload stores the contents read from r as an attachment of a todo. The MIME
+// type is detected from the contents rather than trusted from the client. The
+// blob is stored before the metadata, and removed again if the quota turns out
+// to be exceeded once the uploads of the user are serialised.
+func (s *attachmentService) Upload(ctx context.Context, todoID uint, userID uint, filename string, r io.Reader, size int64) (*model.Attachment, error) {
+	if s.store == nil {
+		return nil, ErrAttachmentsUnavailable
+	}
+	if size > s.limits.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	if size <= 0 {
+		return nil, ErrEmptyAttachment
+	}
+
+	if _, err := s.getAccessibleTodo(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+
+	// Fail early without uploading when the quota is already used up
+	used, err := s.repo.TotalSize(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment usage: %w", err)
+	}
+	if used+size > s.limits.Quota {
+		return nil, ErrAttachmentQuotaExceeded
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	head = head[:n]
+	contentType := sniffContentType(head)
+	if !slices.Contains(s.limits.AllowedTypes, contentType) {
+		return nil, ErrUnsupportedAttachmentType
+	}
+
+	key, err := newAttachmentKey(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate attachment key: %w", err)
+	}
+	hash := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head), io.LimitReader(r, size-int64(n))), hash)
+	if err := s.store.Put(ctx, key, body, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment := &model.Attachment{
+		UserID:      userID,
+		TodoID:      todoID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:  key,
+	}
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Lock(ctx, userID); err != nil {
+			return fmt.Errorf("failed to lock attachments: %w", err)
+		}
+		used, err := s.repo.TotalSize(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get attachment usage: %w", err)
+		}
+		if used+size > s.limits.Quota {
+			return ErrAttachmentQuotaExceeded
+		}
+		if err := s.repo.Create(ctx, attachment); err != nil {
+			return fmt.Errorf("failed to create attachment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.deleteBlob(ctx, key)
+		return nil, err
+	}
+
+	s.signURL(attachment)
+	return attachment, nil
+}
+
+// List retrieves the attachments of a todo with signed download URLs
+func (s *attachmentService) List(ctx context.Context, todoID uint, userID uint) (*model.AttachmentListResponse, error) {
+	if s.store == nil {
+		return nil, ErrAttachmentsUnavailable
+	}
+	if _, err := s.getAccessibleTodo(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.ListByTodoID(ctx, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	used, err := s.repo.TotalSize(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment usage: %w", err)
+	}
+	for _, attachment := range attachments {
+		s.signURL(attachment)
+	}
+
+	return &model.AttachmentListResponse{
+		Attachments: attachments,
+		Count:       len(attachments),
+		UsedBytes:   used,
+		QuotaBytes:  s.limits.Quota,
+	}, nil
+}
+
+// Get retrieves an attachment of a todo with a signed download URL
+func (s *attachmentService) Get(ctx context.Context, todoID uint, id uint, userID uint) (*model.Attachment, error) {
+	if s.store == nil {
+		return nil, ErrAttachmentsUnavailable
+	}
+	attachment, err := s.getAttachment(ctx, todoID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.signURL(attachment)
+	return attachment, nil
+}
+
+// Delete removes an attachment and then its blob. A blob that cannot be
+// deleted is only logged, as the attachment is already gone for the user.
+func (s *attachmentService) Delete(ctx context.Context, todoID uint, id uint, userID uint) error {
+	if s.store == nil {
+		return ErrAttachmentsUnavailable
+	}
+	attachment, err := s.getAttachment(ctx, todoID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, attachment.ID, todoID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAttachmentNotFound
+		}
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	s.deleteBlob(ctx, attachment.StorageKey)
+	return nil
+}
+
+// Open verifies the expiry and signature of a download URL and opens the
+// contents of its attachment, unless its todo is in the trash; the caller
+// closes them
+func (s *attachmentService) Open(ctx context.Context, id uint, expires int64, signature string) (*model.Attachment, io.ReadCloser, error) {
+	if s.store == nil {
+		return nil, nil, ErrAttachmentsUnavailable
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, s.signature(id, expires)) {
+		return nil, nil, ErrInvalidDownloadSignature
+	}
+	if s.now().Unix() > expires {
+		return nil, nil, ErrDownloadExpired
+	}
+
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	// Attachments of todos in the trash are not served, like the todos themselves
+	if _, err := s.todoRepo.GetByID(ctx, attachment.TodoID, attachment.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+	blob, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+	return attachment, blob, nil
+}
+
+// getAccessibleTodo retrieves a todo outside the trash, ensuring the user has access
+func (s *attachmentService) getAccessibleTodo(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	todo, err := s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTodoNotFound
+		}
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+	if !canAccessTodo(todo, userID) {
+		return nil, ErrUnauthorizedAccess
+	}
+	return todo, nil
+}
+
+// getAttachment retrieves an attachment, ensuring it belongs to a todo the user has access to
+func (s *attachmentService) getAttachment(ctx context.Context, todoID uint, id uint, userID uint) (*model.Attachment, error) {
+	if _, err := s.getAccessibleTodo(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if attachment.TodoID != todoID {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// signURL sets the download URL of an attachment, valid for the configured TTL
+func (s *attachmentService) signURL(attachment *model.Attachment) {
+	expiresAt := s.now().Add(s.limits.URLTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	attachment.DownloadURL = fmt.Sprintf("/api/v1/attachments/%d/download?expires=%d&signature=%s",
+		attachment.ID, expires, hex.EncodeToString(s.signature(attachment.ID, expires)))
+	attachment.DownloadExpiresAt = &expiresAt
+}
+
+// signature returns the HMAC-SHA256 of an attachment ID and expiry time
+func (s *attachmentService) signature(id uint, expires int64) []byte {
+	mac := hmac.New(sha256.New, s.limits.URLSecret)
+	mac.Write([]byte(strconv.FormatUint(uint64(id), 10) + "." + strconv.FormatInt(expires, 10)))
+	return mac.Sum(nil)
+}
+
+// deleteBlob removes a blob, logging failures; the blob is then left behind
+func (s *attachmentService) deleteBlob(ctx context.Context, key string) {
+	if err := s.store.Delete(context.WithoutCancel(ctx), key); err != nil {
+		log.Printf("Failed to delete attachment blob %s: %v", key, err)
+	}
+}
+
+// sniffContentType detects the MIME type of contents from their leading bytes,
+// without parameters such as the charset of text
+func sniffContentType(head []byte) string {
+	contentType := http.DetectContentType(head)
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
+
+// cleanFilename strips the directory and control characters from the name of
+// an uploaded file and shortens it to maxFilenameLength bytes
+func cleanFilename(filename string) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	filename = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, filename))
+	if filename == "" || filename == "." || filename == "/" {
+		return "attachment"
+	}
+
+	if len(filename) > maxFilenameLength {
+		// Keep the extension and cut the name at a rune boundary
+		ext := path.Ext(filename)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name := filename[:maxFilenameLength-len(ext)]
+		for !utf8.ValidString(name) {
+			name = name[:len(name)-1]
+		}
+		filename = name + ext
+	}
+	return filename
+}
+
+// newAttachmentKey generates a random blob key below the directory of a user
+func newAttachmentKey(userID uint) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("attachments/%d/%s", userID, hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"todo-api-backend/internal/audit"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+const (
+	defaultSecurityEventLimit = 100
+	maxSecurityEventLimit     = 1000
+)
+
+// auditService implements the AuditService interface
+type auditService struct {
+	eventRepo repository.SecurityEventRepository
+	sinks     []audit.Sink
+}
+
+// NewAuditService creates a new security audit service writing to the
+// database and to any additional sinks
+func NewAuditService(eventRepo repository.SecurityEventRepository, sinks ...audit.Sink) AuditService {
+	return &auditService{
+		eventRepo: eventRepo,
+		sinks:     sinks,
+	}
+}
+
+// Record stores a security event. Failures are logged rather than returned
+// so that auditing never breaks the operation being audited.
+func (s *auditService) Record(ctx context.Context, event *model.SecurityEvent) {
+	// The event must be recorded even if the client has already gone away
+	ctx = context.WithoutCancel(ctx)
+
+	if client, ok := audit.ClientFromContext(ctx); ok {
+		if event.IP == "" {
+			event.IP = client.IP
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = client.UserAgent
+		}
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	if s.eventRepo != nil {
+		if err := s.eventRepo.Create(ctx, event); err != nil {
+			log.Printf("Failed to record security event %s: %v", event.EventType, err)
+		}
+	}
+
+	for _, sink := range s.sinks {
+		if err := sink.Write(ctx, event); err != nil {
+			log.Printf("Failed to write security event %s to sink: %v", event.EventType, err)
+		}
+	}
+}
+
+// Query retrieves the security events matching the filter, newest first
+func (s *auditService) Query(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	if filter == nil {
+		filter = &model.SecurityEventFilter{}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSecurityEventLimit
+	}
+	if filter.Limit > maxSecurityEventLimit {
+		filter.Limit = maxSecurityEventLimit
+	}
+
+	events, err := s.eventRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query security events: %w", err)
+	}
+
+	return events, nil
+}
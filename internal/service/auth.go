@@ -16,6 +16,7 @@ var (
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrIncorrectPassword  = errors.New("current password is incorrect")
 )
 
 // authService implements the AuthService interface
@@ -23,15 +24,33 @@ type authService struct {
 	userRepo     repository.UserRepository
 	tokenManager *jwt.TokenManager
 	hasher       *password.Hasher
+	auditLog     AuditService
+	tx           repository.Transactor
+	outbox       repository.OutboxRepository
+}
+
+// AuthServiceOption configures optional dependencies of the authentication service
+type AuthServiceOption func(*authService)
+
+// WithAuditLog records authentication events in the security audit log
+func WithAuditLog(auditLog AuditService) AuthServiceOption {
+	return func(s *authService) {
+		s.auditLog = auditLog
+	}
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(userRepo repository.UserRepository, tokenManager *jwt.TokenManager) AuthService {
-	return &authService{
+func NewAuthService(userRepo repository.UserRepository, tokenManager *jwt.TokenManager, opts ...AuthServiceOption) AuthService {
+	s := &authService{
 		userRepo:     userRepo,
 		tokenManager: tokenManager,
 		hasher:       password.NewHasher(),
+		tx:           noopTransactor{},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Register creates a new user account with email validation and password hashing
@@ -43,11 +62,13 @@ func (s *authService) Register(ctx context.Context, req *model.RegisterRequest)
 	}
 	
 	if existingUser != nil {
+		s.recordEvent(ctx, model.SecurityEventRegister, model.SecurityOutcomeFailure, &existingUser.ID, req.Email, "email_exists")
 		return nil, ErrEmailAlreadyExists
 	}
 
 	// Validate password strength
 	if err := password.ValidatePasswordStrength(req.Password); err != nil {
+		s.recordEvent(ctx, model.SecurityEventRegister, model.SecurityOutcomeFailure, nil, req.Email, "weak_password")
 		return nil, fmt.Errorf("password validation failed: %w", err)
 	}
 
@@ -63,10 +84,18 @@ func (s *authService) Register(ctx context.Context, req *model.RegisterRequest)
 		Password: hashedPassword,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return appendUserEvent(ctx, s.outbox, model.OutboxEventUserRegistered, user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	s.recordEvent(ctx, model.SecurityEventRegister, model.SecurityOutcomeSuccess, &user.ID, user.Email, "")
+
 	// Generate JWT token
 	token, err := s.tokenManager.GenerateToken(user.ID, user.Email)
 	if err != nil {
@@ -86,6 +115,7 @@ func (s *authService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.recordEvent(ctx, model.SecurityEventLogin, model.SecurityOutcomeFailure, nil, req.Email, "unknown_email")
 			return nil, ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -93,6 +123,7 @@ func (s *authService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 
 	// Verify password
 	if err := s.hasher.VerifyPassword(user.Password, req.Password); err != nil {
+		s.recordEvent(ctx, model.SecurityEventLogin, model.SecurityOutcomeFailure, &user.ID, user.Email, "invalid_password")
 		return nil, ErrInvalidCredentials
 	}
 
@@ -102,6 +133,8 @@ func (s *authService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	s.recordEvent(ctx, model.SecurityEventLogin, model.SecurityOutcomeSuccess, &user.ID, user.Email, "")
+
 	// Return auth response
 	return &model.AuthResponse{
 		Token: token,
@@ -117,4 +150,61 @@ func (s *authService) ValidateToken(tokenString string) (*jwt.Claims, error) {
 	}
 
 	return claims, nil
+}
+
+// ChangePassword changes the password of a user after verifying the current password
+func (s *authService) ChangePassword(ctx context.Context, userID uint, req *model.ChangePasswordRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Verify the current password
+	if err := s.hasher.VerifyPassword(user.Password, req.CurrentPassword); err != nil {
+		s.recordEvent(ctx, model.SecurityEventPasswordChange, model.SecurityOutcomeFailure, &user.ID, user.Email, "invalid_current_password")
+		return ErrIncorrectPassword
+	}
+
+	// Validate new password strength
+	if err := password.ValidatePasswordStrength(req.NewPassword); err != nil {
+		s.recordEvent(ctx, model.SecurityEventPasswordChange, model.SecurityOutcomeFailure, &user.ID, user.Email, "weak_password")
+		return fmt.Errorf("password validation failed: %w", err)
+	}
+
+	hashedPassword, err := s.hasher.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.Password = hashedPassword
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return appendUserEvent(ctx, s.outbox, model.OutboxEventUserPasswordChanged, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recordEvent(ctx, model.SecurityEventPasswordChange, model.SecurityOutcomeSuccess, &user.ID, user.Email, "")
+	return nil
+}
+
+// recordEvent writes an authentication event to the security audit log, if configured
+func (s *authService) recordEvent(ctx context.Context, eventType, outcome string, userID *uint, email, reason string) {
+	if s.auditLog == nil {
+		return
+	}
+
+	s.auditLog.Record(ctx, &model.SecurityEvent{
+		EventType: eventType,
+		Outcome:   outcome,
+		UserID:    userID,
+		Email:     email,
+		Reason:    reason,
+	})
 }
\ No newline at end of file
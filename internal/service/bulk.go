@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/validator"
+)
+
+// maxBulkItems caps the number of todos a single bulk request may touch
+const maxBulkItems = 500
+
+var (
+	ErrTooManyBulkItems = errors.New("too many bulk items")
+
+	// errBulkRolledBack aborts the transaction of an atomic bulk request
+	errBulkRolledBack = errors.New("bulk request rolled back")
+)
+
+// bulkItem is a single todo operation of a bulk request after ids are expanded
+type bulkItem struct {
+	index int
+	op    *model.BulkOperation
+	id    uint
+}
+
+// Bulk runs many todo operations in a single transaction. In atomic mode the first
+// failure rolls back every operation; otherwise each operation runs in its own
+// savepoint and failures are reported per item.
+func (s *todoService) Bulk(ctx context.Context, userID uint, req *model.BulkRequest) (*model.BulkResponse, error) {
+	items := expandBulkOperations(req.Operations)
+	if len(items) > maxBulkItems {
+		return nil, ErrTooManyBulkItems
+	}
+
+	response := &model.BulkResponse{
+		Atomic:  req.Atomic,
+		Results: make([]*model.BulkItemResult, len(items)),
+	}
+
+	failedAt := -1
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, item := range items {
+			response.Results[i] = s.applyBulkItem(ctx, userID, item)
+			if req.Atomic && response.Results[i].Error != "" {
+				failedAt = i
+				return errBulkRolledBack
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkRolledBack) {
+		return nil, fmt.Errorf("failed to run bulk operations: %w", err)
+	}
+
+	if failedAt >= 0 {
+		// Nothing was written, so report the other items as never applied
+		for i, item := range items {
+			if i == failedAt {
+				continue
+			}
+			result := &model.BulkItemResult{
+				Index:   item.index,
+				Op:      item.op.Op,
+				ID:      item.id,
+				Status:  http.StatusFailedDependency,
+				Error:   "not_attempted",
+				Message: "Not attempted because another operation failed",
+			}
+			if i < failedAt {
+				result.Error = "rolled_back"
+				result.Message = "Rolled back because another operation failed"
+			}
+			response.Results[i] = result
+		}
+	}
+
+	response.Committed = failedAt < 0
+	for _, result := range response.Results {
+		if result.Error == "" {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	return response, nil
+}
+
+// expandBulkOperations turns every operation into one item per targeted todo
+func expandBulkOperations(operations []model.BulkOperation) []bulkItem {
+	var items []bulkItem
+	for i := range operations {
+		op := &operations[i]
+		if op.Op == model.BulkOpCreate || len(op.IDs) == 0 {
+			items = append(items, bulkItem{index: i, op: op, id: op.ID})
+			continue
+		}
+		for _, id := range op.IDs {
+			items = append(items, bulkItem{index: i, op: op, id: id})
+		}
+	}
+	return items
+}
+
+// applyBulkItem runs a single bulk item and describes its outcome
+func (s *todoService) applyBulkItem(ctx context.Context, userID uint, item bulkItem) *model.BulkItemResult {
+	result := &model.BulkItemResult{
+		Index: item.index,
+		Op:    item.op.Op,
+		ID:    item.id,
+	}
+
+	if item.op.Op != model.BulkOpCreate && item.id == 0 {
+		return bulkFailure(result, http.StatusBadRequest, "validation_failed", "id or ids is required")
+	}
+
+	var (
+		todo *model.Todo
+		err  error
+	)
+	switch item.op.Op {
+	case model.BulkOpCreate:
+		req := &model.CreateTodoRequest{
+			Title:       valueOf(item.op.Title),
+			Description: valueOf(item.op.Description),
+		}
+		if verr := validator.ValidateStruct(req); verr != nil {
+			return bulkFailure(result, http.StatusBadRequest, "validation_failed", verr.Error())
+		}
+		todo, err = s.Create(ctx, req, userID)
+		result.Status = http.StatusCreated
+
+	case model.BulkOpUpdate:
+		req := &model.UpdateTodoRequest{
+			Title:       item.op.Title,
+			Description: item.op.Description,
+			Completed:   item.op.Completed,
+		}
+		if verr := validator.ValidateStruct(req); verr != nil {
+			return bulkFailure(result, http.StatusBadRequest, "validation_failed", verr.Error())
+		}
+		todo, err = s.Update(ctx, item.id, req, userID)
+		result.Status = http.StatusOK
+
+	case model.BulkOpComplete:
+		completed := true
+		if item.op.Completed != nil {
+			completed = *item.op.Completed
+		}
+		todo, err = s.Update(ctx, item.id, &model.UpdateTodoRequest{Completed: &completed}, userID)
+		result.Status = http.StatusOK
+
+	case model.BulkOpDelete:
+		err = s.Delete(ctx, item.id, userID)
+		result.Status = http.StatusNoContent
+
+	default:
+		return bulkFailure(result, http.StatusBadRequest, "validation_failed", "op must be one of: create, update, delete, complete")
+	}
+
+	if err != nil {
+		if status, code, message, ok := workflowFailure(err); ok {
+			return bulkFailure(result, status, code, message)
+		}
+		switch {
+		case errors.Is(err, ErrTodoNotFound):
+			return bulkFailure(result, http.StatusNotFound, "not_found", "Todo not found")
+		case errors.Is(err, ErrUserNotFound):
+			return bulkFailure(result, http.StatusNotFound, "user_not_found", "User not found")
+		case errors.Is(err, ErrConcurrentUpdate):
+			return bulkFailure(result, http.StatusConflict, "conflict", "Todo was modified concurrently")
+		case errors.Is(err, ErrInvalidCustomFieldValues):
+			return bulkFailure(result, http.StatusUnprocessableEntity, "invalid_custom_fields", err.Error())
+		default:
+			return bulkFailure(result, http.StatusInternalServerError, "operation_failed", fmt.Sprintf("Failed to %s todo", item.op.Op))
+		}
+	}
+
+	if todo != nil {
+		result.ID = todo.ID
+		result.Todo = todo
+	}
+	return result
+}
+
+// bulkFailure marks a bulk item result as failed
+func bulkFailure(result *model.BulkItemResult, status int, code, message string) *model.BulkItemResult {
+	result.Status = status
+	result.Error = code
+	result.Message = message
+	result.Todo = nil
+	return result
+}
+
+// valueOf dereferences an optional string
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/ical"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/pkg/validator"
+)
+
+const (
+	// calDAVPasswordPrefix marks CalDAV app passwords so they are recognisable when leaked
+	calDAVPasswordPrefix = "dav_"
+
+	// calDAVTouchInterval limits how often the last use of an app password is written,
+	// as task apps authenticate every request
+	calDAVTouchInterval = time.Minute
+
+	// maxCalDAVNameLength is the longest resource name a client may choose
+	maxCalDAVNameLength = 255
+)
+
+var (
+	ErrCalDAVPasswordNotFound = errors.New("caldav password not found")
+	ErrCalDAVObjectNotFound   = errors.New("calendar object not found")
+	ErrInvalidCalendarData    = errors.New("invalid calendar data")
+	ErrCalDAVUIDConflict      = errors.New("calendar object UID conflict")
+	ErrInvalidCalDAVName      = errors.New("invalid calendar object name")
+)
+
+// calDAVService implements the CalDAVService interface
+type calDAVService struct {
+	todos     TodoService
+	todoRepo  repository.TodoRepository
+	userRepo  repository.UserRepository
+	passwords repository.CalDAVPasswordRepository
+	resources repository.CalDAVResourceRepository
+	feeds     repository.CalendarFeedRepository
+	tx        repository.Transactor
+}
+
+// NewCalDAVService creates a new CalDAV service. Changes made through CalDAV go
+// through todos, so they are recorded like changes made through the REST API.
+func NewCalDAVService(todos TodoService, todoRepo repository.TodoRepository, userRepo repository.UserRepository, passwords repository.CalDAVPasswordRepository, resources repository.CalDAVResourceRepository, feeds repository.CalendarFeedRepository, tx repository.Transactor) CalDAVService {
+	return &calDAVService{
+		todos:     todos,
+		todoRepo:  todoRepo,
+		userRepo:  userRepo,
+		passwords: passwords,
+		resources: resources,
+		feeds:     feeds,
+		tx:        tx,
+	}
+}
+
+// Authenticate verifies an email address and app password and returns the user they belong to
+func (s *calDAVService) Authenticate(ctx context.Context, email, password string) (uint, error) {
+	stored, err := s.passwords.GetByHash(ctx, hashCalDAVPassword(password))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidCredentials
+		}
+		return 0, fmt.Errorf("failed to get caldav password: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidCredentials
+		}
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	// The password alone identifies the user; the email must still agree with it
+	if !strings.EqualFold(user.Email, email) {
+		return 0, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= calDAVTouchInterval {
+		if err := s.passwords.Touch(ctx, stored.ID, now); err != nil {
+			return 0, fmt.Errorf("failed to record caldav password use: %w", err)
+		}
+	}
+	return user.ID, nil
+}
+
+// CreatePassword generates a new app password for the user
+func (s *calDAVService) CreatePassword(ctx context.Context, userID uint, req *model.CreateCalDAVPasswordRequest) (*model.CalDAVPasswordResponse, error) {
+	secret, err := newCalDAVPassword()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate caldav password: %w", err)
+	}
+
+	password := &model.CalDAVPassword{
+		UserID:       userID,
+		Name:         req.Name,
+		PasswordHash: hashCalDAVPassword(secret),
+	}
+	if err := s.passwords.Create(ctx, password); err != nil {
+		return nil, fmt.Errorf("failed to create caldav password: %w", err)
+	}
+
+	response := newCalDAVPasswordResponse(password)
+	response.Password = secret
+	return response, nil
+}
+
+// ListPasswords retrieves the app passwords of the user, without their secrets
+func (s *calDAVService) ListPasswords(ctx context.Context, userID uint) ([]*model.CalDAVPasswordResponse, error) {
+	passwords, err := s.passwords.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caldav passwords: %w", err)
+	}
+
+	responses := make([]*model.CalDAVPasswordResponse, 0, len(passwords))
+	for _, password := range passwords {
+		responses = append(responses, newCalDAVPasswordResponse(password))
+	}
+	return responses, nil
+}
+
+// DeletePassword revokes an app password, ensuring user ownership
+func (s *calDAVService) DeletePassword(ctx context.Context, id uint, userID uint) error {
+	if err := s.passwords.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCalDAVPasswordNotFound
+		}
+		return fmt.Errorf("failed to delete caldav password: %w", err)
+	}
+	return nil
+}
+
+// Stamp summarises the todos in the collection of the user
+func (s *calDAVService) Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error) {
+	stamp, err := s.feeds.Stamp(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection stamp: %w", err)
+	}
+	return stamp, nil
+}
+
+// List calls fn for every calendar object in the collection of the user: the
+// todos that are neither archived nor trashed, oldest first
+func (s *calDAVService) List(ctx context.Context, userID uint, fn func(*model.CalDAVObject) error) error {
+	resources, err := s.resources.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get caldav resources: %w", err)
+	}
+	byTodo := make(map[uint]*model.CalDAVResource, len(resources))
+	for _, resource := range resources {
+		byTodo[resource.TodoID] = resource
+	}
+
+	filter := &model.TodoFilter{Archived: model.ArchivedExclude}
+	err = s.todoRepo.StreamByUserID(ctx, userID, filter, func(todo *model.Todo) error {
+		return fn(newCalDAVObject(todo, byTodo[todo.ID]))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves the calendar object with the given name from the collection of the user
+func (s *calDAVService) Get(ctx context.Context, userID uint, name string) (*model.CalDAVObject, error) {
+	resource, err := s.resources.GetByName(ctx, userID, name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get caldav resource: %w", err)
+	}
+
+	var todoID uint
+	if resource != nil {
+		todoID = resource.TodoID
+	} else {
+		id, ok := parseCalDAVName(name)
+		if !ok {
+			return nil, ErrCalDAVObjectNotFound
+		}
+		// A todo created through CalDAV is only found under the name its client chose
+		if _, err := s.resources.GetByTodoID(ctx, id); err == nil {
+			return nil, ErrCalDAVObjectNotFound
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get caldav resource: %w", err)
+		}
+		todoID = id
+	}
+
+	todo, err := s.todoRepo.GetByID(ctx, todoID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalDAVObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+	if todo.IsArchived() {
+		return nil, ErrCalDAVObjectNotFound
+	}
+	return newCalDAVObject(todo, resource), nil
+}
+
+// Put stores a calendar object, creating a todo for a new name or replacing the
+// fields of the todo at an existing one. With createOnly an existing object is
+// not replaced. It reports whether a todo was created.
+func (s *calDAVService) Put(ctx context.Context, userID uint, name string, data []byte, createOnly bool) (*model.CalDAVObject, bool, error) {
+	parsed, err := ical.Parse(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidCalendarData, err)
+	}
+
+	req := &model.ReplaceTodoRequest{
+		Title:       parsed.Summary,
+		Description: parsed.Description,
+		Completed:   parsed.Completed,
+		DueAt:       parsed.Due,
+	}
+	if err := validator.ValidateStruct(req); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidCalendarData, err)
+	}
+
+	existing, err := s.Get(ctx, userID, name)
+	if err != nil && !errors.Is(err, ErrCalDAVObjectNotFound) {
+		return nil, false, err
+	}
+
+	if existing != nil {
+		if createOnly {
+			return nil, false, ErrPreconditionFailed
+		}
+		if parsed.UID != existing.UID {
+			return nil, false, ErrCalDAVUIDConflict
+		}
+		todo, err := s.todos.Replace(ctx, existing.Todo.ID, req, userID)
+		if err != nil {
+			return nil, false, err
+		}
+		existing.Todo = todo
+		return existing, false, nil
+	}
+
+	// If-Match can only be met by an existing object
+	if _, ok := ctx.Value(ifMatchKey{}).([]uint); ok {
+		return nil, false, ErrPreconditionFailed
+	}
+	if len(name) > maxCalDAVNameLength || len(parsed.UID) > maxCalDAVNameLength {
+		return nil, false, ErrInvalidCalDAVName
+	}
+
+	object := &model.CalDAVObject{Name: name, UID: parsed.UID}
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.resources.Release(ctx, userID, name, parsed.UID); err != nil {
+			return fmt.Errorf("failed to release caldav resource: %w", err)
+		}
+		exists, err := s.resources.ExistsUID(ctx, userID, parsed.UID)
+		if err != nil {
+			return fmt.Errorf("failed to check caldav resource: %w", err)
+		}
+		if exists {
+			return ErrCalDAVUIDConflict
+		}
+
+		todo, err := s.todos.Create(ctx, &model.CreateTodoRequest{
+			Title:       req.Title,
+			Description: req.Description,
+			DueAt:       req.DueAt,
+		}, userID)
+		if err != nil {
+			return err
+		}
+		if req.Completed {
+			if todo, err = s.todos.Replace(ctx, todo.ID, req, userID); err != nil {
+				return err
+			}
+		}
+		object.Todo = todo
+
+		return s.resources.Create(ctx, &model.CalDAVResource{
+			TodoID: todo.ID,
+			UserID: userID,
+			Name:   name,
+			UID:    parsed.UID,
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return object, true, nil
+}
+
+// Delete moves the todo of a calendar object to the trash
+func (s *calDAVService) Delete(ctx context.Context, userID uint, name string) error {
+	object, err := s.Get(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	return s.todos.Delete(ctx, object.Todo.ID, userID)
+}
+
+// newCalDAVObject returns a todo as a calendar object, under the name and UID
+// its client chose if it was created through CalDAV
+func newCalDAVObject(todo *model.Todo, resource *model.CalDAVResource) *model.CalDAVObject {
+	if resource != nil {
+		return &model.CalDAVObject{Name: resource.Name, UID: resource.UID, Todo: todo}
+	}
+	return &model.CalDAVObject{
+		Name: "todo-" + strconv.FormatUint(uint64(todo.ID), 10) + ".ics",
+		UID:  ical.TodoUID(todo.ID, ical.DefaultDomain),
+		Todo: todo,
+	}
+}
+
+// parseCalDAVName returns the todo ID in a resource name derived from it
+func parseCalDAVName(name string) (uint, bool) {
+	digits, ok := strings.CutPrefix(name, "todo-")
+	if !ok {
+		return 0, false
+	}
+	if digits, ok = strings.CutSuffix(digits, ".ics"); !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// newCalDAVPasswordResponse converts an app password into its API representation
+func newCalDAVPasswordResponse(password *model.CalDAVPassword) *model.CalDAVPasswordResponse {
+	return &model.CalDAVPasswordResponse{
+		ID:         password.ID,
+		Name:       password.Name,
+		LastUsedAt: password.LastUsedAt,
+		CreatedAt:  password.CreatedAt,
+	}
+}
+
+// newCalDAVPassword returns a random app password
+func newCalDAVPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return calDAVPasswordPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashCalDAVPassword returns the stored form of an app password. App passwords
+// are random and long, so a fast hash is enough to protect them.
+func hashCalDAVPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
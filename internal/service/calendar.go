@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+// calendarTokenPrefix marks calendar feed tokens so they are recognisable when leaked
+const calendarTokenPrefix = "cal_"
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// calendarService implements the CalendarService interface
+type calendarService struct {
+	feeds    repository.CalendarFeedRepository
+	todoRepo repository.TodoRepository
+}
+
+// NewCalendarService creates a new calendar feed service
+func NewCalendarService(feeds repository.CalendarFeedRepository, todoRepo repository.TodoRepository) CalendarService {
+	return &calendarService{
+		feeds:    feeds,
+		todoRepo: todoRepo,
+	}
+}
+
+// GetFeed reports whether the user has a calendar feed
+func (s *calendarService) GetFeed(ctx context.Context, userID uint) (*model.CalendarFeedResponse, error) {
+	feed, err := s.feeds.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.CalendarFeedResponse{Enabled: false}, nil
+		}
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	return &model.CalendarFeedResponse{
+		Enabled:   true,
+		CreatedAt: &feed.CreatedAt,
+	}, nil
+}
+
+// RegenerateToken creates the calendar feed of the user or replaces its token,
+// so that the previous feed URL stops working
+func (s *calendarService) RegenerateToken(ctx context.Context, userID uint) (*model.CalendarFeedResponse, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+
+	feed := &model.CalendarFeed{
+		UserID:    userID,
+		TokenHash: hashCalendarToken(token),
+	}
+	if err := s.feeds.Save(ctx, feed); err != nil {
+		return nil, fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+
+	return &model.CalendarFeedResponse{
+		Enabled:   true,
+		CreatedAt: &feed.CreatedAt,
+		Token:     token,
+	}, nil
+}
+
+// DisableFeed removes the calendar feed of the user
+func (s *calendarService) DisableFeed(ctx context.Context, userID uint) error {
+	if err := s.feeds.Delete(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCalendarFeedNotFound
+		}
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	return nil
+}
+
+// ResolveToken returns the user a calendar feed token belongs to
+func (s *calendarService) ResolveToken(ctx context.Context, token string) (uint, error) {
+	feed, err := s.feeds.GetByTokenHash(ctx, hashCalendarToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrCalendarFeedNotFound
+		}
+		return 0, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return feed.UserID, nil
+}
+
+// Stamp summarises the todos in the calendar feed of the user
+func (s *calendarService) Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error) {
+	stamp, err := s.feeds.Stamp(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar stamp: %w", err)
+	}
+	return stamp, nil
+}
+
+// FeedTodos calls fn for every todo in the calendar feed of the user: the todos
+// that are neither archived nor trashed, oldest first
+func (s *calendarService) FeedTodos(ctx context.Context, userID uint, fn func(*model.Todo) error) error {
+	filter := &model.TodoFilter{Archived: model.ArchivedExclude}
+	if err := s.todoRepo.StreamByUserID(ctx, userID, filter, fn); err != nil {
+		return fmt.Errorf("failed to get calendar todos: %w", err)
+	}
+	return nil
+}
+
+// newCalendarToken returns a random calendar feed token that is safe to use in a URL path
+func newCalendarToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return calendarTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashCalendarToken returns the stored form of a calendar feed token
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+const (
+	// defaultCommentPageSize and maxCommentPageSize bound the number of
+	// top-level comments returned per page
+	defaultCommentPageSize = 20
+	maxCommentPageSize     = 100
+
+	// maxMentionsPerComment limits how many distinct addresses of a comment
+	// are looked up as mentions; later ones are left as plain text
+	maxMentionsPerComment = 20
+)
+
+var (
+	ErrCommentsUnavailable   = errors.New("comments are not available")
+	ErrCommentNotFound       = errors.New("comment not found")
+	ErrParentCommentNotFound = errors.New("parent comment not found")
+	ErrCommentNotAuthor      = errors.New("only the author can change a comment")
+	ErrEmptyComment          = errors.New("comment body must not be blank")
+)
+
+// mentionPattern matches @email mentions such as @jane@example.com that do
+// not continue a word or an address
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.@])@([A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,})`)
+
+// MentionNotifier notifies users mentioned in a comment
+type MentionNotifier interface {
+	// NotifyMention tells a user that they were mentioned in a comment on a
+	// todo. Called inside the transaction of the comment, the notification
+	// is only sent if the comment is committed.
+	NotifyMention(ctx context.Context, userID uint, todo *model.Todo, comment *model.TodoComment) error
+}
+
+// WithComments enables threaded comments on todos
+func WithComments(comments repository.CommentRepository) TodoServiceOption {
+	return func(s *todoService) {
+		s.comments = comments
+	}
+}
+
+// WithMentionNotifier notifies the users mentioned in new and edited comments
+func WithMentionNotifier(notifier MentionNotifier) TodoServiceOption {
+	return func(s *todoService) {
+		s.mentions = notifier
+	}
+}
+
+// canAccessTodo reports whether a user may see and discuss a todo. Todos are
+// not shared yet, so only their owner has access, which also means that
+// mentions only ever resolve to the owner.
+func canAccessTodo(todo *model.Todo, userID uint) bool {
+	return todo.UserID == userID
+}
+
+// ListComments retrieves a page of the top-level comments of a todo, oldest
+// first, each with all of its replies
+func (s *todoService) ListComments(ctx context.Context, id uint, userID uint, page, perPage int) (*model.CommentPage, error) {
+	if s.comments == nil {
+		return nil, ErrCommentsUnavailable
+	}
+	if page < 1 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = defaultCommentPageSize
+	}
+	if perPage > maxCommentPageSize {
+		perPage = maxCommentPageSize
+	}
+
+	if _, err := s.getOwnedTodo(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	total, err := s.comments.CountThreads(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+	threads, err := s.comments.ListThreads(ctx, id, (page-1)*perPage, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	parents := make(map[uint]*model.TodoComment, len(threads))
+	parentIDs := make([]uint, 0, len(threads))
+	for _, thread := range threads {
+		parents[thread.ID] = thread
+		parentIDs = append(parentIDs, thread.ID)
+	}
+	replies, err := s.comments.ListReplies(ctx, parentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replies: %w", err)
+	}
+	for _, reply := range replies {
+		if parent := parents[*reply.ParentID]; parent != nil {
+			parent.Replies = append(parent.Replies, reply)
+		}
+	}
+
+	return &model.CommentPage{
+		Comments: append([]*model.TodoComment{}, threads...),
+		Page:     page,
+		PerPage:  perPage,
+		Total:    total,
+	}, nil
+}
+
+// AddComment comments on a todo or replies to one of its comments. A reply to
+// a reply joins the thread of the top-level comment. Mentioned users with
+// access to the todo are recorded and notified.
+func (s *todoService) AddComment(ctx context.Context, id uint, req *model.CreateCommentRequest, userID uint) (*model.TodoComment, error) {
+	if s.comments == nil {
+		return nil, ErrCommentsUnavailable
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, ErrEmptyComment
+	}
+
+	todo, err := s.getOwnedTodo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &model.TodoComment{TodoID: id, UserID: userID, Body: req.Body}
+	if req.ParentID != nil {
+		parent, err := s.comments.GetByID(ctx, *req.ParentID, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrParentCommentNotFound
+			}
+			return nil, fmt.Errorf("failed to get parent comment: %w", err)
+		}
+		parentID := parent.ID
+		if parent.ParentID != nil {
+			parentID = *parent.ParentID
+		}
+		comment.ParentID = &parentID
+	}
+
+	comment.Mentions, err = s.resolveMentions(ctx, todo, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.comments.Create(ctx, comment); err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+		return s.notifyMentions(ctx, todo, comment, comment.Mentions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// UpdateComment replaces the body of a comment, ensuring the user wrote it.
+// Only users who were not mentioned before are notified.
+func (s *todoService) UpdateComment(ctx context.Context, id uint, commentID uint, req *model.UpdateCommentRequest, userID uint) (*model.TodoComment, error) {
+	if s.comments == nil {
+		return nil, ErrCommentsUnavailable
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, ErrEmptyComment
+	}
+
+	todo, comment, err := s.getAuthoredComment(ctx, id, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.resolveMentions(ctx, todo, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	notified := make(map[uint]bool, len(comment.Mentions))
+	for _, mention := range comment.Mentions {
+		notified[mention.UserID] = true
+	}
+	var added []model.CommentMention
+	for _, mention := range mentions {
+		if !notified[mention.UserID] {
+			added = append(added, mention)
+		}
+	}
+
+	comment.Body = req.Body
+	comment.Mentions = mentions
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.comments.Update(ctx, comment); err != nil {
+			return fmt.Errorf("failed to update comment: %w", err)
+		}
+		return s.notifyMentions(ctx, todo, comment, added)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment removes a comment together with its replies, ensuring the user wrote it
+func (s *todoService) DeleteComment(ctx context.Context, id uint, commentID uint, userID uint) error {
+	if s.comments == nil {
+		return ErrCommentsUnavailable
+	}
+
+	if _, _, err := s.getAuthoredComment(ctx, id, commentID, userID); err != nil {
+		return err
+	}
+
+	if err := s.comments.Delete(ctx, commentID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCommentNotFound
+		}
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	return nil
+}
+
+// getAuthoredComment retrieves a comment of a todo the user has access to,
+// ensuring the user wrote it
+func (s *todoService) getAuthoredComment(ctx context.Context, id uint, commentID uint, userID uint) (*model.Todo, *model.TodoComment, error) {
+	todo, err := s.getOwnedTodo(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comment, err := s.comments.GetByID(ctx, commentID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCommentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	if comment.UserID != userID {
+		return nil, nil, ErrCommentNotAuthor
+	}
+
+	return todo, comment, nil
+}
+
+// resolveMentions finds the users mentioned in a comment body who have access
+// to the todo, in order of first mention. Addresses of unknown users and of
+// users without access, currently everyone but the owner, are left as plain text.
+func (s *todoService) resolveMentions(ctx context.Context, todo *model.Todo, body string) ([]model.CommentMention, error) {
+	mentions := []model.CommentMention{}
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := match[1]
+		if seen[email] {
+			continue
+		}
+		if len(seen) == maxMentionsPerComment {
+			break
+		}
+		seen[email] = true
+
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to resolve mention: %w", err)
+		}
+		if !canAccessTodo(todo, user.ID) {
+			continue
+		}
+		mentions = append(mentions, model.CommentMention{UserID: user.ID, Email: user.Email})
+	}
+	return mentions, nil
+}
+
+// notifyMentions notifies the given mentioned users of a comment, if a notifier is configured
+func (s *todoService) notifyMentions(ctx context.Context, todo *model.Todo, comment *model.TodoComment, mentions []model.CommentMention) error {
+	if s.mentions == nil {
+		return nil
+	}
+	for _, mention := range mentions {
+		if err := s.mentions.NotifyMention(ctx, mention.UserID, todo, comment); err != nil {
+			return fmt.Errorf("failed to notify mentioned user: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"todo-api-backend/internal/model"
+)
+
+var (
+	ErrPreconditionFailed = errors.New("todo version does not match")
+	ErrConcurrentUpdate   = errors.New("todo was modified concurrently")
+)
+
+// ifMatchKey is the context key for the versions a conditional write accepts
+type ifMatchKey struct{}
+
+// WithIfMatch makes todo writes performed with the returned context
+// conditional: they fail with ErrPreconditionFailed unless the todo is
+// currently at one of the given versions. Passing no versions makes every
+// conditional write fail.
+func WithIfMatch(ctx context.Context, versions ...uint) context.Context {
+	if versions == nil {
+		versions = []uint{}
+	}
+	return context.WithValue(ctx, ifMatchKey{}, versions)
+}
+
+// checkPrecondition verifies todo against the If-Match versions carried by ctx, if any
+func checkPrecondition(ctx context.Context, todo *model.Todo) error {
+	versions, ok := ctx.Value(ifMatchKey{}).([]uint)
+	if !ok {
+		return nil
+	}
+
+	for _, version := range versions {
+		if todo.Version == version {
+			return nil
+		}
+	}
+	return ErrPreconditionFailed
+}
+
+// versionConflict translates a repository.ErrVersionConflict. A conditional
+// write lost the race, so its precondition no longer holds; an
+// unconditional write is reported as a concurrent update.
+func versionConflict(ctx context.Context) error {
+	if _, ok := ctx.Value(ifMatchKey{}).([]uint); ok {
+		return ErrPreconditionFailed
+	}
+	return ErrConcurrentUpdate
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+// maxCustomFieldsPerUser limits how many custom fields a user may define
+const maxCustomFieldsPerUser = 50
+
+var (
+	ErrCustomFieldNotFound = errors.New("custom field not found")
+	ErrCustomFieldLimit    = errors.New("custom field limit reached")
+	ErrCustomFieldExists   = errors.New("custom field key already exists")
+	ErrInvalidCustomField  = errors.New("invalid custom field")
+)
+
+// customFieldKey matches the keys of custom fields, such as story_points
+var customFieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// customFieldService implements the CustomFieldService interface
+type customFieldService struct {
+	repo  repository.CustomFieldRepository
+	lists repository.ListRepository
+	tx    repository.Transactor
+}
+
+// NewCustomFieldService creates a new custom field service
+func NewCustomFieldService(repo repository.CustomFieldRepository, lists repository.ListRepository, tx repository.Transactor) CustomFieldService {
+	return &customFieldService{
+		repo:  repo,
+		lists: lists,
+		tx:    tx,
+	}
+}
+
+// Create stores a new custom field. Values left behind by an earlier field
+// with the same key, such as one of a deleted list, are removed first.
+func (s *customFieldService) Create(ctx context.Context, userID uint, req *model.CreateCustomFieldRequest) (*model.CustomField, error) {
+	if !customFieldKey.MatchString(req.Key) {
+		return nil, fmt.Errorf("%w: key %q must be lowercase letters, digits and underscores", ErrInvalidCustomField, req.Key)
+	}
+	if err := checkCustomFieldRules(req.Type, &req.Rules); err != nil {
+		return nil, err
+	}
+
+	field := &model.CustomField{
+		UserID:   userID,
+		Key:      req.Key,
+		Name:     req.Name,
+		Type:     req.Type,
+		Required: req.Required,
+		Rules:    req.Rules,
+	}
+	if req.ListID != nil && *req.ListID != 0 {
+		if _, err := s.lists.GetByID(ctx, *req.ListID, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrListNotFound
+			}
+			return nil, fmt.Errorf("failed to get list: %w", err)
+		}
+		listID := *req.ListID
+		field.ListID = &listID
+	}
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		count, err := s.repo.CountByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to count custom fields: %w", err)
+		}
+		if count >= maxCustomFieldsPerUser {
+			return ErrCustomFieldLimit
+		}
+		exists, err := s.repo.ExistsKey(ctx, userID, req.Key)
+		if err != nil {
+			return fmt.Errorf("failed to check custom field key: %w", err)
+		}
+		if exists {
+			return ErrCustomFieldExists
+		}
+
+		if err := s.repo.RemoveValues(ctx, userID, req.Key); err != nil {
+			return fmt.Errorf("failed to remove custom field values: %w", err)
+		}
+		if err := s.repo.Create(ctx, field); err != nil {
+			return fmt.Errorf("failed to create custom field: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+// List retrieves all custom fields of a user
+func (s *customFieldService) List(ctx context.Context, userID uint) ([]*model.CustomField, error) {
+	fields, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	return fields, nil
+}
+
+// Get retrieves a custom field, ensuring it belongs to the user
+func (s *customFieldService) Get(ctx context.Context, id uint, userID uint) (*model.CustomField, error) {
+	field, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomFieldNotFound
+		}
+		return nil, fmt.Errorf("failed to get custom field: %w", err)
+	}
+	return field, nil
+}
+
+// Update renames a custom field or changes its rules. Stored values are not
+// checked again; the new rules apply the next time a todo's values are set.
+func (s *customFieldService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateCustomFieldRequest) (*model.CustomField, error) {
+	field, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		field.Name = *req.Name
+	}
+	if req.Required != nil {
+		field.Required = *req.Required
+	}
+	if req.Rules != nil {
+		if err := checkCustomFieldRules(field.Type, req.Rules); err != nil {
+			return nil, err
+		}
+		field.Rules = *req.Rules
+	}
+
+	if err := s.repo.Update(ctx, field); err != nil {
+		return nil, fmt.Errorf("failed to update custom field: %w", err)
+	}
+	return field, nil
+}
+
+// Delete removes a custom field together with its values on every todo
+func (s *customFieldService) Delete(ctx context.Context, id uint, userID uint) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		field, err := s.Get(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.RemoveValues(ctx, userID, field.Key); err != nil {
+			return fmt.Errorf("failed to remove custom field values: %w", err)
+		}
+		if err := s.repo.Delete(ctx, field.ID, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCustomFieldNotFound
+			}
+			return fmt.Errorf("failed to delete custom field: %w", err)
+		}
+		return nil
+	})
+}
+
+// checkCustomFieldRules checks that the rules of a custom field fit its type:
+// bounds for number and text fields, a format for text fields and distinct
+// options for select fields
+func checkCustomFieldRules(fieldType string, rules *model.CustomFieldRules) error {
+	if rules.Min != nil || rules.Max != nil {
+		if fieldType != model.CustomFieldNumber && fieldType != model.CustomFieldText {
+			return fmt.Errorf("%w: min and max only apply to number and text fields", ErrInvalidCustomField)
+		}
+		for _, bound := range []*float64{rules.Min, rules.Max} {
+			if bound == nil {
+				continue
+			}
+			if math.IsNaN(*bound) || math.IsInf(*bound, 0) {
+				return fmt.Errorf("%w: min and max must be finite", ErrInvalidCustomField)
+			}
+			if fieldType == model.CustomFieldText && (*bound < 0 || *bound != math.Trunc(*bound)) {
+				return fmt.Errorf("%w: text lengths must be whole numbers of at least 0", ErrInvalidCustomField)
+			}
+		}
+		if rules.Min != nil && rules.Max != nil && *rules.Min > *rules.Max {
+			return fmt.Errorf("%w: min must not be greater than max", ErrInvalidCustomField)
+		}
+	}
+	if fieldType == model.CustomFieldText && rules.Max != nil && *rules.Max > maxCustomFieldTextLength {
+		return fmt.Errorf("%w: text fields can hold at most %d characters", ErrInvalidCustomField, maxCustomFieldTextLength)
+	}
+
+	if rules.Format != "" && fieldType != model.CustomFieldText {
+		return fmt.Errorf("%w: format only applies to text fields", ErrInvalidCustomField)
+	}
+
+	if fieldType != model.CustomFieldSelect {
+		if len(rules.Options) > 0 {
+			return fmt.Errorf("%w: options only apply to select fields", ErrInvalidCustomField)
+		}
+		return nil
+	}
+	if len(rules.Options) == 0 {
+		return fmt.Errorf("%w: select fields need at least one option", ErrInvalidCustomField)
+	}
+	options := make(map[string]bool, len(rules.Options))
+	for _, option := range rules.Options {
+		if options[option] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidCustomField, option)
+		}
+		options[option] = true
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/pkg/validator"
+)
+
+// maxCustomFieldTextLength limits the length of text custom field values
+const maxCustomFieldTextLength = 1000
+
+var (
+	ErrCustomFieldsUnavailable  = errors.New("custom fields are not available")
+	ErrInvalidCustomFieldValues = errors.New("invalid custom field values")
+	ErrInvalidCustomFieldFilter = errors.New("invalid custom field filter")
+)
+
+// WithCustomFields enables user-defined custom fields on todos
+func WithCustomFields(customFields repository.CustomFieldRepository) TodoServiceOption {
+	return func(s *todoService) {
+		s.customFields = customFields
+	}
+}
+
+// setCustomFields validates custom field values against the fields that apply
+// to a todo and stores them on it. Every value must belong to one of those
+// fields, and every required field must have a value.
+func (s *todoService) setCustomFields(ctx context.Context, todo *model.Todo, values model.CustomFieldValues, userID uint) error {
+	if s.customFields == nil {
+		if len(values) > 0 {
+			return ErrCustomFieldsUnavailable
+		}
+		return nil
+	}
+
+	fields, err := s.customFields.ListForTodo(ctx, userID, todo.ListID)
+	if err != nil {
+		return fmt.Errorf("failed to get custom fields: %w", err)
+	}
+	checked, err := checkCustomFieldValues(fields, values)
+	if err != nil {
+		return err
+	}
+
+	todo.CustomFields = checked
+	return nil
+}
+
+// patchCustomFields stores the custom field values a patch left a todo with.
+// Only the values that differ from the stored ones are validated, so a patch
+// that does not touch them keeps working after the rules of the fields
+// changed or a required field was added.
+func (s *todoService) patchCustomFields(ctx context.Context, todo *model.Todo, values model.CustomFieldValues, userID uint) error {
+	changed := make(model.CustomFieldValues)
+	for key, value := range values {
+		if !reflect.DeepEqual(todo.CustomFields[key], value) {
+			changed[key] = value
+		}
+	}
+	for key := range todo.CustomFields {
+		if _, ok := values[key]; !ok {
+			changed[key] = nil
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if s.customFields == nil {
+		return ErrCustomFieldsUnavailable
+	}
+
+	fields, err := s.customFields.ListForTodo(ctx, userID, todo.ListID)
+	if err != nil {
+		return fmt.Errorf("failed to get custom fields: %w", err)
+	}
+	var touched []*model.CustomField
+	for _, field := range fields {
+		if _, ok := changed[field.Key]; ok {
+			touched = append(touched, field)
+		}
+	}
+	checked, err := checkCustomFieldValues(touched, changed)
+	if err != nil {
+		return err
+	}
+
+	// Build a new map, since the todo from before the change shares the old one
+	patched := make(model.CustomFieldValues, len(todo.CustomFields)+len(checked))
+	for key, value := range todo.CustomFields {
+		if _, ok := changed[key]; !ok {
+			patched[key] = value
+		}
+	}
+	for key, value := range checked {
+		patched[key] = value
+	}
+	if len(patched) == 0 {
+		patched = nil
+	}
+
+	todo.CustomFields = patched
+	return nil
+}
+
+// pruneCustomFields drops the values of custom fields that no longer apply to
+// a todo, such as those of the list it was moved out of
+func (s *todoService) pruneCustomFields(ctx context.Context, todo *model.Todo, userID uint) error {
+	if s.customFields == nil || len(todo.CustomFields) == 0 {
+		return nil
+	}
+
+	fields, err := s.customFields.ListForTodo(ctx, userID, todo.ListID)
+	if err != nil {
+		return fmt.Errorf("failed to get custom fields: %w", err)
+	}
+	kept := make(model.CustomFieldValues, len(todo.CustomFields))
+	for _, field := range fields {
+		if value, ok := todo.CustomFields[field.Key]; ok {
+			kept[field.Key] = value
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+
+	// Replace the map rather than deleting from it, since the todo from
+	// before the change shares it
+	todo.CustomFields = kept
+	return nil
+}
+
+// resolveCustomFieldFilter checks the custom field conditions and sorting of a
+// listing filter against the fields of the user and parses their values
+func (s *todoService) resolveCustomFieldFilter(ctx context.Context, userID uint, filter *model.TodoFilter) error {
+	if filter == nil || (len(filter.CustomFields) == 0 && filter.SortField == nil) {
+		return nil
+	}
+	if s.customFields == nil {
+		return ErrCustomFieldsUnavailable
+	}
+
+	fields, err := s.customFields.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get custom fields: %w", err)
+	}
+	types := make(map[string]string, len(fields))
+	for _, field := range fields {
+		types[field.Key] = field.Type
+	}
+
+	for i := range filter.CustomFields {
+		condition := &filter.CustomFields[i]
+		fieldType, ok := types[condition.Key]
+		if !ok {
+			return fmt.Errorf("%w: unknown custom field %q", ErrInvalidCustomFieldFilter, condition.Key)
+		}
+		parsed, err := parseCustomFieldCondition(fieldType, condition.Op, condition.Value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidCustomFieldFilter, condition.Key, err)
+		}
+		condition.Type = fieldType
+		condition.Parsed = parsed
+	}
+
+	if filter.SortField != nil {
+		fieldType, ok := types[filter.SortField.Key]
+		if !ok {
+			return fmt.Errorf("%w: unknown custom field %q", ErrInvalidCustomFieldFilter, filter.SortField.Key)
+		}
+		filter.SortField.Type = fieldType
+	}
+	return nil
+}
+
+// parseCustomFieldCondition parses the value of a filter condition on a custom
+// field of the given type. Number and date fields can be compared with gte and
+// lte; all fields can be compared for equality.
+func parseCustomFieldCondition(fieldType, op, value string) (interface{}, error) {
+	switch op {
+	case model.CustomFieldEqual:
+	case model.CustomFieldAtLeast, model.CustomFieldAtMost:
+		if fieldType != model.CustomFieldNumber && fieldType != model.CustomFieldDate {
+			return nil, fmt.Errorf("%s only applies to number and date fields", op)
+		}
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+
+	switch fieldType {
+	case model.CustomFieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return number, nil
+	case model.CustomFieldDate:
+		if _, err := time.Parse(model.CustomFieldDateLayout, value); err != nil {
+			return nil, fmt.Errorf("%q is not a date in the format %s", value, model.CustomFieldDateLayout)
+		}
+		return value, nil
+	case model.CustomFieldCheckbox:
+		checked, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", value)
+		}
+		return checked, nil
+	default:
+		return value, nil
+	}
+}
+
+// checkCustomFieldValues validates custom field values against the fields
+// that apply to a todo. It returns the values without nulls, or nil if there
+// are none, and reports every invalid value at once.
+func checkCustomFieldValues(fields []*model.CustomField, values model.CustomFieldValues) (model.CustomFieldValues, error) {
+	var (
+		checked  = make(model.CustomFieldValues, len(values))
+		problems []validator.ValidationError
+		known    = make(map[string]bool, len(fields))
+	)
+	for _, field := range fields {
+		known[field.Key] = true
+		value := values[field.Key]
+		if err := checkCustomFieldValue(field, value); err != nil {
+			problems = append(problems, *err)
+			continue
+		}
+		if value != nil {
+			checked[field.Key] = value
+		}
+	}
+
+	var unknown []string
+	for key, value := range values {
+		if !known[key] && value != nil {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		name := "custom_fields." + key
+		problems = append(problems, validator.ValidationError{
+			Field:   name,
+			Tag:     "unknown",
+			Message: fmt.Sprintf("%s is not a custom field of this todo", name),
+		})
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCustomFieldValues, validator.ValidationErrors{Errors: problems})
+	}
+	if len(checked) == 0 {
+		return nil, nil
+	}
+	return checked, nil
+}
+
+// checkCustomFieldValue validates a single custom field value, where nil
+// means the todo has no value for the field
+func checkCustomFieldValue(field *model.CustomField, value interface{}) *validator.ValidationError {
+	name := "custom_fields." + field.Key
+	if value == nil {
+		if field.Required {
+			return &validator.ValidationError{Field: name, Tag: "required", Message: fmt.Sprintf("%s is required", name)}
+		}
+		return nil
+	}
+
+	var (
+		tags []string
+		err  error
+	)
+	switch field.Type {
+	case model.CustomFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return customFieldTypeError(name, "a string")
+		}
+		if field.Required {
+			tags = append(tags, "required")
+		} else {
+			tags = append(tags, "omitempty")
+		}
+		if field.Rules.Min != nil {
+			tags = append(tags, "min="+formatBound(*field.Rules.Min))
+		}
+		maxLength := float64(maxCustomFieldTextLength)
+		if field.Rules.Max != nil {
+			maxLength = *field.Rules.Max
+		}
+		tags = append(tags, "max="+formatBound(maxLength))
+		if field.Rules.Format != "" {
+			tags = append(tags, field.Rules.Format)
+		}
+		err = validator.ValidateNamedVar(name, text, strings.Join(tags, ","))
+
+	case model.CustomFieldNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return customFieldTypeError(name, "a number")
+		}
+		if field.Rules.Min != nil {
+			tags = append(tags, "min="+formatBound(*field.Rules.Min))
+		}
+		if field.Rules.Max != nil {
+			tags = append(tags, "max="+formatBound(*field.Rules.Max))
+		}
+		if len(tags) > 0 {
+			err = validator.ValidateNamedVar(name, number, strings.Join(tags, ","))
+		}
+
+	case model.CustomFieldDate:
+		date, ok := value.(string)
+		if !ok {
+			return customFieldTypeError(name, "a date string")
+		}
+		err = validator.ValidateNamedVar(name, date, "required,datetime="+model.CustomFieldDateLayout)
+
+	case model.CustomFieldSelect:
+		option, ok := value.(string)
+		if !ok {
+			return customFieldTypeError(name, "a string")
+		}
+		for _, allowed := range field.Rules.Options {
+			if option == allowed {
+				return nil
+			}
+		}
+		// Options may contain spaces, so they cannot be checked with oneof
+		return &validator.ValidationError{
+			Field:   name,
+			Tag:     "oneof",
+			Value:   option,
+			Message: fmt.Sprintf("%s must be one of: %s", name, strings.Join(field.Rules.Options, ", ")),
+		}
+
+	case model.CustomFieldCheckbox:
+		if _, ok := value.(bool); !ok {
+			return customFieldTypeError(name, "true or false")
+		}
+	}
+
+	if err != nil {
+		var validationError validator.ValidationError
+		if errors.As(err, &validationError) {
+			return &validationError
+		}
+		return &validator.ValidationError{Field: name, Message: fmt.Sprintf("%s is invalid", name)}
+	}
+	return nil
+}
+
+// customFieldTypeError reports a custom field value of the wrong JSON type
+func customFieldTypeError(name, expected string) *validator.ValidationError {
+	return &validator.ValidationError{
+		Field:   name,
+		Tag:     "type",
+		Message: fmt.Sprintf("%s must be %s", name, expected),
+	}
+}
+
+// formatBound formats a rule bound as a validation tag parameter
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+var (
+	ErrDependenciesUnavailable = errors.New("dependencies are not available")
+	ErrInvalidDependency       = errors.New("exactly one of blocked_by and blocks is required")
+	ErrSelfDependency          = errors.New("a todo cannot depend on itself")
+	ErrDependencyTodoNotFound  = errors.New("related todo not found")
+	ErrDependencyExists        = errors.New("dependency already exists")
+	ErrDependencyNotFound      = errors.New("dependency not found")
+	ErrDependencyCycle         = errors.New("dependency cycle")
+)
+
+// WithDependencies enables dependencies between todos and the blocked flag of todos
+func WithDependencies(dependencies repository.DependencyRepository) TodoServiceOption {
+	return func(s *todoService) {
+		s.dependencies = dependencies
+	}
+}
+
+// GetDependencies retrieves the todos blocking and blocked by a todo
+func (s *todoService) GetDependencies(ctx context.Context, id uint, userID uint) (*model.TodoDependenciesResponse, error) {
+	if s.dependencies == nil {
+		return nil, ErrDependenciesUnavailable
+	}
+
+	if _, err := s.getOwnedTodo(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	blockedBy, err := s.dependencies.ListBlockers(ctx, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocking todos: %w", err)
+	}
+	blocks, err := s.dependencies.ListBlocked(ctx, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked todos: %w", err)
+	}
+
+	response := &model.TodoDependenciesResponse{
+		TodoID:    id,
+		BlockedBy: append([]*model.Todo{}, blockedBy...),
+		Blocks:    append([]*model.Todo{}, blocks...),
+	}
+	for _, blocker := range blockedBy {
+		if !blocker.Completed {
+			response.Blocked = true
+		}
+	}
+	if err := s.markBlocked(ctx, userID, append(response.BlockedBy, response.Blocks...)...); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// AddDependency makes a todo blocked by another todo of the same user, or makes
+// it block another todo. A dependency that would close a cycle is rejected.
+func (s *todoService) AddDependency(ctx context.Context, id uint, req *model.AddDependencyRequest, userID uint) (*model.TodoDependency, error) {
+	if s.dependencies == nil {
+		return nil, ErrDependenciesUnavailable
+	}
+	if (req.BlockedBy == 0) == (req.Blocks == 0) {
+		return nil, ErrInvalidDependency
+	}
+
+	dependency := &model.TodoDependency{UserID: userID, TodoID: id, BlockedByID: req.BlockedBy}
+	otherID := req.BlockedBy
+	if req.Blocks != 0 {
+		dependency.TodoID, dependency.BlockedByID = req.Blocks, id
+		otherID = req.Blocks
+	}
+	if otherID == id {
+		return nil, ErrSelfDependency
+	}
+
+	if _, err := s.getOwnedTodo(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.todoRepo.GetByID(ctx, otherID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDependencyTodoNotFound
+		}
+		return nil, fmt.Errorf("failed to get related todo: %w", err)
+	}
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.dependencies.Lock(ctx, userID); err != nil {
+			return fmt.Errorf("failed to lock dependencies: %w", err)
+		}
+
+		// Trashed todos keep their dependencies and can be restored, so they
+		// take part in the cycle check
+		existing, err := s.dependencies.ListByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get dependencies: %w", err)
+		}
+		for _, edge := range existing {
+			if edge.TodoID == dependency.TodoID && edge.BlockedByID == dependency.BlockedByID {
+				return ErrDependencyExists
+			}
+		}
+		if cycle := dependencyCycle(existing, dependency.TodoID, dependency.BlockedByID); cycle != nil {
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, formatDependencyCycle(cycle))
+		}
+
+		if err := s.dependencies.Create(ctx, dependency); err != nil {
+			return fmt.Errorf("failed to create dependency: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dependency, nil
+}
+
+// RemoveDependency removes the dependency between two todos, whichever blocks the other
+func (s *todoService) RemoveDependency(ctx context.Context, id uint, otherID uint, userID uint) error {
+	if s.dependencies == nil {
+		return ErrDependenciesUnavailable
+	}
+
+	if _, err := s.getOwnedTodo(ctx, id, userID); err != nil {
+		return err
+	}
+
+	if err := s.dependencies.Delete(ctx, userID, id, otherID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDependencyNotFound
+		}
+		return fmt.Errorf("failed to delete dependency: %w", err)
+	}
+	return nil
+}
+
+// GetDependencyGraph retrieves the todos outside the trash that block or are
+// blocked by another todo, the dependencies between them and an execution
+// order in which every todo comes after its blockers. Todos that are free to
+// start at the same time are ordered by ID.
+func (s *todoService) GetDependencyGraph(ctx context.Context, userID uint) (*model.DependencyGraphResponse, error) {
+	if s.dependencies == nil {
+		return nil, ErrDependenciesUnavailable
+	}
+
+	todos, err := s.dependencies.ListLinkedTodos(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todos: %w", err)
+	}
+	dependencies, err := s.dependencies.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+
+	nodes := make(map[uint]*model.Todo, len(todos))
+	for _, todo := range todos {
+		nodes[todo.ID] = todo
+	}
+
+	response := &model.DependencyGraphResponse{
+		Todos: append([]*model.Todo{}, todos...),
+		Edges: []model.DependencyEdge{},
+	}
+	for _, dependency := range dependencies {
+		todo, blocker := nodes[dependency.TodoID], nodes[dependency.BlockedByID]
+		if todo == nil || blocker == nil {
+			continue
+		}
+		if !blocker.Completed {
+			todo.Blocked = true
+		}
+		response.Edges = append(response.Edges, model.DependencyEdge{
+			TodoID:      dependency.TodoID,
+			BlockedByID: dependency.BlockedByID,
+		})
+	}
+
+	order, err := dependencyOrder(todos, response.Edges)
+	if err != nil {
+		return nil, err
+	}
+	response.Order = order
+
+	return response, nil
+}
+
+// markBlocked sets the blocked flag of todos that have a blocker that is
+// neither completed nor trashed
+func (s *todoService) markBlocked(ctx context.Context, userID uint, todos ...*model.Todo) error {
+	if s.dependencies == nil || len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	blocked, err := s.dependencies.BlockedTodoIDs(ctx, userID, ids)
+	if err != nil {
+		return fmt.Errorf("failed to check dependencies: %w", err)
+	}
+
+	isBlocked := make(map[uint]bool, len(blocked))
+	for _, id := range blocked {
+		isBlocked[id] = true
+	}
+	for _, todo := range todos {
+		todo.Blocked = isBlocked[todo.ID]
+	}
+	return nil
+}
+
+// dependencyCycle returns the cycle that making todoID blocked by blockedByID
+// would close, starting and ending at todoID, or nil if there is none. There is
+// a cycle if blockedByID already waits for todoID.
+func dependencyCycle(dependencies []*model.TodoDependency, todoID, blockedByID uint) []uint {
+	blockers := make(map[uint][]uint)
+	for _, dependency := range dependencies {
+		blockers[dependency.TodoID] = append(blockers[dependency.TodoID], dependency.BlockedByID)
+	}
+
+	// Breadth-first search finds the shortest chain of blockers
+	previous := map[uint]uint{blockedByID: 0}
+	queue := []uint{blockedByID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == todoID {
+			// Walk back from todoID to blockedByID, then reverse the chain
+			chain := []uint{todoID}
+			for id := todoID; id != blockedByID; id = previous[id] {
+				chain = append(chain, previous[id])
+			}
+			cycle := []uint{todoID}
+			for i := len(chain) - 1; i >= 0; i-- {
+				cycle = append(cycle, chain[i])
+			}
+			return cycle
+		}
+		for _, next := range blockers[current] {
+			if _, seen := previous[next]; !seen {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// formatDependencyCycle describes a cycle of todo IDs in which each todo is blocked by the next
+func formatDependencyCycle(cycle []uint) string {
+	parts := make([]string, len(cycle))
+	for i, id := range cycle {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(parts, " -> ") + ", where each todo would be blocked by the next"
+}
+
+// dependencyOrder sorts todos topologically so that every todo comes after the
+// todos blocking it, using Kahn's algorithm with the lowest ID first among the
+// todos that are ready
+func dependencyOrder(todos []*model.Todo, edges []model.DependencyEdge) ([]uint, error) {
+	waiting := make(map[uint]int, len(todos))
+	unblocks := make(map[uint][]uint)
+	for _, edge := range edges {
+		waiting[edge.TodoID]++
+		unblocks[edge.BlockedByID] = append(unblocks[edge.BlockedByID], edge.TodoID)
+	}
+
+	var ready []uint
+	for _, todo := range todos {
+		if waiting[todo.ID] == 0 {
+			ready = append(ready, todo.ID)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool { return ready[i] < ready[j] })
+
+	order := make([]uint, 0, len(todos))
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+
+		for _, next := range unblocks[id] {
+			if waiting[next]--; waiting[next] == 0 {
+				i := sort.Search(len(ready), func(i int) bool { return ready[i] >= next })
+				ready = append(ready, 0)
+				copy(ready[i+1:], ready[i:])
+				ready[i] = next
+			}
+		}
+	}
+
+	// Cycles are rejected when dependencies are added, so every todo is ordered
+	if len(order) != len(todos) {
+		return nil, fmt.Errorf("%w: the stored dependencies contain a cycle", ErrDependencyCycle)
+	}
+	return order, nil
+}
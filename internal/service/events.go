@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+
+	"todo-api-backend/internal/events"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+// historyEventTypes maps todo history actions to the change events published for them.
+// Purges are not published; clients already saw the todo deleted when it was trashed.
+var historyEventTypes = map[string]string{
+	model.HistoryActionCreated:    events.TypeTodoCreated,
+	model.HistoryActionUpdated:    events.TypeTodoUpdated,
+	model.HistoryActionRestored:   events.TypeTodoUpdated,
+	model.HistoryActionArchived:   events.TypeTodoUpdated,
+	model.HistoryActionUnarchived: events.TypeTodoUpdated,
+	model.HistoryActionDeleted:    events.TypeTodoDeleted,
+}
+
+// WithEvents publishes a change event for every todo change once the change is committed
+func WithEvents(publisher events.Publisher) TodoServiceOption {
+	return func(s *todoService) {
+		s.publisher = publisher
+	}
+}
+
+// pendingEventsKey is the context key for the events of the running transaction
+type pendingEventsKey struct{}
+
+// pendingEvents collects the events emitted inside a transaction
+type pendingEvents struct {
+	events []events.Event
+}
+
+// eventTransactor holds back the events emitted inside a transaction until it
+// commits. Events of a nested transaction that is rolled back are discarded,
+// as are all events of a rolled back outermost transaction.
+type eventTransactor struct {
+	tx        repository.Transactor
+	publisher events.Publisher
+}
+
+// WithinTransaction runs fn in a transaction and publishes its events after the commit
+func (t eventTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent, nested := ctx.Value(pendingEventsKey{}).(*pendingEvents)
+
+	pending := &pendingEvents{}
+	if err := t.tx.WithinTransaction(context.WithValue(ctx, pendingEventsKey{}, pending), fn); err != nil {
+		return err
+	}
+
+	if nested {
+		parent.events = append(parent.events, pending.events...)
+		return nil
+	}
+	for _, event := range pending.events {
+		t.publisher.Publish(event)
+	}
+	return nil
+}
+
+// publishChange emits the change event for a history action, if any. A nil
+// todo publishes the event without a snapshot of the todo.
+func (s *todoService) publishChange(ctx context.Context, action string, todoID, userID uint, todo *model.Todo) {
+	if s.publisher == nil {
+		return
+	}
+	eventType, ok := historyEventTypes[action]
+	if !ok {
+		return
+	}
+
+	event := events.Event{
+		Type:   eventType,
+		UserID: userID,
+		TodoID: todoID,
+	}
+	if todo != nil {
+		snapshot := *todo
+		event.Todo = &snapshot
+	}
+
+	if pending, ok := ctx.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		pending.events = append(pending.events, event)
+		return
+	}
+	s.publisher.Publish(event)
+}
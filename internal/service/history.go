@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"todo-api-backend/internal/model"
+)
+
+// historyField describes a todo field tracked in the change history
+type historyField struct {
+	name  string
+	value func(todo *model.Todo) interface{}
+}
+
+// historyFields lists the todo fields tracked in the change history, in display order
+var historyFields = []historyField{
+	{"title", func(t *model.Todo) interface{} { return t.Title }},
+	{"description", func(t *model.Todo) interface{} { return t.Description }},
+	{"completed", func(t *model.Todo) interface{} { return t.Completed }},
+	{"list_id", func(t *model.Todo) interface{} {
+		if t.ListID == nil {
+			return nil
+		}
+		return *t.ListID
+	}},
+	{"state", func(t *model.Todo) interface{} {
+		if t.State == "" {
+			return nil
+		}
+		return t.State
+	}},
+	{"custom_fields", func(t *model.Todo) interface{} {
+		if len(t.CustomFields) == 0 {
+			return nil
+		}
+		return map[string]interface{}(t.CustomFields)
+	}},
+	{"due_at", func(t *model.Todo) interface{} { return historyTime(t.DueAt) }},
+	{"archived_at", func(t *model.Todo) interface{} { return historyTime(t.ArchivedAt) }},
+	{"deleted_at", func(t *model.Todo) interface{} {
+		if !t.DeletedAt.Valid {
+			return nil
+		}
+		return historyTime(&t.DeletedAt.Time)
+	}},
+}
+
+// diffTodos returns the field-level changes between two versions of a todo.
+// A nil version contributes nil values, so creations and purges list every set field.
+func diffTodos(before, after *model.Todo) model.FieldChanges {
+	changes := model.FieldChanges{}
+	for _, field := range historyFields {
+		var oldValue, newValue interface{}
+		if before != nil {
+			oldValue = field.value(before)
+		}
+		if after != nil {
+			newValue = field.value(after)
+		}
+
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, model.FieldChange{
+				Field: field.name,
+				Old:   oldValue,
+				New:   newValue,
+			})
+		}
+	}
+	return changes
+}
+
+// historyTime formats an optional timestamp for the change history
+func historyTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// noopTransactor runs functions without a transaction; it is used when the
+// todo service is built without a transactor
+type noopTransactor struct{}
+
+// WithinTransaction runs fn directly
+func (noopTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
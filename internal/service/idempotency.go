@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+const (
+	// DefaultIdempotencyTTL is how long idempotency keys are kept when no TTL is configured
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// pendingIdempotencyTimeout is how long a reserved key may go without a
+	// response or a renewal before it is treated as abandoned, e.g. after a
+	// crash. Requests that are still running renew their key well within it.
+	pendingIdempotencyTimeout = time.Minute
+)
+
+var (
+	ErrIdempotencyKeyBusy = errors.New("idempotency key is busy")
+)
+
+// idempotencyService implements the IdempotencyService interface
+type idempotencyService struct {
+	repo repository.IdempotencyRepository
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewIdempotencyService creates a new idempotency service. Keys expire after
+// ttl, or DefaultIdempotencyTTL if ttl is not positive.
+func NewIdempotencyService(repo repository.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &idempotencyService{
+		repo: repo,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Begin reserves a user's idempotency key. Expired records and reservations
+// that were abandoned without a response are replaced.
+func (s *idempotencyService) Begin(ctx context.Context, userID uint, key string, fingerprint string) (*model.IdempotencyRecord, bool, error) {
+	// A second attempt is only needed when a stale record was removed, or the
+	// key was released between the insert and the lookup
+	for attempt := 0; attempt < 2; attempt++ {
+		now := s.now()
+		record := &model.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			RenewedAt:   now,
+			ExpiresAt:   now.Add(s.ttl),
+		}
+
+		created, err := s.repo.Create(ctx, record)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+		if created {
+			return record, false, nil
+		}
+
+		existing, err := s.repo.GetByKey(ctx, userID, key)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+
+		stale := now.After(existing.ExpiresAt) ||
+			(!existing.IsComplete() && now.Sub(existing.RenewedAt) > pendingIdempotencyTimeout)
+		if !stale {
+			return existing, true, nil
+		}
+
+		if err := s.repo.Delete(ctx, existing.ID); err != nil {
+			return nil, false, fmt.Errorf("failed to delete stale idempotency key: %w", err)
+		}
+	}
+
+	return nil, false, ErrIdempotencyKeyBusy
+}
+
+// Renew marks the request of a reserved key as still running
+func (s *idempotencyService) Renew(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := s.repo.Renew(ctx, record.ID, s.now()); err != nil {
+		return fmt.Errorf("failed to renew idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Complete stores the response of a reserved request
+func (s *idempotencyService) Complete(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := s.repo.Update(ctx, record); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Abandon releases a reserved key so that the request can be retried
+func (s *idempotencyService) Abandon(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := s.repo.Delete(ctx, record.ID); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -2,9 +2,15 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"todo-api-backend/internal/audit"
+	"todo-api-backend/internal/events"
 	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/realtime"
 	"todo-api-backend/internal/repository"
+	"todo-api-backend/internal/storage"
 	"todo-api-backend/pkg/jwt"
 )
 
@@ -12,42 +18,432 @@ import (
 type AuthService interface {
 	// Register creates a new user account with email validation and password hashing
 	Register(ctx context.Context, req *model.RegisterRequest) (*model.AuthResponse, error)
-	
+
 	// Login authenticates a user with credential verification and JWT generation
 	Login(ctx context.Context, req *model.LoginRequest) (*model.AuthResponse, error)
-	
+
 	// ValidateToken validates a JWT token and returns the claims
 	ValidateToken(tokenString string) (*jwt.Claims, error)
+
+	// ChangePassword changes the password of a user after verifying the current password
+	ChangePassword(ctx context.Context, userID uint, req *model.ChangePasswordRequest) error
+}
+
+// AuditService defines the interface for the security audit log
+type AuditService interface {
+	// Record stores a security event; failures are logged, never returned
+	Record(ctx context.Context, event *model.SecurityEvent)
+
+	// Query retrieves the security events matching the filter, newest first
+	Query(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error)
+}
+
+// IdempotencyService defines the interface for idempotency key handling
+type IdempotencyService interface {
+	// Begin reserves a user's idempotency key for a request fingerprint. If
+	// the key is already in use it returns the existing record and true.
+	Begin(ctx context.Context, userID uint, key string, fingerprint string) (*model.IdempotencyRecord, bool, error)
+
+	// Renew keeps the reservation of a request that is still running from
+	// being treated as abandoned
+	Renew(ctx context.Context, record *model.IdempotencyRecord) error
+
+	// Complete stores the response of a reserved request for replay
+	Complete(ctx context.Context, record *model.IdempotencyRecord) error
+
+	// Abandon releases a reserved key without storing a response
+	Abandon(ctx context.Context, record *model.IdempotencyRecord) error
 }
 
 // TodoService defines the interface for todo business logic operations
 type TodoService interface {
 	// Create creates a new todo for the authenticated user
 	Create(ctx context.Context, req *model.CreateTodoRequest, userID uint) (*model.Todo, error)
-	
+
 	// GetByID retrieves a specific todo by ID, ensuring user ownership
 	GetByID(ctx context.Context, id uint, userID uint) (*model.Todo, error)
-	
+
 	// GetByUserID retrieves all todos belonging to the authenticated user
 	GetByUserID(ctx context.Context, userID uint) ([]*model.Todo, error)
-	
+
+	// List retrieves the todos of the authenticated user matching the filter
+	List(ctx context.Context, userID uint, filter *model.TodoFilter) ([]*model.Todo, error)
+
 	// Update updates an existing todo, ensuring user ownership
 	Update(ctx context.Context, id uint, req *model.UpdateTodoRequest, userID uint) (*model.Todo, error)
-	
-	// Delete deletes a todo by ID, ensuring user ownership
+
+	// Replace replaces every editable field of a todo, ensuring user ownership
+	Replace(ctx context.Context, id uint, req *model.ReplaceTodoRequest, userID uint) (*model.Todo, error)
+
+	// Patch applies a JSON Merge Patch or JSON Patch document to a todo, ensuring user ownership
+	Patch(ctx context.Context, id uint, patchType string, patch []byte, userID uint) (*model.Todo, error)
+
+	// Delete moves a todo to the trash, ensuring user ownership
+	Delete(ctx context.Context, id uint, userID uint) error
+
+	// GetTrash retrieves all trashed todos belonging to the authenticated user
+	GetTrash(ctx context.Context, userID uint) ([]*model.Todo, error)
+
+	// Restore moves a trashed todo back to the active list, ensuring user ownership
+	Restore(ctx context.Context, id uint, userID uint) (*model.Todo, error)
+
+	// Purge permanently deletes a trashed todo, ensuring user ownership
+	Purge(ctx context.Context, id uint, userID uint) error
+
+	// EmptyTrash permanently deletes all trashed todos of the authenticated user
+	EmptyTrash(ctx context.Context, userID uint) (int64, error)
+
+	// Archive archives a todo, ensuring user ownership
+	Archive(ctx context.Context, id uint, userID uint) (*model.Todo, error)
+
+	// Unarchive moves an archived todo back to the active list, ensuring user ownership
+	Unarchive(ctx context.Context, id uint, userID uint) (*model.Todo, error)
+
+	// ArchiveCompleted archives all completed todos older than the given number of days
+	ArchiveCompleted(ctx context.Context, userID uint, olderThanDays int) (int64, error)
+
+	// GetArchivePolicy retrieves the auto-archive policy of the authenticated user
+	GetArchivePolicy(ctx context.Context, userID uint) (*model.ArchivePolicyResponse, error)
+
+	// UpdateArchivePolicy updates the auto-archive policy of the authenticated user
+	UpdateArchivePolicy(ctx context.Context, userID uint, req *model.ArchivePolicyRequest) (*model.ArchivePolicyResponse, error)
+
+	// GetHistory retrieves the change history of a todo, ensuring user ownership
+	GetHistory(ctx context.Context, id uint, userID uint) ([]*model.TodoHistory, error)
+
+	// Search performs a full-text search over the todos of the authenticated user
+	Search(ctx context.Context, userID uint, req *model.SearchTodosRequest) ([]*model.TodoSearchResult, error)
+
+	// Bulk runs many create/update/delete/complete operations in a single transaction
+	Bulk(ctx context.Context, userID uint, req *model.BulkRequest) (*model.BulkResponse, error)
+
+	// PullChanges returns the todos created, updated and deleted since a sync token
+	PullChanges(ctx context.Context, userID uint, since string) (*model.SyncResponse, error)
+
+	// PushChanges applies a batch of offline client mutations and reports conflicts
+	PushChanges(ctx context.Context, userID uint, req *model.SyncPushRequest) (*model.SyncPushResponse, error)
+
+	// Export calls fn for every todo of the authenticated user matching the filter without loading all of them into memory
+	Export(ctx context.Context, userID uint, filter *model.TodoFilter, fn func(*model.Todo) error) error
+
+	// Import validates the rows of an import file and creates a todo for each valid row
+	Import(ctx context.Context, userID uint, req *model.ImportRequest) (*model.ImportResponse, error)
+
+	// QuickAdd creates a todo from a single line of text and returns how the text was read
+	QuickAdd(ctx context.Context, userID uint, req *model.QuickAddRequest) (*model.QuickAddResponse, error)
+
+	// GetDependencies retrieves the todos blocking and blocked by a todo, ensuring user ownership
+	GetDependencies(ctx context.Context, id uint, userID uint) (*model.TodoDependenciesResponse, error)
+
+	// AddDependency makes a todo blocked by or blocking another todo of the user, rejecting cycles
+	AddDependency(ctx context.Context, id uint, req *model.AddDependencyRequest, userID uint) (*model.TodoDependency, error)
+
+	// RemoveDependency removes the dependency between two todos, ensuring user ownership
+	RemoveDependency(ctx context.Context, id uint, otherID uint, userID uint) error
+
+	// GetDependencyGraph retrieves the dependencies between the todos of the user in execution order
+	GetDependencyGraph(ctx context.Context, userID uint) (*model.DependencyGraphResponse, error)
+
+	// MoveTodo moves a todo into a list, to another state of its list or out of its list, ensuring user ownership
+	MoveTodo(ctx context.Context, id uint, req *model.MoveTodoRequest, userID uint) (*model.Todo, error)
+
+	// GetBoard retrieves the todos of a list grouped by workflow state, ensuring user ownership
+	GetBoard(ctx context.Context, listID uint, userID uint) (*model.BoardResponse, error)
+
+	// ListComments retrieves a page of the comment threads of a todo, ensuring user access
+	ListComments(ctx context.Context, id uint, userID uint, page, perPage int) (*model.CommentPage, error)
+
+	// AddComment comments on a todo or replies to a comment, notifying the mentioned users
+	AddComment(ctx context.Context, id uint, req *model.CreateCommentRequest, userID uint) (*model.TodoComment, error)
+
+	// UpdateComment replaces the body of a comment, ensuring the user wrote it
+	UpdateComment(ctx context.Context, id uint, commentID uint, req *model.UpdateCommentRequest, userID uint) (*model.TodoComment, error)
+
+	// DeleteComment removes a comment and its replies, ensuring the user wrote it
+	DeleteComment(ctx context.Context, id uint, commentID uint, userID uint) error
+}
+
+// WebhookService defines the interface for webhook subscriptions and their delivery queue
+type WebhookService interface {
+	WebhookQueue
+	MentionNotifier
+
+	// Create registers a webhook for the user and generates its signing secret
+	Create(ctx context.Context, userID uint, req *model.CreateWebhookRequest) (*model.Webhook, error)
+
+	// List retrieves all webhooks of the user
+	List(ctx context.Context, userID uint) ([]*model.Webhook, error)
+
+	// Get retrieves a webhook, ensuring user ownership
+	Get(ctx context.Context, id uint, userID uint) (*model.Webhook, error)
+
+	// Update replaces the URL, events and state of a webhook, ensuring user ownership
+	Update(ctx context.Context, id uint, userID uint, req *model.UpdateWebhookRequest) (*model.Webhook, error)
+
+	// Delete removes a webhook and its delivery log, ensuring user ownership
+	Delete(ctx context.Context, id uint, userID uint) error
+
+	// ListDeliveries retrieves the most recent deliveries of a webhook, ensuring user ownership
+	ListDeliveries(ctx context.Context, id uint, userID uint, limit int) ([]*model.WebhookDelivery, error)
+}
+
+// CalendarService defines the interface for the per-user calendar feeds of todos
+type CalendarService interface {
+	// GetFeed reports whether the user has a calendar feed
+	GetFeed(ctx context.Context, userID uint) (*model.CalendarFeedResponse, error)
+
+	// RegenerateToken creates the calendar feed of the user or replaces its
+	// secret token, so that the previous feed URL stops working
+	RegenerateToken(ctx context.Context, userID uint) (*model.CalendarFeedResponse, error)
+
+	// DisableFeed removes the calendar feed of the user
+	DisableFeed(ctx context.Context, userID uint) error
+
+	// ResolveToken returns the user a calendar feed token belongs to
+	ResolveToken(ctx context.Context, token string) (uint, error)
+
+	// Stamp summarises the todos in the calendar feed of the user
+	Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error)
+
+	// FeedTodos calls fn for every todo in the calendar feed of the user
+	FeedTodos(ctx context.Context, userID uint, fn func(*model.Todo) error) error
+}
+
+// CalDAVService defines the interface for the CalDAV collection of todos and its app passwords
+type CalDAVService interface {
+	// Authenticate verifies an email address and app password and returns the user they belong to
+	Authenticate(ctx context.Context, email, password string) (uint, error)
+
+	// CreatePassword generates a new app password for the user
+	CreatePassword(ctx context.Context, userID uint, req *model.CreateCalDAVPasswordRequest) (*model.CalDAVPasswordResponse, error)
+
+	// ListPasswords retrieves the app passwords of the user, without their secrets
+	ListPasswords(ctx context.Context, userID uint) ([]*model.CalDAVPasswordResponse, error)
+
+	// DeletePassword revokes an app password, ensuring user ownership
+	DeletePassword(ctx context.Context, id uint, userID uint) error
+
+	// Stamp summarises the todos in the collection of the user
+	Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error)
+
+	// List calls fn for every calendar object in the collection of the user
+	List(ctx context.Context, userID uint, fn func(*model.CalDAVObject) error) error
+
+	// Get retrieves the calendar object with the given name from the collection of the user
+	Get(ctx context.Context, userID uint, name string) (*model.CalDAVObject, error)
+
+	// Put stores a calendar object and reports whether a todo was created
+	Put(ctx context.Context, userID uint, name string, data []byte, createOnly bool) (*model.CalDAVObject, bool, error)
+
+	// Delete moves the todo of a calendar object to the trash
+	Delete(ctx context.Context, userID uint, name string) error
+}
+
+// TemplateService defines the interface for todo templates
+type TemplateService interface {
+	// Create stores a new template for the user
+	Create(ctx context.Context, userID uint, req *model.CreateTemplateRequest) (*model.TodoTemplate, error)
+
+	// List retrieves all templates of the user
+	List(ctx context.Context, userID uint) ([]*model.TodoTemplate, error)
+
+	// Get retrieves a template, ensuring user ownership
+	Get(ctx context.Context, id uint, userID uint) (*model.TodoTemplate, error)
+
+	// Update replaces the name, description and items of a template, ensuring user ownership
+	Update(ctx context.Context, id uint, userID uint, req *model.UpdateTemplateRequest) (*model.TodoTemplate, error)
+
+	// Delete removes a template, ensuring user ownership; todos created from it are kept
 	Delete(ctx context.Context, id uint, userID uint) error
+
+	// Instantiate creates a todo for every item of a template in a single transaction
+	Instantiate(ctx context.Context, id uint, userID uint, req *model.InstantiateTemplateRequest) (*model.InstantiateTemplateResponse, error)
+}
+
+// ListService defines the interface for todo lists and their workflows
+type ListService interface {
+	// Create stores a new list for the user
+	Create(ctx context.Context, userID uint, req *model.CreateListRequest) (*model.TodoList, error)
+
+	// List retrieves all lists of the user
+	List(ctx context.Context, userID uint) ([]*model.TodoList, error)
+
+	// Get retrieves a list, ensuring user ownership
+	Get(ctx context.Context, id uint, userID uint) (*model.TodoList, error)
+
+	// Update renames a list or replaces its workflow, ensuring user ownership
+	Update(ctx context.Context, id uint, userID uint, req *model.UpdateListRequest) (*model.TodoList, error)
+
+	// Delete removes a list, ensuring user ownership; its todos are kept outside any list
+	Delete(ctx context.Context, id uint, userID uint) error
+}
+
+// CustomFieldService defines the interface for user-defined custom fields of todos
+type CustomFieldService interface {
+	// Create stores a new custom field for the user or one of their lists
+	Create(ctx context.Context, userID uint, req *model.CreateCustomFieldRequest) (*model.CustomField, error)
+
+	// List retrieves all custom fields of the user
+	List(ctx context.Context, userID uint) ([]*model.CustomField, error)
+
+	// Get retrieves a custom field, ensuring user ownership
+	Get(ctx context.Context, id uint, userID uint) (*model.CustomField, error)
+
+	// Update renames a custom field or changes its rules, ensuring user ownership
+	Update(ctx context.Context, id uint, userID uint, req *model.UpdateCustomFieldRequest) (*model.CustomField, error)
+
+	// Delete removes a custom field and its values on every todo, ensuring user ownership
+	Delete(ctx context.Context, id uint, userID uint) error
+}
+
+// AttachmentService defines the interface for files attached to todos
+type AttachmentService interface {
+	// MaxSize returns the size of the largest attachment accepted, in bytes
+	MaxSize() int64
+
+	// Upload stores a file as an attachment of a todo, enforcing the size, type and quota limits
+	Upload(ctx context.Context, todoID uint, userID uint, filename string, r io.Reader, size int64) (*model.Attachment, error)
+
+	// List retrieves the attachments of a todo with signed download URLs, ensuring user access
+	List(ctx context.Context, todoID uint, userID uint) (*model.AttachmentListResponse, error)
+
+	// Get retrieves an attachment of a todo with a signed download URL, ensuring user access
+	Get(ctx context.Context, todoID uint, id uint, userID uint) (*model.Attachment, error)
+
+	// Delete removes an attachment and its contents, ensuring user access
+	Delete(ctx context.Context, todoID uint, id uint, userID uint) error
+
+	// Open verifies a signed download URL and opens the contents of its attachment; the caller closes them
+	Open(ctx context.Context, id uint, expires int64, signature string) (*model.Attachment, io.ReadCloser, error)
 }
 
 // Services holds all service interfaces for dependency injection
 type Services struct {
-	Auth AuthService
-	Todo TodoService
+	Auth        AuthService
+	Todo        TodoService
+	Audit       AuditService
+	Idempotency IdempotencyService
+	Webhook     WebhookService
+	Calendar    CalendarService
+	CalDAV      CalDAVService
+	Template    TemplateService
+	List        ListService
+	CustomField CustomFieldService
+	Attachment  AttachmentService
+	Events      *events.Bus
+	Broker      realtime.Broker
+}
+
+// ServicesOption configures optional dependencies shared by the services
+type ServicesOption func(*servicesConfig)
+
+// servicesConfig holds the optional dependencies passed to NewServices
+type servicesConfig struct {
+	auditSinks     []audit.Sink
+	idempotencyTTL time.Duration
+	trashRetention time.Duration
+	replayBuffer   int
+	broker         realtime.Broker
+	outbox         bool
+	blobStore      storage.BlobStore
+	attachments    AttachmentLimits
+}
+
+// WithAuditSinks writes security events to the given sinks in addition to the database
+func WithAuditSinks(sinks ...audit.Sink) ServicesOption {
+	return func(c *servicesConfig) {
+		c.auditSinks = append(c.auditSinks, sinks...)
+	}
+}
+
+// WithIdempotencyTTL sets how long idempotency keys and their responses are kept
+func WithIdempotencyTTL(ttl time.Duration) ServicesOption {
+	return func(c *servicesConfig) {
+		c.idempotencyTTL = ttl
+	}
+}
+
+// WithTrashRetention tells the services how long trashed todos are kept before
+// they are purged, which bounds how old a sync token may be
+func WithTrashRetention(retention time.Duration) ServicesOption {
+	return func(c *servicesConfig) {
+		c.trashRetention = retention
+	}
+}
+
+// WithEventReplayBuffer sets how many recent change events are kept for resuming event streams
+func WithEventReplayBuffer(size int) ServicesOption {
+	return func(c *servicesConfig) {
+		c.replayBuffer = size
+	}
+}
+
+// WithBroker distributes live messages through a shared broker, so that
+// sessions on every server instance receive them. Change events are then
+// published through the broker and must be forwarded to the local bus with
+// realtime.ForwardEvents.
+func WithBroker(broker realtime.Broker) ServicesOption {
+	return func(c *servicesConfig) {
+		c.broker = broker
+	}
+}
+
+// WithDomainEvents writes a domain event to the transactional outbox for every
+// todo and user change. The events accumulate until they are published by the
+// outbox relay, so this should only be enabled together with the relay.
+func WithDomainEvents() ServicesOption {
+	return func(c *servicesConfig) {
+		c.outbox = true
+	}
+}
+
+// WithAttachments enables file attachments on todos, keeping their contents in store
+func WithAttachments(store storage.BlobStore, limits AttachmentLimits) ServicesOption {
+	return func(c *servicesConfig) {
+		c.blobStore = store
+		c.attachments = limits
+	}
 }
 
 // NewServices creates a new instance of Services with all implementations
-func NewServices(repos *repository.Repositories, tokenManager *jwt.TokenManager) *Services {
+func NewServices(repos *repository.Repositories, tokenManager *jwt.TokenManager, opts ...ServicesOption) *Services {
+	cfg := &servicesConfig{replayBuffer: events.DefaultReplaySize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	auditService := NewAuditService(repos.Audit, cfg.auditSinks...)
+	webhookService := NewWebhookService(repos.Webhook, repos.Delivery)
+	bus := events.NewBus(cfg.replayBuffer)
+	var publisher events.Publisher = bus
+	if cfg.broker != nil {
+		publisher = realtime.NewEventRelay(cfg.broker)
+	} else {
+		cfg.broker = realtime.NewMemoryBroker()
+	}
+
+	authOpts := []AuthServiceOption{WithAuditLog(auditService)}
+	todoOpts := []TodoServiceOption{WithTransactor(repos.Tx), WithHistory(repos.History), WithSearch(repos.Search), WithSyncHorizon(cfg.trashRetention), WithEvents(publisher), WithWebhooks(webhookService), WithDependencies(repos.Dependency), WithLists(repos.List), WithCustomFields(repos.CustomField), WithComments(repos.Comment), WithMentionNotifier(webhookService)}
+	if cfg.outbox {
+		authOpts = append(authOpts, WithUserOutbox(repos.Tx, repos.Outbox))
+		todoOpts = append(todoOpts, WithOutbox(repos.Outbox))
+	}
+
+	todoService := NewTodoService(repos.Todo, repos.User, todoOpts...)
+
 	return &Services{
-		Auth: NewAuthService(repos.User, tokenManager),
-		Todo: NewTodoService(repos.Todo, repos.User),
+		Auth:        NewAuthService(repos.User, tokenManager, authOpts...),
+		Todo:        todoService,
+		Audit:       auditService,
+		Idempotency: NewIdempotencyService(repos.Idempotency, cfg.idempotencyTTL),
+		Webhook:     webhookService,
+		Calendar:    NewCalendarService(repos.Calendar, repos.Todo),
+		CalDAV:      NewCalDAVService(todoService, repos.Todo, repos.User, repos.CalDAVPassword, repos.CalDAVResource, repos.Calendar, repos.Tx),
+		Template:    NewTemplateService(repos.Template, todoService, repos.Tx),
+		List:        NewListService(repos.List, repos.Tx),
+		CustomField: NewCustomFieldService(repos.CustomField, repos.List, repos.Tx),
+		Attachment:  NewAttachmentService(repos.Attachment, repos.Todo, repos.Tx, cfg.blobStore, cfg.attachments),
+		Events:      bus,
+		Broker:      cfg.broker,
 	}
-}
\ No newline at end of file
+}
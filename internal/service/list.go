@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+// maxListsPerUser limits how many lists a user may have
+const maxListsPerUser = 100
+
+var (
+	ErrListNotFound       = errors.New("list not found")
+	ErrListLimit          = errors.New("list limit reached")
+	ErrInvalidWorkflow    = errors.New("invalid workflow")
+	ErrWorkflowStateInUse = errors.New("workflow states are in use")
+)
+
+// workflowStateKey matches the keys of workflow states, such as in_progress
+var workflowStateKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// listService implements the ListService interface
+type listService struct {
+	repo repository.ListRepository
+	tx   repository.Transactor
+}
+
+// NewListService creates a new list service
+func NewListService(repo repository.ListRepository, tx repository.Transactor) ListService {
+	return &listService{
+		repo: repo,
+		tx:   tx,
+	}
+}
+
+// Create stores a new list, with the default workflow if the request has none
+func (s *listService) Create(ctx context.Context, userID uint, req *model.CreateListRequest) (*model.TodoList, error) {
+	workflow := model.DefaultWorkflow()
+	if req.Workflow != nil {
+		workflow = *req.Workflow
+	}
+	if err := checkWorkflow(&workflow); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lists: %w", err)
+	}
+	if count >= maxListsPerUser {
+		return nil, ErrListLimit
+	}
+
+	list := &model.TodoList{
+		UserID:   userID,
+		Name:     req.Name,
+		Workflow: workflow,
+	}
+	if err := s.repo.Create(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to create list: %w", err)
+	}
+	return list, nil
+}
+
+// List retrieves all lists of a user
+func (s *listService) List(ctx context.Context, userID uint) ([]*model.TodoList, error) {
+	lists, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lists: %w", err)
+	}
+	return lists, nil
+}
+
+// Get retrieves a list, ensuring it belongs to the user
+func (s *listService) Get(ctx context.Context, id uint, userID uint) (*model.TodoList, error) {
+	list, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+	return list, nil
+}
+
+// Update renames a list or replaces its workflow. Every state that todos of
+// the list are in must be kept with the same terminal flag, so that no todo is
+// left in an unknown state or changes its completion.
+func (s *listService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateListRequest) (*model.TodoList, error) {
+	if req.Workflow != nil {
+		if err := checkWorkflow(req.Workflow); err != nil {
+			return nil, err
+		}
+	}
+
+	var list *model.TodoList
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = s.Get(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		if req.Name != nil {
+			list.Name = *req.Name
+		}
+		if req.Workflow != nil {
+			states, err := s.repo.StatesInUse(ctx, list.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get states in use: %w", err)
+			}
+			if err := checkStatesKept(&list.Workflow, req.Workflow, states); err != nil {
+				return err
+			}
+			list.Workflow = *req.Workflow
+		}
+
+		if err := s.repo.Update(ctx, list); err != nil {
+			return fmt.Errorf("failed to update list: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Delete removes a list. Its todos are kept outside any list with their completion unchanged.
+func (s *listService) Delete(ctx context.Context, id uint, userID uint) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		list, err := s.Get(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.DetachTodos(ctx, list.ID); err != nil {
+			return fmt.Errorf("failed to detach todos: %w", err)
+		}
+		if err := s.repo.Delete(ctx, list.ID, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrListNotFound
+			}
+			return fmt.Errorf("failed to delete list: %w", err)
+		}
+		return nil
+	})
+}
+
+// checkWorkflow checks the parts of a workflow that struct validation cannot:
+// unique state keys, a non-terminal initial state, at least one terminal state
+// and transitions between distinct known states
+func checkWorkflow(workflow *model.Workflow) error {
+	keys := make(map[string]bool, len(workflow.States))
+	terminal := false
+	for _, state := range workflow.States {
+		if !workflowStateKey.MatchString(state.Key) {
+			return fmt.Errorf("%w: state key %q must be lowercase letters, digits and underscores", ErrInvalidWorkflow, state.Key)
+		}
+		if keys[state.Key] {
+			return fmt.Errorf("%w: duplicate state %q", ErrInvalidWorkflow, state.Key)
+		}
+		keys[state.Key] = true
+		terminal = terminal || state.Terminal
+	}
+	if len(workflow.States) == 0 || workflow.States[0].Terminal {
+		return fmt.Errorf("%w: the first state must not be terminal", ErrInvalidWorkflow)
+	}
+	if !terminal {
+		return fmt.Errorf("%w: at least one state must be terminal", ErrInvalidWorkflow)
+	}
+
+	transitions := make(map[model.WorkflowTransition]bool, len(workflow.Transitions))
+	for _, transition := range workflow.Transitions {
+		if !keys[transition.From] {
+			return fmt.Errorf("%w: transition from unknown state %q", ErrInvalidWorkflow, transition.From)
+		}
+		if !keys[transition.To] {
+			return fmt.Errorf("%w: transition to unknown state %q", ErrInvalidWorkflow, transition.To)
+		}
+		if transition.From == transition.To {
+			return fmt.Errorf("%w: transition from %q to itself", ErrInvalidWorkflow, transition.From)
+		}
+		if transitions[transition] {
+			return fmt.Errorf("%w: duplicate transition from %q to %q", ErrInvalidWorkflow, transition.From, transition.To)
+		}
+		transitions[transition] = true
+	}
+	return nil
+}
+
+// checkStatesKept checks that a new workflow keeps every state in use with its terminal flag
+func checkStatesKept(current, next *model.Workflow, inUse []string) error {
+	var missing []string
+	for _, key := range inUse {
+		before, after := current.State(key), next.State(key)
+		if after == nil || (before != nil && before.Terminal != after.Terminal) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: %s", ErrWorkflowStateInUse, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+// WithOutbox writes a domain event to the transactional outbox for every todo
+// change, in the transaction of the change. Unlike change events, domain
+// events are not lost if the process stops before they are published.
+func WithOutbox(outbox repository.OutboxRepository) TodoServiceOption {
+	return func(s *todoService) {
+		s.outbox = outbox
+	}
+}
+
+// WithUserOutbox writes a domain event to the transactional outbox for every
+// user change, running the change and the event in a single transaction
+func WithUserOutbox(tx repository.Transactor, outbox repository.OutboxRepository) AuthServiceOption {
+	return func(s *authService) {
+		if tx != nil {
+			s.tx = tx
+		}
+		s.outbox = outbox
+	}
+}
+
+// todoEventType returns the domain event type of a todo history action
+func todoEventType(action string) string {
+	return model.OutboxAggregateTodo + "." + action
+}
+
+// appendTodoEvent writes the domain event of a todo history action to the
+// outbox, if enabled. A nil todo writes the event without a snapshot.
+func (s *todoService) appendTodoEvent(ctx context.Context, action string, todoID, userID, actorID uint, todo *model.Todo, changes model.FieldChanges) error {
+	if s.outbox == nil {
+		return nil
+	}
+
+	payload := model.TodoEventPayload{
+		TodoID:  todoID,
+		UserID:  userID,
+		ActorID: actorID,
+		Changes: changes,
+	}
+	if todo != nil {
+		snapshot := *todo
+		payload.Todo = &snapshot
+	}
+	return appendOutbox(ctx, s.outbox, model.OutboxAggregateTodo, todoID, todoEventType(action), payload)
+}
+
+// appendUserEvent writes a user domain event to the outbox, if enabled
+func appendUserEvent(ctx context.Context, outbox repository.OutboxRepository, eventType string, user *model.User) error {
+	if outbox == nil {
+		return nil
+	}
+
+	return appendOutbox(ctx, outbox, model.OutboxAggregateUser, user.ID, eventType, model.UserEventPayload{
+		UserID: user.ID,
+		User:   user.ToUserInfo(),
+	})
+}
+
+// appendOutbox encodes a payload and writes it to the outbox as a message that is due immediately
+func appendOutbox(ctx context.Context, outbox repository.OutboxRepository, aggregateType string, aggregateID uint, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode domain event: %w", err)
+	}
+
+	message := &model.OutboxMessage{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+		NextAttemptAt: time.Now(),
+	}
+	if err := outbox.Append(ctx, message); err != nil {
+		return fmt.Errorf("failed to write domain event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/jsonpatch"
+	"todo-api-backend/pkg/validator"
+)
+
+var (
+	ErrUnsupportedPatchType = errors.New("unsupported patch type")
+	ErrInvalidPatch         = errors.New("invalid patch document")
+	ErrPatchConflict        = errors.New("patch cannot be applied")
+	ErrInvalidPatchedTodo   = errors.New("patched todo is invalid")
+)
+
+// Replace replaces the editable fields of a todo, ensuring user ownership.
+// Fields missing from the request are reset to their zero values, except the
+// workflow state and the custom field values, which are kept.
+func (s *todoService) Replace(ctx context.Context, id uint, req *model.ReplaceTodoRequest, userID uint) (*model.Todo, error) {
+	existingTodo, err := s.getOwnedTodo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.replace(ctx, existingTodo, req, userID, false)
+}
+
+// Patch applies a patch document to the editable fields of a todo, ensuring
+// user ownership. patchType selects RFC 7396 merge patch or RFC 6902 JSON
+// Patch; the patched representation must pass the same validation as PUT.
+func (s *todoService) Patch(ctx context.Context, id uint, patchType string, patch []byte, userID uint) (*model.Todo, error) {
+	if patchType != model.PatchTypeMerge && patchType != model.PatchTypeJSON {
+		return nil, ErrUnsupportedPatchType
+	}
+
+	existingTodo, err := s.getOwnedTodo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := json.Marshal(&model.ReplaceTodoRequest{
+		Title:        existingTodo.Title,
+		Description:  existingTodo.Description,
+		Completed:    existingTodo.Completed,
+		DueAt:        existingTodo.DueAt,
+		State:        existingTodo.State,
+		CustomFields: existingTodo.CustomFields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode todo: %w", err)
+	}
+
+	var patched []byte
+	if patchType == model.PatchTypeMerge {
+		patched, err = jsonpatch.MergePatch(document, patch)
+	} else {
+		patched, err = jsonpatch.Apply(document, patch)
+	}
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrInvalidPatch) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrPatchConflict, err)
+	}
+
+	// The patched document must still be a todo: unknown or read-only
+	// members and wrongly typed values are rejected
+	var req model.ReplaceTodoRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatchedTodo, err)
+	}
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatchedTodo, err)
+	}
+
+	return s.replace(ctx, existingTodo, &req, userID, true)
+}
+
+// replace overwrites the editable fields of todo and saves it. For a patch,
+// req holds every custom field value of the patched todo and only the values
+// the patch changed are validated.
+func (s *todoService) replace(ctx context.Context, todo *model.Todo, req *model.ReplaceTodoRequest, userID uint, patch bool) (*model.Todo, error) {
+	before := *todo
+
+	todo.Title = req.Title
+	todo.Description = req.Description
+	todo.DueAt = req.DueAt
+
+	// A state change decides the completion; otherwise a completion change
+	// moves a todo in a list to a matching state
+	if req.State != "" && req.State != todo.State {
+		if err := s.transition(ctx, todo, req.State, userID); err != nil {
+			return nil, err
+		}
+	} else if err := s.complete(ctx, todo, req.Completed, userID); err != nil {
+		return nil, err
+	}
+	if patch {
+		if err := s.patchCustomFields(ctx, todo, req.CustomFields, userID); err != nil {
+			return nil, err
+		}
+	} else if req.CustomFields != nil {
+		if err := s.setCustomFields(ctx, todo, req.CustomFields, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.saveUpdate(ctx, &before, todo, userID); err != nil {
+		return nil, err
+	}
+	if err := s.markBlocked(ctx, userID, todo); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// getOwnedTodo loads a todo for modification, ensuring user ownership
+func (s *todoService) getOwnedTodo(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	todo, err := s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTodoNotFound
+		}
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+
+	if !canAccessTodo(todo, userID) {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	return todo, nil
+}
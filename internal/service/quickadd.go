@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/plaintext"
+	"todo-api-backend/pkg/quickadd"
+	"todo-api-backend/pkg/validator"
+)
+
+var (
+	ErrUnsupportedLocale = errors.New("unsupported locale")
+	ErrInvalidTimezone   = errors.New("invalid timezone")
+	ErrInvalidQuickAdd   = errors.New("text does not describe a valid todo")
+)
+
+// QuickAdd creates a todo from a single line of text such as "Call Bob tomorrow 3pm
+// !high #sales". Todos have no priority or tag fields, so the title keeps them as a
+// pri: tag and #hashtags, the way the todo.txt export writes priorities.
+func (s *todoService) QuickAdd(ctx context.Context, userID uint, req *model.QuickAddRequest) (*model.QuickAddResponse, error) {
+	locale := quickadd.English
+	if req.Locale != "" {
+		var ok bool
+		if locale, ok = quickadd.LookupLocale(req.Locale); !ok {
+			return nil, ErrUnsupportedLocale
+		}
+	}
+
+	location := time.UTC
+	if req.Timezone != "" {
+		// "Local" would depend on the server's time zone
+		loaded, err := time.LoadLocation(req.Timezone)
+		if err != nil || req.Timezone == "Local" {
+			return nil, ErrInvalidTimezone
+		}
+		location = loaded
+	}
+
+	result := quickadd.Parse(req.Text, quickadd.Options{
+		Now:      time.Now(),
+		Location: location,
+		Locale:   locale,
+	})
+	interpretation := newQuickAddInterpretation(result, locale, location)
+
+	// A text that was interpreted as a whole has no title, whatever its tags
+	title := result.Title
+	if title != "" {
+		for _, tag := range result.Tags {
+			title += " #" + tag
+		}
+		title = plaintext.JoinPriority(title, result.Priority)
+	}
+	create := &model.CreateTodoRequest{
+		Title: title,
+		DueAt: result.DueAt,
+	}
+	if err := validator.ValidateStruct(create); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuickAdd, err)
+	}
+
+	todo, err := s.Create(ctx, create, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.QuickAddResponse{
+		Todo:           todo,
+		Interpretation: interpretation,
+	}, nil
+}
+
+// newQuickAddInterpretation converts a parse result to its API representation
+func newQuickAddInterpretation(result *quickadd.Result, locale *quickadd.Locale, location *time.Location) *model.QuickAddInterpretation {
+	interpretation := &model.QuickAddInterpretation{
+		Title:    result.Title,
+		DueAt:    result.DueAt,
+		AllDay:   result.AllDay,
+		Tags:     []string{},
+		Matches:  []model.QuickAddMatch{},
+		Locale:   locale.Tag,
+		Timezone: location.String(),
+	}
+	if result.Priority != 0 {
+		interpretation.Priority = string(result.Priority)
+	}
+	interpretation.Tags = append(interpretation.Tags, result.Tags...)
+	for _, match := range result.Matches {
+		interpretation.Matches = append(interpretation.Matches, model.QuickAddMatch{Kind: match.Kind, Text: match.Text})
+	}
+	return interpretation
+}
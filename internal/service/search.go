@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"todo-api-backend/internal/model"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchTerms     = 16
+)
+
+var (
+	ErrEmptySearchQuery  = errors.New("search query is empty")
+	ErrSearchUnavailable = errors.New("search is not available")
+)
+
+// Search performs a full-text search over the todos of the authenticated user.
+// Quoted text is matched as a phrase and a trailing * matches word prefixes.
+func (s *todoService) Search(ctx context.Context, userID uint, req *model.SearchTodosRequest) ([]*model.TodoSearchResult, error) {
+	if s.searcher == nil {
+		return nil, ErrSearchUnavailable
+	}
+
+	terms := parseSearchQuery(req.Query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearchQuery
+	}
+
+	query := &model.TodoSearchQuery{
+		Terms:    terms,
+		Archived: req.Archived,
+		Limit:    req.Limit,
+	}
+	if query.Archived == "" {
+		query.Archived = model.ArchivedExclude
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+
+	results, err := s.searcher.Search(ctx, userID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search todos: %w", err)
+	}
+	todos := make([]*model.Todo, 0, len(results))
+	for _, result := range results {
+		todos = append(todos, result.Todo)
+	}
+	if err := s.markBlocked(ctx, userID, todos...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// parseSearchQuery splits a search query into terms. Text in double quotes forms
+// a phrase, and a term ending in * (inside or right after the quotes) is a prefix
+// match. Words are lowercased and reduced to letters and digits.
+func parseSearchQuery(q string) []model.SearchTerm {
+	var terms []model.SearchTerm
+	rest := q
+	for len(terms) < maxSearchTerms {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+
+		var raw string
+		if rest[0] == '"' {
+			// An unterminated quote runs to the end of the query
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				raw, rest = rest[1:], ""
+			} else {
+				raw, rest = rest[1:end+1], rest[end+2:]
+			}
+			if strings.HasPrefix(rest, "*") {
+				raw, rest = raw+"*", rest[1:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				raw, rest = rest, ""
+			} else {
+				raw, rest = rest[:end], rest[end:]
+			}
+		}
+
+		words := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+
+		terms = append(terms, model.SearchTerm{
+			Words:  words,
+			Prefix: strings.HasSuffix(strings.TrimRightFunc(raw, unicode.IsSpace), "*"),
+		})
+	}
+	return terms
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/validator"
+)
+
+const (
+	// syncTokenPrefix versions the format of sync tokens
+	syncTokenPrefix = "v1:"
+
+	// syncOverlap widens every incremental sync to cover writes that were
+	// still in flight, or stamped by a server with a slightly late clock,
+	// when the previous token was issued. Clients may therefore receive a
+	// change twice and should apply todos by version.
+	syncOverlap = 5 * time.Second
+)
+
+var ErrInvalidSyncToken = errors.New("invalid sync token")
+
+// WithSyncHorizon sets how far back sync tokens can be served. Trashed todos
+// are purged without a trace once they leave the trash, so tokens older than
+// the trash retention make the client start over with a full sync.
+func WithSyncHorizon(horizon time.Duration) TodoServiceOption {
+	return func(s *todoService) {
+		s.syncHorizon = horizon
+	}
+}
+
+// PullChanges returns the todos of a user that changed since the sync token,
+// together with tombstones for the todos deleted since then. An empty,
+// expired or future token returns every todo with Reset set. Tombstones of
+// todos purged from the trash come from the todo history, so they are only
+// reported when history is enabled.
+func (s *todoService) PullChanges(ctx context.Context, userID uint, since string) (*model.SyncResponse, error) {
+	// Take the new token before reading so that nothing written meanwhile is skipped
+	now := time.Now()
+
+	sinceTime, err := decodeSyncToken(since)
+	if err != nil {
+		return nil, err
+	}
+
+	reset := sinceTime.IsZero() || sinceTime.After(now) ||
+		(s.syncHorizon > 0 && sinceTime.Before(now.Add(-s.syncHorizon)))
+
+	var from time.Time
+	if !reset {
+		from = sinceTime.Add(-syncOverlap)
+	}
+
+	todos, err := s.todoRepo.ListChangedSince(ctx, userID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed todos: %w", err)
+	}
+
+	response := &model.SyncResponse{
+		Token:   encodeSyncToken(now),
+		Reset:   reset,
+		Todos:   []*model.Todo{},
+		Deleted: []*model.SyncTombstone{},
+	}
+
+	deleted := make(map[uint]bool)
+	for _, todo := range todos {
+		if !todo.DeletedAt.Valid {
+			response.Todos = append(response.Todos, todo)
+			continue
+		}
+		if !reset {
+			deleted[todo.ID] = true
+			response.Deleted = append(response.Deleted, &model.SyncTombstone{ID: todo.ID, DeletedAt: todo.DeletedAt.Time})
+		}
+	}
+
+	if !reset && s.historyRepo != nil {
+		purged, err := s.historyRepo.GetByActionSince(ctx, userID, model.HistoryActionPurged, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get purged todos: %w", err)
+		}
+		for _, entry := range purged {
+			if deleted[entry.TodoID] {
+				continue
+			}
+			deleted[entry.TodoID] = true
+			response.Deleted = append(response.Deleted, &model.SyncTombstone{ID: entry.TodoID, DeletedAt: entry.CreatedAt})
+		}
+	}
+
+	return response, nil
+}
+
+// PushChanges applies a batch of offline client mutations in order. Each
+// mutation succeeds or fails on its own; updates and deletes of todos that
+// changed on the server in the meantime are not applied but reported as
+// conflicts together with the server's copy of the todo.
+func (s *todoService) PushChanges(ctx context.Context, userID uint, req *model.SyncPushRequest) (*model.SyncPushResponse, error) {
+	response := &model.SyncPushResponse{
+		Results: make([]*model.SyncMutationResult, len(req.Mutations)),
+	}
+
+	for i := range req.Mutations {
+		result := s.applySyncMutation(ctx, userID, i, &req.Mutations[i])
+		switch result.Status {
+		case model.SyncStatusApplied:
+			response.Applied++
+		case model.SyncStatusConflict:
+			response.Conflicts++
+		default:
+			response.Failed++
+		}
+		response.Results[i] = result
+	}
+
+	return response, nil
+}
+
+// applySyncMutation applies a single client mutation and describes its outcome
+func (s *todoService) applySyncMutation(ctx context.Context, userID uint, index int, mutation *model.SyncMutation) *model.SyncMutationResult {
+	result := &model.SyncMutationResult{
+		Index:    index,
+		Op:       mutation.Op,
+		ClientID: mutation.ClientID,
+		ID:       mutation.ID,
+	}
+
+	if mutation.Op == model.SyncOpCreate {
+		return s.applySyncCreate(ctx, userID, mutation, result)
+	}
+	if mutation.Op != model.SyncOpUpdate && mutation.Op != model.SyncOpDelete {
+		return syncFailure(result, "validation_failed", "op must be one of: create, update, delete")
+	}
+	if mutation.ID == 0 {
+		return syncFailure(result, "validation_failed", "id is required")
+	}
+
+	req := &model.UpdateTodoRequest{
+		Title:       mutation.Title,
+		Description: mutation.Description,
+		Completed:   mutation.Completed,
+	}
+	if mutation.Op == model.SyncOpUpdate {
+		if err := validator.ValidateStruct(req); err != nil {
+			return syncFailure(result, "validation_failed", err.Error())
+		}
+	}
+
+	current, err := s.todoRepo.GetByID(ctx, mutation.ID, userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return syncFailure(result, "operation_failed", fmt.Sprintf("Failed to %s todo", mutation.Op))
+		}
+		if mutation.Op == model.SyncOpDelete {
+			// The todo is already gone, which is what the client asked for
+			result.Status = model.SyncStatusApplied
+			return result
+		}
+		return syncConflict(result, model.SyncConflictDeleted, nil)
+	}
+
+	if mutation.BaseVersion != 0 {
+		if current.Version != mutation.BaseVersion {
+			return syncConflict(result, model.SyncConflictVersion, current)
+		}
+	} else if current.UpdatedAt.After(mutation.ClientUpdatedAt) {
+		return syncConflict(result, model.SyncConflictServerNewer, current)
+	}
+
+	// Only write the version the conflict check saw
+	ctx = WithIfMatch(ctx, current.Version)
+
+	var todo *model.Todo
+	if mutation.Op == model.SyncOpUpdate {
+		todo, err = s.Update(ctx, mutation.ID, req, userID)
+	} else {
+		err = s.Delete(ctx, mutation.ID, userID)
+	}
+
+	if err != nil {
+		if _, code, message, ok := workflowFailure(err); ok {
+			return syncFailure(result, code, message)
+		}
+		switch {
+		case errors.Is(err, ErrTodoNotFound):
+			if mutation.Op == model.SyncOpDelete {
+				result.Status = model.SyncStatusApplied
+				return result
+			}
+			return syncConflict(result, model.SyncConflictDeleted, nil)
+		case errors.Is(err, ErrPreconditionFailed):
+			latest, lerr := s.todoRepo.GetByID(ctx, mutation.ID, userID)
+			if lerr != nil {
+				return syncConflict(result, model.SyncConflictDeleted, nil)
+			}
+			return syncConflict(result, model.SyncConflictVersion, latest)
+		case errors.Is(err, ErrInvalidCustomFieldValues):
+			return syncFailure(result, "invalid_custom_fields", err.Error())
+		default:
+			return syncFailure(result, "operation_failed", fmt.Sprintf("Failed to %s todo", mutation.Op))
+		}
+	}
+
+	result.Status = model.SyncStatusApplied
+	result.Todo = todo
+	return result
+}
+
+// applySyncCreate creates a todo the client made offline, keeping its completion state
+func (s *todoService) applySyncCreate(ctx context.Context, userID uint, mutation *model.SyncMutation, result *model.SyncMutationResult) *model.SyncMutationResult {
+	req := &model.CreateTodoRequest{
+		Title:       valueOf(mutation.Title),
+		Description: valueOf(mutation.Description),
+	}
+	if err := validator.ValidateStruct(req); err != nil {
+		return syncFailure(result, "validation_failed", err.Error())
+	}
+
+	var todo *model.Todo
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err := s.Create(ctx, req, userID)
+		if err != nil {
+			return err
+		}
+		todo = created
+
+		if mutation.Completed != nil && *mutation.Completed {
+			todo, err = s.Update(ctx, created.ID, &model.UpdateTodoRequest{Completed: mutation.Completed}, userID)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return syncFailure(result, "user_not_found", "User not found")
+		}
+		if errors.Is(err, ErrInvalidCustomFieldValues) {
+			return syncFailure(result, "invalid_custom_fields", err.Error())
+		}
+		if _, code, message, ok := workflowFailure(err); ok {
+			return syncFailure(result, code, message)
+		}
+		return syncFailure(result, "operation_failed", "Failed to create todo")
+	}
+
+	result.Status = model.SyncStatusApplied
+	result.ID = todo.ID
+	result.Todo = todo
+	return result
+}
+
+// syncConflict marks a sync mutation result as conflicting with the server's todo
+func syncConflict(result *model.SyncMutationResult, reason string, server *model.Todo) *model.SyncMutationResult {
+	result.Status = model.SyncStatusConflict
+	result.Reason = reason
+	result.Todo = server
+	return result
+}
+
+// syncFailure marks a sync mutation result as failed
+func syncFailure(result *model.SyncMutationResult, code, message string) *model.SyncMutationResult {
+	result.Status = model.SyncStatusFailed
+	result.Error = code
+	result.Message = message
+	return result
+}
+
+// encodeSyncToken turns a point in time into an opaque sync token
+func encodeSyncToken(t time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncTokenPrefix + strconv.FormatInt(t.UnixNano(), 10)))
+}
+
+// decodeSyncToken parses a sync token; the empty token decodes to the zero time
+func decodeSyncToken(token string) (time.Time, error) {
+	if token == "" {
+		return time.Time{}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(data), syncTokenPrefix) {
+		return time.Time{}, ErrInvalidSyncToken
+	}
+
+	nanos, err := strconv.ParseInt(strings.TrimPrefix(string(data), syncTokenPrefix), 10, 64)
+	if err != nil || nanos <= 0 {
+		return time.Time{}, ErrInvalidSyncToken
+	}
+	return time.Unix(0, nanos), nil
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/pkg/validator"
+)
+
+const (
+	// maxTemplatesPerUser limits how many templates a user may store
+	maxTemplatesPerUser = 100
+
+	// maxTemplateItems and maxTemplateDepth bound the tree of a template, which
+	// is instantiated in a single transaction
+	maxTemplateItems = 200
+	maxTemplateDepth = 5
+)
+
+var (
+	ErrTemplateNotFound         = errors.New("template not found")
+	ErrTemplateLimit            = errors.New("template limit reached")
+	ErrTemplateTooLarge         = errors.New("template too large")
+	ErrInvalidStartDate         = errors.New("invalid start date")
+	ErrMissingTemplateVariables = errors.New("missing template variables")
+	ErrInvalidTemplateTodo      = errors.New("template item does not describe a valid todo")
+)
+
+// templatePlaceholder matches placeholders such as {{name}} or {{ start_date }}
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// plannedTodo is a template item ready to be created, with the index of the
+// todo planned for its parent item or -1 for top-level items
+type plannedTodo struct {
+	req    *model.CreateTodoRequest
+	parent int
+	depth  int
+}
+
+// templateService implements the TemplateService interface
+type templateService struct {
+	repo  repository.TemplateRepository
+	todos TodoService
+	tx    repository.Transactor
+	now   func() time.Time
+}
+
+// NewTemplateService creates a new template service. Todos are created through
+// todos, so they are recorded like todos created through the REST API.
+func NewTemplateService(repo repository.TemplateRepository, todos TodoService, tx repository.Transactor) TemplateService {
+	return &templateService{
+		repo:  repo,
+		todos: todos,
+		tx:    tx,
+		now:   time.Now,
+	}
+}
+
+// Create stores a new template
+func (s *templateService) Create(ctx context.Context, userID uint, req *model.CreateTemplateRequest) (*model.TodoTemplate, error) {
+	if err := checkTemplateSize(req.Items); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count templates: %w", err)
+	}
+	if count >= maxTemplatesPerUser {
+		return nil, ErrTemplateLimit
+	}
+
+	template := &model.TodoTemplate{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Items:       req.Items,
+	}
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+	template.Variables = templateVariables(template.Items)
+	return template, nil
+}
+
+// List retrieves all templates of a user
+func (s *templateService) List(ctx context.Context, userID uint) ([]*model.TodoTemplate, error) {
+	templates, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	for _, template := range templates {
+		template.Variables = templateVariables(template.Items)
+	}
+	return templates, nil
+}
+
+// Get retrieves a template, ensuring it belongs to the user
+func (s *templateService) Get(ctx context.Context, id uint, userID uint) (*model.TodoTemplate, error) {
+	template, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	template.Variables = templateVariables(template.Items)
+	return template, nil
+}
+
+// Update replaces the name, description and items of a template
+func (s *templateService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateTemplateRequest) (*model.TodoTemplate, error) {
+	if err := checkTemplateSize(req.Items); err != nil {
+		return nil, err
+	}
+
+	template, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Items = req.Items
+
+	if err := s.repo.Update(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+	template.Variables = templateVariables(template.Items)
+	return template, nil
+}
+
+// Delete removes a template
+func (s *templateService) Delete(ctx context.Context, id uint, userID uint) error {
+	if err := s.repo.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTemplateNotFound
+		}
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
+
+// Instantiate creates a todo for every item of a template, parents before their
+// children, and makes every parent blocked by the todos of its child items.
+// Placeholders are replaced by the request's variables and due dates are
+// all-day dates, stored at midnight UTC, offset from the start date. Every
+// todo is checked before the first is created, and either all of them are
+// created or none.
+func (s *templateService) Instantiate(ctx context.Context, id uint, userID uint, req *model.InstantiateTemplateRequest) (*model.InstantiateTemplateResponse, error) {
+	template, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if req.StartDate != "" {
+		if start, err = time.Parse("2006-01-02", req.StartDate); err != nil {
+			return nil, ErrInvalidStartDate
+		}
+	}
+
+	var missing []string
+	for _, name := range template.Variables {
+		if _, ok := req.Variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingTemplateVariables, strings.Join(missing, ", "))
+	}
+
+	planned := planTemplateTodos(template.Items, req.Variables, start, -1, 0, nil)
+	for i, p := range planned {
+		if err := validator.ValidateStruct(p.req); err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidTemplateTodo, i+1, err)
+		}
+	}
+
+	response := &model.InstantiateTemplateResponse{
+		TemplateID: template.ID,
+		Todos:      make([]*model.InstantiatedTodo, 0, len(planned)),
+	}
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, p := range planned {
+			todo, err := s.todos.Create(ctx, p.req, userID)
+			if err != nil {
+				return err
+			}
+
+			created := &model.InstantiatedTodo{Todo: todo, Depth: p.depth}
+			if p.parent >= 0 {
+				// The tree is kept as dependencies: a parent waits for its subtasks
+				parent := response.Todos[p.parent].Todo
+				if _, err := s.todos.AddDependency(ctx, parent.ID, &model.AddDependencyRequest{BlockedBy: todo.ID}, userID); err != nil {
+					return err
+				}
+				parent.Blocked = true
+				created.ParentID = &parent.ID
+			}
+			response.Todos = append(response.Todos, created)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response.Count = len(response.Todos)
+	return response, nil
+}
+
+// planTemplateTodos flattens template items depth-first into the todos to create
+func planTemplateTodos(items []model.TemplateItem, variables map[string]string, start time.Time, parent, depth int, planned []plannedTodo) []plannedTodo {
+	for i := range items {
+		item := &items[i]
+		req := &model.CreateTodoRequest{
+			Title:       strings.TrimSpace(fillTemplatePlaceholders(item.Title, variables)),
+			Description: fillTemplatePlaceholders(item.Description, variables),
+		}
+		if item.DueOffsetDays != nil {
+			due := start.AddDate(0, 0, *item.DueOffsetDays)
+			req.DueAt = &due
+		}
+
+		planned = append(planned, plannedTodo{req: req, parent: parent, depth: depth})
+		planned = planTemplateTodos(item.Children, variables, start, len(planned)-1, depth+1, planned)
+	}
+	return planned
+}
+
+// fillTemplatePlaceholders replaces the placeholders of text by their values
+func fillTemplatePlaceholders(text string, variables map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// templateVariables returns the sorted names of the placeholders used by items
+func templateVariables(items []model.TemplateItem) []string {
+	seen := make(map[string]bool)
+	var collect func(items []model.TemplateItem)
+	collect = func(items []model.TemplateItem) {
+		for _, item := range items {
+			for _, text := range []string{item.Title, item.Description} {
+				for _, match := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+					seen[match[1]] = true
+				}
+			}
+			collect(item.Children)
+		}
+	}
+	collect(items)
+
+	variables := make([]string, 0, len(seen))
+	for name := range seen {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+	return variables
+}
+
+// checkTemplateSize rejects trees with too many items or levels
+func checkTemplateSize(items []model.TemplateItem) error {
+	count := 0
+	var walk func(items []model.TemplateItem, depth int) error
+	walk = func(items []model.TemplateItem, depth int) error {
+		if len(items) > 0 && depth > maxTemplateDepth {
+			return fmt.Errorf("%w: items can be nested at most %d levels deep", ErrTemplateTooLarge, maxTemplateDepth)
+		}
+		for _, item := range items {
+			if count++; count > maxTemplateItems {
+				return fmt.Errorf("%w: a template can have at most %d items", ErrTemplateTooLarge, maxTemplateItems)
+			}
+			if err := walk(item.Children, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(items, 1)
+}
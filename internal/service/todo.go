@@ -4,29 +4,77 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"gorm.io/gorm"
+	"todo-api-backend/internal/events"
 	"todo-api-backend/internal/model"
 	"todo-api-backend/internal/repository"
-	"gorm.io/gorm"
 )
 
 var (
-	ErrTodoNotFound      = errors.New("todo not found")
+	ErrTodoNotFound       = errors.New("todo not found")
 	ErrUnauthorizedAccess = errors.New("unauthorized access to todo")
 )
 
 // todoService implements the TodoService interface
 type todoService struct {
-	todoRepo repository.TodoRepository
-	userRepo repository.UserRepository
+	todoRepo     repository.TodoRepository
+	userRepo     repository.UserRepository
+	historyRepo  repository.TodoHistoryRepository
+	searcher     repository.TodoSearcher
+	tx           repository.Transactor
+	syncHorizon  time.Duration
+	publisher    events.Publisher
+	webhooks     WebhookQueue
+	outbox       repository.OutboxRepository
+	dependencies repository.DependencyRepository
+	lists        repository.ListRepository
+	customFields repository.CustomFieldRepository
+	comments     repository.CommentRepository
+	mentions     MentionNotifier
+}
+
+// TodoServiceOption configures optional dependencies of the todo service
+type TodoServiceOption func(*todoService)
+
+// WithTransactor runs every todo write together with its side effects in a single transaction
+func WithTransactor(tx repository.Transactor) TodoServiceOption {
+	return func(s *todoService) {
+		if tx != nil {
+			s.tx = tx
+		}
+	}
+}
+
+// WithHistory records a field-level history entry for every todo change
+func WithHistory(historyRepo repository.TodoHistoryRepository) TodoServiceOption {
+	return func(s *todoService) {
+		s.historyRepo = historyRepo
+	}
+}
+
+// WithSearch enables full-text search over todos
+func WithSearch(searcher repository.TodoSearcher) TodoServiceOption {
+	return func(s *todoService) {
+		s.searcher = searcher
+	}
 }
 
 // NewTodoService creates a new todo service
-func NewTodoService(todoRepo repository.TodoRepository, userRepo repository.UserRepository) TodoService {
-	return &todoService{
+func NewTodoService(todoRepo repository.TodoRepository, userRepo repository.UserRepository, opts ...TodoServiceOption) TodoService {
+	s := &todoService{
 		todoRepo: todoRepo,
 		userRepo: userRepo,
+		tx:       noopTransactor{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.publisher != nil {
+		s.tx = eventTransactor{tx: s.tx, publisher: s.publisher}
 	}
+	return s
 }
 
 // Create creates a new todo for the authenticated user
@@ -44,17 +92,37 @@ func (s *todoService) Create(ctx context.Context, req *model.CreateTodoRequest,
 	todo := &model.Todo{
 		Title:       req.Title,
 		Description: req.Description,
+		DueAt:       req.DueAt,
 		UserID:      userID,
 		Completed:   false, // Default to false for new todos
 	}
+	if err := s.prepareCreate(ctx, todo, req, userID); err != nil {
+		return nil, err
+	}
 
-	if err := s.todoRepo.Create(ctx, todo); err != nil {
-		return nil, fmt.Errorf("failed to create todo: %w", err)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.todoRepo.Create(ctx, todo); err != nil {
+			return fmt.Errorf("failed to create todo: %w", err)
+		}
+		return s.recordChange(ctx, model.HistoryActionCreated, nil, todo, userID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return todo, nil
 }
 
+// prepareCreate applies the rules every new todo follows, however it is
+// created: it enters the list of the request and checks the custom field
+// values, including that required fields have one
+func (s *todoService) prepareCreate(ctx context.Context, todo *model.Todo, req *model.CreateTodoRequest, userID uint) error {
+	if err := s.createInList(ctx, todo, req, userID); err != nil {
+		return err
+	}
+	return s.setCustomFields(ctx, todo, req.CustomFields, userID)
+}
+
 // GetByID retrieves a specific todo by ID, ensuring user ownership
 func (s *todoService) GetByID(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
 	todo, err := s.todoRepo.GetByID(ctx, id, userID)
@@ -70,6 +138,10 @@ func (s *todoService) GetByID(ctx context.Context, id uint, userID uint) (*model
 		return nil, ErrUnauthorizedAccess
 	}
 
+	if err := s.markBlocked(ctx, userID, todo); err != nil {
+		return nil, err
+	}
+
 	return todo, nil
 }
 
@@ -88,6 +160,31 @@ func (s *todoService) GetByUserID(ctx context.Context, userID uint) ([]*model.To
 	if err != nil {
 		return nil, fmt.Errorf("failed to get todos: %w", err)
 	}
+	if err := s.markBlocked(ctx, userID, todos...); err != nil {
+		return nil, err
+	}
+
+	// Return empty slice if no todos found (not an error)
+	if todos == nil {
+		todos = []*model.Todo{}
+	}
+
+	return todos, nil
+}
+
+// List retrieves the todos of the authenticated user matching the filter
+func (s *todoService) List(ctx context.Context, userID uint, filter *model.TodoFilter) ([]*model.Todo, error) {
+	if err := s.resolveCustomFieldFilter(ctx, userID, filter); err != nil {
+		return nil, err
+	}
+
+	todos, err := s.todoRepo.List(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todos: %w", err)
+	}
+	if err := s.markBlocked(ctx, userID, todos...); err != nil {
+		return nil, err
+	}
 
 	// Return empty slice if no todos found (not an error)
 	if todos == nil {
@@ -113,6 +210,8 @@ func (s *todoService) Update(ctx context.Context, id uint, req *model.UpdateTodo
 		return nil, ErrUnauthorizedAccess
 	}
 
+	before := *existingTodo
+
 	// Update fields if provided
 	if req.Title != nil {
 		existingTodo.Title = *req.Title
@@ -121,21 +220,53 @@ func (s *todoService) Update(ctx context.Context, id uint, req *model.UpdateTodo
 		existingTodo.Description = *req.Description
 	}
 	if req.Completed != nil {
-		existingTodo.Completed = *req.Completed
+		if err := s.complete(ctx, existingTodo, *req.Completed, userID); err != nil {
+			return nil, err
+		}
+	}
+	if req.DueAt != nil {
+		existingTodo.DueAt = req.DueAt
 	}
 
 	// Save updated todo
-	if err := s.todoRepo.Update(ctx, existingTodo); err != nil {
-		return nil, fmt.Errorf("failed to update todo: %w", err)
+	if err := s.saveUpdate(ctx, &before, existingTodo, userID); err != nil {
+		return nil, err
+	}
+	if err := s.markBlocked(ctx, userID, existingTodo); err != nil {
+		return nil, err
 	}
 
 	return existingTodo, nil
 }
 
-// Delete deletes a todo by ID, ensuring user ownership
+// saveUpdate persists an updated todo and records the change from before.
+// The write is rejected if it does not meet the If-Match precondition in ctx.
+func (s *todoService) saveUpdate(ctx context.Context, before, todo *model.Todo, userID uint) error {
+	return s.saveChange(ctx, model.HistoryActionUpdated, before, todo, userID)
+}
+
+// saveChange persists a changed todo like saveUpdate, recording the change
+// from before as action
+func (s *todoService) saveChange(ctx context.Context, action string, before, todo *model.Todo, userID uint) error {
+	if err := checkPrecondition(ctx, before); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.todoRepo.Update(ctx, todo); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return versionConflict(ctx)
+			}
+			return fmt.Errorf("failed to update todo: %w", err)
+		}
+		return s.recordChange(ctx, action, before, todo, userID)
+	})
+}
+
+// Delete moves a todo to the trash, ensuring user ownership
 func (s *todoService) Delete(ctx context.Context, id uint, userID uint) error {
 	// Verify todo exists and belongs to user
-	_, err := s.todoRepo.GetByID(ctx, id, userID)
+	todo, err := s.todoRepo.GetByID(ctx, id, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrTodoNotFound
@@ -143,10 +274,325 @@ func (s *todoService) Delete(ctx context.Context, id uint, userID uint) error {
 		return fmt.Errorf("failed to verify todo ownership: %w", err)
 	}
 
+	if err := checkPrecondition(ctx, todo); err != nil {
+		return err
+	}
+
 	// Delete the todo
-	if err := s.todoRepo.Delete(ctx, id, userID); err != nil {
-		return fmt.Errorf("failed to delete todo: %w", err)
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.todoRepo.Delete(ctx, id, userID); err != nil {
+			return fmt.Errorf("failed to delete todo: %w", err)
+		}
+
+		deleted := *todo
+		deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		return s.recordChange(ctx, model.HistoryActionDeleted, todo, &deleted, userID)
+	})
+}
+
+// GetTrash retrieves all trashed todos belonging to the authenticated user
+func (s *todoService) GetTrash(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	todos, err := s.todoRepo.GetTrashByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	// Return empty slice if trash is empty (not an error)
+	if todos == nil {
+		todos = []*model.Todo{}
+	}
+
+	return todos, nil
+}
+
+// Restore moves a trashed todo back to the active list, ensuring user ownership
+func (s *todoService) Restore(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	trashed, err := s.todoRepo.GetTrashedByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTodoNotFound
+		}
+		return nil, fmt.Errorf("failed to get trashed todo: %w", err)
+	}
+
+	var todo *model.Todo
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.todoRepo.Restore(ctx, id, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTodoNotFound
+			}
+			return fmt.Errorf("failed to restore todo: %w", err)
+		}
+
+		// Reload the restored todo so the response reflects its current state
+		restored, err := s.todoRepo.GetByID(ctx, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get restored todo: %w", err)
+		}
+		todo = restored
+
+		return s.recordChange(ctx, model.HistoryActionRestored, trashed, todo, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markBlocked(ctx, userID, todo); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// Purge permanently deletes a trashed todo, ensuring user ownership
+func (s *todoService) Purge(ctx context.Context, id uint, userID uint) error {
+	trashed, err := s.todoRepo.GetTrashedByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTodoNotFound
+		}
+		return fmt.Errorf("failed to get trashed todo: %w", err)
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.todoRepo.Purge(ctx, id, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTodoNotFound
+			}
+			return fmt.Errorf("failed to purge todo: %w", err)
+		}
+		return s.recordChange(ctx, model.HistoryActionPurged, trashed, nil, userID)
+	})
+}
+
+// EmptyTrash permanently deletes all trashed todos of the authenticated user
+func (s *todoService) EmptyTrash(ctx context.Context, userID uint) (int64, error) {
+	var purged int64
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		trashed, err := s.todoRepo.GetTrashByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get trash: %w", err)
+		}
+
+		purged, err = s.todoRepo.PurgeTrashByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to empty trash: %w", err)
+		}
+
+		for _, todo := range trashed {
+			if err := s.recordChange(ctx, model.HistoryActionPurged, todo, nil, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// Archive archives a todo, ensuring user ownership
+func (s *todoService) Archive(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	todo, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archiving an archived todo is a no-op
+	if todo.IsArchived() {
+		if err := checkPrecondition(ctx, todo); err != nil {
+			return nil, err
+		}
+		return todo, nil
+	}
+
+	before := *todo
+	now := time.Now()
+	todo.ArchivedAt = &now
+
+	if err := s.saveChange(ctx, model.HistoryActionArchived, &before, todo, userID); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// Unarchive moves an archived todo back to the active list, ensuring user ownership
+func (s *todoService) Unarchive(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	todo, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !todo.IsArchived() {
+		if err := checkPrecondition(ctx, todo); err != nil {
+			return nil, err
+		}
+		return todo, nil
+	}
+
+	before := *todo
+	todo.ArchivedAt = nil
+
+	if err := s.saveChange(ctx, model.HistoryActionUnarchived, &before, todo, userID); err != nil {
+		return nil, err
 	}
 
+	return todo, nil
+}
+
+// ArchiveCompleted archives all completed todos older than the given number of days
+func (s *todoService) ArchiveCompleted(ctx context.Context, userID uint, olderThanDays int) (int64, error) {
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -olderThanDays)
+
+	var archived []uint
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		ids, err := s.todoRepo.ArchiveCompletedBefore(ctx, userID, cutoff, now)
+		if err != nil {
+			return fmt.Errorf("failed to archive completed todos: %w", err)
+		}
+		archived = ids
+
+		for _, id := range ids {
+			change := model.FieldChanges{{Field: "archived_at", Old: nil, New: historyTime(&now)}}
+			if err := s.appendHistory(ctx, id, userID, userID, model.HistoryActionArchived, change); err != nil {
+				return err
+			}
+			s.publishChange(ctx, model.HistoryActionArchived, id, userID, nil)
+			if err := s.queueWebhooks(ctx, model.HistoryActionArchived, id, userID, nil, nil); err != nil {
+				return err
+			}
+			if err := s.appendTodoEvent(ctx, model.HistoryActionArchived, id, userID, userID, nil, change); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(archived)), nil
+}
+
+// GetArchivePolicy retrieves the auto-archive policy of the authenticated user
+func (s *todoService) GetArchivePolicy(ctx context.Context, userID uint) (*model.ArchivePolicyResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return archivePolicyResponse(user), nil
+}
+
+// UpdateArchivePolicy updates the auto-archive policy of the authenticated user
+func (s *todoService) UpdateArchivePolicy(ctx context.Context, userID uint, req *model.ArchivePolicyRequest) (*model.ArchivePolicyResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user.AutoArchiveDays = req.AutoArchiveDays
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update archive policy: %w", err)
+		}
+		return appendUserEvent(ctx, s.outbox, model.OutboxEventUserArchivePolicyChanged, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return archivePolicyResponse(user), nil
+}
+
+// setCompleted updates the completion state of a todo, tracking when it was completed
+func setCompleted(todo *model.Todo, completed bool) {
+	if completed && !todo.Completed {
+		now := time.Now()
+		todo.CompletedAt = &now
+	} else if !completed {
+		todo.CompletedAt = nil
+	}
+	todo.Completed = completed
+}
+
+// archivePolicyResponse builds the archive policy response for a user
+func archivePolicyResponse(user *model.User) *model.ArchivePolicyResponse {
+	return &model.ArchivePolicyResponse{
+		AutoArchiveDays: user.AutoArchiveDays,
+		Enabled:         user.AutoArchiveDays > 0,
+	}
+}
+
+// GetHistory retrieves the change history of a todo, ensuring user ownership
+func (s *todoService) GetHistory(ctx context.Context, id uint, userID uint) ([]*model.TodoHistory, error) {
+	if s.historyRepo == nil {
+		return []*model.TodoHistory{}, nil
+	}
+
+	entries, err := s.historyRepo.GetByTodoID(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo history: %w", err)
+	}
+
+	// Todos created before history was recorded have no entries; only report
+	// not found when the todo does not exist at all
+	if len(entries) == 0 {
+		if _, err := s.GetByID(ctx, id, userID); err != nil {
+			return nil, err
+		}
+		return []*model.TodoHistory{}, nil
+	}
+
+	return entries, nil
+}
+
+// recordChange appends a history entry describing the change from before to after,
+// publishes the matching change event and writes the matching domain event to the
+// outbox. A nil before denotes a newly created todo and a nil after a permanently
+// deleted one.
+func (s *todoService) recordChange(ctx context.Context, action string, before, after *model.Todo, actorID uint) error {
+	todo := after
+	if todo == nil {
+		todo = before
+	}
+
+	changes := diffTodos(before, after)
+	s.publishChange(ctx, action, todo.ID, todo.UserID, after)
+	if err := s.queueWebhooks(ctx, action, todo.ID, todo.UserID, before, after); err != nil {
+		return err
+	}
+	if err := s.appendTodoEvent(ctx, action, todo.ID, todo.UserID, actorID, after, changes); err != nil {
+		return err
+	}
+	return s.appendHistory(ctx, todo.ID, todo.UserID, actorID, action, changes)
+}
+
+// appendHistory stores a single history entry if history recording is enabled
+func (s *todoService) appendHistory(ctx context.Context, todoID, userID, actorID uint, action string, changes model.FieldChanges) error {
+	if s.historyRepo == nil {
+		return nil
+	}
+
+	entry := &model.TodoHistory{
+		TodoID:  todoID,
+		UserID:  userID,
+		ActorID: actorID,
+		Action:  action,
+		Changes: changes,
+	}
+	if err := s.historyRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record todo history: %w", err)
+	}
 	return nil
-}
\ No newline at end of file
+}
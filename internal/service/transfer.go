@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/validator"
+)
+
+// maxImportRows caps the number of todos a single import may contain
+const maxImportRows = 10000
+
+var ErrTooManyImportRows = errors.New("too many import rows")
+
+// Export calls fn for every todo of the user matching the filter, oldest first,
+// without loading all of them into memory
+func (s *todoService) Export(ctx context.Context, userID uint, filter *model.TodoFilter, fn func(*model.Todo) error) error {
+	if err := s.todoRepo.StreamByUserID(ctx, userID, filter, fn); err != nil {
+		return fmt.Errorf("failed to export todos: %w", err)
+	}
+	return nil
+}
+
+// Import validates every row of an import file with the rules of CreateTodoRequest and
+// of Create, such as required custom fields, and creates a todo for each valid row in
+// a single transaction. Invalid rows are reported and skipped, unless the import is
+// atomic, in which case nothing is created. A dry run reports the same outcome without
+// writing anything.
+func (s *todoService) Import(ctx context.Context, userID uint, req *model.ImportRequest) (*model.ImportResponse, error) {
+	if len(req.Rows) > maxImportRows {
+		return nil, ErrTooManyImportRows
+	}
+
+	// Verify user exists
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to verify user: %w", err)
+	}
+
+	response := &model.ImportResponse{
+		DryRun: req.DryRun,
+		Atomic: req.Atomic,
+		Total:  len(req.Rows),
+		Errors: []*model.ImportRowError{},
+	}
+
+	// valid holds the todos of the valid rows
+	type importedTodo struct {
+		row  int
+		todo *model.Todo
+	}
+	valid := make([]importedTodo, 0, len(req.Rows))
+	for i := range req.Rows {
+		row := &req.Rows[i]
+		if rowErr := validateImportRow(row); rowErr != nil {
+			response.Errors = append(response.Errors, rowErr)
+			continue
+		}
+		todo, rowErr, err := s.prepareImportRow(ctx, userID, row)
+		if err != nil {
+			return nil, err
+		}
+		if rowErr != nil {
+			response.Errors = append(response.Errors, rowErr)
+			continue
+		}
+		valid = append(valid, importedTodo{row: row.Row, todo: todo})
+	}
+	response.Failed = len(response.Errors)
+	response.Imported = len(valid)
+
+	if req.Atomic && response.Failed > 0 {
+		// One invalid row rejects the whole file
+		response.Imported = 0
+		return response, nil
+	}
+	if req.DryRun || len(valid) == 0 {
+		return response, nil
+	}
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, imported := range valid {
+			if err := s.todoRepo.Create(ctx, imported.todo); err != nil {
+				return fmt.Errorf("failed to import row %d: %w", imported.row, err)
+			}
+			if err := s.recordChange(ctx, model.HistoryActionCreated, nil, imported.todo, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response.Committed = true
+	return response, nil
+}
+
+// prepareImportRow builds the todo of a validated import row through the same
+// rules as Create. A row that breaks them is reported as a row error; other
+// errors abort the import.
+func (s *todoService) prepareImportRow(ctx context.Context, userID uint, row *model.ImportRow) (*model.Todo, *model.ImportRowError, error) {
+	todo := &model.Todo{
+		Title:       row.Title,
+		Description: row.Description,
+		DueAt:       row.DueAt,
+		UserID:      userID,
+	}
+	req := &model.CreateTodoRequest{
+		Title:       row.Title,
+		Description: row.Description,
+		DueAt:       row.DueAt,
+	}
+	if err := s.prepareCreate(ctx, todo, req, userID); err != nil {
+		if !errors.Is(err, ErrInvalidCustomFieldValues) {
+			return nil, nil, err
+		}
+		rowErr := &model.ImportRowError{
+			Row:     row.Row,
+			Error:   "invalid_custom_fields",
+			Message: "Custom field values are invalid",
+		}
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			rowErr.Details = validator.FormatValidationErrors(validationErrors)
+		}
+		return nil, rowErr, nil
+	}
+
+	todo.Completed = row.Completed
+	if todo.Completed {
+		now := time.Now()
+		todo.CompletedAt = &now
+		if row.CompletedAt != nil {
+			todo.CompletedAt = row.CompletedAt
+		}
+	}
+	if row.CreatedAt != nil {
+		todo.CreatedAt = *row.CreatedAt
+	}
+	return todo, nil, nil
+}
+
+// validateImportRow checks an import row against the rules of CreateTodoRequest
+func validateImportRow(row *model.ImportRow) *model.ImportRowError {
+	if row.ParseError != "" {
+		return &model.ImportRowError{
+			Row:     row.Row,
+			Error:   "invalid_row",
+			Message: row.ParseError,
+		}
+	}
+
+	req := &model.CreateTodoRequest{
+		Title:       row.Title,
+		Description: row.Description,
+	}
+	if err := validator.ValidateStruct(req); err != nil {
+		return &model.ImportRowError{
+			Row:     row.Row,
+			Error:   "validation_failed",
+			Message: err.Error(),
+			Details: validator.FormatValidationErrors(err),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/pkg/webhook"
+)
+
+const (
+	// maxWebhooksPerUser limits how many webhooks a user may register
+	maxWebhooksPerUser = 10
+
+	// defaultDeliveryLogLimit and maxDeliveryLogLimit bound the delivery log returned for a webhook
+	defaultDeliveryLogLimit = 50
+	maxDeliveryLogLimit     = 200
+
+	// webhookSecretPrefix marks webhook signing secrets so they are recognisable when leaked
+	webhookSecretPrefix = "whsec_"
+)
+
+var (
+	ErrWebhookNotFound   = errors.New("webhook not found")
+	ErrWebhookLimit      = errors.New("webhook limit reached")
+	ErrInvalidWebhookURL = errors.New("invalid webhook url")
+)
+
+// WebhookQueue queues webhook deliveries for todo changes
+type WebhookQueue interface {
+	// Enqueue queues a delivery of an event to every active webhook of the
+	// user subscribed to its type. Called inside the transaction of the
+	// change, the deliveries are only queued if the change is committed.
+	Enqueue(ctx context.Context, userID uint, eventType string, todoID uint, todo *model.Todo) error
+}
+
+// webhookService implements the WebhookService interface
+type webhookService struct {
+	repo       repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+	resolver   webhook.Resolver
+	now        func() time.Time
+}
+
+// WebhookServiceOption configures optional behaviour of the webhook service
+type WebhookServiceOption func(*webhookService)
+
+// WithWebhookResolver sets the resolver used to check that webhook hosts do
+// not point at internal addresses
+func WithWebhookResolver(resolver webhook.Resolver) WebhookServiceOption {
+	return func(s *webhookService) {
+		s.resolver = resolver
+	}
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(repo repository.WebhookRepository, deliveries repository.WebhookDeliveryRepository, opts ...WebhookServiceOption) WebhookService {
+	s := &webhookService{
+		repo:       repo,
+		deliveries: deliveries,
+		resolver:   net.DefaultResolver,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Create registers a webhook with a newly generated signing secret
+func (s *webhookService) Create(ctx context.Context, userID uint, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+	if count >= maxWebhooksPerUser {
+		return nil, ErrWebhookLimit
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	webhook := &model.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Events: uniqueStrings(req.Events),
+		Secret: secret,
+		Active: true,
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// List retrieves all webhooks of a user
+func (s *webhookService) List(ctx context.Context, userID uint) ([]*model.Webhook, error) {
+	webhooks, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Get retrieves a webhook, ensuring it belongs to the user
+func (s *webhookService) Get(ctx context.Context, id uint, userID uint) (*model.Webhook, error) {
+	webhook, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Update replaces the URL, events and state of a webhook. Activating a
+// disabled webhook clears its failures.
+func (s *webhookService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateWebhookRequest) (*model.Webhook, error) {
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Active && !webhook.Active {
+		webhook.FailureCount = 0
+		webhook.DisabledAt = nil
+		webhook.DisabledReason = ""
+	}
+	webhook.URL = req.URL
+	webhook.Events = uniqueStrings(req.Events)
+	webhook.Active = req.Active
+
+	if err := s.repo.Update(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Delete removes a webhook and its delivery log
+func (s *webhookService) Delete(ctx context.Context, id uint, userID uint) error {
+	if err := s.repo.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries retrieves the most recent deliveries of a webhook, newest
+// first. A limit that is not positive returns the default number of deliveries.
+func (s *webhookService) ListDeliveries(ctx context.Context, id uint, userID uint, limit int) ([]*model.WebhookDelivery, error) {
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultDeliveryLogLimit
+	}
+	limit = min(limit, maxDeliveryLogLimit)
+
+	deliveries, err := s.deliveries.ListByWebhookID(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Enqueue queues a delivery of an event to the user's subscribed webhooks
+func (s *webhookService) Enqueue(ctx context.Context, userID uint, eventType string, todoID uint, todo *model.Todo) error {
+	return s.enqueue(ctx, userID, model.WebhookPayload{
+		Type:   eventType,
+		TodoID: todoID,
+		Todo:   todo,
+	})
+}
+
+// NotifyMention queues a comment.mentioned delivery to the webhooks of a
+// user mentioned in a comment
+func (s *webhookService) NotifyMention(ctx context.Context, userID uint, todo *model.Todo, comment *model.TodoComment) error {
+	return s.enqueue(ctx, userID, model.WebhookPayload{
+		Type:    model.WebhookEventCommentMentioned,
+		TodoID:  todo.ID,
+		Todo:    todo,
+		Comment: comment,
+	})
+}
+
+// enqueue queues a delivery of a payload to the user's webhooks subscribed to
+// its type, filling in the event id and time
+func (s *webhookService) enqueue(ctx context.Context, userID uint, payload model.WebhookPayload) error {
+	webhooks, err := s.repo.ListSubscribed(ctx, userID, payload.Type)
+	if err != nil {
+		return fmt.Errorf("failed to find webhooks: %w", err)
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	eventID, err := newEventID()
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook event id: %w", err)
+	}
+
+	now := s.now()
+	payload.ID = eventID
+	payload.OccurredAt = now.UTC()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		delivery := &model.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			EventType:     payload.Type,
+			Payload:       body,
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		}
+		if err := s.deliveries.Create(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// WithWebhooks queues webhook deliveries for every todo change, in the transaction of the change
+func WithWebhooks(queue WebhookQueue) TodoServiceOption {
+	return func(s *todoService) {
+		s.webhooks = queue
+	}
+}
+
+// queueWebhooks queues the webhook events of a history action, if any.
+// Webhook event types match the change event types; a change that completes
+// a todo additionally sends todo.completed.
+func (s *todoService) queueWebhooks(ctx context.Context, action string, todoID, userID uint, before, after *model.Todo) error {
+	if s.webhooks == nil {
+		return nil
+	}
+	eventType, ok := historyEventTypes[action]
+	if !ok {
+		return nil
+	}
+
+	eventTypes := []string{eventType}
+	if before != nil && after != nil && !before.Completed && after.Completed {
+		eventTypes = append(eventTypes, model.WebhookEventTodoCompleted)
+	}
+
+	for _, eventType := range eventTypes {
+		if err := s.webhooks.Enqueue(ctx, userID, eventType, todoID, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkURL ensures raw is an absolute http or https URL whose host does not
+// resolve to a loopback, private, link-local or otherwise internal address.
+// Deliveries check the address again when connecting, in case it changes.
+func (s *webhookService) checkURL(ctx context.Context, raw string) error {
+	if err := webhook.CheckURL(ctx, s.resolver, raw); err != nil {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// uniqueStrings returns values without duplicates, keeping the first occurrence
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// newWebhookSecret returns a random signing secret
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
+
+// newEventID returns a random identifier for a webhook event
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
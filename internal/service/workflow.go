@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+)
+
+var (
+	ErrListsUnavailable     = errors.New("lists are not available")
+	ErrStateWithoutList     = errors.New("todo is not in a list")
+	ErrUnknownState         = errors.New("unknown workflow state")
+	ErrTransitionNotAllowed = errors.New("workflow transition not allowed")
+)
+
+// workflowFailure returns the status, error code and message that a workflow
+// error is reported with, the same way the todo handlers report it. ok is
+// false if err is not a workflow error.
+func workflowFailure(err error) (status int, code, message string, ok bool) {
+	switch {
+	case errors.Is(err, ErrListNotFound):
+		return http.StatusUnprocessableEntity, "list_not_found", "List not found", true
+	case errors.Is(err, ErrUnknownState):
+		return http.StatusUnprocessableEntity, "unknown_state", err.Error(), true
+	case errors.Is(err, ErrStateWithoutList):
+		return http.StatusUnprocessableEntity, "not_in_list", "Only todos in a list have a workflow state", true
+	case errors.Is(err, ErrTransitionNotAllowed):
+		return http.StatusConflict, "transition_not_allowed", err.Error(), true
+	default:
+		return 0, "", "", false
+	}
+}
+
+// WithLists enables lists of todos with workflow states
+func WithLists(lists repository.ListRepository) TodoServiceOption {
+	return func(s *todoService) {
+		s.lists = lists
+	}
+}
+
+// MoveTodo moves a todo into another list, to another state of its list or
+// out of its list, ensuring user ownership. Moves within a list must follow
+// the transitions of its workflow.
+func (s *todoService) MoveTodo(ctx context.Context, id uint, req *model.MoveTodoRequest, userID uint) (*model.Todo, error) {
+	if s.lists == nil {
+		return nil, ErrListsUnavailable
+	}
+
+	todo, err := s.getOwnedTodo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := *todo
+
+	switch {
+	case req.ListID == nil || *req.ListID == 0:
+		if req.State != "" {
+			return nil, ErrStateWithoutList
+		}
+		todo.ListID = nil
+		todo.State = ""
+	case todo.ListID != nil && *todo.ListID == *req.ListID:
+		if req.State != "" {
+			if err := s.transition(ctx, todo, req.State, userID); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		list, err := s.getList(ctx, *req.ListID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := enterList(todo, list, req.State); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.pruneCustomFields(ctx, todo, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveUpdate(ctx, &before, todo, userID); err != nil {
+		return nil, err
+	}
+	if err := s.markBlocked(ctx, userID, todo); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// GetBoard retrieves the todos of a list grouped by workflow state, ensuring user ownership
+func (s *todoService) GetBoard(ctx context.Context, listID uint, userID uint) (*model.BoardResponse, error) {
+	if s.lists == nil {
+		return nil, ErrListsUnavailable
+	}
+
+	list, err := s.getList(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.lists.ListTodos(ctx, userID, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todos: %w", err)
+	}
+	if err := s.markBlocked(ctx, userID, todos...); err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]*model.BoardColumn, len(list.Workflow.States))
+	board := &model.BoardResponse{
+		List:    list,
+		Columns: make([]model.BoardColumn, len(list.Workflow.States)),
+	}
+	for i, state := range list.Workflow.States {
+		board.Columns[i] = model.BoardColumn{WorkflowState: state, Todos: []*model.Todo{}}
+		columns[state.Key] = &board.Columns[i]
+	}
+	for _, todo := range todos {
+		column := columns[todo.State]
+		if column == nil {
+			// Workflow changes keep the states in use, so this only happens
+			// for a todo written concurrently with a workflow change
+			column = &board.Columns[0]
+		}
+		column.Todos = append(column.Todos, todo)
+		column.Count++
+		board.Count++
+	}
+
+	return board, nil
+}
+
+// createInList adds a new todo to the list in the create request, if any
+func (s *todoService) createInList(ctx context.Context, todo *model.Todo, req *model.CreateTodoRequest, userID uint) error {
+	if req.ListID == nil || *req.ListID == 0 {
+		if req.State != "" {
+			return ErrStateWithoutList
+		}
+		return nil
+	}
+
+	list, err := s.getList(ctx, *req.ListID, userID)
+	if err != nil {
+		return err
+	}
+	return enterList(todo, list, req.State)
+}
+
+// complete marks a todo as completed or not. A todo in a list moves to the
+// first state of the workflow, in workflow order, that it is allowed to move
+// to and that matches the completion.
+func (s *todoService) complete(ctx context.Context, todo *model.Todo, completed bool, userID uint) error {
+	if todo.ListID == nil || todo.Completed == completed {
+		setCompleted(todo, completed)
+		return nil
+	}
+
+	list, err := s.getList(ctx, *todo.ListID, userID)
+	if err != nil {
+		return err
+	}
+	for _, state := range list.Workflow.States {
+		if state.Terminal == completed && list.Workflow.Allows(todo.State, state.Key) {
+			todo.State = state.Key
+			setCompleted(todo, completed)
+			return nil
+		}
+	}
+
+	if completed {
+		return fmt.Errorf("%w: no transition from %q to a terminal state", ErrTransitionNotAllowed, todo.State)
+	}
+	return fmt.Errorf("%w: no transition from %q to a non-terminal state", ErrTransitionNotAllowed, todo.State)
+}
+
+// transition moves a todo to another state of its list, following the
+// transitions of the workflow, and updates its completion to match
+func (s *todoService) transition(ctx context.Context, todo *model.Todo, key string, userID uint) error {
+	if todo.ListID == nil {
+		return ErrStateWithoutList
+	}
+
+	list, err := s.getList(ctx, *todo.ListID, userID)
+	if err != nil {
+		return err
+	}
+	state := list.Workflow.State(key)
+	if state == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownState, key)
+	}
+	if key != todo.State && !list.Workflow.Allows(todo.State, key) {
+		return fmt.Errorf("%w: from %q to %q", ErrTransitionNotAllowed, todo.State, key)
+	}
+
+	todo.State = key
+	setCompleted(todo, state.Terminal)
+	return nil
+}
+
+// getList loads a list of the user
+func (s *todoService) getList(ctx context.Context, id uint, userID uint) (*model.TodoList, error) {
+	if s.lists == nil {
+		return nil, ErrListsUnavailable
+	}
+
+	list, err := s.lists.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+	return list, nil
+}
+
+// enterList puts a todo into a list in the given state. Without a state, the
+// todo starts in the first state of the workflow, or its first terminal state
+// if the todo is completed.
+func enterList(todo *model.Todo, list *model.TodoList, key string) error {
+	if key == "" {
+		key = list.Workflow.InitialState()
+		if todo.Completed {
+			for _, state := range list.Workflow.States {
+				if state.Terminal {
+					key = state.Key
+					break
+				}
+			}
+		}
+	}
+
+	state := list.Workflow.State(key)
+	if state == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownState, key)
+	}
+
+	listID := list.ID
+	todo.ListID = &listID
+	todo.State = key
+	setCompleted(todo, state.Terminal)
+	return nil
+}
@@ -0,0 +1,143 @@
+package plaintext
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// taskItem matches a GitHub-flavored Markdown task list item
+	taskItem = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])[ \t]+\[([ xX])\](?:[ \t]+(.*))?$`)
+
+	// escapedTaskItem matches note lines that would read as task list items
+	escapedTaskItem = regexp.MustCompile(`^\\*[ \t]*([-*+]|\d{1,9}[.)])[ \t]+\[[ xX]\]`)
+)
+
+// ParseMarkdown reads the text of a Markdown task list item after its checkbox:
+// an optional "(A) " priority followed by the text of the task and its tags
+func ParseMarkdown(text string, completed bool) (*Task, error) {
+	task := &Task{Completed: completed}
+	rest := parsePriority(task, strings.TrimSpace(text)+" ")
+	if err := parseText(task, rest); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// FormatMarkdown writes a task as a Markdown task list item, with its note as
+// indented lines below it, without a final line break
+func FormatMarkdown(task *Task) string {
+	var b strings.Builder
+	if task.Completed {
+		b.WriteString("- [x]")
+	} else {
+		b.WriteString("- [ ]")
+	}
+	if task.Priority != 0 {
+		b.WriteString(" (" + string(task.Priority) + ")")
+	}
+	if text := formatText(task, false); text != "" {
+		b.WriteString(" " + text)
+	}
+
+	if task.Note != "" {
+		for _, line := range strings.Split(strings.ReplaceAll(task.Note, "\r\n", "\n"), "\n") {
+			b.WriteString("\n")
+			if line == "" {
+				continue
+			}
+			if escapedTaskItem.MatchString(line) {
+				line = `\` + line
+			}
+			b.WriteString("  " + line)
+		}
+	}
+	return b.String()
+}
+
+// ReadMarkdown calls fn for every task list item of a Markdown document with
+// its line number. Lines indented below an item form its note; nested items
+// are tasks of their own, and other content is ignored. Items that cannot be
+// read are passed with an error wrapping ErrInvalidTask. Reading stops at the
+// first error fn returns.
+func ReadMarkdown(r io.Reader, fn func(line int, task *Task, err error) error) error {
+	var (
+		current    *Task
+		currentErr error
+		start      int
+		itemIndent int
+		indent     int
+		note       []string
+		blanks     int
+	)
+	flush := func() error {
+		if current == nil && currentErr == nil {
+			return nil
+		}
+		if current != nil && len(note) > 0 {
+			current.Note = strings.Join(note, "\n")
+		}
+		err := fn(start, current, currentErr)
+		current, currentErr, note, blanks = nil, nil, nil, 0
+		return err
+	}
+
+	scanner := newScanner(r)
+	inItem := false
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if m := taskItem.FindStringSubmatch(line); m != nil {
+			if err := flush(); err != nil {
+				return err
+			}
+			current, currentErr = ParseMarkdown(m[4], m[3] != " ")
+			start = number
+			itemIndent = len(m[1])
+			indent = itemIndent + len(m[2]) + 1
+			inItem = true
+			continue
+		}
+		if !inItem {
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if len(note) > 0 {
+				blanks++
+			}
+			continue
+		}
+		if lead := len(line) - len(strings.TrimLeft(line, " \t")); lead > itemIndent {
+			for ; blanks > 0; blanks-- {
+				note = append(note, "")
+			}
+			text := line[min(lead, indent):]
+			if escapedTaskItem.MatchString(text) {
+				text = text[1:]
+			}
+			note = append(note, text)
+			continue
+		}
+
+		// Anything else ends the item
+		if err := flush(); err != nil {
+			return err
+		}
+		inItem = false
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// WriteMarkdown writes a task as a Markdown task list item
+func WriteMarkdown(w io.Writer, task *Task) error {
+	_, err := io.WriteString(w, FormatMarkdown(task)+"\n")
+	return err
+}
@@ -0,0 +1,240 @@
+package plaintext
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	tests := []struct {
+		line string
+		want Task
+	}{
+		{
+			line: "Call mom",
+			want: Task{Text: "Call mom"},
+		},
+		{
+			line: "(A) 2024-01-01 Call mom +Family @phone due:2024-01-05",
+			want: Task{Text: "Call mom +Family @phone", Priority: 'A', CreatedAt: date(2024, 1, 1), DueAt: date(2024, 1, 5)},
+		},
+		{
+			line: "x 2024-01-06 2024-01-01 Call mom +Family pri:B",
+			want: Task{Text: "Call mom +Family", Priority: 'B', Completed: true, CompletedAt: date(2024, 1, 6), CreatedAt: date(2024, 1, 1)},
+		},
+		{
+			line: "x 2024-01-06 Call mom",
+			want: Task{Text: "Call mom", Completed: true, CompletedAt: date(2024, 1, 6)},
+		},
+		{
+			line: "x Call mom",
+			want: Task{Text: "Call mom", Completed: true},
+		},
+		{
+			// Only a lowercase x followed by a space marks a completed task
+			line: "X-ray appointment",
+			want: Task{Text: "X-ray appointment"},
+		},
+		{
+			line: "(a) lowercase is not a priority",
+			want: Task{Text: "(a) lowercase is not a priority"},
+		},
+		{
+			line: "Read https://example.com/a:b note:Line%20one%0ALine%20two",
+			want: Task{Text: "Read https://example.com/a:b", Note: "Line one\nLine two"},
+		},
+		{
+			line: "Ship release due:2024-01-05T17:30:00Z",
+			want: Task{Text: "Ship release", DueAt: func() *time.Time { t := time.Date(2024, 1, 5, 17, 30, 0, 0, time.UTC); return &t }()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			task, err := ParseTodoTxt(tt.line)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *task)
+		})
+	}
+}
+
+func TestParseTodoTxt_InvalidDue(t *testing.T) {
+	_, err := ParseTodoTxt("Call mom due:tomorrow")
+
+	assert.ErrorIs(t, err, ErrInvalidTask)
+}
+
+func TestTask_Tags(t *testing.T) {
+	task, err := ParseTodoTxt("(A) Plan trip +Travel +Family @home @phone rec:1w due:2024-01-05 http://example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Travel", "Family"}, task.Projects())
+	assert.Equal(t, []string{"home", "phone"}, task.Contexts())
+	assert.Equal(t, map[string]string{"rec": "1w"}, task.Tags())
+}
+
+func TestFormatTodoTxt(t *testing.T) {
+	tests := []struct {
+		name string
+		task Task
+		want string
+	}{
+		{"open", Task{Text: "Call mom +Family", Priority: 'A', CreatedAt: date(2024, 1, 1), DueAt: date(2024, 1, 5)}, "(A) 2024-01-01 Call mom +Family due:2024-01-05"},
+		{"completed keeps priority as tag", Task{Text: "Call mom", Priority: 'A', Completed: true, CompletedAt: date(2024, 1, 6), CreatedAt: date(2024, 1, 1)}, "x 2024-01-06 2024-01-01 Call mom pri:A"},
+		{"line breaks in text", Task{Text: "Call\nmom"}, "Call mom"},
+		{"note", Task{Text: "Call mom", Note: "Ask about 100% of\nthe plan"}, "Call mom note:Ask%20about%20100%25%20of%0Athe%20plan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatTodoTxt(&tt.task))
+		})
+	}
+}
+
+func roundTripTasks() []Task {
+	due := time.Date(2024, 1, 5, 17, 30, 0, 0, time.UTC)
+	return []Task{
+		{Text: "Call mom +Family @phone", Priority: 'A', CreatedAt: date(2024, 1, 1), DueAt: date(2024, 1, 5)},
+		{Text: "File taxes", Completed: true, CompletedAt: date(2024, 1, 6), CreatedAt: date(2024, 1, 1), Priority: 'C', DueAt: &due},
+		{Text: "Write report rec:1w", Note: "Sections:\n- [ ] intro\n\n  indented 50% done"},
+		{Text: "Plain"},
+	}
+}
+
+func TestTodoTxt_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	for _, task := range roundTripTasks() {
+		task := task
+		require.NoError(t, WriteTodoTxt(&buf, &task))
+	}
+
+	var read []Task
+	var lines []int
+	err := ReadTodoTxt(&buf, func(line int, task *Task, err error) error {
+		require.NoError(t, err)
+		read = append(read, *task)
+		lines = append(lines, line)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, roundTripTasks(), read)
+	assert.Equal(t, []int{1, 2, 3, 4}, lines)
+}
+
+func TestMarkdown_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	for _, task := range roundTripTasks() {
+		task := task
+		// Markdown task lists have no creation or completion dates
+		task.CreatedAt, task.CompletedAt = nil, nil
+		require.NoError(t, WriteMarkdown(&buf, &task))
+	}
+
+	var read []Task
+	err := ReadMarkdown(&buf, func(line int, task *Task, err error) error {
+		require.NoError(t, err)
+		read = append(read, *task)
+		return nil
+	})
+
+	require.NoError(t, err)
+	want := roundTripTasks()
+	for i := range want {
+		want[i].CreatedAt, want[i].CompletedAt = nil, nil
+	}
+	assert.Equal(t, want, read)
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	task := &Task{Text: "Write report", Priority: 'B', Completed: true, DueAt: date(2024, 1, 5), Note: "First\n- [ ] not a task"}
+
+	assert.Equal(t, "- [x] (B) Write report due:2024-01-05\n  First\n  \\- [ ] not a task", FormatMarkdown(task))
+}
+
+func TestReadMarkdown(t *testing.T) {
+	doc := strings.Join([]string{
+		"# Groceries",
+		"",
+		"Some intro text.",
+		"",
+		"- [ ] Buy milk",
+		"  2 liters",
+		"",
+		"  skimmed",
+		"- [X] Buy bread",
+		"  - [ ] Nested task",
+		"    with a note",
+		"- plain list item",
+		"1. [x] Numbered",
+		"* [ ] Bad due:never",
+		"",
+		"Trailing paragraph",
+	}, "\r\n")
+
+	type item struct {
+		line int
+		task *Task
+		err  error
+	}
+	var items []item
+	err := ReadMarkdown(strings.NewReader(doc), func(line int, task *Task, err error) error {
+		items = append(items, item{line, task, err})
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	assert.Equal(t, item{5, &Task{Text: "Buy milk", Note: "2 liters\n\nskimmed"}, nil}, items[0])
+	assert.Equal(t, item{9, &Task{Text: "Buy bread", Completed: true}, nil}, items[1])
+	assert.Equal(t, item{10, &Task{Text: "Nested task", Note: "with a note"}, nil}, items[2])
+	assert.Equal(t, item{13, &Task{Text: "Numbered", Completed: true}, nil}, items[3])
+	assert.Equal(t, 14, items[4].line)
+	assert.ErrorIs(t, items[4].err, ErrInvalidTask)
+}
+
+func TestReadTodoTxt_SkipsBlankLinesAndReportsErrors(t *testing.T) {
+	input := "\ufeff(A) First\n\n   \nSecond due:someday\nx Third\n"
+
+	var lines []int
+	var errs []error
+	err := ReadTodoTxt(strings.NewReader(input), func(line int, task *Task, err error) error {
+		lines = append(lines, line)
+		errs = append(errs, err)
+		if err == nil && line == 1 {
+			assert.Equal(t, byte('A'), task.Priority)
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 5}, lines)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrInvalidTask)
+	assert.NoError(t, errs[2])
+}
+
+func TestSplitAndJoinPriority(t *testing.T) {
+	text, priority := SplitPriority("Call mom pri:A +Family")
+	assert.Equal(t, "Call mom +Family", text)
+	assert.Equal(t, byte('A'), priority)
+
+	text, priority = SplitPriority("No priority pri:low")
+	assert.Equal(t, "No priority pri:low", text)
+	assert.Equal(t, byte(0), priority)
+
+	assert.Equal(t, "Call mom +Family pri:A", JoinPriority("Call mom +Family", 'A'))
+	assert.Equal(t, "Call mom", JoinPriority("Call mom", 0))
+}
@@ -0,0 +1,208 @@
+// Package plaintext reads and writes plain text task lists: the todo.txt format
+// (https://github.com/todotxt/todo.txt) and GitHub-flavored Markdown task lists.
+//
+// Both formats describe a task with a single line of text in which +project,
+// @context and key:value tags are part of the text. The due date, the priority
+// of completed tasks and a multi-line note are kept in the due:, pri: and note:
+// tags of todo.txt, so a Task written and read back is unchanged.
+package plaintext
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Tag keys with a meaning of their own
+const (
+	DueTag      = "due"
+	PriorityTag = "pri"
+	NoteTag     = "note"
+)
+
+// dateLayout is the layout of todo.txt dates
+const dateLayout = "2006-01-02"
+
+// ErrInvalidTask is wrapped by the errors returned for lines that cannot be read as a task
+var ErrInvalidTask = errors.New("invalid task")
+
+// Task is a single entry of a task list
+type Task struct {
+	// Text is the description of the task, including its +project, @context and
+	// key:value tags apart from those stored in the fields below
+	Text string
+	// Priority is a capital letter from A (highest) to Z, or 0 for none
+	Priority byte
+	// Completed marks done tasks
+	Completed bool
+	// CreatedAt and CompletedAt are calendar dates at midnight UTC
+	CreatedAt   *time.Time
+	CompletedAt *time.Time
+	// DueAt is a calendar date at midnight UTC, or a point in time when written with one
+	DueAt *time.Time
+	// Note is a free-form, possibly multi-line, text
+	Note string
+}
+
+// Projects returns the +project tags of the task
+func (t *Task) Projects() []string {
+	return words(t.Text, "+")
+}
+
+// Contexts returns the @context tags of the task
+func (t *Task) Contexts() []string {
+	return words(t.Text, "@")
+}
+
+// Tags returns the key:value tags of the task that have no field of their own
+func (t *Task) Tags() map[string]string {
+	tags := make(map[string]string)
+	for _, word := range strings.Fields(t.Text) {
+		if key, value, ok := splitTag(word); ok {
+			tags[key] = value
+		}
+	}
+	return tags
+}
+
+// SplitPriority removes a pri: tag from text and returns the priority it names,
+// or 0 if text has none
+func SplitPriority(text string) (string, byte) {
+	var priority byte
+	kept := make([]string, 0, 8)
+	for _, word := range strings.Fields(text) {
+		if key, value, ok := splitTag(word); ok && key == PriorityTag && isPriority(value) && priority == 0 {
+			priority = value[0]
+			continue
+		}
+		kept = append(kept, word)
+	}
+	if priority == 0 {
+		return text, 0
+	}
+	return strings.Join(kept, " "), priority
+}
+
+// JoinPriority appends a pri: tag naming priority to text. It returns text
+// unchanged for a priority of 0.
+func JoinPriority(text string, priority byte) string {
+	if priority == 0 {
+		return text
+	}
+	return strings.TrimSpace(text + " " + PriorityTag + ":" + string(priority))
+}
+
+// parseText moves the tags with fields of their own out of the text of a task
+func parseText(task *Task, text string) error {
+	kept := make([]string, 0, 8)
+	for _, word := range strings.Fields(text) {
+		key, value, ok := splitTag(word)
+		if !ok {
+			kept = append(kept, word)
+			continue
+		}
+
+		switch {
+		case key == DueTag:
+			due, err := parseDue(value)
+			if err != nil {
+				return err
+			}
+			task.DueAt = &due
+		case key == PriorityTag && isPriority(value) && task.Priority == 0:
+			task.Priority = value[0]
+		case key == NoteTag:
+			note, err := url.PathUnescape(value)
+			if err != nil {
+				return fmt.Errorf("%w: malformed note", ErrInvalidTask)
+			}
+			task.Note = note
+		default:
+			kept = append(kept, word)
+		}
+	}
+	task.Text = strings.Join(kept, " ")
+	return nil
+}
+
+// formatText returns the text of a task with the given extra tags appended
+func formatText(task *Task, withNote bool, extra ...string) string {
+	// Tasks are single lines; breaks in their text become spaces
+	parts := []string{strings.Join(strings.Fields(task.Text), " ")}
+	parts = append(parts, extra...)
+	if task.DueAt != nil {
+		parts = append(parts, DueTag+":"+formatDue(*task.DueAt))
+	}
+	if withNote && task.Note != "" {
+		parts = append(parts, NoteTag+":"+noteEscaper.Replace(task.Note))
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// noteEscaper percent-encodes the characters a tag value cannot contain
+var noteEscaper = strings.NewReplacer("%", "%25", " ", "%20", "\t", "%09", "\r", "%0D", "\n", "%0A")
+
+// parseDue reads the value of a due: tag: a date, or a point in time in RFC 3339
+func parseDue(value string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: due date %q must be YYYY-MM-DD or an RFC 3339 timestamp", ErrInvalidTask, value)
+}
+
+// formatDue writes a due date as a plain date when it is midnight UTC, the way
+// todo.txt apps write it, and as an RFC 3339 timestamp otherwise
+func formatDue(t time.Time) string {
+	t = t.UTC()
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format(dateLayout)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// parseDate reads a todo.txt date
+func parseDate(value string) (*time.Time, bool) {
+	if len(value) != len(dateLayout) {
+		return nil, false
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
+
+// formatDate writes the calendar date of a point in time in UTC
+func formatDate(t *time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+// splitTag splits a key:value tag. URLs and words with an empty key or value are not tags.
+func splitTag(word string) (string, string, bool) {
+	key, value, ok := strings.Cut(word, ":")
+	if !ok || key == "" || value == "" || strings.HasPrefix(value, "//") || strings.ContainsAny(key, "+@") {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// isPriority reports whether value is a single capital letter
+func isPriority(value string) bool {
+	return len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z'
+}
+
+// words returns the words of text that start with prefix, without it
+func words(text, prefix string) []string {
+	var found []string
+	for _, word := range strings.Fields(text) {
+		if len(word) > len(prefix) && strings.HasPrefix(word, prefix) {
+			found = append(found, word[len(prefix):])
+		}
+	}
+	return found
+}
@@ -0,0 +1,138 @@
+package plaintext
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// maxLineSize limits the length of a single line of a task list
+const maxLineSize = 1 << 20
+
+// ParseTodoTxt reads a single line of a todo.txt file:
+//
+//	x 2024-01-06 2024-01-01 Call mom +Family @phone due:2024-01-05
+//	(A) 2024-01-01 Write report +Work
+//
+// A completed task starts with "x", followed by its completion and creation
+// dates; an open task starts with its priority and creation date. All of them
+// are optional. The priority of a completed task is read from its pri: tag.
+func ParseTodoTxt(line string) (*Task, error) {
+	task := &Task{}
+	rest := strings.TrimSpace(line)
+
+	if strings.HasPrefix(rest, "x ") {
+		task.Completed = true
+		rest = strings.TrimLeft(rest[2:], " ")
+		rest = parsePriority(task, rest)
+		if date, ok := parseDate(firstWord(rest)); ok {
+			task.CompletedAt = date
+			rest = afterFirstWord(rest)
+			if date, ok := parseDate(firstWord(rest)); ok {
+				task.CreatedAt = date
+				rest = afterFirstWord(rest)
+			}
+		}
+	}
+
+	rest = parsePriority(task, rest)
+	if task.CreatedAt == nil {
+		if date, ok := parseDate(firstWord(rest)); ok {
+			task.CreatedAt = date
+			rest = afterFirstWord(rest)
+		}
+	}
+
+	if err := parseText(task, rest); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// FormatTodoTxt writes a task as a line of a todo.txt file, without a line break.
+// Completed tasks keep their priority in a pri: tag, as todo.txt apps do.
+func FormatTodoTxt(task *Task) string {
+	var parts []string
+	var extra []string
+	if task.Completed {
+		parts = append(parts, "x")
+		// The creation date of a completed task can only follow its completion date
+		if task.CompletedAt != nil {
+			parts = append(parts, formatDate(task.CompletedAt))
+			if task.CreatedAt != nil {
+				parts = append(parts, formatDate(task.CreatedAt))
+			}
+		}
+		if task.Priority != 0 {
+			extra = append(extra, PriorityTag+":"+string(task.Priority))
+		}
+	} else {
+		if task.Priority != 0 {
+			parts = append(parts, "("+string(task.Priority)+")")
+		}
+		if task.CreatedAt != nil {
+			parts = append(parts, formatDate(task.CreatedAt))
+		}
+	}
+
+	if text := formatText(task, true, extra...); text != "" {
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ReadTodoTxt calls fn for every task of a todo.txt file with its line number.
+// Lines that cannot be read are passed with an error wrapping ErrInvalidTask,
+// and blank lines are skipped. Reading stops at the first error fn returns.
+func ReadTodoTxt(r io.Reader, fn func(line int, task *Task, err error) error) error {
+	scanner := newScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := scanner.Text()
+		if number == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		task, err := ParseTodoTxt(line)
+		if err := fn(number, task, err); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// WriteTodoTxt writes a task as a line of a todo.txt file
+func WriteTodoTxt(w io.Writer, task *Task) error {
+	_, err := io.WriteString(w, FormatTodoTxt(task)+"\n")
+	return err
+}
+
+// parsePriority reads a leading "(A) " priority
+func parsePriority(task *Task, rest string) string {
+	if task.Priority == 0 && len(rest) >= 4 && rest[0] == '(' && isPriority(rest[1:2]) && rest[2] == ')' && rest[3] == ' ' {
+		task.Priority = rest[1]
+		return strings.TrimLeft(rest[4:], " ")
+	}
+	return rest
+}
+
+// newScanner returns a line scanner for task lists with long lines
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	return scanner
+}
+
+// firstWord returns the text up to the first space
+func firstWord(s string) string {
+	word, _, _ := strings.Cut(s, " ")
+	return word
+}
+
+// afterFirstWord returns the text after the first space
+func afterFirstWord(s string) string {
+	_, rest, _ := strings.Cut(s, " ")
+	return strings.TrimLeft(rest, " ")
+}
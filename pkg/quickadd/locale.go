@@ -0,0 +1,362 @@
+package quickadd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateOrder is the order of the day and month in numeric dates such as 4/5
+type DateOrder int
+
+const (
+	// MonthFirst reads 4/5 as April 5
+	MonthFirst DateOrder = iota
+	// DayFirst reads 4/5 as May 4
+	DayFirst
+)
+
+// RelativeDate is a date named relative to today
+type RelativeDate int
+
+const (
+	Today RelativeDate = iota
+	// Tonight is today at 8 pm unless a time is given
+	Tonight
+	Tomorrow
+	DayAfterTomorrow
+	// NextWeek is the first day of next week
+	NextWeek
+	// NextMonth is the first day of next month
+	NextMonth
+	// NextYear is January 1 of next year
+	NextYear
+	// EndOfMonth is the last day of this month
+	EndOfMonth
+)
+
+// Unit is the unit of a duration such as "in 2 weeks"
+type Unit int
+
+const (
+	Minute Unit = iota
+	Hour
+	Day
+	Week
+	Month
+	Year
+)
+
+// Locale holds the words and conventions of a language. Words are lowercase;
+// phrases of several words are separated by single spaces.
+type Locale struct {
+	// Tag is the BCP 47 language tag of the locale
+	Tag string
+	// Order is the order of the day and month in numeric dates
+	Order DateOrder
+	// DateSeparators lists the characters separating the parts of numeric dates.
+	// A date separated by dots needs a trailing dot or a year, so 1.5 stays a number.
+	DateSeparators string
+	// WeekStart is the first day of the week
+	WeekStart time.Weekday
+	// Prepositions may precede a date or time and are removed with it
+	Prepositions []string
+	// Phrases name dates relative to today
+	Phrases map[string]RelativeDate
+	// Weekdays name the days of the week. A weekday is its next occurrence after today.
+	Weekdays map[string]time.Weekday
+	// WeekdayPrefixes and WeekdaySuffixes may surround a weekday, like "next" and "prochain"
+	WeekdayPrefixes []string
+	WeekdaySuffixes []string
+	// Months name the months, for dates like "jan 5" or "5. Januar"
+	Months map[string]time.Month
+	// AmbiguousMonths are month names that are also common words, like "may".
+	// They only start a date after a preposition, before a year, capitalized,
+	// or with an ordinal or "of" between the day and the month.
+	AmbiguousMonths []string
+	// Of may separate the day from the month, as in "5th of april"
+	Of []string
+	// In starts a duration such as "in 2 weeks"
+	In []string
+	// Numbers are the number words of durations
+	Numbers map[string]int
+	// Units are the units of durations
+	Units map[string]Unit
+	// Times name times of day, in minutes after midnight
+	Times map[string]int
+	// AM and PM mark the hours of a 12-hour clock
+	AM []string
+	PM []string
+	// HourMarkers follow the hour of a 24-hour clock, like "Uhr" in "15 Uhr"
+	HourMarkers []string
+	// Priorities are the priority words written after an exclamation mark, like !high
+	Priorities map[string]byte
+}
+
+// English is the English locale with US conventions
+var English = newEnglish("en", MonthFirst, time.Sunday)
+
+// BritishEnglish is English with day-first dates and weeks starting on Monday
+var BritishEnglish = newEnglish("en-GB", DayFirst, time.Monday)
+
+// German is the German locale
+var German = &Locale{
+	Tag:            "de",
+	Order:          DayFirst,
+	DateSeparators: "./",
+	WeekStart:      time.Monday,
+	Prepositions:   []string{"am", "um", "bis", "zu", "zum", "spätestens"},
+	Phrases: map[string]RelativeDate{
+		"heute":           Today,
+		"heute abend":     Tonight,
+		"morgen":          Tomorrow,
+		"übermorgen":      DayAfterTomorrow,
+		"nächste woche":   NextWeek,
+		"kommende woche":  NextWeek,
+		"nächsten monat":  NextMonth,
+		"nächster monat":  NextMonth,
+		"nächstes jahr":   NextYear,
+		"monatsende":      EndOfMonth,
+		"ende des monats": EndOfMonth,
+	},
+	Weekdays: map[string]time.Weekday{
+		"montag": time.Monday, "dienstag": time.Tuesday, "mittwoch": time.Wednesday,
+		"donnerstag": time.Thursday, "freitag": time.Friday, "samstag": time.Saturday,
+		"sonnabend": time.Saturday, "sonntag": time.Sunday,
+	},
+	WeekdayPrefixes: []string{"nächsten", "nächster", "nächste", "kommenden", "kommender", "diesen", "dieser"},
+	Months: map[string]time.Month{
+		"januar": time.January, "jänner": time.January, "jan": time.January,
+		"februar": time.February, "feb": time.February,
+		"märz": time.March, "mär": time.March,
+		"april": time.April, "apr": time.April,
+		"mai":  time.May,
+		"juni": time.June, "jun": time.June,
+		"juli": time.July, "jul": time.July,
+		"august": time.August, "aug": time.August,
+		"september": time.September, "sep": time.September, "sept": time.September,
+		"oktober": time.October, "okt": time.October,
+		"november": time.November, "nov": time.November,
+		"dezember": time.December, "dez": time.December,
+	},
+	In: []string{"in"},
+	Numbers: map[string]int{
+		"ein": 1, "eine": 1, "einem": 1, "einer": 1, "einen": 1, "zwei": 2, "drei": 3, "vier": 4,
+		"fünf": 5, "sechs": 6, "sieben": 7, "acht": 8, "neun": 9, "zehn": 10, "elf": 11, "zwölf": 12,
+	},
+	Units: map[string]Unit{
+		"minute": Minute, "minuten": Minute,
+		"stunde": Hour, "stunden": Hour,
+		"tag": Day, "tage": Day, "tagen": Day,
+		"woche": Week, "wochen": Week,
+		"monat": Month, "monate": Month, "monaten": Month,
+		"jahr": Year, "jahre": Year, "jahren": Year,
+	},
+	Times:       map[string]int{"mittag": 12 * 60, "mitternacht": 0},
+	HourMarkers: []string{"uhr"},
+	Priorities: map[string]byte{
+		"hoch": PriorityHigh, "dringend": PriorityHigh, "wichtig": PriorityHigh,
+		"mittel":  PriorityMedium,
+		"niedrig": PriorityLow,
+	},
+}
+
+// French is the French locale
+var French = &Locale{
+	Tag:            "fr",
+	Order:          DayFirst,
+	DateSeparators: "/.",
+	WeekStart:      time.Monday,
+	Prepositions:   []string{"le", "à", "pour", "avant"},
+	Phrases: map[string]RelativeDate{
+		"aujourd'hui":          Today,
+		"ce soir":              Tonight,
+		"demain":               Tomorrow,
+		"après-demain":         DayAfterTomorrow,
+		"semaine prochaine":    NextWeek,
+		"la semaine prochaine": NextWeek,
+		"mois prochain":        NextMonth,
+		"le mois prochain":     NextMonth,
+		"l'année prochaine":    NextYear,
+		"l'an prochain":        NextYear,
+		"fin du mois":          EndOfMonth,
+	},
+	Weekdays: map[string]time.Weekday{
+		"lundi": time.Monday, "mardi": time.Tuesday, "mercredi": time.Wednesday,
+		"jeudi": time.Thursday, "vendredi": time.Friday, "samedi": time.Saturday,
+		"dimanche": time.Sunday,
+	},
+	WeekdayPrefixes: []string{"ce"},
+	WeekdaySuffixes: []string{"prochain", "prochaine"},
+	Months: map[string]time.Month{
+		// "sept" is left out as it also means seven
+		"janvier": time.January, "janv": time.January,
+		"février": time.February, "févr": time.February, "fév": time.February,
+		"mars":  time.March,
+		"avril": time.April, "avr": time.April,
+		"mai":     time.May,
+		"juin":    time.June,
+		"juillet": time.July, "juil": time.July,
+		"août":      time.August,
+		"septembre": time.September,
+		"octobre":   time.October, "oct": time.October,
+		"novembre": time.November, "nov": time.November,
+		"décembre": time.December, "déc": time.December,
+	},
+	In: []string{"dans"},
+	Numbers: map[string]int{
+		"un": 1, "une": 1, "deux": 2, "trois": 3, "quatre": 4, "cinq": 5, "six": 6,
+		"sept": 7, "huit": 8, "neuf": 9, "dix": 10, "onze": 11, "douze": 12,
+	},
+	Units: map[string]Unit{
+		"minute": Minute, "minutes": Minute,
+		"heure": Hour, "heures": Hour,
+		"jour": Day, "jours": Day,
+		"semaine": Week, "semaines": Week,
+		"mois": Month,
+		"an":   Year, "ans": Year, "année": Year, "années": Year,
+	},
+	Times:       map[string]int{"midi": 12 * 60, "minuit": 0},
+	HourMarkers: []string{"h"},
+	Priorities: map[string]byte{
+		"haute": PriorityHigh, "haut": PriorityHigh, "urgent": PriorityHigh, "urgente": PriorityHigh,
+		"moyenne": PriorityMedium, "moyen": PriorityMedium, "normale": PriorityMedium,
+		"basse": PriorityLow, "bas": PriorityLow,
+	},
+}
+
+// locales maps lowercase language tags to the built-in locales
+var locales = map[string]*Locale{
+	"en":    English,
+	"en-us": English,
+	"en-gb": BritishEnglish,
+	"en-au": BritishEnglish,
+	"en-ie": BritishEnglish,
+	"en-nz": BritishEnglish,
+	"de":    German,
+	"fr":    French,
+}
+
+// newEnglish returns the English words with the given conventions
+func newEnglish(tag string, order DateOrder, weekStart time.Weekday) *Locale {
+	separators := "/"
+	if order == DayFirst {
+		separators = "/."
+	}
+	return &Locale{
+		Tag:            tag,
+		Order:          order,
+		DateSeparators: separators,
+		WeekStart:      weekStart,
+		Prepositions:   []string{"on", "at", "by", "due", "before", "until"},
+		Phrases: map[string]RelativeDate{
+			"today":                  Today,
+			"tonight":                Tonight,
+			"this evening":           Tonight,
+			"tomorrow":               Tomorrow,
+			"tmr":                    Tomorrow,
+			"tmrw":                   Tomorrow,
+			"day after tomorrow":     DayAfterTomorrow,
+			"the day after tomorrow": DayAfterTomorrow,
+			"next week":              NextWeek,
+			"next month":             NextMonth,
+			"next year":              NextYear,
+			"end of month":           EndOfMonth,
+			"end of the month":       EndOfMonth,
+		},
+		// "sun" and "sat" are left out as they are common words
+		Weekdays: map[string]time.Weekday{
+			"monday": time.Monday, "mon": time.Monday,
+			"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+			"wednesday": time.Wednesday, "wed": time.Wednesday,
+			"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+			"friday": time.Friday, "fri": time.Friday,
+			"saturday": time.Saturday,
+			"sunday":   time.Sunday,
+		},
+		WeekdayPrefixes: []string{"next", "this", "coming"},
+		Months: map[string]time.Month{
+			"january": time.January, "jan": time.January,
+			"february": time.February, "feb": time.February,
+			"march": time.March, "mar": time.March,
+			"april": time.April, "apr": time.April,
+			"may":  time.May,
+			"june": time.June, "jun": time.June,
+			"july": time.July, "jul": time.July,
+			"august": time.August, "aug": time.August,
+			"september": time.September, "sep": time.September, "sept": time.September,
+			"october": time.October, "oct": time.October,
+			"november": time.November, "nov": time.November,
+			"december": time.December, "dec": time.December,
+		},
+		AmbiguousMonths: []string{"may"},
+		Of:              []string{"of"},
+		In:              []string{"in"},
+		Numbers: map[string]int{
+			"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+			"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+		},
+		Units: map[string]Unit{
+			"minute": Minute, "minutes": Minute, "min": Minute, "mins": Minute,
+			"hour": Hour, "hours": Hour, "hr": Hour, "hrs": Hour,
+			"day": Day, "days": Day,
+			"week": Week, "weeks": Week, "wk": Week, "wks": Week,
+			"month": Month, "months": Month,
+			"year": Year, "years": Year,
+		},
+		Times: map[string]int{"noon": 12 * 60, "midday": 12 * 60, "midnight": 0},
+		AM:    []string{"am", "a.m."},
+		PM:    []string{"pm", "p.m."},
+		Priorities: map[string]byte{
+			"high": PriorityHigh, "urgent": PriorityHigh,
+			"medium": PriorityMedium, "med": PriorityMedium,
+			"low": PriorityLow,
+		},
+	}
+}
+
+// LookupLocale returns the built-in locale for a language tag such as "en-GB"
+// or "de_AT". Tags of unknown regions fall back to their language.
+func LookupLocale(tag string) (*Locale, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if locale, ok := locales[tag]; ok {
+		return locale, true
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	locale, ok := locales[language]
+	return locale, ok
+}
+
+// Negotiate returns the built-in locale best matching an Accept-Language header,
+// or nil if it names no supported language
+func Negotiate(acceptLanguage string) *Locale {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+		if tag == "" || tag == "*" || quality <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, quality: quality})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if locale, ok := LookupLocale(c.tag); ok {
+			return locale
+		}
+	}
+	return nil
+}
@@ -0,0 +1,687 @@
+// Package quickadd reads a todo written as a single line of text, such as
+//
+//	Call Bob tomorrow 3pm !high #sales
+//
+// and splits it into its title, due date, priority and tags. Parsing is
+// deterministic: the result only depends on the text, the locale and the
+// reference time against which relative dates like "tomorrow" or "in 2 weeks"
+// are resolved. Text in double quotes is never interpreted, so
+// `Watch "Monday Night Football"` has no due date.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Priorities, named after the todo.txt priorities they correspond to
+const (
+	PriorityHigh   byte = 'A'
+	PriorityMedium byte = 'B'
+	PriorityLow    byte = 'C'
+)
+
+// Kinds of matches
+const (
+	MatchDate     = "date"
+	MatchTime     = "time"
+	MatchPriority = "priority"
+	MatchTag      = "tag"
+)
+
+// eveningClock is the time of Tonight, in minutes after midnight
+const eveningClock = 20 * 60
+
+var (
+	isoDate     = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	numericDate = regexp.MustCompile(`^(\d{1,2})([/.])(\d{1,2})(?:([/.])(\d{4}|\d{2}))?(\.?)$`)
+	dayNumber   = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th|er|e|\.)?$`)
+	yearNumber  = regexp.MustCompile(`^\d{4}$`)
+	clock       = regexp.MustCompile(`^(\d{1,2})(?:([:.h])(\d{2}))?$`)
+	tag         = regexp.MustCompile(`^#\pL[\pL\pN_-]*$`)
+)
+
+// universalPriorities are the priority markers of every locale
+var universalPriorities = map[string]byte{
+	"!!!": PriorityHigh, "!!": PriorityMedium, "!": PriorityLow,
+	"!1": PriorityHigh, "!2": PriorityMedium, "!3": PriorityLow,
+	"p1": PriorityHigh, "p2": PriorityMedium, "p3": PriorityLow,
+}
+
+// Options configure Parse
+type Options struct {
+	// Now is the reference time of relative dates
+	Now time.Time
+	// Location is the time zone of dates and times; it defaults to UTC
+	Location *time.Location
+	// Locale defaults to English
+	Locale *Locale
+}
+
+// Result is the interpretation of a line of text
+type Result struct {
+	// Title is the text left after removing the dates, times, priority and tags
+	Title string
+	// DueAt is the due date, if one was found. Dates without a time are all-day
+	// dates at midnight UTC, the way plain dates are stored elsewhere.
+	DueAt  *time.Time
+	AllDay bool
+	// Priority is PriorityHigh, PriorityMedium, PriorityLow or 0 for none
+	Priority byte
+	// Tags are the #hashtags, without the hash, in order of appearance
+	Tags []string
+	// Matches are the parts of the text that were interpreted, in order of appearance
+	Matches []Match
+}
+
+// Match is a part of the text that was interpreted
+type Match struct {
+	// Kind is MatchDate, MatchTime, MatchPriority or MatchTag
+	Kind string
+	// Text is the matched text, including a preposition such as "at" before a date or time
+	Text string
+}
+
+// Parse interprets a line of text. Only the first date, time and priority are
+// used; later ones stay in the title.
+func Parse(text string, opts Options) *Result {
+	p := &parser{
+		tokens: tokenize(text),
+		locale: opts.Locale,
+		loc:    opts.Location,
+	}
+	if p.locale == nil {
+		p.locale = English
+	}
+	if p.loc == nil {
+		p.loc = time.UTC
+	}
+	p.now = opts.Now.In(p.loc)
+
+	for i := 0; i < len(p.tokens); {
+		if n := p.match(i); n > 0 {
+			i += n
+		} else {
+			i++
+		}
+	}
+	return p.result()
+}
+
+// token is a word of the text, or a quoted part of it
+type token struct {
+	// text is the token as written, without quotes
+	text string
+	// word is the lowercased text without trailing punctuation
+	word    string
+	literal bool
+	used    bool
+}
+
+// tokenize splits text into words and quoted parts
+func tokenize(text string) []token {
+	var tokens []token
+	rest := text
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return tokens
+		}
+
+		if open := quoteLen(rest); open > 0 {
+			// An unterminated quote runs to the end of the text
+			quoted := rest[open:]
+			rest = ""
+			if end := strings.IndexAny(quoted, "\"”"); end >= 0 {
+				_, size := utf8.DecodeRuneInString(quoted[end:])
+				quoted, rest = quoted[:end], quoted[end+size:]
+			}
+			if quoted = strings.TrimSpace(quoted); quoted != "" {
+				tokens = append(tokens, token{text: quoted, literal: true})
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		tokens = append(tokens, token{text: rest[:end], word: normalize(rest[:end])})
+		rest = rest[end:]
+	}
+}
+
+// quoteLen returns the length of an opening quote at the start of s, or 0
+func quoteLen(s string) int {
+	for _, quote := range []string{"\"", "“"} {
+		if strings.HasPrefix(s, quote) {
+			return len(quote)
+		}
+	}
+	return 0
+}
+
+// normalize lowercases a word and removes its trailing punctuation, keeping
+// priority markers like "!!" and dots that may belong to dates
+func normalize(word string) string {
+	word = strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+	if strings.HasPrefix(word, "!") {
+		return strings.TrimRight(word, ",;:?")
+	}
+	return strings.TrimRight(word, ",;:?!")
+}
+
+// parser holds the state of a single Parse call
+type parser struct {
+	tokens []token
+	locale *Locale
+	loc    *time.Location
+	now    time.Time
+
+	// prepositioned is set while reading a date or time that follows a preposition
+	prepositioned bool
+
+	date     time.Time
+	hasDate  bool
+	clock    int
+	hasClock bool
+	evening  bool
+	instant  *time.Time
+
+	priority byte
+	tags     []string
+	matches  []Match
+}
+
+// word returns the word at i, or "" past the end of the text or for quoted parts
+func (p *parser) word(i int) string {
+	if i >= len(p.tokens) || p.tokens[i].literal {
+		return ""
+	}
+	return p.tokens[i].word
+}
+
+// bare returns the word at i without a trailing dot
+func (p *parser) bare(i int) string {
+	return strings.TrimSuffix(p.word(i), ".")
+}
+
+// match interprets the text starting at token i and returns the number of tokens used
+func (p *parser) match(i int) int {
+	if p.tokens[i].literal {
+		return 0
+	}
+	if p.matchTag(i) || p.matchPriority(i) {
+		return 1
+	}
+
+	// Prepositions are only removed together with the date or time following them
+	j := i
+	for j < len(p.tokens) && j-i < 2 && contains(p.locale.Prepositions, p.bare(j)) {
+		j++
+	}
+	p.prepositioned = j > i
+	n, kind := p.matchWhen(j)
+	if n == 0 {
+		if j == i {
+			return 0
+		}
+		// The preposition may itself start a date, like "le" in "le mois prochain"
+		p.prepositioned = false
+		if n, kind = p.matchWhen(i); n == 0 {
+			return 0
+		}
+		j = i
+	}
+	p.record(kind, i, j+n)
+	return j + n - i
+}
+
+// record marks the tokens from i up to end as used by a match
+func (p *parser) record(kind string, i, end int) {
+	texts := make([]string, 0, end-i)
+	for k := i; k < end; k++ {
+		p.tokens[k].used = true
+		texts = append(texts, p.tokens[k].text)
+	}
+	p.matches = append(p.matches, Match{Kind: kind, Text: strings.Join(texts, " ")})
+}
+
+// matchTag reads a #hashtag
+func (p *parser) matchTag(i int) bool {
+	text := strings.TrimRight(p.tokens[i].text, ",;:?!.")
+	if !tag.MatchString(text) {
+		return false
+	}
+	p.tokens[i].used = true
+	p.matches = append(p.matches, Match{Kind: MatchTag, Text: p.tokens[i].text})
+	for _, existing := range p.tags {
+		if strings.EqualFold(existing, text[1:]) {
+			return true
+		}
+	}
+	p.tags = append(p.tags, text[1:])
+	return true
+}
+
+// matchPriority reads the first priority marker
+func (p *parser) matchPriority(i int) bool {
+	if p.priority != 0 {
+		return false
+	}
+	word := p.word(i)
+	priority, ok := universalPriorities[word]
+	if !ok && strings.HasPrefix(word, "!") {
+		priority, ok = p.locale.Priorities[word[1:]]
+	}
+	if !ok {
+		return false
+	}
+	p.priority = priority
+	p.tokens[i].used = true
+	p.matches = append(p.matches, Match{Kind: MatchPriority, Text: p.tokens[i].text})
+	return true
+}
+
+// matchWhen reads a date or time at token i
+func (p *parser) matchWhen(i int) (int, string) {
+	if i >= len(p.tokens) || p.instant != nil {
+		return 0, ""
+	}
+	if !p.hasClock {
+		if n := p.matchClock(i); n > 0 {
+			return n, MatchTime
+		}
+	}
+	if !p.hasDate {
+		return p.matchDate(i)
+	}
+	return 0, ""
+}
+
+// matchDate reads a relative or absolute date, or a duration
+func (p *parser) matchDate(i int) (int, string) {
+	if n, relative := matchPhrase(p, i, p.locale.Phrases); n > 0 {
+		p.setRelative(relative)
+		return n, MatchDate
+	}
+	if n, kind := p.matchDuration(i); n > 0 {
+		return n, kind
+	}
+	for _, match := range []func(int) int{p.matchWeekday, p.matchNumericDate, p.matchNamedDate} {
+		if n := match(i); n > 0 {
+			return n, MatchDate
+		}
+	}
+	return 0, ""
+}
+
+// setRelative sets the date named by a relative date phrase
+func (p *parser) setRelative(relative RelativeDate) {
+	today := p.today()
+	switch relative {
+	case Today:
+		p.setDate(today)
+	case Tonight:
+		p.setDate(today)
+		p.evening = true
+	case Tomorrow:
+		p.setDate(today.AddDate(0, 0, 1))
+	case DayAfterTomorrow:
+		p.setDate(today.AddDate(0, 0, 2))
+	case NextWeek:
+		days := (7 + int(p.locale.WeekStart) - int(today.Weekday())) % 7
+		if days == 0 {
+			days = 7
+		}
+		p.setDate(today.AddDate(0, 0, days))
+	case NextMonth:
+		p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, time.UTC))
+	case NextYear:
+		p.setDate(time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC))
+	case EndOfMonth:
+		p.setDate(time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, time.UTC))
+	}
+}
+
+// matchDuration reads a duration such as "in 2 weeks" or "in an hour"
+func (p *parser) matchDuration(i int) (int, string) {
+	if !contains(p.locale.In, p.bare(i)) {
+		return 0, ""
+	}
+	count, ok := p.locale.Numbers[p.bare(i+1)]
+	if !ok {
+		n, err := strconv.Atoi(p.word(i + 1))
+		if err != nil || n < 1 || n > 999 {
+			return 0, ""
+		}
+		count = n
+	}
+	unit, ok := p.locale.Units[p.bare(i+2)]
+	if !ok {
+		return 0, ""
+	}
+
+	today := p.today()
+	switch unit {
+	case Minute, Hour:
+		// A point in time cannot be combined with a separate date or time
+		if p.hasClock {
+			return 0, ""
+		}
+		step := time.Minute
+		if unit == Hour {
+			step = time.Hour
+		}
+		instant := p.now.Add(time.Duration(count) * step).Truncate(time.Minute)
+		p.instant = &instant
+		return 3, MatchTime
+	case Day:
+		p.setDate(today.AddDate(0, 0, count))
+	case Week:
+		p.setDate(today.AddDate(0, 0, 7*count))
+	case Month:
+		p.setDate(addMonths(today, count))
+	case Year:
+		p.setDate(addMonths(today, 12*count))
+	}
+	return 3, MatchDate
+}
+
+// matchWeekday reads a weekday such as "friday", "next friday" or "vendredi prochain"
+func (p *parser) matchWeekday(i int) int {
+	j := i
+	if contains(p.locale.WeekdayPrefixes, p.bare(j)) {
+		j++
+	}
+	weekday, ok := p.locale.Weekdays[p.bare(j)]
+	if !ok {
+		return 0
+	}
+	j++
+	if contains(p.locale.WeekdaySuffixes, p.bare(j)) {
+		j++
+	}
+
+	today := p.today()
+	days := (7 + int(weekday) - int(today.Weekday())) % 7
+	if days == 0 {
+		days = 7
+	}
+	p.setDate(today.AddDate(0, 0, days))
+	return j - i
+}
+
+// matchNumericDate reads an ISO 8601 date or a date such as 4/15 or 15.4.2025
+func (p *parser) matchNumericDate(i int) int {
+	word := p.word(i)
+	if m := isoDate.FindStringSubmatch(word); m != nil {
+		return p.setCalendarDate(atoi(m[1]), atoi(m[2]), atoi(m[3]))
+	}
+
+	m := numericDate.FindStringSubmatch(word)
+	if m == nil || !strings.Contains(p.locale.DateSeparators, m[2]) || (m[4] != "" && m[4] != m[2]) {
+		return 0
+	}
+	if m[2] == "." && m[5] == "" && m[6] == "" {
+		return 0
+	}
+	month, day := atoi(m[1]), atoi(m[3])
+	if p.locale.Order == DayFirst {
+		month, day = day, month
+	}
+	year := 0
+	if m[5] != "" {
+		year = atoi(m[5])
+		if year < 100 {
+			year += 2000
+		}
+	}
+	return p.setCalendarDate(year, month, day)
+}
+
+// matchNamedDate reads a date with a month name, such as "jan 5", "5th of april"
+// or "5. Januar 2025"
+func (p *parser) matchNamedDate(i int) int {
+	if m := dayNumber.FindStringSubmatch(p.word(i)); m != nil {
+		j := i + 1
+		if contains(p.locale.Of, p.bare(j)) {
+			j++
+		}
+		if month, ok := p.locale.Months[p.bare(j)]; ok {
+			// An ordinal or "of" marks "5th may" and "5 of may" as dates
+			marked := j > i+1 || m[0] != m[1]
+			return p.setNamedDate(i, j, j+1, month, atoi(m[1]), marked)
+		}
+	}
+
+	if month, ok := p.locale.Months[p.bare(i)]; ok {
+		if m := dayNumber.FindStringSubmatch(p.word(i + 1)); m != nil {
+			return p.setNamedDate(i, i, i+2, month, atoi(m[1]), false)
+		}
+	}
+	return 0
+}
+
+// setNamedDate sets a date with a month name read from token i up to end, which
+// may be followed by a year. The month name is token at; an ambiguous one like
+// "may" must be marked as a date by its context.
+func (p *parser) setNamedDate(i, at, end int, month time.Month, day int, marked bool) int {
+	if word := p.word(end); yearNumber.MatchString(word) {
+		if n := p.setCalendarDate(atoi(word), int(month), day); n > 0 {
+			return end + 1 - i
+		}
+	}
+	if contains(p.locale.AmbiguousMonths, p.bare(at)) && !marked && !p.prepositioned && !startsUpper(p.tokens[at].text) {
+		return 0
+	}
+	if n := p.setCalendarDate(0, int(month), day); n > 0 {
+		return end - i
+	}
+	return 0
+}
+
+// setCalendarDate sets a date and returns 1, or returns 0 if there is no such
+// date. A year of 0 stands for the next occurrence of the date from today.
+func (p *parser) setCalendarDate(year, month, day int) int {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return 0
+	}
+	if year != 0 {
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if date.Day() != day {
+			return 0
+		}
+		p.setDate(date)
+		return 1
+	}
+
+	today := p.today()
+	// February 29 may be up to 8 years away
+	for k := 0; k <= 8; k++ {
+		date := time.Date(today.Year()+k, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if date.Day() == day && !date.Before(today) {
+			p.setDate(date)
+			return 1
+		}
+	}
+	return 0
+}
+
+// matchClock reads a time of day such as 3pm, 3:30 pm, 15:00, 15 Uhr, 15h30 or noon
+func (p *parser) matchClock(i int) int {
+	if n, minutes := matchPhrase(p, i, p.locale.Times); n > 0 {
+		p.setClock(minutes)
+		return n
+	}
+
+	n := 1
+	core, suffix := p.splitClockSuffix(p.word(i))
+	if next := p.word(i + 1); suffix == "" {
+		switch {
+		case contains(p.locale.AM, next), contains(p.locale.PM, next):
+			suffix, n = next, 2
+		case contains(p.locale.HourMarkers, p.bare(i+1)):
+			suffix, n = p.bare(i+1), 2
+		}
+	}
+
+	m := clock.FindStringSubmatch(core)
+	if m == nil {
+		return 0
+	}
+	hour, minute := atoi(m[1]), atoi(m[3])
+	switch {
+	case contains(p.locale.AM, suffix) || contains(p.locale.PM, suffix):
+		if hour < 1 || hour > 12 {
+			return 0
+		}
+		hour %= 12
+		if contains(p.locale.PM, suffix) {
+			hour += 12
+		}
+	case suffix != "", m[2] == ":", m[2] == "h":
+		if hour > 23 {
+			return 0
+		}
+	default:
+		// A bare number or a decimal like 1.5 is not a time
+		return 0
+	}
+	if minute > 59 {
+		return 0
+	}
+
+	p.setClock(hour*60 + minute)
+	return n
+}
+
+// splitClockSuffix splits an am/pm mark or hour marker off the end of a word
+func (p *parser) splitClockSuffix(word string) (string, string) {
+	for _, suffixes := range [][]string{p.locale.AM, p.locale.PM, p.locale.HourMarkers} {
+		for _, suffix := range suffixes {
+			if len(word) > len(suffix) && strings.HasSuffix(word, suffix) {
+				return word[:len(word)-len(suffix)], suffix
+			}
+		}
+	}
+	return word, ""
+}
+
+// today returns the current date in the location of the parser, at midnight UTC
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (p *parser) setDate(date time.Time) {
+	p.date = date
+	p.hasDate = true
+}
+
+func (p *parser) setClock(minutes int) {
+	p.clock = minutes
+	p.hasClock = true
+}
+
+// result assembles the result from the matches
+func (p *parser) result() *Result {
+	var title []string
+	for _, t := range p.tokens {
+		if !t.used {
+			title = append(title, t.text)
+		}
+	}
+
+	result := &Result{
+		Title:    strings.Trim(strings.Join(title, " "), " ,;:-–"),
+		Priority: p.priority,
+		Tags:     p.tags,
+		Matches:  p.matches,
+	}
+
+	var due time.Time
+	switch {
+	case p.instant != nil:
+		due = *p.instant
+	case p.hasDate && (p.hasClock || p.evening):
+		minutes := eveningClock
+		if p.hasClock {
+			minutes = p.clock
+		}
+		due = time.Date(p.date.Year(), p.date.Month(), p.date.Day(), minutes/60, minutes%60, 0, 0, p.loc)
+	case p.hasDate:
+		due = p.date
+		result.AllDay = true
+	case p.hasClock:
+		// A time without a date is its next occurrence
+		due = time.Date(p.now.Year(), p.now.Month(), p.now.Day(), p.clock/60, p.clock%60, 0, 0, p.loc)
+		if !due.After(p.now) {
+			due = time.Date(p.now.Year(), p.now.Month(), p.now.Day()+1, p.clock/60, p.clock%60, 0, 0, p.loc)
+		}
+	default:
+		return result
+	}
+	due = due.UTC()
+	result.DueAt = &due
+	return result
+}
+
+// matchPhrase finds the longest phrase of table starting at token i and
+// returns its length in tokens and its value
+func matchPhrase[V any](p *parser, i int, table map[string]V) (int, V) {
+	var (
+		longest int
+		value   V
+	)
+	for phrase, v := range table {
+		words := strings.Fields(phrase)
+		if len(words) <= longest {
+			continue
+		}
+		matched := true
+		for k, word := range words {
+			if p.bare(i+k) != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			longest, value = len(words), v
+		}
+	}
+	return longest, value
+}
+
+// addMonths adds months to a date, keeping the day within the target month
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), last)-1)
+}
+
+func contains(words []string, word string) bool {
+	if word == "" {
+		return false
+	}
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// startsUpper reports whether s starts with an uppercase letter
+func startsUpper(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,229 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// now is Wednesday, March 13, 2024, 10:00 UTC
+var now = time.Date(2024, 3, 13, 10, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		locale   *Locale
+		location *time.Location
+		now      time.Time
+		text     string
+		title    string
+		due      string
+		allDay   bool
+		priority byte
+		tags     []string
+	}{
+		// Titles without anything to interpret
+		{text: "Buy milk", title: "Buy milk"},
+		{text: "  Buy   milk  ", title: "Buy milk"},
+		{text: "Fix issue #1", title: "Fix issue #1"},
+		{text: "Meet at home", title: "Meet at home"},
+		{text: "Call Bob at 3", title: "Call Bob at 3"},
+		{text: "Add 1.5 cups", title: "Add 1.5 cups", locale: BritishEnglish},
+
+		// Everything at once
+		{text: "Call Bob tomorrow 3pm !high #sales", title: "Call Bob", due: "2024-03-14T15:00:00Z", priority: 'A', tags: []string{"sales"}},
+		{text: "#sales !high Call Bob at 3pm tomorrow", title: "Call Bob", due: "2024-03-14T15:00:00Z", priority: 'A', tags: []string{"sales"}},
+
+		// Relative dates
+		{text: "Pay rent today", title: "Pay rent", due: "2024-03-13T00:00:00Z", allDay: true},
+		{text: "Pay rent tmrw", title: "Pay rent", due: "2024-03-14T00:00:00Z", allDay: true},
+		{text: "Call Bob, tomorrow.", title: "Call Bob", due: "2024-03-14T00:00:00Z", allDay: true},
+		{text: "Dentist the day after tomorrow at 9:30am", title: "Dentist", due: "2024-03-15T09:30:00Z"},
+		{text: "Ship it tonight", title: "Ship it", due: "2024-03-13T20:00:00Z"},
+		{text: "Ship it tonight at 9pm", title: "Ship it", due: "2024-03-13T21:00:00Z"},
+		{text: "Plan sprint next week", title: "Plan sprint", due: "2024-03-17T00:00:00Z", allDay: true},
+		{text: "Plan sprint next week", title: "Plan sprint", due: "2024-03-18T00:00:00Z", allDay: true, locale: BritishEnglish},
+		{text: "Renew passport next month", title: "Renew passport", due: "2024-04-01T00:00:00Z", allDay: true},
+		{text: "File taxes next year", title: "File taxes", due: "2025-01-01T00:00:00Z", allDay: true},
+		{text: "Close books end of the month", title: "Close books", due: "2024-03-31T00:00:00Z", allDay: true},
+
+		// Weekdays are their next occurrence after today
+		{text: "Submit report by friday", title: "Submit report", due: "2024-03-15T00:00:00Z", allDay: true},
+		{text: "Team lunch wednesday", title: "Team lunch", due: "2024-03-20T00:00:00Z", allDay: true},
+		{text: "Team lunch next Wednesday", title: "Team lunch", due: "2024-03-20T00:00:00Z", allDay: true},
+		{text: "Standup on mon at 9am", title: "Standup", due: "2024-03-18T09:00:00Z"},
+		{text: "Enjoy the sun", title: "Enjoy the sun"},
+
+		// Durations
+		{text: "Check oven in 20 minutes", title: "Check oven", due: "2024-03-13T10:20:00Z"},
+		{text: "Call back in an hour", title: "Call back", due: "2024-03-13T11:00:00Z"},
+		{text: "Follow up in 2 weeks", title: "Follow up", due: "2024-03-27T00:00:00Z", allDay: true},
+		{text: "Invoice in three days", title: "Invoice", due: "2024-03-16T00:00:00Z", allDay: true},
+		{text: "Review in a month", title: "Review", due: "2024-04-13T00:00:00Z", allDay: true},
+		{text: "Renew in 1 month", title: "Renew", due: "2024-02-29T00:00:00Z", allDay: true, now: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)},
+		{text: "Move in 2 years", title: "Move", due: "2026-03-13T00:00:00Z", allDay: true},
+		{text: "Tomorrow in 2 hours", title: "in 2 hours", due: "2024-03-14T00:00:00Z", allDay: true},
+
+		// Times without a date are their next occurrence
+		{text: "Call mom at noon", title: "Call mom", due: "2024-03-13T12:00:00Z"},
+		{text: "Take pills 8am", title: "Take pills", due: "2024-03-14T08:00:00Z"},
+		{text: "Take pills 8 a.m.", title: "Take pills", due: "2024-03-14T08:00:00Z"},
+		{text: "Meeting 12pm", title: "Meeting", due: "2024-03-13T12:00:00Z"},
+		{text: "Deploy 23:30", title: "Deploy", due: "2024-03-13T23:30:00Z"},
+		{text: "Lunch 13pm", title: "Lunch 13pm"},
+		{text: "Deploy 24:00", title: "Deploy 24:00"},
+
+		// Absolute dates
+		{text: "Conference jan 5", title: "Conference", due: "2025-01-05T00:00:00Z", allDay: true},
+		{text: "Conference on January 5th, 2025", title: "Conference", due: "2025-01-05T00:00:00Z", allDay: true},
+		{text: "Birthday 5th of april", title: "Birthday", due: "2024-04-05T00:00:00Z", allDay: true},
+		{text: "Tax day 4/15", title: "Tax day", due: "2024-04-15T00:00:00Z", allDay: true},
+		{text: "Tax day 15/4", title: "Tax day", due: "2024-04-15T00:00:00Z", allDay: true, locale: BritishEnglish},
+		{text: "Tax day 4/15", title: "Tax day 4/15", locale: BritishEnglish},
+		{text: "Tax day 4/15/25", title: "Tax day", due: "2025-04-15T00:00:00Z", allDay: true},
+		{text: "Release 2024-06-01 17:00", title: "Release", due: "2024-06-01T17:00:00Z"},
+		{text: "Today is march 13", title: "is march 13", due: "2024-03-13T00:00:00Z", allDay: true},
+		{text: "Call feb 30", title: "Call feb 30"},
+		{text: "Leap day feb 29", title: "Leap day", due: "2028-02-29T00:00:00Z", allDay: true},
+		{text: "Ask if we may 5 people", title: "Ask if we may 5 people"},
+		{text: "The 3 may be late", title: "The 3 may be late"},
+		{text: "Party on may 5", title: "Party", due: "2024-05-05T00:00:00Z", allDay: true},
+		{text: "Party May 5", title: "Party", due: "2024-05-05T00:00:00Z", allDay: true},
+		{text: "Party may 5 2025", title: "Party", due: "2025-05-05T00:00:00Z", allDay: true},
+		{text: "Party 5th may", title: "Party", due: "2024-05-05T00:00:00Z", allDay: true},
+
+		// Priorities; only the first counts
+		{text: "Fix bug !!!", title: "Fix bug", priority: 'A'},
+		{text: "Fix bug !!", title: "Fix bug", priority: 'B'},
+		{text: "Fix bug !", title: "Fix bug", priority: 'C'},
+		{text: "Fix bug!", title: "Fix bug!"},
+		{text: "p2 Fix bug", title: "Fix bug", priority: 'B'},
+		{text: "Fix bug !3", title: "Fix bug", priority: 'C'},
+		{text: "Fix bug !Low", title: "Fix bug", priority: 'C'},
+		{text: "Fix bug !high !low", title: "Fix bug !low", priority: 'A'},
+		{text: "Fix bug !unknown", title: "Fix bug !unknown"},
+
+		// Tags keep their case; duplicates are dropped
+		{text: "Plan #Q2 budget #sales #Sales", title: "Plan budget", tags: []string{"Q2", "sales"}},
+		{text: "Plan #café,", title: "Plan", tags: []string{"café"}},
+
+		// Quoted text is never interpreted
+		{text: `Watch "Monday Night Football" tomorrow`, title: "Watch Monday Night Football", due: "2024-03-14T00:00:00Z", allDay: true},
+		{text: "Read “Tomorrow and Tomorrow” #books", title: "Read Tomorrow and Tomorrow", tags: []string{"books"}},
+		{text: `Say "at 3pm`, title: "Say at 3pm"},
+
+		// Time zones
+		{text: "Call Bob tomorrow 3pm", title: "Call Bob", due: "2024-03-14T19:00:00Z", location: newYork},
+		{text: "Standup 9am", title: "Standup", due: "2024-03-13T13:00:00Z", location: newYork},
+		{text: "Pay rent today", title: "Pay rent", due: "2024-03-13T00:00:00Z", allDay: true, location: newYork},
+		{text: "Pay rent tomorrow", title: "Pay rent", due: "2024-03-14T00:00:00Z", allDay: true, location: newYork, now: time.Date(2024, 3, 14, 2, 0, 0, 0, time.UTC)},
+
+		// German
+		{text: "Bob anrufen morgen um 15 Uhr !hoch #vertrieb", title: "Bob anrufen", due: "2024-03-14T15:00:00Z", priority: 'A', tags: []string{"vertrieb"}, locale: German},
+		{text: "Steuer bis 5.4.", title: "Steuer", due: "2024-04-05T00:00:00Z", allDay: true, locale: German},
+		{text: "Urlaub am 1. August 2025", title: "Urlaub", due: "2025-08-01T00:00:00Z", allDay: true, locale: German},
+		{text: "Zahnarzt übermorgen 9:30", title: "Zahnarzt", due: "2024-03-15T09:30:00Z", locale: German},
+		{text: "Sprint nächste Woche", title: "Sprint", due: "2024-03-18T00:00:00Z", allDay: true, locale: German},
+		{text: "Meeting nächsten Freitag um 14.30 Uhr", title: "Meeting", due: "2024-03-15T14:30:00Z", locale: German},
+		{text: "Wäsche in zwei Tagen", title: "Wäsche", due: "2024-03-15T00:00:00Z", allDay: true, locale: German},
+		{text: "Termin 5 am Montag", title: "Termin 5", due: "2024-03-18T00:00:00Z", allDay: true, locale: German},
+		{text: "Version 1.5 testen", title: "Version 1.5 testen", locale: German},
+		{text: "Kochen heute Abend", title: "Kochen", due: "2024-03-13T20:00:00Z", locale: German},
+		{text: "Essen zu Mittag", title: "Essen", due: "2024-03-13T12:00:00Z", locale: German},
+
+		// French
+		{text: "Appeler Bob demain à 15h !haute #ventes", title: "Appeler Bob", due: "2024-03-14T15:00:00Z", priority: 'A', tags: []string{"ventes"}, locale: French},
+		{text: "Réunion le 5 janvier", title: "Réunion", due: "2025-01-05T00:00:00Z", allDay: true, locale: French},
+		{text: "Dossier après-demain 9h30", title: "Dossier", due: "2024-03-15T09:30:00Z", locale: French},
+		{text: "Rapport vendredi prochain", title: "Rapport", due: "2024-03-15T00:00:00Z", allDay: true, locale: French},
+		{text: "Rendez-vous le 1er avril à 10 h", title: "Rendez-vous", due: "2024-04-01T10:00:00Z", locale: French},
+		{text: "Payer dans deux semaines", title: "Payer", due: "2024-03-27T00:00:00Z", allDay: true, locale: French},
+		{text: "Finir aujourd’hui", title: "Finir", due: "2024-03-13T00:00:00Z", allDay: true, locale: French},
+		{text: "Facture 15/4 !basse", title: "Facture", due: "2024-04-15T00:00:00Z", allDay: true, priority: 'C', locale: French},
+		{text: "Budget le mois prochain", title: "Budget", due: "2024-04-01T00:00:00Z", allDay: true, locale: French},
+		{text: "Vacances dans sept jours", title: "Vacances", due: "2024-03-20T00:00:00Z", allDay: true, locale: French},
+	}
+
+	for _, tt := range tests {
+		name := tt.text
+		if tt.locale != nil {
+			name = tt.locale.Tag + ": " + name
+		}
+		if tt.location != nil {
+			name += " (" + tt.location.String() + ")"
+		}
+		t.Run(name, func(t *testing.T) {
+			reference := now
+			if !tt.now.IsZero() {
+				reference = tt.now
+			}
+
+			result := Parse(tt.text, Options{Now: reference, Location: tt.location, Locale: tt.locale})
+
+			assert.Equal(t, tt.title, result.Title)
+			if tt.due == "" {
+				assert.Nil(t, result.DueAt)
+			} else if assert.NotNil(t, result.DueAt) {
+				assert.Equal(t, tt.due, result.DueAt.Format(time.RFC3339))
+			}
+			assert.Equal(t, tt.allDay, result.AllDay)
+			assert.Equal(t, tt.priority, result.Priority)
+			assert.Equal(t, tt.tags, result.Tags)
+		})
+	}
+}
+
+func TestParse_Matches(t *testing.T) {
+	result := Parse("Call Bob tomorrow at 3pm !high #sales", Options{Now: now})
+
+	assert.Equal(t, []Match{
+		{Kind: MatchDate, Text: "tomorrow"},
+		{Kind: MatchTime, Text: "at 3pm"},
+		{Kind: MatchPriority, Text: "!high"},
+		{Kind: MatchTag, Text: "#sales"},
+	}, result.Matches)
+}
+
+func TestParse_IsDeterministic(t *testing.T) {
+	text := "Plan le mois prochain à 9h !haute #a #b"
+	first := Parse(text, Options{Now: now, Locale: French})
+
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, first, Parse(text, Options{Now: now, Locale: French}))
+	}
+}
+
+func TestLookupLocale(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want *Locale
+	}{
+		{"en", English},
+		{"en-US", English},
+		{"EN_gb", BritishEnglish},
+		{"en-CA", English},
+		{"de-AT", German},
+		{"fr-CH", French},
+	}
+	for _, tt := range tests {
+		locale, ok := LookupLocale(tt.tag)
+		assert.True(t, ok, tt.tag)
+		assert.Same(t, tt.want, locale, tt.tag)
+	}
+
+	_, ok := LookupLocale("pt-BR")
+	assert.False(t, ok)
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Same(t, English, Negotiate("pt-BR, de;q=0.8, en;q=0.9"))
+	assert.Same(t, German, Negotiate("fr-CH;q=0.5, de-DE;q=0.7"))
+	assert.Same(t, BritishEnglish, Negotiate("en-GB,en;q=0.9"))
+	assert.Nil(t, Negotiate("pt, *"))
+	assert.Nil(t, Negotiate(""))
+	assert.Nil(t, Negotiate("de;q=0"))
+}
@@ -137,6 +137,12 @@ func (v *Validator) ValidateStruct(s interface{}) error {
 
 // ValidateVar validates a single variable
 func (v *Validator) ValidateVar(field interface{}, tag string) error {
+	return v.ValidateNamedVar("field", field, tag)
+}
+
+// ValidateNamedVar validates a single variable, such as a user-defined field
+// value, using name for the field in the returned ValidationError
+func (v *Validator) ValidateNamedVar(name string, field interface{}, tag string) error {
 	err := v.validate.Var(field, tag)
 	if err == nil {
 		return nil
@@ -145,10 +151,10 @@ func (v *Validator) ValidateVar(field interface{}, tag string) error {
 	if validatorErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, fieldError := range validatorErrors {
 			return ValidationError{
-				Field:   "field",
+				Field:   name,
 				Tag:     fieldError.Tag(),
 				Value:   fmt.Sprintf("%v", fieldError.Value()),
-				Message: v.getErrorMessage(fieldError),
+				Message: v.errorMessage(name, fieldError),
 			}
 		}
 	}
@@ -158,7 +164,11 @@ func (v *Validator) ValidateVar(field interface{}, tag string) error {
 
 // getErrorMessage returns a user-friendly error message for validation errors
 func (v *Validator) getErrorMessage(fe validator.FieldError) string {
-	field := fe.Field()
+	return v.errorMessage(fe.Field(), fe)
+}
+
+// errorMessage returns a user-friendly error message for a validation error of the named field
+func (v *Validator) errorMessage(field string, fe validator.FieldError) string {
 	tag := fe.Tag()
 	param := fe.Param()
 	
@@ -168,9 +178,17 @@ func (v *Validator) getErrorMessage(fe validator.FieldError) string {
 	case "email":
 		return fmt.Sprintf("%s must be a valid email address", field)
 	case "min":
+		if isNumber(fe.Kind()) {
+			return fmt.Sprintf("%s must be at least %s", field, param)
+		}
 		return fmt.Sprintf("%s must be at least %s characters long", field, param)
 	case "max":
+		if isNumber(fe.Kind()) {
+			return fmt.Sprintf("%s must be at most %s", field, param)
+		}
 		return fmt.Sprintf("%s must be at most %s characters long", field, param)
+	case "datetime":
+		return fmt.Sprintf("%s must be a date in the format %s", field, param)
 	case "len":
 		return fmt.Sprintf("%s must be exactly %s characters long", field, param)
 	case "password":
@@ -194,6 +212,18 @@ func (v *Validator) getErrorMessage(fe validator.FieldError) string {
 	}
 }
 
+// isNumber reports whether a value of the kind is compared by value rather than by length
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetValidator returns the underlying validator instance for advanced usage
 func (v *Validator) GetValidator() *validator.Validate {
 	return v.validate
@@ -212,6 +242,11 @@ func ValidateVar(field interface{}, tag string) error {
 	return globalValidator.ValidateVar(field, tag)
 }
 
+// ValidateNamedVar validates a named variable using the global validator
+func ValidateNamedVar(name string, field interface{}, tag string) error {
+	return globalValidator.ValidateNamedVar(name, field, tag)
+}
+
 // FormatValidationErrors formats validation errors for API responses
 func FormatValidationErrors(err error) map[string]string {
 	errors := make(map[string]string)
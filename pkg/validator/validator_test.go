@@ -358,6 +358,57 @@ func TestGlobalValidatorFunctions(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestValidator_ValidateNamedVar(t *testing.T) {
+	validator := New()
+	
+	tests := []struct {
+		name    string
+		value   interface{}
+		tag     string
+		message string
+	}{
+		{
+			name:    "number below minimum",
+			value:   0.5,
+			tag:     "min=1,max=13",
+			message: "points must be at least 1",
+		},
+		{
+			name:    "number above maximum",
+			value:   21.0,
+			tag:     "min=1,max=13",
+			message: "points must be at most 13",
+		},
+		{
+			name:    "text too long",
+			value:   "abcdef",
+			tag:     "max=5",
+			message: "points must be at most 5 characters long",
+		},
+		{
+			name:    "invalid date",
+			value:   "2024-13-01",
+			tag:     "datetime=2006-01-02",
+			message: "points must be a date in the format 2006-01-02",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateNamedVar("points", tt.value, tt.tag)
+			require.Error(t, err)
+			
+			validationError, ok := err.(ValidationError)
+			require.True(t, ok)
+			assert.Equal(t, "points", validationError.Field)
+			assert.Equal(t, tt.message, validationError.Message)
+		})
+	}
+	
+	assert.NoError(t, validator.ValidateNamedVar("points", 8.0, "min=1,max=13"))
+	assert.NoError(t, ValidateNamedVar("due", "2024-02-29", "datetime=2006-01-02"))
+}
+
 func TestFormatValidationErrors(t *testing.T) {
 	t.Run("ValidationErrors type", func(t *testing.T) {
 		validationErrors := ValidationErrors{
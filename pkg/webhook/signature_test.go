@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign_IsDeterministic(t *testing.T) {
+	body := []byte(`{"type":"todo.completed"}`)
+
+	signature := Sign("secret", 1704110400, body)
+
+	assert.Equal(t, signature, Sign("secret", 1704110400, body))
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.NotEqual(t, signature, Sign("other", 1704110400, body))
+	assert.NotEqual(t, signature, Sign("secret", 1704110401, body))
+	assert.NotEqual(t, signature, Sign("secret", 1704110400, []byte(`{}`)))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1704110400, 0)
+	body := []byte(`{"type":"todo.completed"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("secret", now.Unix(), body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		now       time.Time
+		expected  error
+	}{
+		{"valid signature", "secret", signature, timestamp, body, now, nil},
+		{"wrong secret", "other", signature, timestamp, body, now, ErrInvalidSignature},
+		{"tampered body", "secret", signature, timestamp, []byte(`{}`), now, ErrInvalidSignature},
+		{"missing prefix", "secret", signature[len("sha256="):], timestamp, body, now, ErrInvalidSignature},
+		{"invalid timestamp", "secret", signature, "yesterday", body, now, ErrInvalidTimestamp},
+		{"expired timestamp", "secret", signature, timestamp, body, now.Add(10 * time.Minute), ErrExpiredTimestamp},
+		{"future timestamp", "secret", signature, timestamp, body, now.Add(-10 * time.Minute), ErrExpiredTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.signature, tt.timestamp, tt.body, 5*time.Minute, tt.now)
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestVerify_WithoutTolerance(t *testing.T) {
+	body := []byte(`{}`)
+	signature := Sign("secret", 1, body)
+
+	assert.NoError(t, Verify("secret", signature, "1", body, 0, time.Now()))
+}
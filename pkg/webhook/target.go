@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+var (
+	ErrInvalidURL      = errors.New("webhook url must be an absolute http or https url")
+	ErrForbiddenTarget = errors.New("webhook target address not allowed")
+)
+
+// forbiddenPrefixes are ranges that are internal but not covered by the
+// netip.Addr predicates: "this network" and shared carrier-grade NAT space,
+// which some clouds use for their metadata services
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Resolver looks up the addresses of a host; *net.Resolver implements it
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// AllowedAddr reports whether webhooks may be delivered to addr. Loopback,
+// private, link-local, unspecified and multicast addresses are refused, so
+// webhooks cannot be used to reach the internal network of the server.
+func AllowedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL returns an error unless raw is an absolute http or https URL whose
+// host resolves only to allowed addresses
+func CheckURL(ctx context.Context, resolver Resolver, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+
+	host := u.Hostname()
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		addrs, err = resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil || len(addrs) == 0 {
+			return fmt.Errorf("%w: cannot resolve %s", ErrInvalidURL, host)
+		}
+	}
+
+	for _, addr := range addrs {
+		if !AllowedAddr(addr) {
+			return fmt.Errorf("%w: %s", ErrForbiddenTarget, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// DialContext returns a dial function for an http.Transport that refuses to
+// connect to addresses that are not allowed. The check runs on the address
+// each connection is actually made to, so a host that resolves to an internal
+// address after its URL was checked is still refused.
+func DialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	guarded := *dialer
+	guarded.Control = func(network, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return err
+		}
+		if !AllowedAddr(addrPort.Addr()) {
+			return fmt.Errorf("%w: %s", ErrForbiddenTarget, addrPort.Addr().Unmap())
+		}
+		return nil
+	}
+	return guarded.DialContext
+}
@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticResolver resolves every host to the same addresses
+type staticResolver []netip.Addr
+
+func (r staticResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	if len(r) == 0 {
+		return nil, errors.New("no such host")
+	}
+	return r, nil
+}
+
+func TestAllowedAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"ff02::1", false},
+		{"100.100.100.200", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, AllowedAddr(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	public := staticResolver{netip.MustParseAddr("93.184.216.34")}
+	internal := staticResolver{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")}
+
+	tests := []struct {
+		name     string
+		resolver Resolver
+		url      string
+		expected error
+	}{
+		{"public host", public, "https://hooks.example.com/todo", nil},
+		{"public address", public, "http://93.184.216.34:8080/hook", nil},
+		{"loopback", public, "http://127.0.0.1:6379/", ErrForbiddenTarget},
+		{"loopback IPv6", public, "http://[::1]/", ErrForbiddenTarget},
+		{"cloud metadata", public, "http://169.254.169.254/latest/meta-data/", ErrForbiddenTarget},
+		{"host resolving to a private address", internal, "https://intranet.example.com/hook", ErrForbiddenTarget},
+		{"unresolvable host", staticResolver{}, "https://missing.example.com/hook", ErrInvalidURL},
+		{"other scheme", public, "ftp://example.com/hook", ErrInvalidURL},
+		{"relative", public, "/relative", ErrInvalidURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckURL(context.Background(), tt.resolver, tt.url)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDialContext_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the loopback server")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{DialContext: DialContext(&net.Dialer{})}}
+	resp, err := client.Post(server.URL, "application/json", nil)
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrForbiddenTarget)
+}
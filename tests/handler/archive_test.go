@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func TestGetTodos_ArchivedFilter(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("List", mock.Anything, uint(1), &model.TodoFilter{Archived: model.ArchivedOnly}).Return([]*model.Todo{}, nil)
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos?archived=true", nil)
+
+	h.GetTodos(c)
+
+	assert.Equal(t, http.StatusOK, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestGetTodos_InvalidArchivedFilter(t *testing.T) {
+	h, _, _ := setupTestHandler()
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos?archived=maybe", nil)
+
+	h.GetTodos(c)
+
+	assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+}
+
+func TestArchiveTodo_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	now := time.Now()
+	archived := &model.Todo{ID: 1, Title: "Done", UserID: 1, ArchivedAt: &now}
+	mockTodoService.On("Archive", mock.Anything, uint(1), uint(1)).Return(archived, nil)
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/1/archive", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.ArchiveTodo(c)
+
+	assert.Equal(t, http.StatusOK, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestUnarchiveTodo_NotFound(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Unarchive", mock.Anything, uint(1), uint(1)).Return(nil, errors.New("todo not found"))
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/1/unarchive", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.UnarchiveTodo(c)
+
+	assert.Equal(t, http.StatusNotFound, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestArchiveTodo_PreconditionFailed(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Archive", mock.Anything, uint(1), uint(1)).Return(nil, service.ErrPreconditionFailed)
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/1/archive", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.ArchiveTodo(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, c.Writer.Status())
+}
+
+func TestArchiveCompletedTodos_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("ArchiveCompleted", mock.Anything, uint(1), 30).Return(int64(5), nil)
+
+	body, _ := json.Marshal(model.ArchiveCompletedRequest{OlderThanDays: 30})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/archive", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.ArchiveCompletedTodos(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.ArchiveResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(5), response.Archived)
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestArchiveCompletedTodos_ValidationError(t *testing.T) {
+	h, _, _ := setupTestHandler()
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/archive", bytes.NewBufferString(`{"older_than_days": -1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.ArchiveCompletedTodos(c)
+
+	assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+}
+
+func TestUpdateArchivePolicy_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	req := &model.ArchivePolicyRequest{AutoArchiveDays: 14}
+	mockTodoService.On("UpdateArchivePolicy", mock.Anything, uint(1), req).
+		Return(&model.ArchivePolicyResponse{AutoArchiveDays: 14, Enabled: true}, nil)
+
+	body, _ := json.Marshal(req)
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPut, "/todos/archive/policy", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.UpdateArchivePolicy(c)
+
+	assert.Equal(t, http.StatusOK, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockAttachmentService is a mock implementation of AttachmentService
+type MockAttachmentService struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentService) MaxSize() int64 {
+	return m.Called().Get(0).(int64)
+}
+
+func (m *MockAttachmentService) Upload(ctx context.Context, todoID uint, userID uint, filename string, r io.Reader, size int64) (*model.Attachment, error) {
+	data, _ := io.ReadAll(r)
+	args := m.Called(ctx, todoID, userID, filename, data, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) List(ctx context.Context, todoID uint, userID uint) (*model.AttachmentListResponse, error) {
+	args := m.Called(ctx, todoID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AttachmentListResponse), args.Error(1)
+}
+
+func (m *MockAttachmentService) Get(ctx context.Context, todoID uint, id uint, userID uint) (*model.Attachment, error) {
+	args := m.Called(ctx, todoID, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) Delete(ctx context.Context, todoID uint, id uint, userID uint) error {
+	return m.Called(ctx, todoID, id, userID).Error(0)
+}
+
+func (m *MockAttachmentService) Open(ctx context.Context, id uint, expires int64, signature string) (*model.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, id, expires, signature)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*model.Attachment), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func setupAttachmentTestHandler() (*handler.Handler, *MockAttachmentService) {
+	gin.SetMode(gin.TestMode)
+	mockAttachmentService := &MockAttachmentService{}
+	mockAttachmentService.On("MaxSize").Return(int64(1 << 20)).Maybe()
+	h := handler.NewHandler(&service.Services{Attachment: mockAttachmentService})
+	return h, mockAttachmentService
+}
+
+// performAttachmentRequest calls an attachment handler for attachment 5 of todo 7
+func performAttachmentRequest(req *http.Request, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "7"}, {Key: "attachment_id", Value: "5"}}
+	c.Set("user_id", uint(1))
+
+	handle(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+// performDownloadRequest calls the download handler for attachment 5
+func performDownloadRequest(query string, h *handler.Handler) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/attachments/5/download"+query, nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+	h.DownloadAttachment(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+// uploadRequest builds a multipart upload of content in the given form field
+func uploadRequest(t *testing.T, field, filename string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, filename)
+	require.NoError(t, err)
+	part.Write(content)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/7/attachments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUploadTodoAttachment_Success(t *testing.T) {
+	h, mockAttachmentService := setupAttachmentTestHandler()
+	content := []byte("\x89PNG\r\n\x1a\nimage")
+
+	mockAttachmentService.On("Upload", mock.Anything, uint(7), uint(1), "login-error.png", content, int64(len(content))).Return(&model.Attachment{
+		ID:          5,
+		TodoID:      7,
+		UserID:      1,
+		Filename:    "login-error.png",
+		ContentType: "image/png",
+		Size:        int64(len(content)),
+		StorageKey:  "attachments/1/abc",
+		DownloadURL: "/api/v1/attachments/5/download?expires=1704114000&signature=ab",
+	}, nil)
+
+	w := performAttachmentRequest(uploadRequest(t, "file", "login-error.png", content), h.UploadTodoAttachment)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var attachment model.Attachment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &attachment))
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.NotContains(t, w.Body.String(), "attachments/1/abc")
+	mockAttachmentService.AssertExpectations(t)
+}
+
+func TestUploadTodoAttachment_MissingFile(t *testing.T) {
+	h, mockAttachmentService := setupAttachmentTestHandler()
+
+	w := performAttachmentRequest(uploadRequest(t, "upload", "a.png", []byte("data")), h.UploadTodoAttachment)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "A multipart file field named file is required")
+	mockAttachmentService.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadTodoAttachment_BodyTooLarge(t *testing.T) {
+	h, mockAttachmentService := setupAttachmentTestHandler()
+
+	w := performAttachmentRequest(uploadRequest(t, "file", "huge.png", bytes.Repeat([]byte{0}, 2<<20)), h.UploadTodoAttachment)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "Attachments may be at most 1 MB")
+	mockAttachmentService.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadTodoAttachment_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"todo not found", service.ErrTodoNotFound, http.StatusNotFound, "not_found"},
+		{"too large", service.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, "attachment_too_large"},
+		{"empty", service.ErrEmptyAttachment, http.StatusBadRequest, "validation_failed"},
+		{"type not allowed", service.ErrUnsupportedAttachmentType, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"quota exceeded", service.ErrAttachmentQuotaExceeded, http.StatusConflict, "quota_exceeded"},
+		{"unavailable", service.ErrAttachmentsUnavailable, http.StatusServiceUnavailable, "attachments_unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockAttachmentService := setupAttachmentTestHandler()
+			mockAttachmentService.On("Upload", mock.Anything, uint(7), uint(1), "a.png", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := performAttachmentRequest(uploadRequest(t, "file", "a.png", []byte("data")), h.UploadTodoAttachment)
+
+			assert.Equal(t, tt.status, w.Code)
+			var response model.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Error)
+		})
+	}
+}
+
+func TestGetTodoAttachments_Success(t *testing.T) {
+	h, mockAttachmentService := setupAttachmentTestHandler()
+
+	mockAttachmentService.On("List", mock.Anything, uint(7), uint(1)).Return(&model.AttachmentListResponse{
+		Attachments: []*model.Attachment{{ID: 5, TodoID: 7, Filename: "a.png"}},
+		Count:       1,
+		UsedBytes:   1024,
+		QuotaBytes:  100 << 20,
+	}, nil)
+
+	w := performAttachmentRequest(httptest.NewRequest(http.MethodGet, "/todos/7/attachments", nil), h.GetTodoAttachments)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"used_bytes":1024`)
+	mockAttachmentService.AssertExpectations(t)
+}
+
+func TestDeleteTodoAttachment(t *testing.T) {
+	h, mockAttachmentService := setupAttachmentTestHandler()
+
+	mockAttachmentService.On("Delete", mock.Anything, uint(7), uint(5), uint(1)).Return(nil).Once()
+	mockAttachmentService.On("Delete", mock.Anything, uint(7), uint(5), uint(1)).Return(service.ErrAttachmentNotFound).Once()
+
+	w := performAttachmentRequest(httptest.NewRequest(http.MethodDelete, "/todos/7/attachments/5", nil), h.DeleteTodoAttachment)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = performAttachmentRequest(httptest.NewRequest(http.MethodDelete, "/todos/7/attachments/5", nil), h.DeleteTodoAttachment)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Attachment not found")
+}
+
+func TestDownloadAttachment_Success(t *testing.T) {
+	h, mockAttachmentService := setupAttachmentTestHandler()
+
+	mockAttachmentService.On("Open", mock.Anything, uint(5), int64(1704114000), "abcd").Return(&model.Attachment{
+		ID:          5,
+		Filename:    "Überblick.pdf",
+		ContentType: "application/pdf",
+		Size:        8,
+	}, io.NopCloser(strings.NewReader("%PDF-1.7")), nil)
+
+	w := performDownloadRequest("?expires=1704114000&signature=abcd", h)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.7", w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, "8", w.Header().Get("Content-Length"))
+	assert.Equal(t, "attachment; filename*=utf-8''%C3%9Cberblick.pdf", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+}
+
+func TestDownloadAttachment_Errors(t *testing.T) {
+	h, mockAttachmentService := setupAttachmentTestHandler()
+
+	mockAttachmentService.On("Open", mock.Anything, uint(5), int64(1704114000), "bad").Return(nil, nil, service.ErrInvalidDownloadSignature)
+	mockAttachmentService.On("Open", mock.Anything, uint(5), int64(1704114000), "old").Return(nil, nil, service.ErrDownloadExpired)
+
+	w := performDownloadRequest("?expires=1704114000&signature=bad", h)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_signature")
+
+	w = performDownloadRequest("?expires=1704114000&signature=old", h)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "link_expired")
+
+	w = performDownloadRequest("?signature=bad", h)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
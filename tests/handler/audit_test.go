@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/audit"
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockAuditService is a mock implementation of AuditService
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) Record(ctx context.Context, event *model.SecurityEvent) {
+	m.Called(ctx, event)
+}
+
+func (m *MockAuditService) Query(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.SecurityEvent), args.Error(1)
+}
+
+func setupAuditTestHandler() (*handler.Handler, *MockAuditService) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuditService := &MockAuditService{}
+	services := &service.Services{
+		Auth:  &MockAuthService{},
+		Todo:  &MockTodoService{},
+		Audit: mockAuditService,
+	}
+
+	return handler.NewHandler(services), mockAuditService
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	h, mockAuthService, _ := setupTestHandler()
+
+	reqBody := model.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword456"}
+	mockAuthService.On("ChangePassword", mock.MatchedBy(func(ctx context.Context) bool {
+		client, ok := audit.ClientFromContext(ctx)
+		return ok && client.UserAgent == "test-agent"
+	}), uint(1), &reqBody).Return(nil)
+
+	body, _ := json.Marshal(reqBody)
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPut, "/auth/password", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("User-Agent", "test-agent")
+
+	h.ChangePassword(c)
+
+	assert.Equal(t, http.StatusOK, c.Writer.Status())
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestChangePassword_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedError  string
+	}{
+		{"incorrect current password", errors.New("current password is incorrect"), http.StatusBadRequest, "incorrect_password"},
+		{"weak new password", errors.New("password validation failed: password must contain at least one letter"), http.StatusBadRequest, "weak_password"},
+		{"user not found", errors.New("user not found"), http.StatusNotFound, "not_found"},
+		{"internal error", errors.New("database error"), http.StatusInternalServerError, "password_change_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockAuthService, _ := setupTestHandler()
+
+			reqBody := model.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword456"}
+			mockAuthService.On("ChangePassword", mock.Anything, uint(1), &reqBody).Return(tt.serviceErr)
+
+			body, _ := json.Marshal(reqBody)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", uint(1))
+			c.Request = httptest.NewRequest(http.MethodPut, "/auth/password", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.ChangePassword(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response model.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedError, response.Error)
+		})
+	}
+}
+
+func TestChangePassword_ValidationFailed(t *testing.T) {
+	h, _, _ := setupTestHandler()
+
+	body, _ := json.Marshal(map[string]string{"current_password": "password123", "new_password": "short"})
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPut, "/auth/password", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.ChangePassword(c)
+
+	assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+}
+
+func TestGetSecurityEvents_Success(t *testing.T) {
+	h, mockAuditService := setupAuditTestHandler()
+
+	userID := uint(7)
+	events := []*model.SecurityEvent{
+		{ID: 1, EventType: model.SecurityEventLogin, Outcome: model.SecurityOutcomeFailure, UserID: &userID, Reason: "invalid_password"},
+	}
+	mockAuditService.On("Query", mock.Anything, mock.MatchedBy(func(f *model.SecurityEventFilter) bool {
+		return f.UserID != nil && *f.UserID == 7 &&
+			f.EventType == model.SecurityEventLogin &&
+			f.Outcome == model.SecurityOutcomeFailure &&
+			f.From != nil && f.To != nil && f.Limit == 50
+	})).Return(events, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet,
+		"/admin/audit-log?user_id=7&event=login&outcome=failure&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&limit=50", nil)
+
+	h.GetSecurityEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.SecurityEventsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "invalid_password", response.Events[0].Reason)
+
+	mockAuditService.AssertExpectations(t)
+}
+
+func TestGetSecurityEvents_InvalidFilter(t *testing.T) {
+	queries := []string{
+		"user_id=abc",
+		"event=logout",
+		"outcome=maybe",
+		"from=yesterday",
+		"to=2024-13-01",
+		"from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+		"limit=0",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			h, mockAuditService := setupAuditTestHandler()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/admin/audit-log?%s", query), nil)
+
+			h.GetSecurityEvents(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockAuditService.AssertNotCalled(t, "Query", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetSecurityEvents_ServiceError(t *testing.T) {
+	h, mockAuditService := setupAuditTestHandler()
+
+	mockAuditService.On("Query", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/audit-log", nil)
+
+	h.GetSecurityEvents(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -48,6 +48,11 @@ func (m *MockAuthService) ValidateToken(tokenString string) (*jwt.Claims, error)
 	return args.Get(0).(*jwt.Claims), args.Error(1)
 }
 
+func (m *MockAuthService) ChangePassword(ctx context.Context, userID uint, req *model.ChangePasswordRequest) error {
+	args := m.Called(ctx, userID, req)
+	return args.Error(0)
+}
+
 // MockTodoService is a mock implementation of TodoService
 type MockTodoService struct {
 	mock.Mock
@@ -77,6 +82,14 @@ func (m *MockTodoService) GetByUserID(ctx context.Context, userID uint) ([]*mode
 	return args.Get(0).([]*model.Todo), args.Error(1)
 }
 
+func (m *MockTodoService) List(ctx context.Context, userID uint, filter *model.TodoFilter) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
 func (m *MockTodoService) Update(ctx context.Context, id uint, req *model.UpdateTodoRequest, userID uint) (*model.Todo, error) {
 	args := m.Called(ctx, id, req, userID)
 	if args.Get(0) == nil {
@@ -85,35 +98,255 @@ func (m *MockTodoService) Update(ctx context.Context, id uint, req *model.Update
 	return args.Get(0).(*model.Todo), args.Error(1)
 }
 
+func (m *MockTodoService) Replace(ctx context.Context, id uint, req *model.ReplaceTodoRequest, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoService) Patch(ctx context.Context, id uint, patchType string, patch []byte, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, patchType, patch, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
 func (m *MockTodoService) Delete(ctx context.Context, id uint, userID uint) error {
 	args := m.Called(ctx, id, userID)
 	return args.Error(0)
 }
 
+func (m *MockTodoService) GetTrash(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoService) Restore(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoService) Purge(ctx context.Context, id uint, userID uint) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTodoService) EmptyTrash(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTodoService) Archive(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoService) Unarchive(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoService) ArchiveCompleted(ctx context.Context, userID uint, olderThanDays int) (int64, error) {
+	args := m.Called(ctx, userID, olderThanDays)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTodoService) GetArchivePolicy(ctx context.Context, userID uint) (*model.ArchivePolicyResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ArchivePolicyResponse), args.Error(1)
+}
+
+func (m *MockTodoService) UpdateArchivePolicy(ctx context.Context, userID uint, req *model.ArchivePolicyRequest) (*model.ArchivePolicyResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ArchivePolicyResponse), args.Error(1)
+}
+
+func (m *MockTodoService) GetHistory(ctx context.Context, id uint, userID uint) ([]*model.TodoHistory, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoHistory), args.Error(1)
+}
+
+func (m *MockTodoService) Search(ctx context.Context, userID uint, req *model.SearchTodosRequest) ([]*model.TodoSearchResult, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoSearchResult), args.Error(1)
+}
+
+func (m *MockTodoService) Bulk(ctx context.Context, userID uint, req *model.BulkRequest) (*model.BulkResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BulkResponse), args.Error(1)
+}
+
+func (m *MockTodoService) PullChanges(ctx context.Context, userID uint, since string) (*model.SyncResponse, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SyncResponse), args.Error(1)
+}
+
+func (m *MockTodoService) PushChanges(ctx context.Context, userID uint, req *model.SyncPushRequest) (*model.SyncPushResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SyncPushResponse), args.Error(1)
+}
+
+// Export passes the mocked todos to fn before returning the mocked error
+func (m *MockTodoService) Export(ctx context.Context, userID uint, filter *model.TodoFilter, fn func(*model.Todo) error) error {
+	args := m.Called(ctx, userID, filter)
+	if todos, ok := args.Get(0).([]*model.Todo); ok {
+		for _, todo := range todos {
+			if err := fn(todo); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTodoService) Import(ctx context.Context, userID uint, req *model.ImportRequest) (*model.ImportResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ImportResponse), args.Error(1)
+}
+
+func (m *MockTodoService) QuickAdd(ctx context.Context, userID uint, req *model.QuickAddRequest) (*model.QuickAddResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.QuickAddResponse), args.Error(1)
+}
+
+func (m *MockTodoService) GetDependencies(ctx context.Context, id uint, userID uint) (*model.TodoDependenciesResponse, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoDependenciesResponse), args.Error(1)
+}
+
+func (m *MockTodoService) AddDependency(ctx context.Context, id uint, req *model.AddDependencyRequest, userID uint) (*model.TodoDependency, error) {
+	args := m.Called(ctx, id, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoDependency), args.Error(1)
+}
+
+func (m *MockTodoService) RemoveDependency(ctx context.Context, id uint, otherID uint, userID uint) error {
+	return m.Called(ctx, id, otherID, userID).Error(0)
+}
+
+func (m *MockTodoService) GetDependencyGraph(ctx context.Context, userID uint) (*model.DependencyGraphResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DependencyGraphResponse), args.Error(1)
+}
+
+func (m *MockTodoService) MoveTodo(ctx context.Context, id uint, req *model.MoveTodoRequest, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoService) GetBoard(ctx context.Context, listID uint, userID uint) (*model.BoardResponse, error) {
+	args := m.Called(ctx, listID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BoardResponse), args.Error(1)
+}
+
+func (m *MockTodoService) ListComments(ctx context.Context, id uint, userID uint, page, perPage int) (*model.CommentPage, error) {
+	args := m.Called(ctx, id, userID, page, perPage)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CommentPage), args.Error(1)
+}
+
+func (m *MockTodoService) AddComment(ctx context.Context, id uint, req *model.CreateCommentRequest, userID uint) (*model.TodoComment, error) {
+	args := m.Called(ctx, id, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoComment), args.Error(1)
+}
+
+func (m *MockTodoService) UpdateComment(ctx context.Context, id uint, commentID uint, req *model.UpdateCommentRequest, userID uint) (*model.TodoComment, error) {
+	args := m.Called(ctx, id, commentID, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoComment), args.Error(1)
+}
+
+func (m *MockTodoService) DeleteComment(ctx context.Context, id uint, commentID uint, userID uint) error {
+	return m.Called(ctx, id, commentID, userID).Error(0)
+}
+
 func setupTestHandler() (*handler.Handler, *MockAuthService, *MockTodoService) {
 	gin.SetMode(gin.TestMode)
-	
+
 	mockAuthService := &MockAuthService{}
 	mockTodoService := &MockTodoService{}
-	
+
 	services := &service.Services{
 		Auth: mockAuthService,
 		Todo: mockTodoService,
 	}
-	
+
 	h := handler.NewHandler(services)
 	return h, mockAuthService, mockTodoService
 }
 
 func TestRegister_Success(t *testing.T) {
 	h, mockAuthService, _ := setupTestHandler()
-	
+
 	// Setup request
 	reqBody := model.RegisterRequest{
 		Email:    "test@example.com",
 		Password: "password123",
 	}
-	
+
 	expectedResponse := &model.AuthResponse{
 		Token: "jwt-token",
 		User: &model.UserInfo{
@@ -121,53 +354,53 @@ func TestRegister_Success(t *testing.T) {
 			Email: "test@example.com",
 		},
 	}
-	
+
 	// Setup mock
 	mockAuthService.On("Register", mock.Anything, &reqBody).Return(expectedResponse, nil)
-	
+
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Register(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusCreated, w.Code)
-	
+
 	var response model.AuthResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResponse.Token, response.Token)
 	assert.Equal(t, expectedResponse.User.Email, response.User.Email)
-	
+
 	mockAuthService.AssertExpectations(t)
 }
 
 func TestRegister_InvalidJSON(t *testing.T) {
 	h, _, _ := setupTestHandler()
-	
+
 	// Create request with invalid JSON
 	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Register(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	
+
 	var response model.ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
@@ -176,29 +409,29 @@ func TestRegister_InvalidJSON(t *testing.T) {
 
 func TestRegister_ValidationError(t *testing.T) {
 	h, _, _ := setupTestHandler()
-	
+
 	// Setup request with invalid data
 	reqBody := model.RegisterRequest{
 		Email:    "invalid-email",
 		Password: "123", // Too short
 	}
-	
+
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Register(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	
+
 	var response model.ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
@@ -208,85 +441,85 @@ func TestRegister_ValidationError(t *testing.T) {
 
 func TestRegister_EmailExists(t *testing.T) {
 	h, mockAuthService, _ := setupTestHandler()
-	
+
 	// Setup request
 	reqBody := model.RegisterRequest{
 		Email:    "test@example.com",
 		Password: "password123",
 	}
-	
+
 	// Setup mock to return email exists error
 	mockAuthService.On("Register", mock.Anything, &reqBody).Return(nil, errors.New("email already exists"))
-	
+
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Register(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusConflict, w.Code)
-	
+
 	var response model.ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "email_exists", response.Error)
-	
+
 	mockAuthService.AssertExpectations(t)
 }
 
 func TestRegister_ServiceError(t *testing.T) {
 	h, mockAuthService, _ := setupTestHandler()
-	
+
 	// Setup request
 	reqBody := model.RegisterRequest{
 		Email:    "test@example.com",
 		Password: "password123",
 	}
-	
+
 	// Setup mock to return generic error
 	mockAuthService.On("Register", mock.Anything, &reqBody).Return(nil, errors.New("database error"))
-	
+
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Register(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	
+
 	var response model.ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "registration_failed", response.Error)
-	
+
 	mockAuthService.AssertExpectations(t)
 }
 
 func TestLogin_Success(t *testing.T) {
 	h, mockAuthService, _ := setupTestHandler()
-	
+
 	// Setup request
 	reqBody := model.LoginRequest{
 		Email:    "test@example.com",
 		Password: "password123",
 	}
-	
+
 	expectedResponse := &model.AuthResponse{
 		Token: "jwt-token",
 		User: &model.UserInfo{
@@ -294,99 +527,99 @@ func TestLogin_Success(t *testing.T) {
 			Email: "test@example.com",
 		},
 	}
-	
+
 	// Setup mock
 	mockAuthService.On("Login", mock.Anything, &reqBody).Return(expectedResponse, nil)
-	
+
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Login(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusOK, w.Code)
-	
+
 	var response model.AuthResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResponse.Token, response.Token)
 	assert.Equal(t, expectedResponse.User.Email, response.User.Email)
-	
+
 	mockAuthService.AssertExpectations(t)
 }
 
 func TestLogin_InvalidCredentials(t *testing.T) {
 	h, mockAuthService, _ := setupTestHandler()
-	
+
 	// Setup request
 	reqBody := model.LoginRequest{
 		Email:    "test@example.com",
 		Password: "wrongpassword",
 	}
-	
+
 	// Setup mock to return invalid credentials error
 	mockAuthService.On("Login", mock.Anything, &reqBody).Return(nil, errors.New("invalid credentials"))
-	
+
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Login(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
-	
+
 	var response model.ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "invalid_credentials", response.Error)
-	
+
 	mockAuthService.AssertExpectations(t)
 }
 
 func TestLogin_ValidationError(t *testing.T) {
 	h, _, _ := setupTestHandler()
-	
+
 	// Setup request with invalid data
 	reqBody := model.LoginRequest{
 		Email:    "invalid-email",
 		Password: "", // Empty password
 	}
-	
+
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	// Create response recorder
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Call handler
 	h.Login(c)
-	
+
 	// Assertions
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	
+
 	var response model.ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "validation_failed", response.Error)
 	assert.NotEmpty(t, response.Details)
-}
\ No newline at end of file
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+)
+
+func performBulkRequest(h interface{ BulkTodos(*gin.Context) }, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/bulk", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.BulkTodos(c)
+	return w
+}
+
+func TestBulkTodos_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name           string
+		response       *model.BulkResponse
+		expectedStatus int
+	}{
+		{"all succeeded", &model.BulkResponse{Committed: true, Succeeded: 2}, http.StatusOK},
+		{"some failed", &model.BulkResponse{Committed: true, Succeeded: 1, Failed: 1}, http.StatusMultiStatus},
+		{"atomic rolled back", &model.BulkResponse{Atomic: true, Failed: 2}, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			reqBody := model.BulkRequest{
+				Operations: []model.BulkOperation{{Op: model.BulkOpComplete, IDs: []uint{1, 2}}},
+			}
+			mockTodoService.On("Bulk", mock.Anything, uint(1), &reqBody).Return(tt.response, nil)
+
+			body, _ := json.Marshal(reqBody)
+			w := performBulkRequest(h, body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response model.BulkResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.response.Succeeded, response.Succeeded)
+			mockTodoService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBulkTodos_ValidationFailed(t *testing.T) {
+	bodies := []string{
+		`{"operations": []}`,
+		`{"operations": [{"op": "archive", "id": 1}]}`,
+		`{"atomic": true}`,
+		`{"operations": `,
+	}
+
+	for _, body := range bodies {
+		t.Run(body, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			w := performBulkRequest(h, []byte(body))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockTodoService.AssertNotCalled(t, "Bulk", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBulkTodos_TooManyItems(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Bulk", mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("too many bulk items"))
+
+	w := performBulkRequest(h, []byte(`{"operations": [{"op": "delete", "id": 1}]}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBulkTodos_ServiceError(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Bulk", mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("database error"))
+
+	w := performBulkRequest(h, []byte(`{"operations": [{"op": "delete", "id": 1}]}`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/ical"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockCalDAVService is a mock implementation of CalDAVService
+type MockCalDAVService struct {
+	mock.Mock
+}
+
+func (m *MockCalDAVService) Authenticate(ctx context.Context, email, password string) (uint, error) {
+	args := m.Called(ctx, email, password)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockCalDAVService) CreatePassword(ctx context.Context, userID uint, req *model.CreateCalDAVPasswordRequest) (*model.CalDAVPasswordResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalDAVPasswordResponse), args.Error(1)
+}
+
+func (m *MockCalDAVService) ListPasswords(ctx context.Context, userID uint) ([]*model.CalDAVPasswordResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CalDAVPasswordResponse), args.Error(1)
+}
+
+func (m *MockCalDAVService) DeletePassword(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockCalDAVService) Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarStamp), args.Error(1)
+}
+
+func (m *MockCalDAVService) List(ctx context.Context, userID uint, fn func(*model.CalDAVObject) error) error {
+	return m.Called(ctx, userID, fn).Error(0)
+}
+
+func (m *MockCalDAVService) Get(ctx context.Context, userID uint, name string) (*model.CalDAVObject, error) {
+	args := m.Called(ctx, userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalDAVObject), args.Error(1)
+}
+
+func (m *MockCalDAVService) Put(ctx context.Context, userID uint, name string, data []byte, createOnly bool) (*model.CalDAVObject, bool, error) {
+	args := m.Called(ctx, userID, name, data, createOnly)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*model.CalDAVObject), args.Bool(1), args.Error(2)
+}
+
+func (m *MockCalDAVService) Delete(ctx context.Context, userID uint, name string) error {
+	return m.Called(ctx, userID, name).Error(0)
+}
+
+func setupCalDAVTestRouter() (*gin.Engine, *MockCalDAVService) {
+	gin.SetMode(gin.TestMode)
+
+	mockCalDAVService := &MockCalDAVService{}
+	h := handler.NewHandler(&service.Services{
+		Auth:   &MockAuthService{},
+		Todo:   &MockTodoService{},
+		CalDAV: mockCalDAVService,
+	})
+
+	router := gin.New()
+	h.RegisterCalDAVRoutes(router, func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Set("user_email", "test@example.com")
+	})
+	passwords := router.Group("/api/v1/caldav/passwords", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+	})
+	passwords.GET("", h.GetCalDAVPasswords)
+	passwords.POST("", h.CreateCalDAVPassword)
+	passwords.DELETE("/:id", h.DeleteCalDAVPassword)
+
+	return router, mockCalDAVService
+}
+
+func performCalDAVRequest(router *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func testCalDAVObject() *model.CalDAVObject {
+	updated := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return &model.CalDAVObject{
+		Name: "abc.ics",
+		UID:  "abc@phone",
+		Todo: &model.Todo{ID: 7, UserID: 1, Title: "Water plants", Version: 3, CreatedAt: updated, UpdatedAt: updated},
+	}
+}
+
+func expectCalDAVObjects(mockCalDAVService *MockCalDAVService, objects ...*model.CalDAVObject) {
+	mockCalDAVService.On("List", mock.Anything, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(2).(func(*model.CalDAVObject) error)
+		for _, object := range objects {
+			_ = fn(object)
+		}
+	}).Return(nil)
+}
+
+func TestCalDAVWellKnown(t *testing.T) {
+	router, _ := setupCalDAVTestRouter()
+
+	w := performCalDAVRequest(router, "PROPFIND", "/.well-known/caldav", "", nil)
+
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/caldav/", w.Header().Get("Location"))
+}
+
+func TestCalDAVOptions(t *testing.T) {
+	router, _ := setupCalDAVTestRouter()
+
+	w := performCalDAVRequest(router, "OPTIONS", "/caldav/todos/", "", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("DAV"), "calendar-access")
+	assert.Contains(t, w.Header().Get("Allow"), "REPORT")
+}
+
+func TestPropfindCalDAVHome(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+	mockCalDAVService.On("Stamp", mock.Anything, uint(1)).Return(&model.CalendarStamp{Count: 1}, nil)
+
+	w := performCalDAVRequest(router, "PROPFIND", "/caldav/", `<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:current-user-principal/><c:calendar-home-set/><d:quota-used-bytes/></d:prop>
+</d:propfind>`, map[string]string{"Depth": "1"})
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/xml")
+	body := w.Body.String()
+	assert.Contains(t, body, "<d:current-user-principal><d:href>/caldav/</d:href></d:current-user-principal>")
+	assert.Contains(t, body, "<cal:calendar-home-set><d:href>/caldav/</d:href></cal:calendar-home-set>")
+	// Unknown properties are reported as missing
+	assert.Contains(t, body, "<d:quota-used-bytes/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status>")
+	assert.Contains(t, body, "<d:href>/caldav/todos/</d:href>")
+}
+
+func TestPropfindCalDAVCollection(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+	mockCalDAVService.On("Stamp", mock.Anything, uint(1)).Return(&model.CalendarStamp{Count: 1, LastModified: time.Now()}, nil)
+	expectCalDAVObjects(mockCalDAVService, testCalDAVObject())
+
+	t.Run("depth 0", func(t *testing.T) {
+		w := performCalDAVRequest(router, "PROPFIND", "/caldav/todos/", "", map[string]string{"Depth": "0"})
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "<cal:calendar/>")
+		assert.Contains(t, body, `<cal:comp name="VTODO"/>`)
+		assert.Contains(t, body, "<cs:getctag>")
+		assert.NotContains(t, body, "abc.ics")
+	})
+
+	t.Run("depth 1", func(t *testing.T) {
+		w := performCalDAVRequest(router, "PROPFIND", "/caldav/todos/", `<d:propfind xmlns:d="DAV:"><d:prop><d:getetag/></d:prop></d:propfind>`, map[string]string{"Depth": "1"})
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "<d:href>/caldav/todos/abc.ics</d:href>")
+		assert.Contains(t, body, "<d:getetag>&#34;3&#34;</d:getetag>")
+	})
+}
+
+func TestReportCalDAVCollection_CalendarQuery(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+	done := testCalDAVObject()
+	done.Name = "done.ics"
+	done.Todo = &model.Todo{ID: 8, UserID: 1, Title: "Done", Completed: true}
+	expectCalDAVObjects(mockCalDAVService, testCalDAVObject(), done)
+
+	w := performCalDAVRequest(router, "REPORT", "/caldav/todos/", `<?xml version="1.0"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VTODO">
+        <c:prop-filter name="STATUS"><c:text-match negate-condition="yes">COMPLETED</c:text-match></c:prop-filter>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`, map[string]string{"Depth": "1"})
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "/caldav/todos/abc.ics")
+	assert.NotContains(t, body, "done.ics")
+	assert.Contains(t, body, "UID:abc@phone")
+	assert.Contains(t, body, "SUMMARY:Water plants")
+}
+
+func TestReportCalDAVCollection_CalendarMultiget(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+	mockCalDAVService.On("Get", mock.Anything, uint(1), "abc.ics").Return(testCalDAVObject(), nil)
+	mockCalDAVService.On("Get", mock.Anything, uint(1), "gone.ics").Return(nil, service.ErrCalDAVObjectNotFound)
+
+	w := performCalDAVRequest(router, "REPORT", "/caldav/todos/", `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/></d:prop>
+  <d:href>/caldav/todos/abc.ics</d:href>
+  <d:href>/caldav/todos/gone.ics</d:href>
+</c:calendar-multiget>`, nil)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<d:href>/caldav/todos/abc.ics</d:href><d:propstat>")
+	assert.Contains(t, body, "<d:href>/caldav/todos/gone.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>")
+}
+
+func TestReportCalDAVCollection_UnsupportedReport(t *testing.T) {
+	router, _ := setupCalDAVTestRouter()
+
+	w := performCalDAVRequest(router, "REPORT", "/caldav/todos/", `<d:sync-collection xmlns:d="DAV:"/>`, nil)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<d:supported-report/>")
+}
+
+func TestGetCalDAVObject(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+	mockCalDAVService.On("Get", mock.Anything, uint(1), "abc.ics").Return(testCalDAVObject(), nil)
+	mockCalDAVService.On("Get", mock.Anything, uint(1), "gone.ics").Return(nil, service.ErrCalDAVObjectNotFound)
+
+	t.Run("found", func(t *testing.T) {
+		w := performCalDAVRequest(router, "GET", "/caldav/todos/abc.ics", "", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/calendar")
+		assert.Contains(t, w.Body.String(), "UID:abc@phone\r\n")
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		w := performCalDAVRequest(router, "GET", "/caldav/todos/abc.ics", "", map[string]string{"If-None-Match": `"3"`})
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		w := performCalDAVRequest(router, "GET", "/caldav/todos/gone.ics", "", nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPutCalDAVObject(t *testing.T) {
+	body := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:abc@phone\r\nSUMMARY:Water plants\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		createOnly bool
+		created    bool
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "created", headers: map[string]string{"If-None-Match": "*"}, createOnly: true, created: true, wantStatus: http.StatusCreated},
+		{name: "replaced", wantStatus: http.StatusNoContent},
+		{name: "precondition failed", headers: map[string]string{"If-Match": `"2"`}, err: service.ErrPreconditionFailed, wantStatus: http.StatusPreconditionFailed},
+		{name: "concurrent update", err: service.ErrConcurrentUpdate, wantStatus: http.StatusConflict},
+		{name: "invalid data", err: fmt.Errorf("%w: %w", service.ErrInvalidCalendarData, ical.ErrInvalidCalendar), wantStatus: http.StatusForbidden, wantBody: "<cal:valid-calendar-data/>"},
+		{name: "not a todo", err: fmt.Errorf("%w: %w", service.ErrInvalidCalendarData, ical.ErrNoTodo), wantStatus: http.StatusForbidden, wantBody: "<cal:supported-calendar-component/>"},
+		{name: "UID conflict", err: service.ErrCalDAVUIDConflict, wantStatus: http.StatusForbidden, wantBody: "<cal:no-uid-conflict/>"},
+		{name: "invalid name", err: service.ErrInvalidCalDAVName, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockCalDAVService := setupCalDAVTestRouter()
+			call := mockCalDAVService.On("Put", mock.Anything, uint(1), "abc.ics", []byte(body), tt.createOnly)
+			if tt.err != nil {
+				call.Return(nil, false, tt.err)
+			} else {
+				call.Return(testCalDAVObject(), tt.created, nil)
+			}
+
+			w := performCalDAVRequest(router, "PUT", "/caldav/todos/abc.ics", body, tt.headers)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.err == nil {
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+			}
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestPutCalDAVObject_TooLarge(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+
+	w := performCalDAVRequest(router, "PUT", "/caldav/todos/abc.ics", strings.Repeat("x", 1<<20+1), nil)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	mockCalDAVService.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteCalDAVObject(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"deleted", nil, http.StatusNoContent},
+		{"not found", service.ErrCalDAVObjectNotFound, http.StatusNotFound},
+		{"precondition failed", service.ErrPreconditionFailed, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockCalDAVService := setupCalDAVTestRouter()
+			mockCalDAVService.On("Delete", mock.Anything, uint(1), "abc.ics").Return(tt.err)
+
+			w := performCalDAVRequest(router, "DELETE", "/caldav/todos/abc.ics", "", nil)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestCreateCalDAVPassword(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+	mockCalDAVService.On("CreatePassword", mock.Anything, uint(1), &model.CreateCalDAVPasswordRequest{Name: "Phone"}).
+		Return(&model.CalDAVPasswordResponse{ID: 3, Name: "Phone", Password: "dav_secret"}, nil)
+
+	t.Run("created", func(t *testing.T) {
+		w := performCalDAVRequest(router, "POST", "/api/v1/caldav/passwords", `{"name": "Phone"}`, map[string]string{"Content-Type": "application/json"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response model.CalDAVPasswordResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "dav_secret", response.Password)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		w := performCalDAVRequest(router, "POST", "/api/v1/caldav/passwords", `{}`, map[string]string{"Content-Type": "application/json"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteCalDAVPassword(t *testing.T) {
+	router, mockCalDAVService := setupCalDAVTestRouter()
+	mockCalDAVService.On("DeletePassword", mock.Anything, uint(3), uint(1)).Return(nil)
+	mockCalDAVService.On("DeletePassword", mock.Anything, uint(4), uint(1)).Return(service.ErrCalDAVPasswordNotFound)
+
+	assert.Equal(t, http.StatusNoContent, performCalDAVRequest(router, "DELETE", "/api/v1/caldav/passwords/3", "", nil).Code)
+	assert.Equal(t, http.StatusNotFound, performCalDAVRequest(router, "DELETE", "/api/v1/caldav/passwords/4", "", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, performCalDAVRequest(router, "DELETE", "/api/v1/caldav/passwords/abc", "", nil).Code)
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockCalendarService is a mock implementation of CalendarService
+type MockCalendarService struct {
+	mock.Mock
+}
+
+func (m *MockCalendarService) GetFeed(ctx context.Context, userID uint) (*model.CalendarFeedResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarFeedResponse), args.Error(1)
+}
+
+func (m *MockCalendarService) RegenerateToken(ctx context.Context, userID uint) (*model.CalendarFeedResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarFeedResponse), args.Error(1)
+}
+
+func (m *MockCalendarService) DisableFeed(ctx context.Context, userID uint) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockCalendarService) ResolveToken(ctx context.Context, token string) (uint, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockCalendarService) Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarStamp), args.Error(1)
+}
+
+func (m *MockCalendarService) FeedTodos(ctx context.Context, userID uint, fn func(*model.Todo) error) error {
+	args := m.Called(ctx, userID, fn)
+	return args.Error(0)
+}
+
+func setupCalendarTestRouter() (*gin.Engine, *MockCalendarService) {
+	gin.SetMode(gin.TestMode)
+
+	mockCalendarService := &MockCalendarService{}
+	h := handler.NewHandler(&service.Services{
+		Auth:     &MockAuthService{},
+		Todo:     &MockTodoService{},
+		Calendar: mockCalendarService,
+	})
+
+	router := gin.New()
+	router.GET("/calendar/:token", h.ServeCalendarFeed)
+	protected := router.Group("/calendar", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+	})
+	protected.GET("/feed", h.GetCalendarFeed)
+	protected.POST("/feed", h.RegenerateCalendarToken)
+	protected.DELETE("/feed", h.DisableCalendarFeed)
+
+	return router, mockCalendarService
+}
+
+func performCalendarRequest(router *gin.Engine, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func expectCalendarFeed(mockCalendarService *MockCalendarService, stamp *model.CalendarStamp, todos ...*model.Todo) {
+	mockCalendarService.On("ResolveToken", mock.Anything, "cal_secret").Return(uint(1), nil)
+	mockCalendarService.On("Stamp", mock.Anything, uint(1)).Return(stamp, nil)
+	mockCalendarService.On("FeedTodos", mock.Anything, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(2).(func(*model.Todo) error)
+		for _, todo := range todos {
+			_ = fn(todo)
+		}
+	}).Return(nil)
+}
+
+func TestServeCalendarFeed_Events(t *testing.T) {
+	router, mockCalendarService := setupCalendarTestRouter()
+
+	due := time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	expectCalendarFeed(mockCalendarService, &model.CalendarStamp{Count: 2, LastModified: updated},
+		&model.Todo{ID: 1, Title: "Dated", DueAt: &due, Version: 1, CreatedAt: updated, UpdatedAt: updated},
+		&model.Todo{ID: 2, Title: "Undated", Version: 1, CreatedAt: updated, UpdatedAt: updated},
+	)
+
+	w := performCalendarRequest(router, http.MethodGet, "/calendar/cal_secret.ics", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, "Tue, 02 Jan 2024 08:00:00 GMT", w.Header().Get("Last-Modified"))
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+	assert.Contains(t, body, "SUMMARY:Dated\r\n")
+	assert.NotContains(t, body, "Undated")
+	assert.NotContains(t, body, "BEGIN:VTODO")
+}
+
+func TestServeCalendarFeed_Todos(t *testing.T) {
+	router, mockCalendarService := setupCalendarTestRouter()
+
+	updated := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	expectCalendarFeed(mockCalendarService, &model.CalendarStamp{Count: 1, LastModified: updated},
+		&model.Todo{ID: 2, Title: "Undated", Version: 1, CreatedAt: updated, UpdatedAt: updated},
+	)
+
+	w := performCalendarRequest(router, http.MethodGet, "/calendar/cal_secret.ics?component=vtodo", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "BEGIN:VTODO\r\n")
+	assert.Contains(t, w.Body.String(), "SUMMARY:Undated\r\n")
+}
+
+func TestServeCalendarFeed_ConditionalRequests(t *testing.T) {
+	updated := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	stamp := &model.CalendarStamp{Count: 3, LastModified: updated}
+
+	router, mockCalendarService := setupCalendarTestRouter()
+	expectCalendarFeed(mockCalendarService, stamp)
+	first := performCalendarRequest(router, http.MethodGet, "/calendar/cal_secret.ics", nil)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	tests := []struct {
+		name           string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{"matching etag", "/calendar/cal_secret.ics", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"wildcard etag", "/calendar/cal_secret.ics", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"stale etag", "/calendar/cal_secret.ics", map[string]string{"If-None-Match": `"stale"`}, http.StatusOK},
+		{"other component", "/calendar/cal_secret.ics?component=vtodo", map[string]string{"If-None-Match": etag}, http.StatusOK},
+		{"not modified since", "/calendar/cal_secret.ics", map[string]string{"If-Modified-Since": updated.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", "/calendar/cal_secret.ics", map[string]string{"If-Modified-Since": updated.Add(-time.Second).Format(http.TimeFormat)}, http.StatusOK},
+		{"etag takes precedence", "/calendar/cal_secret.ics", map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": updated.Format(http.TimeFormat)}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockCalendarService := setupCalendarTestRouter()
+			expectCalendarFeed(mockCalendarService, stamp)
+
+			w := performCalendarRequest(router, http.MethodGet, tt.path, tt.headers)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+				mockCalendarService.AssertNotCalled(t, "FeedTodos", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestServeCalendarFeed_ETagChangesWithTodos(t *testing.T) {
+	updated := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+
+	router, mockCalendarService := setupCalendarTestRouter()
+	expectCalendarFeed(mockCalendarService, &model.CalendarStamp{Count: 3, LastModified: updated})
+	before := performCalendarRequest(router, http.MethodGet, "/calendar/cal_secret.ics", nil).Header().Get("ETag")
+
+	router, mockCalendarService = setupCalendarTestRouter()
+	expectCalendarFeed(mockCalendarService, &model.CalendarStamp{Count: 2, LastModified: updated})
+	after := performCalendarRequest(router, http.MethodGet, "/calendar/cal_secret.ics", nil).Header().Get("ETag")
+
+	assert.NotEqual(t, before, after)
+}
+
+func TestServeCalendarFeed_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		resolveErr     error
+		expectedStatus int
+		expectedError  string
+	}{
+		{"missing extension", "/calendar/cal_secret", nil, http.StatusNotFound, "not_found"},
+		{"invalid component", "/calendar/cal_secret.ics?component=vjournal", nil, http.StatusBadRequest, "invalid_component"},
+		{"unknown token", "/calendar/cal_secret.ics", service.ErrCalendarFeedNotFound, http.StatusNotFound, "not_found"},
+		{"internal error", "/calendar/cal_secret.ics", assert.AnError, http.StatusInternalServerError, "retrieval_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockCalendarService := setupCalendarTestRouter()
+			mockCalendarService.On("ResolveToken", mock.Anything, "cal_secret").Return(uint(0), tt.resolveErr)
+
+			w := performCalendarRequest(router, http.MethodGet, tt.path, nil)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response model.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedError, response.Error)
+			mockCalendarService.AssertNotCalled(t, "FeedTodos", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRegenerateCalendarToken_ReturnsURL(t *testing.T) {
+	router, mockCalendarService := setupCalendarTestRouter()
+	mockCalendarService.On("RegenerateToken", mock.Anything, uint(1)).Return(&model.CalendarFeedResponse{Enabled: true, Token: "cal_secret"}, nil)
+
+	w := performCalendarRequest(router, http.MethodPost, "/calendar/feed", map[string]string{"X-Forwarded-Proto": "https"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response model.CalendarFeedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "cal_secret", response.Token)
+	assert.Equal(t, "https://example.com/api/v1/calendar/cal_secret.ics", response.URL)
+}
+
+func TestGetCalendarFeed(t *testing.T) {
+	router, mockCalendarService := setupCalendarTestRouter()
+	mockCalendarService.On("GetFeed", mock.Anything, uint(1)).Return(&model.CalendarFeedResponse{Enabled: false}, nil)
+
+	w := performCalendarRequest(router, http.MethodGet, "/calendar/feed", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled":false}`, w.Body.String())
+	mockCalendarService.AssertNotCalled(t, "ResolveToken", mock.Anything, mock.Anything)
+}
+
+func TestDisableCalendarFeed(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"disabled", nil, http.StatusNoContent},
+		{"not found", service.ErrCalendarFeedNotFound, http.StatusNotFound},
+		{"internal error", assert.AnError, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockCalendarService := setupCalendarTestRouter()
+			mockCalendarService.On("DisableFeed", mock.Anything, uint(1)).Return(tt.serviceErr)
+
+			w := performCalendarRequest(router, http.MethodDelete, "/calendar/feed", nil)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// performCommentRequest calls a comment handler for comment 3 of todo 7
+func performCommentRequest(method, query, body string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/todos/7/comments"+query, bytes.NewBufferString(body))
+	if body != "" {
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+	c.Params = gin.Params{{Key: "id", Value: "7"}, {Key: "comment_id", Value: "3"}}
+	c.Set("user_id", uint(1))
+
+	handle(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func commentIDPtr(id uint) *uint {
+	return &id
+}
+
+func setupCommentTestHandler() (*handler.Handler, *MockTodoService) {
+	h, _, mockTodoService := setupTestHandler()
+	return h, mockTodoService
+}
+
+func TestGetTodoComments_Pagination(t *testing.T) {
+	h, mockTodoService := setupCommentTestHandler()
+
+	mockTodoService.On("ListComments", mock.Anything, uint(7), uint(1), 2, 10).Return(&model.CommentPage{
+		Comments: []*model.TodoComment{{ID: 3, TodoID: 7, UserID: 1, Body: "**Done**", Replies: []*model.TodoComment{{ID: 4, TodoID: 7, UserID: 1, ParentID: commentIDPtr(3), Body: "Thanks"}}}},
+		Page:     2,
+		PerPage:  10,
+		Total:    11,
+	}, nil)
+
+	w := performCommentRequest(http.MethodGet, "?page=2&per_page=10", "", h.GetTodoComments)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page model.CommentPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(11), page.Total)
+	require.Len(t, page.Comments, 1)
+	require.Len(t, page.Comments[0].Replies, 1)
+	assert.Equal(t, "Thanks", page.Comments[0].Replies[0].Body)
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestGetTodoComments_InvalidPage(t *testing.T) {
+	h, mockTodoService := setupCommentTestHandler()
+
+	w := performCommentRequest(http.MethodGet, "?per_page=-1", "", h.GetTodoComments)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "per_page must be a positive integer")
+	mockTodoService.AssertNotCalled(t, "ListComments", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddTodoComment_Success(t *testing.T) {
+	h, mockTodoService := setupCommentTestHandler()
+
+	mockTodoService.On("AddComment", mock.Anything, uint(7), mock.MatchedBy(func(req *model.CreateCommentRequest) bool {
+		return req.Body == "Looks good @me@example.com" && *req.ParentID == 3
+	}), uint(1)).Return(&model.TodoComment{
+		ID:       4,
+		TodoID:   7,
+		UserID:   1,
+		ParentID: commentIDPtr(3),
+		Body:     "Looks good @me@example.com",
+		Mentions: []model.CommentMention{{UserID: 1, Email: "me@example.com"}},
+	}, nil)
+
+	w := performCommentRequest(http.MethodPost, "", `{"body": "Looks good @me@example.com", "parent_id": 3}`, h.AddTodoComment)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"mentions":[{"user_id":1,"email":"me@example.com"}]`)
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestAddTodoComment_ValidationFailed(t *testing.T) {
+	h, mockTodoService := setupCommentTestHandler()
+
+	w := performCommentRequest(http.MethodPost, "", `{"body": ""}`, h.AddTodoComment)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "This field is required", response.Details["Body"])
+	mockTodoService.AssertNotCalled(t, "AddComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTodoComment_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"todo not found", service.ErrTodoNotFound, http.StatusNotFound, "not_found"},
+		{"comment not found", service.ErrCommentNotFound, http.StatusNotFound, "not_found"},
+		{"not the author", service.ErrCommentNotAuthor, http.StatusForbidden, "forbidden"},
+		{"blank body", service.ErrEmptyComment, http.StatusBadRequest, "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockTodoService := setupCommentTestHandler()
+			mockTodoService.On("UpdateComment", mock.Anything, uint(7), uint(3), mock.Anything, uint(1)).Return(nil, tt.err)
+			mockTodoService.On("DeleteComment", mock.Anything, uint(7), uint(3), uint(1)).Return(tt.err)
+
+			w := performCommentRequest(http.MethodPut, "/3", `{"body": "Edited"}`, h.UpdateTodoComment)
+			assert.Equal(t, tt.status, w.Code)
+			var response model.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Error)
+
+			w = performCommentRequest(http.MethodDelete, "/3", "", h.DeleteTodoComment)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestAddTodoComment_ParentNotFound(t *testing.T) {
+	h, mockTodoService := setupCommentTestHandler()
+
+	mockTodoService.On("AddComment", mock.Anything, uint(7), mock.Anything, uint(1)).Return(nil, service.ErrParentCommentNotFound)
+
+	w := performCommentRequest(http.MethodPost, "", `{"body": "Reply", "parent_id": 99}`, h.AddTodoComment)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "parent_comment_not_found")
+}
+
+func TestDeleteTodoComment_Success(t *testing.T) {
+	h, mockTodoService := setupCommentTestHandler()
+
+	mockTodoService.On("DeleteComment", mock.Anything, uint(7), uint(3), uint(1)).Return(nil)
+
+	w := performCommentRequest(http.MethodDelete, "/3", "", h.DeleteTodoComment)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockTodoService.AssertExpectations(t)
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+	"todo-api-backend/pkg/validator"
+)
+
+// MockCustomFieldService is a mock implementation of CustomFieldService
+type MockCustomFieldService struct {
+	mock.Mock
+}
+
+func (m *MockCustomFieldService) Create(ctx context.Context, userID uint, req *model.CreateCustomFieldRequest) (*model.CustomField, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldService) List(ctx context.Context, userID uint) ([]*model.CustomField, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldService) Get(ctx context.Context, id uint, userID uint) (*model.CustomField, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateCustomFieldRequest) (*model.CustomField, error) {
+	args := m.Called(ctx, id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldService) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func setupCustomFieldTestHandler() (*handler.Handler, *MockCustomFieldService, *MockTodoService) {
+	gin.SetMode(gin.TestMode)
+
+	mockFieldService := &MockCustomFieldService{}
+	mockTodoService := &MockTodoService{}
+	services := &service.Services{
+		Auth:        &MockAuthService{},
+		Todo:        mockTodoService,
+		CustomField: mockFieldService,
+	}
+
+	return handler.NewHandler(services), mockFieldService, mockTodoService
+}
+
+// performTodoListing calls GetTodos with the given query string
+func performTodoListing(h *handler.Handler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos?"+query, nil)
+	c.Set("user_id", uint(1))
+
+	h.GetTodos(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestCreateCustomField_Success(t *testing.T) {
+	h, mockFieldService, _ := setupCustomFieldTestHandler()
+
+	mockFieldService.On("Create", mock.Anything, uint(1), mock.MatchedBy(func(req *model.CreateCustomFieldRequest) bool {
+		return req.Key == "points" && req.Type == model.CustomFieldNumber && *req.Rules.Max == 13
+	})).Return(&model.CustomField{ID: 1, UserID: 1, Key: "points", Type: model.CustomFieldNumber}, nil)
+
+	body := `{"key": "points", "name": "Story points", "type": "number", "rules": {"min": 1, "max": 13}}`
+	w := performTemplateRequest(http.MethodPost, "/custom-fields", "", body, h.CreateCustomField)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockFieldService.AssertExpectations(t)
+}
+
+func TestCreateCustomField_ValidationFailed(t *testing.T) {
+	h, mockFieldService, _ := setupCustomFieldTestHandler()
+
+	body := `{"key": "points", "name": "Story points", "type": "currency"}`
+	w := performTemplateRequest(http.MethodPost, "/custom-fields", "", body, h.CreateCustomField)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Details, "Type")
+	mockFieldService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateCustomField_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"key exists", service.ErrCustomFieldExists, http.StatusConflict, "custom_field_exists"},
+		{"limit reached", service.ErrCustomFieldLimit, http.StatusConflict, "custom_field_limit_reached"},
+		{"invalid rules", fmt.Errorf("%w: format only applies to text fields", service.ErrInvalidCustomField), http.StatusBadRequest, "invalid_custom_field"},
+		{"list not found", service.ErrListNotFound, http.StatusUnprocessableEntity, "list_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockFieldService, _ := setupCustomFieldTestHandler()
+			mockFieldService.On("Create", mock.Anything, uint(1), mock.Anything).Return(nil, tt.err)
+
+			body := `{"key": "points", "name": "Story points", "type": "number"}`
+			w := performTemplateRequest(http.MethodPost, "/custom-fields", "", body, h.CreateCustomField)
+
+			assert.Equal(t, tt.status, w.Code)
+			var response model.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Error)
+		})
+	}
+}
+
+func TestDeleteCustomField_NotFound(t *testing.T) {
+	h, mockFieldService, _ := setupCustomFieldTestHandler()
+
+	mockFieldService.On("Delete", mock.Anything, uint(7), uint(1)).Return(service.ErrCustomFieldNotFound)
+
+	w := performTemplateRequest(http.MethodDelete, "/custom-fields/7", "7", "", h.DeleteCustomField)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateTodo_InvalidCustomFields(t *testing.T) {
+	h, _, mockTodoService := setupCustomFieldTestHandler()
+
+	err := fmt.Errorf("%w: %w", service.ErrInvalidCustomFieldValues, validator.ValidationErrors{Errors: []validator.ValidationError{
+		{Field: "custom_fields.points", Tag: "max", Message: "custom_fields.points must be at most 13"},
+	}})
+	mockTodoService.On("Create", mock.Anything, mock.MatchedBy(func(req *model.CreateTodoRequest) bool {
+		return req.CustomFields["points"] == 21.0
+	}), uint(1)).Return(nil, err)
+
+	w := performTemplateRequest(http.MethodPost, "/todos", "", `{"title": "Card", "custom_fields": {"points": 21}}`, h.CreateTodo)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "invalid_custom_fields", response.Error)
+	assert.Equal(t, "custom_fields.points must be at most 13", response.Details["custom_fields.points"])
+}
+
+func TestGetTodos_CustomFieldFilter(t *testing.T) {
+	h, _, mockTodoService := setupCustomFieldTestHandler()
+
+	mockTodoService.On("List", mock.Anything, uint(1), mock.MatchedBy(func(filter *model.TodoFilter) bool {
+		return len(filter.CustomFields) == 3 &&
+			filter.CustomFields[0] == model.CustomFieldCondition{Key: "customer", Op: model.CustomFieldEqual, Value: "Acme Corp"} &&
+			filter.CustomFields[1] == model.CustomFieldCondition{Key: "points", Op: model.CustomFieldAtLeast, Value: "3"} &&
+			filter.CustomFields[2] == model.CustomFieldCondition{Key: "points", Op: model.CustomFieldAtMost, Value: "8"} &&
+			*filter.SortField == model.CustomFieldSort{Key: "points", Desc: true}
+	})).Return([]*model.Todo{{ID: 1, CustomFields: model.CustomFieldValues{"points": 5.0}}}, nil)
+
+	w := performTodoListing(h, "cf[customer]=Acme+Corp&cf_gte[points]=3&cf_lte[points]=8&sort=-cf.points")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"custom_fields":{"points":5}`)
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestGetTodos_InvalidCustomFieldFilter(t *testing.T) {
+	h, _, mockTodoService := setupCustomFieldTestHandler()
+
+	w := performTodoListing(h, "sort=title")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "sort must name a custom field as cf.key or -cf.key")
+
+	err := fmt.Errorf("%w: unknown custom field %q", service.ErrInvalidCustomFieldFilter, "colour")
+	mockTodoService.On("List", mock.Anything, uint(1), mock.Anything).Return(nil, err)
+
+	w = performTodoListing(h, "cf[colour]=red")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "invalid_filter", response.Error)
+	assert.Contains(t, response.Message, `unknown custom field "colour"`)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func TestAddTodoDependency_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	req := &model.AddDependencyRequest{BlockedBy: 3}
+	dependency := &model.TodoDependency{ID: 1, UserID: 1, TodoID: 2, BlockedByID: 3}
+	mockTodoService.On("AddDependency", mock.Anything, uint(2), req, uint(1)).Return(dependency, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/2/dependencies", strings.NewReader(`{"blocked_by":3}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	h.AddTodoDependency(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response model.TodoDependency
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, uint(2), response.TodoID)
+	assert.Equal(t, uint(3), response.BlockedByID)
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestAddTodoDependency_ValidationFailed(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	for _, body := range []string{`{}`, `{"blocked_by":3,"blocks":4}`} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/todos/2/dependencies", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+		h.AddTodoDependency(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "validation_failed")
+	}
+
+	mockTodoService.AssertNotCalled(t, "AddDependency", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddTodoDependency_Cycle(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	cycleErr := fmt.Errorf("%w: 2 -> 1 -> 2, where each todo would be blocked by the next", service.ErrDependencyCycle)
+	mockTodoService.On("AddDependency", mock.Anything, uint(2), mock.Anything, uint(1)).Return(nil, cycleErr)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/2/dependencies", strings.NewReader(`{"blocked_by":1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	h.AddTodoDependency(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response model.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "dependency_cycle", response.Error)
+	assert.Contains(t, response.Message, "2 -> 1 -> 2")
+}
+
+func TestAddTodoDependency_RelatedTodoNotFound(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("AddDependency", mock.Anything, uint(2), mock.Anything, uint(1)).Return(nil, service.ErrDependencyTodoNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/2/dependencies", strings.NewReader(`{"blocks":9}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	h.AddTodoDependency(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestRemoveTodoDependency_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("RemoveDependency", mock.Anything, uint(2), uint(3), uint(1)).Return(nil)
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/todos/2/dependencies/3", nil)
+	c.Params = gin.Params{{Key: "id", Value: "2"}, {Key: "other_id", Value: "3"}}
+
+	h.RemoveTodoDependency(c)
+	c.Writer.WriteHeaderNow()
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestRemoveTodoDependency_NotFound(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("RemoveDependency", mock.Anything, uint(2), uint(3), uint(1)).Return(service.ErrDependencyNotFound)
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/todos/2/dependencies/3", nil)
+	c.Params = gin.Params{{Key: "id", Value: "2"}, {Key: "other_id", Value: "3"}}
+
+	h.RemoveTodoDependency(c)
+
+	assert.Equal(t, http.StatusNotFound, c.Writer.Status())
+}
+
+func TestGetDependencyGraph_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	graph := &model.DependencyGraphResponse{
+		Todos: []*model.Todo{{ID: 1, UserID: 1}, {ID: 2, UserID: 1, Blocked: true}},
+		Edges: []model.DependencyEdge{{TodoID: 2, BlockedByID: 1}},
+		Order: []uint{1, 2},
+	}
+	mockTodoService.On("GetDependencyGraph", mock.Anything, uint(1)).Return(graph, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/dependencies", nil)
+
+	h.GetDependencyGraph(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.DependencyGraphResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []uint{1, 2}, response.Order)
+	assert.True(t, response.Todos[1].Blocked)
+}
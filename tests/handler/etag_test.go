@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/internal/service"
+)
+
+// versionedTodoRepository stores a single todo and implements the version
+// check of the real repository; other methods are not used by these tests
+type versionedTodoRepository struct {
+	repository.TodoRepository
+	todo    model.Todo
+	updates int
+}
+
+func (r *versionedTodoRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	todo := r.todo
+	return &todo, nil
+}
+
+func (r *versionedTodoRepository) Update(ctx context.Context, todo *model.Todo) error {
+	if todo.Version != r.todo.Version {
+		return repository.ErrVersionConflict
+	}
+	todo.Version++
+	r.todo = *todo
+	r.updates++
+	return nil
+}
+
+func setupETagTestHandler(version uint) (*handler.Handler, *versionedTodoRepository) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &versionedTodoRepository{todo: model.Todo{ID: 1, Title: "Todo", UserID: 1, Version: version}}
+	services := &service.Services{
+		Todo: service.NewTodoService(repo, nil),
+	}
+	return handler.NewHandler(services), repo
+}
+
+func performETagRequest(handle func(*gin.Context), method string, headers map[string]string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest(method, "/todos/1", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		c.Request.Header.Set(name, value)
+	}
+
+	handle(c)
+	// Bodiless responses are only written when the request completes
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestGetTodo_ETag(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+	mockTodoService.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Todo", UserID: 1, Version: 3}, nil)
+
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{"no validator", "", http.StatusOK},
+		{"current etag", `"3"`, http.StatusNotModified},
+		{"weak current etag", `W/"3"`, http.StatusNotModified},
+		{"one of several", `"1", "3"`, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"stale etag", `"2"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performETagRequest(h.GetTodo, http.MethodGet, map[string]string{"If-None-Match": tt.ifNoneMatch}, "")
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+			if w.Code == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetTodo_ETagChangesWhenBlocked(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+	mockTodoService.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Todo", UserID: 1, Version: 3, Blocked: true}, nil)
+
+	// Adding a blocker does not give the todo a new version
+	w := performETagRequest(h.GetTodo, http.MethodGet, map[string]string{"If-None-Match": `"3"`}, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3-blocked"`, w.Header().Get("ETag"))
+
+	w = performETagRequest(h.GetTodo, http.MethodGet, map[string]string{"If-None-Match": `"3-blocked"`}, "")
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestUpdateTodo_IfMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{"no precondition", "", http.StatusOK},
+		{"current etag", `"2"`, http.StatusOK},
+		{"one of several", `"1", "2"`, http.StatusOK},
+		{"etag of a blocked todo", `"2-blocked"`, http.StatusOK},
+		{"wildcard", "*", http.StatusOK},
+		{"stale etag", `"1"`, http.StatusPreconditionFailed},
+		{"weak etag never matches", `W/"2"`, http.StatusPreconditionFailed},
+		{"malformed etag", `2`, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, repo := setupETagTestHandler(2)
+
+			w := performETagRequest(h.UpdateTodo, http.MethodPut, map[string]string{"If-Match": tt.ifMatch}, `{"title": "Updated"}`)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+				assert.Equal(t, 1, repo.updates)
+			} else {
+				assert.Contains(t, w.Body.String(), "precondition_failed")
+				assert.Equal(t, 0, repo.updates)
+			}
+		})
+	}
+}
+
+func TestPatchTodo_IfMatch(t *testing.T) {
+	h, repo := setupETagTestHandler(5)
+
+	w := performETagRequest(h.PatchTodo, http.MethodPatch, map[string]string{
+		"Content-Type": model.PatchTypeMerge,
+		"If-Match":     `"4"`,
+	}, `{"completed": true}`)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, 0, repo.updates)
+}
+
+func TestDeleteTodo_IfMatch(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+	mockTodoService.On("Delete", mock.Anything, uint(1), uint(1)).Return(service.ErrPreconditionFailed)
+
+	w := performETagRequest(h.DeleteTodo, http.MethodDelete, map[string]string{"If-Match": `"1"`}, "")
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestUpdateTodo_ConcurrentUpdate(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+	mockTodoService.On("Replace", mock.Anything, uint(1), mock.Anything, uint(1)).Return(nil, service.ErrConcurrentUpdate)
+
+	w := performETagRequest(h.UpdateTodo, http.MethodPut, nil, `{"title": "Updated"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
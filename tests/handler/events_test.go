@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/events"
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/service"
+)
+
+// streamRecorder is a concurrency-safe response writer that signals every flush
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+	mu      sync.Mutex
+	body    bytes.Buffer
+	flushed chan struct{}
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan struct{}, 100)}
+}
+
+func (r *streamRecorder) Write(data []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.Write(data)
+}
+
+func (r *streamRecorder) Flush() {
+	r.flushed <- struct{}{}
+}
+
+func (r *streamRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.String()
+}
+
+// startEventStream runs the event stream handler until the returned function is called
+func startEventStream(t *testing.T, h *handler.Handler, lastEventID string) (*streamRecorder, func()) {
+	w := newStreamRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request = httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	if lastEventID != "" {
+		c.Request.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.StreamEvents(c)
+		close(done)
+	}()
+
+	select {
+	case <-w.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("event stream did not start")
+	}
+
+	return w, func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestStreamEvents_DeliversLiveEvents(t *testing.T) {
+	bus := events.NewBus(10)
+	h := handler.NewHandler(&service.Services{Events: bus}, handler.WithHeartbeat(0))
+
+	w, stop := startEventStream(t, h, "")
+
+	bus.Publish(events.Event{Type: events.TypeTodoCreated, UserID: 2, TodoID: 7})
+	bus.Publish(events.Event{Type: events.TypeTodoCreated, UserID: 1, TodoID: 1})
+	select {
+	case <-w.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+	stop()
+
+	body := w.String()
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, body, "retry: 3000\n\n")
+	assert.Contains(t, body, "event: todo.created\n")
+	assert.Contains(t, body, `"todo_id":1`)
+	assert.NotContains(t, body, `"todo_id":7`)
+	assert.NotContains(t, body, "event: reset")
+}
+
+func TestStreamEvents_ResumesFromLastEventID(t *testing.T) {
+	bus := events.NewBus(10)
+	h := handler.NewHandler(&service.Services{Events: bus}, handler.WithHeartbeat(0))
+
+	sub, _, _ := bus.Subscribe(1, 0, false)
+	bus.Publish(events.Event{Type: events.TypeTodoCreated, UserID: 1, TodoID: 1})
+	seen := <-sub.Events()
+	sub.Close()
+	bus.Publish(events.Event{Type: events.TypeTodoUpdated, UserID: 1, TodoID: 1})
+
+	w, stop := startEventStream(t, h, strconv.FormatUint(seen.ID, 10))
+	stop()
+
+	body := w.String()
+	assert.NotContains(t, body, "event: todo.created")
+	assert.Contains(t, body, "id: "+strconv.FormatUint(seen.ID+1, 10)+"\nevent: todo.updated\n")
+	assert.NotContains(t, body, "event: reset")
+}
+
+func TestStreamEvents_ResetsWhenReplayUnavailable(t *testing.T) {
+	bus := events.NewBus(10)
+	h := handler.NewHandler(&service.Services{Events: bus}, handler.WithHeartbeat(0))
+
+	for _, lastEventID := range []string{"1", "garbage"} {
+		w, stop := startEventStream(t, h, lastEventID)
+		stop()
+
+		assert.Contains(t, w.String(), "event: reset\ndata: {}\n\n", lastEventID)
+	}
+}
+
+func TestStreamEvents_SendsHeartbeats(t *testing.T) {
+	bus := events.NewBus(10)
+	h := handler.NewHandler(&service.Services{Events: bus}, handler.WithHeartbeat(10*time.Millisecond))
+
+	w, stop := startEventStream(t, h, "")
+	select {
+	case <-w.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("no heartbeat was sent")
+	}
+	stop()
+
+	require.True(t, strings.Contains(w.String(), ": heartbeat\n\n"))
+}
+
+func TestStreamEvents_Unauthorized(t *testing.T) {
+	h := handler.NewHandler(&service.Services{Events: events.NewBus(10)})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/events", nil)
+
+	h.StreamEvents(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
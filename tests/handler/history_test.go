@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+)
+
+func TestGetTodoHistory_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	history := []*model.TodoHistory{
+		{
+			ID:      1,
+			TodoID:  1,
+			UserID:  1,
+			ActorID: 1,
+			Action:  model.HistoryActionUpdated,
+			Changes: model.FieldChanges{{Field: "title", Old: "Old", New: "New"}},
+		},
+	}
+	mockTodoService.On("GetHistory", mock.Anything, uint(1), uint(1)).Return(history, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/1/history", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.GetTodoHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.TodoHistoryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "title", response.History[0].Changes[0].Field)
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestGetTodoHistory_NotFound(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("GetHistory", mock.Anything, uint(1), uint(1)).Return(nil, errors.New("todo not found"))
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/1/history", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.GetTodoHistory(c)
+
+	assert.Equal(t, http.StatusNotFound, c.Writer.Status())
+}
+
+func TestGetTodoHistory_InvalidID(t *testing.T) {
+	h, _, _ := setupTestHandler()
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/abc/history", nil)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	h.GetTodoHistory(c)
+
+	assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockListService is a mock implementation of ListService
+type MockListService struct {
+	mock.Mock
+}
+
+func (m *MockListService) Create(ctx context.Context, userID uint, req *model.CreateListRequest) (*model.TodoList, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoList), args.Error(1)
+}
+
+func (m *MockListService) List(ctx context.Context, userID uint) ([]*model.TodoList, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoList), args.Error(1)
+}
+
+func (m *MockListService) Get(ctx context.Context, id uint, userID uint) (*model.TodoList, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoList), args.Error(1)
+}
+
+func (m *MockListService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateListRequest) (*model.TodoList, error) {
+	args := m.Called(ctx, id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoList), args.Error(1)
+}
+
+func (m *MockListService) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func setupListTestHandler() (*handler.Handler, *MockListService, *MockTodoService) {
+	gin.SetMode(gin.TestMode)
+
+	mockListService := &MockListService{}
+	mockTodoService := &MockTodoService{}
+	services := &service.Services{
+		Auth: &MockAuthService{},
+		Todo: mockTodoService,
+		List: mockListService,
+	}
+
+	return handler.NewHandler(services), mockListService, mockTodoService
+}
+
+func TestCreateList_Success(t *testing.T) {
+	h, mockListService, _ := setupListTestHandler()
+
+	mockListService.On("Create", mock.Anything, uint(1), mock.MatchedBy(func(req *model.CreateListRequest) bool {
+		return req.Name == "Sprint" && len(req.Workflow.States) == 3 && len(req.Workflow.Transitions) == 2
+	})).Return(&model.TodoList{ID: 4, UserID: 1, Name: "Sprint"}, nil)
+
+	body := `{"name": "Sprint", "workflow": {"states": [{"key": "backlog", "name": "Backlog"}, {"key": "doing", "name": "Doing"}, {"key": "done", "name": "Done", "terminal": true}], "transitions": [{"from": "backlog", "to": "doing"}, {"from": "doing", "to": "done"}]}}`
+	w := performTemplateRequest(http.MethodPost, "/lists", "", body, h.CreateList)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockListService.AssertExpectations(t)
+}
+
+func TestCreateList_ValidationFailed(t *testing.T) {
+	h, mockListService, _ := setupListTestHandler()
+
+	body := `{"name": "Sprint", "workflow": {"states": [{"key": "backlog", "name": "Backlog"}, {"key": "", "name": "Done"}]}}`
+	w := performTemplateRequest(http.MethodPost, "/lists", "", body, h.CreateList)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "This field is required", response.Details["Workflow.States[1].Key"])
+	mockListService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateList_InvalidWorkflow(t *testing.T) {
+	h, mockListService, _ := setupListTestHandler()
+
+	err := fmt.Errorf("%w: at least one state must be terminal", service.ErrInvalidWorkflow)
+	mockListService.On("Create", mock.Anything, uint(1), mock.Anything).Return(nil, err)
+
+	body := `{"name": "Sprint", "workflow": {"states": [{"key": "backlog", "name": "Backlog"}, {"key": "doing", "name": "Doing"}]}}`
+	w := performTemplateRequest(http.MethodPost, "/lists", "", body, h.CreateList)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "at least one state must be terminal")
+}
+
+func TestUpdateList_StateInUse(t *testing.T) {
+	h, mockListService, _ := setupListTestHandler()
+
+	err := fmt.Errorf("%w: review", service.ErrWorkflowStateInUse)
+	mockListService.On("Update", mock.Anything, uint(4), uint(1), mock.Anything).Return(nil, err)
+
+	body := `{"workflow": {"states": [{"key": "backlog", "name": "Backlog"}, {"key": "done", "name": "Done", "terminal": true}]}}`
+	w := performTemplateRequest(http.MethodPut, "/lists/4", "4", body, h.UpdateList)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "state_in_use")
+}
+
+func TestDeleteList_NotFound(t *testing.T) {
+	h, mockListService, _ := setupListTestHandler()
+
+	mockListService.On("Delete", mock.Anything, uint(4), uint(1)).Return(service.ErrListNotFound)
+
+	w := performTemplateRequest(http.MethodDelete, "/lists/4", "4", "", h.DeleteList)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetListBoard_Success(t *testing.T) {
+	h, _, mockTodoService := setupListTestHandler()
+
+	board := &model.BoardResponse{
+		List: &model.TodoList{ID: 4, Name: "Sprint"},
+		Columns: []model.BoardColumn{
+			{WorkflowState: model.WorkflowState{Key: "todo", Name: "To Do"}, Todos: []*model.Todo{{ID: 1, State: "todo"}}, Count: 1},
+			{WorkflowState: model.WorkflowState{Key: "done", Name: "Done", Terminal: true}, Todos: []*model.Todo{}},
+		},
+		Count: 1,
+	}
+	mockTodoService.On("GetBoard", mock.Anything, uint(4), uint(1)).Return(board, nil)
+
+	w := performTemplateRequest(http.MethodGet, "/lists/4/board", "4", "", h.GetListBoard)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	columns := response["columns"].([]interface{})
+	require.Len(t, columns, 2)
+	assert.Equal(t, "todo", columns[0].(map[string]interface{})["key"])
+	assert.Equal(t, true, columns[1].(map[string]interface{})["terminal"])
+}
+
+func TestMoveTodo_TransitionNotAllowed(t *testing.T) {
+	h, _, mockTodoService := setupListTestHandler()
+
+	err := fmt.Errorf("%w: from %q to %q", service.ErrTransitionNotAllowed, "backlog", "done")
+	mockTodoService.On("MoveTodo", mock.Anything, uint(2), mock.MatchedBy(func(req *model.MoveTodoRequest) bool {
+		return *req.ListID == 4 && req.State == "done"
+	}), uint(1)).Return(nil, err)
+
+	w := performTemplateRequest(http.MethodPost, "/todos/2/move", "2", `{"list_id": 4, "state": "done"}`, h.MoveTodo)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "transition_not_allowed", response.Error)
+	assert.Contains(t, response.Message, `from "backlog" to "done"`)
+}
+
+func TestMoveTodo_Success(t *testing.T) {
+	h, _, mockTodoService := setupListTestHandler()
+
+	listID := uint(4)
+	mockTodoService.On("MoveTodo", mock.Anything, uint(2), mock.Anything, uint(1)).
+		Return(&model.Todo{ID: 2, UserID: 1, ListID: &listID, State: "doing", Version: 3}, nil)
+
+	w := performTemplateRequest(http.MethodPost, "/todos/2/move", "2", `{"list_id": 4, "state": "doing"}`, h.MoveTodo)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"state":"doing"`)
+}
+
+func TestCreateTodo_ListNotFound(t *testing.T) {
+	h, _, mockTodoService := setupListTestHandler()
+
+	mockTodoService.On("Create", mock.Anything, mock.Anything, uint(1)).Return(nil, service.ErrListNotFound)
+
+	w := performTemplateRequest(http.MethodPost, "/todos", "", `{"title": "Card", "list_id": 9}`, h.CreateTodo)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "list_not_found")
+}
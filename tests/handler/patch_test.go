@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+)
+
+func performPatchRequest(h interface{ PatchTodo(*gin.Context) }, id, contentType, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Request = httptest.NewRequest(http.MethodPatch, "/todos/"+id, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", contentType)
+
+	h.PatchTodo(c)
+	return w
+}
+
+func TestPatchTodo_Success(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{model.PatchTypeMerge, `{"description": null}`},
+		{model.PatchTypeJSON + "; charset=utf-8", `[{"op": "remove", "path": "/description"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			patchType := model.PatchTypeMerge
+			if tt.body[0] == '[' {
+				patchType = model.PatchTypeJSON
+			}
+			expectedTodo := &model.Todo{ID: 1, Title: "Todo", UserID: 1}
+			mockTodoService.On("Patch", mock.Anything, uint(1), patchType, []byte(tt.body), uint(1)).Return(expectedTodo, nil)
+
+			w := performPatchRequest(h, "1", tt.contentType, tt.body)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockTodoService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchTodo_UnsupportedMediaType(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	w := performPatchRequest(h, "1", "application/json", `{"title": "New"}`)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", w.Header().Get("Accept-Patch"))
+	mockTodoService.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchTodo_InvalidID(t *testing.T) {
+	h, _, _ := setupTestHandler()
+
+	w := performPatchRequest(h, "invalid", model.PatchTypeMerge, `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPatchTodo_ServiceErrors(t *testing.T) {
+	tests := []struct {
+		err            error
+		expectedStatus int
+		expectedError  string
+	}{
+		{errors.New("todo not found"), http.StatusNotFound, "not_found"},
+		{fmt.Errorf("%w: operation 0: missing \"path\"", errors.New("invalid patch document")), http.StatusBadRequest, "invalid_patch"},
+		{errors.New("patch cannot be applied: operation 0: test operation failed at \"/title\""), http.StatusConflict, "patch_conflict"},
+		{errors.New("patched todo is invalid: title is required"), http.StatusUnprocessableEntity, "validation_failed"},
+		{errors.New("database error"), http.StatusInternalServerError, "update_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedError, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			mockTodoService.On("Patch", mock.Anything, uint(1), model.PatchTypeJSON, mock.Anything, uint(1)).Return(nil, tt.err)
+
+			w := performPatchRequest(h, "1", model.PatchTypeJSON, `[]`)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), `"error":"`+tt.expectedError+`"`)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func performQuickAddRequest(h interface{ QuickAddTodo(*gin.Context) }, body, acceptLanguage string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/quick", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if acceptLanguage != "" {
+		c.Request.Header.Set("Accept-Language", acceptLanguage)
+	}
+
+	h.QuickAddTodo(c)
+	return w
+}
+
+func TestQuickAddTodo_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+	dueAt := timePtr(time.Date(2024, 1, 5, 15, 0, 0, 0, time.UTC))
+	expected := &model.QuickAddResponse{
+		Todo: &model.Todo{ID: 1, Title: "Call Bob #sales pri:A", DueAt: dueAt, UserID: 1},
+		Interpretation: &model.QuickAddInterpretation{
+			Title:    "Call Bob",
+			DueAt:    dueAt,
+			Priority: "A",
+			Tags:     []string{"sales"},
+			Matches:  []model.QuickAddMatch{{Kind: "date", Text: "tomorrow"}},
+			Locale:   "en",
+			Timezone: "UTC",
+		},
+	}
+	mockTodoService.On("QuickAdd", mock.Anything, uint(1), &model.QuickAddRequest{Text: "Call Bob tomorrow 3pm !high #sales"}).Return(expected, nil)
+
+	w := performQuickAddRequest(h, `{"text": "Call Bob tomorrow 3pm !high #sales"}`, "")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response model.QuickAddResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Call Bob #sales pri:A", response.Todo.Title)
+	assert.Equal(t, "Call Bob", response.Interpretation.Title)
+	assert.Equal(t, "A", response.Interpretation.Priority)
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestQuickAddTodo_Locale(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		acceptLanguage string
+		locale         string
+	}{
+		{"from header", `{"text": "Steuer morgen"}`, "pt-BR, de-DE;q=0.9", "de"},
+		{"request wins over header", `{"text": "Steuer morgen", "locale": "fr"}`, "de", "fr"},
+		{"unsupported header", `{"text": "Buy milk"}`, "pt-BR", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+			mockTodoService.On("QuickAdd", mock.Anything, uint(1), mock.MatchedBy(func(req *model.QuickAddRequest) bool {
+				return req.Locale == tt.locale
+			})).Return(&model.QuickAddResponse{Todo: &model.Todo{ID: 1}, Interpretation: &model.QuickAddInterpretation{}}, nil)
+
+			w := performQuickAddRequest(h, tt.body, tt.acceptLanguage)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			mockTodoService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQuickAddTodo_InvalidRequests(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		error string
+	}{
+		{"invalid json", `{"text":`, "invalid_request"},
+		{"missing text", `{}`, "validation_failed"},
+		{"text too long", fmt.Sprintf(`{"text": %q}`, strings.Repeat("x", 501)), "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			w := performQuickAddRequest(h, tt.body, "")
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.error)
+			mockTodoService.AssertNotCalled(t, "QuickAdd", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestQuickAddTodo_ServiceErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		error  string
+	}{
+		{service.ErrUnsupportedLocale, http.StatusBadRequest, "unsupported_locale"},
+		{service.ErrInvalidTimezone, http.StatusBadRequest, "invalid_timezone"},
+		{fmt.Errorf("%w: title is required", service.ErrInvalidQuickAdd), http.StatusUnprocessableEntity, "invalid_todo"},
+		{errors.New("database down"), http.StatusInternalServerError, "creation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.error, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+			mockTodoService.On("QuickAdd", mock.Anything, uint(1), mock.Anything).Return(nil, tt.err)
+
+			w := performQuickAddRequest(h, `{"text": "tomorrow"}`, "")
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.error)
+		})
+	}
+}
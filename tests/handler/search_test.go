@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+)
+
+func TestSearchTodos_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	results := []*model.TodoSearchResult{
+		{
+			Todo:           &model.Todo{ID: 1, Title: "Quarterly report", UserID: 1},
+			Rank:           0.6,
+			TitleHighlight: "Quarterly <mark>report</mark>",
+		},
+	}
+	mockTodoService.On("Search", mock.Anything, uint(1), &model.SearchTodosRequest{
+		Query:    "report",
+		Archived: "all",
+		Limit:    10,
+	}).Return(results, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/search?q=report&archived=all&limit=10", nil)
+
+	h.SearchTodos(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.TodoSearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "report", response.Query)
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "Quarterly <mark>report</mark>", response.Results[0].TitleHighlight)
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestSearchTodos_InvalidQuery(t *testing.T) {
+	queries := []string{
+		"",
+		"q=",
+		"q=report&archived=maybe",
+		"q=report&limit=abc",
+		"q=report&limit=1000",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			c := setupTodoTestContext(1)
+			c.Request = httptest.NewRequest(http.MethodGet, "/todos/search?"+query, nil)
+
+			h.SearchTodos(c)
+
+			assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+			mockTodoService.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSearchTodos_EmptyAfterParsing(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Search", mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("search query is empty"))
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/search?q=%26%26", nil)
+
+	h.SearchTodos(c)
+
+	assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+}
+
+func TestSearchTodos_ServiceError(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Search", mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("database error"))
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/search?q=report", nil)
+
+	h.SearchTodos(c)
+
+	assert.Equal(t, http.StatusInternalServerError, c.Writer.Status())
+}
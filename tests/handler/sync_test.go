@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func TestPullChanges_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	expected := &model.SyncResponse{
+		Token:   "next",
+		Todos:   []*model.Todo{{ID: 1, Title: "Updated", UserID: 1, Version: 2}},
+		Deleted: []*model.SyncTombstone{{ID: 2, DeletedAt: time.Now().UTC()}},
+	}
+	mockTodoService.On("PullChanges", mock.Anything, uint(1), "previous").Return(expected, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/sync?since=previous", nil)
+
+	h.PullChanges(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.SyncResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "next", response.Token)
+	assert.Len(t, response.Todos, 1)
+	assert.Equal(t, uint(2), response.Deleted[0].ID)
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestPullChanges_InvalidToken(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("PullChanges", mock.Anything, uint(1), "bogus").Return(nil, service.ErrInvalidSyncToken)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/sync?since=bogus", nil)
+
+	h.PullChanges(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response model.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "invalid_sync_token", response.Error)
+}
+
+func performPushRequest(h interface{ PushChanges(*gin.Context) }, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/sync", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.PushChanges(c)
+	return w
+}
+
+func TestPushChanges_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name           string
+		response       *model.SyncPushResponse
+		expectedStatus int
+	}{
+		{"all applied", &model.SyncPushResponse{Applied: 1}, http.StatusOK},
+		{"conflicts", &model.SyncPushResponse{Conflicts: 1}, http.StatusMultiStatus},
+		{"failures", &model.SyncPushResponse{Failed: 1}, http.StatusMultiStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			mockTodoService.On("PushChanges", mock.Anything, uint(1), mock.AnythingOfType("*model.SyncPushRequest")).Return(tt.response, nil)
+
+			w := performPushRequest(h, []byte(`{"mutations": [{"op": "delete", "id": 1, "base_version": 2, "client_updated_at": "2024-01-01T12:00:00Z"}]}`))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockTodoService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPushChanges_ValidationFailed(t *testing.T) {
+	bodies := []string{
+		`{"mutations": []}`,
+		`{"mutations": [{"op": "archive", "id": 1, "client_updated_at": "2024-01-01T12:00:00Z"}]}`,
+		`{"mutations": [{"op": "delete", "id": 1}]}`,
+		`{"mutations": `,
+	}
+
+	for _, body := range bodies {
+		h, _, mockTodoService := setupTestHandler()
+
+		w := performPushRequest(h, []byte(body))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		mockTodoService.AssertNotCalled(t, "PushChanges", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestPushChanges_ServiceError(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("PushChanges", mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("database down"))
+
+	w := performPushRequest(h, []byte(`{"mutations": [{"op": "create", "title": "Offline", "client_updated_at": "2024-01-01T12:00:00Z"}]}`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockTemplateService is a mock implementation of TemplateService
+type MockTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTemplateService) Create(ctx context.Context, userID uint, req *model.CreateTemplateRequest) (*model.TodoTemplate, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoTemplate), args.Error(1)
+}
+
+func (m *MockTemplateService) List(ctx context.Context, userID uint) ([]*model.TodoTemplate, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoTemplate), args.Error(1)
+}
+
+func (m *MockTemplateService) Get(ctx context.Context, id uint, userID uint) (*model.TodoTemplate, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoTemplate), args.Error(1)
+}
+
+func (m *MockTemplateService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateTemplateRequest) (*model.TodoTemplate, error) {
+	args := m.Called(ctx, id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoTemplate), args.Error(1)
+}
+
+func (m *MockTemplateService) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockTemplateService) Instantiate(ctx context.Context, id uint, userID uint, req *model.InstantiateTemplateRequest) (*model.InstantiateTemplateResponse, error) {
+	args := m.Called(ctx, id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.InstantiateTemplateResponse), args.Error(1)
+}
+
+func setupTemplateTestHandler() (*handler.Handler, *MockTemplateService) {
+	gin.SetMode(gin.TestMode)
+
+	mockTemplateService := &MockTemplateService{}
+	services := &service.Services{
+		Auth:     &MockAuthService{},
+		Todo:     &MockTodoService{},
+		Template: mockTemplateService,
+	}
+
+	return handler.NewHandler(services), mockTemplateService
+}
+
+func performTemplateRequest(method, path, id, body string, handle func(*gin.Context)) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	if id != "" {
+		c.Params = gin.Params{{Key: "id", Value: id}}
+	}
+	c.Request = httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if body != "" {
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+
+	handle(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestCreateTemplate_Success(t *testing.T) {
+	h, mockTemplateService := setupTemplateTestHandler()
+
+	mockTemplateService.On("Create", mock.Anything, uint(1), mock.MatchedBy(func(req *model.CreateTemplateRequest) bool {
+		return req.Name == "Onboarding" && len(req.Items) == 1 && len(req.Items[0].Children) == 1 &&
+			*req.Items[0].Children[0].DueOffsetDays == 3
+	})).Return(&model.TodoTemplate{ID: 5, UserID: 1, Name: "Onboarding", Variables: []string{"name"}}, nil)
+
+	body := `{"name": "Onboarding", "items": [{"title": "Welcome {{name}}", "children": [{"title": "Laptop", "due_offset_days": 3}]}]}`
+	w := performTemplateRequest(http.MethodPost, "/templates", "", body, h.CreateTemplate)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response model.TodoTemplate
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, uint(5), response.ID)
+	assert.Equal(t, []string{"name"}, response.Variables)
+	mockTemplateService.AssertExpectations(t)
+}
+
+func TestCreateTemplate_ValidationFailed(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"missing name", `{"items": [{"title": "Laptop"}]}`, "Name"},
+		{"no items", `{"name": "Onboarding", "items": []}`, "Items"},
+		{"item without title", `{"name": "Onboarding", "items": [{"description": "Laptop"}]}`, "Items[0].Title"},
+		{"nested item without title", `{"name": "Onboarding", "items": [{"title": "Laptop", "children": [{"title": ""}]}]}`, "Items[0].Children[0].Title"},
+		{"offset too large", `{"name": "Onboarding", "items": [{"title": "Laptop", "due_offset_days": 5000}]}`, "Items[0].DueOffsetDays"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockTemplateService := setupTemplateTestHandler()
+
+			w := performTemplateRequest(http.MethodPost, "/templates", "", tt.body, h.CreateTemplate)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response model.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "validation_failed", response.Error)
+			assert.Contains(t, response.Details, tt.field)
+			mockTemplateService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetTemplates(t *testing.T) {
+	h, mockTemplateService := setupTemplateTestHandler()
+
+	mockTemplateService.On("List", mock.Anything, uint(1)).Return([]*model.TodoTemplate{{ID: 1}, {ID: 2}}, nil)
+
+	w := performTemplateRequest(http.MethodGet, "/templates", "", "", h.GetTemplates)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.TemplateListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+}
+
+func TestGetTemplate_InvalidID(t *testing.T) {
+	h, mockTemplateService := setupTemplateTestHandler()
+
+	w := performTemplateRequest(http.MethodGet, "/templates/abc", "abc", "", h.GetTemplate)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_id")
+	mockTemplateService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteTemplate(t *testing.T) {
+	h, mockTemplateService := setupTemplateTestHandler()
+
+	mockTemplateService.On("Delete", mock.Anything, uint(3), uint(1)).Return(nil)
+
+	w := performTemplateRequest(http.MethodDelete, "/templates/3", "3", "", h.DeleteTemplate)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockTemplateService.AssertExpectations(t)
+}
+
+func TestInstantiateTemplate_Success(t *testing.T) {
+	h, mockTemplateService := setupTemplateTestHandler()
+
+	parentID := uint(10)
+	expected := &model.InstantiateTemplateResponse{
+		TemplateID: 3,
+		Todos: []*model.InstantiatedTodo{
+			{Todo: &model.Todo{ID: 10, Title: "Welcome Alice"}},
+			{Todo: &model.Todo{ID: 11, Title: "Laptop"}, ParentID: &parentID, Depth: 1},
+		},
+		Count: 2,
+	}
+	mockTemplateService.On("Instantiate", mock.Anything, uint(3), uint(1), &model.InstantiateTemplateRequest{
+		Variables: map[string]string{"name": "Alice"},
+		StartDate: "2024-01-08",
+	}).Return(expected, nil)
+
+	w := performTemplateRequest(http.MethodPost, "/templates/3/instantiate", "3", `{"variables": {"name": "Alice"}, "start_date": "2024-01-08"}`, h.InstantiateTemplate)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response model.InstantiateTemplateResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	require.NotNil(t, response.Todos[1].ParentID)
+	assert.Equal(t, uint(10), *response.Todos[1].ParentID)
+	mockTemplateService.AssertExpectations(t)
+}
+
+func TestInstantiateTemplate_EmptyBody(t *testing.T) {
+	h, mockTemplateService := setupTemplateTestHandler()
+
+	mockTemplateService.On("Instantiate", mock.Anything, uint(3), uint(1), &model.InstantiateTemplateRequest{}).
+		Return(&model.InstantiateTemplateResponse{TemplateID: 3, Todos: []*model.InstantiatedTodo{}}, nil)
+
+	w := performTemplateRequest(http.MethodPost, "/templates/3/instantiate", "3", "", h.InstantiateTemplate)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockTemplateService.AssertExpectations(t)
+}
+
+func TestInstantiateTemplate_InvalidStartDate(t *testing.T) {
+	h, mockTemplateService := setupTemplateTestHandler()
+
+	w := performTemplateRequest(http.MethodPost, "/templates/3/instantiate", "3", `{"start_date": "next monday"}`, h.InstantiateTemplate)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Details, "StartDate")
+	mockTemplateService.AssertNotCalled(t, "Instantiate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTemplateHandlers_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		error  string
+	}{
+		{service.ErrTemplateNotFound, http.StatusNotFound, "not_found"},
+		{fmt.Errorf("%w: name, team", service.ErrMissingTemplateVariables), http.StatusUnprocessableEntity, "missing_variables"},
+		{fmt.Errorf("%w: item 2: title is required", service.ErrInvalidTemplateTodo), http.StatusUnprocessableEntity, "invalid_todo"},
+		{service.ErrInvalidStartDate, http.StatusBadRequest, "validation_failed"},
+		{assert.AnError, http.StatusInternalServerError, "instantiation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.error, func(t *testing.T) {
+			h, mockTemplateService := setupTemplateTestHandler()
+			mockTemplateService.On("Instantiate", mock.Anything, uint(3), uint(1), mock.Anything).Return(nil, tt.err)
+
+			w := performTemplateRequest(http.MethodPost, "/templates/3/instantiate", "3", `{}`, h.InstantiateTemplate)
+
+			assert.Equal(t, tt.status, w.Code)
+			var response model.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.error, response.Error)
+		})
+	}
+}
+
+func TestUpdateTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		error  string
+	}{
+		{service.ErrTemplateNotFound, http.StatusNotFound, "not_found"},
+		{fmt.Errorf("%w: a template can have at most 200 items", service.ErrTemplateTooLarge), http.StatusBadRequest, "template_too_large"},
+		{assert.AnError, http.StatusInternalServerError, "update_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.error, func(t *testing.T) {
+			h, mockTemplateService := setupTemplateTestHandler()
+			mockTemplateService.On("Update", mock.Anything, uint(3), uint(1), mock.Anything).Return(nil, tt.err)
+
+			w := performTemplateRequest(http.MethodPut, "/templates/3", "3", `{"name": "Onboarding", "items": [{"title": "Laptop"}]}`, h.UpdateTemplate)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.error)
+		})
+	}
+}
@@ -193,7 +193,7 @@ func TestGetTodos_Success(t *testing.T) {
 	}
 	
 	// Setup mock
-	mockTodoService.On("GetByUserID", mock.Anything, uint(1)).Return(expectedTodos, nil)
+	mockTodoService.On("List", mock.Anything, uint(1), &model.TodoFilter{Archived: model.ArchivedExclude}).Return(expectedTodos, nil)
 	
 	// Create request
 	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
@@ -233,7 +233,7 @@ func TestGetTodos_ServiceError(t *testing.T) {
 	h, _, mockTodoService := setupTestHandler()
 	
 	// Setup mock to return error
-	mockTodoService.On("GetByUserID", mock.Anything, uint(1)).Return(nil, errors.New("database error"))
+	mockTodoService.On("List", mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("database error"))
 	
 	// Create request
 	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
@@ -327,11 +327,9 @@ func TestUpdateTodo_Success(t *testing.T) {
 	h, _, mockTodoService := setupTestHandler()
 	
 	// Setup request
-	title := "Updated Todo"
-	completed := true
-	reqBody := model.UpdateTodoRequest{
-		Title:     &title,
-		Completed: &completed,
+	reqBody := model.ReplaceTodoRequest{
+		Title:     "Updated Todo",
+		Completed: true,
 	}
 	
 	expectedTodo := &model.Todo{
@@ -343,7 +341,7 @@ func TestUpdateTodo_Success(t *testing.T) {
 	}
 	
 	// Setup mock
-	mockTodoService.On("Update", mock.Anything, uint(1), &reqBody, uint(1)).Return(expectedTodo, nil)
+	mockTodoService.On("Replace", mock.Anything, uint(1), &reqBody, uint(1)).Return(expectedTodo, nil)
 	
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
@@ -368,9 +366,8 @@ func TestUpdateTodo_InvalidID(t *testing.T) {
 	h, _, _ := setupTestHandler()
 	
 	// Setup request
-	title := "Updated Todo"
-	reqBody := model.UpdateTodoRequest{
-		Title: &title,
+	reqBody := model.ReplaceTodoRequest{
+		Title: "Updated Todo",
 	}
 	
 	// Create request
@@ -394,13 +391,12 @@ func TestUpdateTodo_NotFound(t *testing.T) {
 	h, _, mockTodoService := setupTestHandler()
 	
 	// Setup request
-	title := "Updated Todo"
-	reqBody := model.UpdateTodoRequest{
-		Title: &title,
+	reqBody := model.ReplaceTodoRequest{
+		Title: "Updated Todo",
 	}
 	
 	// Setup mock to return not found error
-	mockTodoService.On("Update", mock.Anything, uint(1), &reqBody, uint(1)).Return(nil, errors.New("todo not found"))
+	mockTodoService.On("Replace", mock.Anything, uint(1), &reqBody, uint(1)).Return(nil, errors.New("todo not found"))
 	
 	// Create request
 	jsonBody, _ := json.Marshal(reqBody)
@@ -421,6 +417,23 @@ func TestUpdateTodo_NotFound(t *testing.T) {
 	mockTodoService.AssertExpectations(t)
 }
 
+func TestUpdateTodo_TitleRequired(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+	
+	// A full replacement must include the title
+	req := httptest.NewRequest(http.MethodPut, "/todos/1", bytes.NewBufferString(`{"completed": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	
+	c := setupTodoTestContext(1)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	
+	h.UpdateTodo(c)
+	
+	assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+	mockTodoService.AssertNotCalled(t, "Replace", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestDeleteTodo_Success(t *testing.T) {
 	h, _, mockTodoService := setupTestHandler()
 	
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/model"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func exportedTodos() []*model.Todo {
+	completedAt := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC)
+	return []*model.Todo{
+		{ID: 1, Title: "Buy milk", Description: "2 litres, \"semi\"", DueAt: &dueAt, UserID: 1, CreatedAt: created, UpdatedAt: created},
+		{ID: 2, Title: "=SUM(A1:A2)", Completed: true, CompletedAt: &completedAt, UserID: 1, CreatedAt: created, UpdatedAt: completedAt},
+	}
+}
+
+func performExportRequest(h interface{ ExportTodos(*gin.Context) }, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/export"+query, nil)
+
+	h.ExportTodos(c)
+	return w
+}
+
+func performImportRequest(h interface{ ImportTodos(*gin.Context) }, query, filename, content string) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if filename != "" {
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write([]byte(content))
+	}
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/import"+query, body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	h.ImportTodos(c)
+	return w
+}
+
+func TestExportTodos_CSV(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+	mockTodoService.On("Export", mock.Anything, uint(1), &model.TodoFilter{Archived: model.ArchivedInclude}).Return(exportedTodos(), nil)
+
+	w := performExportRequest(h, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv\"")
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, model.TransferCSVHeader, records[0])
+	assert.Equal(t, []string{"1", "Buy milk", "2 litres, \"semi\"", "false", "2024-01-05T17:00:00Z", "", "", "2024-01-01T12:00:00Z", "2024-01-01T12:00:00Z"}, records[1])
+	assert.Equal(t, "'=SUM(A1:A2)", records[2][1])
+	assert.Equal(t, "2024-01-02T15:00:00Z", records[2][5])
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestExportTodos_JSONFormats(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		h, _, mockTodoService := setupTestHandler()
+		mockTodoService.On("Export", mock.Anything, uint(1), &model.TodoFilter{Archived: model.ArchivedExclude}).Return(exportedTodos(), nil)
+
+		w := performExportRequest(h, "?format=json&archived=false")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var todos []model.TodoExport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &todos))
+		require.Len(t, todos, 2)
+		assert.Equal(t, "=SUM(A1:A2)", todos[1].Title)
+		assert.NotContains(t, w.Body.String(), "user")
+	})
+
+	t.Run("empty json", func(t *testing.T) {
+		h, _, mockTodoService := setupTestHandler()
+		mockTodoService.On("Export", mock.Anything, uint(1), mock.Anything).Return(nil, nil)
+
+		w := performExportRequest(h, "?format=json")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "[]\n", w.Body.String())
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		h, _, mockTodoService := setupTestHandler()
+		mockTodoService.On("Export", mock.Anything, uint(1), mock.Anything).Return(exportedTodos(), nil)
+
+		w := performExportRequest(h, "?format=ndjson")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var todo model.TodoExport
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &todo))
+		assert.Equal(t, uint(1), todo.ID)
+	})
+}
+
+func TestExportTodos_PlainTextFormats(t *testing.T) {
+	todos := func() []*model.Todo {
+		todos := exportedTodos()
+		todos[0].Title = "Buy milk pri:A"
+		return todos
+	}
+
+	t.Run("todo.txt", func(t *testing.T) {
+		h, _, mockTodoService := setupTestHandler()
+		mockTodoService.On("Export", mock.Anything, uint(1), mock.Anything).Return(todos(), nil)
+
+		w := performExportRequest(h, "?format=txt")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".txt\"")
+		assert.Equal(t, "(A) 2024-01-01 Buy milk due:2024-01-05T17:00:00Z note:2%20litres,%20\"semi\"\n"+
+			"x 2024-01-02 2024-01-01 =SUM(A1:A2)\n", w.Body.String())
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		h, _, mockTodoService := setupTestHandler()
+		mockTodoService.On("Export", mock.Anything, uint(1), mock.Anything).Return(todos(), nil)
+
+		w := performExportRequest(h, "?format=md")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "- [ ] (A) Buy milk due:2024-01-05T17:00:00Z\n  2 litres, \"semi\"\n"+
+			"- [x] =SUM(A1:A2)\n", w.Body.String())
+	})
+}
+
+func TestExportTodos_Errors(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	w := performExportRequest(h, "?format=xml")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unsupported_format")
+
+	w = performExportRequest(h, "?archived=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_filter")
+
+	mockTodoService.On("Export", mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("database down"))
+	w = performExportRequest(h, "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "export_failed")
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestImportTodos_ParsesFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		filename string
+		content  string
+		expected []model.ImportRow
+	}{
+		{
+			name:     "csv with export columns",
+			filename: "todos.csv",
+			content:  "\ufeffid,Title,description,completed,due_at\n7,Buy milk,\"2 litres, semi\",false,2024-01-05\n8,'=SUM(A1),,yes,\n9,Pay rent,,maybe,\n10,File taxes,,,soon\n",
+			expected: []model.ImportRow{
+				{Row: 2, Title: "Buy milk", Description: "2 litres, semi", DueAt: timePtr(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC))},
+				{Row: 3, Title: "=SUM(A1)", Completed: true},
+				{Row: 4, Title: "Pay rent", ParseError: "completed must be true or false"},
+				{Row: 5, Title: "File taxes", ParseError: "due_at must be an RFC 3339 timestamp or a YYYY-MM-DD date"},
+			},
+		},
+		{
+			name:     "json array",
+			filename: "todos.json",
+			content:  `[{"id": 1, "title": "Buy milk", "completed": true}, {"title": 5}]`,
+			expected: []model.ImportRow{
+				{Row: 1, Title: "Buy milk", Completed: true},
+				{Row: 2, ParseError: "row must be a todo object with a string title and description, a boolean completed and an RFC 3339 due_at"},
+			},
+		},
+		{
+			name:     "ndjson from format parameter",
+			query:    "?format=ndjson",
+			filename: "upload.txt",
+			content:  "{\"title\": \"Buy milk\"}\n\n{not json}\n{\"title\": \"Pay rent\", \"description\": \"May\"}\n",
+			expected: []model.ImportRow{
+				{Row: 1, Title: "Buy milk"},
+				{Row: 3, ParseError: "row must be a todo object with a string title and description, a boolean completed and an RFC 3339 due_at"},
+				{Row: 4, Title: "Pay rent", Description: "May"},
+			},
+		},
+		{
+			name:     "csv with dates",
+			filename: "todos.csv",
+			content:  "title,completed,completed_at,created_at\nPay rent,true,2024-01-02T15:00:00Z,2024-01-01\nFile taxes,,,yesterday\n",
+			expected: []model.ImportRow{
+				{Row: 2, Title: "Pay rent", Completed: true, CompletedAt: timePtr(time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)), CreatedAt: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+				{Row: 3, Title: "File taxes", ParseError: "created_at must be an RFC 3339 timestamp or a YYYY-MM-DD date"},
+			},
+		},
+		{
+			name:     "todo.txt",
+			filename: "todo.txt",
+			content:  "(A) 2024-01-01 Call mom +Family due:2024-01-05\n\nx 2024-01-02 2024-01-01 Pay rent pri:B note:May%0Aonline\nFile taxes due:soon\n",
+			expected: []model.ImportRow{
+				{Row: 1, Title: "Call mom +Family pri:A", DueAt: timePtr(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)), CreatedAt: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+				{Row: 3, Title: "Pay rent pri:B", Description: "May\nonline", Completed: true, CompletedAt: timePtr(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)), CreatedAt: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+				{Row: 4, ParseError: `invalid task: due date "soon" must be YYYY-MM-DD or an RFC 3339 timestamp`},
+			},
+		},
+		{
+			name:     "markdown task list",
+			filename: "TODO.md",
+			content:  "# Chores\n\n- [ ] (C) Buy milk\n  2 litres\n- [x] Pay rent\n- not a task\n",
+			expected: []model.ImportRow{
+				{Row: 3, Title: "Buy milk pri:C", Description: "2 litres"},
+				{Row: 5, Title: "Pay rent", Completed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+			expectedReq := &model.ImportRequest{Rows: tt.expected}
+			mockTodoService.On("Import", mock.Anything, uint(1), expectedReq).Return(&model.ImportResponse{Committed: true}, nil)
+
+			w := performImportRequest(h, tt.query, tt.filename, tt.content)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockTodoService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportTodos_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		response       *model.ImportResponse
+		expectedStatus int
+	}{
+		{"all imported", "", &model.ImportResponse{Committed: true, Total: 2, Imported: 2}, http.StatusOK},
+		{"some skipped", "", &model.ImportResponse{Committed: true, Total: 2, Imported: 1, Failed: 1}, http.StatusMultiStatus},
+		{"atomic rejected", "?atomic=true", &model.ImportResponse{Atomic: true, Total: 2, Failed: 1}, http.StatusUnprocessableEntity},
+		{"dry run with errors", "?dry_run=1", &model.ImportResponse{DryRun: true, Total: 2, Imported: 1, Failed: 1}, http.StatusMultiStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+			mockTodoService.On("Import", mock.Anything, uint(1), mock.MatchedBy(func(req *model.ImportRequest) bool {
+				return req.DryRun == tt.response.DryRun && req.Atomic == tt.response.Atomic
+			})).Return(tt.response, nil)
+
+			w := performImportRequest(h, tt.query, "todos.csv", "title\nOne\nTwo\n")
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response model.ImportResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.response.Imported, response.Imported)
+			mockTodoService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportTodos_InvalidRequests(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		filename      string
+		content       string
+		expectedError string
+	}{
+		{"missing file", "", "", "", "invalid_request"},
+		{"invalid dry_run", "?dry_run=sometimes", "todos.csv", "title\nOne\n", "invalid_request"},
+		{"unknown format", "", "todos.xlsx", "title\nOne\n", "unsupported_format"},
+		{"csv without title column", "", "todos.csv", "name\nOne\n", "invalid_file"},
+		{"empty csv", "", "todos.csv", "", "invalid_file"},
+		{"json object instead of array", "", "todos.json", `{"title": "One"}`, "invalid_file"},
+		{"truncated json array", "", "todos.json", `[{"title": "One"},`, "invalid_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+
+			w := performImportRequest(h, tt.query, tt.filename, tt.content)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedError)
+			mockTodoService.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestImportTodos_FileTooLarge(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	content := "title\n" + strings.Repeat("a long todo title\n", (10<<20)/18+1)
+	w := performImportRequest(h, "", "todos.csv", content)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	mockTodoService.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportTodos_ServiceErrors(t *testing.T) {
+	tests := []struct {
+		err            error
+		expectedStatus int
+		expectedError  string
+	}{
+		{errors.New("too many import rows"), http.StatusBadRequest, "too_many_rows"},
+		{errors.New("database down"), http.StatusInternalServerError, "import_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedError, func(t *testing.T) {
+			h, _, mockTodoService := setupTestHandler()
+			mockTodoService.On("Import", mock.Anything, uint(1), mock.Anything).Return(nil, tt.err)
+
+			w := performImportRequest(h, "", "todos.csv", "title\nOne\n")
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedError)
+		})
+	}
+}
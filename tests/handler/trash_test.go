@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+)
+
+func TestGetTrash_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	trashed := []*model.Todo{{ID: 1, Title: "Deleted Todo", UserID: 1}}
+	mockTodoService.On("GetTrash", mock.Anything, uint(1)).Return(trashed, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/todos/trash", nil)
+
+	h.GetTrash(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.TodoListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestRestoreTodo_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	restored := &model.Todo{ID: 1, Title: "Restored Todo", UserID: 1}
+	mockTodoService.On("Restore", mock.Anything, uint(1), uint(1)).Return(restored, nil)
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/1/restore", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.RestoreTodo(c)
+
+	assert.Equal(t, http.StatusOK, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestRestoreTodo_NotFound(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Restore", mock.Anything, uint(1), uint(1)).Return(nil, errors.New("todo not found"))
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/1/restore", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.RestoreTodo(c)
+
+	assert.Equal(t, http.StatusNotFound, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestRestoreTodo_InvalidID(t *testing.T) {
+	h, _, _ := setupTestHandler()
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/todos/invalid/restore", nil)
+	c.Params = gin.Params{{Key: "id", Value: "invalid"}}
+
+	h.RestoreTodo(c)
+
+	assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+}
+
+func TestPurgeTodo_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Purge", mock.Anything, uint(1), uint(1)).Return(nil)
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/todos/trash/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.PurgeTodo(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestPurgeTodo_NotFound(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("Purge", mock.Anything, uint(1), uint(1)).Return(errors.New("todo not found"))
+
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/todos/trash/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.PurgeTodo(c)
+
+	assert.Equal(t, http.StatusNotFound, c.Writer.Status())
+
+	mockTodoService.AssertExpectations(t)
+}
+
+func TestEmptyTrash_Success(t *testing.T) {
+	h, _, mockTodoService := setupTestHandler()
+
+	mockTodoService.On("EmptyTrash", mock.Anything, uint(1)).Return(int64(2), nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodDelete, "/todos/trash", nil)
+
+	h.EmptyTrash(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"purged":2`)
+
+	mockTodoService.AssertExpectations(t)
+}
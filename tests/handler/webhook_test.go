@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockWebhookService is a mock implementation of WebhookService
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Enqueue(ctx context.Context, userID uint, eventType string, todoID uint, todo *model.Todo) error {
+	return m.Called(ctx, userID, eventType, todoID, todo).Error(0)
+}
+
+func (m *MockWebhookService) NotifyMention(ctx context.Context, userID uint, todo *model.Todo, comment *model.TodoComment) error {
+	return m.Called(ctx, userID, todo, comment).Error(0)
+}
+
+func (m *MockWebhookService) Create(ctx context.Context, userID uint, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) List(ctx context.Context, userID uint) ([]*model.Webhook, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Get(ctx context.Context, id uint, userID uint) (*model.Webhook, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Update(ctx context.Context, id uint, userID uint, req *model.UpdateWebhookRequest) (*model.Webhook, error) {
+	args := m.Called(ctx, id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, id uint, userID uint, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, id, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func setupWebhookTestHandler() (*handler.Handler, *MockWebhookService) {
+	gin.SetMode(gin.TestMode)
+
+	mockWebhookService := &MockWebhookService{}
+	services := &service.Services{
+		Auth:    &MockAuthService{},
+		Todo:    &MockTodoService{},
+		Webhook: mockWebhookService,
+	}
+
+	return handler.NewHandler(services), mockWebhookService
+}
+
+func TestCreateWebhook_Success(t *testing.T) {
+	h, mockWebhookService := setupWebhookTestHandler()
+
+	reqBody := model.CreateWebhookRequest{URL: "https://bot.example.com/hooks", Events: []string{model.WebhookEventTodoCompleted}}
+	mockWebhookService.On("Create", mock.Anything, uint(1), &reqBody).Return(&model.Webhook{
+		ID: 3, UserID: 1, URL: reqBody.URL, Events: reqBody.Events, Secret: "whsec_abc", Active: true,
+	}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	c := setupTodoTestContext(1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.CreateWebhook(c)
+
+	assert.Equal(t, http.StatusCreated, c.Writer.Status())
+	mockWebhookService.AssertExpectations(t)
+}
+
+func TestCreateWebhook_ReturnsSecretOnce(t *testing.T) {
+	h, mockWebhookService := setupWebhookTestHandler()
+
+	webhook := &model.Webhook{ID: 3, UserID: 1, URL: "https://bot.example.com/hooks", Events: []string{model.WebhookEventTodoCreated}, Secret: "whsec_abc", Active: true}
+	mockWebhookService.On("Create", mock.Anything, uint(1), mock.Anything).Return(webhook, nil)
+	mockWebhookService.On("Get", mock.Anything, uint(3), uint(1)).Return(webhook, nil)
+
+	body, _ := json.Marshal(model.CreateWebhookRequest{URL: webhook.URL, Events: webhook.Events})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Request = httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.CreateWebhook(c)
+
+	var created map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "whsec_abc", created["secret"])
+	assert.Equal(t, float64(3), created["id"])
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/webhooks/3", nil)
+
+	h.GetWebhook(c)
+
+	var fetched map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, fetched, "secret")
+}
+
+func TestCreateWebhook_ValidationFailed(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  map[string]interface{}
+		field string
+	}{
+		{"missing url", map[string]interface{}{"events": []string{"todo.created"}}, "URL"},
+		{"invalid url", map[string]interface{}{"url": "not a url", "events": []string{"todo.created"}}, "URL"},
+		{"no events", map[string]interface{}{"url": "https://bot.example.com", "events": []string{}}, "Events"},
+		{"unknown event", map[string]interface{}{"url": "https://bot.example.com", "events": []string{"todo.exploded"}}, "Events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockWebhookService := setupWebhookTestHandler()
+
+			body, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", uint(1))
+			c.Request = httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.CreateWebhook(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response model.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "validation_failed", response.Error)
+			assert.Contains(t, response.Details, tt.field)
+			mockWebhookService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWebhookHandlers_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedError  string
+	}{
+		{"not found", service.ErrWebhookNotFound, http.StatusNotFound, "not_found"},
+		{"invalid url", service.ErrInvalidWebhookURL, http.StatusBadRequest, "validation_failed"},
+		{"limit reached", service.ErrWebhookLimit, http.StatusConflict, "webhook_limit_reached"},
+		{"internal error", assert.AnError, http.StatusInternalServerError, "update_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockWebhookService := setupWebhookTestHandler()
+
+			mockWebhookService.On("Update", mock.Anything, uint(3), uint(1), mock.Anything).Return(nil, tt.serviceErr)
+
+			body, _ := json.Marshal(model.UpdateWebhookRequest{URL: "https://bot.example.com", Events: []string{"todo.created"}, Active: true})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", uint(1))
+			c.Params = gin.Params{{Key: "id", Value: "3"}}
+			c.Request = httptest.NewRequest(http.MethodPut, "/webhooks/3", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.UpdateWebhook(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response model.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedError, response.Error)
+		})
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	h, mockWebhookService := setupWebhookTestHandler()
+
+	mockWebhookService.On("Delete", mock.Anything, uint(3), uint(1)).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/webhooks/3", nil)
+
+	h.DeleteWebhook(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	mockWebhookService.AssertExpectations(t)
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	h, mockWebhookService := setupWebhookTestHandler()
+
+	deliveries := []*model.WebhookDelivery{
+		{ID: 2, WebhookID: 3, EventType: model.WebhookEventTodoUpdated, Status: model.WebhookDeliveryFailed, Attempts: 8, ResponseCode: 503, LastError: "unexpected status 503"},
+		{ID: 1, WebhookID: 3, EventType: model.WebhookEventTodoCreated, Status: model.WebhookDeliverySucceeded, Attempts: 1, ResponseCode: 200},
+	}
+	mockWebhookService.On("ListDeliveries", mock.Anything, uint(3), uint(1), 20).Return(deliveries, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/webhooks/3/deliveries?limit=20", nil)
+
+	h.GetWebhookDeliveries(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.WebhookDeliveriesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, "unexpected status 503", response.Deliveries[0].LastError)
+}
+
+func TestGetWebhookDeliveries_InvalidLimit(t *testing.T) {
+	h, mockWebhookService := setupWebhookTestHandler()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/webhooks/3/deliveries?limit=-1", nil)
+
+	h.GetWebhookDeliveries(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockWebhookService.AssertNotCalled(t, "ListDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
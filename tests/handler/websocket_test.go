@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"todo-api-backend/internal/events"
+	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/realtime"
+	"todo-api-backend/internal/service"
+)
+
+// startLiveServer serves the WebSocket handler, authenticating every request as user 1
+func startLiveServer(t *testing.T) (*httptest.Server, *events.Bus) {
+	bus := events.NewBus(10)
+	h := handler.NewHandler(&service.Services{Events: bus, Broker: realtime.NewMemoryBroker()}, handler.WithHeartbeat(0))
+
+	router := gin.New()
+	router.GET("/ws", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		h.Live(c)
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, bus
+}
+
+func dialLive(t *testing.T, server *httptest.Server) *websocket.Conn {
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func sendLive(t *testing.T, ws *websocket.Conn, msg realtime.ClientMessage) {
+	require.NoError(t, websocket.JSON.Send(ws, msg))
+}
+
+func receiveLive(t *testing.T, ws *websocket.Conn) realtime.ServerMessage {
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	var msg realtime.ServerMessage
+	require.NoError(t, websocket.JSON.Receive(ws, &msg))
+	return msg
+}
+
+func TestLive_DeliversTodoEvents(t *testing.T) {
+	server, bus := startLiveServer(t)
+	ws := dialLive(t, server)
+
+	sendLive(t, ws, realtime.ClientMessage{Type: realtime.TypeSubscribe, List: "user:1"})
+	msg := receiveLive(t, ws)
+	assert.Equal(t, realtime.TypeSubscribed, msg.Type)
+	assert.Equal(t, "user:1", msg.List)
+
+	bus.Publish(events.Event{Type: events.TypeTodoCreated, UserID: 2, TodoID: 7})
+	bus.Publish(events.Event{Type: events.TypeTodoCreated, UserID: 1, TodoID: 1})
+
+	msg = receiveLive(t, ws)
+	assert.Equal(t, realtime.TypeEvent, msg.Type)
+	assert.Equal(t, "user:1", msg.List)
+	require.NotNil(t, msg.Event)
+	assert.Equal(t, events.TypeTodoCreated, msg.Event.Type)
+	assert.Equal(t, uint(1), msg.Event.TodoID)
+}
+
+func TestLive_RejectsOtherUsersLists(t *testing.T) {
+	server, _ := startLiveServer(t)
+	ws := dialLive(t, server)
+
+	sendLive(t, ws, realtime.ClientMessage{Type: realtime.TypeSubscribe, List: "user:2"})
+	msg := receiveLive(t, ws)
+	assert.Equal(t, realtime.TypeError, msg.Type)
+	assert.Equal(t, "forbidden", msg.Error)
+
+	sendLive(t, ws, realtime.ClientMessage{Type: realtime.TypeTyping, List: "user:2", TodoID: 1, Typing: true})
+	msg = receiveLive(t, ws)
+	assert.Equal(t, realtime.TypeError, msg.Type)
+	assert.Equal(t, "not_subscribed", msg.Error)
+}
+
+func TestLive_RelaysPresenceAndTyping(t *testing.T) {
+	server, _ := startLiveServer(t)
+	first := dialLive(t, server)
+	second := dialLive(t, server)
+
+	sendLive(t, first, realtime.ClientMessage{Type: realtime.TypeSubscribe, List: "user:1"})
+	require.Equal(t, realtime.TypeSubscribed, receiveLive(t, first).Type)
+
+	sendLive(t, second, realtime.ClientMessage{Type: realtime.TypeSubscribe, List: "user:1"})
+	require.Equal(t, realtime.TypeSubscribed, receiveLive(t, second).Type)
+
+	msg := receiveLive(t, first)
+	assert.Equal(t, realtime.TypePresence, msg.Type)
+	assert.Equal(t, realtime.PresenceOnline, msg.Status)
+	assert.Equal(t, uint(1), msg.UserID)
+
+	sendLive(t, second, realtime.ClientMessage{Type: realtime.TypeTyping, List: "user:1", TodoID: 3, Typing: true})
+	msg = receiveLive(t, first)
+	assert.Equal(t, realtime.TypeTyping, msg.Type)
+	assert.Equal(t, uint(3), msg.TodoID)
+	require.NotNil(t, msg.Typing)
+	assert.True(t, *msg.Typing)
+
+	// The sender does not receive its own indicators
+	sendLive(t, second, realtime.ClientMessage{Type: realtime.TypePing})
+	assert.Equal(t, realtime.TypePong, receiveLive(t, second).Type)
+
+	second.Close()
+	msg = receiveLive(t, first)
+	assert.Equal(t, realtime.TypePresence, msg.Type)
+	assert.Equal(t, realtime.PresenceOffline, msg.Status)
+}
+
+func TestLive_RejectsInvalidMessages(t *testing.T) {
+	server, _ := startLiveServer(t)
+	ws := dialLive(t, server)
+
+	require.NoError(t, websocket.Message.Send(ws, "not json"))
+	msg := receiveLive(t, ws)
+	assert.Equal(t, realtime.TypeError, msg.Type)
+	assert.Equal(t, "invalid_message", msg.Error)
+
+	sendLive(t, ws, realtime.ClientMessage{Type: "shout"})
+	msg = receiveLive(t, ws)
+	assert.Equal(t, "invalid_message", msg.Error)
+}
+
+func TestLive_Unauthorized(t *testing.T) {
+	h := handler.NewHandler(&service.Services{Events: events.NewBus(10), Broker: realtime.NewMemoryBroker()})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/ws", nil)
+
+	h.Live(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -2,11 +2,15 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,11 +22,15 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"todo-api-backend/internal/database"
 	"todo-api-backend/internal/handler"
+	"todo-api-backend/internal/jobs"
 	"todo-api-backend/internal/middleware"
 	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/realtime"
 	"todo-api-backend/internal/repository"
 	"todo-api-backend/internal/service"
+	"todo-api-backend/internal/storage"
 	"todo-api-backend/pkg/jwt"
 )
 
@@ -50,8 +58,16 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 	// Setup repositories
 	repos := repository.NewRepositories(suite.db)
 
-	// Setup services
-	services := service.NewServices(repos, suite.tokenManager)
+	// Setup services with attachments kept in a temporary directory
+	blobStore, err := storage.NewLocalStore(suite.T().TempDir())
+	require.NoError(suite.T(), err)
+	services := service.NewServices(repos, suite.tokenManager, service.WithAttachments(blobStore, service.AttachmentLimits{
+		MaxSize: 1 << 20,
+		Quota:   2 << 20,
+	}))
+
+	// Create test user and token (the test user is the administrator)
+	suite.createTestUser()
 
 	// Setup handlers
 	h := handler.NewHandler(services)
@@ -68,22 +84,125 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 		auth.POST("/login", h.Login)
 	}
 
+	// Calendar feed subscriptions (authenticated by the token in the URL)
+	suite.router.GET("/calendar/:token", h.ServeCalendarFeed)
+
+	// Attachment downloads (authenticated by the signature in the URL)
+	suite.router.GET("/api/v1/attachments/:id/download", h.DownloadAttachment)
+
+	// CalDAV server (authenticated with app passwords)
+	h.RegisterCalDAVRoutes(suite.router, h.CalDAVAuth(middleware.WithSecurityAuditor(services.Audit)))
+
 	// Protected routes (with JWT middleware)
 	api := suite.router.Group("/api")
-	api.Use(middleware.AuthMiddleware(suite.tokenManager))
+	api.Use(middleware.AuthMiddleware(suite.tokenManager, middleware.WithSecurityAuditor(services.Audit)))
+	api.Use(middleware.IdempotencyMiddleware(services.Idempotency, h.MaxRequestBodySize()))
 	{
+		api.PUT("/auth/password", h.ChangePassword)
+		api.GET("/events", h.StreamEvents)
+		api.GET("/sync", h.PullChanges)
+		api.POST("/sync", h.PushChanges)
+
+		calendar := api.Group("/calendar")
+		{
+			calendar.GET("/feed", h.GetCalendarFeed)
+			calendar.POST("/feed", h.RegenerateCalendarToken)
+			calendar.DELETE("/feed", h.DisableCalendarFeed)
+		}
+
+		caldav := api.Group("/caldav")
+		{
+			caldav.GET("/passwords", h.GetCalDAVPasswords)
+			caldav.POST("/passwords", h.CreateCalDAVPassword)
+			caldav.DELETE("/passwords/:id", h.DeleteCalDAVPassword)
+		}
+
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("", h.CreateWebhook)
+			webhooks.GET("", h.GetWebhooks)
+			webhooks.GET("/:id", h.GetWebhook)
+			webhooks.PUT("/:id", h.UpdateWebhook)
+			webhooks.DELETE("/:id", h.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", h.GetWebhookDeliveries)
+		}
+
+		templates := api.Group("/templates")
+		{
+			templates.POST("", h.CreateTemplate)
+			templates.GET("", h.GetTemplates)
+			templates.GET("/:id", h.GetTemplate)
+			templates.PUT("/:id", h.UpdateTemplate)
+			templates.DELETE("/:id", h.DeleteTemplate)
+			templates.POST("/:id/instantiate", h.InstantiateTemplate)
+		}
+
+		lists := api.Group("/lists")
+		{
+			lists.POST("", h.CreateList)
+			lists.GET("", h.GetLists)
+			lists.GET("/:id", h.GetList)
+			lists.PUT("/:id", h.UpdateList)
+			lists.DELETE("/:id", h.DeleteList)
+			lists.GET("/:id/board", h.GetListBoard)
+		}
+
+		customFields := api.Group("/custom-fields")
+		{
+			customFields.POST("", h.CreateCustomField)
+			customFields.GET("", h.GetCustomFields)
+			customFields.GET("/:id", h.GetCustomField)
+			customFields.PUT("/:id", h.UpdateCustomField)
+			customFields.DELETE("/:id", h.DeleteCustomField)
+		}
+
+		admin := api.Group("/admin")
+		admin.Use(middleware.AdminMiddleware([]uint{suite.testUser.ID}))
+		{
+			admin.GET("/audit-log", h.GetSecurityEvents)
+		}
+
 		todos := api.Group("/todos")
 		{
 			todos.POST("", h.CreateTodo)
 			todos.GET("", h.GetTodos)
+			todos.GET("/search", h.SearchTodos)
+			todos.POST("/bulk", h.BulkTodos)
+			todos.POST("/quick", h.QuickAddTodo)
+			todos.GET("/export", h.ExportTodos)
+			todos.POST("/import", h.ImportTodos)
+			todos.GET("/trash", h.GetTrash)
+			todos.DELETE("/trash", h.EmptyTrash)
+			todos.DELETE("/trash/:id", h.PurgeTodo)
+			todos.POST("/archive", h.ArchiveCompletedTodos)
+			todos.GET("/dependencies", h.GetDependencyGraph)
+			todos.GET("/archive/policy", h.GetArchivePolicy)
+			todos.PUT("/archive/policy", h.UpdateArchivePolicy)
 			todos.GET("/:id", h.GetTodo)
 			todos.PUT("/:id", h.UpdateTodo)
+			todos.PATCH("/:id", h.PatchTodo)
 			todos.DELETE("/:id", h.DeleteTodo)
+			todos.POST("/:id/restore", h.RestoreTodo)
+			todos.POST("/:id/archive", h.ArchiveTodo)
+			todos.POST("/:id/unarchive", h.UnarchiveTodo)
+			todos.GET("/:id/history", h.GetTodoHistory)
+			todos.GET("/:id/dependencies", h.GetTodoDependencies)
+			todos.POST("/:id/dependencies", h.AddTodoDependency)
+			todos.DELETE("/:id/dependencies/:other_id", h.RemoveTodoDependency)
+			todos.POST("/:id/move", h.MoveTodo)
+			todos.GET("/:id/comments", h.GetTodoComments)
+			todos.POST("/:id/comments", h.AddTodoComment)
+			todos.PUT("/:id/comments/:comment_id", h.UpdateTodoComment)
+			todos.DELETE("/:id/comments/:comment_id", h.DeleteTodoComment)
+			todos.POST("/:id/attachments", h.UploadTodoAttachment)
+			todos.GET("/:id/attachments", h.GetTodoAttachments)
+			todos.GET("/:id/attachments/:attachment_id", h.GetTodoAttachment)
+			todos.DELETE("/:id/attachments/:attachment_id", h.DeleteTodoAttachment)
 		}
 	}
 
-	// Create test user and token
-	suite.createTestUser()
+	// Live collaboration WebSocket (JWT may be passed as a query parameter)
+	suite.router.GET("/api/ws", middleware.AuthMiddleware(suite.tokenManager, middleware.WithSecurityAuditor(services.Audit), middleware.WithQueryToken("access_token")), h.Live)
 }
 
 // setupTestDatabase initializes the test database connection
@@ -101,7 +220,7 @@ func (suite *IntegrationTestSuite) setupTestDatabase() {
 	require.NoError(suite.T(), err, "Failed to connect to test database")
 
 	// Auto-migrate the schema
-	err = suite.db.AutoMigrate(&model.User{}, &model.Todo{})
+	err = database.AutoMigrate(suite.db)
 	require.NoError(suite.T(), err, "Failed to migrate test database")
 }
 
@@ -123,7 +242,18 @@ func (suite *IntegrationTestSuite) createTestUser() {
 // TearDownSuite runs once after all tests in the suite
 func (suite *IntegrationTestSuite) TearDownSuite() {
 	// Clean up test data
+	suite.db.Exec("DELETE FROM caldav_resources")
+	suite.db.Exec("DELETE FROM todo_comments")
+	suite.db.Exec("DELETE FROM todo_attachments")
+	suite.db.Exec("DELETE FROM todo_dependencies")
 	suite.db.Exec("DELETE FROM todos")
+	suite.db.Exec("DELETE FROM custom_fields")
+	suite.db.Exec("DELETE FROM todo_lists")
+	suite.db.Exec("DELETE FROM idempotency_keys")
+	suite.db.Exec("DELETE FROM outbox_messages")
+	suite.db.Exec("DELETE FROM calendar_feeds")
+	suite.db.Exec("DELETE FROM caldav_passwords")
+	suite.db.Exec("DELETE FROM todo_templates")
 	suite.db.Exec("DELETE FROM users")
 
 	// Close database connection
@@ -136,7 +266,8 @@ func (suite *IntegrationTestSuite) TearDownSuite() {
 // SetupTest runs before each test
 func (suite *IntegrationTestSuite) SetupTest() {
 	// Clean todos table before each test (keep test user)
-	suite.db.Where("user_id = ?", suite.testUser.ID).Delete(&model.Todo{})
+	suite.db.Unscoped().Where("user_id = ?", suite.testUser.ID).Delete(&model.Todo{})
+	suite.db.Where("user_id = ?", suite.testUser.ID).Delete(&model.IdempotencyRecord{})
 }
 
 // TestAuthenticationFlow tests the complete authentication flow
@@ -601,7 +732,7 @@ func (suite *IntegrationTestSuite) TestDatabaseInteractions() {
 			Title:  "Orphaned Todo",
 			UserID: 99999, // Non-existent user ID
 		}
-		
+
 		err := suite.db.Create(todo).Error
 		// This should fail due to foreign key constraint
 		assert.Error(suite.T(), err)
@@ -610,17 +741,17 @@ func (suite *IntegrationTestSuite) TestDatabaseInteractions() {
 	suite.Run("Database transaction rollback", func() {
 		// Start a transaction
 		tx := suite.db.Begin()
-		
+
 		// Create a user in transaction
 		user := &model.User{
 			Email:    "transaction@example.com",
 			Password: "hashedpassword",
 		}
 		tx.Create(user)
-		
+
 		// Rollback the transaction
 		tx.Rollback()
-		
+
 		// Verify user was not created
 		var count int64
 		suite.db.Model(&model.User{}).Where("email = ?", "transaction@example.com").Count(&count)
@@ -799,7 +930,7 @@ func (suite *IntegrationTestSuite) TestCompleteWorkflows() {
 
 		json.Unmarshal(w.Body.Bytes(), &todos)
 		assert.Len(suite.T(), todos, 2) // One deleted
-		
+
 		// Find the updated todo
 		var updatedTodo *model.Todo
 		for _, todo := range todos {
@@ -814,7 +945,1218 @@ func (suite *IntegrationTestSuite) TestCompleteWorkflows() {
 	})
 }
 
+// TestTrashWorkflow tests soft deletion, restore and purge of todos
+func (suite *IntegrationTestSuite) TestTrashWorkflow() {
+	todo := &model.Todo{Title: "Trash me", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(todo).Error)
+
+	suite.Run("Deleted todo moves to trash", func() {
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/todos/%d", todo.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), http.StatusNoContent, w.Code)
+
+		req = httptest.NewRequest("GET", "/api/todos/trash", nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w = httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+		var response model.TodoListResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(suite.T(), 1, response.Count)
+		assert.Equal(suite.T(), todo.ID, response.Todos[0].ID)
+	})
+
+	suite.Run("Restore trashed todo", func() {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/todos/%d/restore", todo.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+		req = httptest.NewRequest("GET", fmt.Sprintf("/api/todos/%d", todo.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w = httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+	})
+
+	suite.Run("Purge trashed todo", func() {
+		require.NoError(suite.T(), suite.db.Delete(&model.Todo{}, todo.ID).Error)
+
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/todos/trash/%d", todo.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), http.StatusNoContent, w.Code)
+
+		var count int64
+		suite.db.Unscoped().Model(&model.Todo{}).Where("id = ?", todo.ID).Count(&count)
+		assert.Equal(suite.T(), int64(0), count)
+	})
+
+	suite.Run("Restore todo that is not in trash", func() {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/todos/%d/restore", todo.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	})
+}
+
+// TestArchiveWorkflow tests archiving completed todos and the archived listing filter
+func (suite *IntegrationTestSuite) TestArchiveWorkflow() {
+	completedAt := time.Now().AddDate(0, 0, -10)
+	oldTodo := &model.Todo{Title: "Old completed", Completed: true, CompletedAt: &completedAt, UserID: suite.testUser.ID}
+	activeTodo := &model.Todo{Title: "Still active", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(oldTodo).Error)
+	require.NoError(suite.T(), suite.db.Create(activeTodo).Error)
+
+	listTodos := func(query string) model.TodoListResponse {
+		req := httptest.NewRequest("GET", "/api/todos"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		require.Equal(suite.T(), http.StatusOK, w.Code)
+
+		var response model.TodoListResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	suite.Run("Archive completed todos older than N days", func() {
+		body, _ := json.Marshal(map[string]interface{}{"older_than_days": 7})
+		req := httptest.NewRequest("POST", "/api/todos/archive", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+		var response model.ArchiveResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(suite.T(), int64(1), response.Archived)
+	})
+
+	suite.Run("Default listing excludes archived todos", func() {
+		response := listTodos("")
+		assert.Equal(suite.T(), 1, response.Count)
+		assert.Equal(suite.T(), activeTodo.ID, response.Todos[0].ID)
+	})
+
+	suite.Run("Archived filter returns archived todos", func() {
+		response := listTodos("?archived=true")
+		assert.Equal(suite.T(), 1, response.Count)
+		assert.Equal(suite.T(), oldTodo.ID, response.Todos[0].ID)
+
+		assert.Equal(suite.T(), 2, listTodos("?archived=all").Count)
+	})
+}
+
+// TestSearchWorkflow tests full-text search over todos
+func (suite *IntegrationTestSuite) TestSearchWorkflow() {
+	report := &model.Todo{Title: "Quarterly report", Description: "Send the quarterly report to Bob", UserID: suite.testUser.ID}
+	groceries := &model.Todo{Title: "Groceries", Description: "Buy milk and a <b>report</b> folder", UserID: suite.testUser.ID}
+	reporting := &model.Todo{Title: "Reporting pipeline", Description: "Fix the nightly job", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(report).Error)
+	require.NoError(suite.T(), suite.db.Create(groceries).Error)
+	require.NoError(suite.T(), suite.db.Create(reporting).Error)
+
+	search := func(query string) model.TodoSearchResponse {
+		req := httptest.NewRequest("GET", "/api/todos/search?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		require.Equal(suite.T(), http.StatusOK, w.Code)
+
+		var response model.TodoSearchResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	suite.Run("Title matches rank first and are highlighted", func() {
+		response := search("q=report")
+		require.Equal(suite.T(), 3, response.Count)
+		assert.Equal(suite.T(), report.ID, response.Results[0].Todo.ID)
+		assert.Contains(suite.T(), response.Results[0].TitleHighlight, "<mark>")
+	})
+
+	suite.Run("Snippets are HTML-escaped", func() {
+		response := search("q=folder")
+		require.Equal(suite.T(), 1, response.Count)
+		assert.Contains(suite.T(), response.Results[0].Snippet, "&lt;b&gt;")
+		assert.Contains(suite.T(), response.Results[0].Snippet, "<mark>folder</mark>")
+	})
+
+	suite.Run("Phrase query", func() {
+		response := search("q=%22quarterly+report%22")
+		require.Equal(suite.T(), 1, response.Count)
+		assert.Equal(suite.T(), report.ID, response.Results[0].Todo.ID)
+	})
+
+	suite.Run("Prefix query", func() {
+		response := search("q=pipe*")
+		require.Equal(suite.T(), 1, response.Count)
+		assert.Equal(suite.T(), reporting.ID, response.Results[0].Todo.ID)
+	})
+
+	suite.Run("Empty query is rejected", func() {
+		req := httptest.NewRequest("GET", "/api/todos/search?q=%22%22", nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	})
+}
+
+// TestPatchWorkflow tests merge patch, JSON patch and full replacement semantics
+func (suite *IntegrationTestSuite) TestPatchWorkflow() {
+	todo := &model.Todo{Title: "Patch me", Description: "Some notes", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(todo).Error)
+
+	send := func(method, contentType, body string) (int, model.Todo) {
+		req := httptest.NewRequest(method, fmt.Sprintf("/api/todos/%d", todo.ID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		var response model.Todo
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	suite.Run("Merge patch clears the description", func() {
+		code, response := send("PATCH", "application/merge-patch+json", `{"description": null, "completed": true}`)
+
+		assert.Equal(suite.T(), http.StatusOK, code)
+		assert.Equal(suite.T(), "Patch me", response.Title)
+		assert.Empty(suite.T(), response.Description)
+		assert.True(suite.T(), response.Completed)
+	})
+
+	suite.Run("JSON patch test failure changes nothing", func() {
+		code, _ := send("PATCH", "application/json-patch+json", `[
+			{"op": "replace", "path": "/title", "value": "Changed"},
+			{"op": "test", "path": "/completed", "value": false}
+		]`)
+
+		assert.Equal(suite.T(), http.StatusConflict, code)
+
+		var reloaded model.Todo
+		require.NoError(suite.T(), suite.db.First(&reloaded, todo.ID).Error)
+		assert.Equal(suite.T(), "Patch me", reloaded.Title)
+	})
+
+	suite.Run("PUT replaces the whole todo", func() {
+		code, response := send("PUT", "application/json", `{"title": "Replaced"}`)
+
+		assert.Equal(suite.T(), http.StatusOK, code)
+		assert.Equal(suite.T(), "Replaced", response.Title)
+		assert.False(suite.T(), response.Completed)
+	})
+}
+
+// TestIdempotencyWorkflow tests that retried requests with an Idempotency-Key are not applied twice
+func (suite *IntegrationTestSuite) TestIdempotencyWorkflow() {
+	create := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/todos", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		req.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	suite.Run("Retry returns the original todo", func() {
+		first := create("retry-key", `{"title": "Only once"}`)
+		retry := create("retry-key", `{"title": "Only once"}`)
+
+		require.Equal(suite.T(), http.StatusCreated, first.Code)
+		assert.Equal(suite.T(), http.StatusCreated, retry.Code)
+		assert.Equal(suite.T(), first.Body.String(), retry.Body.String())
+		assert.Equal(suite.T(), "true", retry.Header().Get("Idempotent-Replayed"))
+
+		var count int64
+		suite.db.Model(&model.Todo{}).Where("title = ?", "Only once").Count(&count)
+		assert.Equal(suite.T(), int64(1), count)
+	})
+
+	suite.Run("Key reused with a different body", func() {
+		w := create("retry-key", `{"title": "Something else"}`)
+		assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+// TestConcurrencyWorkflow tests ETags and conditional requests on a todo
+func (suite *IntegrationTestSuite) TestConcurrencyWorkflow() {
+	todo := &model.Todo{Title: "Shared todo", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(todo).Error)
+
+	send := func(method string, headers map[string]string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, fmt.Sprintf("/api/todos/%d", todo.ID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", nil, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(suite.T(), `"1"`, etag)
+
+	suite.Run("Unchanged todo is not modified", func() {
+		w := send("GET", map[string]string{"If-None-Match": etag}, "")
+		assert.Equal(suite.T(), http.StatusNotModified, w.Code)
+	})
+
+	suite.Run("First writer wins, second gets 412", func() {
+		first := send("PUT", map[string]string{"If-Match": etag}, `{"title": "First writer"}`)
+		require.Equal(suite.T(), http.StatusOK, first.Code)
+		assert.Equal(suite.T(), `"2"`, first.Header().Get("ETag"))
+
+		second := send("PUT", map[string]string{"If-Match": etag}, `{"title": "Second writer"}`)
+		assert.Equal(suite.T(), http.StatusPreconditionFailed, second.Code)
+
+		var reloaded model.Todo
+		require.NoError(suite.T(), suite.db.First(&reloaded, todo.ID).Error)
+		assert.Equal(suite.T(), "First writer", reloaded.Title)
+		assert.Equal(suite.T(), uint(2), reloaded.Version)
+	})
+
+	suite.Run("Stale cached copy is refetched", func() {
+		w := send("GET", map[string]string{"If-None-Match": etag}, "")
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+	})
+}
+
+// TestBulkWorkflow tests partial and atomic bulk operations against the database
+func (suite *IntegrationTestSuite) TestBulkWorkflow() {
+	first := &model.Todo{Title: "First", UserID: suite.testUser.ID}
+	second := &model.Todo{Title: "Second", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(first).Error)
+	require.NoError(suite.T(), suite.db.Create(second).Error)
+
+	bulk := func(body string) (int, model.BulkResponse) {
+		req := httptest.NewRequest("POST", "/api/todos/bulk", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		var response model.BulkResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	suite.Run("Atomic failure leaves nothing applied", func() {
+		code, response := bulk(fmt.Sprintf(`{"atomic": true, "operations": [
+			{"op": "create", "title": "Never saved"},
+			{"op": "complete", "id": %d},
+			{"op": "delete", "id": 999999}
+		]}`, first.ID))
+
+		assert.Equal(suite.T(), http.StatusUnprocessableEntity, code)
+		assert.False(suite.T(), response.Committed)
+
+		var count int64
+		suite.db.Model(&model.Todo{}).Where("title = ?", "Never saved").Count(&count)
+		assert.Equal(suite.T(), int64(0), count)
+
+		var reloaded model.Todo
+		require.NoError(suite.T(), suite.db.First(&reloaded, first.ID).Error)
+		assert.False(suite.T(), reloaded.Completed)
+	})
+
+	suite.Run("Partial mode applies the items that succeed", func() {
+		code, response := bulk(fmt.Sprintf(`{"operations": [
+			{"op": "complete", "ids": [%d, %d]},
+			{"op": "delete", "id": 999999}
+		]}`, first.ID, second.ID))
+
+		assert.Equal(suite.T(), http.StatusMultiStatus, code)
+		assert.True(suite.T(), response.Committed)
+		assert.Equal(suite.T(), 2, response.Succeeded)
+		assert.Equal(suite.T(), 1, response.Failed)
+
+		var completed int64
+		suite.db.Model(&model.Todo{}).Where("user_id = ? AND completed = ?", suite.testUser.ID, true).Count(&completed)
+		assert.Equal(suite.T(), int64(2), completed)
+	})
+}
+
+// TestQuickAddWorkflow tests creating a todo from a line of text
+func (suite *IntegrationTestSuite) TestQuickAddWorkflow() {
+	body := `{"text": "Call Bob tomorrow 3pm !high #sales", "timezone": "Europe/Berlin"}`
+	req := httptest.NewRequest("POST", "/api/todos/quick", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.testToken)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response model.QuickAddResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "Call Bob", response.Interpretation.Title)
+	assert.Equal(suite.T(), []string{"sales"}, response.Interpretation.Tags)
+
+	var stored model.Todo
+	require.NoError(suite.T(), suite.db.First(&stored, response.Todo.ID).Error)
+	assert.Equal(suite.T(), "Call Bob #sales pri:A", stored.Title)
+	require.NotNil(suite.T(), stored.DueAt)
+	assert.True(suite.T(), stored.DueAt.Equal(*response.Interpretation.DueAt))
+}
+
+// TestTemplateWorkflow tests creating a template and instantiating it
+func (suite *IntegrationTestSuite) TestTemplateWorkflow() {
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/templates", `{"name": "Onboarding", "items": [{"title": "Welcome {{name}}", "due_offset_days": 0, "children": [{"title": "Laptop for {{name}}", "due_offset_days": 2}]}, {"title": "First review"}]}`)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var template model.TodoTemplate
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &template))
+	assert.Equal(suite.T(), []string{"name"}, template.Variables)
+
+	path := fmt.Sprintf("/api/templates/%d/instantiate", template.ID)
+	w = send("POST", path, `{}`)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+
+	w = send("POST", path, `{"variables": {"name": "Alice"}, "start_date": "2024-01-08"}`)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response model.InstantiateTemplateResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(suite.T(), 3, response.Count)
+	assert.Equal(suite.T(), response.Todos[0].Todo.ID, *response.Todos[1].ParentID)
+
+	// The parent waits for its subtask
+	w = send("GET", fmt.Sprintf("/api/todos/%d/dependencies", response.Todos[0].Todo.ID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var dependencies model.TodoDependenciesResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &dependencies))
+	assert.True(suite.T(), dependencies.Blocked)
+	require.Len(suite.T(), dependencies.BlockedBy, 1)
+	assert.Equal(suite.T(), response.Todos[1].Todo.ID, dependencies.BlockedBy[0].ID)
+
+	var stored model.Todo
+	require.NoError(suite.T(), suite.db.First(&stored, response.Todos[1].Todo.ID).Error)
+	assert.Equal(suite.T(), "Laptop for Alice", stored.Title)
+	require.NotNil(suite.T(), stored.DueAt)
+	assert.True(suite.T(), stored.DueAt.Equal(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)))
+}
+
+// TestDependencyWorkflow tests relating todos, rejecting cycles and ordering the graph
+func (suite *IntegrationTestSuite) TestDependencyWorkflow() {
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	create := func(title string) uint {
+		w := send("POST", "/api/todos", fmt.Sprintf(`{"title": %q}`, title))
+		require.Equal(suite.T(), http.StatusCreated, w.Code)
+		var todo model.Todo
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &todo))
+		return todo.ID
+	}
+
+	design, build, ship := create("Design"), create("Build"), create("Ship")
+
+	w := send("POST", fmt.Sprintf("/api/todos/%d/dependencies", build), fmt.Sprintf(`{"blocked_by": %d}`, design))
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	w = send("POST", fmt.Sprintf("/api/todos/%d/dependencies", build), fmt.Sprintf(`{"blocks": %d}`, ship))
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+
+	// Design cannot wait for Ship, which already waits for Design
+	w = send("POST", fmt.Sprintf("/api/todos/%d/dependencies", design), fmt.Sprintf(`{"blocked_by": %d}`, ship))
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), fmt.Sprintf("%d -> %d -> %d -> %d", design, ship, build, design))
+
+	w = send("GET", fmt.Sprintf("/api/todos/%d", build), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var todo model.Todo
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &todo))
+	assert.True(suite.T(), todo.Blocked)
+
+	w = send("PUT", fmt.Sprintf("/api/todos/%d", design), `{"completed": true}`)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = send("GET", fmt.Sprintf("/api/todos/%d/dependencies", build), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var dependencies model.TodoDependenciesResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &dependencies))
+	assert.False(suite.T(), dependencies.Blocked)
+	require.Len(suite.T(), dependencies.Blocks, 1)
+	assert.True(suite.T(), dependencies.Blocks[0].Blocked)
+
+	w = send("GET", "/api/todos/dependencies", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var graph model.DependencyGraphResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &graph))
+	assert.Equal(suite.T(), []uint{design, build, ship}, graph.Order)
+	assert.Len(suite.T(), graph.Edges, 2)
+
+	w = send("DELETE", fmt.Sprintf("/api/todos/%d/dependencies/%d", ship, build), "")
+	assert.Equal(suite.T(), http.StatusNoContent, w.Code)
+	w = send("DELETE", fmt.Sprintf("/api/todos/%d/dependencies/%d", ship, build), "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	// Purging a todo removes its dependencies
+	w = send("DELETE", fmt.Sprintf("/api/todos/%d", design), "")
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	w = send("DELETE", fmt.Sprintf("/api/todos/trash/%d", design), "")
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	var edges int64
+	require.NoError(suite.T(), suite.db.Model(&model.TodoDependency{}).Where("blocked_by_id = ?", design).Count(&edges).Error)
+	assert.Zero(suite.T(), edges)
+}
+
+// TestListWorkflow tests moving todos of a list through its workflow and grouping them on a board
+func (suite *IntegrationTestSuite) TestListWorkflow() {
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/lists", `{"name": "Sprint", "workflow": {"states": [{"key": "backlog", "name": "Backlog"}, {"key": "doing", "name": "In Progress"}, {"key": "review", "name": "Review"}, {"key": "done", "name": "Done", "terminal": true}], "transitions": [{"from": "backlog", "to": "doing"}, {"from": "doing", "to": "review"}, {"from": "review", "to": "doing"}, {"from": "review", "to": "done"}]}}`)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var list model.TodoList
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &list))
+
+	w = send("POST", "/api/todos", fmt.Sprintf(`{"title": "Write spec", "list_id": %d}`, list.ID))
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var todo model.Todo
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &todo))
+	assert.Equal(suite.T(), "backlog", todo.State)
+
+	// Completing a todo in the backlog skips the workflow
+	path := fmt.Sprintf("/api/todos/%d", todo.ID)
+	w = send("PUT", path, `{"title": "Write spec", "completed": true}`)
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	w = send("POST", path+"/move", fmt.Sprintf(`{"list_id": %d, "state": "doing"}`, list.ID))
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	req := httptest.NewRequest("PATCH", path, bytes.NewBufferString(`{"state": "review"}`))
+	req.Header.Set("Content-Type", model.PatchTypeMerge)
+	req.Header.Set("Authorization", "Bearer "+suite.testToken)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = send("PUT", path, `{"title": "Write spec", "completed": true}`)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &todo))
+	assert.Equal(suite.T(), "done", todo.State)
+	assert.True(suite.T(), todo.Completed)
+
+	// The state of the todo must survive workflow changes
+	w = send("PUT", fmt.Sprintf("/api/lists/%d", list.ID), `{"workflow": {"states": [{"key": "backlog", "name": "Backlog"}, {"key": "shipped", "name": "Shipped", "terminal": true}]}}`)
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	w = send("GET", fmt.Sprintf("/api/lists/%d/board", list.ID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var board model.BoardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &board))
+	require.Len(suite.T(), board.Columns, 4)
+	assert.Equal(suite.T(), 1, board.Columns[3].Count)
+	assert.Equal(suite.T(), todo.ID, board.Columns[3].Todos[0].ID)
+
+	w = send("DELETE", fmt.Sprintf("/api/lists/%d", list.ID), "")
+	assert.Equal(suite.T(), http.StatusNoContent, w.Code)
+	var stored model.Todo
+	require.NoError(suite.T(), suite.db.First(&stored, todo.ID).Error)
+	assert.Nil(suite.T(), stored.ListID)
+	assert.True(suite.T(), stored.Completed)
+}
+
+// TestCustomFieldWorkflow tests defining custom fields, setting their values and filtering by them
+func (suite *IntegrationTestSuite) TestCustomFieldWorkflow() {
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/custom-fields", `{"key": "points", "name": "Story points", "type": "number", "rules": {"min": 1, "max": 13}}`)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var points model.CustomField
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &points))
+	w = send("POST", "/api/custom-fields", `{"key": "customer", "name": "Customer", "type": "text"}`)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var customer model.CustomField
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &customer))
+	w = send("POST", "/api/custom-fields", `{"key": "points", "name": "Points", "type": "number"}`)
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	for _, body := range []string{
+		`{"title": "Small", "custom_fields": {"points": 2, "customer": "Acme"}}`,
+		`{"title": "Large", "custom_fields": {"points": 8, "customer": "Acme"}}`,
+		`{"title": "Medium", "custom_fields": {"points": 5, "customer": "Globex"}}`,
+	} {
+		w = send("POST", "/api/todos", body)
+		require.Equal(suite.T(), http.StatusCreated, w.Code)
+	}
+	w = send("POST", "/api/todos", `{"title": "Huge", "custom_fields": {"points": 21}}`)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "custom_fields.points must be at most 13")
+
+	list := func(query string) []string {
+		w := send("GET", "/api/todos?"+query, "")
+		require.Equal(suite.T(), http.StatusOK, w.Code)
+		var response model.TodoListResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		titles := make([]string, 0, len(response.Todos))
+		for _, todo := range response.Todos {
+			titles = append(titles, todo.Title)
+		}
+		return titles
+	}
+	assert.Equal(suite.T(), []string{"Large", "Small"}, list("cf[customer]=Acme&sort=-cf.points"))
+	assert.Equal(suite.T(), []string{"Medium", "Large"}, list("cf_gte[points]=3&cf_lte[points]=10&sort=cf.points"))
+	assert.Equal(suite.T(), []string{"Large"}, list("cf[points]=8"))
+
+	w = send("GET", "/api/todos?cf[colour]=red", "")
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	// Deleting a field removes its values from every todo
+	w = send("DELETE", fmt.Sprintf("/api/custom-fields/%d", points.ID), "")
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	var stored model.Todo
+	require.NoError(suite.T(), suite.db.Where("title = ?", "Large").First(&stored).Error)
+	assert.Equal(suite.T(), model.CustomFieldValues{"customer": "Acme"}, stored.CustomFields)
+
+	w = send("DELETE", fmt.Sprintf("/api/custom-fields/%d", customer.ID), "")
+	assert.Equal(suite.T(), http.StatusNoContent, w.Code)
+}
+
+func (suite *IntegrationTestSuite) TestCommentWorkflow() {
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	comment := func(todoID uint, body string) model.TodoComment {
+		w := send("POST", fmt.Sprintf("/api/todos/%d/comments", todoID), body)
+		require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+		var comment model.TodoComment
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &comment))
+		return comment
+	}
+
+	w := send("POST", "/api/todos", `{"title": "Fix login bug"}`)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	var todo model.Todo
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &todo))
+
+	// Only users with access to the todo are mentioned
+	first := comment(todo.ID, `{"body": "Screenshot attached, @test@example.com and @nobody@example.com"}`)
+	require.Len(suite.T(), first.Mentions, 1)
+	assert.Equal(suite.T(), suite.testUser.ID, first.Mentions[0].UserID)
+
+	reply := comment(todo.ID, fmt.Sprintf(`{"body": "On it", "parent_id": %d}`, first.ID))
+	nested := comment(todo.ID, fmt.Sprintf(`{"body": "Done", "parent_id": %d}`, reply.ID))
+	require.NotNil(suite.T(), nested.ParentID)
+	assert.Equal(suite.T(), first.ID, *nested.ParentID)
+	second := comment(todo.ID, `{"body": "Also broken on **mobile**"}`)
+
+	w = send("GET", fmt.Sprintf("/api/todos/%d/comments?per_page=1", todo.ID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var page model.CommentPage
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(suite.T(), int64(2), page.Total)
+	require.Len(suite.T(), page.Comments, 1)
+	assert.Equal(suite.T(), first.ID, page.Comments[0].ID)
+	assert.Len(suite.T(), page.Comments[0].Replies, 2)
+
+	w = send("PUT", fmt.Sprintf("/api/todos/%d/comments/%d", todo.ID, second.ID), `{"body": "Also broken on tablets"}`)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"edited":true`)
+
+	// Deleting a thread removes its replies
+	w = send("DELETE", fmt.Sprintf("/api/todos/%d/comments/%d", todo.ID, first.ID), "")
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	w = send("DELETE", fmt.Sprintf("/api/todos/%d/comments/%d", todo.ID, reply.ID), "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = send("GET", fmt.Sprintf("/api/todos/%d/comments?page=1", todo.ID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(suite.T(), int64(1), page.Total)
+	require.Len(suite.T(), page.Comments, 1)
+	assert.Equal(suite.T(), "Also broken on tablets", page.Comments[0].Body)
+
+	// Purging the todo removes its comments
+	w = send("DELETE", fmt.Sprintf("/api/todos/%d", todo.ID), "")
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	w = send("DELETE", fmt.Sprintf("/api/todos/trash/%d", todo.ID), "")
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	var comments int64
+	require.NoError(suite.T(), suite.db.Model(&model.TodoComment{}).Where("todo_id = ?", todo.ID).Count(&comments).Error)
+	assert.Zero(suite.T(), comments)
+}
+
+// TestAttachmentWorkflow tests uploading, downloading and deleting attachments
+func (suite *IntegrationTestSuite) TestAttachmentWorkflow() {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 512<<10-8)...)
+	upload := func(todoID uint, filename string, content []byte) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", filename)
+		require.NoError(suite.T(), err)
+		part.Write(content)
+		require.NoError(suite.T(), writer.Close())
+
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/todos/%d/attachments", todoID), body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	send := func(method, path string, authenticated bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if authenticated {
+			req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		}
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	todo := &model.Todo{Title: "Fix login bug", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(todo).Error)
+
+	w := upload(todo.ID, "C:\\Users\\me\\login-error.png", png)
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var attachment model.Attachment
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &attachment))
+	assert.Equal(suite.T(), "login-error.png", attachment.Filename)
+	assert.Equal(suite.T(), "image/png", attachment.ContentType)
+	assert.Equal(suite.T(), int64(len(png)), attachment.Size)
+	require.NotEmpty(suite.T(), attachment.DownloadURL)
+
+	// The signed URL works without a JWT, but not once tampered with
+	w = send("GET", attachment.DownloadURL, false)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), png, w.Body.Bytes())
+	assert.Equal(suite.T(), "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), `attachment; filename=login-error.png`, w.Header().Get("Content-Disposition"))
+	assert.Equal(suite.T(), "nosniff", w.Header().Get("X-Content-Type-Options"))
+	w = send("GET", strings.Replace(attachment.DownloadURL, "signature=", "signature=00", 1), false)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	// Executables are rejected whatever they are called
+	w = upload(todo.ID, "screenshot.png", append([]byte("MZ\x90\x00"), bytes.Repeat([]byte{1}, 100)...))
+	assert.Equal(suite.T(), http.StatusUnsupportedMediaType, w.Code)
+
+	w = upload(todo.ID, "huge.png", append(png, png...))
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, w.Code)
+
+	// The quota of 2 MB fits three more files of 512 KB only
+	for i := 0; i < 3; i++ {
+		w = upload(todo.ID, "more.png", png)
+		require.Equal(suite.T(), http.StatusCreated, w.Code)
+	}
+	w = upload(todo.ID, "more.png", png)
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	w = send("GET", fmt.Sprintf("/api/todos/%d/attachments", todo.ID), true)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var list model.AttachmentListResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(suite.T(), 4, list.Count)
+	assert.Equal(suite.T(), int64(4*len(png)), list.UsedBytes)
+	assert.Equal(suite.T(), int64(2<<20), list.QuotaBytes)
+
+	// Deleting an attachment frees its quota and its download URL stops working
+	w = send("DELETE", fmt.Sprintf("/api/todos/%d/attachments/%d", todo.ID, attachment.ID), true)
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	w = send("GET", attachment.DownloadURL, false)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	w = upload(todo.ID, "again.png", png)
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+}
+
+// TestTransferWorkflow tests importing todos from a file and exporting them again
+func (suite *IntegrationTestSuite) TestTransferWorkflow() {
+	importFile := func(query, filename, content string) (int, model.ImportResponse) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", filename)
+		require.NoError(suite.T(), err)
+		part.Write([]byte(content))
+		require.NoError(suite.T(), writer.Close())
+
+		req := httptest.NewRequest("POST", "/api/todos/import"+query, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		var response model.ImportResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	csvFile := "title,description,completed\nBuy milk,Semi-skimmed,false\n,Missing title,false\nPay rent,,true\n"
+
+	suite.Run("Dry run reports errors without importing", func() {
+		code, response := importFile("?dry_run=true", "todos.csv", csvFile)
+
+		assert.Equal(suite.T(), http.StatusMultiStatus, code)
+		assert.False(suite.T(), response.Committed)
+		assert.Equal(suite.T(), 2, response.Imported)
+		require.Len(suite.T(), response.Errors, 1)
+		assert.Equal(suite.T(), 3, response.Errors[0].Row)
+
+		var count int64
+		suite.db.Model(&model.Todo{}).Where("user_id = ?", suite.testUser.ID).Count(&count)
+		assert.Equal(suite.T(), int64(0), count)
+	})
+
+	suite.Run("Import creates the valid rows", func() {
+		code, response := importFile("", "todos.csv", csvFile)
+
+		assert.Equal(suite.T(), http.StatusMultiStatus, code)
+		assert.True(suite.T(), response.Committed)
+		assert.Equal(suite.T(), 2, response.Imported)
+
+		var completed model.Todo
+		require.NoError(suite.T(), suite.db.Where("user_id = ? AND title = ?", suite.testUser.ID, "Pay rent").First(&completed).Error)
+		assert.True(suite.T(), completed.Completed)
+		assert.NotNil(suite.T(), completed.CompletedAt)
+	})
+
+	suite.Run("Export streams every todo", func() {
+		req := httptest.NewRequest("GET", "/api/todos/export?format=ndjson", nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(suite.T(), lines, 2)
+
+		var first model.TodoExport
+		require.NoError(suite.T(), json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(suite.T(), "Buy milk", first.Title)
+	})
+}
+
+// TestCalendarFeedWorkflow tests subscribing to the calendar feed with its secret token
+func (suite *IntegrationTestSuite) TestCalendarFeedWorkflow() {
+	due := time.Now().Add(48 * time.Hour)
+	suite.db.Create(&model.Todo{Title: "File taxes", UserID: suite.testUser.ID, DueAt: &due})
+	suite.db.Create(&model.Todo{Title: "Someday", UserID: suite.testUser.ID})
+
+	req := httptest.NewRequest("POST", "/api/calendar/feed", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.testToken)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+
+	var feed model.CalendarFeedResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &feed))
+	require.NotEmpty(suite.T(), feed.Token)
+
+	fetch := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	feedPath := "/calendar/" + feed.Token + ".ics"
+
+	var etag string
+	suite.Run("Feed is served without a JWT", func() {
+		w := fetch(feedPath, "")
+
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+		assert.Contains(suite.T(), w.Body.String(), "SUMMARY:File taxes")
+		assert.NotContains(suite.T(), w.Body.String(), "Someday")
+		etag = w.Header().Get("ETag")
+		assert.NotEmpty(suite.T(), etag)
+	})
+
+	suite.Run("Unchanged feed is not modified", func() {
+		assert.Equal(suite.T(), http.StatusNotModified, fetch(feedPath, etag).Code)
+	})
+
+	suite.Run("Changing a todo changes the feed", func() {
+		suite.db.Model(&model.Todo{}).Where("user_id = ? AND title = ?", suite.testUser.ID, "Someday").
+			Update("updated_at", time.Now().Add(time.Minute))
+
+		assert.Equal(suite.T(), http.StatusOK, fetch(feedPath, etag).Code)
+	})
+
+	suite.Run("Regenerating the token revokes the old URL", func() {
+		req := httptest.NewRequest("POST", "/api/calendar/feed", nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		require.Equal(suite.T(), http.StatusCreated, w.Code)
+
+		assert.Equal(suite.T(), http.StatusNotFound, fetch(feedPath, "").Code)
+	})
+}
+
+// TestCalDAVWorkflow tests syncing todos with a task app over CalDAV using an app password
+func (suite *IntegrationTestSuite) TestCalDAVWorkflow() {
+	req := httptest.NewRequest("POST", "/api/caldav/passwords", bytes.NewBufferString(`{"name": "Phone"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.testToken)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+
+	var password model.CalDAVPasswordResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &password))
+	require.NotEmpty(suite.T(), password.Password)
+
+	dav := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.SetBasicAuth(suite.testUser.Email, password.Password)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	vtodo := func(status string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//EN\r\nBEGIN:VTODO\r\n" +
+			"UID:phone-1@example.com\r\nSUMMARY:Water plants\r\nSTATUS:" + status + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	}
+	objectPath := "/caldav/todos/phone-1.ics"
+
+	suite.Run("Wrong password is rejected", func() {
+		req := httptest.NewRequest("PROPFIND", "/caldav/todos/", nil)
+		req.SetBasicAuth(suite.testUser.Email, "wrong")
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+		assert.Contains(suite.T(), w.Header().Get("WWW-Authenticate"), "Basic")
+	})
+
+	var etag string
+	suite.Run("Task app creates a todo", func() {
+		w := dav("PUT", objectPath, vtodo("NEEDS-ACTION"), map[string]string{"If-None-Match": "*"})
+
+		require.Equal(suite.T(), http.StatusCreated, w.Code)
+		etag = w.Header().Get("ETag")
+		assert.NotEmpty(suite.T(), etag)
+
+		var todo model.Todo
+		require.NoError(suite.T(), suite.db.Where("user_id = ? AND title = ?", suite.testUser.ID, "Water plants").First(&todo).Error)
+		assert.False(suite.T(), todo.Completed)
+	})
+
+	suite.Run("Todo is listed and served with its UID", func() {
+		w := dav("REPORT", "/caldav/todos/", `<?xml version="1.0"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter>
+</c:calendar-query>`, map[string]string{"Depth": "1"})
+
+		assert.Equal(suite.T(), http.StatusMultiStatus, w.Code)
+		assert.Contains(suite.T(), w.Body.String(), objectPath)
+		assert.Contains(suite.T(), w.Body.String(), "UID:phone-1@example.com")
+	})
+
+	suite.Run("Completing in the task app completes the todo", func() {
+		w := dav("PUT", objectPath, vtodo("COMPLETED"), map[string]string{"If-Match": etag})
+		require.Equal(suite.T(), http.StatusNoContent, w.Code)
+
+		var todo model.Todo
+		require.NoError(suite.T(), suite.db.Where("user_id = ? AND title = ?", suite.testUser.ID, "Water plants").First(&todo).Error)
+		assert.True(suite.T(), todo.Completed)
+	})
+
+	suite.Run("Stale ETag is rejected", func() {
+		w := dav("PUT", objectPath, vtodo("NEEDS-ACTION"), map[string]string{"If-Match": etag})
+		assert.Equal(suite.T(), http.StatusPreconditionFailed, w.Code)
+	})
+
+	suite.Run("Deleting moves the todo to the trash", func() {
+		assert.Equal(suite.T(), http.StatusNoContent, dav("DELETE", objectPath, "", nil).Code)
+		assert.Equal(suite.T(), http.StatusNotFound, dav("GET", objectPath, "", nil).Code)
+	})
+}
+
+// TestSyncWorkflow tests pulling changes with sync tokens and pushing offline mutations
+func (suite *IntegrationTestSuite) TestSyncWorkflow() {
+	kept := &model.Todo{Title: "Kept", UserID: suite.testUser.ID}
+	removed := &model.Todo{Title: "Removed", UserID: suite.testUser.ID}
+	require.NoError(suite.T(), suite.db.Create(kept).Error)
+	require.NoError(suite.T(), suite.db.Create(removed).Error)
+
+	pull := func(since string) model.SyncResponse {
+		req := httptest.NewRequest("GET", "/api/sync?since="+since, nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		require.Equal(suite.T(), http.StatusOK, w.Code)
+
+		var response model.SyncResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	push := func(body string) (int, model.SyncPushResponse) {
+		req := httptest.NewRequest("POST", "/api/sync", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		var response model.SyncPushResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	initial := pull("")
+	assert.True(suite.T(), initial.Reset)
+	assert.Len(suite.T(), initial.Todos, 2)
+
+	code, response := push(fmt.Sprintf(`{"mutations": [
+		{"op": "create", "client_id": "local-1", "title": "Offline", "client_updated_at": "2024-01-01T12:00:00Z"},
+		{"op": "update", "id": %d, "base_version": 1, "completed": true, "client_updated_at": "2024-01-01T12:00:00Z"},
+		{"op": "delete", "id": %d, "base_version": 1, "client_updated_at": "2024-01-01T12:00:00Z"},
+		{"op": "update", "id": %d, "base_version": 1, "title": "Stale", "client_updated_at": "2024-01-01T12:00:00Z"}
+	]}`, kept.ID, removed.ID, kept.ID))
+
+	assert.Equal(suite.T(), http.StatusMultiStatus, code)
+	assert.Equal(suite.T(), 3, response.Applied)
+	assert.Equal(suite.T(), 1, response.Conflicts)
+	assert.Equal(suite.T(), model.SyncConflictVersion, response.Results[3].Reason)
+	assert.Equal(suite.T(), uint(2), response.Results[3].Todo.Version)
+
+	changes := pull(initial.Token)
+	assert.False(suite.T(), changes.Reset)
+
+	changed := make(map[uint]bool)
+	for _, todo := range changes.Todos {
+		changed[todo.ID] = true
+	}
+	assert.True(suite.T(), changed[kept.ID])
+	assert.True(suite.T(), changed[response.Results[0].ID])
+
+	require.Len(suite.T(), changes.Deleted, 1)
+	assert.Equal(suite.T(), removed.ID, changes.Deleted[0].ID)
+
+	req := httptest.NewRequest("GET", "/api/sync?since=bogus", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.testToken)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+// TestSecurityAuditLog tests that authentication events are recorded and queryable
+func (suite *IntegrationTestSuite) TestSecurityAuditLog() {
+	queryAuditLog := func(query string) model.SecurityEventsResponse {
+		req := httptest.NewRequest("GET", "/api/admin/audit-log"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+suite.testToken)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		require.Equal(suite.T(), http.StatusOK, w.Code)
+
+		var response model.SecurityEventsResponse
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	suite.Run("Failed login is recorded", func() {
+		body, _ := json.Marshal(map[string]string{"email": "test@example.com", "password": "wrong-password"})
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "integration-test")
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		require.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+		response := queryAuditLog(fmt.Sprintf("?event=login&outcome=failure&user_id=%d", suite.testUser.ID))
+		require.NotZero(suite.T(), response.Count)
+		assert.Equal(suite.T(), "invalid_password", response.Events[0].Reason)
+		assert.Equal(suite.T(), "integration-test", response.Events[0].UserAgent)
+	})
+
+	suite.Run("Rejected token is recorded", func() {
+		req := httptest.NewRequest("GET", "/api/todos", nil)
+		req.Header.Set("Authorization", "Bearer not-a-valid-token")
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		require.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+		response := queryAuditLog("?event=token_validation")
+		require.NotZero(suite.T(), response.Count)
+		assert.Equal(suite.T(), model.SecurityOutcomeFailure, response.Events[0].Outcome)
+	})
+
+	suite.Run("Non-admin users cannot query the audit log", func() {
+		token, err := suite.tokenManager.GenerateToken(suite.testUser.ID+1000, "someone@example.com")
+		require.NoError(suite.T(), err)
+
+		req := httptest.NewRequest("GET", "/api/admin/audit-log", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	})
+}
+
+// TestRealtimeBrokerWorkflow tests fan-out through Postgres LISTEN/NOTIFY
+func (suite *IntegrationTestSuite) TestRealtimeBrokerWorkflow() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Two brokers stand in for two server instances
+	publisher := realtime.NewPostgresBroker(suite.db, os.Getenv("TEST_DATABASE_URL"))
+	receiver := realtime.NewPostgresBroker(suite.db, os.Getenv("TEST_DATABASE_URL"))
+	go receiver.Start(ctx)
+
+	sub := receiver.Subscribe("list:user:1")
+	defer sub.Close()
+
+	suite.Run("Message reaches the other instance", func() {
+		msg := realtime.Message{Topic: "list:user:1", Type: realtime.TypeTyping, Origin: "session", UserID: 1, Data: json.RawMessage(`{"type":"typing"}`)}
+
+		// The listener connects asynchronously, so publish until it is delivered
+		deadline := time.After(5 * time.Second)
+		for {
+			require.NoError(suite.T(), publisher.Publish(ctx, msg))
+			select {
+			case received := <-sub.Messages():
+				assert.Equal(suite.T(), msg.Type, received.Type)
+				assert.Equal(suite.T(), msg.UserID, received.UserID)
+				assert.JSONEq(suite.T(), string(msg.Data), string(received.Data))
+				return
+			case <-time.After(100 * time.Millisecond):
+			case <-deadline:
+				suite.T().Fatal("message was not delivered")
+			}
+		}
+	})
+
+	suite.Run("Oversized message is rejected", func() {
+		big := realtime.Message{Topic: "list:user:1", Type: realtime.TypeTyping, Data: json.RawMessage(`"` + string(bytes.Repeat([]byte("x"), 8000)) + `"`)}
+		assert.ErrorIs(suite.T(), publisher.Publish(ctx, big), realtime.ErrMessageTooLarge)
+	})
+}
+
+// recordingPublisher records the domain events published by the outbox relay
+type recordingPublisher struct {
+	events []string
+	fail   bool
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.events = append(p.events, fmt.Sprintf("%s/%d", message.EventType, message.AggregateID))
+	return nil
+}
+
+// TestOutboxWorkflow tests that domain events are committed with their change and relayed in order
+func (suite *IntegrationTestSuite) TestOutboxWorkflow() {
+	ctx := context.Background()
+	repos := repository.NewRepositories(suite.db)
+	todoService := service.NewTodoService(repos.Todo, repos.User, service.WithTransactor(repos.Tx), service.WithOutbox(repos.Outbox))
+	suite.db.Exec("DELETE FROM outbox_messages")
+
+	first, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "First"}, suite.testUser.ID)
+	require.NoError(suite.T(), err)
+	second, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Second"}, suite.testUser.ID)
+	require.NoError(suite.T(), err)
+	completed := true
+	_, err = todoService.Update(ctx, first.ID, &model.UpdateTodoRequest{Completed: &completed}, suite.testUser.ID)
+	require.NoError(suite.T(), err)
+
+	suite.Run("Failed publish holds back later events of the aggregate", func() {
+		publisher := &recordingPublisher{fail: true}
+		published, err := jobs.NewOutboxRelay(repos.Outbox, publisher, time.Second).RunOnce(ctx)
+		require.NoError(suite.T(), err)
+		assert.Zero(suite.T(), published)
+
+		var pending []model.OutboxMessage
+		suite.db.Where("published_at IS NULL").Order("id").Find(&pending)
+		require.Len(suite.T(), pending, 3)
+		assert.Equal(suite.T(), 1, pending[0].Attempts)
+		assert.Equal(suite.T(), 1, pending[1].Attempts)
+		assert.Zero(suite.T(), pending[2].Attempts, "todo.updated waits for todo.created of the same todo")
+
+		// Make the failed messages due again
+		suite.db.Model(&model.OutboxMessage{}).Where("published_at IS NULL").Update("next_attempt_at", time.Now().Add(-time.Second))
+	})
+
+	suite.Run("Events are published once and in order per aggregate", func() {
+		publisher := &recordingPublisher{}
+		relay := jobs.NewOutboxRelay(repos.Outbox, publisher, time.Second)
+
+		published, err := relay.RunOnce(ctx)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), 3, published)
+		assert.Equal(suite.T(), []string{
+			fmt.Sprintf("todo.created/%d", first.ID),
+			fmt.Sprintf("todo.created/%d", second.ID),
+			fmt.Sprintf("todo.updated/%d", first.ID),
+		}, publisher.events)
+
+		published, err = relay.RunOnce(ctx)
+		require.NoError(suite.T(), err)
+		assert.Zero(suite.T(), published)
+	})
+
+	suite.Run("Rolled back changes write no events", func() {
+		err := repos.Tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			if _, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Rolled back"}, suite.testUser.ID); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+		require.Error(suite.T(), err)
+
+		var count int64
+		suite.db.Model(&model.OutboxMessage{}).Where("published_at IS NULL").Count(&count)
+		assert.Zero(suite.T(), count)
+	})
+}
+
 // TestIntegrationTestSuite runs the integration test suite
 func TestIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(IntegrationTestSuite))
-}
\ No newline at end of file
+}
@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/jobs"
+	"todo-api-backend/internal/model"
+)
+
+// MockUserRepository is a mock implementation of UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *model.User) error {
+	return m.Called(ctx, user).Error(0)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error {
+	return m.Called(ctx, user).Error(0)
+}
+
+func (m *MockUserRepository) GetWithAutoArchive(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func TestAutoArchiver_RunOnce_AppliesEachPolicy(t *testing.T) {
+	mockUserRepo := &MockUserRepository{}
+	mockTodoRepo := &MockTodoRepository{}
+	archiver := jobs.NewAutoArchiver(mockUserRepo, mockTodoRepo, time.Hour)
+	ctx := context.Background()
+
+	users := []*model.User{
+		{ID: 1, AutoArchiveDays: 7},
+		{ID: 2, AutoArchiveDays: 30},
+	}
+	mockUserRepo.On("GetWithAutoArchive", ctx).Return(users, nil)
+
+	withinDays := func(days int) interface{} {
+		return mock.MatchedBy(func(cutoff time.Time) bool {
+			expected := time.Now().AddDate(0, 0, -days)
+			return expected.Sub(cutoff) < time.Minute && cutoff.Sub(expected) < time.Minute
+		})
+	}
+	mockTodoRepo.On("ArchiveCompletedBefore", ctx, uint(1), withinDays(7), mock.Anything).Return([]uint{1, 2}, nil)
+	mockTodoRepo.On("ArchiveCompletedBefore", ctx, uint(2), withinDays(30), mock.Anything).Return([]uint{3, 4, 5}, nil)
+
+	archived, err := archiver.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), archived)
+
+	mockUserRepo.AssertExpectations(t)
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestAutoArchiver_RunOnce_ContinuesAfterUserFailure(t *testing.T) {
+	mockUserRepo := &MockUserRepository{}
+	mockTodoRepo := &MockTodoRepository{}
+	archiver := jobs.NewAutoArchiver(mockUserRepo, mockTodoRepo, time.Hour)
+	ctx := context.Background()
+
+	users := []*model.User{
+		{ID: 1, AutoArchiveDays: 7},
+		{ID: 2, AutoArchiveDays: 7},
+	}
+	mockUserRepo.On("GetWithAutoArchive", ctx).Return(users, nil)
+	mockTodoRepo.On("ArchiveCompletedBefore", ctx, uint(1), mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+	mockTodoRepo.On("ArchiveCompletedBefore", ctx, uint(2), mock.Anything, mock.Anything).Return([]uint{7}, nil)
+
+	archived, err := archiver.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestAutoArchiver_RunOnce_UserLookupError(t *testing.T) {
+	mockUserRepo := &MockUserRepository{}
+	mockTodoRepo := &MockTodoRepository{}
+	archiver := jobs.NewAutoArchiver(mockUserRepo, mockTodoRepo, time.Hour)
+	ctx := context.Background()
+
+	mockUserRepo.On("GetWithAutoArchive", ctx).Return(nil, errors.New("database error"))
+
+	_, err := archiver.RunOnce(ctx)
+
+	assert.Error(t, err)
+	mockTodoRepo.AssertNotCalled(t, "ArchiveCompletedBefore", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
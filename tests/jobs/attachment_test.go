@@ -0,0 +1,138 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/jobs"
+	"todo-api-backend/internal/model"
+)
+
+// MockAttachmentRepository is a mock implementation of AttachmentRepository
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Lock(ctx context.Context, userID uint) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	return m.Called(ctx, attachment).Error(0)
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, id uint) (*model.Attachment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByTodoID(ctx context.Context, todoID uint) ([]*model.Attachment, error) {
+	args := m.Called(ctx, todoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) TotalSize(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Delete(ctx context.Context, id uint, todoID uint) error {
+	return m.Called(ctx, id, todoID).Error(0)
+}
+
+func (m *MockAttachmentRepository) ListOrphaned(ctx context.Context, limit int) ([]*model.Attachment, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Attachment), args.Error(1)
+}
+
+// recordingBlobStore records deleted keys and fails to delete the keys in failing
+type recordingBlobStore struct {
+	deleted []string
+	failing map[string]bool
+}
+
+func (s *recordingBlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return nil
+}
+
+func (s *recordingBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *recordingBlobStore) Delete(ctx context.Context, key string) error {
+	if s.failing[key] {
+		return errors.New("storage unavailable")
+	}
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func TestAttachmentSweeper_RunOnce_RemovesBlobsThenRows(t *testing.T) {
+	repo := &MockAttachmentRepository{}
+	store := &recordingBlobStore{}
+	ctx := context.Background()
+
+	repo.On("ListOrphaned", ctx, 100).Return([]*model.Attachment{
+		{ID: 1, TodoID: 7, StorageKey: "attachments/1/a"},
+		{ID: 2, TodoID: 8, StorageKey: "attachments/1/b"},
+	}, nil)
+	repo.On("Delete", ctx, uint(1), uint(7)).Return(nil)
+	repo.On("Delete", ctx, uint(2), uint(8)).Return(nil)
+
+	removed, err := jobs.NewAttachmentSweeper(repo, store, time.Hour).RunOnce(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+	assert.Equal(t, []string{"attachments/1/a", "attachments/1/b"}, store.deleted)
+	repo.AssertExpectations(t)
+}
+
+func TestAttachmentSweeper_RunOnce_KeepsRowOfFailedBlob(t *testing.T) {
+	repo := &MockAttachmentRepository{}
+	store := &recordingBlobStore{failing: map[string]bool{"attachments/1/1": true}}
+	ctx := context.Background()
+
+	orphans := make([]*model.Attachment, 100)
+	for i := range orphans {
+		orphans[i] = &model.Attachment{ID: uint(i + 1), TodoID: 7, StorageKey: fmt.Sprintf("attachments/1/%d", i+1)}
+	}
+	repo.On("ListOrphaned", ctx, 100).Return(orphans, nil).Once()
+	repo.On("Delete", ctx, mock.Anything, uint(7)).Return(nil)
+
+	removed, err := jobs.NewAttachmentSweeper(repo, store, time.Hour).RunOnce(ctx)
+
+	// The failed blob is left for the next run instead of being listed again
+	require.NoError(t, err)
+	assert.Equal(t, int64(99), removed)
+	repo.AssertNumberOfCalls(t, "ListOrphaned", 1)
+	repo.AssertNotCalled(t, "Delete", ctx, uint(1), uint(7))
+}
+
+func TestAttachmentSweeper_RunOnce_ListError(t *testing.T) {
+	repo := &MockAttachmentRepository{}
+	ctx := context.Background()
+
+	repo.On("ListOrphaned", ctx, 100).Return(nil, errors.New("db down"))
+
+	removed, err := jobs.NewAttachmentSweeper(repo, &recordingBlobStore{}, time.Hour).RunOnce(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), removed)
+}
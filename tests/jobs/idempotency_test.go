@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/jobs"
+	"todo-api-backend/internal/model"
+)
+
+// MockIdempotencyRepository is a mock implementation of IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Create(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	args := m.Called(ctx, record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) GetByKey(ctx context.Context, userID uint, key string) (*model.IdempotencyRecord, error) {
+	args := m.Called(ctx, userID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Update(ctx context.Context, record *model.IdempotencyRecord) error {
+	return m.Called(ctx, record).Error(0)
+}
+
+func (m *MockIdempotencyRepository) Renew(ctx context.Context, id uint, now time.Time) error {
+	return m.Called(ctx, id, now).Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, id uint) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyKeyPurger_RunOnce(t *testing.T) {
+	repo := &MockIdempotencyRepository{}
+	purger := jobs.NewIdempotencyKeyPurger(repo, time.Hour)
+	ctx := context.Background()
+
+	before := time.Now()
+	repo.On("DeleteExpired", ctx, mock.MatchedBy(func(now time.Time) bool {
+		return !now.Before(before) && now.Before(before.Add(time.Minute))
+	})).Return(int64(4), nil)
+
+	purged, err := purger.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotencyKeyPurger_RunOnceError(t *testing.T) {
+	repo := &MockIdempotencyRepository{}
+	purger := jobs.NewIdempotencyKeyPurger(repo, time.Hour)
+	ctx := context.Background()
+
+	repo.On("DeleteExpired", ctx, mock.Anything).Return(int64(0), errors.New("database error"))
+
+	purged, err := purger.RunOnce(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), purged)
+}
@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/jobs"
+	"todo-api-backend/internal/model"
+)
+
+// MockOutboxRepository is a mock implementation of OutboxRepository
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Append(ctx context.Context, message *model.OutboxMessage) error {
+	return m.Called(ctx, message).Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimNext(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.OutboxMessage, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id uint, publishedAt time.Time) error {
+	return m.Called(ctx, id, publishedAt).Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, message *model.OutboxMessage) error {
+	return m.Called(ctx, message).Error(0)
+}
+
+func (m *MockOutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// recordingPublisher records the messages it publishes and fails those listed in failures
+type recordingPublisher struct {
+	published []uint
+	failures  map[uint]error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	if err := p.failures[message.ID]; err != nil {
+		return err
+	}
+	p.published = append(p.published, message.ID)
+	return nil
+}
+
+func TestOutboxRelay_PublishesRoundsUntilDrained(t *testing.T) {
+	mockRepo := &MockOutboxRepository{}
+	publisher := &recordingPublisher{}
+	relay := jobs.NewOutboxRelay(mockRepo, publisher, time.Second)
+	ctx := context.Background()
+
+	// The first round claims the head of two aggregates, the second the next message of one of them
+	mockRepo.On("DeletePublishedBefore", ctx, mock.Anything).Return(int64(0), nil)
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{
+		{ID: 1, AggregateType: "todo", AggregateID: 7},
+		{ID: 2, AggregateType: "user", AggregateID: 1},
+	}, nil).Once()
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{
+		{ID: 3, AggregateType: "todo", AggregateID: 7},
+	}, nil).Once()
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{}, nil).Once()
+	mockRepo.On("MarkPublished", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	published, err := relay.RunOnce(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, published)
+	assert.Equal(t, []uint{1, 2, 3}, publisher.published)
+	mockRepo.AssertNumberOfCalls(t, "MarkPublished", 3)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOutboxRelay_FailureSchedulesRetry(t *testing.T) {
+	mockRepo := &MockOutboxRepository{}
+	publisher := &recordingPublisher{failures: map[uint]error{1: errors.New("broker unavailable")}}
+	relay := jobs.NewOutboxRelay(mockRepo, publisher, time.Second)
+	ctx := context.Background()
+
+	failed := &model.OutboxMessage{ID: 1, AggregateType: "todo", AggregateID: 7, Attempts: 3}
+	mockRepo.On("DeletePublishedBefore", ctx, mock.Anything).Return(int64(0), nil)
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{
+		failed,
+		{ID: 2, AggregateType: "todo", AggregateID: 8},
+	}, nil).Once()
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{}, nil).Once()
+	mockRepo.On("MarkPublished", ctx, uint(2), mock.Anything).Return(nil)
+	mockRepo.On("MarkFailed", ctx, failed).Return(nil)
+
+	before := time.Now()
+	published, err := relay.RunOnce(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []uint{2}, publisher.published)
+	assert.Equal(t, 4, failed.Attempts)
+	assert.Equal(t, "broker unavailable", failed.LastError)
+
+	// The fourth attempt is followed by an 8 second backoff
+	assert.WithinDuration(t, before.Add(8*time.Second), failed.NextAttemptAt, time.Second)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOutboxRelay_BackoffIsCapped(t *testing.T) {
+	mockRepo := &MockOutboxRepository{}
+	publisher := &recordingPublisher{failures: map[uint]error{1: errors.New("broker unavailable")}}
+	relay := jobs.NewOutboxRelay(mockRepo, publisher, time.Second)
+	ctx := context.Background()
+
+	failed := &model.OutboxMessage{ID: 1, Attempts: 40}
+	mockRepo.On("DeletePublishedBefore", ctx, mock.Anything).Return(int64(0), nil)
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{failed}, nil).Once()
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{}, nil).Once()
+	mockRepo.On("MarkFailed", ctx, failed).Return(nil)
+
+	before := time.Now()
+	_, err := relay.RunOnce(ctx)
+
+	require.NoError(t, err)
+	assert.WithinDuration(t, before.Add(5*time.Minute), failed.NextAttemptAt, time.Second)
+}
+
+func TestOutboxRelay_MarkPublishedError(t *testing.T) {
+	mockRepo := &MockOutboxRepository{}
+	relay := jobs.NewOutboxRelay(mockRepo, &recordingPublisher{}, time.Second)
+	ctx := context.Background()
+
+	mockRepo.On("DeletePublishedBefore", ctx, mock.Anything).Return(int64(0), nil)
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return([]*model.OutboxMessage{{ID: 1}}, nil).Once()
+	mockRepo.On("MarkPublished", ctx, uint(1), mock.Anything).Return(errors.New("database error"))
+
+	// The message stays claimed and is published again once its lease expires
+	published, err := relay.RunOnce(ctx)
+
+	assert.Error(t, err)
+	assert.Zero(t, published)
+}
+
+func TestOutboxRelay_ClaimError(t *testing.T) {
+	mockRepo := &MockOutboxRepository{}
+	relay := jobs.NewOutboxRelay(mockRepo, &recordingPublisher{}, time.Second)
+	ctx := context.Background()
+
+	mockRepo.On("DeletePublishedBefore", ctx, mock.Anything).Return(int64(0), nil)
+	mockRepo.On("ClaimNext", ctx, mock.Anything, mock.Anything, 100).Return(nil, errors.New("database error"))
+
+	_, err := relay.RunOnce(ctx)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/jobs"
+	"todo-api-backend/internal/model"
+)
+
+// MockTodoRepository is a mock implementation of TodoRepository
+type MockTodoRepository struct {
+	mock.Mock
+}
+
+func (m *MockTodoRepository) Create(ctx context.Context, todo *model.Todo) error {
+	return m.Called(ctx, todo).Error(0)
+}
+
+func (m *MockTodoRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) GetByUserID(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) List(ctx context.Context, userID uint, filter *model.TodoFilter) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) Update(ctx context.Context, todo *model.Todo) error {
+	return m.Called(ctx, todo).Error(0)
+}
+
+func (m *MockTodoRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockTodoRepository) GetTrashedByID(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) GetTrashByUserID(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) Restore(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockTodoRepository) Purge(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockTodoRepository) PurgeTrashByUserID(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTodoRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTodoRepository) ArchiveCompletedBefore(ctx context.Context, userID uint, cutoff time.Time, archivedAt time.Time) ([]uint, error) {
+	args := m.Called(ctx, userID, cutoff, archivedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockTodoRepository) ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) StreamByUserID(ctx context.Context, userID uint, filter *model.TodoFilter, fn func(*model.Todo) error) error {
+	args := m.Called(ctx, userID, filter, fn)
+	return args.Error(0)
+}
+
+func TestTrashPurger_RunOnce_UsesRetentionCutoff(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	purger := jobs.NewTrashPurger(mockTodoRepo, 30*24*time.Hour, time.Hour)
+	ctx := context.Background()
+
+	before := time.Now().Add(-30 * 24 * time.Hour)
+	mockTodoRepo.On("PurgeDeletedBefore", ctx, mock.MatchedBy(func(cutoff time.Time) bool {
+		after := time.Now().Add(-30 * 24 * time.Hour)
+		return !cutoff.Before(before) && !cutoff.After(after)
+	})).Return(int64(4), nil)
+
+	purged, err := purger.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTrashPurger_RunOnce_Error(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	purger := jobs.NewTrashPurger(mockTodoRepo, time.Hour, time.Hour)
+	ctx := context.Background()
+
+	mockTodoRepo.On("PurgeDeletedBefore", ctx, mock.Anything).Return(int64(0), errors.New("database error"))
+
+	_, err := purger.RunOnce(ctx)
+
+	assert.Error(t, err)
+}
+
+func TestTrashPurger_Start_StopsOnCancel(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	purger := jobs.NewTrashPurger(mockTodoRepo, time.Hour, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockTodoRepo.On("PurgeDeletedBefore", ctx, mock.Anything).Return(int64(0), nil)
+
+	done := make(chan struct{})
+	go func() {
+		purger.Start(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("trash purger did not stop after context cancellation")
+	}
+}
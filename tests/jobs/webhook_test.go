@@ -0,0 +1,285 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/jobs"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/webhook"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	return m.Called(ctx, webhook).Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.Webhook, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetByIDForDelivery(ctx context.Context, id uint) (*model.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.Webhook, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListSubscribed(ctx context.Context, userID uint, eventType string) ([]*model.Webhook, error) {
+	args := m.Called(ctx, userID, eventType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	return m.Called(ctx, webhook).Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockWebhookRepository) RecordSuccess(ctx context.Context, id uint) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockWebhookRepository) RecordFailure(ctx context.Context, id uint, threshold int, reason string, now time.Time) (bool, error) {
+	args := m.Called(ctx, id, threshold, reason, now)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockWebhookDeliveryRepository is a mock implementation of WebhookDeliveryRepository
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) Create(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) ListByWebhookID(ctx context.Context, webhookID uint, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// setupWebhookDispatcher returns a dispatcher that claims the given deliveries once
+func setupWebhookDispatcher(maxAttempts, disableAfter int, deliveries ...*model.WebhookDelivery) (*jobs.WebhookDispatcher, *MockWebhookRepository, *MockWebhookDeliveryRepository) {
+	mockWebhooks := &MockWebhookRepository{}
+	mockDeliveries := &MockWebhookDeliveryRepository{}
+
+	mockDeliveries.On("DeleteFinishedBefore", mock.Anything, mock.Anything).Return(int64(0), nil)
+	mockDeliveries.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, 20).Return(deliveries, nil)
+	mockDeliveries.On("Update", mock.Anything, mock.AnythingOfType("*model.WebhookDelivery")).Return(nil)
+
+	// The test servers listen on loopback, which the default transport refuses
+	dispatcher := jobs.NewWebhookDispatcher(mockWebhooks, mockDeliveries, time.Minute, maxAttempts, disableAfter, jobs.WithWebhookTransport(http.DefaultTransport))
+	return dispatcher, mockWebhooks, mockDeliveries
+}
+
+func TestWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	payload := []byte(`{"id":"evt","type":"todo.created","todo_id":7}`)
+
+	var received atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := webhook.Verify("whsec_test", r.Header.Get(webhook.SignatureHeader), r.Header.Get(webhook.TimestampHeader), body, 5*time.Minute, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, "todo.created", r.Header.Get(webhook.EventHeader))
+		assert.Equal(t, "evt", r.Header.Get(webhook.DeliveryHeader))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, payload, body)
+		received.Store(true)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := &model.WebhookDelivery{ID: 1, WebhookID: 3, EventID: "evt", EventType: "todo.created", Payload: payload, Status: model.WebhookDeliveryPending}
+	dispatcher, mockWebhooks, _ := setupWebhookDispatcher(8, 20, delivery)
+	mockWebhooks.On("GetByIDForDelivery", mock.Anything, uint(3)).Return(&model.Webhook{ID: 3, URL: server.URL, Secret: "whsec_test", Active: true}, nil)
+	mockWebhooks.On("RecordSuccess", mock.Anything, uint(3)).Return(nil)
+
+	attempted, err := dispatcher.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.True(t, received.Load())
+	assert.Equal(t, model.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseCode)
+	assert.NotNil(t, delivery.LastAttemptAt)
+	mockWebhooks.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_FailureSchedulesRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	delivery := &model.WebhookDelivery{ID: 1, WebhookID: 3, Payload: []byte(`{}`), Status: model.WebhookDeliveryPending, Attempts: 2}
+	dispatcher, mockWebhooks, _ := setupWebhookDispatcher(8, 20, delivery)
+	mockWebhooks.On("GetByIDForDelivery", mock.Anything, uint(3)).Return(&model.Webhook{ID: 3, URL: server.URL, Active: true}, nil)
+	mockWebhooks.On("RecordFailure", mock.Anything, uint(3), 20, "20 consecutive failed delivery attempts", mock.Anything).Return(false, nil)
+
+	before := time.Now()
+	_, err := dispatcher.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseCode)
+	assert.Equal(t, "unexpected status 503", delivery.LastError)
+
+	// The third attempt is followed by a 2 minute backoff
+	assert.WithinDuration(t, before.Add(2*time.Minute), delivery.NextAttemptAt, 5*time.Second)
+	mockWebhooks.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_RedirectIsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://elsewhere.example.com", http.StatusFound)
+	}))
+	defer server.Close()
+
+	delivery := &model.WebhookDelivery{ID: 1, WebhookID: 3, Payload: []byte(`{}`), Status: model.WebhookDeliveryPending}
+	dispatcher, mockWebhooks, _ := setupWebhookDispatcher(8, 20, delivery)
+	mockWebhooks.On("GetByIDForDelivery", mock.Anything, uint(3)).Return(&model.Webhook{ID: 3, URL: server.URL, Active: true}, nil)
+	mockWebhooks.On("RecordFailure", mock.Anything, uint(3), 20, mock.Anything, mock.Anything).Return(false, nil)
+
+	_, err := dispatcher.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, delivery.ResponseCode)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+}
+
+func TestWebhookDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	delivery := &model.WebhookDelivery{ID: 1, WebhookID: 3, Payload: []byte(`{}`), Status: model.WebhookDeliveryPending, Attempts: 7}
+	dispatcher, mockWebhooks, _ := setupWebhookDispatcher(8, 20, delivery)
+	mockWebhooks.On("GetByIDForDelivery", mock.Anything, uint(3)).Return(&model.Webhook{ID: 3, URL: "http://127.0.0.1:1", Active: true}, nil)
+	mockWebhooks.On("RecordFailure", mock.Anything, uint(3), 20, mock.Anything, mock.Anything).Return(true, nil)
+
+	_, err := dispatcher.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 8, delivery.Attempts)
+	assert.Zero(t, delivery.ResponseCode)
+	assert.NotEmpty(t, delivery.LastError)
+}
+
+func TestWebhookDispatcher_DisabledWebhook(t *testing.T) {
+	disabled := &model.WebhookDelivery{ID: 1, WebhookID: 3, Status: model.WebhookDeliveryPending}
+	deleted := &model.WebhookDelivery{ID: 2, WebhookID: 4, Status: model.WebhookDeliveryPending}
+	dispatcher, mockWebhooks, _ := setupWebhookDispatcher(8, 20, disabled, deleted)
+	mockWebhooks.On("GetByIDForDelivery", mock.Anything, uint(3)).Return(&model.Webhook{ID: 3, Active: false}, nil)
+	mockWebhooks.On("GetByIDForDelivery", mock.Anything, uint(4)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := dispatcher.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	for _, delivery := range []*model.WebhookDelivery{disabled, deleted} {
+		assert.Equal(t, model.WebhookDeliveryFailed, delivery.Status)
+		assert.Equal(t, "webhook disabled", delivery.LastError)
+		assert.Zero(t, delivery.Attempts)
+	}
+	mockWebhooks.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebhookDispatcher_ClaimError(t *testing.T) {
+	mockWebhooks := &MockWebhookRepository{}
+	mockDeliveries := &MockWebhookDeliveryRepository{}
+	mockDeliveries.On("DeleteFinishedBefore", mock.Anything, mock.Anything).Return(int64(0), nil)
+	mockDeliveries.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, 20).Return(nil, errors.New("database error"))
+
+	dispatcher := jobs.NewWebhookDispatcher(mockWebhooks, mockDeliveries, time.Minute, 8, 20)
+	attempted, err := dispatcher.RunOnce(context.Background())
+
+	assert.Error(t, err)
+	assert.Zero(t, attempted)
+}
+
+func TestWebhookDispatcher_RefusesInternalAddresses(t *testing.T) {
+	var received atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(true)
+	}))
+	defer server.Close()
+
+	for _, url := range []string{server.URL, "http://169.254.169.254/latest/meta-data/"} {
+		t.Run(url, func(t *testing.T) {
+			mockWebhooks := &MockWebhookRepository{}
+			mockDeliveries := &MockWebhookDeliveryRepository{}
+			delivery := &model.WebhookDelivery{ID: 1, WebhookID: 3, Payload: []byte(`{}`), Status: model.WebhookDeliveryPending}
+			mockDeliveries.On("DeleteFinishedBefore", mock.Anything, mock.Anything).Return(int64(0), nil)
+			mockDeliveries.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, 20).Return([]*model.WebhookDelivery{delivery}, nil)
+			mockDeliveries.On("Update", mock.Anything, mock.AnythingOfType("*model.WebhookDelivery")).Return(nil)
+			mockWebhooks.On("GetByIDForDelivery", mock.Anything, uint(3)).Return(&model.Webhook{ID: 3, URL: url, Active: true}, nil)
+			mockWebhooks.On("RecordFailure", mock.Anything, uint(3), 20, mock.Anything, mock.Anything).Return(false, nil)
+
+			dispatcher := jobs.NewWebhookDispatcher(mockWebhooks, mockDeliveries, time.Minute, 8, 20)
+			_, err := dispatcher.RunOnce(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+			assert.Zero(t, delivery.ResponseCode)
+			assert.Contains(t, delivery.LastError, "webhook target address not allowed")
+		})
+	}
+	assert.False(t, received.Load())
+}
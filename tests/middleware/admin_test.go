@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/pkg/jwt"
+)
+
+// recordingAuditor collects the security events recorded by the middleware
+type recordingAuditor struct {
+	events []*model.SecurityEvent
+}
+
+func (r *recordingAuditor) Record(ctx context.Context, event *model.SecurityEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestAuthMiddleware_RecordsTokenFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenManager := jwt.NewTokenManager("test-secret-key", 24)
+	validToken, err := tokenManager.GenerateToken(1, "test@example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectedReason string
+	}{
+		{"Valid token", "Bearer " + validToken, ""},
+		{"Missing authorization header", "", "missing_header"},
+		{"Invalid bearer prefix", "Basic " + validToken, "invalid_scheme"},
+		{"Empty token", "Bearer ", "missing_token"},
+		{"Invalid token", "Bearer invalid.token.here", "invalid_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditor := &recordingAuditor{}
+
+			router := gin.New()
+			router.Use(middleware.AuthMiddleware(tokenManager, middleware.WithSecurityAuditor(auditor)))
+			router.GET("/protected", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("User-Agent", "test-agent")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if tt.expectedReason == "" {
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Empty(t, auditor.events)
+				return
+			}
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			require.Len(t, auditor.events, 1)
+			assert.Equal(t, model.SecurityEventTokenValidation, auditor.events[0].EventType)
+			assert.Equal(t, model.SecurityOutcomeFailure, auditor.events[0].Outcome)
+			assert.Equal(t, tt.expectedReason, auditor.events[0].Reason)
+			assert.Equal(t, "test-agent", auditor.events[0].UserAgent)
+		})
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		userID         uint
+		email          string
+		expectedStatus int
+	}{
+		{"Admin user", 1, "admin@example.com", http.StatusOK},
+		{"Admin user with another email", 1, "renamed@example.com", http.StatusOK},
+		{"Regular user", 2, "user@example.com", http.StatusForbidden},
+		{"Regular user with the email of an admin", 3, "admin@example.com", http.StatusForbidden},
+		{"Unauthenticated request", 0, "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set(middleware.UserIDKey, tt.userID)
+					c.Set(middleware.UserEmailKey, tt.email)
+				}
+				c.Next()
+			})
+			router.Use(middleware.AdminMiddleware([]uint{1}))
+			router.GET("/admin", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAdminMiddleware_NoAdminsConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, uint(1))
+		c.Next()
+	})
+	router.Use(middleware.AdminMiddleware(nil))
+	router.GET("/admin", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -322,4 +322,74 @@ func TestAuthMiddleware_TokenValidationScenarios(t *testing.T) {
 			assert.Contains(t, w.Body.String(), tt.expectedError)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestAuthMiddleware_QueryToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenManager := jwt.NewTokenManager("test-secret-key", 24)
+	validToken, err := tokenManager.GenerateToken(1, "test@example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		query          string
+		authHeader     string
+		enabled        bool
+		expectedStatus int
+	}{
+		{
+			name:           "Token in query parameter",
+			query:          "?access_token=" + validToken,
+			enabled:        true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid token in query parameter",
+			query:          "?access_token=invalid-token",
+			enabled:        true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Authorization header takes precedence",
+			query:          "?access_token=" + validToken,
+			authHeader:     "Bearer invalid-token",
+			enabled:        true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Query parameter ignored unless enabled",
+			query:          "?access_token=" + validToken,
+			enabled:        false,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []middleware.AuthOption
+			if tt.enabled {
+				opts = append(opts, middleware.WithQueryToken("access_token"))
+			}
+
+			router := gin.New()
+			router.Use(middleware.AuthMiddleware(tokenManager, opts...))
+			router.GET("/test", func(c *gin.Context) {
+				userID, _ := middleware.GetUserID(c)
+				c.JSON(http.StatusOK, gin.H{"user_id": userID})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"user_id":1`)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+)
+
+var (
+	errBadCredentials = errors.New("invalid credentials")
+	errStoreDown      = errors.New("database unavailable")
+)
+
+// fakeVerifier accepts a single user name and password
+type fakeVerifier struct {
+	err error
+}
+
+func (f *fakeVerifier) Authenticate(ctx context.Context, username, password string) (uint, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	if username != "test@example.com" || password != "dav_secret" {
+		return 0, errBadCredentials
+	}
+	return 7, nil
+}
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		verifierErr    error
+		expectedStatus int
+		expectAudit    bool
+	}{
+		{name: "Valid credentials", username: "test@example.com", password: "dav_secret", expectedStatus: http.StatusOK},
+		{name: "Missing credentials", expectedStatus: http.StatusUnauthorized},
+		{name: "Wrong password", username: "test@example.com", password: "wrong", expectedStatus: http.StatusUnauthorized, expectAudit: true},
+		{name: "Verifier failure", username: "test@example.com", password: "dav_secret", verifierErr: errStoreDown, expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditor := &recordingAuditor{}
+			rejected := func(err error) bool { return errors.Is(err, errBadCredentials) }
+
+			router := gin.New()
+			router.Use(middleware.BasicAuthMiddleware(&fakeVerifier{err: tt.verifierErr}, "Todos", rejected, middleware.WithSecurityAuditor(auditor)))
+			router.GET("/dav", func(c *gin.Context) {
+				userID, ok := middleware.GetUserID(c)
+				require.True(t, ok)
+				email, _ := middleware.GetUserEmail(c)
+				assert.Equal(t, uint(7), userID)
+				assert.Equal(t, "test@example.com", email)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/dav", nil)
+			if tt.username != "" {
+				req.SetBasicAuth(tt.username, tt.password)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="Todos", charset="UTF-8"`, w.Header().Get("WWW-Authenticate"))
+			}
+			if tt.expectAudit {
+				require.Len(t, auditor.events, 1)
+				assert.Equal(t, model.SecurityEventCalDAVLogin, auditor.events[0].EventType)
+				assert.Equal(t, model.SecurityOutcomeFailure, auditor.events[0].Outcome)
+				assert.Equal(t, tt.username, auditor.events[0].Email)
+			} else {
+				assert.Empty(t, auditor.events)
+			}
+		})
+	}
+}
@@ -334,4 +334,39 @@ func TestCORSMiddleware_MultipleOrigins(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestCORSMiddleware_ExcludePaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := middleware.DefaultCORSConfig()
+	config.ExcludePaths = []string{"/caldav"}
+
+	router := gin.New()
+	router.Use(middleware.CORSMiddleware(config))
+	router.OPTIONS("/caldav/*path", func(c *gin.Context) {
+		c.Header("DAV", "1, calendar-access")
+		c.Status(http.StatusOK)
+	})
+	router.OPTIONS("/api/todos", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Excluded paths answer OPTIONS themselves
+	req := httptest.NewRequest(http.MethodOptions, "/caldav/todos/", nil)
+	req.Header.Set("Origin", "https://example.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1, calendar-access", w.Header().Get("DAV"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Other paths still get CORS preflight handling
+	req = httptest.NewRequest(http.MethodOptions, "/api/todos", nil)
+	req.Header.Set("Origin", "https://example.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+}
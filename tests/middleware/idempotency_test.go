@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/middleware"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// memoryIdempotencyRepository keeps idempotency records in memory
+type memoryIdempotencyRepository struct {
+	records map[string]*model.IdempotencyRecord
+	nextID  uint
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: make(map[string]*model.IdempotencyRecord)}
+}
+
+func (r *memoryIdempotencyRepository) mapKey(userID uint, key string) string {
+	return fmt.Sprintf("%d/%s", userID, key)
+}
+
+func (r *memoryIdempotencyRepository) Create(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	if _, exists := r.records[r.mapKey(record.UserID, record.Key)]; exists {
+		return false, nil
+	}
+	r.nextID++
+	record.ID = r.nextID
+	stored := *record
+	r.records[r.mapKey(record.UserID, record.Key)] = &stored
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) GetByKey(ctx context.Context, userID uint, key string) (*model.IdempotencyRecord, error) {
+	record, exists := r.records[r.mapKey(userID, key)]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *record
+	return &found, nil
+}
+
+func (r *memoryIdempotencyRepository) Update(ctx context.Context, record *model.IdempotencyRecord) error {
+	stored := *record
+	r.records[r.mapKey(record.UserID, record.Key)] = &stored
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Renew(ctx context.Context, id uint, now time.Time) error {
+	for _, record := range r.records {
+		if record.ID == id && !record.IsComplete() {
+			record.RenewedAt = now
+		}
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Delete(ctx context.Context, id uint) error {
+	for key, record := range r.records {
+		if record.ID == id {
+			delete(r.records, key)
+		}
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// setupIdempotencyRouter returns a router whose POST /todos handler counts its calls
+// and answers with the given status
+func setupIdempotencyRouter(repo *memoryIdempotencyRepository, status *int) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-User") != "" {
+			c.Set(middleware.UserIDKey, uint(len(c.GetHeader("X-User"))))
+		}
+		c.Next()
+	})
+	router.Use(middleware.IdempotencyMiddleware(service.NewIdempotencyService(repo, time.Hour), 1<<10))
+
+	handle := func(c *gin.Context) {
+		calls++
+		c.Header("Location", "/todos/1")
+		c.JSON(*status, gin.H{"call": calls})
+	}
+	router.POST("/todos", handle)
+	router.GET("/todos", handle)
+
+	return router, &calls
+}
+
+func sendIdempotent(router *gin.Engine, method, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/todos", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware_ReplaysResponse(t *testing.T) {
+	status := http.StatusCreated
+	router, calls := setupIdempotencyRouter(newMemoryIdempotencyRepository(), &status)
+
+	first := sendIdempotent(router, http.MethodPost, "a", "key-1", `{"title":"Milk"}`)
+	retry := sendIdempotent(router, http.MethodPost, "a", "key-1", `{"title":"Milk"}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "/todos/1", retry.Header().Get("Location"))
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyMiddleware_KeyReusedWithDifferentBody(t *testing.T) {
+	status := http.StatusCreated
+	router, calls := setupIdempotencyRouter(newMemoryIdempotencyRepository(), &status)
+
+	sendIdempotent(router, http.MethodPost, "a", "key-1", `{"title":"Milk"}`)
+	w := sendIdempotent(router, http.MethodPost, "a", "key-1", `{"title":"Eggs"}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "idempotency_key_reused")
+}
+
+func TestIdempotencyMiddleware_KeysAreScopedPerUser(t *testing.T) {
+	status := http.StatusCreated
+	router, calls := setupIdempotencyRouter(newMemoryIdempotencyRepository(), &status)
+
+	sendIdempotent(router, http.MethodPost, "a", "key-1", `{"title":"Milk"}`)
+	w := sendIdempotent(router, http.MethodPost, "bb", "key-1", `{"title":"Milk"}`)
+
+	assert.Equal(t, 2, *calls)
+	assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyMiddleware_InProgress(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	status := http.StatusCreated
+	router, calls := setupIdempotencyRouter(repo, &status)
+
+	// Record the key as reserved by a request that has not finished yet
+	first := sendIdempotent(router, http.MethodPost, "a", "key-1", `{}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	for _, record := range repo.records {
+		record.StatusCode = 0
+	}
+
+	w := sendIdempotent(router, http.MethodPost, "a", "key-1", `{}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestIdempotencyMiddleware_ServerErrorsAreNotStored(t *testing.T) {
+	status := http.StatusInternalServerError
+	router, calls := setupIdempotencyRouter(newMemoryIdempotencyRepository(), &status)
+
+	sendIdempotent(router, http.MethodPost, "a", "key-1", `{}`)
+	status = http.StatusCreated
+	w := sendIdempotent(router, http.MethodPost, "a", "key-1", `{}`)
+
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestIdempotencyMiddleware_Passthrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		user   string
+		key    string
+	}{
+		{"no key", http.MethodPost, "a", ""},
+		{"safe method", http.MethodGet, "a", "key-1"},
+		{"unauthenticated", http.MethodPost, "", "key-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := http.StatusOK
+			repo := newMemoryIdempotencyRepository()
+			router, calls := setupIdempotencyRouter(repo, &status)
+
+			sendIdempotent(router, tt.method, tt.user, tt.key, `{}`)
+			sendIdempotent(router, tt.method, tt.user, tt.key, `{}`)
+
+			assert.Equal(t, 2, *calls)
+			assert.Empty(t, repo.records)
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_KeyTooLong(t *testing.T) {
+	status := http.StatusCreated
+	router, calls := setupIdempotencyRouter(newMemoryIdempotencyRepository(), &status)
+
+	w := sendIdempotent(router, http.MethodPost, "a", strings.Repeat("k", 256), `{}`)
+
+	assert.Equal(t, 0, *calls)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIdempotencyMiddleware_BodyTooLarge(t *testing.T) {
+	status := http.StatusCreated
+	repo := newMemoryIdempotencyRepository()
+	router, calls := setupIdempotencyRouter(repo, &status)
+
+	w := sendIdempotent(router, http.MethodPost, "a", "key-1", `{"title":"`+strings.Repeat("x", 2<<10)+`"}`)
+
+	assert.Equal(t, 0, *calls)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "request_too_large")
+	assert.Empty(t, repo.records)
+
+	// Without a key the body is left to the handler
+	w = sendIdempotent(router, http.MethodPost, "a", "", `{"title":"`+strings.Repeat("x", 2<<10)+`"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
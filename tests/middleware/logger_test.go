@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"todo-api-backend/internal/middleware"
+)
+
+func TestLoggerMiddleware_RedactsQueryParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		target string
+		logged string
+		hidden string
+	}{
+		{"redacted parameter", "/ws?access_token=secret.jwt&list=user%3A1", `"/ws?access_token=REDACTED&list=user%3A1"`, "secret.jwt"},
+		{"escaped parameter name", "/ws?list=7&access%5Ftoken=secret.jwt", `"/ws?list=7&access_token=REDACTED"`, "secret.jwt"},
+		{"other parameters", "/ws?list=7", `"/ws?list=7"`, ""},
+		{"no query", "/ws", `"/ws"`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var query string
+			router := gin.New()
+			router.Use(middleware.LoggerMiddleware(&out, "access_token"))
+			router.GET("/ws", func(c *gin.Context) {
+				query = c.Query("access_token")
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, out.String(), tt.logged)
+			if tt.hidden != "" {
+				assert.NotContains(t, out.String(), tt.hidden)
+				// The handler still sees the real value
+				assert.Equal(t, tt.hidden, query)
+			}
+		})
+	}
+}
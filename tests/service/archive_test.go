@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/internal/service"
+)
+
+func TestTodoService_List_PassesFilter(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	filter := &model.TodoFilter{Archived: model.ArchivedOnly}
+	archived := []*model.Todo{{ID: 1, Title: "Old", UserID: 1}}
+
+	mockTodoRepo.On("List", ctx, uint(1), filter).Return(archived, nil)
+
+	todos, err := todoService.List(ctx, uint(1), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, archived, todos)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Archive_Success(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	existingTodo := &model.Todo{ID: 1, Title: "Done", Completed: true, UserID: 1}
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingTodo, nil)
+	mockTodoRepo.On("Update", ctx, mock.MatchedBy(func(todo *model.Todo) bool {
+		return todo.ArchivedAt != nil
+	})).Return(nil)
+
+	todo, err := todoService.Archive(ctx, uint(1), uint(1))
+
+	assert.NoError(t, err)
+	assert.True(t, todo.IsArchived())
+	assert.True(t, todo.Completed, "archiving must not change completion")
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Archive_AlreadyArchived(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	archivedAt := time.Now().Add(-time.Hour)
+	existingTodo := &model.Todo{ID: 1, Title: "Done", UserID: 1, ArchivedAt: &archivedAt}
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingTodo, nil)
+
+	todo, err := todoService.Archive(ctx, uint(1), uint(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, archivedAt, *todo.ArchivedAt)
+	mockTodoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_Archive_Preconditions(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+
+	existing := func() *model.Todo {
+		return &model.Todo{ID: 1, Title: "Done", UserID: 1, Version: 3}
+	}
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(existing(), nil).Once()
+
+	// A stale If-Match is rejected before writing
+	todo, err := todoService.Archive(service.WithIfMatch(context.Background(), 2), uint(1), uint(1))
+	assert.Nil(t, todo)
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+	mockTodoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	// A concurrent write between reading and archiving is a conflict
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(existing(), nil).Once()
+	mockTodoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(repository.ErrVersionConflict)
+	todo, err = todoService.Archive(context.Background(), uint(1), uint(1))
+	assert.Nil(t, todo)
+	assert.ErrorIs(t, err, service.ErrConcurrentUpdate)
+}
+
+func TestTodoService_Unarchive_Preconditions(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+
+	existing := func() *model.Todo {
+		archivedAt := time.Now().Add(-time.Hour)
+		return &model.Todo{ID: 1, Title: "Done", UserID: 1, Version: 3, ArchivedAt: &archivedAt}
+	}
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(existing(), nil).Once()
+	mockTodoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(repository.ErrVersionConflict)
+
+	_, err := todoService.Unarchive(service.WithIfMatch(context.Background(), 2), uint(1), uint(1))
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(existing(), nil).Once()
+	_, err = todoService.Unarchive(context.Background(), uint(1), uint(1))
+	assert.ErrorIs(t, err, service.ErrConcurrentUpdate)
+}
+
+func TestTodoService_Archive_NotFound(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	todo, err := todoService.Archive(ctx, uint(1), uint(1))
+
+	assert.Nil(t, todo)
+	assert.Equal(t, service.ErrTodoNotFound, err)
+}
+
+func TestTodoService_Unarchive_Success(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	archivedAt := time.Now()
+	existingTodo := &model.Todo{ID: 1, Title: "Done", UserID: 1, ArchivedAt: &archivedAt}
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingTodo, nil)
+	mockTodoRepo.On("Update", ctx, existingTodo).Return(nil)
+
+	todo, err := todoService.Unarchive(ctx, uint(1), uint(1))
+
+	assert.NoError(t, err)
+	assert.False(t, todo.IsArchived())
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_ArchiveCompleted_UsesCutoff(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("ArchiveCompletedBefore", ctx, uint(1), mock.MatchedBy(func(cutoff time.Time) bool {
+		expected := time.Now().AddDate(0, 0, -30)
+		return expected.Sub(cutoff) < time.Minute && cutoff.Sub(expected) < time.Minute
+	}), mock.Anything).Return([]uint{4, 8, 15}, nil)
+
+	archived, err := todoService.ArchiveCompleted(ctx, uint(1), 30)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), archived)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Update_TracksCompletionTime(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	existingTodo := &model.Todo{ID: 1, Title: "Task", UserID: 1}
+	completed := true
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingTodo, nil)
+	mockTodoRepo.On("Update", ctx, existingTodo).Return(nil)
+
+	todo, err := todoService.Update(ctx, uint(1), &model.UpdateTodoRequest{Completed: &completed}, uint(1))
+
+	assert.NoError(t, err)
+	assert.True(t, todo.Completed)
+	assert.NotNil(t, todo.CompletedAt)
+
+	// Reopening the todo clears the completion time
+	reopened := false
+	todo, err = todoService.Update(ctx, uint(1), &model.UpdateTodoRequest{Completed: &reopened}, uint(1))
+
+	assert.NoError(t, err)
+	assert.False(t, todo.Completed)
+	assert.Nil(t, todo.CompletedAt)
+}
+
+func TestTodoService_UpdateArchivePolicy(t *testing.T) {
+	todoService, _, mockUserRepo := setupTodoService()
+	ctx := context.Background()
+
+	user := &model.User{ID: 1, Email: "test@example.com"}
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(user, nil)
+	mockUserRepo.On("Update", ctx, mock.MatchedBy(func(u *model.User) bool {
+		return u.AutoArchiveDays == 14
+	})).Return(nil)
+
+	policy, err := todoService.UpdateArchivePolicy(ctx, uint(1), &model.ArchivePolicyRequest{AutoArchiveDays: 14})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 14, policy.AutoArchiveDays)
+	assert.True(t, policy.Enabled)
+
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestTodoService_GetArchivePolicy_Disabled(t *testing.T) {
+	todoService, _, mockUserRepo := setupTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+
+	policy, err := todoService.GetArchivePolicy(ctx, uint(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, policy.AutoArchiveDays)
+	assert.False(t, policy.Enabled)
+}
+
+func TestTodoService_GetArchivePolicy_UserNotFound(t *testing.T) {
+	todoService, _, mockUserRepo := setupTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	policy, err := todoService.GetArchivePolicy(ctx, uint(1))
+
+	assert.Nil(t, policy)
+	assert.Equal(t, service.ErrUserNotFound, err)
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+	"todo-api-backend/internal/storage"
+)
+
+// MockAttachmentRepository is a mock implementation of AttachmentRepository
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Lock(ctx context.Context, userID uint) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	return m.Called(ctx, attachment).Error(0)
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, id uint) (*model.Attachment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByTodoID(ctx context.Context, todoID uint) ([]*model.Attachment, error) {
+	args := m.Called(ctx, todoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) TotalSize(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Delete(ctx context.Context, id uint, todoID uint) error {
+	return m.Called(ctx, id, todoID).Error(0)
+}
+
+func (m *MockAttachmentRepository) ListOrphaned(ctx context.Context, limit int) ([]*model.Attachment, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Attachment), args.Error(1)
+}
+
+// pngData is the signature of a PNG image followed by padding up to size bytes
+func pngData(size int) []byte {
+	return append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, size-8)...)
+}
+
+type attachmentFixture struct {
+	service     service.AttachmentService
+	attachments *MockAttachmentRepository
+	todos       *MockTodoRepository
+	store       *storage.LocalStore
+	dir         string
+	tx          *fakeTransactor
+}
+
+func setupAttachmentService(t *testing.T, limits service.AttachmentLimits) *attachmentFixture {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStore(dir)
+	require.NoError(t, err)
+	f := &attachmentFixture{
+		attachments: &MockAttachmentRepository{},
+		todos:       &MockTodoRepository{},
+		store:       store,
+		dir:         dir,
+		tx:          &fakeTransactor{},
+	}
+	if limits.MaxSize == 0 {
+		limits.MaxSize = 1 << 20
+	}
+	if limits.Quota == 0 {
+		limits.Quota = 4 << 20
+	}
+	limits.URLSecret = []byte("attachment-url-secret")
+	f.service = service.NewAttachmentService(f.attachments, f.todos, f.tx, store, limits)
+	return f
+}
+
+// downloadParams extracts the ID, expiry time and signature of a download URL
+func downloadParams(t *testing.T, downloadURL string) (uint, int64, string) {
+	u, err := url.Parse(downloadURL)
+	require.NoError(t, err)
+	var id uint
+	_, err = fmt.Sscanf(u.Path, "/api/v1/attachments/%d/download", &id)
+	require.NoError(t, err)
+	expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	require.NoError(t, err)
+	return id, expires, u.Query().Get("signature")
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{})
+	ctx := context.Background()
+	data := pngData(2048)
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("TotalSize", mock.Anything, uint(1)).Return(int64(0), nil)
+	f.attachments.On("Lock", ctx, uint(1)).Return(nil)
+	f.attachments.On("Create", ctx, mock.AnythingOfType("*model.Attachment")).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Attachment).ID = 5
+	}).Return(nil)
+
+	attachment, err := f.service.Upload(ctx, 7, 1, `C:\Users\me\login error.png`, bytes.NewReader(data), int64(len(data)))
+
+	require.NoError(t, err)
+	assert.Equal(t, "login error.png", attachment.Filename)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, int64(2048), attachment.Size)
+	sum := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(sum[:]), attachment.Checksum)
+	assert.Regexp(t, `^attachments/1/[0-9a-f]{32}$`, attachment.StorageKey)
+	assert.Regexp(t, `^/api/v1/attachments/5/download\?expires=\d+&signature=[0-9a-f]{64}$`, attachment.DownloadURL)
+	require.NotNil(t, attachment.DownloadExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), *attachment.DownloadExpiresAt, 5*time.Second)
+	assert.Equal(t, 1, f.tx.committed)
+
+	blob, err := f.store.Get(ctx, attachment.StorageKey)
+	require.NoError(t, err)
+	stored, _ := io.ReadAll(blob)
+	blob.Close()
+	assert.Equal(t, data, stored)
+}
+
+func TestAttachmentService_Upload_Rejected(t *testing.T) {
+	exe := append([]byte("MZ\x90\x00"), bytes.Repeat([]byte{1}, 100)...)
+	html := []byte("<!DOCTYPE html><script>alert(1)</script>")
+
+	tests := []struct {
+		name string
+		data []byte
+		size int64
+		err  error
+	}{
+		{"too large", pngData(1<<20 + 1), 1<<20 + 1, service.ErrAttachmentTooLarge},
+		{"empty", nil, 0, service.ErrEmptyAttachment},
+		{"executable named like an image", exe, int64(len(exe)), service.ErrUnsupportedAttachmentType},
+		{"html", html, int64(len(html)), service.ErrUnsupportedAttachmentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupAttachmentService(t, service.AttachmentLimits{})
+			ctx := context.Background()
+			f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+			f.attachments.On("TotalSize", ctx, uint(1)).Return(int64(0), nil)
+
+			_, err := f.service.Upload(ctx, 7, 1, "screenshot.png", bytes.NewReader(tt.data), tt.size)
+
+			assert.ErrorIs(t, err, tt.err)
+			f.attachments.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAttachmentService_Upload_AllowedTypes(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{AllowedTypes: []string{"text/plain"}})
+	ctx := context.Background()
+	data := pngData(64)
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("TotalSize", mock.Anything, uint(1)).Return(int64(0), nil)
+	f.attachments.On("Lock", ctx, uint(1)).Return(nil)
+	f.attachments.On("Create", ctx, mock.AnythingOfType("*model.Attachment")).Return(nil)
+
+	_, err := f.service.Upload(ctx, 7, 1, "image.png", bytes.NewReader(data), int64(len(data)))
+	assert.ErrorIs(t, err, service.ErrUnsupportedAttachmentType)
+
+	// The charset of text is not part of the type
+	attachment, err := f.service.Upload(ctx, 7, 1, "steps.txt", bytes.NewBufferString("1. Open the login page"), 22)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", attachment.ContentType)
+}
+
+func TestAttachmentService_Upload_QuotaExceeded(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{Quota: 1 << 20})
+	ctx := context.Background()
+	data := pngData(4096)
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("TotalSize", ctx, uint(1)).Return(int64(1<<20-4096), nil).Once()
+	// A concurrent upload used up the quota in the meantime
+	f.attachments.On("TotalSize", ctx, uint(1)).Return(int64(1<<20-1024), nil).Once()
+	f.attachments.On("Lock", ctx, uint(1)).Return(nil)
+
+	_, err := f.service.Upload(ctx, 7, 1, "screenshot.png", bytes.NewReader(data), int64(len(data)))
+
+	assert.ErrorIs(t, err, service.ErrAttachmentQuotaExceeded)
+	assert.Equal(t, 1, f.tx.rolledBack)
+	f.attachments.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	// The uploaded blob is removed again
+	var blobs []string
+	require.NoError(t, filepath.WalkDir(f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			blobs = append(blobs, path)
+		}
+		return err
+	}))
+	assert.Empty(t, blobs)
+}
+
+func TestAttachmentService_Upload_QuotaUsedUp(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{Quota: 1 << 20})
+	ctx := context.Background()
+	data := pngData(4096)
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("TotalSize", ctx, uint(1)).Return(int64(1<<20-1024), nil)
+
+	_, err := f.service.Upload(ctx, 7, 1, "screenshot.png", bytes.NewReader(data), int64(len(data)))
+
+	assert.ErrorIs(t, err, service.ErrAttachmentQuotaExceeded)
+	assert.Equal(t, 0, f.tx.committed+f.tx.rolledBack)
+}
+
+func TestAttachmentService_TodoAccess(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{})
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	f.todos.On("GetByID", ctx, uint(8), uint(1)).Return(&model.Todo{ID: 8, UserID: 1}, nil)
+	f.attachments.On("GetByID", ctx, uint(5)).Return(&model.Attachment{ID: 5, TodoID: 7, UserID: 1}, nil)
+
+	_, err := f.service.List(ctx, 7, 1)
+	assert.ErrorIs(t, err, service.ErrTodoNotFound)
+
+	_, err = f.service.Upload(ctx, 7, 1, "a.png", bytes.NewReader(pngData(64)), 64)
+	assert.ErrorIs(t, err, service.ErrTodoNotFound)
+
+	// Attachments are only found through their own todo
+	_, err = f.service.Get(ctx, 8, 5, 1)
+	assert.ErrorIs(t, err, service.ErrAttachmentNotFound)
+	assert.ErrorIs(t, f.service.Delete(ctx, 8, 5, 1), service.ErrAttachmentNotFound)
+}
+
+func TestAttachmentService_List(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{})
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("ListByTodoID", ctx, uint(7)).Return([]*model.Attachment{{ID: 5, TodoID: 7}, {ID: 6, TodoID: 7}}, nil)
+	f.attachments.On("TotalSize", ctx, uint(1)).Return(int64(3000), nil)
+
+	list, err := f.service.List(ctx, 7, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, list.Count)
+	assert.Equal(t, int64(3000), list.UsedBytes)
+	assert.Equal(t, int64(4<<20), list.QuotaBytes)
+	for _, attachment := range list.Attachments {
+		assert.NotEmpty(t, attachment.DownloadURL)
+	}
+}
+
+func TestAttachmentService_Open(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{})
+	ctx := context.Background()
+	require.NoError(t, f.store.Put(ctx, "attachments/1/abc", bytes.NewBufferString("steps"), 5, "text/plain"))
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("GetByID", ctx, uint(5)).Return(&model.Attachment{ID: 5, TodoID: 7, UserID: 1, StorageKey: "attachments/1/abc", Size: 5}, nil)
+
+	attachment, err := f.service.Get(ctx, 7, 5, 1)
+	require.NoError(t, err)
+	id, expires, signature := downloadParams(t, attachment.DownloadURL)
+	assert.Equal(t, uint(5), id)
+
+	opened, blob, err := f.service.Open(ctx, id, expires, signature)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(blob)
+	blob.Close()
+	assert.Equal(t, "steps", string(data))
+	assert.Equal(t, uint(5), opened.ID)
+
+	// The signature covers the attachment and the expiry time
+	_, _, err = f.service.Open(ctx, 6, expires, signature)
+	assert.ErrorIs(t, err, service.ErrInvalidDownloadSignature)
+	_, _, err = f.service.Open(ctx, id, expires+3600, signature)
+	assert.ErrorIs(t, err, service.ErrInvalidDownloadSignature)
+	_, _, err = f.service.Open(ctx, id, expires, "not-hex")
+	assert.ErrorIs(t, err, service.ErrInvalidDownloadSignature)
+
+	// URLs signed with another secret are rejected
+	other := service.NewAttachmentService(f.attachments, f.todos, f.tx, f.store, service.AttachmentLimits{URLSecret: []byte("another-secret")})
+	_, _, err = other.Open(ctx, id, expires, signature)
+	assert.ErrorIs(t, err, service.ErrInvalidDownloadSignature)
+}
+
+func TestAttachmentService_Open_Expired(t *testing.T) {
+	// A negative TTL issues URLs that have already expired
+	f := setupAttachmentService(t, service.AttachmentLimits{URLTTL: -time.Minute})
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("GetByID", ctx, uint(5)).Return(&model.Attachment{ID: 5, TodoID: 7, UserID: 1, StorageKey: "attachments/1/abc"}, nil)
+
+	attachment, err := f.service.Get(ctx, 7, 5, 1)
+	require.NoError(t, err)
+	id, expires, signature := downloadParams(t, attachment.DownloadURL)
+
+	_, _, err = f.service.Open(ctx, id, expires, signature)
+	assert.ErrorIs(t, err, service.ErrDownloadExpired)
+}
+
+func TestAttachmentService_Open_TrashedTodo(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{})
+	ctx := context.Background()
+	require.NoError(t, f.store.Put(ctx, "attachments/1/abc", bytes.NewBufferString("steps"), 5, "text/plain"))
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil).Once()
+	f.attachments.On("GetByID", ctx, uint(5)).Return(&model.Attachment{ID: 5, TodoID: 7, UserID: 1, StorageKey: "attachments/1/abc", Size: 5}, nil)
+
+	attachment, err := f.service.Get(ctx, 7, 5, 1)
+	require.NoError(t, err)
+	id, expires, signature := downloadParams(t, attachment.DownloadURL)
+
+	// The todo was moved to the trash after the URL was issued
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, blob, err := f.service.Open(ctx, id, expires, signature)
+	assert.ErrorIs(t, err, service.ErrAttachmentNotFound)
+	assert.Nil(t, blob)
+}
+
+func TestAttachmentService_Delete(t *testing.T) {
+	f := setupAttachmentService(t, service.AttachmentLimits{})
+	ctx := context.Background()
+	require.NoError(t, f.store.Put(ctx, "attachments/1/abc", bytes.NewBufferString("steps"), 5, "text/plain"))
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.attachments.On("GetByID", ctx, uint(5)).Return(&model.Attachment{ID: 5, TodoID: 7, UserID: 1, StorageKey: "attachments/1/abc"}, nil)
+	f.attachments.On("Delete", ctx, uint(5), uint(7)).Return(nil)
+
+	require.NoError(t, f.service.Delete(ctx, 7, 5, 1))
+
+	_, err := f.store.Get(ctx, "attachments/1/abc")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	f.attachments.AssertExpectations(t)
+}
+
+func TestAttachmentService_Unavailable(t *testing.T) {
+	attachmentService := service.NewAttachmentService(&MockAttachmentRepository{}, &MockTodoRepository{}, &fakeTransactor{}, nil, service.AttachmentLimits{})
+	ctx := context.Background()
+
+	_, err := attachmentService.List(ctx, 7, 1)
+	assert.ErrorIs(t, err, service.ErrAttachmentsUnavailable)
+	_, err = attachmentService.Upload(ctx, 7, 1, "a.png", bytes.NewReader(pngData(64)), 64)
+	assert.ErrorIs(t, err, service.ErrAttachmentsUnavailable)
+	_, _, err = attachmentService.Open(ctx, 5, time.Now().Unix(), "")
+	assert.ErrorIs(t, err, service.ErrAttachmentsUnavailable)
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/audit"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+	"todo-api-backend/pkg/jwt"
+	"todo-api-backend/pkg/password"
+)
+
+// MockSecurityEventRepository is a mock implementation of SecurityEventRepository
+type MockSecurityEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockSecurityEventRepository) Create(ctx context.Context, event *model.SecurityEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockSecurityEventRepository) List(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.SecurityEvent), args.Error(1)
+}
+
+// recordingAuditLog collects the security events recorded by the auth service
+type recordingAuditLog struct {
+	events []*model.SecurityEvent
+}
+
+func (r *recordingAuditLog) Record(ctx context.Context, event *model.SecurityEvent) {
+	r.events = append(r.events, event)
+}
+
+func (r *recordingAuditLog) Query(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	return r.events, nil
+}
+
+// recordingSink collects the security events written to it
+type recordingSink struct {
+	events []*model.SecurityEvent
+	err    error
+}
+
+func (s *recordingSink) Write(ctx context.Context, event *model.SecurityEvent) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func setupAuditedAuthService() (service.AuthService, *MockUserRepository, *recordingAuditLog) {
+	mockUserRepo := &MockUserRepository{}
+	auditLog := &recordingAuditLog{}
+	authService := service.NewAuthService(mockUserRepo, jwt.NewTokenManager("test-secret", 24), service.WithAuditLog(auditLog))
+
+	return authService, mockUserRepo, auditLog
+}
+
+func hashTestPassword(t *testing.T, plain string) string {
+	hashed, err := password.NewHasher().HashPassword(plain)
+	require.NoError(t, err)
+	return hashed
+}
+
+func TestAuthService_Login_RecordsSuccess(t *testing.T) {
+	authService, mockUserRepo, auditLog := setupAuditedAuthService()
+	ctx := context.Background()
+
+	user := &model.User{ID: 1, Email: "test@example.com", Password: hashTestPassword(t, "password123")}
+	mockUserRepo.On("GetByEmail", ctx, user.Email).Return(user, nil)
+
+	_, err := authService.Login(ctx, &model.LoginRequest{Email: user.Email, Password: "password123"})
+
+	assert.NoError(t, err)
+	require.Len(t, auditLog.events, 1)
+	assert.Equal(t, model.SecurityEventLogin, auditLog.events[0].EventType)
+	assert.Equal(t, model.SecurityOutcomeSuccess, auditLog.events[0].Outcome)
+	assert.Equal(t, uint(1), *auditLog.events[0].UserID)
+}
+
+func TestAuthService_Login_RecordsFailures(t *testing.T) {
+	authService, mockUserRepo, auditLog := setupAuditedAuthService()
+	ctx := context.Background()
+
+	user := &model.User{ID: 1, Email: "test@example.com", Password: hashTestPassword(t, "password123")}
+	mockUserRepo.On("GetByEmail", ctx, user.Email).Return(user, nil)
+	mockUserRepo.On("GetByEmail", ctx, "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := authService.Login(ctx, &model.LoginRequest{Email: user.Email, Password: "wrong-password"})
+	assert.Equal(t, service.ErrInvalidCredentials, err)
+
+	_, err = authService.Login(ctx, &model.LoginRequest{Email: "nobody@example.com", Password: "password123"})
+	assert.Equal(t, service.ErrInvalidCredentials, err)
+
+	require.Len(t, auditLog.events, 2)
+	assert.Equal(t, model.SecurityOutcomeFailure, auditLog.events[0].Outcome)
+	assert.Equal(t, "invalid_password", auditLog.events[0].Reason)
+	assert.Equal(t, uint(1), *auditLog.events[0].UserID)
+	assert.Equal(t, "unknown_email", auditLog.events[1].Reason)
+	assert.Nil(t, auditLog.events[1].UserID)
+	assert.Equal(t, "nobody@example.com", auditLog.events[1].Email)
+}
+
+func TestAuthService_Register_RecordsEvents(t *testing.T) {
+	authService, mockUserRepo, auditLog := setupAuditedAuthService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByEmail", ctx, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockUserRepo.On("GetByEmail", ctx, "taken@example.com").Return(&model.User{ID: 2, Email: "taken@example.com"}, nil)
+	mockUserRepo.On("Create", ctx, mock.AnythingOfType("*model.User")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.User).ID = 3
+	})
+
+	_, err := authService.Register(ctx, &model.RegisterRequest{Email: "new@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	_, err = authService.Register(ctx, &model.RegisterRequest{Email: "taken@example.com", Password: "password123"})
+	assert.Equal(t, service.ErrEmailAlreadyExists, err)
+
+	require.Len(t, auditLog.events, 2)
+	assert.Equal(t, model.SecurityEventRegister, auditLog.events[0].EventType)
+	assert.Equal(t, model.SecurityOutcomeSuccess, auditLog.events[0].Outcome)
+	assert.Equal(t, uint(3), *auditLog.events[0].UserID)
+	assert.Equal(t, model.SecurityOutcomeFailure, auditLog.events[1].Outcome)
+	assert.Equal(t, "email_exists", auditLog.events[1].Reason)
+}
+
+func TestAuthService_ChangePassword_Success(t *testing.T) {
+	authService, mockUserRepo, auditLog := setupAuditedAuthService()
+	ctx := context.Background()
+
+	user := &model.User{ID: 1, Email: "test@example.com", Password: hashTestPassword(t, "password123")}
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(user, nil)
+	mockUserRepo.On("Update", ctx, user).Return(nil)
+
+	err := authService.ChangePassword(ctx, uint(1), &model.ChangePasswordRequest{
+		CurrentPassword: "password123",
+		NewPassword:     "newpassword456",
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, password.NewHasher().VerifyPassword(user.Password, "newpassword456"))
+	require.Len(t, auditLog.events, 1)
+	assert.Equal(t, model.SecurityEventPasswordChange, auditLog.events[0].EventType)
+	assert.Equal(t, model.SecurityOutcomeSuccess, auditLog.events[0].Outcome)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestAuthService_ChangePassword_IncorrectCurrentPassword(t *testing.T) {
+	authService, mockUserRepo, auditLog := setupAuditedAuthService()
+	ctx := context.Background()
+
+	user := &model.User{ID: 1, Email: "test@example.com", Password: hashTestPassword(t, "password123")}
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(user, nil)
+
+	err := authService.ChangePassword(ctx, uint(1), &model.ChangePasswordRequest{
+		CurrentPassword: "wrong-password",
+		NewPassword:     "newpassword456",
+	})
+
+	assert.Equal(t, service.ErrIncorrectPassword, err)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	require.Len(t, auditLog.events, 1)
+	assert.Equal(t, model.SecurityOutcomeFailure, auditLog.events[0].Outcome)
+	assert.Equal(t, "invalid_current_password", auditLog.events[0].Reason)
+}
+
+func TestAuthService_ChangePassword_WeakPassword(t *testing.T) {
+	authService, mockUserRepo, auditLog := setupAuditedAuthService()
+	ctx := context.Background()
+
+	user := &model.User{ID: 1, Email: "test@example.com", Password: hashTestPassword(t, "password123")}
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(user, nil)
+
+	err := authService.ChangePassword(ctx, uint(1), &model.ChangePasswordRequest{
+		CurrentPassword: "password123",
+		NewPassword:     "short",
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "password validation failed")
+	require.Len(t, auditLog.events, 1)
+	assert.Equal(t, "weak_password", auditLog.events[0].Reason)
+}
+
+func TestAuditService_Record_AddsClientAndWritesSinks(t *testing.T) {
+	mockRepo := &MockSecurityEventRepository{}
+	sink := &recordingSink{}
+	auditService := service.NewAuditService(mockRepo, sink)
+
+	ctx := audit.WithClient(context.Background(), "203.0.113.7", "test-agent")
+	event := &model.SecurityEvent{EventType: model.SecurityEventLogin, Outcome: model.SecurityOutcomeSuccess}
+
+	mockRepo.On("Create", mock.Anything, event).Return(nil)
+
+	auditService.Record(ctx, event)
+
+	assert.Equal(t, "203.0.113.7", event.IP)
+	assert.Equal(t, "test-agent", event.UserAgent)
+	assert.False(t, event.CreatedAt.IsZero())
+	assert.Equal(t, []*model.SecurityEvent{event}, sink.events)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditService_Record_FailuresDoNotStopOtherSinks(t *testing.T) {
+	mockRepo := &MockSecurityEventRepository{}
+	failingSink := &recordingSink{err: errors.New("disk full")}
+	sink := &recordingSink{}
+	auditService := service.NewAuditService(mockRepo, failingSink, sink)
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	auditService.Record(context.Background(), &model.SecurityEvent{EventType: model.SecurityEventLogin})
+
+	assert.Len(t, failingSink.events, 1)
+	assert.Len(t, sink.events, 1)
+}
+
+func TestAuditService_Query_AppliesLimits(t *testing.T) {
+	mockRepo := &MockSecurityEventRepository{}
+	auditService := service.NewAuditService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("List", ctx, mock.MatchedBy(func(f *model.SecurityEventFilter) bool { return f.Limit == 100 })).Return([]*model.SecurityEvent{}, nil).Once()
+	mockRepo.On("List", ctx, mock.MatchedBy(func(f *model.SecurityEventFilter) bool { return f.Limit == 1000 })).Return([]*model.SecurityEvent{}, nil).Once()
+
+	_, err := auditService.Query(ctx, &model.SecurityEventFilter{})
+	assert.NoError(t, err)
+
+	_, err = auditService.Query(ctx, &model.SecurityEventFilter{Limit: 5000})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
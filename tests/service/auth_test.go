@@ -42,6 +42,19 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uint) (*model.User,
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetWithAutoArchive(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
 // MockTodoRepository is a mock implementation of TodoRepository
 type MockTodoRepository struct {
 	mock.Mock
@@ -68,6 +81,14 @@ func (m *MockTodoRepository) GetByUserID(ctx context.Context, userID uint) ([]*m
 	return args.Get(0).([]*model.Todo), args.Error(1)
 }
 
+func (m *MockTodoRepository) List(ctx context.Context, userID uint, filter *model.TodoFilter) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
 func (m *MockTodoRepository) Update(ctx context.Context, todo *model.Todo) error {
 	args := m.Called(ctx, todo)
 	return args.Error(0)
@@ -78,6 +99,63 @@ func (m *MockTodoRepository) Delete(ctx context.Context, id uint, userID uint) e
 	return args.Error(0)
 }
 
+func (m *MockTodoRepository) GetTrashedByID(ctx context.Context, id uint, userID uint) (*model.Todo, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) GetTrashByUserID(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) Restore(ctx context.Context, id uint, userID uint) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTodoRepository) Purge(ctx context.Context, id uint, userID uint) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTodoRepository) PurgeTrashByUserID(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTodoRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTodoRepository) ArchiveCompletedBefore(ctx context.Context, userID uint, cutoff time.Time, archivedAt time.Time) ([]uint, error) {
+	args := m.Called(ctx, userID, cutoff, archivedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockTodoRepository) ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) StreamByUserID(ctx context.Context, userID uint, filter *model.TodoFilter, fn func(*model.Todo) error) error {
+	args := m.Called(ctx, userID, filter, fn)
+	return args.Error(0)
+}
+
 func setupAuthService() (service.AuthService, *MockUserRepository, *jwt.TokenManager) {
 	mockUserRepo := &MockUserRepository{}
 	tokenManager := jwt.NewTokenManager("test-secret", 24)
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func setupBulkTodoService() (service.TodoService, *MockTodoRepository, *MockUserRepository, *fakeTransactor) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	tx := &fakeTransactor{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(tx))
+
+	return todoService, mockTodoRepo, mockUserRepo, tx
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestTodoService_Bulk_PerItemResults(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, tx := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = 10
+	})
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "One", UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, Title: "Two", UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(3), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockTodoRepo.On("Delete", ctx, uint(2), uint(1)).Return(nil)
+
+	response, err := todoService.Bulk(ctx, uint(1), &model.BulkRequest{
+		Operations: []model.BulkOperation{
+			{Op: model.BulkOpCreate, Title: stringPtr("New")},
+			{Op: model.BulkOpComplete, IDs: []uint{1, 3}},
+			{Op: model.BulkOpDelete, ID: 2},
+			{Op: model.BulkOpCreate},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, response.Committed)
+	assert.Equal(t, 3, response.Succeeded)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, 1, tx.committed)
+
+	require.Len(t, response.Results, 5)
+
+	assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+	assert.Equal(t, uint(10), response.Results[0].ID)
+
+	assert.Equal(t, 1, response.Results[1].Index)
+	assert.Equal(t, http.StatusOK, response.Results[1].Status)
+	assert.True(t, response.Results[1].Todo.Completed)
+
+	assert.Equal(t, 1, response.Results[2].Index)
+	assert.Equal(t, uint(3), response.Results[2].ID)
+	assert.Equal(t, http.StatusNotFound, response.Results[2].Status)
+	assert.Equal(t, "not_found", response.Results[2].Error)
+
+	assert.Equal(t, http.StatusNoContent, response.Results[3].Status)
+
+	assert.Equal(t, 3, response.Results[4].Index)
+	assert.Equal(t, http.StatusBadRequest, response.Results[4].Status)
+	assert.Equal(t, "validation_failed", response.Results[4].Error)
+	assert.Contains(t, response.Results[4].Message, "title")
+}
+
+func TestTodoService_Bulk_AtomicRollsBack(t *testing.T) {
+	todoService, mockTodoRepo, _, tx := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "One", UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	response, err := todoService.Bulk(ctx, uint(1), &model.BulkRequest{
+		Atomic: true,
+		Operations: []model.BulkOperation{
+			{Op: model.BulkOpUpdate, ID: 1, Title: stringPtr("Renamed")},
+			{Op: model.BulkOpDelete, ID: 2},
+			{Op: model.BulkOpDelete, ID: 4},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.False(t, response.Committed)
+	assert.Equal(t, 0, response.Succeeded)
+	assert.Equal(t, 3, response.Failed)
+	assert.Equal(t, 1, tx.rolledBack)
+	assert.Equal(t, 0, tx.committed)
+
+	assert.Equal(t, "rolled_back", response.Results[0].Error)
+	assert.Equal(t, http.StatusFailedDependency, response.Results[0].Status)
+	assert.Nil(t, response.Results[0].Todo)
+	assert.Equal(t, "not_found", response.Results[1].Error)
+	assert.Equal(t, "not_attempted", response.Results[2].Error)
+
+	// The operation after the failure never reached the repository
+	mockTodoRepo.AssertNotCalled(t, "GetByID", ctx, uint(4), uint(1))
+}
+
+func TestTodoService_Bulk_ForbiddenTransition(t *testing.T) {
+	todoService, mockTodoRepo, _, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Card", UserID: 1, ListID: uintPtr(4), State: "backlog"}, nil)
+	mockListRepo.On("GetByID", mock.Anything, uint(4), uint(1)).Return(sprintBoard(), nil)
+
+	response, err := todoService.Bulk(ctx, uint(1), &model.BulkRequest{
+		Operations: []model.BulkOperation{{Op: model.BulkOpComplete, ID: 1}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, http.StatusConflict, response.Results[0].Status)
+	assert.Equal(t, "transition_not_allowed", response.Results[0].Error)
+	mockTodoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_Bulk_MissingID(t *testing.T) {
+	todoService, _, _, _ := setupBulkTodoService()
+
+	response, err := todoService.Bulk(context.Background(), uint(1), &model.BulkRequest{
+		Operations: []model.BulkOperation{{Op: model.BulkOpComplete}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Results[0].Status)
+	assert.Equal(t, "id or ids is required", response.Results[0].Message)
+}
+
+func TestTodoService_Bulk_TooManyItems(t *testing.T) {
+	todoService, _, _, tx := setupBulkTodoService()
+
+	ids := make([]uint, 100)
+	for i := range ids {
+		ids[i] = uint(i + 1)
+	}
+	operations := make([]model.BulkOperation, 6)
+	for i := range operations {
+		operations[i] = model.BulkOperation{Op: model.BulkOpDelete, IDs: ids}
+	}
+
+	response, err := todoService.Bulk(context.Background(), uint(1), &model.BulkRequest{Operations: operations})
+
+	assert.Nil(t, response)
+	assert.Equal(t, service.ErrTooManyBulkItems, err)
+	assert.Equal(t, 0, tx.committed+tx.rolledBack)
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/ical"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockCalDAVPasswordRepository is a mock implementation of CalDAVPasswordRepository
+type MockCalDAVPasswordRepository struct {
+	mock.Mock
+}
+
+func (m *MockCalDAVPasswordRepository) Create(ctx context.Context, password *model.CalDAVPassword) error {
+	return m.Called(ctx, password).Error(0)
+}
+
+func (m *MockCalDAVPasswordRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.CalDAVPassword, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*model.CalDAVPassword), args.Error(1)
+}
+
+func (m *MockCalDAVPasswordRepository) GetByHash(ctx context.Context, passwordHash string) (*model.CalDAVPassword, error) {
+	args := m.Called(ctx, passwordHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalDAVPassword), args.Error(1)
+}
+
+func (m *MockCalDAVPasswordRepository) Touch(ctx context.Context, id uint, usedAt time.Time) error {
+	return m.Called(ctx, id, usedAt).Error(0)
+}
+
+func (m *MockCalDAVPasswordRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+// MockCalDAVResourceRepository is a mock implementation of CalDAVResourceRepository
+type MockCalDAVResourceRepository struct {
+	mock.Mock
+}
+
+func (m *MockCalDAVResourceRepository) Create(ctx context.Context, resource *model.CalDAVResource) error {
+	return m.Called(ctx, resource).Error(0)
+}
+
+func (m *MockCalDAVResourceRepository) GetByName(ctx context.Context, userID uint, name string) (*model.CalDAVResource, error) {
+	args := m.Called(ctx, userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalDAVResource), args.Error(1)
+}
+
+func (m *MockCalDAVResourceRepository) GetByTodoID(ctx context.Context, todoID uint) (*model.CalDAVResource, error) {
+	args := m.Called(ctx, todoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalDAVResource), args.Error(1)
+}
+
+func (m *MockCalDAVResourceRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.CalDAVResource, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*model.CalDAVResource), args.Error(1)
+}
+
+func (m *MockCalDAVResourceRepository) ExistsUID(ctx context.Context, userID uint, uid string) (bool, error) {
+	args := m.Called(ctx, userID, uid)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCalDAVResourceRepository) Release(ctx context.Context, userID uint, name string, uid string) error {
+	return m.Called(ctx, userID, name, uid).Error(0)
+}
+
+type calDAVTestDeps struct {
+	todoRepo  *MockTodoRepository
+	userRepo  *MockUserRepository
+	passwords *MockCalDAVPasswordRepository
+	resources *MockCalDAVResourceRepository
+	tx        *fakeTransactor
+}
+
+func setupCalDAVService() (service.CalDAVService, *calDAVTestDeps) {
+	deps := &calDAVTestDeps{
+		todoRepo:  &MockTodoRepository{},
+		userRepo:  &MockUserRepository{},
+		passwords: &MockCalDAVPasswordRepository{},
+		resources: &MockCalDAVResourceRepository{},
+		tx:        &fakeTransactor{},
+	}
+	todos := service.NewTodoService(deps.todoRepo, deps.userRepo, service.WithTransactor(deps.tx))
+	svc := service.NewCalDAVService(todos, deps.todoRepo, deps.userRepo, deps.passwords, deps.resources, &MockCalendarFeedRepository{}, deps.tx)
+	return svc, deps
+}
+
+func calDAVTodo(uid, status string) []byte {
+	return []byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:" + uid +
+		"\r\nSUMMARY:Water plants\r\nSTATUS:" + status + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n")
+}
+
+func TestCalDAVService_CreatePassword(t *testing.T) {
+	svc, deps := setupCalDAVService()
+	ctx := context.Background()
+
+	var stored *model.CalDAVPassword
+	deps.passwords.On("Create", ctx, mock.AnythingOfType("*model.CalDAVPassword")).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*model.CalDAVPassword)
+	})
+
+	password, err := svc.CreatePassword(ctx, 1, &model.CreateCalDAVPasswordRequest{Name: "Phone"})
+
+	require.NoError(t, err)
+	assert.Regexp(t, `^dav_[A-Za-z0-9_-]{32}$`, password.Password)
+	assert.Equal(t, "Phone", password.Name)
+	// Only a hash of the password is stored
+	assert.NotContains(t, stored.PasswordHash, password.Password)
+	assert.Len(t, stored.PasswordHash, 64)
+
+	t.Run("authenticates with the email address of the owner", func(t *testing.T) {
+		deps.passwords.On("GetByHash", ctx, stored.PasswordHash).Return(&model.CalDAVPassword{ID: 3, UserID: 1}, nil)
+		deps.passwords.On("Touch", ctx, uint(3), mock.AnythingOfType("time.Time")).Return(nil)
+		deps.userRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1, Email: "test@example.com"}, nil)
+
+		userID, err := svc.Authenticate(ctx, "Test@Example.com", password.Password)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), userID)
+		deps.passwords.AssertCalled(t, "Touch", ctx, uint(3), mock.AnythingOfType("time.Time"))
+	})
+
+	t.Run("rejects another email address", func(t *testing.T) {
+		_, err := svc.Authenticate(ctx, "other@example.com", password.Password)
+
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	})
+}
+
+func TestCalDAVService_Authenticate_UnknownPassword(t *testing.T) {
+	svc, deps := setupCalDAVService()
+	ctx := context.Background()
+	deps.passwords.On("GetByHash", ctx, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.Authenticate(ctx, "test@example.com", "dav_wrong")
+
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestCalDAVService_Authenticate_RecentUseNotTouched(t *testing.T) {
+	svc, deps := setupCalDAVService()
+	ctx := context.Background()
+	usedAt := time.Now().Add(-10 * time.Second)
+	deps.passwords.On("GetByHash", ctx, mock.Anything).Return(&model.CalDAVPassword{ID: 3, UserID: 1, LastUsedAt: &usedAt}, nil)
+	deps.userRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1, Email: "test@example.com"}, nil)
+
+	_, err := svc.Authenticate(ctx, "test@example.com", "dav_secret")
+
+	require.NoError(t, err)
+	deps.passwords.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCalDAVService_DeletePassword_NotFound(t *testing.T) {
+	svc, deps := setupCalDAVService()
+	ctx := context.Background()
+	deps.passwords.On("Delete", ctx, uint(3), uint(1)).Return(gorm.ErrRecordNotFound)
+
+	err := svc.DeletePassword(ctx, 3, 1)
+
+	assert.ErrorIs(t, err, service.ErrCalDAVPasswordNotFound)
+}
+
+func TestCalDAVService_Get_DefaultName(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("todo created through the API", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		deps.resources.On("GetByName", ctx, uint(1), "todo-7.ics").Return(nil, gorm.ErrRecordNotFound)
+		deps.resources.On("GetByTodoID", ctx, uint(7)).Return(nil, gorm.ErrRecordNotFound)
+		deps.todoRepo.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1, Title: "Call mom"}, nil)
+
+		object, err := svc.Get(ctx, 1, "todo-7.ics")
+
+		require.NoError(t, err)
+		assert.Equal(t, "todo-7.ics", object.Name)
+		assert.Equal(t, ical.TodoUID(7, ical.DefaultDomain), object.UID)
+	})
+
+	t.Run("todo created through CalDAV is only found under its own name", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		deps.resources.On("GetByName", ctx, uint(1), "todo-7.ics").Return(nil, gorm.ErrRecordNotFound)
+		deps.resources.On("GetByTodoID", ctx, uint(7)).Return(&model.CalDAVResource{TodoID: 7, Name: "abc.ics"}, nil)
+
+		_, err := svc.Get(ctx, 1, "todo-7.ics")
+
+		assert.ErrorIs(t, err, service.ErrCalDAVObjectNotFound)
+	})
+
+	t.Run("archived todo", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		archivedAt := time.Now()
+		deps.resources.On("GetByName", ctx, uint(1), "todo-7.ics").Return(nil, gorm.ErrRecordNotFound)
+		deps.resources.On("GetByTodoID", ctx, uint(7)).Return(nil, gorm.ErrRecordNotFound)
+		deps.todoRepo.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1, ArchivedAt: &archivedAt}, nil)
+
+		_, err := svc.Get(ctx, 1, "todo-7.ics")
+
+		assert.ErrorIs(t, err, service.ErrCalDAVObjectNotFound)
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		deps.resources.On("GetByName", ctx, uint(1), "abc.ics").Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.Get(ctx, 1, "abc.ics")
+
+		assert.ErrorIs(t, err, service.ErrCalDAVObjectNotFound)
+	})
+}
+
+func TestCalDAVService_Put_Create(t *testing.T) {
+	svc, deps := setupCalDAVService()
+	ctx := context.Background()
+
+	deps.resources.On("GetByName", mock.Anything, uint(1), "abc.ics").Return(nil, gorm.ErrRecordNotFound)
+	deps.resources.On("Release", mock.Anything, uint(1), "abc.ics", "abc@phone").Return(nil)
+	deps.resources.On("ExistsUID", mock.Anything, uint(1), "abc@phone").Return(false, nil)
+	deps.userRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	deps.todoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = 9
+	})
+	deps.todoRepo.On("GetByID", mock.Anything, uint(9), uint(1)).Return(&model.Todo{ID: 9, UserID: 1, Title: "Water plants"}, nil)
+	deps.todoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+	deps.resources.On("Create", mock.Anything, &model.CalDAVResource{TodoID: 9, UserID: 1, Name: "abc.ics", UID: "abc@phone"}).Return(nil)
+
+	object, created, err := svc.Put(ctx, 1, "abc.ics", calDAVTodo("abc@phone", "COMPLETED"), true)
+
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "abc.ics", object.Name)
+	assert.Equal(t, "abc@phone", object.UID)
+	assert.True(t, object.Todo.Completed)
+	assert.Equal(t, 1, deps.tx.committed)
+}
+
+func TestCalDAVService_Put_UIDConflict(t *testing.T) {
+	svc, deps := setupCalDAVService()
+	ctx := context.Background()
+
+	deps.resources.On("GetByName", mock.Anything, uint(1), "other.ics").Return(nil, gorm.ErrRecordNotFound)
+	deps.resources.On("Release", mock.Anything, uint(1), "other.ics", "abc@phone").Return(nil)
+	deps.resources.On("ExistsUID", mock.Anything, uint(1), "abc@phone").Return(true, nil)
+
+	_, _, err := svc.Put(ctx, 1, "other.ics", calDAVTodo("abc@phone", "NEEDS-ACTION"), false)
+
+	assert.ErrorIs(t, err, service.ErrCalDAVUIDConflict)
+	assert.Equal(t, 1, deps.tx.rolledBack)
+	deps.todoRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCalDAVService_Put_Update(t *testing.T) {
+	ctx := context.Background()
+	existing := func(deps *calDAVTestDeps) {
+		deps.resources.On("GetByName", mock.Anything, uint(1), "abc.ics").Return(&model.CalDAVResource{TodoID: 9, UserID: 1, Name: "abc.ics", UID: "abc@phone"}, nil)
+		deps.todoRepo.On("GetByID", mock.Anything, uint(9), uint(1)).Return(&model.Todo{ID: 9, UserID: 1, Title: "Water plants", Version: 2}, nil)
+	}
+
+	t.Run("marks the todo completed", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		existing(deps)
+		deps.todoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+		object, created, err := svc.Put(ctx, 1, "abc.ics", calDAVTodo("abc@phone", "COMPLETED"), false)
+
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.True(t, object.Todo.Completed)
+		assert.NotNil(t, object.Todo.CompletedAt)
+	})
+
+	t.Run("create only", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		existing(deps)
+
+		_, _, err := svc.Put(ctx, 1, "abc.ics", calDAVTodo("abc@phone", "COMPLETED"), true)
+
+		assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		existing(deps)
+
+		_, _, err := svc.Put(service.WithIfMatch(ctx, 1), 1, "abc.ics", calDAVTodo("abc@phone", "COMPLETED"), false)
+
+		assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+	})
+
+	t.Run("different UID", func(t *testing.T) {
+		svc, deps := setupCalDAVService()
+		existing(deps)
+
+		_, _, err := svc.Put(ctx, 1, "abc.ics", calDAVTodo("xyz@phone", "COMPLETED"), false)
+
+		assert.ErrorIs(t, err, service.ErrCalDAVUIDConflict)
+	})
+}
+
+func TestCalDAVService_Put_InvalidData(t *testing.T) {
+	svc, _ := setupCalDAVService()
+	ctx := context.Background()
+
+	_, _, err := svc.Put(ctx, 1, "abc.ics", []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), false)
+
+	assert.ErrorIs(t, err, service.ErrInvalidCalendarData)
+	assert.True(t, errors.Is(err, ical.ErrNoTodo))
+}
+
+func TestCalDAVService_Put_IfMatchOnMissingObject(t *testing.T) {
+	svc, deps := setupCalDAVService()
+	ctx := service.WithIfMatch(context.Background(), 1)
+	deps.resources.On("GetByName", mock.Anything, uint(1), "abc.ics").Return(nil, gorm.ErrRecordNotFound)
+
+	_, _, err := svc.Put(ctx, 1, "abc.ics", calDAVTodo("abc@phone", "NEEDS-ACTION"), false)
+
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockCalendarFeedRepository is a mock implementation of CalendarFeedRepository
+type MockCalendarFeedRepository struct {
+	mock.Mock
+}
+
+func (m *MockCalendarFeedRepository) Save(ctx context.Context, feed *model.CalendarFeed) error {
+	return m.Called(ctx, feed).Error(0)
+}
+
+func (m *MockCalendarFeedRepository) GetByUserID(ctx context.Context, userID uint) (*model.CalendarFeed, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarFeed), args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.CalendarFeed, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarFeed), args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) Delete(ctx context.Context, userID uint) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockCalendarFeedRepository) Stamp(ctx context.Context, userID uint) (*model.CalendarStamp, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarStamp), args.Error(1)
+}
+
+func setupCalendarService() (service.CalendarService, *MockCalendarFeedRepository, *MockTodoRepository) {
+	mockFeedRepo := &MockCalendarFeedRepository{}
+	mockTodoRepo := &MockTodoRepository{}
+	return service.NewCalendarService(mockFeedRepo, mockTodoRepo), mockFeedRepo, mockTodoRepo
+}
+
+func TestCalendarService_GetFeed(t *testing.T) {
+	t.Run("no feed", func(t *testing.T) {
+		svc, mockFeedRepo, _ := setupCalendarService()
+		mockFeedRepo.On("GetByUserID", mock.Anything, uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		feed, err := svc.GetFeed(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.False(t, feed.Enabled)
+		assert.Nil(t, feed.CreatedAt)
+	})
+
+	t.Run("existing feed", func(t *testing.T) {
+		svc, mockFeedRepo, _ := setupCalendarService()
+		createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		mockFeedRepo.On("GetByUserID", mock.Anything, uint(1)).Return(&model.CalendarFeed{UserID: 1, TokenHash: "abc", CreatedAt: createdAt}, nil)
+
+		feed, err := svc.GetFeed(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.True(t, feed.Enabled)
+		assert.Equal(t, createdAt, *feed.CreatedAt)
+		assert.Empty(t, feed.Token, "the token cannot be recovered")
+	})
+}
+
+func TestCalendarService_RegenerateToken(t *testing.T) {
+	svc, mockFeedRepo, _ := setupCalendarService()
+
+	var saved []*model.CalendarFeed
+	mockFeedRepo.On("Save", mock.Anything, mock.AnythingOfType("*model.CalendarFeed")).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(*model.CalendarFeed))
+	}).Return(nil)
+
+	first, err := svc.RegenerateToken(context.Background(), 1)
+	require.NoError(t, err)
+	second, err := svc.RegenerateToken(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first.Token, "cal_"))
+	assert.NotEqual(t, first.Token, second.Token)
+	require.Len(t, saved, 2)
+	assert.Equal(t, uint(1), saved[0].UserID)
+	assert.Len(t, saved[0].TokenHash, 64)
+	assert.NotContains(t, saved[0].TokenHash, first.Token, "only a hash of the token is stored")
+	assert.NotEqual(t, saved[0].TokenHash, saved[1].TokenHash)
+}
+
+func TestCalendarService_ResolveToken(t *testing.T) {
+	svc, mockFeedRepo, _ := setupCalendarService()
+
+	var hash string
+	mockFeedRepo.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		hash = args.Get(1).(*model.CalendarFeed).TokenHash
+	}).Return(nil)
+
+	feed, err := svc.RegenerateToken(context.Background(), 4)
+	require.NoError(t, err)
+
+	mockFeedRepo.On("GetByTokenHash", mock.Anything, hash).Return(&model.CalendarFeed{UserID: 4, TokenHash: hash}, nil)
+	mockFeedRepo.On("GetByTokenHash", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	userID, err := svc.ResolveToken(context.Background(), feed.Token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), userID)
+
+	_, err = svc.ResolveToken(context.Background(), "cal_unknown")
+	assert.Equal(t, service.ErrCalendarFeedNotFound, err)
+}
+
+func TestCalendarService_DisableFeed(t *testing.T) {
+	svc, mockFeedRepo, _ := setupCalendarService()
+	mockFeedRepo.On("Delete", mock.Anything, uint(1)).Return(nil).Once()
+	mockFeedRepo.On("Delete", mock.Anything, uint(1)).Return(gorm.ErrRecordNotFound).Once()
+
+	assert.NoError(t, svc.DisableFeed(context.Background(), 1))
+	assert.Equal(t, service.ErrCalendarFeedNotFound, svc.DisableFeed(context.Background(), 1))
+}
+
+func TestCalendarService_FeedTodos_ExcludesArchived(t *testing.T) {
+	svc, _, mockTodoRepo := setupCalendarService()
+
+	todos := []*model.Todo{{ID: 1, UserID: 1}, {ID: 2, UserID: 1}}
+	mockTodoRepo.On("StreamByUserID", mock.Anything, uint(1), mock.MatchedBy(func(filter *model.TodoFilter) bool {
+		return filter.Archived == model.ArchivedExclude
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(3).(func(*model.Todo) error)
+		for _, todo := range todos {
+			_ = fn(todo)
+		}
+	}).Return(nil)
+
+	var ids []uint
+	err := svc.FeedTodos(context.Background(), 1, func(todo *model.Todo) error {
+		ids = append(ids, todo.ID)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 2}, ids)
+	mockTodoRepo.AssertExpectations(t)
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockCommentRepository is a mock implementation of CommentRepository
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) Create(ctx context.Context, comment *model.TodoComment) error {
+	return m.Called(ctx, comment).Error(0)
+}
+
+func (m *MockCommentRepository) GetByID(ctx context.Context, id uint, todoID uint) (*model.TodoComment, error) {
+	args := m.Called(ctx, id, todoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoComment), args.Error(1)
+}
+
+func (m *MockCommentRepository) ListThreads(ctx context.Context, todoID uint, offset, limit int) ([]*model.TodoComment, error) {
+	args := m.Called(ctx, todoID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoComment), args.Error(1)
+}
+
+func (m *MockCommentRepository) CountThreads(ctx context.Context, todoID uint) (int64, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) ListReplies(ctx context.Context, parentIDs []uint) ([]*model.TodoComment, error) {
+	args := m.Called(ctx, parentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoComment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Update(ctx context.Context, comment *model.TodoComment) error {
+	return m.Called(ctx, comment).Error(0)
+}
+
+func (m *MockCommentRepository) Delete(ctx context.Context, id uint, todoID uint) error {
+	return m.Called(ctx, id, todoID).Error(0)
+}
+
+// recordingNotifier records the users notified of mentions
+type recordingNotifier struct {
+	userIDs []uint
+	err     error
+}
+
+func (n *recordingNotifier) NotifyMention(ctx context.Context, userID uint, todo *model.Todo, comment *model.TodoComment) error {
+	n.userIDs = append(n.userIDs, userID)
+	return n.err
+}
+
+type commentFixture struct {
+	todoService service.TodoService
+	todos       *MockTodoRepository
+	users       *MockUserRepository
+	comments    *MockCommentRepository
+	notifier    *recordingNotifier
+	tx          *fakeTransactor
+}
+
+func setupCommentTodoService() *commentFixture {
+	f := &commentFixture{
+		todos:    &MockTodoRepository{},
+		users:    &MockUserRepository{},
+		comments: &MockCommentRepository{},
+		notifier: &recordingNotifier{},
+		tx:       &fakeTransactor{},
+	}
+	f.todoService = service.NewTodoService(f.todos, f.users,
+		service.WithTransactor(f.tx),
+		service.WithComments(f.comments),
+		service.WithMentionNotifier(f.notifier))
+	return f
+}
+
+func TestTodoService_AddComment_ResolvesMentions(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.users.On("GetByEmail", ctx, "owner@example.com").Return(&model.User{ID: 1, Email: "owner@example.com"}, nil)
+	f.users.On("GetByEmail", ctx, "jane@example.com").Return(&model.User{ID: 2, Email: "jane@example.com"}, nil)
+	f.users.On("GetByEmail", ctx, "ghost@example.com").Return(nil, gorm.ErrRecordNotFound)
+	f.comments.On("Create", ctx, mock.AnythingOfType("*model.TodoComment")).Return(nil)
+
+	body := "Repro steps below, @owner@example.com. cc @jane@example.com @ghost@example.com @owner@example.com\n\n`mail owner@example.com`"
+	comment, err := f.todoService.AddComment(ctx, 7, &model.CreateCommentRequest{Body: body}, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, body, comment.Body)
+	assert.Nil(t, comment.ParentID)
+	// jane has no access to the todo and ghost is not a user
+	assert.Equal(t, []model.CommentMention{{UserID: 1, Email: "owner@example.com"}}, comment.Mentions)
+	assert.Equal(t, []uint{1}, f.notifier.userIDs)
+	assert.Equal(t, 1, f.tx.committed)
+	f.users.AssertNumberOfCalls(t, "GetByEmail", 3)
+}
+
+func TestTodoService_AddComment_MentionsOnlyResolveToOwner(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	// Todos are not shared, so other users cannot be mentioned even if they exist
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.users.On("GetByEmail", ctx, "jane@example.com").Return(&model.User{ID: 2, Email: "jane@example.com"}, nil)
+	f.comments.On("Create", ctx, mock.AnythingOfType("*model.TodoComment")).Return(nil)
+
+	comment, err := f.todoService.AddComment(ctx, 7, &model.CreateCommentRequest{Body: "@jane@example.com can you review?"}, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "@jane@example.com can you review?", comment.Body)
+	assert.Empty(t, comment.Mentions)
+	assert.Empty(t, f.notifier.userIDs)
+}
+
+func TestTodoService_AddComment_ReplyJoinsThread(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.comments.On("GetByID", ctx, uint(4), uint(7)).Return(&model.TodoComment{ID: 4, TodoID: 7, ParentID: uintPtr(3)}, nil)
+	f.comments.On("Create", ctx, mock.AnythingOfType("*model.TodoComment")).Return(nil)
+
+	comment, err := f.todoService.AddComment(ctx, 7, &model.CreateCommentRequest{Body: "Agreed", ParentID: uintPtr(4)}, 1)
+
+	require.NoError(t, err)
+	require.NotNil(t, comment.ParentID)
+	assert.Equal(t, uint(3), *comment.ParentID)
+	assert.Empty(t, comment.Mentions)
+	assert.Empty(t, f.notifier.userIDs)
+}
+
+func TestTodoService_AddComment_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("blank body", func(t *testing.T) {
+		f := setupCommentTodoService()
+		_, err := f.todoService.AddComment(ctx, 7, &model.CreateCommentRequest{Body: " \n\t"}, 1)
+		assert.ErrorIs(t, err, service.ErrEmptyComment)
+	})
+
+	t.Run("todo of another user", func(t *testing.T) {
+		f := setupCommentTodoService()
+		f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		_, err := f.todoService.AddComment(ctx, 7, &model.CreateCommentRequest{Body: "Hi"}, 1)
+		assert.ErrorIs(t, err, service.ErrTodoNotFound)
+	})
+
+	t.Run("parent on another todo", func(t *testing.T) {
+		f := setupCommentTodoService()
+		f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+		f.comments.On("GetByID", ctx, uint(9), uint(7)).Return(nil, gorm.ErrRecordNotFound)
+		_, err := f.todoService.AddComment(ctx, 7, &model.CreateCommentRequest{Body: "Hi", ParentID: uintPtr(9)}, 1)
+		assert.ErrorIs(t, err, service.ErrParentCommentNotFound)
+		f.comments.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("notification failure", func(t *testing.T) {
+		f := setupCommentTodoService()
+		f.notifier.err = errors.New("queue unavailable")
+		f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+		f.users.On("GetByEmail", ctx, "owner@example.com").Return(&model.User{ID: 1, Email: "owner@example.com"}, nil)
+		f.comments.On("Create", ctx, mock.AnythingOfType("*model.TodoComment")).Return(nil)
+		_, err := f.todoService.AddComment(ctx, 7, &model.CreateCommentRequest{Body: "@owner@example.com"}, 1)
+		assert.ErrorContains(t, err, "queue unavailable")
+		assert.Equal(t, 1, f.tx.rolledBack)
+	})
+}
+
+func TestTodoService_UpdateComment_NotifiesNewMentions(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.users.On("GetByEmail", ctx, "owner@example.com").Return(&model.User{ID: 1, Email: "owner@example.com"}, nil)
+	f.comments.On("GetByID", ctx, uint(3), uint(7)).Return(&model.TodoComment{ID: 3, TodoID: 7, UserID: 1, Body: "Draft"}, nil).Once()
+	f.comments.On("Update", ctx, mock.AnythingOfType("*model.TodoComment")).Return(nil)
+
+	comment, err := f.todoService.UpdateComment(ctx, 7, 3, &model.UpdateCommentRequest{Body: "Ready, @owner@example.com"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Ready, @owner@example.com", comment.Body)
+	assert.Len(t, comment.Mentions, 1)
+	assert.Equal(t, []uint{1}, f.notifier.userIDs)
+
+	// Editing again does not notify the users mentioned before
+	f.comments.On("GetByID", ctx, uint(3), uint(7)).Return(comment, nil).Once()
+	_, err = f.todoService.UpdateComment(ctx, 7, 3, &model.UpdateCommentRequest{Body: "Ready for review, @owner@example.com"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1}, f.notifier.userIDs)
+}
+
+func TestTodoService_ChangeComment_OnlyByAuthor(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.comments.On("GetByID", ctx, uint(3), uint(7)).Return(&model.TodoComment{ID: 3, TodoID: 7, UserID: 2, Body: "Theirs"}, nil)
+
+	_, err := f.todoService.UpdateComment(ctx, 7, 3, &model.UpdateCommentRequest{Body: "Mine now"}, 1)
+	assert.ErrorIs(t, err, service.ErrCommentNotAuthor)
+	err = f.todoService.DeleteComment(ctx, 7, 3, 1)
+	assert.ErrorIs(t, err, service.ErrCommentNotAuthor)
+
+	f.comments.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	f.comments.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTodoService_DeleteComment(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.comments.On("GetByID", ctx, uint(3), uint(7)).Return(&model.TodoComment{ID: 3, TodoID: 7, UserID: 1}, nil)
+	f.comments.On("GetByID", ctx, uint(4), uint(7)).Return(nil, gorm.ErrRecordNotFound)
+	f.comments.On("Delete", ctx, uint(3), uint(7)).Return(nil)
+
+	assert.NoError(t, f.todoService.DeleteComment(ctx, 7, 3, 1))
+	assert.ErrorIs(t, f.todoService.DeleteComment(ctx, 7, 4, 1), service.ErrCommentNotFound)
+	f.comments.AssertExpectations(t)
+}
+
+func TestTodoService_ListComments_Paginates(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.comments.On("CountThreads", ctx, uint(7)).Return(int64(5), nil)
+	f.comments.On("ListThreads", ctx, uint(7), 2, 2).Return([]*model.TodoComment{{ID: 5}, {ID: 8}}, nil)
+	f.comments.On("ListReplies", ctx, []uint{5, 8}).Return([]*model.TodoComment{
+		{ID: 6, ParentID: uintPtr(5)},
+		{ID: 9, ParentID: uintPtr(8)},
+		{ID: 10, ParentID: uintPtr(5)},
+	}, nil)
+
+	page, err := f.todoService.ListComments(ctx, 7, 1, 2, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), page.Total)
+	assert.Equal(t, 2, page.Page)
+	assert.Equal(t, 2, page.PerPage)
+	require.Len(t, page.Comments, 2)
+	require.Len(t, page.Comments[0].Replies, 2)
+	assert.Equal(t, uint(6), page.Comments[0].Replies[0].ID)
+	assert.Equal(t, uint(10), page.Comments[0].Replies[1].ID)
+	require.Len(t, page.Comments[1].Replies, 1)
+	assert.Equal(t, uint(9), page.Comments[1].Replies[0].ID)
+}
+
+func TestTodoService_ListComments_ClampsPageSize(t *testing.T) {
+	f := setupCommentTodoService()
+	ctx := context.Background()
+
+	f.todos.On("GetByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1}, nil)
+	f.comments.On("CountThreads", ctx, uint(7)).Return(int64(0), nil)
+	f.comments.On("ListThreads", ctx, uint(7), 0, 20).Return([]*model.TodoComment{}, nil).Once()
+	f.comments.On("ListThreads", ctx, uint(7), 0, 100).Return([]*model.TodoComment{}, nil).Once()
+	f.comments.On("ListReplies", ctx, []uint{}).Return([]*model.TodoComment{}, nil)
+
+	page, err := f.todoService.ListComments(ctx, 7, 1, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, 20, page.PerPage)
+	assert.NotNil(t, page.Comments)
+
+	page, err = f.todoService.ListComments(ctx, 7, 1, 1, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 100, page.PerPage)
+	f.comments.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/internal/service"
+)
+
+func TestTodoService_Replace_IfMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		expectedErr error
+	}{
+		{"unconditional", context.Background(), nil},
+		{"matching version", service.WithIfMatch(context.Background(), 1, 4), nil},
+		{"stale version", service.WithIfMatch(context.Background(), 3), service.ErrPreconditionFailed},
+		{"no acceptable version", service.WithIfMatch(context.Background()), service.ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+
+			mockTodoRepo.On("GetByID", tt.ctx, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Todo", UserID: 1, Version: 4}, nil)
+			mockTodoRepo.On("Update", tt.ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+			_, err := todoService.Replace(tt.ctx, uint(1), &model.ReplaceTodoRequest{Title: "Updated"}, uint(1))
+
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr != nil {
+				mockTodoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTodoService_Update_VersionConflict(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		expectedErr error
+	}{
+		{"unconditional write reports a concurrent update", context.Background(), service.ErrConcurrentUpdate},
+		{"conditional write loses its precondition", service.WithIfMatch(context.Background(), 4), service.ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, mockTodoRepo, _, tx := setupBulkTodoService()
+
+			title := "Updated"
+			mockTodoRepo.On("GetByID", tt.ctx, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Todo", UserID: 1, Version: 4}, nil)
+			mockTodoRepo.On("Update", tt.ctx, mock.AnythingOfType("*model.Todo")).Return(repository.ErrVersionConflict)
+
+			_, err := todoService.Update(tt.ctx, uint(1), &model.UpdateTodoRequest{Title: &title}, uint(1))
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, 1, tx.rolledBack)
+		})
+	}
+}
+
+func TestTodoService_Delete_IfMatch(t *testing.T) {
+	todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+	ctx := service.WithIfMatch(context.Background(), 1)
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Todo", UserID: 1, Version: 2}, nil)
+
+	err := todoService.Delete(ctx, uint(1), uint(1))
+
+	assert.Equal(t, service.ErrPreconditionFailed, err)
+	mockTodoRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
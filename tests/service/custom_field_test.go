@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+	"todo-api-backend/pkg/validator"
+)
+
+// MockCustomFieldRepository is a mock implementation of CustomFieldRepository
+type MockCustomFieldRepository struct {
+	mock.Mock
+}
+
+func (m *MockCustomFieldRepository) Create(ctx context.Context, field *model.CustomField) error {
+	args := m.Called(ctx, field)
+	return args.Error(0)
+}
+
+func (m *MockCustomFieldRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.CustomField, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.CustomField, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldRepository) ListForTodo(ctx context.Context, userID uint, listID *uint) ([]*model.CustomField, error) {
+	args := m.Called(ctx, userID, listID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCustomFieldRepository) ExistsKey(ctx context.Context, userID uint, key string) (bool, error) {
+	args := m.Called(ctx, userID, key)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCustomFieldRepository) Update(ctx context.Context, field *model.CustomField) error {
+	args := m.Called(ctx, field)
+	return args.Error(0)
+}
+
+func (m *MockCustomFieldRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockCustomFieldRepository) RemoveValues(ctx context.Context, userID uint, key string) error {
+	args := m.Called(ctx, userID, key)
+	return args.Error(0)
+}
+
+// teamFields returns custom fields for story points, a customer, a link, a priority and a sign-off
+func teamFields() []*model.CustomField {
+	one, thirteen, hundred := 1.0, 13.0, 100.0
+	return []*model.CustomField{
+		{ID: 1, UserID: 1, Key: "points", Name: "Story points", Type: model.CustomFieldNumber, Rules: model.CustomFieldRules{Min: &one, Max: &thirteen}},
+		{ID: 2, UserID: 1, Key: "customer", Name: "Customer", Type: model.CustomFieldText, Required: true, Rules: model.CustomFieldRules{Max: &hundred}},
+		{ID: 3, UserID: 1, Key: "link", Name: "Link", Type: model.CustomFieldText, Rules: model.CustomFieldRules{Format: "url"}},
+		{ID: 4, UserID: 1, Key: "release", Name: "Release", Type: model.CustomFieldDate},
+		{ID: 5, UserID: 1, ListID: uintPtr(4), Key: "priority", Name: "Priority", Type: model.CustomFieldSelect, Rules: model.CustomFieldRules{Options: []string{"Low", "Very high"}}},
+		{ID: 6, UserID: 1, Key: "signed_off", Name: "Signed off", Type: model.CustomFieldCheckbox},
+	}
+}
+
+func setupCustomFieldService() (service.CustomFieldService, *MockCustomFieldRepository, *MockListRepository, *fakeTransactor) {
+	mockFieldRepo := &MockCustomFieldRepository{}
+	mockListRepo := &MockListRepository{}
+	tx := &fakeTransactor{}
+	return service.NewCustomFieldService(mockFieldRepo, mockListRepo, tx), mockFieldRepo, mockListRepo, tx
+}
+
+func setupCustomFieldTodoService() (service.TodoService, *MockTodoRepository, *MockUserRepository, *MockCustomFieldRepository) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockFieldRepo := &MockCustomFieldRepository{}
+	mockListRepo := &MockListRepository{}
+	mockListRepo.On("GetByID", mock.Anything, uint(4), uint(1)).Return(sprintBoard(), nil)
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(&fakeTransactor{}), service.WithLists(mockListRepo), service.WithCustomFields(mockFieldRepo))
+
+	return todoService, mockTodoRepo, mockUserRepo, mockFieldRepo
+}
+
+func TestCustomFieldService_Create(t *testing.T) {
+	fieldService, mockFieldRepo, mockListRepo, tx := setupCustomFieldService()
+	ctx := context.Background()
+
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+	mockFieldRepo.On("CountByUserID", ctx, uint(1)).Return(int64(3), nil)
+	mockFieldRepo.On("ExistsKey", ctx, uint(1), "priority").Return(false, nil)
+	mockFieldRepo.On("RemoveValues", ctx, uint(1), "priority").Return(nil)
+	mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*model.CustomField")).Return(nil)
+
+	field, err := fieldService.Create(ctx, 1, &model.CreateCustomFieldRequest{
+		Key:    "priority",
+		Name:   "Priority",
+		Type:   model.CustomFieldSelect,
+		ListID: uintPtr(4),
+		Rules:  model.CustomFieldRules{Options: []string{"Low", "High"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), *field.ListID)
+	assert.Equal(t, 1, tx.committed)
+	mockFieldRepo.AssertExpectations(t)
+}
+
+func TestCustomFieldService_Create_KeyExists(t *testing.T) {
+	fieldService, mockFieldRepo, _, tx := setupCustomFieldService()
+	ctx := context.Background()
+
+	mockFieldRepo.On("CountByUserID", ctx, uint(1)).Return(int64(3), nil)
+	mockFieldRepo.On("ExistsKey", ctx, uint(1), "points").Return(true, nil)
+
+	_, err := fieldService.Create(ctx, 1, &model.CreateCustomFieldRequest{Key: "points", Name: "Points", Type: model.CustomFieldNumber})
+
+	assert.Equal(t, service.ErrCustomFieldExists, err)
+	assert.Equal(t, 1, tx.rolledBack)
+	mockFieldRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCustomFieldService_Create_InvalidRules(t *testing.T) {
+	fieldService, mockFieldRepo, _, _ := setupCustomFieldService()
+	ctx := context.Background()
+	five, two := 5.0, 2.0
+
+	tests := []struct {
+		name    string
+		req     model.CreateCustomFieldRequest
+		message string
+	}{
+		{
+			name:    "key with uppercase letters",
+			req:     model.CreateCustomFieldRequest{Key: "Points", Type: model.CustomFieldNumber},
+			message: "must be lowercase letters, digits and underscores",
+		},
+		{
+			name:    "min greater than max",
+			req:     model.CreateCustomFieldRequest{Key: "points", Type: model.CustomFieldNumber, Rules: model.CustomFieldRules{Min: &five, Max: &two}},
+			message: "min must not be greater than max",
+		},
+		{
+			name:    "bounds on a date field",
+			req:     model.CreateCustomFieldRequest{Key: "release", Type: model.CustomFieldDate, Rules: model.CustomFieldRules{Min: &two}},
+			message: "min and max only apply to number and text fields",
+		},
+		{
+			name:    "format on a number field",
+			req:     model.CreateCustomFieldRequest{Key: "points", Type: model.CustomFieldNumber, Rules: model.CustomFieldRules{Format: "url"}},
+			message: "format only applies to text fields",
+		},
+		{
+			name:    "select without options",
+			req:     model.CreateCustomFieldRequest{Key: "priority", Type: model.CustomFieldSelect},
+			message: "select fields need at least one option",
+		},
+		{
+			name:    "duplicate options",
+			req:     model.CreateCustomFieldRequest{Key: "priority", Type: model.CustomFieldSelect, Rules: model.CustomFieldRules{Options: []string{"Low", "Low"}}},
+			message: `duplicate option "Low"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fieldService.Create(ctx, 1, &tt.req)
+			assert.True(t, errors.Is(err, service.ErrInvalidCustomField))
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+	mockFieldRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCustomFieldService_Delete_RemovesValues(t *testing.T) {
+	fieldService, mockFieldRepo, _, tx := setupCustomFieldService()
+	ctx := context.Background()
+
+	mockFieldRepo.On("GetByID", ctx, uint(1), uint(1)).Return(teamFields()[0], nil)
+	mockFieldRepo.On("RemoveValues", ctx, uint(1), "points").Return(nil)
+	mockFieldRepo.On("Delete", ctx, uint(1), uint(1)).Return(nil)
+
+	err := fieldService.Delete(ctx, 1, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tx.committed)
+	mockFieldRepo.AssertExpectations(t)
+
+	mockFieldRepo.On("GetByID", ctx, uint(9), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	assert.Equal(t, service.ErrCustomFieldNotFound, fieldService.Delete(ctx, 9, 1))
+}
+
+func TestTodoService_Create_ValidatesCustomFields(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, mockFieldRepo := setupCustomFieldTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockFieldRepo.On("ListForTodo", ctx, uint(1), (*uint)(nil)).Return(teamFields()[:4], nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	todo, err := todoService.Create(ctx, &model.CreateTodoRequest{
+		Title:        "Invoice",
+		CustomFields: model.CustomFieldValues{"points": 8.0, "customer": "Acme", "link": nil},
+	}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.CustomFieldValues{"points": 8.0, "customer": "Acme"}, todo.CustomFields)
+
+	_, err = todoService.Create(ctx, &model.CreateTodoRequest{
+		Title:        "Invoice",
+		CustomFields: model.CustomFieldValues{"points": 21.0, "link": "not a url", "release": "2024-02-30", "colour": "red"},
+	}, 1)
+	require.True(t, errors.Is(err, service.ErrInvalidCustomFieldValues))
+
+	var validationErrors validator.ValidationErrors
+	require.True(t, errors.As(err, &validationErrors))
+	details := validator.FormatValidationErrors(validationErrors)
+	assert.Equal(t, "custom_fields.points must be at most 13", details["custom_fields.points"])
+	assert.Equal(t, "custom_fields.customer is required", details["custom_fields.customer"])
+	assert.Equal(t, "custom_fields.link must be a valid URL", details["custom_fields.link"])
+	assert.Equal(t, "custom_fields.release must be a date in the format 2006-01-02", details["custom_fields.release"])
+	assert.Equal(t, "custom_fields.colour is not a custom field of this todo", details["custom_fields.colour"])
+	mockTodoRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestTodoService_Replace_CustomFieldValues(t *testing.T) {
+	todoService, mockTodoRepo, _, mockFieldRepo := setupCustomFieldTodoService()
+	ctx := context.Background()
+
+	existing := func() *model.Todo {
+		return &model.Todo{ID: 2, UserID: 1, Title: "Card", ListID: uintPtr(4), State: "backlog", CustomFields: model.CustomFieldValues{"customer": "Acme", "priority": "Low"}}
+	}
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(existing(), nil).Once()
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	// Without custom fields in the request the values are kept
+	todo, err := todoService.Replace(ctx, 2, &model.ReplaceTodoRequest{Title: "Card"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Low", todo.CustomFields["priority"])
+	mockFieldRepo.AssertNotCalled(t, "ListForTodo", mock.Anything, mock.Anything, mock.Anything)
+
+	// Select options may contain spaces; fields of the todo's list apply
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(existing(), nil).Once()
+	mockFieldRepo.On("ListForTodo", ctx, uint(1), uintPtr(4)).Return(teamFields(), nil)
+	todo, err = todoService.Replace(ctx, 2, &model.ReplaceTodoRequest{
+		Title:        "Card",
+		CustomFields: model.CustomFieldValues{"customer": "Acme", "priority": "Very high", "signed_off": true},
+	}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.CustomFieldValues{"customer": "Acme", "priority": "Very high", "signed_off": true}, todo.CustomFields)
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(existing(), nil).Once()
+	_, err = todoService.Replace(ctx, 2, &model.ReplaceTodoRequest{
+		Title:        "Card",
+		CustomFields: model.CustomFieldValues{"customer": "Acme", "priority": "Urgent", "signed_off": "yes"},
+	}, 1)
+	require.True(t, errors.Is(err, service.ErrInvalidCustomFieldValues))
+	assert.Contains(t, err.Error(), "custom_fields.priority must be one of: Low, Very high")
+	assert.Contains(t, err.Error(), "custom_fields.signed_off must be true or false")
+}
+
+func TestTodoService_Patch_CustomFieldValues(t *testing.T) {
+	todoService, mockTodoRepo, _, mockFieldRepo := setupCustomFieldTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1, Title: "Card", CustomFields: model.CustomFieldValues{"customer": "Acme", "points": 3.0}}, nil)
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockFieldRepo.On("ListForTodo", ctx, uint(1), (*uint)(nil)).Return(teamFields()[:4], nil)
+
+	todo, err := todoService.Patch(ctx, 2, model.PatchTypeMerge, []byte(`{"custom_fields": {"points": null, "release": "2024-06-01"}}`), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, model.CustomFieldValues{"customer": "Acme", "release": "2024-06-01"}, todo.CustomFields)
+}
+
+func TestTodoService_Patch_KeepsValuesOfFieldsAddedLater(t *testing.T) {
+	todoService, mockTodoRepo, _, mockFieldRepo := setupCustomFieldTodoService()
+	ctx := context.Background()
+
+	// The required customer field was added after the todo was created
+	existing := func() *model.Todo {
+		return &model.Todo{ID: 2, UserID: 1, Title: "Card", CustomFields: model.CustomFieldValues{"points": 3.0}}
+	}
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(existing(), nil).Once()
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	todo, err := todoService.Patch(ctx, 2, model.PatchTypeMerge, []byte(`{"title": "Renamed card"}`), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed card", todo.Title)
+	assert.Equal(t, model.CustomFieldValues{"points": 3.0}, todo.CustomFields)
+	mockFieldRepo.AssertNotCalled(t, "ListForTodo", mock.Anything, mock.Anything, mock.Anything)
+
+	// Only the values the patch changes are validated
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(existing(), nil).Once()
+	mockFieldRepo.On("ListForTodo", ctx, uint(1), (*uint)(nil)).Return(teamFields()[:4], nil)
+	todo, err = todoService.Patch(ctx, 2, model.PatchTypeMerge, []byte(`{"custom_fields": {"points": 5}}`), 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.CustomFieldValues{"points": 5.0}, todo.CustomFields)
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(existing(), nil).Once()
+	_, err = todoService.Patch(ctx, 2, model.PatchTypeMerge, []byte(`{"custom_fields": {"points": 21}}`), 1)
+	require.True(t, errors.Is(err, service.ErrInvalidCustomFieldValues))
+	assert.Contains(t, err.Error(), "custom_fields.points must be at most 13")
+	assert.NotContains(t, err.Error(), "custom_fields.customer")
+}
+
+func TestTodoService_MoveTodo_DropsListCustomFields(t *testing.T) {
+	todoService, mockTodoRepo, _, mockFieldRepo := setupCustomFieldTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1, ListID: uintPtr(4), State: "doing", CustomFields: model.CustomFieldValues{"customer": "Acme", "priority": "Low"}}, nil)
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockFieldRepo.On("ListForTodo", ctx, uint(1), (*uint)(nil)).Return(teamFields()[:4], nil)
+
+	todo, err := todoService.MoveTodo(ctx, 2, &model.MoveTodoRequest{}, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, model.CustomFieldValues{"customer": "Acme"}, todo.CustomFields)
+}
+
+func TestTodoService_List_CustomFieldFilter(t *testing.T) {
+	todoService, mockTodoRepo, _, mockFieldRepo := setupCustomFieldTodoService()
+	ctx := context.Background()
+
+	mockFieldRepo.On("ListByUserID", ctx, uint(1)).Return(teamFields(), nil)
+	mockTodoRepo.On("List", ctx, uint(1), mock.AnythingOfType("*model.TodoFilter")).Return([]*model.Todo{}, nil)
+
+	filter := &model.TodoFilter{
+		CustomFields: []model.CustomFieldCondition{
+			{Key: "points", Op: model.CustomFieldAtLeast, Value: "5"},
+			{Key: "signed_off", Op: model.CustomFieldEqual, Value: "true"},
+		},
+		SortField: &model.CustomFieldSort{Key: "release", Desc: true},
+	}
+	_, err := todoService.List(ctx, 1, filter)
+
+	require.NoError(t, err)
+	assert.Equal(t, model.CustomFieldNumber, filter.CustomFields[0].Type)
+	assert.Equal(t, 5.0, filter.CustomFields[0].Parsed)
+	assert.Equal(t, true, filter.CustomFields[1].Parsed)
+	assert.Equal(t, model.CustomFieldDate, filter.SortField.Type)
+
+	tests := []struct {
+		name      string
+		condition model.CustomFieldCondition
+		message   string
+	}{
+		{"unknown field", model.CustomFieldCondition{Key: "colour", Op: model.CustomFieldEqual, Value: "red"}, `unknown custom field "colour"`},
+		{"range on text", model.CustomFieldCondition{Key: "customer", Op: model.CustomFieldAtMost, Value: "M"}, "lte only applies to number and date fields"},
+		{"not a number", model.CustomFieldCondition{Key: "points", Op: model.CustomFieldEqual, Value: "NaN"}, `"NaN" is not a number`},
+		{"not a date", model.CustomFieldCondition{Key: "release", Op: model.CustomFieldAtLeast, Value: "June"}, `"June" is not a date`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := todoService.List(ctx, 1, &model.TodoFilter{CustomFields: []model.CustomFieldCondition{tt.condition}})
+			assert.True(t, errors.Is(err, service.ErrInvalidCustomFieldFilter))
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+	mockTodoRepo.AssertNumberOfCalls(t, "List", 1)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockDependencyRepository is a mock implementation of DependencyRepository
+type MockDependencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockDependencyRepository) Lock(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockDependencyRepository) Create(ctx context.Context, dependency *model.TodoDependency) error {
+	args := m.Called(ctx, dependency)
+	return args.Error(0)
+}
+
+func (m *MockDependencyRepository) Delete(ctx context.Context, userID uint, todoID uint, otherID uint) error {
+	args := m.Called(ctx, userID, todoID, otherID)
+	return args.Error(0)
+}
+
+func (m *MockDependencyRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.TodoDependency, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoDependency), args.Error(1)
+}
+
+func (m *MockDependencyRepository) ListLinkedTodos(ctx context.Context, userID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockDependencyRepository) ListBlockers(ctx context.Context, userID uint, todoID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, todoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockDependencyRepository) ListBlocked(ctx context.Context, userID uint, todoID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, todoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+func (m *MockDependencyRepository) BlockedTodoIDs(ctx context.Context, userID uint, todoIDs []uint) ([]uint, error) {
+	args := m.Called(ctx, userID, todoIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func setupDependencyTodoService() (service.TodoService, *MockTodoRepository, *MockDependencyRepository, *fakeTransactor) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockDependencyRepo := &MockDependencyRepository{}
+	tx := &fakeTransactor{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(tx), service.WithDependencies(mockDependencyRepo))
+
+	return todoService, mockTodoRepo, mockDependencyRepo, tx
+}
+
+func TestTodoService_AddDependency_BlockedBy(t *testing.T) {
+	todoService, mockTodoRepo, mockDependencyRepo, tx := setupDependencyTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(3), uint(1)).Return(&model.Todo{ID: 3, UserID: 1}, nil)
+	mockDependencyRepo.On("Lock", ctx, uint(1)).Return(nil)
+	mockDependencyRepo.On("ListByUserID", ctx, uint(1)).Return([]*model.TodoDependency{}, nil)
+	mockDependencyRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoDependency")).Return(nil)
+
+	dependency, err := todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{BlockedBy: 3}, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), dependency.TodoID)
+	assert.Equal(t, uint(3), dependency.BlockedByID)
+	assert.Equal(t, uint(1), dependency.UserID)
+	assert.Equal(t, 1, tx.committed)
+}
+
+func TestTodoService_AddDependency_Blocks(t *testing.T) {
+	todoService, mockTodoRepo, mockDependencyRepo, _ := setupDependencyTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(3), uint(1)).Return(&model.Todo{ID: 3, UserID: 1}, nil)
+	mockDependencyRepo.On("Lock", ctx, uint(1)).Return(nil)
+	mockDependencyRepo.On("ListByUserID", ctx, uint(1)).Return([]*model.TodoDependency{}, nil)
+	mockDependencyRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoDependency")).Return(nil)
+
+	dependency, err := todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{Blocks: 3}, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), dependency.TodoID)
+	assert.Equal(t, uint(2), dependency.BlockedByID)
+}
+
+func TestTodoService_AddDependency_RejectsCycle(t *testing.T) {
+	todoService, mockTodoRepo, mockDependencyRepo, tx := setupDependencyTodoService()
+	ctx := context.Background()
+
+	// 1 is blocked by 3 and 3 is blocked by 2, so 2 cannot be blocked by 1
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(&model.Todo{ID: 1, UserID: 1}, nil)
+	mockDependencyRepo.On("Lock", ctx, uint(1)).Return(nil)
+	mockDependencyRepo.On("ListByUserID", ctx, uint(1)).Return([]*model.TodoDependency{
+		{TodoID: 1, BlockedByID: 3},
+		{TodoID: 3, BlockedByID: 2},
+	}, nil)
+
+	dependency, err := todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{BlockedBy: 1}, 1)
+
+	assert.Nil(t, dependency)
+	assert.True(t, errors.Is(err, service.ErrDependencyCycle))
+	assert.Equal(t, "dependency cycle: 2 -> 1 -> 3 -> 2, where each todo would be blocked by the next", err.Error())
+	assert.Equal(t, 1, tx.rolledBack)
+	mockDependencyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_AddDependency_RejectsDuplicate(t *testing.T) {
+	todoService, mockTodoRepo, mockDependencyRepo, _ := setupDependencyTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(3), uint(1)).Return(&model.Todo{ID: 3, UserID: 1}, nil)
+	mockDependencyRepo.On("Lock", ctx, uint(1)).Return(nil)
+	mockDependencyRepo.On("ListByUserID", ctx, uint(1)).Return([]*model.TodoDependency{
+		{TodoID: 2, BlockedByID: 3},
+	}, nil)
+
+	_, err := todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{BlockedBy: 3}, 1)
+
+	assert.Equal(t, service.ErrDependencyExists, err)
+}
+
+func TestTodoService_AddDependency_InvalidRequests(t *testing.T) {
+	todoService, _, _, _ := setupDependencyTodoService()
+	ctx := context.Background()
+
+	_, err := todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{BlockedBy: 2}, 1)
+	assert.Equal(t, service.ErrSelfDependency, err)
+
+	_, err = todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{BlockedBy: 3, Blocks: 4}, 1)
+	assert.Equal(t, service.ErrInvalidDependency, err)
+
+	_, err = todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{}, 1)
+	assert.Equal(t, service.ErrInvalidDependency, err)
+}
+
+func TestTodoService_AddDependency_RelatedTodoNotFound(t *testing.T) {
+	todoService, mockTodoRepo, _, _ := setupDependencyTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(9), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := todoService.AddDependency(ctx, 2, &model.AddDependencyRequest{BlockedBy: 9}, 1)
+
+	assert.Equal(t, service.ErrDependencyTodoNotFound, err)
+}
+
+func TestTodoService_RemoveDependency_NotFound(t *testing.T) {
+	todoService, mockTodoRepo, mockDependencyRepo, _ := setupDependencyTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil)
+	mockDependencyRepo.On("Delete", ctx, uint(1), uint(2), uint(3)).Return(gorm.ErrRecordNotFound)
+
+	err := todoService.RemoveDependency(ctx, 2, 3, 1)
+
+	assert.Equal(t, service.ErrDependencyNotFound, err)
+}
+
+func TestTodoService_GetDependencyGraph_Order(t *testing.T) {
+	todoService, _, mockDependencyRepo, _ := setupDependencyTodoService()
+	ctx := context.Background()
+
+	// 4 waits for 1 and 2, 1 waits for 3, and 5 is trashed
+	mockDependencyRepo.On("ListLinkedTodos", ctx, uint(1)).Return([]*model.Todo{
+		{ID: 1, UserID: 1},
+		{ID: 2, UserID: 1, Completed: true},
+		{ID: 3, UserID: 1},
+		{ID: 4, UserID: 1},
+	}, nil)
+	mockDependencyRepo.On("ListByUserID", ctx, uint(1)).Return([]*model.TodoDependency{
+		{TodoID: 4, BlockedByID: 1},
+		{TodoID: 4, BlockedByID: 2},
+		{TodoID: 1, BlockedByID: 3},
+		{TodoID: 2, BlockedByID: 5},
+	}, nil)
+
+	graph, err := todoService.GetDependencyGraph(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{2, 3, 1, 4}, graph.Order)
+	assert.Len(t, graph.Edges, 3)
+	blocked := make(map[uint]bool)
+	for _, todo := range graph.Todos {
+		blocked[todo.ID] = todo.Blocked
+	}
+	assert.Equal(t, map[uint]bool{1: true, 2: false, 3: false, 4: true}, blocked)
+}
+
+func TestTodoService_GetByID_MarksBlocked(t *testing.T) {
+	todoService, mockTodoRepo, mockDependencyRepo, _ := setupDependencyTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil)
+	mockDependencyRepo.On("BlockedTodoIDs", ctx, uint(1), []uint{2}).Return([]uint{2}, nil)
+
+	todo, err := todoService.GetByID(ctx, 2, 1)
+
+	assert.NoError(t, err)
+	assert.True(t, todo.Blocked)
+}
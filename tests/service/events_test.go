@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/events"
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// recordingPublisher collects the events published to it
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(event events.Event) {
+	p.events = append(p.events, event)
+}
+
+func setupTodoServiceWithEvents() (service.TodoService, *MockTodoRepository, *MockUserRepository, *recordingPublisher) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	publisher := &recordingPublisher{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithEvents(publisher), service.WithTransactor(&fakeTransactor{}))
+
+	return todoService, mockTodoRepo, mockUserRepo, publisher
+}
+
+func TestTodoService_PublishesChangeEvents(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, publisher := setupTodoServiceWithEvents()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = 1
+	})
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Created", UserID: 1}, nil)
+	mockTodoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockTodoRepo.On("Delete", mock.Anything, uint(1), uint(1)).Return(nil)
+
+	_, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Created"}, uint(1))
+	require.NoError(t, err)
+	completed := true
+	_, err = todoService.Update(ctx, uint(1), &model.UpdateTodoRequest{Completed: &completed}, uint(1))
+	require.NoError(t, err)
+	require.NoError(t, todoService.Delete(ctx, uint(1), uint(1)))
+
+	require.Len(t, publisher.events, 3)
+	assert.Equal(t, events.TypeTodoCreated, publisher.events[0].Type)
+	assert.Equal(t, events.TypeTodoUpdated, publisher.events[1].Type)
+	assert.True(t, publisher.events[1].Todo.Completed)
+	assert.Equal(t, events.TypeTodoDeleted, publisher.events[2].Type)
+	for _, event := range publisher.events {
+		assert.Equal(t, uint(1), event.UserID)
+		assert.Equal(t, uint(1), event.TodoID)
+	}
+}
+
+func TestTodoService_DoesNotPublishRolledBackChanges(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, publisher := setupTodoServiceWithEvents()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = 10
+	})
+	mockTodoRepo.On("GetByID", mock.Anything, uint(2), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := todoService.Bulk(ctx, uint(1), &model.BulkRequest{
+		Atomic: true,
+		Operations: []model.BulkOperation{
+			{Op: model.BulkOpCreate, Title: stringPtr("Never saved")},
+			{Op: model.BulkOpDelete, ID: 2},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, publisher.events)
+}
+
+func TestTodoService_DiscardsEventsOfFailedBulkItems(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockHistoryRepo := &MockTodoHistoryRepository{}
+	publisher := &recordingPublisher{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(&fakeTransactor{}), service.WithHistory(mockHistoryRepo), service.WithEvents(publisher))
+	ctx := context.Background()
+
+	nextID := uint(10)
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = nextID
+		nextID++
+	})
+	mockHistoryRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.TodoHistory")).Return(nil).Once()
+	mockHistoryRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.TodoHistory")).Return(errors.New("disk full")).Once()
+
+	response, err := todoService.Bulk(ctx, uint(1), &model.BulkRequest{
+		Operations: []model.BulkOperation{
+			{Op: model.BulkOpCreate, Title: stringPtr("Saved")},
+			{Op: model.BulkOpCreate, Title: stringPtr("Rolled back")},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Failed)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, events.TypeTodoCreated, publisher.events[0].Type)
+	assert.Equal(t, uint(10), publisher.events[0].TodoID)
+}
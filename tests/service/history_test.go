@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockTodoHistoryRepository is a mock implementation of TodoHistoryRepository
+type MockTodoHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockTodoHistoryRepository) Create(ctx context.Context, entry *model.TodoHistory) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockTodoHistoryRepository) GetByTodoID(ctx context.Context, todoID uint, userID uint) ([]*model.TodoHistory, error) {
+	args := m.Called(ctx, todoID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoHistory), args.Error(1)
+}
+
+func (m *MockTodoHistoryRepository) GetByActionSince(ctx context.Context, userID uint, action string, since time.Time) ([]*model.TodoHistory, error) {
+	args := m.Called(ctx, userID, action, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoHistory), args.Error(1)
+}
+
+// fakeTransactor records whether the work it ran was committed or rolled back.
+// Nested calls behave like savepoints and are not counted.
+type fakeTransactor struct {
+	committed  int
+	rolledBack int
+	depth      int
+}
+
+func (f *fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.depth++
+	err := fn(ctx)
+	f.depth--
+	if f.depth > 0 {
+		return err
+	}
+	if err != nil {
+		f.rolledBack++
+		return err
+	}
+	f.committed++
+	return nil
+}
+
+func setupTodoServiceWithHistory() (service.TodoService, *MockTodoRepository, *MockUserRepository, *MockTodoHistoryRepository, *fakeTransactor) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockHistoryRepo := &MockTodoHistoryRepository{}
+	tx := &fakeTransactor{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(tx), service.WithHistory(mockHistoryRepo))
+
+	return todoService, mockTodoRepo, mockUserRepo, mockHistoryRepo, tx
+}
+
+func TestTodoService_Create_RecordsHistory(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, mockHistoryRepo, tx := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = 7
+	})
+
+	var recorded *model.TodoHistory
+	mockHistoryRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoHistory")).Return(nil).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*model.TodoHistory)
+	})
+
+	_, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "New", Description: "Desc"}, uint(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tx.committed)
+	assert.Equal(t, uint(7), recorded.TodoID)
+	assert.Equal(t, uint(1), recorded.ActorID)
+	assert.Equal(t, model.HistoryActionCreated, recorded.Action)
+	assert.Equal(t, model.FieldChanges{
+		{Field: "title", Old: nil, New: "New"},
+		{Field: "description", Old: nil, New: "Desc"},
+		{Field: "completed", Old: nil, New: false},
+	}, recorded.Changes)
+}
+
+func TestTodoService_Update_RecordsOnlyChangedFields(t *testing.T) {
+	todoService, mockTodoRepo, _, mockHistoryRepo, _ := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	existingTodo := &model.Todo{ID: 1, Title: "Old title", Description: "Same", UserID: 1}
+	newTitle := "New title"
+	completed := true
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingTodo, nil)
+	mockTodoRepo.On("Update", ctx, existingTodo).Return(nil)
+
+	var recorded *model.TodoHistory
+	mockHistoryRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoHistory")).Return(nil).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*model.TodoHistory)
+	})
+
+	_, err := todoService.Update(ctx, uint(1), &model.UpdateTodoRequest{Title: &newTitle, Completed: &completed}, uint(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.HistoryActionUpdated, recorded.Action)
+	assert.Equal(t, model.FieldChanges{
+		{Field: "title", Old: "Old title", New: "New title"},
+		{Field: "completed", Old: false, New: true},
+	}, recorded.Changes)
+}
+
+func TestTodoService_Update_HistoryFailureRollsBack(t *testing.T) {
+	todoService, mockTodoRepo, _, mockHistoryRepo, tx := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	existingTodo := &model.Todo{ID: 1, Title: "Old title", UserID: 1}
+	newTitle := "New title"
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingTodo, nil)
+	mockTodoRepo.On("Update", ctx, existingTodo).Return(nil)
+	mockHistoryRepo.On("Create", ctx, mock.Anything).Return(errors.New("database error"))
+
+	todo, err := todoService.Update(ctx, uint(1), &model.UpdateTodoRequest{Title: &newTitle}, uint(1))
+
+	assert.Nil(t, todo)
+	assert.Contains(t, err.Error(), "failed to record todo history")
+	assert.Equal(t, 1, tx.rolledBack)
+	assert.Equal(t, 0, tx.committed)
+}
+
+func TestTodoService_Delete_RecordsHistory(t *testing.T) {
+	todoService, mockTodoRepo, _, mockHistoryRepo, _ := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	existingTodo := &model.Todo{ID: 1, Title: "Task", UserID: 1}
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingTodo, nil)
+	mockTodoRepo.On("Delete", ctx, uint(1), uint(1)).Return(nil)
+
+	var recorded *model.TodoHistory
+	mockHistoryRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoHistory")).Return(nil).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*model.TodoHistory)
+	})
+
+	err := todoService.Delete(ctx, uint(1), uint(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.HistoryActionDeleted, recorded.Action)
+	assert.Len(t, recorded.Changes, 1)
+	assert.Equal(t, "deleted_at", recorded.Changes[0].Field)
+	assert.Nil(t, recorded.Changes[0].Old)
+	assert.NotNil(t, recorded.Changes[0].New)
+}
+
+func TestTodoService_GetHistory_Success(t *testing.T) {
+	todoService, _, _, mockHistoryRepo, _ := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	entries := []*model.TodoHistory{
+		{ID: 1, TodoID: 1, UserID: 1, ActorID: 1, Action: model.HistoryActionCreated},
+		{ID: 2, TodoID: 1, UserID: 1, ActorID: 1, Action: model.HistoryActionUpdated},
+	}
+	mockHistoryRepo.On("GetByTodoID", ctx, uint(1), uint(1)).Return(entries, nil)
+
+	history, err := todoService.GetHistory(ctx, uint(1), uint(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, entries, history)
+}
+
+func TestTodoService_GetHistory_TodoNotFound(t *testing.T) {
+	todoService, mockTodoRepo, _, mockHistoryRepo, _ := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	mockHistoryRepo.On("GetByTodoID", ctx, uint(1), uint(2)).Return([]*model.TodoHistory{}, nil)
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	history, err := todoService.GetHistory(ctx, uint(1), uint(2))
+
+	assert.Nil(t, history)
+	assert.Equal(t, service.ErrTodoNotFound, err)
+}
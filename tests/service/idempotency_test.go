@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockIdempotencyRepository is a mock implementation of IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Create(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	args := m.Called(ctx, record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) GetByKey(ctx context.Context, userID uint, key string) (*model.IdempotencyRecord, error) {
+	args := m.Called(ctx, userID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Update(ctx context.Context, record *model.IdempotencyRecord) error {
+	return m.Called(ctx, record).Error(0)
+}
+
+func (m *MockIdempotencyRepository) Renew(ctx context.Context, id uint, now time.Time) error {
+	return m.Called(ctx, id, now).Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, id uint) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyService_Begin_ReservesNewKey(t *testing.T) {
+	repo := &MockIdempotencyRepository{}
+	idempotencyService := service.NewIdempotencyService(repo, 2*time.Hour)
+	ctx := context.Background()
+
+	var created *model.IdempotencyRecord
+	repo.On("Create", ctx, mock.AnythingOfType("*model.IdempotencyRecord")).Return(true, nil).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*model.IdempotencyRecord)
+	})
+
+	record, found, err := idempotencyService.Begin(ctx, uint(1), "key-1", "fingerprint")
+
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Same(t, created, record)
+	assert.Equal(t, "fingerprint", record.Fingerprint)
+	assert.False(t, record.IsComplete())
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), record.ExpiresAt, time.Minute)
+}
+
+func TestIdempotencyService_Begin_ReturnsExistingRecord(t *testing.T) {
+	repo := &MockIdempotencyRepository{}
+	idempotencyService := service.NewIdempotencyService(repo, 0)
+	ctx := context.Background()
+
+	existing := &model.IdempotencyRecord{ID: 3, UserID: 1, Key: "key-1", StatusCode: 201, CreatedAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(time.Hour)}
+	repo.On("Create", ctx, mock.Anything).Return(false, nil)
+	repo.On("GetByKey", ctx, uint(1), "key-1").Return(existing, nil)
+
+	record, found, err := idempotencyService.Begin(ctx, uint(1), "key-1", "fingerprint")
+
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Same(t, existing, record)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyService_Begin_ReplacesStaleRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *model.IdempotencyRecord
+	}{
+		{"expired", &model.IdempotencyRecord{ID: 3, StatusCode: 201, CreatedAt: time.Now().Add(-25 * time.Hour), ExpiresAt: time.Now().Add(-time.Hour)}},
+		{"abandoned reservation", &model.IdempotencyRecord{ID: 3, CreatedAt: time.Now().Add(-10 * time.Minute), RenewedAt: time.Now().Add(-2 * time.Minute), ExpiresAt: time.Now().Add(time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockIdempotencyRepository{}
+			idempotencyService := service.NewIdempotencyService(repo, time.Hour)
+			ctx := context.Background()
+
+			repo.On("Create", ctx, mock.Anything).Return(false, nil).Once()
+			repo.On("GetByKey", ctx, uint(1), "key-1").Return(tt.existing, nil).Once()
+			repo.On("Delete", ctx, uint(3)).Return(nil).Once()
+			repo.On("Create", ctx, mock.Anything).Return(true, nil).Once()
+
+			record, found, err := idempotencyService.Begin(ctx, uint(1), "key-1", "fingerprint")
+
+			require.NoError(t, err)
+			assert.False(t, found)
+			assert.Equal(t, "fingerprint", record.Fingerprint)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIdempotencyService_Begin_KeepsRenewedReservations(t *testing.T) {
+	repo := &MockIdempotencyRepository{}
+	idempotencyService := service.NewIdempotencyService(repo, time.Hour)
+	ctx := context.Background()
+	existing := &model.IdempotencyRecord{
+		ID:          3,
+		Fingerprint: "fingerprint",
+		CreatedAt:   time.Now().Add(-10 * time.Minute),
+		RenewedAt:   time.Now().Add(-10 * time.Second),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	repo.On("Create", ctx, mock.Anything).Return(false, nil).Once()
+	repo.On("GetByKey", ctx, uint(1), "key-1").Return(existing, nil).Once()
+
+	record, found, err := idempotencyService.Begin(ctx, uint(1), "key-1", "fingerprint")
+
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, existing, record)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyService_Begin_KeyReleasedConcurrently(t *testing.T) {
+	repo := &MockIdempotencyRepository{}
+	idempotencyService := service.NewIdempotencyService(repo, time.Hour)
+	ctx := context.Background()
+
+	repo.On("Create", ctx, mock.Anything).Return(false, nil).Once()
+	repo.On("GetByKey", ctx, uint(1), "key-1").Return(nil, gorm.ErrRecordNotFound).Once()
+	repo.On("Create", ctx, mock.Anything).Return(true, nil).Once()
+
+	_, found, err := idempotencyService.Begin(ctx, uint(1), "key-1", "fingerprint")
+
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestIdempotencyService_RenewCompleteAndAbandon(t *testing.T) {
+	repo := &MockIdempotencyRepository{}
+	idempotencyService := service.NewIdempotencyService(repo, time.Hour)
+	ctx := context.Background()
+	record := &model.IdempotencyRecord{ID: 5, StatusCode: 201}
+
+	repo.On("Renew", ctx, uint(5), mock.AnythingOfType("time.Time")).Return(nil)
+	repo.On("Update", ctx, record).Return(nil)
+	repo.On("Delete", ctx, uint(5)).Return(nil)
+
+	assert.NoError(t, idempotencyService.Renew(ctx, record))
+	assert.NoError(t, idempotencyService.Complete(ctx, record))
+	assert.NoError(t, idempotencyService.Abandon(ctx, record))
+	repo.AssertExpectations(t)
+}
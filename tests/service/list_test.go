@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockListRepository is a mock implementation of ListRepository
+type MockListRepository struct {
+	mock.Mock
+}
+
+func (m *MockListRepository) Create(ctx context.Context, list *model.TodoList) error {
+	args := m.Called(ctx, list)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.TodoList, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoList), args.Error(1)
+}
+
+func (m *MockListRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.TodoList, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoList), args.Error(1)
+}
+
+func (m *MockListRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockListRepository) Update(ctx context.Context, list *model.TodoList) error {
+	args := m.Called(ctx, list)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) StatesInUse(ctx context.Context, listID uint) ([]string, error) {
+	args := m.Called(ctx, listID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockListRepository) DetachTodos(ctx context.Context, listID uint) error {
+	args := m.Called(ctx, listID)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) ListTodos(ctx context.Context, userID uint, listID uint) ([]*model.Todo, error) {
+	args := m.Called(ctx, userID, listID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Todo), args.Error(1)
+}
+
+// sprintBoard returns a list whose todos move from backlog through doing and
+// review to done, and may go back from review to doing
+func sprintBoard() *model.TodoList {
+	return &model.TodoList{
+		ID:     4,
+		UserID: 1,
+		Name:   "Sprint",
+		Workflow: model.Workflow{
+			States: []model.WorkflowState{
+				{Key: "backlog", Name: "Backlog"},
+				{Key: "doing", Name: "In Progress"},
+				{Key: "review", Name: "Review"},
+				{Key: "done", Name: "Done", Terminal: true},
+			},
+			Transitions: []model.WorkflowTransition{
+				{From: "backlog", To: "doing"},
+				{From: "doing", To: "review"},
+				{From: "review", To: "doing"},
+				{From: "review", To: "done"},
+			},
+		},
+	}
+}
+
+func setupListService() (service.ListService, *MockListRepository, *fakeTransactor) {
+	mockListRepo := &MockListRepository{}
+	tx := &fakeTransactor{}
+	return service.NewListService(mockListRepo, tx), mockListRepo, tx
+}
+
+func setupWorkflowTodoService() (service.TodoService, *MockTodoRepository, *MockUserRepository, *MockListRepository) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockListRepo := &MockListRepository{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(&fakeTransactor{}), service.WithLists(mockListRepo))
+
+	return todoService, mockTodoRepo, mockUserRepo, mockListRepo
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestListService_Create_DefaultWorkflow(t *testing.T) {
+	listService, mockListRepo, _ := setupListService()
+	ctx := context.Background()
+
+	mockListRepo.On("CountByUserID", ctx, uint(1)).Return(int64(0), nil)
+	mockListRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoList")).Return(nil)
+
+	list, err := listService.Create(ctx, 1, &model.CreateListRequest{Name: "Chores"})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultWorkflow(), list.Workflow)
+	assert.Equal(t, "todo", list.Workflow.InitialState())
+}
+
+func TestListService_Create_InvalidWorkflow(t *testing.T) {
+	listService, _, _ := setupListService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		workflow model.Workflow
+		message  string
+	}{
+		{
+			name: "no terminal state",
+			workflow: model.Workflow{States: []model.WorkflowState{
+				{Key: "backlog", Name: "Backlog"}, {Key: "doing", Name: "Doing"},
+			}},
+			message: "at least one state must be terminal",
+		},
+		{
+			name: "terminal initial state",
+			workflow: model.Workflow{States: []model.WorkflowState{
+				{Key: "done", Name: "Done", Terminal: true}, {Key: "doing", Name: "Doing"},
+			}},
+			message: "the first state must not be terminal",
+		},
+		{
+			name: "duplicate state",
+			workflow: model.Workflow{States: []model.WorkflowState{
+				{Key: "doing", Name: "Doing"}, {Key: "doing", Name: "Done", Terminal: true},
+			}},
+			message: `duplicate state "doing"`,
+		},
+		{
+			name: "invalid key",
+			workflow: model.Workflow{States: []model.WorkflowState{
+				{Key: "In Progress", Name: "Doing"}, {Key: "done", Name: "Done", Terminal: true},
+			}},
+			message: `state key "In Progress"`,
+		},
+		{
+			name: "unknown transition state",
+			workflow: model.Workflow{
+				States:      []model.WorkflowState{{Key: "todo", Name: "To Do"}, {Key: "done", Name: "Done", Terminal: true}},
+				Transitions: []model.WorkflowTransition{{From: "todo", To: "review"}},
+			},
+			message: `transition to unknown state "review"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := tt.workflow
+			_, err := listService.Create(ctx, 1, &model.CreateListRequest{Name: "Board", Workflow: &workflow})
+
+			assert.True(t, errors.Is(err, service.ErrInvalidWorkflow))
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestListService_Update_RejectsRemovingStatesInUse(t *testing.T) {
+	listService, mockListRepo, tx := setupListService()
+	ctx := context.Background()
+
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+	mockListRepo.On("StatesInUse", ctx, uint(4)).Return([]string{"review", "backlog"}, nil)
+
+	// review is dropped and backlog becomes terminal
+	workflow := model.Workflow{States: []model.WorkflowState{
+		{Key: "doing", Name: "Doing"},
+		{Key: "backlog", Name: "Backlog", Terminal: true},
+	}}
+	_, err := listService.Update(ctx, 4, 1, &model.UpdateListRequest{Workflow: &workflow})
+
+	assert.True(t, errors.Is(err, service.ErrWorkflowStateInUse))
+	assert.Equal(t, "workflow states are in use: backlog, review", err.Error())
+	assert.Equal(t, 1, tx.rolledBack)
+	mockListRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestListService_Delete_DetachesTodos(t *testing.T) {
+	listService, mockListRepo, tx := setupListService()
+	ctx := context.Background()
+
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+	mockListRepo.On("DetachTodos", ctx, uint(4)).Return(nil)
+	mockListRepo.On("Delete", ctx, uint(4), uint(1)).Return(nil)
+
+	err := listService.Delete(ctx, 4, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tx.committed)
+	mockListRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Create_InList(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	todo, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Card", ListID: uintPtr(4)}, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), *todo.ListID)
+	assert.Equal(t, "backlog", todo.State)
+	assert.False(t, todo.Completed)
+
+	_, err = todoService.Create(ctx, &model.CreateTodoRequest{Title: "Card", ListID: uintPtr(4), State: "shipped"}, 1)
+	assert.True(t, errors.Is(err, service.ErrUnknownState))
+
+	_, err = todoService.Create(ctx, &model.CreateTodoRequest{Title: "Card", State: "doing"}, 1)
+	assert.Equal(t, service.ErrStateWithoutList, err)
+}
+
+func TestTodoService_Replace_EnforcesTransitions(t *testing.T) {
+	todoService, mockTodoRepo, _, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1, Title: "Card", ListID: uintPtr(4), State: "backlog"}, nil)
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+
+	todo, err := todoService.Replace(ctx, 2, &model.ReplaceTodoRequest{Title: "Card", State: "done"}, 1)
+
+	assert.Nil(t, todo)
+	assert.True(t, errors.Is(err, service.ErrTransitionNotAllowed))
+	assert.Contains(t, err.Error(), `from "backlog" to "done"`)
+	mockTodoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_Replace_StateDecidesCompletion(t *testing.T) {
+	todoService, mockTodoRepo, _, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1, Title: "Card", ListID: uintPtr(4), State: "review"}, nil)
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+
+	// The state changes, so completed follows it even though the request disagrees
+	todo, err := todoService.Replace(ctx, 2, &model.ReplaceTodoRequest{Title: "Card", State: "done", Completed: false}, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "done", todo.State)
+	assert.True(t, todo.Completed)
+	assert.NotNil(t, todo.CompletedAt)
+}
+
+func TestTodoService_Update_CompletedMovesToAllowedState(t *testing.T) {
+	todoService, mockTodoRepo, _, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+	completed := true
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1, ListID: uintPtr(4), State: "review"}, nil).Once()
+	mockTodoRepo.On("GetByID", ctx, uint(3), uint(1)).Return(&model.Todo{ID: 3, UserID: 1, ListID: uintPtr(4), State: "backlog"}, nil).Once()
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+
+	todo, err := todoService.Update(ctx, 2, &model.UpdateTodoRequest{Completed: &completed}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "done", todo.State)
+	assert.True(t, todo.Completed)
+
+	// Nothing leads from backlog straight to a terminal state
+	_, err = todoService.Update(ctx, 3, &model.UpdateTodoRequest{Completed: &completed}, 1)
+	assert.True(t, errors.Is(err, service.ErrTransitionNotAllowed))
+	assert.Contains(t, err.Error(), `no transition from "backlog" to a terminal state`)
+}
+
+func TestTodoService_MoveTodo(t *testing.T) {
+	todoService, mockTodoRepo, _, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1, Completed: true}, nil).Once()
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+
+	// A completed todo entering a list starts in its first terminal state
+	todo, err := todoService.MoveTodo(ctx, 2, &model.MoveTodoRequest{ListID: uintPtr(4)}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "done", todo.State)
+	assert.True(t, todo.Completed)
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1, ListID: uintPtr(4), State: "doing"}, nil).Once()
+	todo, err = todoService.MoveTodo(ctx, 2, &model.MoveTodoRequest{}, 1)
+	require.NoError(t, err)
+	assert.Nil(t, todo.ListID)
+	assert.Empty(t, todo.State)
+	assert.False(t, todo.Completed)
+
+	mockTodoRepo.On("GetByID", ctx, uint(2), uint(1)).Return(&model.Todo{ID: 2, UserID: 1}, nil).Once()
+	mockListRepo.On("GetByID", ctx, uint(9), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	_, err = todoService.MoveTodo(ctx, 2, &model.MoveTodoRequest{ListID: uintPtr(9)}, 1)
+	assert.Equal(t, service.ErrListNotFound, err)
+}
+
+func TestTodoService_GetBoard(t *testing.T) {
+	todoService, _, _, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+
+	mockListRepo.On("GetByID", ctx, uint(4), uint(1)).Return(sprintBoard(), nil)
+	mockListRepo.On("ListTodos", ctx, uint(1), uint(4)).Return([]*model.Todo{
+		{ID: 1, State: "doing"},
+		{ID: 2, State: "backlog"},
+		{ID: 3, State: "doing"},
+		{ID: 4, State: "done", Completed: true},
+	}, nil)
+
+	board, err := todoService.GetBoard(ctx, 4, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, board.Count)
+	require.Len(t, board.Columns, 4)
+	counts := make([]int, len(board.Columns))
+	for i, column := range board.Columns {
+		counts[i] = column.Count
+	}
+	assert.Equal(t, []int{1, 2, 0, 1}, counts)
+	assert.Equal(t, "review", board.Columns[2].Key)
+	assert.Empty(t, board.Columns[2].Todos)
+	assert.Equal(t, uint(3), board.Columns[1].Todos[1].ID)
+}
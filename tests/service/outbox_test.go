@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+	"todo-api-backend/pkg/jwt"
+)
+
+// MockOutboxRepository is a mock implementation of OutboxRepository
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Append(ctx context.Context, message *model.OutboxMessage) error {
+	return m.Called(ctx, message).Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimNext(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.OutboxMessage, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id uint, publishedAt time.Time) error {
+	return m.Called(ctx, id, publishedAt).Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, message *model.OutboxMessage) error {
+	return m.Called(ctx, message).Error(0)
+}
+
+func (m *MockOutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// recordOutbox makes the mock outbox accept every message and returns the messages written
+func recordOutbox(mockOutbox *MockOutboxRepository) *[]*model.OutboxMessage {
+	var messages []*model.OutboxMessage
+	mockOutbox.On("Append", mock.Anything, mock.AnythingOfType("*model.OutboxMessage")).Return(nil).Run(func(args mock.Arguments) {
+		messages = append(messages, args.Get(1).(*model.OutboxMessage))
+	})
+	return &messages
+}
+
+func TestTodoService_WritesDomainEvents(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockOutbox := &MockOutboxRepository{}
+	tx := &fakeTransactor{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(tx), service.WithOutbox(mockOutbox))
+	ctx := context.Background()
+	messages := recordOutbox(mockOutbox)
+
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = 7
+	})
+	mockTodoRepo.On("GetByID", mock.Anything, uint(7), uint(1)).Return(&model.Todo{ID: 7, Title: "Created", UserID: 1}, nil)
+	mockTodoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	_, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Created"}, uint(1))
+	require.NoError(t, err)
+	title := "Renamed"
+	_, err = todoService.Update(ctx, uint(7), &model.UpdateTodoRequest{Title: &title}, uint(1))
+	require.NoError(t, err)
+
+	require.Len(t, *messages, 2)
+	assert.Equal(t, 2, tx.committed)
+
+	created := (*messages)[0]
+	assert.Equal(t, model.OutboxAggregateTodo, created.AggregateType)
+	assert.Equal(t, uint(7), created.AggregateID)
+	assert.Equal(t, "todo.created", created.EventType)
+	assert.False(t, created.NextAttemptAt.IsZero())
+
+	updated := (*messages)[1]
+	assert.Equal(t, "todo.updated", updated.EventType)
+
+	var payload model.TodoEventPayload
+	require.NoError(t, json.Unmarshal(updated.Payload, &payload))
+	assert.Equal(t, uint(7), payload.TodoID)
+	assert.Equal(t, uint(1), payload.ActorID)
+	require.NotNil(t, payload.Todo)
+	assert.Equal(t, "Renamed", payload.Todo.Title)
+	assert.Equal(t, model.FieldChanges{{Field: "title", Old: "Created", New: "Renamed"}}, payload.Changes)
+}
+
+func TestTodoService_DomainEventFailureRollsBack(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockOutbox := &MockOutboxRepository{}
+	tx := &fakeTransactor{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(tx), service.WithOutbox(mockOutbox))
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockOutbox.On("Append", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	_, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Created"}, uint(1))
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, tx.rolledBack)
+	assert.Zero(t, tx.committed)
+}
+
+func TestTodoService_PurgeWritesDomainEvent(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockOutbox := &MockOutboxRepository{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(&fakeTransactor{}), service.WithOutbox(mockOutbox))
+	ctx := context.Background()
+	messages := recordOutbox(mockOutbox)
+
+	mockTodoRepo.On("GetTrashedByID", ctx, uint(7), uint(1)).Return(&model.Todo{ID: 7, UserID: 1, Title: "Gone"}, nil)
+	mockTodoRepo.On("Purge", ctx, uint(7), uint(1)).Return(nil)
+
+	require.NoError(t, todoService.Purge(ctx, uint(7), uint(1)))
+
+	// Purges are not change events, but they are domain events
+	require.Len(t, *messages, 1)
+	assert.Equal(t, "todo.purged", (*messages)[0].EventType)
+
+	var payload model.TodoEventPayload
+	require.NoError(t, json.Unmarshal((*messages)[0].Payload, &payload))
+	assert.Nil(t, payload.Todo)
+}
+
+func TestAuthService_Register_WritesDomainEvent(t *testing.T) {
+	mockUserRepo := &MockUserRepository{}
+	mockOutbox := &MockOutboxRepository{}
+	tx := &fakeTransactor{}
+	authService := service.NewAuthService(mockUserRepo, jwt.NewTokenManager("test-secret", 24), service.WithUserOutbox(tx, mockOutbox))
+	ctx := context.Background()
+	messages := recordOutbox(mockOutbox)
+
+	mockUserRepo.On("GetByEmail", ctx, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockUserRepo.On("Create", ctx, mock.AnythingOfType("*model.User")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.User).ID = 5
+	})
+
+	_, err := authService.Register(ctx, &model.RegisterRequest{Email: "new@example.com", Password: "password123"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, tx.committed)
+	require.Len(t, *messages, 1)
+	assert.Equal(t, model.OutboxAggregateUser, (*messages)[0].AggregateType)
+	assert.Equal(t, uint(5), (*messages)[0].AggregateID)
+	assert.Equal(t, model.OutboxEventUserRegistered, (*messages)[0].EventType)
+
+	var payload model.UserEventPayload
+	require.NoError(t, json.Unmarshal((*messages)[0].Payload, &payload))
+	require.NotNil(t, payload.User)
+	assert.Equal(t, "new@example.com", payload.User.Email)
+	assert.NotContains(t, string((*messages)[0].Payload), "password")
+}
+
+func TestAuthService_Register_DomainEventFailureRollsBack(t *testing.T) {
+	mockUserRepo := &MockUserRepository{}
+	mockOutbox := &MockOutboxRepository{}
+	tx := &fakeTransactor{}
+	authService := service.NewAuthService(mockUserRepo, jwt.NewTokenManager("test-secret", 24), service.WithUserOutbox(tx, mockOutbox))
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByEmail", ctx, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockUserRepo.On("Create", ctx, mock.AnythingOfType("*model.User")).Return(nil)
+	mockOutbox.On("Append", ctx, mock.Anything).Return(errors.New("database error"))
+
+	_, err := authService.Register(ctx, &model.RegisterRequest{Email: "new@example.com", Password: "password123"})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, tx.rolledBack)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func existingPatchTodo() *model.Todo {
+	completedAt := time.Now().Add(-time.Hour)
+	return &model.Todo{
+		ID:          1,
+		Title:       "Write report",
+		Description: "Quarterly numbers",
+		Completed:   true,
+		CompletedAt: &completedAt,
+		UserID:      1,
+	}
+}
+
+func TestTodoService_Replace_ResetsOmittedFields(t *testing.T) {
+	todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingPatchTodo(), nil)
+	mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	todo, err := todoService.Replace(ctx, uint(1), &model.ReplaceTodoRequest{Title: "Write summary"}, uint(1))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Write summary", todo.Title)
+	assert.Empty(t, todo.Description)
+	assert.False(t, todo.Completed)
+	assert.Nil(t, todo.CompletedAt)
+}
+
+func TestTodoService_Patch(t *testing.T) {
+	tests := []struct {
+		name      string
+		patchType string
+		patch     string
+		check     func(t *testing.T, todo *model.Todo)
+	}{
+		{
+			name:      "merge patch clears description with null",
+			patchType: model.PatchTypeMerge,
+			patch:     `{"description": null}`,
+			check: func(t *testing.T, todo *model.Todo) {
+				assert.Equal(t, "Write report", todo.Title)
+				assert.Empty(t, todo.Description)
+				assert.True(t, todo.Completed)
+				assert.NotNil(t, todo.CompletedAt)
+			},
+		},
+		{
+			name:      "merge patch updates only given members",
+			patchType: model.PatchTypeMerge,
+			patch:     `{"completed": false}`,
+			check: func(t *testing.T, todo *model.Todo) {
+				assert.Equal(t, "Quarterly numbers", todo.Description)
+				assert.False(t, todo.Completed)
+				assert.Nil(t, todo.CompletedAt)
+			},
+		},
+		{
+			name:      "json patch with passing test",
+			patchType: model.PatchTypeJSON,
+			patch:     `[{"op": "test", "path": "/title", "value": "Write report"}, {"op": "replace", "path": "/title", "value": "Write summary"}, {"op": "remove", "path": "/description"}]`,
+			check: func(t *testing.T, todo *model.Todo) {
+				assert.Equal(t, "Write summary", todo.Title)
+				assert.Empty(t, todo.Description)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, mockTodoRepo, _, tx := setupBulkTodoService()
+			ctx := context.Background()
+
+			mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingPatchTodo(), nil)
+			mockTodoRepo.On("Update", ctx, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+			todo, err := todoService.Patch(ctx, uint(1), tt.patchType, []byte(tt.patch), uint(1))
+
+			require.NoError(t, err)
+			tt.check(t, todo)
+			assert.Equal(t, 1, tx.committed)
+		})
+	}
+}
+
+func TestTodoService_Patch_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		patchType   string
+		patch       string
+		expectedErr error
+	}{
+		{"unsupported type", "application/json", `{}`, service.ErrUnsupportedPatchType},
+		{"malformed merge patch", model.PatchTypeMerge, `{"title":`, service.ErrInvalidPatch},
+		{"malformed json patch", model.PatchTypeJSON, `[{"op": "add"}]`, service.ErrInvalidPatch},
+		{"failed test", model.PatchTypeJSON, `[{"op": "test", "path": "/completed", "value": false}]`, service.ErrPatchConflict},
+		{"missing path", model.PatchTypeJSON, `[{"op": "replace", "path": "/tags/0", "value": "x"}]`, service.ErrPatchConflict},
+		{"title removed", model.PatchTypeMerge, `{"title": null}`, service.ErrInvalidPatchedTodo},
+		{"read-only member", model.PatchTypeJSON, `[{"op": "add", "path": "/user_id", "value": 2}]`, service.ErrInvalidPatchedTodo},
+		{"wrong type", model.PatchTypeMerge, `{"completed": "yes"}`, service.ErrInvalidPatchedTodo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+			ctx := context.Background()
+
+			mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(existingPatchTodo(), nil)
+
+			todo, err := todoService.Patch(ctx, uint(1), tt.patchType, []byte(tt.patch), uint(1))
+
+			assert.Nil(t, todo)
+			assert.True(t, errors.Is(err, tt.expectedErr), "got %v", err)
+			mockTodoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTodoService_Patch_NotFound(t *testing.T) {
+	todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", ctx, uint(1), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := todoService.Patch(ctx, uint(1), model.PatchTypeMerge, []byte(`{}`), uint(1))
+
+	assert.Equal(t, service.ErrTodoNotFound, err)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func TestTodoService_QuickAdd_CreatesTodo(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, tx := setupBulkTodoService()
+	ctx := context.Background()
+
+	var created *model.Todo
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*model.Todo)
+		created.ID = 7
+	})
+
+	response, err := todoService.QuickAdd(ctx, uint(1), &model.QuickAddRequest{
+		Text:     "Call Bob tomorrow 3pm !high #sales",
+		Timezone: "Europe/Berlin",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	assert.Equal(t, 1, tx.committed)
+	assert.Same(t, created, response.Todo)
+	assert.Equal(t, "Call Bob #sales pri:A", created.Title)
+	assert.Equal(t, uint(1), created.UserID)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	tomorrow := time.Now().In(berlin).AddDate(0, 0, 1)
+	expectedDue := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 15, 0, 0, 0, berlin).UTC()
+	require.NotNil(t, created.DueAt)
+	assert.Equal(t, expectedDue, *created.DueAt)
+
+	interpretation := response.Interpretation
+	assert.Equal(t, "Call Bob", interpretation.Title)
+	assert.Equal(t, created.DueAt, interpretation.DueAt)
+	assert.False(t, interpretation.AllDay)
+	assert.Equal(t, "A", interpretation.Priority)
+	assert.Equal(t, []string{"sales"}, interpretation.Tags)
+	assert.Equal(t, "en", interpretation.Locale)
+	assert.Equal(t, "Europe/Berlin", interpretation.Timezone)
+	assert.Equal(t, []model.QuickAddMatch{
+		{Kind: "date", Text: "tomorrow"},
+		{Kind: "time", Text: "3pm"},
+		{Kind: "priority", Text: "!high"},
+		{Kind: "tag", Text: "#sales"},
+	}, interpretation.Matches)
+}
+
+func TestTodoService_QuickAdd_Locale(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	var created *model.Todo
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*model.Todo)
+	})
+
+	response, err := todoService.QuickAdd(ctx, uint(1), &model.QuickAddRequest{Text: "Steuer übermorgen", Locale: "de-AT"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Steuer", created.Title)
+	today := time.Now().UTC()
+	assert.Equal(t, time.Date(today.Year(), today.Month(), today.Day()+2, 0, 0, 0, 0, time.UTC), *created.DueAt)
+	assert.True(t, response.Interpretation.AllDay)
+	assert.Equal(t, "de", response.Interpretation.Locale)
+	assert.Equal(t, "UTC", response.Interpretation.Timezone)
+	assert.Empty(t, response.Interpretation.Priority)
+	assert.Equal(t, []string{}, response.Interpretation.Tags)
+}
+
+func TestTodoService_QuickAdd_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *model.QuickAddRequest
+		err  error
+	}{
+		{"unsupported locale", &model.QuickAddRequest{Text: "Buy milk", Locale: "pt-BR"}, service.ErrUnsupportedLocale},
+		{"unknown time zone", &model.QuickAddRequest{Text: "Buy milk", Timezone: "Mars/Olympus"}, service.ErrInvalidTimezone},
+		{"server time zone", &model.QuickAddRequest{Text: "Buy milk", Timezone: "Local"}, service.ErrInvalidTimezone},
+		{"no title", &model.QuickAddRequest{Text: "tomorrow 3pm !high #sales"}, service.ErrInvalidQuickAdd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+
+			response, err := todoService.QuickAdd(context.Background(), uint(1), tt.req)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Nil(t, response)
+			mockTodoRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTodoService_QuickAdd_CreateError(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(errors.New("database down"))
+
+	response, err := todoService.QuickAdd(ctx, uint(1), &model.QuickAddRequest{Text: "Buy milk"})
+
+	assert.ErrorContains(t, err, "failed to create todo")
+	assert.Nil(t, response)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockTodoSearcher is a mock implementation of TodoSearcher
+type MockTodoSearcher struct {
+	mock.Mock
+}
+
+func (m *MockTodoSearcher) Search(ctx context.Context, userID uint, query *model.TodoSearchQuery) ([]*model.TodoSearchResult, error) {
+	args := m.Called(ctx, userID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoSearchResult), args.Error(1)
+}
+
+func setupSearchTodoService() (service.TodoService, *MockTodoSearcher) {
+	mockSearcher := &MockTodoSearcher{}
+	todoService := service.NewTodoService(&MockTodoRepository{}, &MockUserRepository{}, service.WithSearch(mockSearcher))
+
+	return todoService, mockSearcher
+}
+
+func TestTodoService_Search_ParsesQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []model.SearchTerm
+	}{
+		{
+			name:     "single word",
+			query:    "Report",
+			expected: []model.SearchTerm{{Words: []string{"report"}}},
+		},
+		{
+			name:  "multiple words",
+			query: "  call   bob ",
+			expected: []model.SearchTerm{
+				{Words: []string{"call"}},
+				{Words: []string{"bob"}},
+			},
+		},
+		{
+			name:     "quoted phrase",
+			query:    `"quarterly report"`,
+			expected: []model.SearchTerm{{Words: []string{"quarterly", "report"}}},
+		},
+		{
+			name:     "prefix word",
+			query:    "rep*",
+			expected: []model.SearchTerm{{Words: []string{"rep"}, Prefix: true}},
+		},
+		{
+			name:  "phrase with prefix",
+			query: `"quarterly rep"* bob`,
+			expected: []model.SearchTerm{
+				{Words: []string{"quarterly", "rep"}, Prefix: true},
+				{Words: []string{"bob"}},
+			},
+		},
+		{
+			name:     "prefix inside quotes",
+			query:    `"quarterly rep*"`,
+			expected: []model.SearchTerm{{Words: []string{"quarterly", "rep"}, Prefix: true}},
+		},
+		{
+			name:     "unterminated quote",
+			query:    `"send the report`,
+			expected: []model.SearchTerm{{Words: []string{"send", "the", "report"}}},
+		},
+		{
+			name:  "operators are stripped",
+			query: "bob & !alice | carol:*",
+			expected: []model.SearchTerm{
+				{Words: []string{"bob"}},
+				{Words: []string{"alice"}},
+				{Words: []string{"carol"}, Prefix: true},
+			},
+		},
+		{
+			name:     "hyphenated word becomes a phrase",
+			query:    "e-mail",
+			expected: []model.SearchTerm{{Words: []string{"e", "mail"}}},
+		},
+		{
+			name:     "unicode words",
+			query:    "Café Ünïcode",
+			expected: []model.SearchTerm{{Words: []string{"café"}}, {Words: []string{"ünïcode"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, mockSearcher := setupSearchTodoService()
+			ctx := context.Background()
+
+			mockSearcher.On("Search", ctx, uint(1), &model.TodoSearchQuery{
+				Terms:    tt.expected,
+				Archived: model.ArchivedExclude,
+				Limit:    20,
+			}).Return([]*model.TodoSearchResult{}, nil)
+
+			results, err := todoService.Search(ctx, uint(1), &model.SearchTodosRequest{Query: tt.query})
+
+			assert.NoError(t, err)
+			assert.Empty(t, results)
+			mockSearcher.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTodoService_Search_PassesFilterAndLimit(t *testing.T) {
+	todoService, mockSearcher := setupSearchTodoService()
+	ctx := context.Background()
+
+	expected := []*model.TodoSearchResult{{Todo: &model.Todo{ID: 1, Title: "Report"}, Rank: 0.6}}
+	mockSearcher.On("Search", ctx, uint(1), &model.TodoSearchQuery{
+		Terms:    []model.SearchTerm{{Words: []string{"report"}}},
+		Archived: model.ArchivedInclude,
+		Limit:    5,
+	}).Return(expected, nil)
+
+	results, err := todoService.Search(ctx, uint(1), &model.SearchTodosRequest{
+		Query:    "report",
+		Archived: model.ArchivedInclude,
+		Limit:    5,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, results)
+}
+
+func TestTodoService_Search_EmptyQuery(t *testing.T) {
+	todoService, mockSearcher := setupSearchTodoService()
+
+	for _, query := range []string{"", "   ", `""`, "*", "&|!"} {
+		results, err := todoService.Search(context.Background(), uint(1), &model.SearchTodosRequest{Query: query})
+
+		assert.Nil(t, results)
+		assert.Equal(t, service.ErrEmptySearchQuery, err, query)
+	}
+	mockSearcher.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTodoService_Search_RepositoryError(t *testing.T) {
+	todoService, mockSearcher := setupSearchTodoService()
+	ctx := context.Background()
+
+	mockSearcher.On("Search", ctx, uint(1), mock.Anything).Return(nil, errors.New("database error"))
+
+	results, err := todoService.Search(ctx, uint(1), &model.SearchTodosRequest{Query: "report"})
+
+	assert.Nil(t, results)
+	assert.Contains(t, err.Error(), "failed to search todos")
+}
+
+func TestTodoService_Search_Unavailable(t *testing.T) {
+	todoService := service.NewTodoService(&MockTodoRepository{}, &MockUserRepository{})
+
+	_, err := todoService.Search(context.Background(), uint(1), &model.SearchTodosRequest{Query: "report"})
+
+	assert.Equal(t, service.ErrSearchUnavailable, err)
+}
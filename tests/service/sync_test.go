@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/repository"
+	"todo-api-backend/internal/service"
+)
+
+// syncToken builds the sync token the service issues at t
+func syncToken(t time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("v1:" + strconv.FormatInt(t.UnixNano(), 10)))
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestTodoService_PullChanges_FullSync(t *testing.T) {
+	todoService, mockTodoRepo, _, mockHistoryRepo, _ := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	deletedAt := time.Now().Add(-time.Hour)
+	mockTodoRepo.On("ListChangedSince", ctx, uint(1), time.Time{}).Return([]*model.Todo{
+		{ID: 1, Title: "Live", UserID: 1},
+		{ID: 2, Title: "Trashed", UserID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	}, nil)
+
+	response, err := todoService.PullChanges(ctx, uint(1), "")
+
+	require.NoError(t, err)
+	assert.True(t, response.Reset)
+	assert.NotEmpty(t, response.Token)
+	require.Len(t, response.Todos, 1)
+	assert.Equal(t, uint(1), response.Todos[0].ID)
+	assert.Empty(t, response.Deleted)
+	mockHistoryRepo.AssertNotCalled(t, "GetByActionSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTodoService_PullChanges_Incremental(t *testing.T) {
+	todoService, mockTodoRepo, _, mockHistoryRepo, _ := setupTodoServiceWithHistory()
+	ctx := context.Background()
+
+	since := time.Now().Add(-time.Minute)
+	deletedAt := time.Now().Add(-30 * time.Second)
+	purgedAt := time.Now().Add(-10 * time.Second)
+
+	from := mock.MatchedBy(func(from time.Time) bool {
+		return from.Equal(since.Add(-5 * time.Second))
+	})
+	mockTodoRepo.On("ListChangedSince", ctx, uint(1), from).Return([]*model.Todo{
+		{ID: 1, Title: "Updated", UserID: 1, Version: 2},
+		{ID: 2, Title: "Trashed", UserID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	}, nil)
+	mockHistoryRepo.On("GetByActionSince", ctx, uint(1), model.HistoryActionPurged, from).Return([]*model.TodoHistory{
+		{TodoID: 2, UserID: 1, Action: model.HistoryActionPurged, CreatedAt: purgedAt},
+		{TodoID: 3, UserID: 1, Action: model.HistoryActionPurged, CreatedAt: purgedAt},
+	}, nil)
+
+	response, err := todoService.PullChanges(ctx, uint(1), syncToken(since))
+
+	require.NoError(t, err)
+	assert.False(t, response.Reset)
+	assert.NotEqual(t, syncToken(since), response.Token)
+	require.Len(t, response.Todos, 1)
+	assert.Equal(t, uint(1), response.Todos[0].ID)
+
+	require.Len(t, response.Deleted, 2)
+	assert.Equal(t, uint(2), response.Deleted[0].ID)
+	assert.True(t, response.Deleted[0].DeletedAt.Equal(deletedAt))
+	assert.Equal(t, uint(3), response.Deleted[1].ID)
+	assert.True(t, response.Deleted[1].DeletedAt.Equal(purgedAt))
+}
+
+func TestTodoService_PullChanges_ExpiredTokenResets(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	todoService := service.NewTodoService(mockTodoRepo, &MockUserRepository{}, service.WithSyncHorizon(24*time.Hour))
+	ctx := context.Background()
+
+	mockTodoRepo.On("ListChangedSince", ctx, uint(1), time.Time{}).Return([]*model.Todo{}, nil)
+
+	response, err := todoService.PullChanges(ctx, uint(1), syncToken(time.Now().Add(-48*time.Hour)))
+
+	require.NoError(t, err)
+	assert.True(t, response.Reset)
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_PullChanges_InvalidToken(t *testing.T) {
+	todoService, _, _ := setupTodoService()
+
+	tokens := []string{
+		"not a token",
+		base64.RawURLEncoding.EncodeToString([]byte("v2:123")),
+		base64.RawURLEncoding.EncodeToString([]byte("v1:abc")),
+	}
+
+	for _, token := range tokens {
+		_, err := todoService.PullChanges(context.Background(), uint(1), token)
+		assert.ErrorIs(t, err, service.ErrInvalidSyncToken, token)
+	}
+}
+
+func TestTodoService_PushChanges(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	clientTime := time.Now().Add(-time.Hour)
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		todo := args.Get(1).(*model.Todo)
+		todo.ID = 10
+		todo.Version = 1
+	})
+	mockTodoRepo.On("GetByID", mock.Anything, uint(10), uint(1)).Return(&model.Todo{ID: 10, Title: "Offline", UserID: 1, Version: 1}, nil)
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Same", UserID: 1, Version: 3}, nil)
+	mockTodoRepo.On("GetByID", mock.Anything, uint(2), uint(1)).Return(&model.Todo{ID: 2, Title: "Changed", UserID: 1, Version: 5}, nil)
+	mockTodoRepo.On("GetByID", mock.Anything, uint(3), uint(1)).Return(&model.Todo{ID: 3, Title: "Newer", UserID: 1, Version: 1, UpdatedAt: time.Now()}, nil)
+	mockTodoRepo.On("GetByID", mock.Anything, uint(4), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockTodoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	response, err := todoService.PushChanges(ctx, uint(1), &model.SyncPushRequest{
+		Mutations: []model.SyncMutation{
+			{Op: model.SyncOpCreate, ClientID: "local-1", Title: stringPtr("Offline"), Completed: boolPtr(true), ClientUpdatedAt: clientTime},
+			{Op: model.SyncOpUpdate, ID: 1, BaseVersion: 3, Title: stringPtr("Edited"), ClientUpdatedAt: clientTime},
+			{Op: model.SyncOpUpdate, ID: 2, BaseVersion: 4, Title: stringPtr("Stale"), ClientUpdatedAt: clientTime},
+			{Op: model.SyncOpDelete, ID: 3, ClientUpdatedAt: clientTime},
+			{Op: model.SyncOpUpdate, ID: 4, Completed: boolPtr(true), ClientUpdatedAt: clientTime},
+			{Op: model.SyncOpDelete, ID: 4, ClientUpdatedAt: clientTime},
+			{Op: model.SyncOpUpdate, ClientUpdatedAt: clientTime},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, response.Applied)
+	assert.Equal(t, 3, response.Conflicts)
+	assert.Equal(t, 1, response.Failed)
+	require.Len(t, response.Results, 7)
+
+	created := response.Results[0]
+	assert.Equal(t, model.SyncStatusApplied, created.Status)
+	assert.Equal(t, "local-1", created.ClientID)
+	assert.Equal(t, uint(10), created.ID)
+	assert.True(t, created.Todo.Completed)
+
+	assert.Equal(t, model.SyncStatusApplied, response.Results[1].Status)
+	assert.Equal(t, "Edited", response.Results[1].Todo.Title)
+
+	assert.Equal(t, model.SyncStatusConflict, response.Results[2].Status)
+	assert.Equal(t, model.SyncConflictVersion, response.Results[2].Reason)
+	assert.Equal(t, uint(5), response.Results[2].Todo.Version)
+
+	assert.Equal(t, model.SyncStatusConflict, response.Results[3].Status)
+	assert.Equal(t, model.SyncConflictServerNewer, response.Results[3].Reason)
+	assert.Equal(t, "Newer", response.Results[3].Todo.Title)
+
+	assert.Equal(t, model.SyncStatusConflict, response.Results[4].Status)
+	assert.Equal(t, model.SyncConflictDeleted, response.Results[4].Reason)
+	assert.Nil(t, response.Results[4].Todo)
+
+	assert.Equal(t, model.SyncStatusApplied, response.Results[5].Status)
+
+	assert.Equal(t, model.SyncStatusFailed, response.Results[6].Status)
+	assert.Equal(t, "validation_failed", response.Results[6].Error)
+
+	mockTodoRepo.AssertNotCalled(t, "Delete", mock.Anything, uint(3), uint(1))
+}
+
+func TestTodoService_PushChanges_LostRaceIsConflict(t *testing.T) {
+	todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Same", UserID: 1, Version: 3}, nil)
+	mockTodoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(repository.ErrVersionConflict)
+
+	response, err := todoService.PushChanges(ctx, uint(1), &model.SyncPushRequest{
+		Mutations: []model.SyncMutation{
+			{Op: model.SyncOpUpdate, ID: 1, BaseVersion: 3, Title: stringPtr("Edited"), ClientUpdatedAt: time.Now()},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Conflicts)
+	assert.Equal(t, model.SyncConflictVersion, response.Results[0].Reason)
+}
+
+func TestTodoService_PushChanges_ForbiddenTransition(t *testing.T) {
+	todoService, mockTodoRepo, _, mockListRepo := setupWorkflowTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Card", UserID: 1, ListID: uintPtr(4), State: "backlog", Version: 2}, nil)
+	mockListRepo.On("GetByID", mock.Anything, uint(4), uint(1)).Return(sprintBoard(), nil)
+
+	response, err := todoService.PushChanges(ctx, uint(1), &model.SyncPushRequest{
+		Mutations: []model.SyncMutation{
+			{Op: model.SyncOpUpdate, ID: 1, BaseVersion: 2, Completed: boolPtr(true), ClientUpdatedAt: time.Now()},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, model.SyncStatusFailed, response.Results[0].Status)
+	assert.Equal(t, "transition_not_allowed", response.Results[0].Error)
+	mockTodoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
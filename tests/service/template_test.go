@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockTemplateRepository is a mock implementation of TemplateRepository
+type MockTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockTemplateRepository) Create(ctx context.Context, template *model.TodoTemplate) error {
+	return m.Called(ctx, template).Error(0)
+}
+
+func (m *MockTemplateRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.TodoTemplate, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TodoTemplate), args.Error(1)
+}
+
+func (m *MockTemplateRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.TodoTemplate, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TodoTemplate), args.Error(1)
+}
+
+func (m *MockTemplateRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTemplateRepository) Update(ctx context.Context, template *model.TodoTemplate) error {
+	return m.Called(ctx, template).Error(0)
+}
+
+func (m *MockTemplateRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func setupTemplateService() (service.TemplateService, *MockTemplateRepository, *MockTodoRepository, *MockUserRepository, *MockDependencyRepository, *fakeTransactor) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	mockDependencyRepo := &MockDependencyRepository{}
+	mockTemplateRepo := &MockTemplateRepository{}
+	tx := &fakeTransactor{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(tx), service.WithDependencies(mockDependencyRepo))
+
+	// Newly created todos are never blocked
+	mockDependencyRepo.On("BlockedTodoIDs", mock.Anything, uint(1), mock.Anything).Return([]uint{}, nil).Maybe()
+
+	return service.NewTemplateService(mockTemplateRepo, todoService, tx), mockTemplateRepo, mockTodoRepo, mockUserRepo, mockDependencyRepo, tx
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func onboardingTemplate() *model.TodoTemplate {
+	return &model.TodoTemplate{
+		ID:     3,
+		UserID: 1,
+		Name:   "Onboarding",
+		Items: []model.TemplateItem{
+			{
+				Title:         "Welcome {{name}}",
+				DueOffsetDays: intPtr(0),
+				Children: []model.TemplateItem{
+					{Title: "Laptop for {{ name }}", Description: "Ask {{team}} IT", DueOffsetDays: intPtr(3)},
+					{Title: "Badge"},
+				},
+			},
+			{Title: "First review", DueOffsetDays: intPtr(30)},
+		},
+	}
+}
+
+func TestTemplateService_Create(t *testing.T) {
+	templateService, mockTemplateRepo, _, _, _, _ := setupTemplateService()
+	ctx := context.Background()
+
+	mockTemplateRepo.On("CountByUserID", ctx, uint(1)).Return(int64(0), nil)
+	mockTemplateRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoTemplate")).Return(nil)
+
+	template, err := templateService.Create(ctx, uint(1), &model.CreateTemplateRequest{
+		Name:  "Onboarding",
+		Items: onboardingTemplate().Items,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), template.UserID)
+	assert.Equal(t, []string{"name", "team"}, template.Variables)
+}
+
+func TestTemplateService_Create_Limits(t *testing.T) {
+	deep := []model.TemplateItem{{Title: "1"}}
+	for level := 2; level <= 6; level++ {
+		deep = []model.TemplateItem{{Title: "level", Children: deep}}
+	}
+	many := make([]model.TemplateItem, 0, 101)
+	for i := 0; i < 101; i++ {
+		many = append(many, model.TemplateItem{Title: "item", Children: []model.TemplateItem{{Title: "child"}}})
+	}
+
+	tests := []struct {
+		name  string
+		items []model.TemplateItem
+	}{
+		{"too deep", deep},
+		{"too many items", many},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateService, mockTemplateRepo, _, _, _, _ := setupTemplateService()
+
+			template, err := templateService.Create(context.Background(), uint(1), &model.CreateTemplateRequest{Name: "Big", Items: tt.items})
+
+			assert.ErrorIs(t, err, service.ErrTemplateTooLarge)
+			assert.Nil(t, template)
+			mockTemplateRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTemplateService_Create_LimitReached(t *testing.T) {
+	templateService, mockTemplateRepo, _, _, _, _ := setupTemplateService()
+	ctx := context.Background()
+
+	mockTemplateRepo.On("CountByUserID", ctx, uint(1)).Return(int64(100), nil)
+
+	_, err := templateService.Create(ctx, uint(1), &model.CreateTemplateRequest{Name: "One more", Items: []model.TemplateItem{{Title: "Todo"}}})
+
+	assert.ErrorIs(t, err, service.ErrTemplateLimit)
+}
+
+func TestTemplateService_Get_NotFound(t *testing.T) {
+	templateService, mockTemplateRepo, _, _, _, _ := setupTemplateService()
+	ctx := context.Background()
+
+	mockTemplateRepo.On("GetByID", ctx, uint(3), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := templateService.Get(ctx, uint(3), uint(1))
+
+	assert.ErrorIs(t, err, service.ErrTemplateNotFound)
+}
+
+func TestTemplateService_Instantiate_CreatesTree(t *testing.T) {
+	templateService, mockTemplateRepo, mockTodoRepo, mockUserRepo, mockDependencyRepo, tx := setupTemplateService()
+	ctx := context.Background()
+
+	var (
+		created []*model.Todo
+		edges   []*model.TodoDependency
+	)
+	mockTemplateRepo.On("GetByID", ctx, uint(3), uint(1)).Return(onboardingTemplate(), nil)
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		todo := args.Get(1).(*model.Todo)
+		todo.ID = uint(10 + len(created))
+		created = append(created, todo)
+	})
+	mockTodoRepo.On("GetByID", ctx, mock.Anything, uint(1)).Return(&model.Todo{UserID: 1}, nil)
+	mockDependencyRepo.On("Lock", ctx, uint(1)).Return(nil)
+	mockDependencyRepo.On("ListByUserID", ctx, uint(1)).Return([]*model.TodoDependency{}, nil)
+	mockDependencyRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoDependency")).Return(nil).Run(func(args mock.Arguments) {
+		edges = append(edges, args.Get(1).(*model.TodoDependency))
+	})
+
+	response, err := templateService.Instantiate(ctx, uint(3), uint(1), &model.InstantiateTemplateRequest{
+		Variables: map[string]string{"name": "Alice", "team": "Platform"},
+		StartDate: "2024-01-08",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, tx.committed)
+	assert.Equal(t, uint(3), response.TemplateID)
+	assert.Equal(t, 4, response.Count)
+	require.Len(t, created, 4)
+
+	titles := make([]string, 0, len(created))
+	for _, todo := range created {
+		titles = append(titles, todo.Title)
+	}
+	assert.Equal(t, []string{"Welcome Alice", "Laptop for Alice", "Badge", "First review"}, titles)
+	assert.Equal(t, "Ask Platform IT", created[1].Description)
+
+	start := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, start, *created[0].DueAt)
+	assert.Equal(t, start.AddDate(0, 0, 3), *created[1].DueAt)
+	assert.Nil(t, created[2].DueAt)
+	assert.Equal(t, start.AddDate(0, 0, 30), *created[3].DueAt)
+
+	assert.Nil(t, response.Todos[0].ParentID)
+	require.NotNil(t, response.Todos[1].ParentID)
+	assert.Equal(t, uint(10), *response.Todos[1].ParentID)
+	assert.Equal(t, uint(10), *response.Todos[2].ParentID)
+	assert.Equal(t, 1, response.Todos[2].Depth)
+	assert.Nil(t, response.Todos[3].ParentID)
+	assert.Equal(t, 0, response.Todos[3].Depth)
+
+	// The tree is stored as dependencies of the parent on its subtasks
+	require.Len(t, edges, 2)
+	assert.Equal(t, model.TodoDependency{UserID: 1, TodoID: 10, BlockedByID: 11}, *edges[0])
+	assert.Equal(t, model.TodoDependency{UserID: 1, TodoID: 10, BlockedByID: 12}, *edges[1])
+	assert.True(t, response.Todos[0].Todo.Blocked)
+	assert.False(t, response.Todos[3].Todo.Blocked)
+}
+
+func TestTemplateService_Instantiate_DefaultsToToday(t *testing.T) {
+	templateService, mockTemplateRepo, mockTodoRepo, mockUserRepo, _, _ := setupTemplateService()
+	ctx := context.Background()
+
+	var created *model.Todo
+	mockTemplateRepo.On("GetByID", ctx, uint(3), uint(1)).Return(&model.TodoTemplate{
+		ID:    3,
+		Items: []model.TemplateItem{{Title: "Plan the week", DueOffsetDays: intPtr(1)}},
+	}, nil)
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*model.Todo)
+	})
+
+	_, err := templateService.Instantiate(ctx, uint(3), uint(1), &model.InstantiateTemplateRequest{})
+
+	require.NoError(t, err)
+	today := time.Now().UTC()
+	assert.Equal(t, time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, time.UTC), *created.DueAt)
+}
+
+func TestTemplateService_Instantiate_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *model.TodoTemplate
+		req      *model.InstantiateTemplateRequest
+		err      error
+		message  string
+	}{
+		{
+			name:     "missing variables",
+			template: onboardingTemplate(),
+			req:      &model.InstantiateTemplateRequest{Variables: map[string]string{"unused": "x"}},
+			err:      service.ErrMissingTemplateVariables,
+			message:  "missing template variables: name, team",
+		},
+		{
+			name:     "empty title",
+			template: &model.TodoTemplate{Items: []model.TemplateItem{{Title: "Done"}, {Title: "{{name}}"}}},
+			req:      &model.InstantiateTemplateRequest{Variables: map[string]string{"name": " "}},
+			err:      service.ErrInvalidTemplateTodo,
+			message:  "item 2",
+		},
+		{
+			name:     "invalid start date",
+			template: onboardingTemplate(),
+			req:      &model.InstantiateTemplateRequest{StartDate: "01/08/2024"},
+			err:      service.ErrInvalidStartDate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateService, mockTemplateRepo, mockTodoRepo, _, _, _ := setupTemplateService()
+			ctx := context.Background()
+			mockTemplateRepo.On("GetByID", ctx, uint(3), uint(1)).Return(tt.template, nil)
+
+			response, err := templateService.Instantiate(ctx, uint(3), uint(1), tt.req)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.message)
+			assert.Nil(t, response)
+			mockTodoRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTemplateService_Instantiate_RollsBack(t *testing.T) {
+	templateService, mockTemplateRepo, mockTodoRepo, mockUserRepo, mockDependencyRepo, tx := setupTemplateService()
+	ctx := context.Background()
+
+	mockTemplateRepo.On("GetByID", ctx, uint(3), uint(1)).Return(onboardingTemplate(), nil)
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	ids := uint(10)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = ids
+		ids++
+	}).Twice()
+	mockTodoRepo.On("GetByID", ctx, mock.Anything, uint(1)).Return(&model.Todo{UserID: 1}, nil)
+	mockDependencyRepo.On("Lock", ctx, uint(1)).Return(nil)
+	mockDependencyRepo.On("ListByUserID", ctx, uint(1)).Return([]*model.TodoDependency{}, nil)
+	mockDependencyRepo.On("Create", ctx, mock.AnythingOfType("*model.TodoDependency")).Return(nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(errors.New("database down")).Once()
+
+	response, err := templateService.Instantiate(ctx, uint(3), uint(1), &model.InstantiateTemplateRequest{
+		Variables: map[string]string{"name": "Alice", "team": "Platform"},
+	})
+
+	assert.ErrorContains(t, err, "failed to create todo")
+	assert.Nil(t, response)
+	assert.Equal(t, 0, tx.committed)
+	assert.Equal(t, 1, tx.rolledBack)
+	mockTodoRepo.AssertNumberOfCalls(t, "Create", 3)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func importRows() []model.ImportRow {
+	return []model.ImportRow{
+		{Row: 2, Title: "Buy milk"},
+		{Row: 3, Title: "", Description: "No title"},
+		{Row: 4, Title: "Pay rent", Completed: true},
+		{Row: 5, Title: "Broken", ParseError: "completed must be true or false"},
+		{Row: 6, Title: "Too long", Description: strings.Repeat("x", 1001)},
+	}
+}
+
+func TestTodoService_Export_StreamsTodos(t *testing.T) {
+	todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+	ctx := context.Background()
+	filter := &model.TodoFilter{Archived: model.ArchivedInclude}
+
+	mockTodoRepo.On("StreamByUserID", ctx, uint(1), filter, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		fn := args.Get(3).(func(*model.Todo) error)
+		for _, todo := range []*model.Todo{{ID: 1}, {ID: 2}} {
+			if err := fn(todo); err != nil {
+				return
+			}
+		}
+	})
+
+	var ids []uint
+	err := todoService.Export(ctx, uint(1), filter, func(todo *model.Todo) error {
+		ids = append(ids, todo.ID)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 2}, ids)
+}
+
+func TestTodoService_Export_RepositoryError(t *testing.T) {
+	todoService, mockTodoRepo, _, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("StreamByUserID", ctx, uint(1), (*model.TodoFilter)(nil), mock.Anything).Return(errors.New("connection reset"))
+
+	err := todoService.Export(ctx, uint(1), nil, func(*model.Todo) error { return nil })
+
+	assert.ErrorContains(t, err, "failed to export todos")
+}
+
+func TestTodoService_Import_SkipsInvalidRows(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, tx := setupBulkTodoService()
+	ctx := context.Background()
+
+	var created []*model.Todo
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(1).(*model.Todo))
+	})
+
+	response, err := todoService.Import(ctx, uint(1), &model.ImportRequest{Rows: importRows()})
+
+	require.NoError(t, err)
+	assert.True(t, response.Committed)
+	assert.Equal(t, 5, response.Total)
+	assert.Equal(t, 2, response.Imported)
+	assert.Equal(t, 3, response.Failed)
+	assert.Equal(t, 1, tx.committed)
+
+	require.Len(t, created, 2)
+	assert.Equal(t, "Buy milk", created[0].Title)
+	assert.False(t, created[0].Completed)
+	assert.Equal(t, "Pay rent", created[1].Title)
+	assert.True(t, created[1].Completed)
+	assert.NotNil(t, created[1].CompletedAt)
+	assert.Equal(t, uint(1), created[1].UserID)
+
+	require.Len(t, response.Errors, 3)
+	assert.Equal(t, 3, response.Errors[0].Row)
+	assert.Equal(t, "validation_failed", response.Errors[0].Error)
+	assert.Contains(t, response.Errors[0].Details, "title")
+	assert.Equal(t, 5, response.Errors[1].Row)
+	assert.Equal(t, "invalid_row", response.Errors[1].Error)
+	assert.Equal(t, "completed must be true or false", response.Errors[1].Message)
+	assert.Equal(t, 6, response.Errors[2].Row)
+	assert.Contains(t, response.Errors[2].Details, "description")
+}
+
+func TestTodoService_Import_KeepsDates(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, _ := setupBulkTodoService()
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	var created []*model.Todo
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(1).(*model.Todo))
+	})
+
+	rows := []model.ImportRow{
+		{Row: 1, Title: "Pay rent", Completed: true, CompletedAt: &completedAt, CreatedAt: &createdAt},
+		{Row: 2, Title: "Buy milk", CompletedAt: &completedAt},
+	}
+	_, err := todoService.Import(ctx, uint(1), &model.ImportRequest{Rows: rows})
+
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.Equal(t, createdAt, created[0].CreatedAt)
+	assert.Equal(t, &completedAt, created[0].CompletedAt)
+	// Open todos have no completion date
+	assert.Nil(t, created[1].CompletedAt)
+	assert.True(t, created[1].CreatedAt.IsZero())
+}
+
+func TestTodoService_Import_DryRunWritesNothing(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, tx := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+
+	response, err := todoService.Import(ctx, uint(1), &model.ImportRequest{Rows: importRows(), DryRun: true})
+
+	require.NoError(t, err)
+	assert.True(t, response.DryRun)
+	assert.False(t, response.Committed)
+	assert.Equal(t, 2, response.Imported)
+	assert.Equal(t, 3, response.Failed)
+	assert.Equal(t, 0, tx.committed)
+	mockTodoRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_Import_AtomicRejectsInvalidFile(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, tx := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+
+	response, err := todoService.Import(ctx, uint(1), &model.ImportRequest{Rows: importRows(), Atomic: true})
+
+	require.NoError(t, err)
+	assert.False(t, response.Committed)
+	assert.Equal(t, 0, response.Imported)
+	assert.Equal(t, 3, response.Failed)
+	assert.Equal(t, 0, tx.committed)
+	mockTodoRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_Import_RollsBackOnWriteError(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, tx := setupBulkTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(nil).Once()
+	mockTodoRepo.On("Create", ctx, mock.AnythingOfType("*model.Todo")).Return(errors.New("disk full")).Once()
+
+	response, err := todoService.Import(ctx, uint(1), &model.ImportRequest{
+		Rows: []model.ImportRow{{Row: 1, Title: "One"}, {Row: 2, Title: "Two"}},
+	})
+
+	assert.Nil(t, response)
+	assert.ErrorContains(t, err, "failed to import row 2")
+	assert.Equal(t, 0, tx.committed)
+	assert.Equal(t, 1, tx.rolledBack)
+}
+
+func TestTodoService_Import_Limits(t *testing.T) {
+	todoService, _, mockUserRepo, _ := setupBulkTodoService()
+	ctx := context.Background()
+
+	_, err := todoService.Import(ctx, uint(1), &model.ImportRequest{Rows: make([]model.ImportRow, 10001)})
+	assert.ErrorIs(t, err, service.ErrTooManyImportRows)
+
+	mockUserRepo.On("GetByID", ctx, uint(2)).Return(nil, gorm.ErrRecordNotFound)
+	_, err = todoService.Import(ctx, uint(2), &model.ImportRequest{Rows: importRows()})
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+}
+
+func TestTodoService_Import_AppliesCustomFieldRules(t *testing.T) {
+	todoService, mockTodoRepo, mockUserRepo, mockFieldRepo := setupCustomFieldTodoService()
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", ctx, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockFieldRepo.On("ListForTodo", ctx, uint(1), (*uint)(nil)).Return(teamFields()[:4], nil)
+
+	response, err := todoService.Import(ctx, uint(1), &model.ImportRequest{
+		Rows: []model.ImportRow{{Row: 2, Title: "Buy milk"}},
+	})
+
+	// The customer field is required, so the row cannot be created without it
+	require.NoError(t, err)
+	assert.Equal(t, 0, response.Imported)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, 2, response.Errors[0].Row)
+	assert.Equal(t, "invalid_custom_fields", response.Errors[0].Error)
+	assert.Contains(t, response.Errors[0].Details, "custom_fields.customer")
+	mockTodoRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+func TestTodoService_GetTrash_Success(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	userID := uint(1)
+	trashed := []*model.Todo{
+		{ID: 1, Title: "Deleted Todo", UserID: userID},
+	}
+
+	mockTodoRepo.On("GetTrashByUserID", ctx, userID).Return(trashed, nil)
+
+	todos, err := todoService.GetTrash(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, todos, 1)
+	assert.Equal(t, "Deleted Todo", todos[0].Title)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_GetTrash_Empty(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetTrashByUserID", ctx, uint(1)).Return(nil, nil)
+
+	todos, err := todoService.GetTrash(ctx, uint(1))
+
+	assert.NoError(t, err)
+	assert.NotNil(t, todos)
+	assert.Len(t, todos, 0)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Restore_Success(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	todoID := uint(1)
+	userID := uint(1)
+	restored := &model.Todo{ID: todoID, Title: "Restored Todo", UserID: userID}
+
+	mockTodoRepo.On("GetTrashedByID", ctx, todoID, userID).Return(&model.Todo{ID: todoID, Title: "Restored Todo", UserID: userID}, nil)
+	mockTodoRepo.On("Restore", ctx, todoID, userID).Return(nil)
+	mockTodoRepo.On("GetByID", ctx, todoID, userID).Return(restored, nil)
+
+	todo, err := todoService.Restore(ctx, todoID, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, restored, todo)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Restore_NotInTrash(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetTrashedByID", ctx, uint(1), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	todo, err := todoService.Restore(ctx, uint(1), uint(1))
+
+	assert.Nil(t, todo)
+	assert.Equal(t, service.ErrTodoNotFound, err)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Purge_Success(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetTrashedByID", ctx, uint(1), uint(1)).Return(&model.Todo{ID: 1, UserID: 1}, nil)
+	mockTodoRepo.On("Purge", ctx, uint(1), uint(1)).Return(nil)
+
+	err := todoService.Purge(ctx, uint(1), uint(1))
+
+	assert.NoError(t, err)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Purge_NotInTrash(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetTrashedByID", ctx, uint(1), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	err := todoService.Purge(ctx, uint(1), uint(1))
+
+	assert.Equal(t, service.ErrTodoNotFound, err)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_EmptyTrash(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetTrashByUserID", ctx, uint(1)).Return([]*model.Todo{}, nil)
+	mockTodoRepo.On("PurgeTrashByUserID", ctx, uint(1)).Return(int64(3), nil)
+
+	purged, err := todoService.EmptyTrash(ctx, uint(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+
+	mockTodoRepo.AssertExpectations(t)
+}
+
+func TestTodoService_EmptyTrash_DatabaseError(t *testing.T) {
+	todoService, mockTodoRepo, _ := setupTodoService()
+	ctx := context.Background()
+
+	mockTodoRepo.On("GetTrashByUserID", ctx, uint(1)).Return([]*model.Todo{}, nil)
+	mockTodoRepo.On("PurgeTrashByUserID", ctx, uint(1)).Return(int64(0), errors.New("database error"))
+
+	_, err := todoService.EmptyTrash(ctx, uint(1))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to empty trash")
+
+	mockTodoRepo.AssertExpectations(t)
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api-backend/internal/model"
+	"todo-api-backend/internal/service"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	return m.Called(ctx, webhook).Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id uint, userID uint) (*model.Webhook, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetByIDForDelivery(ctx context.Context, id uint) (*model.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListByUserID(ctx context.Context, userID uint) ([]*model.Webhook, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListSubscribed(ctx context.Context, userID uint, eventType string) ([]*model.Webhook, error) {
+	args := m.Called(ctx, userID, eventType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	return m.Called(ctx, webhook).Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id uint, userID uint) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockWebhookRepository) RecordSuccess(ctx context.Context, id uint) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockWebhookRepository) RecordFailure(ctx context.Context, id uint, threshold int, reason string, now time.Time) (bool, error) {
+	args := m.Called(ctx, id, threshold, reason, now)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockWebhookDeliveryRepository is a mock implementation of WebhookDeliveryRepository
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) Create(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) ListByWebhookID(ctx context.Context, webhookID uint, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// recordingQueue records the webhook events queued by the todo service
+type recordingQueue struct {
+	events []string
+	err    error
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, userID uint, eventType string, todoID uint, todo *model.Todo) error {
+	q.events = append(q.events, eventType)
+	return q.err
+}
+
+// fakeResolver resolves hosts from a fixed table
+type fakeResolver map[string]string
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addr, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return []netip.Addr{netip.MustParseAddr(addr)}, nil
+}
+
+func setupWebhookService() (service.WebhookService, *MockWebhookRepository, *MockWebhookDeliveryRepository) {
+	mockRepo := &MockWebhookRepository{}
+	mockDeliveries := &MockWebhookDeliveryRepository{}
+	resolver := fakeResolver{
+		"bot.example.com":      "93.184.216.34",
+		"old.example.com":      "93.184.216.34",
+		"new.example.com":      "93.184.216.34",
+		"metadata.example.com": "169.254.169.254",
+		"localhost":            "127.0.0.1",
+	}
+	return service.NewWebhookService(mockRepo, mockDeliveries, service.WithWebhookResolver(resolver)), mockRepo, mockDeliveries
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	webhookService, mockRepo, _ := setupWebhookService()
+	ctx := context.Background()
+
+	mockRepo.On("CountByUserID", ctx, uint(1)).Return(int64(0), nil)
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*model.Webhook")).Return(nil)
+
+	webhook, err := webhookService.Create(ctx, 1, &model.CreateWebhookRequest{
+		URL:    "https://bot.example.com/hooks",
+		Events: []string{model.WebhookEventTodoCompleted, model.WebhookEventTodoCompleted},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), webhook.UserID)
+	assert.True(t, webhook.Active)
+	assert.Equal(t, []string{model.WebhookEventTodoCompleted}, webhook.Events)
+	assert.True(t, strings.HasPrefix(webhook.Secret, "whsec_"))
+	assert.Len(t, webhook.Secret, len("whsec_")+48)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_Create_RejectsNonHTTPURL(t *testing.T) {
+	webhookService, mockRepo, _ := setupWebhookService()
+
+	for _, url := range []string{"ftp://example.com/hook", "mailto:bot@example.com", "/relative"} {
+		_, err := webhookService.Create(context.Background(), 1, &model.CreateWebhookRequest{URL: url, Events: []string{model.WebhookEventTodoCreated}})
+		assert.Equal(t, service.ErrInvalidWebhookURL, err, url)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_Create_RejectsInternalTargets(t *testing.T) {
+	webhookService, mockRepo, _ := setupWebhookService()
+
+	urls := []string{
+		"http://127.0.0.1:6379/",
+		"http://localhost:8080/hook",
+		"http://[::1]:8080/hook",
+		"http://10.0.0.8/hook",
+		"http://169.254.169.254/latest/meta-data/iam/",
+		"https://metadata.example.com/hook",
+	}
+	for _, url := range urls {
+		_, err := webhookService.Create(context.Background(), 1, &model.CreateWebhookRequest{URL: url, Events: []string{model.WebhookEventTodoCreated}})
+		assert.Equal(t, service.ErrInvalidWebhookURL, err, url)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_Update_RejectsInternalTargets(t *testing.T) {
+	webhookService, mockRepo, _ := setupWebhookService()
+
+	_, err := webhookService.Update(context.Background(), 3, 1, &model.UpdateWebhookRequest{
+		URL: "http://169.254.169.254/latest/meta-data/", Events: []string{model.WebhookEventTodoCreated}, Active: true,
+	})
+
+	assert.Equal(t, service.ErrInvalidWebhookURL, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_Create_Limit(t *testing.T) {
+	webhookService, mockRepo, _ := setupWebhookService()
+	ctx := context.Background()
+
+	mockRepo.On("CountByUserID", ctx, uint(1)).Return(int64(10), nil)
+
+	_, err := webhookService.Create(ctx, 1, &model.CreateWebhookRequest{URL: "https://bot.example.com/hooks", Events: []string{model.WebhookEventTodoCreated}})
+
+	assert.Equal(t, service.ErrWebhookLimit, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_Update_ReactivationResetsFailures(t *testing.T) {
+	webhookService, mockRepo, _ := setupWebhookService()
+	ctx := context.Background()
+
+	disabledAt := time.Now()
+	mockRepo.On("GetByID", ctx, uint(3), uint(1)).Return(&model.Webhook{
+		ID: 3, UserID: 1, URL: "https://old.example.com", Events: []string{model.WebhookEventTodoCreated},
+		Active: false, FailureCount: 20, DisabledAt: &disabledAt, DisabledReason: "20 consecutive failed delivery attempts",
+	}, nil)
+	mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Webhook")).Return(nil)
+
+	webhook, err := webhookService.Update(ctx, 3, 1, &model.UpdateWebhookRequest{
+		URL: "https://new.example.com", Events: []string{model.WebhookEventTodoCompleted}, Active: true,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, webhook.Active)
+	assert.Zero(t, webhook.FailureCount)
+	assert.Nil(t, webhook.DisabledAt)
+	assert.Empty(t, webhook.DisabledReason)
+	assert.Equal(t, "https://new.example.com", webhook.URL)
+}
+
+func TestWebhookService_NotFound(t *testing.T) {
+	webhookService, mockRepo, _ := setupWebhookService()
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, uint(9), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("Delete", ctx, uint(9), uint(1)).Return(gorm.ErrRecordNotFound)
+
+	_, err := webhookService.Get(ctx, 9, 1)
+	assert.Equal(t, service.ErrWebhookNotFound, err)
+
+	_, err = webhookService.ListDeliveries(ctx, 9, 1, 0)
+	assert.Equal(t, service.ErrWebhookNotFound, err)
+
+	assert.Equal(t, service.ErrWebhookNotFound, webhookService.Delete(ctx, 9, 1))
+}
+
+func TestWebhookService_ListDeliveries_ClampsLimit(t *testing.T) {
+	webhookService, mockRepo, mockDeliveries := setupWebhookService()
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, uint(3), uint(1)).Return(&model.Webhook{ID: 3, UserID: 1}, nil)
+	mockDeliveries.On("ListByWebhookID", ctx, uint(3), 50).Return([]*model.WebhookDelivery{}, nil).Once()
+	mockDeliveries.On("ListByWebhookID", ctx, uint(3), 200).Return([]*model.WebhookDelivery{}, nil).Once()
+
+	_, err := webhookService.ListDeliveries(ctx, 3, 1, 0)
+	require.NoError(t, err)
+	_, err = webhookService.ListDeliveries(ctx, 3, 1, 1000)
+	require.NoError(t, err)
+
+	mockDeliveries.AssertExpectations(t)
+}
+
+func TestWebhookService_Enqueue(t *testing.T) {
+	webhookService, mockRepo, mockDeliveries := setupWebhookService()
+	ctx := context.Background()
+
+	mockRepo.On("ListSubscribed", ctx, uint(1), model.WebhookEventTodoCompleted).Return([]*model.Webhook{{ID: 3}, {ID: 4}}, nil)
+
+	var queued []*model.WebhookDelivery
+	mockDeliveries.On("Create", ctx, mock.AnythingOfType("*model.WebhookDelivery")).Return(nil).Run(func(args mock.Arguments) {
+		queued = append(queued, args.Get(1).(*model.WebhookDelivery))
+	})
+
+	todo := &model.Todo{ID: 7, UserID: 1, Title: "Ship it", Completed: true}
+	err := webhookService.Enqueue(ctx, 1, model.WebhookEventTodoCompleted, 7, todo)
+
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	assert.Equal(t, uint(3), queued[0].WebhookID)
+	assert.Equal(t, uint(4), queued[1].WebhookID)
+	assert.Equal(t, queued[0].EventID, queued[1].EventID)
+	assert.Equal(t, model.WebhookDeliveryPending, queued[0].Status)
+	assert.False(t, queued[0].NextAttemptAt.IsZero())
+
+	var payload model.WebhookPayload
+	require.NoError(t, json.Unmarshal(queued[0].Payload, &payload))
+	assert.Equal(t, queued[0].EventID, payload.ID)
+	assert.Equal(t, model.WebhookEventTodoCompleted, payload.Type)
+	assert.Equal(t, uint(7), payload.TodoID)
+	require.NotNil(t, payload.Todo)
+	assert.Equal(t, "Ship it", payload.Todo.Title)
+}
+
+func TestWebhookService_Enqueue_NoSubscribers(t *testing.T) {
+	webhookService, mockRepo, mockDeliveries := setupWebhookService()
+	ctx := context.Background()
+
+	mockRepo.On("ListSubscribed", ctx, uint(1), model.WebhookEventTodoCreated).Return([]*model.Webhook{}, nil)
+
+	require.NoError(t, webhookService.Enqueue(ctx, 1, model.WebhookEventTodoCreated, 7, &model.Todo{ID: 7}))
+	mockDeliveries.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_NotifyMention(t *testing.T) {
+	webhookService, mockRepo, mockDeliveries := setupWebhookService()
+	ctx := context.Background()
+
+	mockRepo.On("ListSubscribed", ctx, uint(2), model.WebhookEventCommentMentioned).Return([]*model.Webhook{{ID: 5}}, nil)
+
+	var queued *model.WebhookDelivery
+	mockDeliveries.On("Create", ctx, mock.AnythingOfType("*model.WebhookDelivery")).Return(nil).Run(func(args mock.Arguments) {
+		queued = args.Get(1).(*model.WebhookDelivery)
+	})
+
+	todo := &model.Todo{ID: 7, UserID: 2, Title: "Fix login bug"}
+	comment := &model.TodoComment{ID: 9, TodoID: 7, UserID: 2, Body: "@jane@example.com can you check?"}
+	require.NoError(t, webhookService.NotifyMention(ctx, 2, todo, comment))
+
+	require.NotNil(t, queued)
+	assert.Equal(t, model.WebhookEventCommentMentioned, queued.EventType)
+	var payload model.WebhookPayload
+	require.NoError(t, json.Unmarshal(queued.Payload, &payload))
+	assert.Equal(t, uint(7), payload.TodoID)
+	require.NotNil(t, payload.Comment)
+	assert.Equal(t, uint(9), payload.Comment.ID)
+	assert.Equal(t, comment.Body, payload.Comment.Body)
+}
+
+func TestTodoService_QueuesWebhooks(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	queue := &recordingQueue{}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(&fakeTransactor{}), service.WithWebhooks(queue))
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Todo).ID = 1
+	})
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Created", UserID: 1}, nil).Once()
+	mockTodoRepo.On("GetByID", mock.Anything, uint(1), uint(1)).Return(&model.Todo{ID: 1, Title: "Created", UserID: 1, Completed: true}, nil)
+	mockTodoRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+	mockTodoRepo.On("Delete", mock.Anything, uint(1), uint(1)).Return(nil)
+
+	_, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Created"}, uint(1))
+	require.NoError(t, err)
+
+	// Completing a todo also sends todo.completed; editing a completed todo does not
+	completed := true
+	_, err = todoService.Update(ctx, uint(1), &model.UpdateTodoRequest{Completed: &completed}, uint(1))
+	require.NoError(t, err)
+	title := "Renamed"
+	_, err = todoService.Update(ctx, uint(1), &model.UpdateTodoRequest{Title: &title}, uint(1))
+	require.NoError(t, err)
+
+	require.NoError(t, todoService.Delete(ctx, uint(1), uint(1)))
+
+	assert.Equal(t, []string{
+		model.WebhookEventTodoCreated,
+		model.WebhookEventTodoUpdated,
+		model.WebhookEventTodoCompleted,
+		model.WebhookEventTodoUpdated,
+		model.WebhookEventTodoDeleted,
+	}, queue.events)
+}
+
+func TestTodoService_WebhookQueueFailureFailsChange(t *testing.T) {
+	mockTodoRepo := &MockTodoRepository{}
+	mockUserRepo := &MockUserRepository{}
+	queue := &recordingQueue{err: errors.New("queue unavailable")}
+	todoService := service.NewTodoService(mockTodoRepo, mockUserRepo, service.WithTransactor(&fakeTransactor{}), service.WithWebhooks(queue))
+	ctx := context.Background()
+
+	mockUserRepo.On("GetByID", mock.Anything, uint(1)).Return(&model.User{ID: 1}, nil)
+	mockTodoRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Todo")).Return(nil)
+
+	_, err := todoService.Create(ctx, &model.CreateTodoRequest{Title: "Created"}, uint(1))
+
+	assert.Error(t, err)
+}